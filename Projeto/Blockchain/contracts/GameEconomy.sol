@@ -11,6 +11,8 @@ pragma solidity ^0.8.19;
  * - Propriedade única e verificável de cartas (NFTs)
  * - Distribuição justa de pacotes (prevenção de duplo gasto)
  * - Trocas atômicas entre jogadores
+ * - Mercado de cartas a preço fixo, com custódia no contrato
+ * - Fusão de cartas repetidas em uma carta de raridade maior
  * - Transparência total através de eventos na blockchain
  */
 
@@ -52,6 +54,30 @@ struct PropostaTroca {
     bool aceita;             // Se jogador2 aceitou
     bool executada;          // Se a troca já foi executada
     uint256 timestamp;       // Quando a proposta foi criada
+    uint256 expiraEm;        // Depois deste instante a proposta não pode mais ser aceita
+    bool cancelada;          // Se jogador1 desistiu da proposta
+    bool recusada;           // Se jogador2 recusou a proposta
+}
+
+/**
+ * @dev Compra de pacote aguardando revelação (commit-reveal)
+ * O conteúdo do pacote só é sorteado quando o comprador revela o segredo
+ */
+struct CompraPendente {
+    bytes32 compromisso;     // hashCompromisso(comprador, segredo)
+    uint256 bloco;           // Bloco em que a compra foi comprometida
+}
+
+/**
+ * @dev Anúncio de carta no mercado
+ * Enquanto ativo, a carta fica sob custódia do contrato (proprietario = address(this))
+ */
+struct Anuncio {
+    address vendedor;        // Quem anunciou (recebe o pagamento)
+    uint256 cartaId;         // Carta à venda
+    uint256 preco;           // Preço em wei
+    bool ativo;              // false depois de vendido ou retirado
+    uint256 timestamp;       // Quando o anúncio foi criado
 }
 
 /**
@@ -63,6 +89,9 @@ struct Partida {
     address jogador2;
     address vencedor;        // address(0) se empate
     uint256 timestamp;
+    bytes32 hashLog;         // keccak256 do EventLog da partida (até MATCH_END)
+    address servidorHost;    // Servidor que processou a partida
+    address servidorSombra;  // Servidor de backup (address(0) em partida local)
 }
 
 // ===================== Contrato Principal =====================
@@ -88,15 +117,51 @@ contract GameEconomy {
     mapping(uint256 => PropostaTroca) public propostasTroca;
     uint256 private _propostaCounter;
     
+    // BAREMA ITEM 8: TROCAS - Propostas criadas e recebidas por cada jogador (para listagem)
+    mapping(address => uint256[]) private _propostasEnviadas;
+    mapping(address => uint256[]) private _propostasRecebidas;
+    
+    // BAREMA ITEM 8: TROCAS - Por quanto tempo uma proposta pode ser aceita
+    uint256 public constant VALIDADE_PROPOSTA = 1 days;
+    
+    // BAREMA ITEM 8: MERCADO - Anúncios por ID e IDs dos anúncios ativos
+    mapping(uint256 => Anuncio) public anuncios;
+    uint256 private _anuncioCounter;
+    uint256[] private _anunciosAtivos;
+    mapping(uint256 => uint256) private _posicaoAnuncioAtivo; // índice em _anunciosAtivos + 1
+    
+    // BAREMA ITEM 8: MERCADO - Valor das vendas que cada vendedor ainda pode sacar
+    mapping(address => uint256) public saldoVendas;
+    uint256 private _saldoVendasTotal; // Parte do saldo do contrato que pertence aos vendedores
+    
     // BAREMA ITEM 7: PARTIDAS - Lista de partidas registradas
     Partida[] public partidas;
     
+    // BAREMA ITEM 7: PARTIDAS - hash do EventLog -> índice da partida + 1 (0 = não registrada)
+    mapping(bytes32 => uint256) public partidaPorLog;
+    
+    // BAREMA ITEM 7: PARTIDAS - Contas dos servidores de jogo que podem assinar resultados
+    mapping(address => bool) public servidoresAutorizados;
+    
     // BAREMA ITEM 8: PACOTES - Preço de um pacote (em wei, moeda fictícia)
     uint256 public precoPacote = 1000000000000000000; // 1 ETH (fictício)
     
     // BAREMA ITEM 8: PACOTES - Quantidade de cartas por pacote
     uint256 public constant CARTAS_POR_PACOTE = 5;
     
+    // BAREMA ITEM 8: FUSÃO - Cartas de mesmo nome e raridade queimadas para gerar uma da raridade seguinte
+    uint256 public constant CARTAS_POR_FUSAO = 3;
+    
+    // BAREMA ITEM 8: PACOTES - Blocos após o compromisso em que a revelação é aceita
+    // (blockhash só alcança os últimos 256 blocos)
+    uint256 public constant PRAZO_REVELACAO = 250;
+    
+    // BAREMA ITEM 8: PACOTES - Compra aguardando revelação de cada jogador (no máximo uma)
+    mapping(address => CompraPendente) public comprasPendentes;
+    
+    // BAREMA ITEM 8: PACOTES - Quantos compromissos cada jogador já fez (numera os segredos do cliente)
+    mapping(address => uint256) public comprasComprometidas;
+    
     // BAREMA ITEM 7: PARTIDAS - Endereço do dono do contrato (para funções administrativas)
     address public owner;
     
@@ -134,6 +199,24 @@ contract GameEconomy {
         uint256 timestamp
     );
     
+    /**
+     * @dev Emitido quando um jogador paga e compromete o segredo de uma compra de pacote
+     */
+    event CompraComprometida(
+        address indexed comprador,
+        bytes32 compromisso,
+        uint256 bloco
+    );
+    
+    /**
+     * @dev Emitido quando uma compra não foi revelada no prazo e é descartada
+     * O pagamento não é devolvido: sem isso, o comprador poderia esconder um sorteio ruim e tentar de novo
+     */
+    event CompraExpirada(
+        address indexed comprador,
+        uint256 bloco
+    );
+    
     /**
      * @dev Emitido quando uma proposta de troca é criada
      * Notifica o jogador2 sobre a proposta pendente
@@ -158,6 +241,80 @@ contract GameEconomy {
         uint256 cartaJogador2
     );
     
+    /**
+     * @dev Emitido quando o jogador1 cancela uma proposta pendente
+     */
+    event PropostaTrocaCancelada(
+        uint256 indexed propostaId,
+        address indexed jogador1,
+        address indexed jogador2
+    );
+    
+    /**
+     * @dev Emitido quando o jogador2 recusa uma proposta pendente
+     */
+    event PropostaTrocaRecusada(
+        uint256 indexed propostaId,
+        address indexed jogador1,
+        address indexed jogador2
+    );
+    
+    /**
+     * @dev Emitido quando uma carta é destruída (fusão)
+     */
+    event CartaQueimada(
+        uint256 indexed tokenId,
+        address indexed proprietario
+    );
+    
+    /**
+     * @dev Emitido quando cartas repetidas são fundidas em uma carta nova
+     */
+    event CartasFundidas(
+        address indexed proprietario,
+        uint256[] queimadas,
+        uint256 indexed novaCarta,
+        string raridade
+    );
+    
+    /**
+     * @dev Emitido quando uma carta é anunciada no mercado (e passa à custódia do contrato)
+     */
+    event CartaAnunciada(
+        uint256 indexed anuncioId,
+        address indexed vendedor,
+        uint256 indexed cartaId,
+        uint256 preco
+    );
+    
+    /**
+     * @dev Emitido quando uma carta anunciada é comprada
+     */
+    event CartaVendida(
+        uint256 indexed anuncioId,
+        address indexed vendedor,
+        address indexed comprador,
+        uint256 cartaId,
+        uint256 preco
+    );
+    
+    /**
+     * @dev Emitido quando o vendedor retira o anúncio e a carta volta para ele
+     */
+    event AnuncioRetirado(
+        uint256 indexed anuncioId,
+        address indexed vendedor,
+        uint256 indexed cartaId
+    );
+    
+    /**
+     * @dev Emitido quando um vendedor saca o valor acumulado das vendas
+     */
+    event VendasSacadas(
+        address indexed vendedor,
+        uint256 valor
+    );
+    
     /**
      * @dev Emitido quando uma partida é registrada
      * Permite auditabilidade completa do histórico de partidas
@@ -166,7 +323,16 @@ contract GameEconomy {
         address indexed jogador1,
         address indexed jogador2,
         address indexed vencedor,
-        uint256 timestamp
+        uint256 timestamp,
+        bytes32 hashLog
+    );
+    
+    /**
+     * @dev Emitido quando o dono autoriza ou revoga a conta de um servidor de jogo
+     */
+    event ServidorAutorizado(
+        address indexed servidor,
+        bool autorizado
     );
     
     // ===================== Modificadores =====================
@@ -240,22 +406,22 @@ contract GameEconomy {
     }
     
     /**
-     * @dev Gera uma carta aleatória baseada em block hash
-     * BAREMA ITEM 8: PACOTES - Usa blockhash como fonte de entropia para sorteio
-     * @param _seed Seed adicional para aumentar aleatoriedade
+     * @dev Gera uma carta aleatória a partir de uma semente
+     * BAREMA ITEM 8: PACOTES - A semente combina o segredo do comprador com o blockhash do compromisso
+     * @param _seed Semente da carta (ver revelarPacote)
      * @return nome Nome da carta gerada
      * @return naipe Naipe da carta gerada
      * @return valor Valor/poder da carta gerada
      * @return raridade Raridade da carta gerada
      */
-    function _gerarCartaAleatoria(uint256 _seed) internal view returns (
+    function _gerarCartaAleatoria(bytes32 _seed) internal pure returns (
         string memory nome,
         string memory naipe,
         uint256 valor,
         string memory raridade
     ) {
-        // BAREMA ITEM 8: PACOTES - Combina blockhash com seed para aleatoriedade
-        bytes32 hash = keccak256(abi.encodePacked(blockhash(block.number - 1), _seed, block.timestamp));
+        // BAREMA ITEM 8: PACOTES - Nada aqui vem do bloco atual, que o signatário do bloco poderia escolher
+        bytes32 hash = keccak256(abi.encodePacked(_seed));
         
         // Lista de nomes de cartas disponíveis
         string[20] memory nomes = [
@@ -292,22 +458,59 @@ contract GameEconomy {
     // ===================== Funções Públicas de Compra =====================
     
     /**
-     * @dev Compra um pacote de cartas
-     * BAREMA ITEM 8: PACOTES - Previne duplo gasto através de atomicidade da transação
-     * Cada chamada cria exatamente 5 cartas novas e as atribui ao comprador
-     * @return tokenIds Array com os IDs das cartas recebidas
+     * @dev Compromisso que o comprador envia junto com o pagamento
+     * @param _comprador Quem vai revelar o segredo
+     * @param _segredo Segredo escolhido pelo comprador
+     * @return keccak256(comprador, segredo)
      */
-    function comprarPacote() public payable returns (uint256[] memory) {
+    function hashCompromisso(address _comprador, bytes32 _segredo) public pure returns (bytes32) {
+        return keccak256(abi.encodePacked(_comprador, _segredo));
+    }
+    
+    /**
+     * @dev Primeira etapa da compra: paga o pacote e compromete o hash de um segredo
+     * BAREMA ITEM 8: PACOTES - O pacote só é sorteado em revelarPacote, num bloco posterior
+     * @param _compromisso hashCompromisso(msg.sender, segredo)
+     */
+    function comprometerCompraPacote(bytes32 _compromisso) public payable {
         // BAREMA ITEM 8: PACOTES - Valida que o pagamento é suficiente
         require(msg.value >= precoPacote, "Valor insuficiente para comprar pacote");
+        require(_compromisso != bytes32(0), "Compromisso invalido");
+        require(comprasPendentes[msg.sender].bloco == 0, "Compra pendente de revelacao");
+        
+        comprasPendentes[msg.sender] = CompraPendente({
+            compromisso: _compromisso,
+            bloco: block.number
+        });
+        comprasComprometidas[msg.sender]++;
+        
+        emit CompraComprometida(msg.sender, _compromisso, block.number);
+    }
+    
+    /**
+     * @dev Segunda etapa da compra: revela o segredo e recebe as cartas
+     * BAREMA ITEM 8: PACOTES - Previne duplo gasto: cada compromisso é revelado uma única vez
+     * A semente combina o segredo (desconhecido do signatário do bloco) com o blockhash do bloco do
+     * compromisso (desconhecido do comprador quando se comprometeu)
+     * @param _segredo Segredo cujo hash foi comprometido
+     * @return tokenIds Array com os IDs das cartas recebidas
+     */
+    function revelarPacote(bytes32 _segredo) public returns (uint256[] memory) {
+        CompraPendente memory compra = comprasPendentes[msg.sender];
+        require(compra.bloco != 0, "Nenhuma compra pendente");
+        require(block.number > compra.bloco, "Revele em um bloco posterior");
+        require(block.number <= compra.bloco + PRAZO_REVELACAO, "Prazo de revelacao expirado");
+        require(hashCompromisso(msg.sender, _segredo) == compra.compromisso, "Segredo nao confere");
+        
+        delete comprasPendentes[msg.sender];
         
+        bytes32 semente = keccak256(abi.encodePacked(_segredo, blockhash(compra.bloco)));
         uint256[] memory tokenIds = new uint256[](CARTAS_POR_PACOTE);
         
-        // BAREMA ITEM 8: PACOTES - Gera 5 cartas aleatórias
-        // Cada carta usa um seed diferente baseado no tokenCounter para garantir unicidade
+        // BAREMA ITEM 8: PACOTES - Gera 5 cartas aleatórias, uma semente derivada para cada
         for (uint256 i = 0; i < CARTAS_POR_PACOTE; i++) {
             (string memory nome, string memory naipe, uint256 valor, string memory raridade) = 
-                _gerarCartaAleatoria(_tokenCounter + i);
+                _gerarCartaAleatoria(keccak256(abi.encodePacked(semente, i)));
             
             tokenIds[i] = _criarCarta(msg.sender, nome, naipe, valor, raridade);
         }
@@ -317,6 +520,21 @@ contract GameEconomy {
         return tokenIds;
     }
     
+    /**
+     * @dev Descarta uma compra que não foi revelada no prazo, liberando o comprador para comprar de novo
+     * Qualquer conta pode chamar. O pagamento fica no contrato, como em uma compra concluída
+     * @param _comprador Dono da compra expirada
+     */
+    function expirarCompraPacote(address _comprador) public {
+        CompraPendente memory compra = comprasPendentes[_comprador];
+        require(compra.bloco != 0, "Nenhuma compra pendente");
+        require(block.number > compra.bloco + PRAZO_REVELACAO, "Compra ainda pode ser revelada");
+        
+        delete comprasPendentes[_comprador];
+        
+        emit CompraExpirada(_comprador, compra.bloco);
+    }
+    
     // ===================== Funções de Consulta =====================
     
     /**
@@ -389,7 +607,7 @@ contract GameEconomy {
      * @param _jogador2 Endereço do jogador com quem quer trocar
      * @param _minhaCarta ID da carta que está oferecendo
      * @param _cartaDesejada ID da carta que deseja receber
-     * @return propostaId ID da proposta criada
+     * @return propostaId ID da proposta criada (aceitável por VALIDADE_PROPOSTA)
      */
     function criarPropostaTroca(
         address _jogador2,
@@ -410,8 +628,13 @@ contract GameEconomy {
             cartaJogador2: _cartaDesejada,
             aceita: false,
             executada: false,
-            timestamp: block.timestamp
+            timestamp: block.timestamp,
+            expiraEm: block.timestamp + VALIDADE_PROPOSTA,
+            cancelada: false,
+            recusada: false
         });
+        _propostasEnviadas[msg.sender].push(propostaId);
+        _propostasRecebidas[_jogador2].push(propostaId);
         
         emit PropostaTrocaCriada(propostaId, msg.sender, _jogador2, _minhaCarta, _cartaDesejada);
         
@@ -430,7 +653,7 @@ contract GameEconomy {
         require(proposta.jogador1 != address(0), "Proposta nao existe");
         require(proposta.timestamp > 0, "Proposta invalida");
         require(proposta.jogador2 == msg.sender, "Voce nao e o destinatario desta proposta");
-        require(!proposta.executada, "Proposta ja foi executada");
+        _exigirPendente(proposta);
         require(proprietario[proposta.cartaJogador1] == proposta.jogador1, "Jogador1 nao possui mais esta carta");
         require(proprietario[proposta.cartaJogador2] == proposta.jogador2, "Voce nao possui mais esta carta");
         
@@ -452,6 +675,68 @@ contract GameEconomy {
         );
     }
     
+    /**
+     * @dev Cancela uma proposta pendente
+     * BAREMA ITEM 8: TROCAS - Só quem criou a proposta pode desistir dela
+     * @param propostaId ID da proposta a ser cancelada
+     */
+    function cancelarPropostaTroca(uint256 propostaId) public {
+        PropostaTroca storage proposta = propostasTroca[propostaId];
+        
+        require(proposta.jogador1 != address(0), "Proposta nao existe");
+        require(proposta.jogador1 == msg.sender, "Voce nao criou esta proposta");
+        _exigirPendente(proposta);
+        
+        proposta.cancelada = true;
+        
+        emit PropostaTrocaCancelada(propostaId, proposta.jogador1, proposta.jogador2);
+    }
+    
+    /**
+     * @dev Recusa uma proposta recebida
+     * BAREMA ITEM 8: TROCAS - Só o destinatário pode recusar
+     * @param propostaId ID da proposta a ser recusada
+     */
+    function recusarPropostaTroca(uint256 propostaId) public {
+        PropostaTroca storage proposta = propostasTroca[propostaId];
+        
+        require(proposta.jogador1 != address(0), "Proposta nao existe");
+        require(proposta.jogador2 == msg.sender, "Voce nao e o destinatario desta proposta");
+        _exigirPendente(proposta);
+        
+        proposta.recusada = true;
+        
+        emit PropostaTrocaRecusada(propostaId, proposta.jogador1, proposta.jogador2);
+    }
+    
+    /**
+     * @dev Exige que a proposta ainda possa ser aceita, cancelada ou recusada
+     */
+    function _exigirPendente(PropostaTroca storage proposta) internal view {
+        require(!proposta.executada, "Proposta ja foi executada");
+        require(!proposta.cancelada, "Proposta foi cancelada");
+        require(!proposta.recusada, "Proposta foi recusada");
+        require(block.timestamp <= proposta.expiraEm, "Proposta expirada");
+    }
+    
+    /**
+     * @dev IDs das propostas criadas por um jogador, em ordem de criação (inclui encerradas)
+     * @param _jogador Endereço do jogador
+     * @return Array com os IDs das propostas
+     */
+    function obterPropostasEnviadas(address _jogador) public view returns (uint256[] memory) {
+        return _propostasEnviadas[_jogador];
+    }
+    
+    /**
+     * @dev IDs das propostas recebidas por um jogador, em ordem de criação (inclui encerradas)
+     * @param _jogador Endereço do jogador
+     * @return Array com os IDs das propostas
+     */
+    function obterPropostasRecebidas(address _jogador) public view returns (uint256[] memory) {
+        return _propostasRecebidas[_jogador];
+    }
+    
     /**
      * @dev Retorna informações de uma proposta de troca
      * @param propostaId ID da proposta
@@ -493,7 +778,10 @@ contract GameEconomy {
             cartaJogador2: _cartaJogador2,
             aceita: true,
             executada: true,
-            timestamp: block.timestamp
+            timestamp: block.timestamp,
+            expiraEm: block.timestamp,
+            cancelada: false,
+            recusada: false
         });
         
         // Executa a transferência atômica das cartas
@@ -534,30 +822,308 @@ contract GameEconomy {
         emit CartaTransferida(tokenId, _de, _para);
     }
     
+    // ===================== Funções de Fusão =====================
+    
+    /**
+     * @dev Funde CARTAS_POR_FUSAO cartas de mesmo nome e raridade em uma carta da raridade seguinte
+     * BAREMA ITEM 8: FUSÃO - As cartas fundidas são queimadas; a nova mantém o nome e o naipe da primeira
+     * e tem valor determinístico dentro da faixa da nova raridade: mínimo + (soma dos valores % faixa)
+     * @param _cartas IDs das cartas a fundir (todas do chamador)
+     * @return novaCarta ID da carta criada
+     */
+    function fundirCartas(uint256[] memory _cartas) public returns (uint256) {
+        require(_cartas.length == CARTAS_POR_FUSAO, "Quantidade de cartas invalida para fusao");
+        
+        Carta memory primeira = cartas[_cartas[0]];
+        (string memory raridade, uint256 valorMinimo, uint256 faixa) = _proximaRaridade(primeira.raridade);
+        
+        uint256 somaValores = 0;
+        for (uint256 i = 0; i < _cartas.length; i++) {
+            uint256 tokenId = _cartas[i];
+            require(proprietario[tokenId] == msg.sender, "Voce nao possui esta carta");
+            for (uint256 j = 0; j < i; j++) {
+                require(_cartas[j] != tokenId, "Carta repetida na fusao");
+            }
+            Carta memory carta = cartas[tokenId];
+            require(keccak256(bytes(carta.nome)) == keccak256(bytes(primeira.nome)), "Cartas com nomes diferentes");
+            require(keccak256(bytes(carta.raridade)) == keccak256(bytes(primeira.raridade)), "Cartas com raridades diferentes");
+            somaValores += carta.valor;
+        }
+        
+        for (uint256 i = 0; i < _cartas.length; i++) {
+            _queimarCarta(msg.sender, _cartas[i]);
+        }
+        
+        uint256 novaCarta = _criarCarta(
+            msg.sender,
+            primeira.nome,
+            primeira.naipe,
+            valorMinimo + (somaValores % faixa),
+            raridade
+        );
+        
+        emit CartasFundidas(msg.sender, _cartas, novaCarta, raridade);
+        
+        return novaCarta;
+    }
+    
+    /**
+     * @dev Raridade seguinte e a faixa de valores dela (as mesmas de _gerarCartaAleatoria)
+     */
+    function _proximaRaridade(string memory _raridade) internal pure returns (
+        string memory raridade,
+        uint256 valorMinimo,
+        uint256 faixa
+    ) {
+        bytes32 atual = keccak256(bytes(_raridade));
+        if (atual == keccak256(bytes("C"))) {
+            return ("U", 51, 30);
+        } else if (atual == keccak256(bytes("U"))) {
+            return ("R", 81, 20);
+        } else if (atual == keccak256(bytes("R"))) {
+            return ("L", 101, 20);
+        }
+        revert("Raridade nao pode ser fundida");
+    }
+    
+    /**
+     * @dev Destrói uma carta: sai do inventário do dono e os dados deixam de existir
+     */
+    function _queimarCarta(address _dono, uint256 tokenId) internal {
+        delete proprietario[tokenId];
+        delete cartas[tokenId];
+        
+        uint256[] storage inv = inventario[_dono];
+        for (uint256 i = 0; i < inv.length; i++) {
+            if (inv[i] == tokenId) {
+                inv[i] = inv[inv.length - 1];
+                inv.pop();
+                break;
+            }
+        }
+        saldo[_dono]--;
+        
+        emit CartaQueimada(tokenId, _dono);
+    }
+    
+    // ===================== Funções de Mercado =====================
+    
+    /**
+     * @dev Anuncia uma carta a preço fixo
+     * BAREMA ITEM 8: MERCADO - A carta fica sob custódia do contrato até ser vendida ou retirada,
+     * então não pode ser trocada nem anunciada de novo nesse meio tempo
+     * @param tokenId ID da carta
+     * @param _preco Preço em wei
+     * @return anuncioId ID do anúncio criado
+     */
+    function anunciarCarta(uint256 tokenId, uint256 _preco) public possuiCarta(tokenId) returns (uint256) {
+        require(_preco > 0, "Preco invalido");
+        
+        _transferirCartaInterno(msg.sender, address(this), tokenId);
+        
+        uint256 anuncioId = _anuncioCounter;
+        _anuncioCounter++;
+        
+        anuncios[anuncioId] = Anuncio({
+            vendedor: msg.sender,
+            cartaId: tokenId,
+            preco: _preco,
+            ativo: true,
+            timestamp: block.timestamp
+        });
+        _anunciosAtivos.push(anuncioId);
+        _posicaoAnuncioAtivo[anuncioId] = _anunciosAtivos.length;
+        
+        emit CartaAnunciada(anuncioId, msg.sender, tokenId, _preco);
+        
+        return anuncioId;
+    }
+    
+    /**
+     * @dev Compra uma carta anunciada pelo preço exato do anúncio
+     * BAREMA ITEM 8: MERCADO - Pagamento e entrega da carta na mesma transação; o valor fica com o
+     * contrato até o vendedor sacar (sacarVendas)
+     * @param anuncioId ID do anúncio
+     */
+    function comprarCartaAnunciada(uint256 anuncioId) public payable {
+        Anuncio storage anuncio = anuncios[anuncioId];
+        
+        require(anuncio.ativo, "Anuncio inativo");
+        require(anuncio.vendedor != msg.sender, "Nao pode comprar o proprio anuncio");
+        require(msg.value == anuncio.preco, "Valor diferente do preco");
+        
+        _encerrarAnuncio(anuncioId);
+        saldoVendas[anuncio.vendedor] += msg.value;
+        _saldoVendasTotal += msg.value;
+        
+        _transferirCartaInterno(address(this), msg.sender, anuncio.cartaId);
+        
+        emit CartaVendida(anuncioId, anuncio.vendedor, msg.sender, anuncio.cartaId, msg.value);
+    }
+    
+    /**
+     * @dev Retira um anúncio ativo e devolve a carta ao vendedor
+     * @param anuncioId ID do anúncio
+     */
+    function retirarAnuncio(uint256 anuncioId) public {
+        Anuncio storage anuncio = anuncios[anuncioId];
+        
+        require(anuncio.ativo, "Anuncio inativo");
+        require(anuncio.vendedor == msg.sender, "Voce nao e o vendedor");
+        
+        _encerrarAnuncio(anuncioId);
+        _transferirCartaInterno(address(this), msg.sender, anuncio.cartaId);
+        
+        emit AnuncioRetirado(anuncioId, msg.sender, anuncio.cartaId);
+    }
+    
+    /**
+     * @dev Saca o valor acumulado das vendas do chamador
+     */
+    function sacarVendas() public {
+        uint256 valor = saldoVendas[msg.sender];
+        require(valor > 0, "Nada a sacar");
+        
+        saldoVendas[msg.sender] = 0;
+        _saldoVendasTotal -= valor;
+        payable(msg.sender).transfer(valor);
+        
+        emit VendasSacadas(msg.sender, valor);
+    }
+    
+    /**
+     * @dev Marca o anúncio como inativo e o remove da lista de ativos (troca com o último)
+     */
+    function _encerrarAnuncio(uint256 anuncioId) internal {
+        anuncios[anuncioId].ativo = false;
+        
+        uint256 indice = _posicaoAnuncioAtivo[anuncioId] - 1;
+        uint256 ultimo = _anunciosAtivos[_anunciosAtivos.length - 1];
+        _anunciosAtivos[indice] = ultimo;
+        _posicaoAnuncioAtivo[ultimo] = indice + 1;
+        _anunciosAtivos.pop();
+        delete _posicaoAnuncioAtivo[anuncioId];
+    }
+    
+    /**
+     * @dev IDs dos anúncios ativos (sem ordem definida)
+     * @return Array com os IDs
+     */
+    function obterAnunciosAtivos() public view returns (uint256[] memory) {
+        return _anunciosAtivos;
+    }
+    
+    /**
+     * @dev Retorna um anúncio
+     * @param anuncioId ID do anúncio
+     * @return Anuncio Estrutura com dados do anúncio
+     */
+    function obterAnuncio(uint256 anuncioId) public view returns (Anuncio memory) {
+        return anuncios[anuncioId];
+    }
+    
     // ===================== Funções de Partidas =====================
     
     /**
-     * @dev Registra o resultado de uma partida
+     * @dev Hash do resultado que Host, Sombra e jogadores assinam (personal_sign)
+     * Inclui o endereço do contrato para que a assinatura não valha em outra implantação
+     * @param _jogador1 Endereço do primeiro jogador
+     * @param _jogador2 Endereço do segundo jogador
+     * @param _vencedor Endereço do vencedor (address(0) se empate)
+     * @param _hashLog keccak256 do EventLog da partida
+     * @return Hash a ser assinado
+     */
+    function hashResultado(
+        address _jogador1,
+        address _jogador2,
+        address _vencedor,
+        bytes32 _hashLog
+    ) public view returns (bytes32) {
+        return keccak256(abi.encodePacked(address(this), _jogador1, _jogador2, _vencedor, _hashLog));
+    }
+    
+    /**
+     * @dev Registra o resultado de uma partida co-assinado por servidores e jogadores
      * BAREMA ITEM 7: PARTIDAS - Cria registro permanente e auditável na blockchain
-     * @param _jogador2 Endereço do oponente
+     * Qualquer conta pode enviar a transação: a autenticidade vem das assinaturas
+     * @param _jogador1 Endereço do primeiro jogador
+     * @param _jogador2 Endereço do segundo jogador
      * @param _vencedor Endereço do vencedor (address(0) se empate)
+     * @param _hashLog keccak256 do EventLog da partida
+     * @param _servidorHost Conta do servidor Host (precisa estar autorizada)
+     * @param _servidorSombra Conta do servidor Sombra (address(0) em partida local)
+     * @param _assinaturas Assinaturas de [Host, Sombra, jogador1, jogador2] sobre hashResultado
      */
-    function registrarPartida(address _jogador2, address _vencedor) public {
+    function registrarPartida(
+        address _jogador1,
+        address _jogador2,
+        address _vencedor,
+        bytes32 _hashLog,
+        address _servidorHost,
+        address _servidorSombra,
+        bytes[] memory _assinaturas
+    ) public {
+        require(_jogador1 != address(0), "Jogador1 invalido");
         require(_jogador2 != address(0), "Jogador2 invalido");
-        require(_jogador2 != msg.sender, "Nao pode jogar consigo mesmo");
-        require(_vencedor == msg.sender || _vencedor == _jogador2 || _vencedor == address(0), 
+        require(_jogador1 != _jogador2, "Nao pode jogar consigo mesmo");
+        require(_vencedor == _jogador1 || _vencedor == _jogador2 || _vencedor == address(0), 
                 "Vencedor deve ser um dos jogadores ou empate");
+        require(_hashLog != bytes32(0), "Hash do log invalido");
+        require(partidaPorLog[_hashLog] == 0, "Partida ja registrada");
+        require(_assinaturas.length == 4, "Assinaturas incompletas");
+        require(servidoresAutorizados[_servidorHost], "Servidor nao autorizado");
+        require(_servidorSombra == address(0) || (servidoresAutorizados[_servidorSombra] && _servidorSombra != _servidorHost),
+                "Servidor nao autorizado");
+        
+        bytes32 hash = keccak256(abi.encodePacked(
+            "\x19Ethereum Signed Message:\n32",
+            hashResultado(_jogador1, _jogador2, _vencedor, _hashLog)
+        ));
+        require(_recuperarAssinante(hash, _assinaturas[0]) == _servidorHost, "Assinatura do Host invalida");
+        if (_servidorSombra != address(0)) {
+            require(_recuperarAssinante(hash, _assinaturas[1]) == _servidorSombra, "Assinatura da Sombra invalida");
+        }
+        require(_recuperarAssinante(hash, _assinaturas[2]) == _jogador1, "Assinatura do jogador1 invalida");
+        require(_recuperarAssinante(hash, _assinaturas[3]) == _jogador2, "Assinatura do jogador2 invalida");
         
         Partida memory novaPartida = Partida({
-            jogador1: msg.sender,
+            jogador1: _jogador1,
             jogador2: _jogador2,
             vencedor: _vencedor,
-            timestamp: block.timestamp
+            timestamp: block.timestamp,
+            hashLog: _hashLog,
+            servidorHost: _servidorHost,
+            servidorSombra: _servidorSombra
         });
         
         partidas.push(novaPartida);
+        partidaPorLog[_hashLog] = partidas.length;
         
-        emit PartidaRegistrada(msg.sender, _jogador2, _vencedor, block.timestamp);
+        emit PartidaRegistrada(_jogador1, _jogador2, _vencedor, block.timestamp, _hashLog);
+    }
+    
+    /**
+     * @dev Recupera o endereço que produziu uma assinatura (r, s, v) de 65 bytes
+     * @param _hash Hash assinado (já com o prefixo personal_sign)
+     * @param _assinatura Assinatura no formato r || s || v
+     * @return Endereço do assinante (address(0) se a assinatura for inválida)
+     */
+    function _recuperarAssinante(bytes32 _hash, bytes memory _assinatura) internal pure returns (address) {
+        if (_assinatura.length != 65) {
+            return address(0);
+        }
+        bytes32 r;
+        bytes32 s;
+        uint8 v;
+        assembly {
+            r := mload(add(_assinatura, 32))
+            s := mload(add(_assinatura, 64))
+            v := byte(0, mload(add(_assinatura, 96)))
+        }
+        if (v < 27) {
+            v += 27;
+        }
+        return ecrecover(_hash, v, r, s);
     }
     
     /**
@@ -580,6 +1146,17 @@ contract GameEconomy {
     
     // ===================== Funções Administrativas =====================
     
+    /**
+     * @dev Autoriza (ou revoga) a conta de um servidor de jogo a assinar resultados de partidas
+     * @param _servidor Conta do servidor
+     * @param _autorizado true para autorizar, false para revogar
+     */
+    function autorizarServidor(address _servidor, bool _autorizado) public onlyOwner {
+        require(_servidor != address(0), "Servidor invalido");
+        servidoresAutorizados[_servidor] = _autorizado;
+        emit ServidorAutorizado(_servidor, _autorizado);
+    }
+    
     /**
      * @dev Permite ao dono alterar o preço dos pacotes
      * @param _novoPreco Novo preço em wei
@@ -590,9 +1167,10 @@ contract GameEconomy {
     
     /**
      * @dev Permite ao dono retirar fundos acumulados
+     * O valor das vendas do mercado ainda não sacado pertence aos vendedores e fica no contrato
      */
     function retirarFundos() public onlyOwner {
-        payable(owner).transfer(address(this).balance);
+        payable(owner).transfer(address(this).balance - _saldoVendasTotal);
     }
 }
 
@@ -38,7 +38,7 @@ REM Compila o contrato usando Docker (gera .bin e .abi)
 docker run --rm ^
     -v "%CONTRACTS_DIR%:/contracts" ^
     -w /contracts ^
-    ethereum/solc:0.8.20 ^
+    ethereum/solc:0.8.21 ^
     --bin --abi ^
     --evm-version paris ^
     --optimize --optimize-runs 200 ^
@@ -78,6 +78,8 @@ if %BIN_OK%==1 if %ABI_OK%==1 (
     echo Compilacao concluida com sucesso!
     echo ========================================
     echo.
+    echo Regenere os bindings Go ^(em Jogo\^): go generate ./servidor/blockchain/contrato
+    echo.
     echo Proximo passo: Fazer deploy do contrato
     echo   - Execute o cliente Go
     echo   - Escolha opcao 6 (Deploy do Contrato)
@@ -35,7 +35,7 @@ echo ""
 docker run --rm \
     -v "$CONTRACTS_DIR:/contracts" \
     -w /contracts \
-    ethereum/solc:0.8.20 \
+    ethereum/solc:0.8.21 \
     --bin --abi \
     --evm-version paris \
     --optimize --optimize-runs 200 \
@@ -64,6 +64,14 @@ if [ -f "$CONTRACTS_DIR/GameEconomy.abi" ]; then
 fi
 
 if [ $BIN_OK -eq 1 ] && [ $ABI_OK -eq 1 ]; then
+    # Regenera os bindings Go (ABI e bytecode embutidos no servidor e no cliente)
+    if go version > /dev/null 2>&1; then
+        echo "Regenerando bindings Go (servidor/blockchain/contrato)..."
+        (cd "$PROJECT_DIR/../Jogo" && go generate ./servidor/blockchain/contrato) \
+            || echo "[AVISO] Falha ao regenerar bindings. Rode em Jogo/: go generate ./servidor/blockchain/contrato"
+    else
+        echo "[AVISO] Go não encontrado. Rode em Jogo/: go generate ./servidor/blockchain/contrato"
+    fi
     echo ""
     echo "========================================"
     echo "Compilação concluída com sucesso!"
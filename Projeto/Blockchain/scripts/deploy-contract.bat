@@ -50,7 +50,17 @@ if not exist "deploy-contract.exe" (
 )
 
 .\deploy-contract.exe
+if %ERRORLEVEL% NEQ 0 goto fim
 
+REM Cada servidor de jogo assina com uma conta própria, que o dono do contrato precisa autorizar
+echo.
+echo Criando e autorizando as contas dos servidores...
+if not exist "autorizar-servidores.exe" (
+    go build -o autorizar-servidores.exe autorizar-servidores.go
+)
+.\autorizar-servidores.exe
+
+:fim
 if %ERRORLEVEL% EQU 0 (
     echo.
     echo ========================================
@@ -54,6 +54,18 @@ else
     exit 1
 fi
 
+# Cada servidor de jogo assina com uma conta própria, que o dono do contrato precisa autorizar
+echo
+echo "Criando e autorizando as contas dos servidores..."
+if [ ! -f "autorizar-servidores" ] && [ ! -f "autorizar-servidores.exe" ]; then
+    go build -o autorizar-servidores autorizar-servidores.go
+fi
+if [ -f "autorizar-servidores" ]; then
+    ./autorizar-servidores
+else
+    ./autorizar-servidores.exe
+fi
+
 if [ $? -eq 0 ]; then
     echo
     echo "========================================"
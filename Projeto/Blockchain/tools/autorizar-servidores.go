@@ -0,0 +1,232 @@
+// Utilitário que dá a cada servidor de jogo a sua própria conta e a autoriza no GameEconomy.
+//
+// O contrato só aceita resultados assinados por contas autorizadas (autorizarServidor) e recusa
+// Host e Sombra com a mesma conta, então cada servidor precisa de um keystore só seu. Para cada
+// nome informado (padrão: servidor1 servidor2 servidor3), o utilitário:
+//   - cria data/servidores/<nome>.json, se ainda não existir (senha: SERVER_PASSWORD ou 123456);
+//   - transfere ETH da conta dona do contrato, se a conta do servidor estiver sem saldo para o gás;
+//   - chama autorizarServidor(conta, true), se a conta ainda não estiver autorizada.
+//
+// Deve ser executado depois do deploy, pela conta que fez o deploy (a dona do contrato).
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+var (
+	saldoMinimoServidor = new(big.Int).Mul(big.NewInt(1), big.NewInt(1e18))  // 1 ETH: abaixo disso a conta é abastecida
+	abastecimento       = new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18)) // 10 ETH por abastecimento
+)
+
+func main() {
+	servidores := os.Args[1:]
+	if len(servidores) == 0 {
+		servidores = []string{"servidor1", "servidor2", "servidor3"}
+	}
+	senhaServidores := os.Getenv("SERVER_PASSWORD")
+	if senhaServidores == "" {
+		senhaServidores = "123456"
+	}
+
+	fmt.Println("========================================")
+	fmt.Println("Autorização das Contas dos Servidores")
+	fmt.Println("========================================")
+	fmt.Println()
+
+	client, err := ethclient.Dial("http://localhost:8545")
+	if err != nil {
+		fmt.Printf("ERRO: Falha ao conectar ao Geth: %v\n", err)
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	// Endereço do contrato gravado pelo deploy
+	enderecoBytes, err := ioutil.ReadFile(filepath.Join("..", "..", "contract-address.txt"))
+	if err != nil {
+		fmt.Printf("ERRO: contract-address.txt não encontrado: %v\n", err)
+		fmt.Println("Execute deploy-contract primeiro")
+		os.Exit(1)
+	}
+	contrato := common.HexToAddress(strings.TrimSpace(string(enderecoBytes)))
+
+	abiBytes, err := ioutil.ReadFile(filepath.Join("..", "contracts", "GameEconomy.abi"))
+	if err != nil {
+		fmt.Printf("ERRO: Falha ao ler a ABI: %v\n", err)
+		os.Exit(1)
+	}
+	abiContrato, err := abi.JSON(strings.NewReader(string(abiBytes)))
+	if err != nil {
+		fmt.Printf("ERRO: ABI inválida: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Conta dona do contrato: a mesma usada no deploy
+	ks := keystore.NewKeyStore(filepath.Join("..", "data", "keystore"), keystore.StandardScryptN, keystore.StandardScryptP)
+	if len(ks.Accounts()) == 0 {
+		fmt.Println("ERRO: Nenhuma conta encontrada no keystore!")
+		os.Exit(1)
+	}
+	dono := ks.Accounts()[0]
+	senhaBytes, err := ioutil.ReadFile(filepath.Join("..", "data", "password.txt"))
+	if err != nil {
+		fmt.Printf("ERRO: Falha ao ler password.txt: %v\n", err)
+		os.Exit(1)
+	}
+	if err := ks.Unlock(dono, strings.TrimSpace(string(senhaBytes))); err != nil {
+		fmt.Printf("ERRO: Falha ao desbloquear a conta dona %s: %v\n", dono.Address.Hex(), err)
+		os.Exit(1)
+	}
+	chainID, err := client.NetworkID(context.Background())
+	if err != nil {
+		chainID = big.NewInt(1337)
+	}
+	fmt.Printf("Contrato: %s\nDono:     %s\n\n", contrato.Hex(), dono.Address.Hex())
+
+	diretorio := filepath.Join("..", "data", "servidores")
+	if err := os.MkdirAll(diretorio, 0700); err != nil {
+		fmt.Printf("ERRO: Falha ao criar %s: %v\n", diretorio, err)
+		os.Exit(1)
+	}
+
+	for i, nome := range servidores {
+		fmt.Printf("[%d/%d] %s\n", i+1, len(servidores), nome)
+		conta, err := contaDoServidor(filepath.Join(diretorio, nome+".json"), senhaServidores)
+		if err != nil {
+			fmt.Printf("ERRO: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("  Conta: %s\n", conta.Hex())
+
+		saldo, err := client.BalanceAt(context.Background(), conta, nil)
+		if err != nil {
+			fmt.Printf("ERRO: Falha ao consultar saldo: %v\n", err)
+			os.Exit(1)
+		}
+		if saldo.Cmp(saldoMinimoServidor) < 0 {
+			if err := enviar(client, ks, dono, chainID, conta, abastecimento, nil); err != nil {
+				fmt.Printf("ERRO: Falha ao abastecer a conta: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("  [OK] Conta abastecida com 10 ETH")
+		}
+
+		autorizado, err := servidorAutorizado(client, abiContrato, contrato, conta)
+		if err != nil {
+			fmt.Printf("ERRO: Falha ao consultar servidoresAutorizados: %v\n", err)
+			os.Exit(1)
+		}
+		if autorizado {
+			fmt.Println("  [OK] Já autorizada")
+			continue
+		}
+		dados, err := abiContrato.Pack("autorizarServidor", conta, true)
+		if err != nil {
+			fmt.Printf("ERRO: Falha ao codificar autorizarServidor: %v\n", err)
+			os.Exit(1)
+		}
+		if err := enviar(client, ks, dono, chainID, contrato, big.NewInt(0), dados); err != nil {
+			fmt.Printf("ERRO: autorizarServidor falhou: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("  [OK] Autorizada no contrato")
+	}
+
+	fmt.Println()
+	fmt.Println("✓ Contas dos servidores prontas em", diretorio)
+}
+
+// contaDoServidor lê o keystore do servidor, criando-o se ainda não existir.
+func contaDoServidor(caminho, senha string) (common.Address, error) {
+	if jsonBytes, err := ioutil.ReadFile(caminho); err == nil {
+		chave, err := keystore.DecryptKey(jsonBytes, senha)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("falha ao abrir %s: %v", caminho, err)
+		}
+		return chave.Address, nil
+	}
+
+	// StoreKey grava com o nome padrão do geth (UTC--...); o arquivo é renomeado para o do servidor
+	conta, err := keystore.StoreKey(filepath.Dir(caminho), senha, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("falha ao criar chave: %v", err)
+	}
+	if err := os.Rename(conta.URL.Path, caminho); err != nil {
+		return common.Address{}, fmt.Errorf("falha ao gravar %s: %v", caminho, err)
+	}
+	fmt.Printf("  Keystore criado: %s\n", caminho)
+	return conta.Address, nil
+}
+
+// servidorAutorizado lê o mapeamento público servidoresAutorizados do contrato.
+func servidorAutorizado(client *ethclient.Client, abiContrato abi.ABI, contrato, conta common.Address) (bool, error) {
+	dados, err := abiContrato.Pack("servidoresAutorizados", conta)
+	if err != nil {
+		return false, err
+	}
+	resultado, err := client.CallContract(context.Background(), ethereum.CallMsg{To: &contrato, Data: dados}, nil)
+	if err != nil {
+		return false, err
+	}
+	valores, err := abiContrato.Unpack("servidoresAutorizados", resultado)
+	if err != nil || len(valores) != 1 {
+		return false, fmt.Errorf("resposta inesperada: %v", err)
+	}
+	autorizado, _ := valores[0].(bool)
+	return autorizado, nil
+}
+
+// enviar assina com a conta dona uma transação para destino e espera o recibo.
+func enviar(client *ethclient.Client, ks *keystore.KeyStore, dono accounts.Account, chainID *big.Int, destino common.Address, valor *big.Int, dados []byte) error {
+	ctx := context.Background()
+	nonce, err := client.PendingNonceAt(ctx, dono.Address)
+	if err != nil {
+		return fmt.Errorf("falha ao obter nonce: %v", err)
+	}
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		gasPrice = big.NewInt(1000000000) // 1 gwei como fallback
+	}
+	gasLimit := uint64(21000)
+	if len(dados) > 0 {
+		gasLimit = 200000
+	}
+	tx := types.NewTransaction(nonce, destino, valor, gasLimit, gasPrice, dados)
+	assinada, err := ks.SignTx(dono, tx, chainID)
+	if err != nil {
+		return fmt.Errorf("falha ao assinar: %v", err)
+	}
+	if err := client.SendTransaction(ctx, assinada); err != nil {
+		return fmt.Errorf("falha ao enviar: %v", err)
+	}
+
+	prazo := time.Now().Add(60 * time.Second)
+	for time.Now().Before(prazo) {
+		time.Sleep(2 * time.Second)
+		recibo, err := client.TransactionReceipt(ctx, assinada.Hash())
+		if err != nil || recibo == nil {
+			continue
+		}
+		if recibo.Status != types.ReceiptStatusSuccessful {
+			return fmt.Errorf("transação %s revertida", assinada.Hash().Hex())
+		}
+		return nil
+	}
+	return fmt.Errorf("timeout aguardando a transação %s", assinada.Hash().Hex())
+}
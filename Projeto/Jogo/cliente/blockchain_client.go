@@ -2,20 +2,24 @@ package main
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"fmt"
 	"io/ioutil"
 	"math/big"
-	"reflect"
+	"sort"
 	"strings"
 	"time"
 
 	"jogodistribuido/protocolo"
+	"jogodistribuido/servidor/blockchain"
+	"jogodistribuido/servidor/blockchain/contrato"
 
 	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -47,12 +51,12 @@ func getKeystorePath() string {
 }
 
 var (
-	blockchainClient  *ethclient.Client
+	blockchainClient  blockchain.Backend // ethclient em produção; simulado nos testes
 	blockchainRPC     *rpc.Client
 	contaBlockchain   common.Address
 	chavePrivada      *keystore.Key
 	contractAddress   common.Address
-	contractABI       abi.ABI
+	contratoLeitor    *contrato.GameEconomyCaller
 	senhaConta        string
 	blockchainEnabled bool
 )
@@ -96,33 +100,12 @@ func inicializarBlockchain() error {
 
 	contractAddress = common.HexToAddress(strings.TrimSpace(string(contractAddrBytes)))
 
-	// Carrega ABI (tenta vários caminhos)
-	abiPaths := []string{
-		"../Blockchain/contracts/GameEconomy.abi",
-		"../../Blockchain/contracts/GameEconomy.abi",
-		"../../../Blockchain/contracts/GameEconomy.abi",
-		"../Projeto/Blockchain/contracts/GameEconomy.abi",
-		"./Blockchain/contracts/GameEconomy.abi",
-	}
-
-	var abiBytes []byte
-	for _, abiPath := range abiPaths {
-		abiBytes, err = ioutil.ReadFile(abiPath)
-		if err == nil {
-			break
-		}
-	}
-
-	if err != nil {
-		return fmt.Errorf("ABI do contrato não encontrado")
-	}
-
-	parsedABI, err := abi.JSON(strings.NewReader(string(abiBytes)))
+	// Bindings tipados do contrato (ABI embutido no binário)
+	leitor, err := contrato.NewGameEconomyCaller(contractAddress, client)
 	if err != nil {
-		return fmt.Errorf("erro ao fazer parse do ABI: %v", err)
+		return fmt.Errorf("erro ao criar bindings do contrato: %v", err)
 	}
-
-	contractABI = parsedABI
+	contratoLeitor = leitor
 	// NÃO define blockchainEnabled = true aqui
 	// Só será true quando a carteira também for carregada
 	fmt.Printf("✓ Conexão com blockchain estabelecida (Contrato: %s)\n", contractAddress.Hex())
@@ -130,6 +113,23 @@ func inicializarBlockchain() error {
 	return nil
 }
 
+// usarBackendBlockchain liga o cliente a um backend já pronto (ex.: cadeia do pacote simulado),
+// sem ler contract-address.txt nem keystore. A carteira passa a ser a chave informada.
+func usarBackendBlockchain(backend blockchain.Backend, endereco common.Address, chave *ecdsa.PrivateKey) error {
+	leitor, err := contrato.NewGameEconomyCaller(endereco, backend)
+	if err != nil {
+		return fmt.Errorf("erro ao criar bindings do contrato: %v", err)
+	}
+	blockchainClient = backend
+	blockchainRPC = nil
+	contractAddress = endereco
+	contratoLeitor = leitor
+	contaBlockchain = crypto.PubkeyToAddress(chave.PublicKey)
+	chavePrivada = &keystore.Key{Address: contaBlockchain, PrivateKey: chave}
+	blockchainEnabled = true
+	return nil
+}
+
 // carregarCarteira carrega ou cria uma carteira
 func carregarCarteira() error {
 	// Removido verificação de blockchainEnabled aqui, pois ela só será true ao final desta função
@@ -213,26 +213,40 @@ func carregarCarteira() error {
 	return nil
 }
 
-// comprarPacoteBlockchain compra um pacote de cartas na blockchain
-func comprarPacoteBlockchain() error {
+// comprarPacoteBlockchain compra um pacote de cartas na blockchain, em duas transações: o
+// compromisso (com o pagamento) e, num bloco posterior, a revelação do segredo que sorteia as cartas.
+// Uma compra anterior ainda pendente é revelada (ou descartada, se o prazo passou) antes da nova.
+// Retorna o hash da transação de revelação da nova compra.
+func comprarPacoteBlockchain() (common.Hash, error) {
 	fmt.Printf("[DEBUG] comprarPacoteBlockchain() iniciado\n")
 	fmt.Printf("[DEBUG] blockchainEnabled=%v, chavePrivada!=nil=%v\n", blockchainEnabled, chavePrivada != nil)
 	fmt.Printf("[DEBUG] contaBlockchain=%s, contractAddress=%s\n", contaBlockchain.Hex(), contractAddress.Hex())
 
 	if !blockchainEnabled {
-		return fmt.Errorf("blockchain não está habilitada")
+		return common.Hash{}, fmt.Errorf("blockchain não está habilitada")
 	}
 
 	if chavePrivada == nil {
-		return fmt.Errorf("carteira não carregada")
+		return common.Hash{}, fmt.Errorf("carteira não carregada")
+	}
+
+	if err := resolverCompraPendenteBlockchain(); err != nil {
+		return common.Hash{}, err
+	}
+
+	// O segredo é derivado da carteira e do número da compra, então sobrevive a um reinício do cliente
+	numero, err := contratoLeitor.ComprasComprometidas(opcoesLeitura(), contaBlockchain)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
 	}
+	segredo := segredoCompraBlockchain(numero)
 
 	// Prepara a chamada
-	fmt.Printf("[DEBUG] Preparando chamada comprarPacote()...\n")
-	data, err := contractABI.Pack("comprarPacote")
+	fmt.Printf("[DEBUG] Preparando chamada comprometerCompraPacote()...\n")
+	data, err := contrato.DadosComprometerCompraPacote(contrato.HashCompromisso(contaBlockchain, segredo))
 	if err != nil {
 		fmt.Printf("[ERRO] Falha ao preparar chamada: %v\n", err)
-		return fmt.Errorf("erro ao preparar chamada: %v", err)
+		return common.Hash{}, fmt.Errorf("erro ao preparar chamada: %v", err)
 	}
 	fmt.Printf("[DEBUG] Chamada preparada, dados: %s...\n", common.Bytes2Hex(data[:min(20, len(data))]))
 
@@ -240,12 +254,103 @@ func comprarPacoteBlockchain() error {
 	valor := big.NewInt(1000000000000000000) // 1 ETH em wei
 	fmt.Printf("[DEBUG] Valor da transação: %s wei (1 ETH)\n", valor.String())
 
+	receipt, err := executarTransacaoBlockchain(data, valor)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	fmt.Println("✓ Pagamento confirmado, revelando o pacote no próximo bloco...")
+
+	revelacao, err := revelarPacoteBlockchain(segredo, receipt.BlockNumber.Uint64())
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	fmt.Println("✓ Pacote comprado com sucesso!")
+	return revelacao, nil
+}
+
+// resolverCompraPendenteBlockchain trata uma compra da carteira que ficou sem revelação (ex: o
+// cliente fechou entre as duas transações): revela se ainda está no prazo, descarta se não está.
+func resolverCompraPendenteBlockchain() error {
+	pendente, err := contratoLeitor.ComprasPendentes(opcoesLeitura(), contaBlockchain)
+	if err != nil {
+		return fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	if pendente.Bloco.Sign() == 0 {
+		return nil
+	}
+	bloco := pendente.Bloco.Uint64()
+
+	atual, err := blockchainClient.BlockNumber(context.Background())
+	if err != nil {
+		return fmt.Errorf("erro ao obter bloco atual: %v", err)
+	}
+	if atual > bloco+blockchain.PrazoRevelacao {
+		fmt.Printf("[BLOCKCHAIN] Compra do bloco %d não foi revelada no prazo; descartando\n", bloco)
+		data, err := contrato.DadosExpirarCompraPacote(contaBlockchain)
+		if err != nil {
+			return fmt.Errorf("erro ao preparar chamada: %v", err)
+		}
+		_, err = executarTransacaoBlockchain(data, big.NewInt(0))
+		return err
+	}
+
+	// A compra pendente é a última comprometida pela carteira
+	numero, err := contratoLeitor.ComprasComprometidas(opcoesLeitura(), contaBlockchain)
+	if err != nil {
+		return fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	segredo := segredoCompraBlockchain(new(big.Int).Sub(numero, big.NewInt(1)))
+	if contrato.HashCompromisso(contaBlockchain, segredo) != common.Hash(pendente.Compromisso) {
+		return fmt.Errorf("compra do bloco %d não foi feita por este cliente, expira após o bloco %d: %w",
+			bloco, bloco+blockchain.PrazoRevelacao, contrato.ErrCompraPendente)
+	}
+	fmt.Printf("[BLOCKCHAIN] Revelando compra pendente do bloco %d...\n", bloco)
+	_, err = revelarPacoteBlockchain(segredo, bloco)
+	return err
+}
+
+// revelarPacoteBlockchain espera um bloco posterior ao do compromisso e revela o segredo. Retorna o
+// hash da transação de revelação, que o servidor confere ao entregar o pacote
+func revelarPacoteBlockchain(segredo common.Hash, bloco uint64) (common.Hash, error) {
+	limite := time.Now().Add(blockchain.EsperaRevelacao)
+	for {
+		atual, err := blockchainClient.BlockNumber(context.Background())
+		if err == nil && atual > bloco {
+			break
+		}
+		if time.Now().After(limite) {
+			return common.Hash{}, fmt.Errorf("nenhum bloco após o %d em %v", bloco, blockchain.EsperaRevelacao)
+		}
+		time.Sleep(blockchain.IntervaloRevelacao)
+	}
+
+	fmt.Printf("[DEBUG] Preparando chamada revelarPacote()...\n")
+	data, err := contrato.DadosRevelarPacote(segredo)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("erro ao preparar chamada: %v", err)
+	}
+	receipt, err := executarTransacaoBlockchain(data, big.NewInt(0))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return receipt.TxHash, nil
+}
+
+// segredoCompraBlockchain deriva o segredo da n-ésima compra da carteira (n começa em 0)
+func segredoCompraBlockchain(n *big.Int) common.Hash {
+	return crypto.Keccak256Hash(crypto.FromECDSA(chavePrivada.PrivateKey), []byte("pacote"), common.BigToHash(n).Bytes())
+}
+
+// executarTransacaoBlockchain envia uma transação e aguarda o recibo; reversões viram erro com o
+// motivo do require
+func executarTransacaoBlockchain(data []byte, valor *big.Int) (*types.Receipt, error) {
 	// Envia transação
 	fmt.Printf("[DEBUG] Enviando transação...\n")
 	tx, err := enviarTransacaoBlockchain(data, valor)
 	if err != nil {
 		fmt.Printf("[ERRO] Falha ao enviar transação: %v\n", err)
-		return fmt.Errorf("erro ao enviar transação: %v", err)
+		return nil, fmt.Errorf("erro ao enviar transação: %v", err)
 	}
 
 	fmt.Printf("[DEBUG] Transação criada: Hash=%s, Nonce=%d\n", tx.Hash().Hex(), tx.Nonce())
@@ -257,18 +362,19 @@ func comprarPacoteBlockchain() error {
 	receipt, err := aguardarConfirmacaoBlockchain(tx.Hash())
 	if err != nil {
 		fmt.Printf("[ERRO] Falha ao aguardar confirmação: %v\n", err)
-		return err
+		return nil, err
 	}
 
 	fmt.Printf("[DEBUG] Receipt recebido: Status=%d, BlockNumber=%d, GasUsed=%d\n", receipt.Status, receipt.BlockNumber.Uint64(), receipt.GasUsed)
 
 	if receipt.Status == 0 {
 		fmt.Printf("[ERRO] Transação falhou (Status=0)\n")
-		return fmt.Errorf("transação falhou")
+		if motivo := motivoFalhaBlockchain(data, valor, receipt); motivo != nil {
+			return nil, fmt.Errorf("transação falhou: %w", motivo)
+		}
+		return nil, fmt.Errorf("transação falhou")
 	}
-
-	fmt.Println("✓ Pacote comprado com sucesso!")
-	return nil
+	return receipt, nil
 }
 
 func min(a, b int) int {
@@ -284,29 +390,9 @@ func obterInventarioBlockchain() ([]protocolo.Carta, error) {
 		return nil, fmt.Errorf("blockchain não está habilitada")
 	}
 
-	// Prepara chamada
-	data, err := contractABI.Pack("obterInventario", contaBlockchain)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao preparar chamada: %v", err)
-	}
-
-	// Faz chamada
-	msg := ethereum.CallMsg{
-		To:   &contractAddress,
-		Data: data,
-	}
-
-	result, err := blockchainClient.CallContract(context.Background(), msg, nil)
+	ids, err := contratoLeitor.ObterInventario(opcoesLeitura(), contaBlockchain)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao chamar contrato: %v", err)
-	}
-
-	// Desempacota IDs
-	var ids []*big.Int
-	err = contractABI.UnpackIntoInterface(&ids, "obterInventario", result)
-	if err != nil {
-		fmt.Printf("[DEBUG] Erro ao desempacotar IDs: %v\n", err)
-		return nil, fmt.Errorf("erro ao desempacotar: %v", err)
+		return nil, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
 	}
 
 	fmt.Printf("[DEBUG] IDs encontrados na blockchain: %d\n", len(ids))
@@ -331,65 +417,17 @@ func obterInventarioBlockchain() ([]protocolo.Carta, error) {
 
 // obterCartaBlockchain obtém os dados de uma carta usando o mapeamento público 'cartas'
 func obterCartaBlockchain(cartaID *big.Int) (protocolo.Carta, error) {
-	// Usa o mapeamento público 'cartas' que retorna campos individuais
-	data, err := contractABI.Pack("cartas", cartaID)
-	if err != nil {
-		return protocolo.Carta{}, fmt.Errorf("erro ao preparar chamada: %v", err)
-	}
-
-	msg := ethereum.CallMsg{
-		To:   &contractAddress,
-		Data: data,
-	}
-
-	result, err := blockchainClient.CallContract(context.Background(), msg, nil)
+	carta, err := contratoLeitor.Cartas(opcoesLeitura(), cartaID)
 	if err != nil {
-		return protocolo.Carta{}, fmt.Errorf("erro ao chamar contrato: %v", err)
-	}
-
-	// O mapeamento público retorna os campos individualmente (não como struct)
-	// Saída: id, nome, naipe, valor, raridade, timestamp
-	values, err := contractABI.Unpack("cartas", result)
-	if err != nil {
-		return protocolo.Carta{}, fmt.Errorf("erro ao desempacotar: %v", err)
-	}
-
-	if len(values) < 6 {
-		return protocolo.Carta{}, fmt.Errorf("resposta incompleta: esperado 6 campos, recebido %d", len(values))
-	}
-
-	// Extrai os valores individuais
-	id, ok := values[0].(*big.Int)
-	if !ok {
-		return protocolo.Carta{}, fmt.Errorf("tipo inválido para id: %T", values[0])
-	}
-
-	nome, ok := values[1].(string)
-	if !ok {
-		return protocolo.Carta{}, fmt.Errorf("tipo inválido para nome: %T", values[1])
-	}
-
-	naipe, ok := values[2].(string)
-	if !ok {
-		return protocolo.Carta{}, fmt.Errorf("tipo inválido para naipe: %T", values[2])
-	}
-
-	valor, ok := values[3].(*big.Int)
-	if !ok {
-		return protocolo.Carta{}, fmt.Errorf("tipo inválido para valor: %T", values[3])
-	}
-
-	raridade, ok := values[4].(string)
-	if !ok {
-		return protocolo.Carta{}, fmt.Errorf("tipo inválido para raridade: %T", values[4])
+		return protocolo.Carta{}, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
 	}
 
 	return protocolo.Carta{
-		ID:       id.String(),
-		Nome:     nome,
-		Naipe:    naipe,
-		Valor:    int(valor.Int64()),
-		Raridade: raridade,
+		ID:       carta.Id.String(),
+		Nome:     carta.Nome,
+		Naipe:    carta.Naipe,
+		Valor:    int(carta.Valor.Int64()),
+		Raridade: carta.Raridade,
 	}, nil
 }
 
@@ -412,7 +450,7 @@ func enviarTransacaoBlockchain(data []byte, valor *big.Int) (*types.Transaction,
 	}
 	fmt.Printf("[DEBUG] GasPrice sugerido: %s wei\n", gasPrice.String())
 
-	chainID, err := blockchainClient.NetworkID(context.Background())
+	chainID, err := blockchainClient.ChainID(context.Background())
 	if err != nil {
 		fmt.Printf("[ERRO] Falha ao obter chainID: %v\n", err)
 		return nil, err
@@ -441,6 +479,23 @@ func enviarTransacaoBlockchain(data []byte, valor *big.Int) (*types.Transaction,
 	return txAssinada, nil
 }
 
+// motivoFalhaBlockchain repete a chamada de uma transação revertida no estado anterior ao bloco dela
+// para recuperar o motivo do require (nil se o nó não devolver o motivo)
+func motivoFalhaBlockchain(data []byte, valor *big.Int, receipt *types.Receipt) *contrato.ErroContrato {
+	msg := ethereum.CallMsg{From: contaBlockchain, To: &contractAddress, Value: valor, Data: data}
+	anterior := new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+	_, err := blockchainClient.CallContract(context.Background(), msg, anterior)
+	if motivo, ok := contrato.Revertido(err); ok {
+		return motivo
+	}
+	return nil
+}
+
+// opcoesLeitura são as opções das consultas pelos bindings (bloco mais recente)
+func opcoesLeitura() *bind.CallOpts {
+	return &bind.CallOpts{Context: context.Background()}
+}
+
 // aguardarConfirmacaoBlockchain aguarda confirmação
 func aguardarConfirmacaoBlockchain(txHash common.Hash) (*types.Receipt, error) {
 	for i := 0; i < 30; i++ {
@@ -469,7 +524,7 @@ func CriarPropostaTrocaBlockchain(oponenteAddressHex string, minhaCartaID string
 	cartaDesejadaBig.SetString(cartaDesejadaID, 10)
 
 	// 2. Preparar Dados (Pack)
-	data, err := contractABI.Pack("criarPropostaTroca", oponenteAddr, minhaCartaBig, cartaDesejadaBig)
+	data, err := contrato.DadosCriarPropostaTroca(oponenteAddr, minhaCartaBig, cartaDesejadaBig)
 	if err != nil {
 		return "", fmt.Errorf("erro ao empacotar dados: %v", err)
 	}
@@ -488,6 +543,9 @@ func CriarPropostaTrocaBlockchain(oponenteAddressHex string, minhaCartaID string
 	}
 
 	if receipt.Status == 0 {
+		if motivo := motivoFalhaBlockchain(data, big.NewInt(0), receipt); motivo != nil {
+			return "", fmt.Errorf("transação falhou na blockchain: %w", motivo)
+		}
 		return "", fmt.Errorf("transação falhou na blockchain")
 	}
 
@@ -506,16 +564,8 @@ func CriarPropostaTrocaBlockchain(oponenteAddressHex string, minhaCartaID string
 	return "ID_DESCONHECIDO", nil
 }
 
-// PropostaTrocaStruct representa a estrutura de uma proposta de troca
-type PropostaTrocaStruct struct {
-	Jogador1      common.Address
-	Jogador2      common.Address
-	CartaJogador1 *big.Int
-	CartaJogador2 *big.Int
-	Aceita        bool
-	Executada     bool
-	Timestamp     *big.Int
-}
+// PropostaTrocaStruct representa a estrutura de uma proposta de troca (struct do contrato)
+type PropostaTrocaStruct = contrato.PropostaTroca
 
 // obterPropostaTrocaBlockchain consulta uma proposta de troca
 func obterPropostaTrocaBlockchain(propostaID string) (*PropostaTrocaStruct, error) {
@@ -526,116 +576,10 @@ func obterPropostaTrocaBlockchain(propostaID string) (*PropostaTrocaStruct, erro
 	propIDBig := new(big.Int)
 	propIDBig.SetString(propostaID, 10)
 
-	// Prepara chamada
-	data, err := contractABI.Pack("obterPropostaTroca", propIDBig)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao preparar chamada: %v", err)
-	}
-
-	// Faz chamada
-	msg := ethereum.CallMsg{
-		To:   &contractAddress,
-		Data: data,
-	}
-
-	result, err := blockchainClient.CallContract(context.Background(), msg, nil)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao chamar contrato: %v", err)
-	}
-
-	// Desempacota resultado manualmente
-	// Quando Solidity retorna um struct, ele vem como uma tupla
-	values, err := contractABI.Unpack("obterPropostaTroca", result)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao desempacotar: %v", err)
-	}
-
-	fmt.Printf("[DEBUG] Valores desempacotados: %d elementos, tipo: %T\n", len(values), values)
-
-	var proposta PropostaTrocaStruct
-
-	// O struct Solidity vem como uma tupla aninhada (values[0] é o struct)
-	if len(values) == 1 {
-		// Usa reflection para extrair os campos do struct anônimo
-		v := reflect.ValueOf(values[0])
-		if v.Kind() == reflect.Struct {
-			// Extrai cada campo pelo índice
-			if v.NumField() >= 7 {
-				// Campo 0: Jogador1 (address)
-				if f := v.Field(0); f.CanInterface() {
-					if addr, ok := f.Interface().(common.Address); ok {
-						proposta.Jogador1 = addr
-					}
-				}
-				// Campo 1: Jogador2 (address)
-				if f := v.Field(1); f.CanInterface() {
-					if addr, ok := f.Interface().(common.Address); ok {
-						proposta.Jogador2 = addr
-					}
-				}
-				// Campo 2: CartaJogador1 (uint256)
-				if f := v.Field(2); f.CanInterface() {
-					if val, ok := f.Interface().(*big.Int); ok {
-						proposta.CartaJogador1 = val
-					}
-				}
-				// Campo 3: CartaJogador2 (uint256)
-				if f := v.Field(3); f.CanInterface() {
-					if val, ok := f.Interface().(*big.Int); ok {
-						proposta.CartaJogador2 = val
-					}
-				}
-				// Campo 4: Aceita (bool)
-				if f := v.Field(4); f.CanInterface() {
-					if val, ok := f.Interface().(bool); ok {
-						proposta.Aceita = val
-					}
-				}
-				// Campo 5: Executada (bool)
-				if f := v.Field(5); f.CanInterface() {
-					if val, ok := f.Interface().(bool); ok {
-						proposta.Executada = val
-					}
-				}
-				// Campo 6: Timestamp (uint256)
-				if f := v.Field(6); f.CanInterface() {
-					if val, ok := f.Interface().(*big.Int); ok {
-						proposta.Timestamp = val
-					}
-				}
-			} else {
-				return nil, fmt.Errorf("struct tem menos campos que o esperado: %d", v.NumField())
-			}
-		} else {
-			return nil, fmt.Errorf("tipo inesperado para proposta: %T (kind: %v)", values[0], v.Kind())
-		}
-	} else if len(values) >= 7 {
-		// Valores vieram separados
-		if addr, ok := values[0].(common.Address); ok {
-			proposta.Jogador1 = addr
-		}
-		if addr, ok := values[1].(common.Address); ok {
-			proposta.Jogador2 = addr
-		}
-		if val, ok := values[2].(*big.Int); ok {
-			proposta.CartaJogador1 = val
-		}
-		if val, ok := values[3].(*big.Int); ok {
-			proposta.CartaJogador2 = val
-		}
-		if val, ok := values[4].(bool); ok {
-			proposta.Aceita = val
-		}
-		if val, ok := values[5].(bool); ok {
-			proposta.Executada = val
-		}
-		if val, ok := values[6].(*big.Int); ok {
-			proposta.Timestamp = val
-		}
-	} else {
-		return nil, fmt.Errorf("resposta incompleta: esperado 1 ou 7 campos, recebido %d", len(values))
+	proposta, err := contratoLeitor.ObterPropostaTroca(opcoesLeitura(), propIDBig)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
 	}
-
 	return &proposta, nil
 }
 
@@ -645,39 +589,11 @@ func obterProprietarioCartaBlockchain(cartaID *big.Int) (common.Address, error)
 		return common.Address{}, fmt.Errorf("blockchain não habilitada")
 	}
 
-	// Prepara chamada ao mapeamento público 'proprietario'
-	data, err := contractABI.Pack("proprietario", cartaID)
-	if err != nil {
-		return common.Address{}, fmt.Errorf("erro ao preparar chamada: %v", err)
-	}
-
-	// Faz chamada
-	msg := ethereum.CallMsg{
-		To:   &contractAddress,
-		Data: data,
-	}
-
-	result, err := blockchainClient.CallContract(context.Background(), msg, nil)
+	proprietario, err := contratoLeitor.Proprietario(opcoesLeitura(), cartaID)
 	if err != nil {
-		return common.Address{}, fmt.Errorf("erro ao chamar contrato: %v", err)
+		return common.Address{}, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
 	}
-
-	// Desempacota resultado (address) - mapeamento público retorna diretamente
-	values, err := contractABI.Unpack("proprietario", result)
-	if err != nil {
-		return common.Address{}, fmt.Errorf("erro ao desempacotar: %v", err)
-	}
-
-	if len(values) == 0 {
-		return common.Address{}, fmt.Errorf("resposta vazia")
-	}
-
-	// O mapeamento público retorna o valor diretamente
-	if addr, ok := values[0].(common.Address); ok {
-		return addr, nil
-	}
-
-	return common.Address{}, fmt.Errorf("tipo inesperado: %T", values[0])
+	return proprietario, nil
 }
 
 // AceitarPropostaTrocaBlockchain aceita uma proposta existente
@@ -704,9 +620,9 @@ func AceitarPropostaTrocaBlockchain(propostaID string) error {
 			proposta.Jogador2.Hex(), contaBlockchain.Hex())
 	}
 
-	// Verifica se já foi executada
-	if proposta.Executada {
-		return fmt.Errorf("proposta já foi executada")
+	// Verifica se ainda pode ser aceita (executada, cancelada, recusada ou expirada)
+	if estado := proposta.Estado(time.Now()); estado != contrato.PropostaPendente {
+		return fmt.Errorf("proposta %s está %s", propostaID, estado)
 	}
 
 	fmt.Printf("[DEBUG] Proposta válida encontrada:\n")
@@ -749,7 +665,7 @@ func AceitarPropostaTrocaBlockchain(propostaID string) error {
 	propIDBig := new(big.Int)
 	propIDBig.SetString(propostaID, 10)
 
-	data, err := contractABI.Pack("aceitarPropostaTroca", propIDBig)
+	data, err := contrato.DadosAceitarPropostaTroca(propIDBig)
 	if err != nil {
 		return fmt.Errorf("erro ao empacotar: %v", err)
 	}
@@ -797,6 +713,10 @@ func AceitarPropostaTrocaBlockchain(propostaID string) error {
 			fmt.Printf("[DEBUG] Proposta após falha - Executada: %v, Aceita: %v\n", propostaAposFalha.Executada, propostaAposFalha.Aceita)
 		}
 
+		if motivo := motivoFalhaBlockchain(data, big.NewInt(0), receipt); motivo != nil {
+			return fmt.Errorf("transação de aceite falhou: %w", motivo)
+		}
+
 		return fmt.Errorf("transação de aceite falhou (Status=0). Possíveis causas:\n" +
 			"  - Você não é o destinatário da proposta\n" +
 			"  - A proposta já foi executada\n" +
@@ -808,3 +728,231 @@ func AceitarPropostaTrocaBlockchain(propostaID string) error {
 	fmt.Printf("[DEBUG] Transação confirmada com sucesso!\n")
 	return nil
 }
+
+// listarPropostasBlockchain devolve os IDs das propostas recebidas e enviadas pela carteira
+func listarPropostasBlockchain() (recebidas, enviadas []*big.Int, err error) {
+	if !blockchainEnabled {
+		return nil, nil, fmt.Errorf("blockchain não habilitada")
+	}
+
+	recebidas, err = contratoLeitor.ObterPropostasRecebidas(opcoesLeitura(), contaBlockchain)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	enviadas, err = contratoLeitor.ObterPropostasEnviadas(opcoesLeitura(), contaBlockchain)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	return recebidas, enviadas, nil
+}
+
+// CancelarPropostaTrocaBlockchain desiste de uma proposta criada por esta carteira
+func CancelarPropostaTrocaBlockchain(propostaID string) error {
+	return executarComIDBlockchain(propostaID, contrato.DadosCancelarPropostaTroca)
+}
+
+// RecusarPropostaTrocaBlockchain recusa uma proposta recebida por esta carteira
+func RecusarPropostaTrocaBlockchain(propostaID string) error {
+	return executarComIDBlockchain(propostaID, contrato.DadosRecusarPropostaTroca)
+}
+
+// executarComIDBlockchain envia uma chamada sem valor cujo único argumento é um ID (proposta, anúncio)
+func executarComIDBlockchain(id string, dados func(*big.Int) ([]byte, error)) error {
+	if !blockchainEnabled || chavePrivada == nil {
+		return fmt.Errorf("blockchain não habilitada")
+	}
+
+	idBig, ok := new(big.Int).SetString(id, 10)
+	if !ok {
+		return fmt.Errorf("ID inválido: %s", id)
+	}
+	data, err := dados(idBig)
+	if err != nil {
+		return fmt.Errorf("erro ao empacotar: %v", err)
+	}
+	_, err = executarTransacaoBlockchain(data, big.NewInt(0))
+	return err
+}
+
+// weiPorEth converte os preços digitados em ETH (fictício) para wei
+var weiPorEth = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+// parsearEth converte um valor em ETH ("0.5", "2") para wei
+func parsearEth(valor string) (*big.Int, error) {
+	eth, ok := new(big.Rat).SetString(valor)
+	if !ok || eth.Sign() <= 0 {
+		return nil, fmt.Errorf("valor inválido: %s", valor)
+	}
+	wei := new(big.Rat).Mul(eth, new(big.Rat).SetInt(weiPorEth))
+	if !wei.IsInt() {
+		return nil, fmt.Errorf("valor com mais de 18 casas decimais: %s", valor)
+	}
+	return wei.Num(), nil
+}
+
+// formatarEth mostra um valor em wei como ETH
+func formatarEth(wei *big.Int) string {
+	return strings.TrimRight(strings.TrimRight(new(big.Rat).SetFrac(wei, weiPorEth).FloatString(6), "0"), ".")
+}
+
+// AnuncioMercadoBlockchain é um anúncio ativo do mercado com o ID que o contrato lhe deu
+type AnuncioMercadoBlockchain struct {
+	ID *big.Int
+	contrato.Anuncio
+}
+
+// listarAnunciosBlockchain lê os anúncios ativos do mercado, do mais antigo para o mais recente
+func listarAnunciosBlockchain() ([]AnuncioMercadoBlockchain, error) {
+	if !blockchainEnabled {
+		return nil, fmt.Errorf("blockchain não habilitada")
+	}
+
+	ids, err := contratoLeitor.ObterAnunciosAtivos(opcoesLeitura())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Cmp(ids[j]) < 0 })
+
+	anuncios := make([]AnuncioMercadoBlockchain, 0, len(ids))
+	for _, id := range ids {
+		anuncio, err := contratoLeitor.ObterAnuncio(opcoesLeitura(), id)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+		}
+		if anuncio.Ativo {
+			anuncios = append(anuncios, AnuncioMercadoBlockchain{ID: id, Anuncio: anuncio})
+		}
+	}
+	return anuncios, nil
+}
+
+// saldoVendasBlockchain é o valor das vendas da carteira que ainda não foi sacado
+func saldoVendasBlockchain() (*big.Int, error) {
+	saldo, err := contratoLeitor.SaldoVendas(opcoesLeitura(), contaBlockchain)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	return saldo, nil
+}
+
+// AnunciarCartaBlockchain coloca uma carta da carteira à venda e retorna o ID do anúncio.
+// Até a venda (ou /retirar), a carta fica sob custódia do contrato e some do inventário.
+func AnunciarCartaBlockchain(cartaID string, preco *big.Int) (string, error) {
+	if !blockchainEnabled || chavePrivada == nil {
+		return "", fmt.Errorf("blockchain não habilitada")
+	}
+
+	cartaBig, ok := new(big.Int).SetString(cartaID, 10)
+	if !ok {
+		return "", fmt.Errorf("ID de carta inválido: %s", cartaID)
+	}
+	data, err := contrato.DadosAnunciarCarta(cartaBig, preco)
+	if err != nil {
+		return "", fmt.Errorf("erro ao empacotar: %v", err)
+	}
+	receipt, err := executarTransacaoBlockchain(data, big.NewInt(0))
+	if err != nil {
+		return "", err
+	}
+
+	filtro, err := contrato.NewGameEconomyFilterer(contractAddress, nil)
+	if err != nil {
+		return "", fmt.Errorf("erro ao criar bindings do contrato: %v", err)
+	}
+	for _, vLog := range receipt.Logs {
+		if vLog.Address != contractAddress {
+			continue
+		}
+		if evento, err := filtro.ParseCartaAnunciada(*vLog); err == nil {
+			return evento.AnuncioId.String(), nil
+		}
+	}
+	return "ID_DESCONHECIDO", nil
+}
+
+// ComprarCartaAnunciadaBlockchain compra a carta de um anúncio pagando o preço anunciado
+func ComprarCartaAnunciadaBlockchain(anuncioID string) error {
+	if !blockchainEnabled || chavePrivada == nil {
+		return fmt.Errorf("blockchain não habilitada")
+	}
+
+	idBig, ok := new(big.Int).SetString(anuncioID, 10)
+	if !ok {
+		return fmt.Errorf("ID de anúncio inválido: %s", anuncioID)
+	}
+	anuncio, err := contratoLeitor.ObterAnuncio(opcoesLeitura(), idBig)
+	if err != nil {
+		return fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	if !anuncio.Ativo {
+		return contrato.ErrAnuncioInativo
+	}
+	if anuncio.Vendedor == contaBlockchain {
+		return contrato.ErrCompraProprioAnuncio
+	}
+
+	data, err := contrato.DadosComprarCartaAnunciada(idBig)
+	if err != nil {
+		return fmt.Errorf("erro ao empacotar: %v", err)
+	}
+	fmt.Printf("[BLOCKCHAIN] Comprando carta %s por %s ETH...\n", anuncio.CartaId, formatarEth(anuncio.Preco))
+	_, err = executarTransacaoBlockchain(data, anuncio.Preco)
+	return err
+}
+
+// RetirarAnuncioBlockchain encerra um anúncio da carteira e recebe a carta de volta
+func RetirarAnuncioBlockchain(anuncioID string) error {
+	return executarComIDBlockchain(anuncioID, contrato.DadosRetirarAnuncio)
+}
+
+// SacarVendasBlockchain transfere para a carteira o valor acumulado das vendas
+func SacarVendasBlockchain() error {
+	if !blockchainEnabled || chavePrivada == nil {
+		return fmt.Errorf("blockchain não habilitada")
+	}
+	data, err := contrato.DadosSacarVendas()
+	if err != nil {
+		return fmt.Errorf("erro ao empacotar: %v", err)
+	}
+	_, err = executarTransacaoBlockchain(data, big.NewInt(0))
+	return err
+}
+
+// FundirCartasBlockchain queima as cartas da carteira (todas de mesmo nome e raridade) e
+// retorna a carta da raridade seguinte criada no lugar delas
+func FundirCartasBlockchain(cartaIDs []string) (protocolo.Carta, error) {
+	if !blockchainEnabled || chavePrivada == nil {
+		return protocolo.Carta{}, fmt.Errorf("blockchain não habilitada")
+	}
+
+	ids := make([]*big.Int, len(cartaIDs))
+	for i, id := range cartaIDs {
+		idBig, ok := new(big.Int).SetString(id, 10)
+		if !ok {
+			return protocolo.Carta{}, fmt.Errorf("ID de carta inválido: %s", id)
+		}
+		ids[i] = idBig
+	}
+	data, err := contrato.DadosFundirCartas(ids)
+	if err != nil {
+		return protocolo.Carta{}, fmt.Errorf("erro ao empacotar: %v", err)
+	}
+	receipt, err := executarTransacaoBlockchain(data, big.NewInt(0))
+	if err != nil {
+		return protocolo.Carta{}, err
+	}
+
+	filtro, err := contrato.NewGameEconomyFilterer(contractAddress, nil)
+	if err != nil {
+		return protocolo.Carta{}, fmt.Errorf("erro ao criar bindings do contrato: %v", err)
+	}
+	for _, vLog := range receipt.Logs {
+		if vLog.Address != contractAddress {
+			continue
+		}
+		if evento, err := filtro.ParseCartasFundidas(*vLog); err == nil {
+			return obterCartaBlockchain(evento.NovaCarta)
+		}
+	}
+	return protocolo.Carta{}, fmt.Errorf("carta criada na fusão não encontrada nos logs")
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math/big"
 	"net"
 	"os"
 	"strconv"
@@ -12,8 +13,11 @@ import (
 	"time"
 
 	"jogodistribuido/protocolo"
+	"jogodistribuido/servidor/blockchain/contrato"
+	"jogodistribuido/servidor/seguranca"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/google/uuid"
 )
 
@@ -26,6 +30,11 @@ var (
 	oponenteNome  string
 	meuInventario []protocolo.Carta
 	turnoDeQuem   string // NOVO: Armazena o ID de quem tem o turno
+	meuRating     int    // Rating (ELO) informado no LOGIN_OK e atualizado no FIM_DE_JOGO
+
+	// Modo espectador: partida assistida sem participar
+	salaEspectada       string
+	jogadoresEspectados map[string]string // ID -> nome dos jogadores da sala assistida
 )
 
 func main() {
@@ -96,22 +105,15 @@ func main() {
 
 	fmt.Printf("\nConectando ao broker MQTT: %s\n", brokerAddr)
 
+	brokerAtual = brokerAddr
 	if err := conectarMQTT(brokerAddr); err != nil {
 		log.Fatalf("Erro ao conectar ao MQTT: %v", err)
 	}
 
-	// --- LÓGICA DE LOGIN CORRIGIDA ---
-	if err := fazerLogin(); err != nil {
-		log.Fatalf("Erro no processo de login: %v", err)
-	}
-	// --- FIM DA CORREÇÃO ---
-
-	fmt.Printf("\nBem-vindo, %s! (Seu ID: %s)\n", meuNome, meuID)
-
 	// Tenta inicializar blockchain (opcional)
 	fmt.Println("\n=== Configuração Blockchain (Opcional) ===")
 	fmt.Println("Deseja conectar sua carteira blockchain?")
-	fmt.Println("(Necessário para comprar/trocar cartas)")
+	fmt.Println("(Necessário para comprar/trocar cartas; o login passa a ser assinado pela carteira)")
 	fmt.Print("(s/n): ")
 	scanner.Scan()
 	resposta := strings.ToLower(strings.TrimSpace(scanner.Text()))
@@ -138,8 +140,33 @@ func main() {
 		blockchainEnabled = false // Garante que está desabilitado
 	}
 
-	fmt.Println("\nEntrando na fila de matchmaking...")
-	entrarNaFila()
+	// Se houver uma sessão salva (cliente fechado ou conexão perdida), tenta voltar a ela
+	retomou := false
+	if carregarSessao() {
+		if err := retomarSessao(); err != nil {
+			fmt.Printf("[SESSAO] %v. Fazendo login normalmente.\n", err)
+		} else {
+			retomou = true
+		}
+	}
+
+	// --- LÓGICA DE LOGIN CORRIGIDA ---
+	if !retomou {
+		if err := fazerLogin(); err != nil {
+			log.Fatalf("Erro no processo de login: %v", err)
+		}
+	}
+	// --- FIM DA CORREÇÃO ---
+	go enviarPresencaPeriodicamente()
+
+	fmt.Printf("\nBem-vindo, %s! (Seu ID: %s)\n", meuNome, meuID)
+
+	if salaAtual == "" {
+		fmt.Println("\nEntrando na fila de matchmaking...")
+		entrarNaFila()
+	} else {
+		fmt.Printf("\nRetomando a partida na sala %s.\n", salaAtual)
+	}
 
 	mostrarAjuda()
 
@@ -177,6 +204,17 @@ func conectarMQTT(broker string) error {
 				topicoPartida := fmt.Sprintf("partidas/%s/eventos", salaAtual)
 				client.Subscribe(topicoPartida, 0, handleEventoPartida)
 			}
+			if salaEspectada != "" {
+				client.Subscribe(fmt.Sprintf("partidas/%s/eventos", salaEspectada), 0, handleEventoPartida)
+			}
+			// Reassocia a sessão e recebe o estado atual da partida
+			if tokenSessao != "" {
+				go func() {
+					if err := retomarSessao(); err != nil {
+						fmt.Printf("\n[SESSAO] %v\n> ", err)
+					}
+				}()
+			}
 		}
 	})
 
@@ -205,24 +243,57 @@ func fazerLogin() error {
 		return fmt.Errorf("falha ao se inscrever no tópico de resposta: %v", token.Error())
 	}
 
-	// Publica a mensagem de login num tópico que o servidor ouve
+	// Publica a mensagem de login num tópico que o servidor ouve. Com a carteira carregada, o
+	// servidor responde com um desafio que precisa ser assinado antes do LOGIN_OK.
 	dadosLogin := protocolo.DadosLogin{Nome: meuNome}
-	msgLogin := protocolo.Mensagem{Comando: "LOGIN", Dados: mustJSON(dadosLogin)}
-	payloadLogin, _ := json.Marshal(msgLogin)
-
-	// O tópico de login agora inclui o ID temporário
+	if blockchainEnabled && chavePrivada != nil {
+		dadosLogin.Endereco = contaBlockchain.Hex()
+	}
 	loginTopic := fmt.Sprintf("clientes/%s/login", tempID)
-	mqttClient.Publish(loginTopic, 1, false, payloadLogin)
+	publicarLogin := func(dados protocolo.DadosLogin) {
+		msgLogin := protocolo.Mensagem{Comando: "LOGIN", Dados: mustJSON(dados)}
+		payloadLogin, _ := json.Marshal(msgLogin)
+		mqttClient.Publish(loginTopic, 1, false, payloadLogin)
+	}
+	publicarLogin(dadosLogin)
 
 	// Aguarda a resposta por um tempo limitado (sem time.Sleep!)
-	select {
-	case resp := <-loginResponseChan:
-		if resp.Comando == "LOGIN_OK" {
-			var dados map[string]string
+	for {
+		var resp protocolo.Mensagem
+		select {
+		case resp = <-loginResponseChan:
+		case <-time.After(5 * time.Second): // Espera por 5 segundos
+			return fmt.Errorf("não foi possível obter ID do servidor (timeout)")
+		}
+
+		switch resp.Comando {
+		case "DESAFIO_LOGIN":
+			var desafio protocolo.DadosDesafioLogin
+			json.Unmarshal(resp.Dados, &desafio)
+			assinatura, err := seguranca.AssinarMensagem(desafio.Mensagem, chavePrivada.PrivateKey)
+			if err != nil {
+				return fmt.Errorf("falha ao assinar o desafio de login: %v", err)
+			}
+			fmt.Printf("[LOGIN] Desafio assinado com a carteira %s.\n", contaBlockchain.Hex())
+			dadosLogin.Nonce = desafio.Nonce
+			dadosLogin.Assinatura = assinatura
+			publicarLogin(dadosLogin)
+			continue
+		case "ERRO":
+			var erro protocolo.DadosErro
+			json.Unmarshal(resp.Dados, &erro)
+			return fmt.Errorf("login recusado: %s", erro.Mensagem)
+		case "LOGIN_OK":
+			var dados protocolo.DadosLoginOK
 			json.Unmarshal(resp.Dados, &dados)
-			meuID = dados["cliente_id"] // Guarda o ID permanente recebido do servidor
-			servidor := dados["servidor"]
-			fmt.Printf("\n[LOGIN] Conectado ao servidor %s (ID: %s)\n", servidor, meuID)
+			meuID = dados.ClienteID // Guarda o ID permanente recebido do servidor
+			tokenSessao = dados.TokenSessao
+			salvarSessao()
+			meuRating = dados.Rating
+			fmt.Printf("\n[LOGIN] Conectado ao servidor %s (ID: %s) | Rating: %d\n", dados.Servidor, meuID, meuRating)
+			if dados.Perfil != nil {
+				restaurarPerfil(dados.Perfil)
+			}
 
 			// Limpa a inscrição temporária e inscreve-se na permanente
 			mqttClient.Unsubscribe(responseTopic)
@@ -233,15 +304,48 @@ func fazerLogin() error {
 			return nil
 		}
 		return fmt.Errorf("resposta de login inesperada: %s", resp.Comando)
-	case <-time.After(5 * time.Second): // Espera por 5 segundos
-		return fmt.Errorf("não foi possível obter ID do servidor (timeout)")
 	}
 }
 
+// restaurarPerfil carrega o inventário e mostra o histórico salvos no servidor para um jogador que está retornando.
+func restaurarPerfil(perfil *protocolo.DadosPerfilJogador) {
+	meuInventario = perfil.Inventario
+	fmt.Printf("[LOGIN] Bem-vindo de volta! Você tem %d cartas salvas.\n", len(perfil.Inventario))
+
+	if len(perfil.Historico) == 0 {
+		return
+	}
+	vitorias := 0
+	for _, p := range perfil.Historico {
+		if p.VencedorNome == meuNome {
+			vitorias++
+		}
+	}
+	fmt.Printf("[LOGIN] Histórico: %d partidas, %d vitórias.\n", len(perfil.Historico), vitorias)
+	// Mostra apenas as partidas mais recentes
+	inicio := len(perfil.Historico) - 5
+	if inicio < 0 {
+		inicio = 0
+	}
+	for _, p := range perfil.Historico[inicio:] {
+		resultado := "Derrota"
+		if p.VencedorNome == meuNome {
+			resultado = "Vitória"
+		} else if p.VencedorNome == "EMPATE" {
+			resultado = "Empate"
+		}
+		fmt.Printf("  %s - vs %s: %s\n", time.Unix(p.Timestamp, 0).Format("02/01 15:04"), p.OponenteNome, resultado)
+	}
+}
+
+// regrasFila e formatoFila são a variante de regras e o formato pedidos ao entrar na fila
+// (vazios = os padrões do servidor)
+var regrasFila, formatoFila string
+
 func entrarNaFila() {
 	fmt.Printf("[DEBUG] entrarNaFila() chamado - meuID=%s\n", meuID)
 
-	dados := map[string]string{"cliente_id": meuID}
+	dados := map[string]string{"cliente_id": meuID, "regras": regrasFila, "formato": formatoFila}
 	payload, _ := json.Marshal(dados)
 
 	fmt.Printf("[DEBUG] Payload: %s\n", string(payload))
@@ -278,11 +382,15 @@ func handleMensagemServidor(client mqtt.Client, msg mqtt.Message) {
 func processarMensagemServidor(msg protocolo.Mensagem) {
 	switch msg.Comando {
 	case "LOGIN_OK":
-		var dados map[string]string
+		var dados protocolo.DadosLoginOK
 		json.Unmarshal(msg.Dados, &dados)
-		meuID = dados["cliente_id"]
-		servidor := dados["servidor"]
-		fmt.Printf("\n[LOGIN] Conectado ao servidor %s (ID: %s)\n", servidor, meuID)
+		meuID = dados.ClienteID
+		tokenSessao = dados.TokenSessao
+		meuRating = dados.Rating
+		fmt.Printf("\n[LOGIN] Conectado ao servidor %s (ID: %s) | Rating: %d\n", dados.Servidor, meuID, meuRating)
+		if dados.Perfil != nil {
+			restaurarPerfil(dados.Perfil)
+		}
 
 		// Agora subscreve ao tópico correto com o ID
 		topico := fmt.Sprintf("clientes/%s/eventos", meuID)
@@ -343,6 +451,41 @@ func processarMensagemServidor(msg protocolo.Mensagem) {
 			log.Printf("[SYNC] AVISO: Inventário vazio! O jogador precisa comprar cartas primeiro.")
 		}
 
+	case "ESPECTANDO":
+		var dados protocolo.DadosEspectando
+		if err := json.Unmarshal(msg.Dados, &dados); err != nil {
+			fmt.Printf("[ERRO] Falha ao decodificar ESPECTANDO: %v\n", err)
+			return
+		}
+		salaEspectada = dados.SalaID
+		jogadoresEspectados = dados.Jogadores
+		turnoDeQuem = dados.Estado.TurnoDe
+
+		// Assina apenas o tópico de eventos: espectadores não publicam em partidas/{salaID}/comandos
+		topicoPartida := fmt.Sprintf("partidas/%s/eventos", salaEspectada)
+		if token := mqttClient.Subscribe(topicoPartida, 0, handleEventoPartida); token.Wait() && token.Error() != nil {
+			log.Printf("[ERRO] Erro ao se inscrever no tópico da partida: %v", token.Error())
+		}
+
+		nomes := make([]string, 0, len(dados.Jogadores))
+		for _, nome := range dados.Jogadores {
+			nomes = append(nomes, nome)
+		}
+		fmt.Printf("\n[ESPECTADOR] Assistindo %s (Sala: %s)\n", strings.Join(nomes, " vs "), salaEspectada)
+		fmt.Printf("Regras: %s | Espectadores: %d\n", dados.Regras, dados.Estado.Espectadores)
+		fmt.Printf("--- RODADA %d ---\n%s\n", dados.Estado.NumeroRodada, dados.Estado.MensagemDoTurno)
+		if len(dados.Estado.UltimaJogada) > 0 {
+			fmt.Println("\nCartas na mesa:")
+			for nome, carta := range dados.Estado.UltimaJogada {
+				fmt.Printf("  %s: %s %s (Poder: %d)\n", nome, carta.Nome, carta.Naipe, carta.Valor)
+			}
+		}
+		for nome, qtd := range dados.Estado.ContagemCartas {
+			fmt.Printf("  %s: %d cartas | %d pontos\n", nome, qtd, dados.Estado.PontosRodada[nome])
+		}
+		fmt.Println("Use /parar-espectar para deixar de assistir.")
+		fmt.Print("> ")
+
 	case "TROCA_CONCLUIDA":
 		var resp protocolo.TrocarCartasResp
 		json.Unmarshal(msg.Dados, &resp)
@@ -355,6 +498,25 @@ func processarMensagemServidor(msg protocolo.Mensagem) {
 		mostrarCartas() // Mostra o inventário atualizado
 		fmt.Print("> ")
 
+	case "FUSAO_CONCLUIDA":
+		var dados protocolo.DadosCartasFundidas
+		json.Unmarshal(msg.Dados, &dados)
+		meuInventario = dados.Inventario
+		fmt.Printf("\n[FUSAO] %d cartas fundidas! Você recebeu %s %s - Poder: %d (Raridade: %s) [ID: %s]\n",
+			len(dados.Queimadas), dados.NovaCarta.Nome, dados.NovaCarta.Naipe, dados.NovaCarta.Valor, dados.NovaCarta.Raridade, dados.NovaCarta.ID)
+		mostrarCartas()
+		fmt.Print("> ")
+
+	case "INVENTARIO_ATUALIZADO":
+		// Mudança vista pelo servidor na blockchain (ex: compra ou troca feita por este cliente)
+		var dados protocolo.DadosInventarioAtualizado
+		json.Unmarshal(msg.Dados, &dados)
+		meuInventario = dados.Cartas
+		log.Printf("[INVENTARIO] Atualizado no bloco %d (%s): %d cartas", dados.Bloco, strings.Join(dados.Eventos, ", "), len(meuInventario))
+		fmt.Printf("\n[INVENTARIO] Suas cartas mudaram na blockchain (%s).\n", strings.Join(dados.Eventos, ", "))
+		mostrarCartas()
+		fmt.Print("> ")
+
 	case "PACOTE_RESULTADO":
 		var dados protocolo.ComprarPacoteResp
 		json.Unmarshal(msg.Dados, &dados)
@@ -484,13 +646,122 @@ func processarMensagemServidor(msg protocolo.Mensagem) {
 		if turnoDeQuem == meuID {
 			quemJoga = "Você"
 		}
-		fmt.Printf("(Aguardando jogada de %s)\n-------------------\n> ", quemJoga)
+		fmt.Printf("(Aguardando jogada de %s)%s\n-------------------\n> ", quemJoga, textoTempoRestante(dados.TempoRestante))
+
+	case "RODADA_FIM":
+		// Jogadores em outro servidor recebem os eventos da partida pelo tópico pessoal
+		var dados protocolo.DadosFimDeRodada
+		json.Unmarshal(msg.Dados, &dados)
+		exibirFimDeRodada(dados)
+
+	case "FIM_DE_JOGO":
+		var dados protocolo.DadosFimDeJogo
+		json.Unmarshal(msg.Dados, &dados)
+		exibirFimDeJogo(dados)
+
+	case "ASSINAR_RESULTADO":
+		var dados protocolo.DadosAssinarResultado
+		json.Unmarshal(msg.Dados, &dados)
+		assinarResultadoPartida(dados)
 
 	default:
 		// Comando não reconhecido, ignora
 	}
 }
 
+// exibirFimDeRodada mostra o resultado de uma rodada e o placar de rodadas da partida
+func exibirFimDeRodada(dados protocolo.DadosFimDeRodada) {
+	fmt.Printf("\n=== FIM DA RODADA %d ===\n", dados.NumeroRodada)
+	if dados.VencedorRodada == "EMPATE" {
+		fmt.Println("Rodada empatada.")
+	} else {
+		fmt.Printf("🎯 Vencedor da rodada: %s\n", dados.VencedorRodada)
+	}
+	fmt.Printf("Placar da partida (%s):\n", dados.Formato)
+	for nome, rodadas := range dados.PontosPartida {
+		fmt.Printf("  %s: %d rodada(s)\n", nome, rodadas)
+	}
+	fmt.Print("> ")
+}
+
+// exibirFimDeJogo mostra o vencedor da partida, o placar final e a variação de rating
+func exibirFimDeJogo(dados protocolo.DadosFimDeJogo) {
+	fmt.Printf("\n╔═══════════════════════════════════════╗\n")
+	if dados.VencedorNome == "EMPATE" {
+		fmt.Printf("║   FIM DE JOGO - EMPATE!               ║\n")
+	} else {
+		fmt.Printf("║   FIM DE JOGO!                        ║\n")
+		fmt.Printf("║   Vencedor: %-25s ║\n", dados.VencedorNome)
+	}
+	fmt.Printf("╚═══════════════════════════════════════╝\n")
+	for nome, rodadas := range dados.PontosPartida {
+		fmt.Printf("  %s: %d rodada(s) vencida(s)\n", nome, rodadas)
+	}
+	if novoRating, ok := dados.Ratings[meuNome]; ok && salaEspectada == "" {
+		fmt.Printf("Rating: %d -> %d (%+d)\n", meuRating, novoRating, novoRating-meuRating)
+		meuRating = novoRating
+	}
+	if salaAtual == "" && salaEspectada != "" {
+		pararDeEspectar(false)
+	}
+	fmt.Print("> ")
+}
+
+// assinarResultadoPartida confere o resultado pedido pelo Host (a própria carteira é um dos
+// jogadores e o vencedor é o anunciado no FIM_DE_JOGO) e devolve a assinatura que o contrato exige
+// para registrar a partida.
+func assinarResultadoPartida(dados protocolo.DadosAssinarResultado) {
+	if !blockchainEnabled || chavePrivada == nil {
+		return
+	}
+	minhaCarteira := chavePrivada.Address
+	jogador1 := common.HexToAddress(dados.Jogador1)
+	jogador2 := common.HexToAddress(dados.Jogador2)
+	vencedor := common.HexToAddress(dados.Vencedor)
+	if minhaCarteira != jogador1 && minhaCarteira != jogador2 {
+		fmt.Printf("\n[RESULTADO] Pedido de assinatura recusado: sua carteira não está no resultado.\n> ")
+		return
+	}
+	oponente := jogador1
+	if minhaCarteira == jogador1 {
+		oponente = jogador2
+	}
+	vencedorEsperado := oponente
+	switch dados.VencedorNome {
+	case meuNome:
+		vencedorEsperado = minhaCarteira
+	case "EMPATE":
+		vencedorEsperado = common.Address{}
+	}
+	if vencedor != vencedorEsperado {
+		fmt.Printf("\n[RESULTADO] Pedido de assinatura recusado: vencedor não confere com o fim de jogo.\n> ")
+		return
+	}
+
+	hash := contrato.HashResultado(contractAddress, jogador1, jogador2, vencedor, common.HexToHash(dados.HashLog))
+	assinatura, err := seguranca.AssinarHash(hash, chavePrivada.PrivateKey)
+	if err != nil {
+		fmt.Printf("\n[ERRO] Falha ao assinar o resultado: %v\n> ", err)
+		return
+	}
+	mensagem := protocolo.Mensagem{
+		Comando: "ASSINATURA_RESULTADO",
+		Dados:   mustJSON(protocolo.DadosAssinaturaResultado{ClienteID: meuID, SalaID: dados.SalaID, Assinatura: assinatura}),
+		Token:   tokenSessao,
+	}
+	payload, _ := json.Marshal(mensagem)
+	mqttClient.Publish(fmt.Sprintf("partidas/%s/comandos", dados.SalaID), 0, false, payload)
+	fmt.Printf("\n[RESULTADO] Resultado assinado com a carteira %s para registro na blockchain.\n> ", minhaCarteira.Hex())
+}
+
+// textoTempoRestante formata o relógio de turno enviado pelo servidor (0 = partida sem relógio)
+func textoTempoRestante(segundos int) string {
+	if segundos <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%ds para jogar)", segundos)
+}
+
 func handleEventoPartida(client mqtt.Client, msg mqtt.Message) {
 	log.Printf("[MQTT_RX] Mensagem recebida no tópico: %s", msg.Topic())
 
@@ -569,34 +840,38 @@ func handleEventoPartida(client mqtt.Client, msg mqtt.Message) {
 		}
 
 		// Mostra de quem é a vez
-		if turnoDeQuem == meuID {
-			fmt.Println("\n>>> É A SUA VEZ DE JOGAR! <<<")
+		tempo := textoTempoRestante(dados.TempoRestante)
+		if salaAtual == "" && salaEspectada != "" {
+			fmt.Printf("\n(Vez de %s)%s\n", jogadoresEspectados[turnoDeQuem], tempo)
+		} else if turnoDeQuem == meuID {
+			fmt.Printf("\n>>> É A SUA VEZ DE JOGAR! <<<%s\n", tempo)
 		} else {
-			fmt.Printf("\n(Aguardando jogada de %s)\n", oponenteNome)
+			fmt.Printf("\n(Aguardando jogada de %s)%s\n", oponenteNome, tempo)
 		}
 
 		fmt.Println("-------------------")
 		fmt.Print("> ")
 
+	case "RODADA_FIM":
+		var dados protocolo.DadosFimDeRodada
+		json.Unmarshal(mensagem.Dados, &dados)
+		exibirFimDeRodada(dados)
+
 	case "FIM_DE_JOGO":
 		var dados protocolo.DadosFimDeJogo
 		json.Unmarshal(mensagem.Dados, &dados)
-
-		fmt.Printf("\n╔═══════════════════════════════════════╗\n")
-		if dados.VencedorNome == "EMPATE" {
-			fmt.Printf("║   FIM DE JOGO - EMPATE!               ║\n")
-		} else {
-			fmt.Printf("║   FIM DE JOGO!                        ║\n")
-			fmt.Printf("║   Vencedor: %-25s ║\n", dados.VencedorNome)
-		}
-		fmt.Printf("╚═══════════════════════════════════════╝\n")
-		fmt.Print("> ")
+		exibirFimDeJogo(dados)
 
 	case "ERRO_JOGADA":
 		var dados protocolo.DadosErro
 		json.Unmarshal(mensagem.Dados, &dados)
 		fmt.Printf("\n[JOGADA_INVALIDA] %s\n> ", dados.Mensagem)
 
+	case "SISTEMA":
+		var dados protocolo.DadosErro
+		json.Unmarshal(mensagem.Dados, &dados)
+		fmt.Printf("\n[SISTEMA] %s\n> ", dados.Mensagem)
+
 	case "RECEBER_CHAT":
 		var dados protocolo.DadosReceberChat
 		json.Unmarshal(mensagem.Dados, &dados)
@@ -647,6 +922,18 @@ func processarComando(entrada string) {
 	case "/cartas", "/inventario":
 		mostrarCartas()
 
+	case "/fila":
+		// /fila [variante] [formato]: volta à fila pedindo a variante (CLASSICO, TRUNFO, RARIDADE,
+		// ALEATORIA) e o formato (LIVRE, 3x2, MD3x1...)
+		regrasFila, formatoFila = "", ""
+		if len(partes) >= 2 {
+			regrasFila = strings.ToUpper(partes[1])
+		}
+		if len(partes) >= 3 {
+			formatoFila = strings.ToUpper(partes[2])
+		}
+		entrarNaFila()
+
 	case "/ajuda", "/help":
 		mostrarAjuda()
 
@@ -658,6 +945,14 @@ func processarComando(entrada string) {
 
 	case "/conectar-carteira", "/conectar":
 		reconectarCarteira()
+	case "/espectar":
+		if len(partes) < 2 {
+			fmt.Println("[ERRO] Uso: /espectar <ID_da_sala>")
+			return
+		}
+		espectarPartida(partes[1])
+	case "/parar-espectar":
+		pararDeEspectar(true)
 	case "/aceitar":
 		if len(partes) < 2 {
 			fmt.Println("[ERRO] Uso: /aceitar <ID_DA_PROPOSTA>")
@@ -671,6 +966,63 @@ func processarComando(entrada string) {
 			// Atualiza inventário local
 			mostrarCartas()
 		}
+	case "/mercado":
+		mostrarMercado()
+	case "/vender":
+		if len(partes) < 3 {
+			fmt.Println("[ERRO] Uso: /vender <ID_da_carta> <preço_em_ETH>")
+			return
+		}
+		venderCarta(partes[1], partes[2])
+	case "/comprar-carta":
+		if len(partes) < 2 {
+			fmt.Println("[ERRO] Uso: /comprar-carta <ID_do_anúncio>")
+			return
+		}
+		if err := ComprarCartaAnunciadaBlockchain(partes[1]); err != nil {
+			fmt.Printf("[ERRO] Falha ao comprar carta: %v\n", err)
+		} else {
+			fmt.Println("[SUCESSO] Carta comprada no mercado!")
+			mostrarCartas()
+		}
+	case "/retirar":
+		if len(partes) < 2 {
+			fmt.Println("[ERRO] Uso: /retirar <ID_do_anúncio>")
+			return
+		}
+		if err := RetirarAnuncioBlockchain(partes[1]); err != nil {
+			fmt.Printf("[ERRO] Falha ao retirar anúncio: %v\n", err)
+		} else {
+			fmt.Printf("[SUCESSO] Anúncio %s retirado; a carta voltou para você.\n", partes[1])
+		}
+	case "/sacar":
+		if err := SacarVendasBlockchain(); err != nil {
+			fmt.Printf("[ERRO] Falha ao sacar vendas: %v\n", err)
+		} else {
+			fmt.Println("[SUCESSO] Valor das vendas transferido para sua carteira.")
+		}
+	case "/fundir":
+		if len(partes) < 2 {
+			fmt.Println("[ERRO] Uso: /fundir <ID1> <ID2> <ID3>")
+			return
+		}
+		fundirCartas(partes[1:])
+	case "/propostas":
+		mostrarPropostas()
+	case "/cancelar", "/recusar":
+		if len(partes) < 2 {
+			fmt.Printf("[ERRO] Uso: %s <ID_DA_PROPOSTA>\n", comando)
+			return
+		}
+		encerrar, acao := CancelarPropostaTrocaBlockchain, "cancelada"
+		if comando == "/recusar" {
+			encerrar, acao = RecusarPropostaTrocaBlockchain, "recusada"
+		}
+		if err := encerrar(partes[1]); err != nil {
+			fmt.Printf("[ERRO] Falha ao encerrar proposta: %v\n", err)
+		} else {
+			fmt.Printf("[SUCESSO] Proposta %s %s na Blockchain.\n", partes[1], acao)
+		}
 	default:
 		// Se não for um comando, envia como chat
 		if salaAtual != "" {
@@ -681,6 +1033,77 @@ func processarComando(entrada string) {
 	}
 }
 
+// espectarPartida pede ao servidor para assistir uma partida em andamento (somente leitura)
+func espectarPartida(salaID string) {
+	if salaAtual != "" {
+		fmt.Println("[ERRO] Você está em uma partida. Termine-a antes de assistir outra.")
+		return
+	}
+	if salaEspectada != "" {
+		pararDeEspectar(true)
+	}
+	enviarPedidoEspectador("ESPECTAR", salaID)
+}
+
+// pararDeEspectar cancela a inscrição nos eventos da sala assistida. Quando avisarServidor
+// é falso (fim da partida), o servidor já removeu o espectador por conta própria.
+func pararDeEspectar(avisarServidor bool) {
+	if salaEspectada == "" {
+		fmt.Println("[ERRO] Você não está assistindo nenhuma partida.")
+		return
+	}
+	mqttClient.Unsubscribe(fmt.Sprintf("partidas/%s/eventos", salaEspectada))
+	if avisarServidor {
+		enviarPedidoEspectador("PARAR_ESPECTAR", salaEspectada)
+	}
+	fmt.Printf("[ESPECTADOR] Deixou de assistir a sala %s.\n", salaEspectada)
+	salaEspectada = ""
+	jogadoresEspectados = nil
+}
+
+// fundirCartas usa o contrato quando a carteira está conectada; sem carteira, pede a fusão ao
+// servidor, que mantém o inventário
+func fundirCartas(ids []string) {
+	if salaAtual != "" {
+		fmt.Println("[ERRO] Você está em uma partida. Termine-a antes de fundir cartas.")
+		return
+	}
+
+	if !blockchainEnabled || chavePrivada == nil {
+		msg := protocolo.Mensagem{
+			Comando: "FUNDIR_CARTAS",
+			Dados:   mustJSON(protocolo.DadosFundirCartas{ClienteID: meuID, Cartas: ids}),
+		}
+		topico := fmt.Sprintf("clientes/%s/fundir", meuID)
+		if token := mqttClient.Publish(topico, 0, false, mustJSON(msg)); token.Wait() && token.Error() != nil {
+			fmt.Printf("[ERRO] Falha ao enviar fusão: %v\n", token.Error())
+		}
+		return
+	}
+
+	nova, err := FundirCartasBlockchain(ids)
+	if err != nil {
+		fmt.Printf("[ERRO] Falha ao fundir cartas: %v\n", err)
+		return
+	}
+	fmt.Printf("[SUCESSO] Cartas fundidas! Você recebeu %s %s - Poder: %d (Raridade: %s) [ID: %s]\n",
+		nova.Nome, nova.Naipe, nova.Valor, nova.Raridade, nova.ID)
+	if cartas, err := obterInventarioBlockchain(); err == nil {
+		meuInventario = cartas
+	}
+}
+
+func enviarPedidoEspectador(comando, salaID string) {
+	msg := protocolo.Mensagem{
+		Comando: comando,
+		Dados:   mustJSON(protocolo.DadosEspectar{ClienteID: meuID, SalaID: salaID}),
+	}
+	topico := fmt.Sprintf("clientes/%s/espectar", meuID)
+	if token := mqttClient.Publish(topico, 0, false, mustJSON(msg)); token.Wait() && token.Error() != nil {
+		fmt.Printf("[ERRO] Falha ao enviar %s: %v\n", comando, token.Error())
+	}
+}
+
 func reconectarCarteira() {
 	fmt.Println("\n=== Reconectar Carteira Blockchain ===")
 
@@ -726,7 +1149,8 @@ func comprarPacote() {
 	if blockchainEnabled && chavePrivada != nil {
 		fmt.Println("[BLOCKCHAIN] Comprando pacote na blockchain...")
 		fmt.Printf("[DEBUG] Chamando comprarPacoteBlockchain()...\n")
-		if err := comprarPacoteBlockchain(); err != nil {
+		revelacao, err := comprarPacoteBlockchain()
+		if err != nil {
 			fmt.Printf("[ERRO] Falha ao comprar na blockchain: %v\n", err)
 			return
 		}
@@ -739,15 +1163,17 @@ func comprarPacote() {
 			fmt.Printf("[OK] Você agora possui %d cartas!\n", len(cartas))
 		}
 
-		// Notifica o servidor sobre a compra (opcional, para sincronização)
+		// Pede ao servidor o pacote desta compra, que ele confere no contrato
 		if salaAtual != "" {
+			// O servidor não cunha outro pacote: confere no contrato a revelação desta compra
 			dados := map[string]string{
-				"cliente_id": meuID,
-				"endereco":   contaBlockchain.Hex(),
+				"cliente_id":        meuID,
+				"compra_blockchain": revelacao.Hex(),
 			}
 			mensagem := protocolo.Mensagem{
 				Comando: "COMPRAR_PACOTE",
 				Dados:   mustJSON(dados),
+				Token:   tokenSessao,
 			}
 			payload, _ := json.Marshal(mensagem)
 			topico := fmt.Sprintf("partidas/%s/comandos", salaAtual)
@@ -770,6 +1196,7 @@ func comprarPacote() {
 	mensagem := protocolo.Mensagem{
 		Comando: "COMPRAR_PACOTE",
 		Dados:   mustJSON(dados),
+		Token:   tokenSessao,
 	}
 
 	payload, _ := json.Marshal(mensagem)
@@ -808,14 +1235,20 @@ func jogarCarta(cartaID string) {
 		return
 	}
 
-	// Envia com cliente_id e carta_id
-	dados := map[string]string{
-		"cliente_id": meuID,
-		"carta_id":   cartaID,
+	// Envia com cliente_id e carta_id; com a carteira carregada, a jogada vai assinada
+	dados := protocolo.DadosJogarCarta{ClienteID: meuID, CartaID: cartaID}
+	if chavePrivada != nil {
+		assinatura, err := seguranca.AssinarMensagem(seguranca.MensagemJogada(salaAtual, meuID, cartaID), chavePrivada.PrivateKey)
+		if err != nil {
+			fmt.Printf("[ERRO] Falha ao assinar a jogada: %v\n", err)
+			return
+		}
+		dados.Assinatura = assinatura
 	}
 	mensagem := protocolo.Mensagem{
 		Comando: "JOGAR_CARTA",
 		Dados:   mustJSON(dados),
+		Token:   tokenSessao,
 	}
 
 	payload, _ := json.Marshal(mensagem)
@@ -851,6 +1284,7 @@ func enviarChat(texto string) {
 	msg := protocolo.Mensagem{
 		Comando: "CHAT",
 		Dados:   mustJSON(dados),
+		Token:   tokenSessao,
 	}
 
 	payload, _ := json.Marshal(msg)
@@ -927,6 +1361,7 @@ func sincronizarCartasComServidor() {
 	msg := protocolo.Mensagem{
 		Comando: "SINCRONIZAR_CARTAS",
 		Dados:   mustJSON(dados),
+		Token:   tokenSessao,
 	}
 
 	payload, _ := json.Marshal(msg)
@@ -964,8 +1399,21 @@ func mostrarAjuda() {
 	} else {
 		fmt.Println("  [INFO] Use /conectar-carteira para conectar sua carteira blockchain")
 	}
+	fmt.Println("  /fila [variante] [formato] - Entra na fila pedindo variante (CLASSICO, TRUNFO, RARIDADE, ALEATORIA) e formato (LIVRE, 3x2, MD3x1)")
 	fmt.Println("  /jogar <ID_da_carta>   - Joga uma carta da sua mão")
 	fmt.Println("  /trocar                - Propõe uma troca de cartas com o oponente")
+	fmt.Println("  /fundir <ID1> <ID2> <ID3> - Funde 3 cartas de mesmo nome e raridade em uma da raridade seguinte")
+	fmt.Println("  /mercado               - Lista as cartas à venda no mercado")
+	fmt.Println("  /vender <ID> <ETH>     - Anuncia uma carta sua no mercado por um preço fixo")
+	fmt.Println("  /comprar-carta <ID>    - Compra a carta de um anúncio do mercado")
+	fmt.Println("  /retirar <ID>          - Retira um anúncio seu e recebe a carta de volta")
+	fmt.Println("  /sacar                 - Saca o valor das suas vendas no mercado")
+	fmt.Println("  /propostas             - Lista as propostas de troca pendentes (recebidas e enviadas)")
+	fmt.Println("  /aceitar <ID>          - Aceita uma proposta de troca recebida")
+	fmt.Println("  /recusar <ID>          - Recusa uma proposta de troca recebida")
+	fmt.Println("  /cancelar <ID>         - Cancela uma proposta de troca que você enviou")
+	fmt.Println("  /espectar <ID_da_sala> - Assiste uma partida em andamento (somente leitura)")
+	fmt.Println("  /parar-espectar        - Deixa de assistir a partida")
 	fmt.Println("  /ajuda                 - Mostra esta lista de comandos")
 	fmt.Println("  /sair                  - Sai do jogo")
 	fmt.Println("  Qualquer outro texto será enviado como chat.")
@@ -1011,11 +1459,132 @@ func iniciarProcessoDeTroca() {
 	fmt.Printf("\n[SUCESSO] Proposta criada na Blockchain! ID da Proposta: %s\n", idProposta)
 	fmt.Println("Avise seu oponente para aceitar a proposta usando o comando:")
 	fmt.Printf("/aceitar %s\n", idProposta)
+	fmt.Println("Ela também aparece em /propostas e expira em 24h; use /cancelar para desistir antes disso.")
 
 	// Opcional: Enviar chat avisando
 	enviarChat(fmt.Sprintf("Criei uma proposta de troca na blockchain! ID: %s", idProposta))
 }
 
+// mostrarMercado lista os anúncios ativos e o valor de vendas a sacar
+func mostrarMercado() {
+	if !blockchainEnabled || chavePrivada == nil {
+		fmt.Println("[ERRO] Você precisa conectar sua carteira (/conectar) para usar o mercado.")
+		return
+	}
+
+	anuncios, err := listarAnunciosBlockchain()
+	if err != nil {
+		fmt.Printf("[ERRO] Falha ao listar o mercado: %v\n", err)
+		return
+	}
+
+	fmt.Println("\n--- Mercado de Cartas ---")
+	if len(anuncios) == 0 {
+		fmt.Println("  Nenhuma carta à venda.")
+	}
+	for _, anuncio := range anuncios {
+		descricao := fmt.Sprintf("carta %s", anuncio.CartaId)
+		if carta, err := obterCartaBlockchain(anuncio.CartaId); err == nil {
+			descricao = fmt.Sprintf("%s de %s (valor %d, %s)", carta.Nome, carta.Naipe, carta.Valor, carta.Raridade)
+		}
+		vendedor := anuncio.Vendedor.Hex()
+		if anuncio.Vendedor == contaBlockchain {
+			vendedor = "você"
+		}
+		fmt.Printf("  #%s: %s por %s ETH - vendedor %s\n", anuncio.ID, descricao, formatarEth(anuncio.Preco), vendedor)
+	}
+
+	if saldo, err := saldoVendasBlockchain(); err == nil && saldo.Sign() > 0 {
+		fmt.Printf("Você tem %s ETH de vendas para sacar (/sacar).\n", formatarEth(saldo))
+	}
+	fmt.Println("Use /comprar-carta <ID> para comprar ou /vender <ID_da_carta> <ETH> para anunciar.")
+}
+
+// venderCarta anuncia uma carta da carteira no mercado
+func venderCarta(cartaID, precoEth string) {
+	preco, err := parsearEth(precoEth)
+	if err != nil {
+		fmt.Printf("[ERRO] %v\n", err)
+		return
+	}
+	anuncioID, err := AnunciarCartaBlockchain(cartaID, preco)
+	if err != nil {
+		fmt.Printf("[ERRO] Falha ao anunciar carta: %v\n", err)
+		return
+	}
+	fmt.Printf("[SUCESSO] Carta %s anunciada por %s ETH (anúncio %s).\n", cartaID, formatarEth(preco), anuncioID)
+	fmt.Println("A carta fica sob custódia do contrato até ser vendida; use /retirar para desistir.")
+}
+
+// mostrarPropostas lista as propostas pendentes da carteira, agrupadas por endereço da outra parte
+func mostrarPropostas() {
+	if !blockchainEnabled || chavePrivada == nil {
+		fmt.Println("[ERRO] Você precisa conectar sua carteira (/conectar) para ver propostas.")
+		return
+	}
+
+	recebidas, enviadas, err := listarPropostasBlockchain()
+	if err != nil {
+		fmt.Printf("[ERRO] Falha ao listar propostas: %v\n", err)
+		return
+	}
+
+	fmt.Println("\n--- Propostas Recebidas ---")
+	mostrarGrupoPropostas(recebidas, func(p *PropostaTrocaStruct) common.Address { return p.Jogador1 }, "de")
+	fmt.Println("\n--- Propostas Enviadas ---")
+	mostrarGrupoPropostas(enviadas, func(p *PropostaTrocaStruct) common.Address { return p.Jogador2 }, "para")
+	fmt.Println("Use /aceitar, /recusar ou /cancelar seguido do ID da proposta.")
+}
+
+func mostrarGrupoPropostas(ids []*big.Int, outraParte func(*PropostaTrocaStruct) common.Address, rotulo string) {
+	agora := time.Now()
+	porEndereco := make(map[common.Address][]string)
+	var enderecos []common.Address
+	for _, id := range ids {
+		proposta, err := obterPropostaTrocaBlockchain(id.String())
+		if err != nil {
+			fmt.Printf("[ERRO] Falha ao consultar proposta %s: %v\n", id, err)
+			continue
+		}
+		if proposta.Estado(agora) != contrato.PropostaPendente {
+			continue
+		}
+		endereco := outraParte(proposta)
+		if _, ok := porEndereco[endereco]; !ok {
+			enderecos = append(enderecos, endereco)
+		}
+		porEndereco[endereco] = append(porEndereco[endereco], fmt.Sprintf(
+			"  #%s: sua carta %s <-> carta %s dele(a) (expira %s)",
+			id, cartaDaCarteira(proposta), cartaDaOutraParte(proposta), proposta.Expiracao().Format("02/01 15:04")))
+	}
+
+	if len(enderecos) == 0 {
+		fmt.Println("  Nenhuma proposta pendente.")
+		return
+	}
+	for _, endereco := range enderecos {
+		fmt.Printf(" %s %s:\n", rotulo, endereco.Hex())
+		for _, linha := range porEndereco[endereco] {
+			fmt.Println(linha)
+		}
+	}
+}
+
+// cartaDaCarteira é a carta desta carteira na proposta; cartaDaOutraParte, a do outro jogador
+func cartaDaCarteira(p *PropostaTrocaStruct) *big.Int {
+	if p.Jogador1 == contaBlockchain {
+		return p.CartaJogador1
+	}
+	return p.CartaJogador2
+}
+
+func cartaDaOutraParte(p *PropostaTrocaStruct) *big.Int {
+	if p.Jogador1 == contaBlockchain {
+		return p.CartaJogador2
+	}
+	return p.CartaJogador1
+}
+
 func mustJSON(v interface{}) []byte {
 	b, _ := json.Marshal(v)
 	return b
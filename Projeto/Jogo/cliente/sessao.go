@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"jogodistribuido/protocolo"
+	"jogodistribuido/servidor/tipos"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+)
+
+const PRESENCA_INTERVALO = 5 * time.Second // Deve acompanhar o PRESENCA_INTERVALO do servidor
+
+var (
+	tokenSessao string // Emitido no LOGIN_OK; usado em RETOMAR_SESSAO
+	brokerAtual string
+)
+
+// sessaoSalva é o conteúdo do arquivo de sessão, usado para voltar à partida
+// depois de fechar o cliente ou perder a conexão.
+type sessaoSalva struct {
+	ClienteID   string `json:"cliente_id"`
+	TokenSessao string `json:"token_sessao"`
+	Broker      string `json:"broker"`
+}
+
+func arquivoSessao() string {
+	nome := strings.NewReplacer("/", "_", "\\", "_", " ", "_").Replace(meuNome)
+	return fmt.Sprintf(".sessao_%s.json", nome)
+}
+
+func salvarSessao() {
+	dados, _ := json.Marshal(sessaoSalva{ClienteID: meuID, TokenSessao: tokenSessao, Broker: brokerAtual})
+	if err := os.WriteFile(arquivoSessao(), dados, 0600); err != nil {
+		log.Printf("[SESSAO] Não foi possível salvar a sessão: %v", err)
+	}
+}
+
+// carregarSessao lê o token salvo para este nome e broker, se houver.
+func carregarSessao() bool {
+	dados, err := os.ReadFile(arquivoSessao())
+	if err != nil {
+		return false
+	}
+	var sessao sessaoSalva
+	if err := json.Unmarshal(dados, &sessao); err != nil || sessao.Broker != brokerAtual || sessao.TokenSessao == "" {
+		return false
+	}
+	tokenSessao = sessao.TokenSessao
+	return true
+}
+
+// retomarSessao envia RETOMAR_SESSAO com o token salvo. Em caso de sucesso o cliente volta a usar
+// o mesmo ID e, se estava em uma partida, recebe o estado atual dela.
+func retomarSessao() error {
+	respostaChan := make(chan protocolo.Mensagem, 1)
+
+	tempID := uuid.New().String()
+	responseTopic := fmt.Sprintf("clientes/%s/eventos", tempID)
+	if token := mqttClient.Subscribe(responseTopic, 1, func(c mqtt.Client, m mqtt.Message) {
+		var msg protocolo.Mensagem
+		if err := json.Unmarshal(m.Payload(), &msg); err == nil {
+			select {
+			case respostaChan <- msg:
+			default:
+			}
+		}
+	}); token.Wait() && token.Error() != nil {
+		return fmt.Errorf("falha ao se inscrever no tópico de resposta: %v", token.Error())
+	}
+	defer mqttClient.Unsubscribe(responseTopic)
+
+	msg := protocolo.Mensagem{
+		Comando: "RETOMAR_SESSAO",
+		Dados:   mustJSON(protocolo.DadosRetomarSessao{TokenSessao: tokenSessao}),
+	}
+	mqttClient.Publish(fmt.Sprintf("clientes/%s/retomar_sessao", tempID), 1, false, mustJSON(msg))
+
+	select {
+	case resp := <-respostaChan:
+		if resp.Comando != "SESSAO_RETOMADA" {
+			var erro protocolo.DadosErro
+			json.Unmarshal(resp.Dados, &erro)
+			return fmt.Errorf("sessão não retomada: %s", erro.Mensagem)
+		}
+		var dados protocolo.DadosSessaoRetomada
+		if err := json.Unmarshal(resp.Dados, &dados); err != nil {
+			return fmt.Errorf("resposta de retomada inválida: %v", err)
+		}
+		aplicarSessaoRetomada(dados)
+		return nil
+	case <-time.After(5 * time.Second):
+		return fmt.Errorf("servidor não respondeu à retomada de sessão (timeout)")
+	}
+}
+
+func aplicarSessaoRetomada(dados protocolo.DadosSessaoRetomada) {
+	meuID = dados.ClienteID
+	meuInventario = dados.Inventario
+	meuRating = dados.Rating
+	fmt.Printf("\n[SESSAO] Sessão retomada no servidor %s (ID: %s) | Rating: %d\n", dados.Servidor, meuID, meuRating)
+
+	if token := mqttClient.Subscribe(fmt.Sprintf("clientes/%s/eventos", meuID), 1, handleMensagemServidor); token.Wait() && token.Error() != nil {
+		log.Printf("[ERRO] Erro ao se inscrever nos eventos do cliente: %v", token.Error())
+	}
+	if dados.SalaID == "" {
+		return
+	}
+
+	salaAtual = dados.SalaID
+	oponenteID = dados.OponenteID
+	oponenteNome = dados.OponenteNome
+	if token := mqttClient.Subscribe(fmt.Sprintf("partidas/%s/eventos", salaAtual), 0, handleEventoPartida); token.Wait() && token.Error() != nil {
+		log.Printf("[ERRO] Erro ao se inscrever no tópico da partida: %v", token.Error())
+	}
+
+	var estado tipos.EstadoPartida
+	if err := json.Unmarshal(dados.Estado, &estado); err != nil {
+		log.Printf("[SESSAO] Estado da partida inválido: %v", err)
+		return
+	}
+	turnoDeQuem = estado.TurnoDe
+	for _, j := range estado.Jogadores {
+		if j.ID == meuID {
+			meuInventario = j.Inventario
+		}
+	}
+
+	fmt.Printf("[SESSAO] De volta à partida contra '%s' (Sala: %s) | Rodada %d | Estado: %s\n", oponenteNome, salaAtual, estado.NumeroRodada, estado.Estado)
+	for _, nome := range []string{meuNome, oponenteNome} {
+		fmt.Printf("  %s: %d pontos na rodada, %d rodada(s) vencida(s)\n", nome, estado.PontosRodada[nome], estado.PontosPartida[nome])
+	}
+	if len(estado.CartasNaMesa) > 0 {
+		fmt.Println("Cartas na mesa:")
+		for nome, carta := range estado.CartasNaMesa {
+			fmt.Printf("  %s: %s %s (Poder: %d)\n", nome, carta.Nome, carta.Naipe, carta.Valor)
+		}
+	}
+	if estado.Estado == "JOGANDO" && turnoDeQuem == meuID {
+		fmt.Println(">>> É A SUA VEZ DE JOGAR! <<<")
+	}
+}
+
+// enviarPresencaPeriodicamente avisa o servidor de que o cliente continua conectado.
+// Sem esses sinais, o servidor dá um prazo para a retomada da sessão e depois declara W.O.
+func enviarPresencaPeriodicamente() {
+	ticker := time.NewTicker(PRESENCA_INTERVALO)
+	defer ticker.Stop()
+	for range ticker.C {
+		if meuID == "" || !mqttClient.IsConnectionOpen() {
+			continue
+		}
+		msg := protocolo.Mensagem{Comando: "PRESENCA"}
+		mqttClient.Publish(fmt.Sprintf("clientes/%s/presenca", meuID), 0, false, mustJSON(msg))
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"jogodistribuido/servidor/blockchain"
+	"jogodistribuido/servidor/blockchain/contrato"
+	"jogodistribuido/servidor/indexador"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/gin-gonic/gin"
+)
+
+// indexador acompanha os eventos do contrato GameEconomy, grava-os em um banco local e
+// expõe consultas (inventário, cartas, pacotes, trocas, partidas) para os servidores de jogo.
+//
+// Uso:
+//
+//	indexador -rpc http://geth:8545 -contrato 0x... [-dados dados/indexador.jsonl] [-addr :8090]
+//
+// As flags também podem vir do ambiente: BLOCKCHAIN_RPC_URL, CONTRACT_ADDRESS, INDEXADOR_DADOS,
+// INDEXADOR_ADDR, INDEXADOR_BLOCO_INICIAL e INDEXADOR_CONFIRMACOES.
+func main() {
+	rpcURL := flag.String("rpc", variavel("BLOCKCHAIN_RPC_URL", blockchain.DefaultRPCURL), "Endereço RPC do nó")
+	contratoHex := flag.String("contrato", os.Getenv("CONTRACT_ADDRESS"), "Endereço do contrato GameEconomy")
+	dados := flag.String("dados", variavel("INDEXADOR_DADOS", "dados/indexador.jsonl"), "Arquivo do banco do indexador")
+	addr := flag.String("addr", variavel("INDEXADOR_ADDR", ":8090"), "Endereço da API de consulta")
+	blocoInicial := flag.Uint64("bloco-inicial", numero("INDEXADOR_BLOCO_INICIAL", 0), "Bloco de implantação do contrato")
+	confirmacoes := flag.Uint64("confirmacoes", numero("INDEXADOR_CONFIRMACOES", 0), "Blocos de confirmação antes de indexar")
+	flag.Parse()
+
+	if !common.IsHexAddress(*contratoHex) {
+		log.Fatalf("Endereço do contrato inválido ou ausente (-contrato ou CONTRACT_ADDRESS): %q", *contratoHex)
+	}
+
+	client, err := ethclient.Dial(*rpcURL)
+	if err != nil {
+		log.Fatalf("Falha ao conectar ao nó %s: %v", *rpcURL, err)
+	}
+	defer client.Close()
+
+	contratoABI, err := contrato.ABI()
+	if err != nil {
+		log.Fatalf("Falha ao carregar ABI: %v", err)
+	}
+
+	banco, err := indexador.NewBanco(*dados)
+	if err != nil {
+		log.Fatalf("Falha ao abrir banco do indexador: %v", err)
+	}
+	defer banco.Fechar()
+
+	ctx, cancelar := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancelar()
+
+	ix := indexador.NewIndexador(client, common.HexToAddress(*contratoHex), contratoABI, banco, *blocoInicial, *confirmacoes)
+	go ix.Executar(ctx)
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	indexador.RegistrarRotas(router, banco)
+	go func() {
+		log.Printf("API do indexador iniciada em %s", *addr)
+		if err := router.Run(*addr); err != nil {
+			log.Fatalf("Erro ao iniciar API: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Indexador encerrado")
+}
+
+func variavel(nome, padrao string) string {
+	if valor := os.Getenv(nome); valor != "" {
+		return valor
+	}
+	return padrao
+}
+
+func numero(nome string, padrao uint64) uint64 {
+	valor := os.Getenv(nome)
+	if valor == "" {
+		return padrao
+	}
+	n, err := strconv.ParseUint(valor, 10, 64)
+	if err != nil {
+		log.Printf("⚠ Aviso: %s inválido (%s). Usando %d.", nome, valor, padrao)
+		return padrao
+	}
+	return n
+}
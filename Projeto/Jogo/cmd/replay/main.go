@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"jogodistribuido/protocolo"
+	"jogodistribuido/servidor/regras"
+	"jogodistribuido/servidor/replay"
+	"jogodistribuido/servidor/seguranca"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// replay reconstrói uma partida a partir do EventLog exportado por um servidor e
+// indica o primeiro ponto em que o log e o estado final não conferem.
+//
+// Uso:
+//
+//	replay -arquivo partida.json
+//	replay -servidor localhost:8080 -sala <salaID> [-salvar partida.json]
+func main() {
+	arquivo := flag.String("arquivo", "", "Arquivo JSON exportado (GET /partida/exportar/:salaID)")
+	servidor := flag.String("servidor", "", "Servidor de onde exportar a partida (host:porta)")
+	salaID := flag.String("sala", "", "ID da sala a exportar (usado com -servidor)")
+	salvar := flag.String("salvar", "", "Salva a exportação baixada do servidor neste arquivo")
+	saidaJSON := flag.Bool("json", false, "Imprime todos os estados intermediários em JSON")
+	flag.Parse()
+
+	var exportacao replay.Exportacao
+	var err error
+	switch {
+	case *arquivo != "":
+		exportacao, err = lerArquivo(*arquivo)
+	case *servidor != "" && *salaID != "":
+		exportacao, err = baixarExportacao(*servidor, *salaID)
+		if err == nil && *salvar != "" {
+			err = salvarArquivo(*salvar, exportacao)
+		}
+	default:
+		fmt.Println("Uso: replay -arquivo <partida.json>")
+		fmt.Println("     replay -servidor <host:porta> -sala <salaID> [-salvar partida.json]")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Printf("[ERRO] %v\n", err)
+		os.Exit(1)
+	}
+
+	resultado, err := replay.Reproduzir(exportacao)
+	if err != nil {
+		fmt.Printf("[ERRO] %v\n", err)
+		os.Exit(1)
+	}
+
+	if *saidaJSON {
+		saida, _ := json.MarshalIndent(resultado, "", "  ")
+		fmt.Println(string(saida))
+	} else {
+		imprimirPassos(exportacao, resultado)
+	}
+
+	if resultado.Divergencia != nil {
+		fmt.Printf("\n✗ DIVERGÊNCIA: %s\n", resultado.Divergencia)
+		os.Exit(1)
+	}
+	fmt.Printf("\n✓ Replay consistente: %d eventos reproduzidos, estado final confere.\n", len(resultado.Passos))
+	if resultado.HashLog != "" {
+		// Partidas co-assinadas guardam este hash no contrato (obterPartida / GET /partidas/:endereco do indexador)
+		fmt.Printf("Hash do EventLog: %s\n", resultado.HashLog)
+	}
+}
+
+func lerArquivo(caminho string) (replay.Exportacao, error) {
+	var exportacao replay.Exportacao
+	dados, err := os.ReadFile(caminho)
+	if err != nil {
+		return exportacao, fmt.Errorf("erro ao ler %s: %v", caminho, err)
+	}
+	if err := json.Unmarshal(dados, &exportacao); err != nil {
+		return exportacao, fmt.Errorf("erro ao decodificar %s: %v", caminho, err)
+	}
+	return exportacao, nil
+}
+
+func salvarArquivo(caminho string, exportacao replay.Exportacao) error {
+	dados, err := json.MarshalIndent(exportacao, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao serializar exportação: %v", err)
+	}
+	if err := os.WriteFile(caminho, dados, 0644); err != nil {
+		return fmt.Errorf("erro ao salvar %s: %v", caminho, err)
+	}
+	fmt.Printf("Exportação salva em %s\n", caminho)
+	return nil
+}
+
+// baixarExportacao busca a partida na API do servidor (rota protegida por JWT entre servidores)
+func baixarExportacao(servidor, salaID string) (replay.Exportacao, error) {
+	var exportacao replay.Exportacao
+	url := fmt.Sprintf("http://%s/partida/exportar/%s", servidor, salaID)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return exportacao, fmt.Errorf("erro ao criar requisição: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+seguranca.GenerateJWT("replay"))
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return exportacao, fmt.Errorf("erro ao exportar partida de %s: %v", servidor, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return exportacao, fmt.Errorf("servidor %s respondeu com status %d", servidor, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&exportacao); err != nil {
+		return exportacao, fmt.Errorf("erro ao decodificar exportação: %v", err)
+	}
+	return exportacao, nil
+}
+
+func imprimirPassos(exportacao replay.Exportacao, resultado *replay.Resultado) {
+	nomes := make(map[string]string)
+	for _, j := range exportacao.Jogadores {
+		nomes[j.ID] = j.Nome
+	}
+
+	fmt.Printf("Partida %s | Regras: %s | Formato: %s | %d eventos\n\n", exportacao.SalaID, exportacao.Regras, regras.ObterFormato(exportacao.Formato).ID(), len(exportacao.EventLog))
+	for _, passo := range resultado.Passos {
+		assinatura := "OK"
+		if !passo.AssinaturaValida {
+			assinatura = "INVÁLIDA"
+		}
+		jogador := nomes[passo.Evento.PlayerID]
+		if jogador == "" {
+			jogador = passo.Evento.PlayerID
+		}
+
+		if passo.JogadaAssinada {
+			assinatura += ", carteira OK"
+		}
+
+		fmt.Printf("#%-4d %-13s %-12s [HMAC %s] rodada %d | pontos %s | rodadas %s | mesa %s | turno de %s",
+			passo.Evento.EventSeq, passo.Evento.EventType, jogador, assinatura,
+			passo.Estado.NumeroRodada, formatarPontos(passo.Estado.PontosRodada), formatarPontos(passo.Estado.PontosPartida),
+			formatarMesa(passo.Estado.CartasNaMesa), nomes[passo.Estado.TurnoDe])
+		if passo.Observacao != "" {
+			fmt.Printf(" | %s", passo.Observacao)
+		}
+		fmt.Println()
+	}
+}
+
+func formatarPontos(pontos map[string]int) string {
+	partes := make([]string, 0, len(pontos))
+	for nome, p := range pontos {
+		partes = append(partes, fmt.Sprintf("%s=%d", nome, p))
+	}
+	sort.Strings(partes)
+	return "{" + strings.Join(partes, ", ") + "}"
+}
+
+func formatarMesa(mesa map[string]protocolo.Carta) string {
+	partes := make([]string, 0, len(mesa))
+	for nome, c := range mesa {
+		partes = append(partes, fmt.Sprintf("%s: %s %d%s", nome, c.Nome, c.Valor, c.Naipe))
+	}
+	sort.Strings(partes)
+	return "{" + strings.Join(partes, ", ") + "}"
+}
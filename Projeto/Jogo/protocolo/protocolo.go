@@ -4,8 +4,9 @@ import "encoding/json"
 
 // Envelope base para todas as mensagens do protocolo
 type Mensagem struct {
-	Comando string          `json:"comando"` // Tipo da operação (LOGIN, JOGAR_CARTA, etc.)
-	Dados   json.RawMessage `json:"dados"`   // Payload específico de cada comando
+	Comando string          `json:"comando"`         // Tipo da operação (LOGIN, JOGAR_CARTA, etc.)
+	Dados   json.RawMessage `json:"dados"`           // Payload específico de cada comando
+	Token   string          `json:"token,omitempty"` // Token de sessão (LOGIN_OK) de quem publica em partidas/{salaID}/comandos
 }
 
 /* ===================== Cartas / Inventário ===================== */
@@ -46,11 +47,83 @@ type TrocarCartasResp struct {
 	InventarioAtualizado []Carta `json:"inventario_atualizado,omitempty"`
 }
 
+// Pedido de fusão (FUNDIR_CARTAS) de cartas do inventário mantido pelo servidor
+type DadosFundirCartas struct {
+	ClienteID string   `json:"cliente_id"`
+	Cartas    []string `json:"cartas"` // IDs das cartas a fundir, todas de mesmo nome e raridade
+}
+
+// Resultado da fusão (FUSAO_CONCLUIDA)
+type DadosCartasFundidas struct {
+	Queimadas  []string `json:"queimadas"`  // IDs das cartas destruídas
+	NovaCarta  Carta    `json:"nova_carta"` // Carta da raridade seguinte
+	Inventario []Carta  `json:"inventario"` // Inventário completo após a fusão
+}
+
+// Inventário alterado na blockchain (compra, troca ou transferência, inclusive as feitas pelo próprio cliente)
+type DadosInventarioAtualizado struct {
+	Cartas  []Carta  `json:"cartas"`  // Inventário completo após a mudança
+	Eventos []string `json:"eventos"` // Eventos do contrato que causaram a mudança (ex: PacoteComprado)
+	Bloco   uint64   `json:"bloco"`   // Bloco mais recente entre os eventos
+}
+
 /* ===================== Login / Match / Chat ===================== */
 
-// Dados para autenticação do jogador
+// Dados para autenticação do jogador. No login com carteira o cliente envia Nome e Endereco,
+// recebe um DESAFIO_LOGIN e repete o LOGIN com o nonce e a assinatura da mensagem do desafio.
 type DadosLogin struct {
-	Nome string `json:"nome"` // Nome único do jogador no sistema
+	Nome       string `json:"nome"`                 // Nome único do jogador no sistema
+	Endereco   string `json:"endereco,omitempty"`   // Carteira que o jogador quer provar ser sua
+	Nonce      string `json:"nonce,omitempty"`      // Nonce recebido no DESAFIO_LOGIN
+	Assinatura string `json:"assinatura,omitempty"` // Assinatura personal_sign (hex) da mensagem do desafio
+}
+
+// Desafio enviado pelo servidor (DESAFIO_LOGIN) a um login com carteira
+type DadosDesafioLogin struct {
+	Nonce    string `json:"nonce"`
+	Mensagem string `json:"mensagem"` // Texto exato que deve ser assinado com a chave da carteira
+	Expira   int64  `json:"expira"`   // Unix (segundos) a partir do qual o desafio não é mais aceito
+}
+
+// Resposta do servidor a um login bem-sucedido
+type DadosLoginOK struct {
+	ClienteID   string              `json:"cliente_id"`       // ID permanente do jogador
+	Servidor    string              `json:"servidor"`         // Servidor que atendeu o login
+	TokenSessao string              `json:"token_sessao"`     // Usado em RETOMAR_SESSAO após uma queda de conexão
+	Rating      int                 `json:"rating"`           // Rating (ELO) atual do jogador no cluster
+	Perfil      *DadosPerfilJogador `json:"perfil,omitempty"` // Dados salvos de um jogador que está retornando
+}
+
+// Pedido de retomada de sessão (RETOMAR_SESSAO) de um cliente que perdeu a conexão
+type DadosRetomarSessao struct {
+	TokenSessao string `json:"token_sessao"`
+}
+
+// Resposta a RETOMAR_SESSAO: o cliente volta a usar o mesmo ID e, se estiver em uma partida,
+// recebe o estado atual dela (apenas com o seu próprio inventário)
+type DadosSessaoRetomada struct {
+	ClienteID    string          `json:"cliente_id"`
+	Servidor     string          `json:"servidor"`
+	Inventario   []Carta         `json:"inventario"`
+	Rating       int             `json:"rating"`
+	SalaID       string          `json:"sala_id,omitempty"`
+	OponenteID   string          `json:"oponente_id,omitempty"`
+	OponenteNome string          `json:"oponente_nome,omitempty"`
+	Estado       json.RawMessage `json:"estado,omitempty"` // tipos.EstadoPartida da sala
+}
+
+// Perfil persistido de um jogador (inventário e histórico de partidas)
+type DadosPerfilJogador struct {
+	Inventario []Carta         `json:"inventario"`
+	Historico  []ResumoPartida `json:"historico"`
+}
+
+// Resumo de uma partida finalizada, guardado no histórico do jogador
+type ResumoPartida struct {
+	SalaID       string `json:"sala_id"`
+	OponenteNome string `json:"oponente_nome"`
+	VencedorNome string `json:"vencedor_nome"` // Nome do vencedor / "EMPATE"
+	Timestamp    int64  `json:"timestamp"`     // Unix (segundos) do fim da partida
 }
 
 // Notificação de que uma partida foi encontrada
@@ -66,9 +139,12 @@ type DadosEnviarChat struct {
 	Texto     string `json:"texto"` // Conteúdo da mensagem de chat
 }
 
-// Dados para jogada de carta
+// Dados para jogada de carta (JOGAR_CARTA). Jogadores com carteira vinculada no login assinam a
+// jogada; a assinatura vai para o EventLog e é conferida pela Sombra e pelo replay.
 type DadosJogarCarta struct {
-	CartaID string `json:"cartaID"` // ID da carta a ser jogada
+	ClienteID  string `json:"cliente_id"`
+	CartaID    string `json:"carta_id"`             // ID da carta a ser jogada
+	Assinatura string `json:"assinatura,omitempty"` // personal_sign (hex) de seguranca.MensagemJogada
 }
 
 // Dados para recebimento de mensagens de chat
@@ -77,26 +153,72 @@ type DadosReceberChat struct {
 	Texto       string `json:"texto"`       // Conteúdo da mensagem
 }
 
+// Pedido para assistir (ESPECTAR) ou deixar de assistir (PARAR_ESPECTAR) uma partida
+type DadosEspectar struct {
+	ClienteID string `json:"cliente_id"`
+	SalaID    string `json:"sala_id"`
+}
+
+// Resposta ao ESPECTAR: jogadores da sala e a visão pública do estado atual (sem as mãos)
+type DadosEspectando struct {
+	SalaID    string               `json:"sala_id"`
+	Jogadores map[string]string    `json:"jogadores"` // ID -> nome, para o espectador saber de quem é a vez
+	Regras    string               `json:"regras"`    // Descrição das regras da partida
+	Estado    DadosAtualizacaoJogo `json:"estado"`
+}
+
 /* ===================== Atualizações de jogo ===================== */
 
 // Estrutura principal para atualizações do estado do jogo
 type DadosAtualizacaoJogo struct {
-	MensagemDoTurno string           `json:"mensagem_do_turno"` // Mensagem descritiva do que aconteceu
-	ContagemCartas  map[string]int   `json:"contagem_cartas"`   // nome -> cartas restantes no inventário
-	UltimaJogada    map[string]Carta `json:"ultima_jogada"`     // nome -> carta recém jogada na mesa
-	VencedorJogada  string           `json:"vencedor_jogada"`   // nome do vencedor da jogada atual / "EMPATE" / ""
-	VencedorRodada  string           `json:"vencedor_rodada"`   // nome do vencedor da rodada / "EMPATE" / ""
-	NumeroRodada    int              `json:"numero_rodada"`     // Número da rodada atual (1, 2, 3...)
-	PontosRodada    map[string]int   `json:"pontos_rodada"`     // nome -> pontos na rodada atual
-	PontosPartida   map[string]int   `json:"pontos_partida"`    // nome -> rodadas ganhas na partida
-	SalaID          string           `json:"sala_id"`           // ID da sala para roteamento na sombra
-	TurnoDe         string           `json:"turnoDe"`           // ID do jogador que deve jogar
+	MensagemDoTurno string           `json:"mensagem_do_turno"`        // Mensagem descritiva do que aconteceu
+	ContagemCartas  map[string]int   `json:"contagem_cartas"`          // nome -> cartas restantes no inventário
+	UltimaJogada    map[string]Carta `json:"ultima_jogada"`            // nome -> carta recém jogada na mesa
+	VencedorJogada  string           `json:"vencedor_jogada"`          // nome do vencedor da jogada atual / "EMPATE" / ""
+	VencedorRodada  string           `json:"vencedor_rodada"`          // nome do vencedor da rodada / "EMPATE" / ""
+	NumeroRodada    int              `json:"numero_rodada"`            // Número da rodada atual (1, 2, 3...)
+	PontosRodada    map[string]int   `json:"pontos_rodada"`            // nome -> pontos na rodada atual
+	PontosPartida   map[string]int   `json:"pontos_partida"`           // nome -> rodadas ganhas na partida
+	SalaID          string           `json:"sala_id"`                  // ID da sala para roteamento na sombra
+	TurnoDe         string           `json:"turnoDe"`                  // ID do jogador que deve jogar
+	Espectadores    int              `json:"espectadores,omitempty"`   // Espectadores acompanhando a sala
+	TempoRestante   int              `json:"tempo_restante,omitempty"` // Segundos que TurnoDe ainda tem para jogar
+}
+
+// Notificação de fim de rodada (RODADA_FIM)
+type DadosFimDeRodada struct {
+	SalaID         string         `json:"sala_id"`
+	NumeroRodada   int            `json:"numero_rodada"`   // Rodada que terminou
+	VencedorRodada string         `json:"vencedor_rodada"` // Nome do vencedor da rodada / "EMPATE"
+	PontosRodada   map[string]int `json:"pontos_rodada"`   // nome -> jogadas vencidas na rodada
+	PontosPartida  map[string]int `json:"pontos_partida"`  // nome -> rodadas vencidas até agora
+	Formato        string         `json:"formato"`         // Descrição do formato da partida
 }
 
 // Notificação de fim de partida
 type DadosFimDeJogo struct {
-	VencedorNome string `json:"vencedorNome"` // Nome do vencedor final / "EMPATE" em caso de empate
-	SalaID       string `json:"sala_id"`      // ID da sala para roteamento na sombra
+	VencedorNome  string         `json:"vencedorNome"`             // Nome do vencedor final / "EMPATE" em caso de empate
+	SalaID        string         `json:"sala_id"`                  // ID da sala para roteamento na sombra
+	PontosPartida map[string]int `json:"pontos_partida,omitempty"` // nome -> rodadas vencidas
+	Ratings       map[string]int `json:"ratings,omitempty"`        // nome -> novo rating (ELO) de cada jogador
+}
+
+// Pedido de assinatura do resultado (ASSINAR_RESULTADO), enviado pelo Host aos jogadores com
+// carteira. O cliente confere o vencedor e assina contrato.HashResultado com a própria carteira.
+type DadosAssinarResultado struct {
+	SalaID       string `json:"sala_id"`
+	Jogador1     string `json:"jogador1"`
+	Jogador2     string `json:"jogador2"`
+	Vencedor     string `json:"vencedor"`      // Endereço do vencedor / endereço zero em caso de empate
+	VencedorNome string `json:"vencedor_nome"` // Nome do vencedor / "EMPATE", como no FIM_DE_JOGO
+	HashLog      string `json:"hash_log"`      // keccak256 do EventLog da partida
+}
+
+// Assinatura do jogador sobre o resultado (ASSINATURA_RESULTADO), publicada no tópico de comandos da sala
+type DadosAssinaturaResultado struct {
+	ClienteID  string `json:"cliente_id"`
+	SalaID     string `json:"sala_id"`
+	Assinatura string `json:"assinatura"` // personal_sign (hex) de contrato.HashResultado
 }
 
 // Comando representa uma ação de um jogador em uma partida
@@ -104,6 +226,7 @@ type Comando struct {
 	ClienteID string
 	Tipo      string // Ex: "JOGAR_CARTA"
 	Payload   json.RawMessage
+	Resposta  chan interface{} // Opcional: recebe o resultado quando quem enviou espera pelo comando
 }
 
 /* ===================== Erro ===================== */
@@ -3,6 +3,7 @@ package api
 import (
 	"jogodistribuido/protocolo"
 	"jogodistribuido/servidor/cluster"
+	"jogodistribuido/servidor/replay"
 	"jogodistribuido/servidor/tipos"
 	"log"
 
@@ -12,7 +13,7 @@ import (
 // ServidorInterface define as operações que a API pode precisar do Servidor principal (não relacionadas a cluster)
 type ServidorInterface interface {
 	EncaminharParaLider(*gin.Context)
-	FormarPacote() ([]tipos.Carta, error)
+	FormarPacote(clienteID string) ([]tipos.Carta, error)
 	NotificarCompraSucesso(string, []tipos.Carta)
 	GetStatusEstoque() (map[string]int, int)
 	GetFilaDeEspera() []*tipos.Cliente
@@ -20,7 +21,7 @@ type ServidorInterface interface {
 	AtualizarEstadoSalaRemoto(estado tipos.EstadoPartida)
 	CriarSalaRemota(solicitante, oponente *tipos.Cliente)
 	CriarSalaRemotaComSombra(solicitante, oponente *tipos.Cliente, shadowAddr string) string
-	RemoverPrimeiroDaFila() *tipos.Cliente
+	RemoverOponenteCompativel(rating, janela int, variante, formato string) *tipos.Cliente
 	PublicarParaCliente(clienteID string, msg protocolo.Mensagem)
 	AjustarContagemCartasLocal(clienteID string, msg *protocolo.Mensagem)
 	ProcessarComandoRemoto(salaID string, comando protocolo.Mensagem) error
@@ -30,6 +31,13 @@ type ServidorInterface interface {
 	ProcessarTrocaDireta(sala *tipos.Sala, req *protocolo.TrocarCartasReq)
 	AplicarTrocaLocal(clienteID string, idCartaDesejada string, cartaOferecida tipos.Carta) (bool, tipos.Carta, []tipos.Carta)
 	BuscarCartaEmCliente(clienteID, cartaID string) tipos.Carta
+	ExportarPartida(salaID string) (replay.Exportacao, bool)
+	JogarAutomaticamente(salaID, clienteID string) error
+	AssinarResultadoComoSombra(req tipos.AssinarResultadoRequest) (tipos.AssinarResultadoResponse, error)
+	ListarAnunciosMercado() ([]tipos.AnuncioMercado, error)
+	ObterAnuncioMercado(anuncioID string) (tipos.AnuncioMercado, bool, error)
+	UltimaReconciliacao() (tipos.RelatorioReconciliacao, bool)
+	ReconciliarInventarios() (tipos.RelatorioReconciliacao, error)
 }
 
 type Server struct {
@@ -72,7 +80,25 @@ func (s *Server) setupRoutes() {
 	election := s.router.Group("/election")
 	{
 		election.POST("/vote", s.handleRequestVote)
-		election.POST("/leader", s.handleAnnounceLeader)
+		election.POST("/append_entries", s.handleAppendEntries)
+		election.POST("/snapshot", s.handleSnapshot)
+	}
+
+	// Propostas de entradas do log Raft encaminhadas ao líder pelos seguidores
+	s.router.POST("/raft/propor", authMiddleware(), s.handleProporEntrada)
+
+	// Mercado de cartas: só leitura do estado do GameEconomy, que já é público na blockchain
+	mercado := s.router.Group("/mercado")
+	{
+		mercado.GET("", s.handleListarAnuncios)
+		mercado.GET("/:anuncioID", s.handleObterAnuncio)
+	}
+
+	// Divergências entre o inventário em memória e o da blockchain (expõem IDs de clientes)
+	reconciliacao := s.router.Group("/inventario/reconciliacao", authMiddleware())
+	{
+		reconciliacao.GET("", s.handleRelatorioReconciliacao)
+		reconciliacao.POST("", s.handleExecutarReconciliacao)
 	}
 
 	// Rotas de matchmaking (protegidas por JWT)
@@ -98,6 +124,7 @@ func (s *Server) setupRoutes() {
 		game.POST("/start", s.handleGameStart)
 		game.POST("/event", s.handleGameEvent)
 		game.POST("/replicate", s.handleGameReplicate)
+		game.POST("/turno_esgotado", s.handleTurnoEsgotado)
 	}
 
 	// Rotas de sincronização de partidas (mantidas para compatibilidade, agora dentro do grupo /partida)
@@ -111,5 +138,7 @@ func (s *Server) setupRoutes() {
 		partida.POST("/notificar_pronto", s.handleNotificarPronto)
 		partida.POST("/aplicar_troca_local", s.handleAplicarTrocaLocal)
 		partida.POST("/buscar_carta", s.handleBuscarCarta)
+		partida.GET("/exportar/:salaID", s.handleExportarPartida)
+		partida.POST("/assinar_resultado", s.handleAssinarResultado)
 	}
 }
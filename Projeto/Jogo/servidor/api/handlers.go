@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"jogodistribuido/protocolo"
+	"jogodistribuido/servidor/cluster"
 	"jogodistribuido/servidor/seguranca"
 	"jogodistribuido/servidor/tipos"
 	"log"
@@ -97,34 +98,53 @@ func (s *Server) handleGetServers(c *gin.Context) {
 	c.JSON(http.StatusOK, s.clusterManager.GetServidores())
 }
 
-// Handlers de eleição
+// Handlers de eleição e replicação do log (Raft)
 func (s *Server) handleRequestVote(c *gin.Context) {
-	var req struct {
-		Candidato string `json:"candidato"`
-		Termo     int64  `json:"termo"`
-	}
+	var req cluster.RequisicaoVoto
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição de voto inválida"})
 		return
 	}
-	votoConcedido, termoAtual := s.clusterManager.ProcessarVoto(req.Candidato, req.Termo)
-	c.JSON(http.StatusOK, gin.H{
-		"voto_concedido": votoConcedido,
-		"termo":          termoAtual,
-	})
+	c.JSON(http.StatusOK, s.clusterManager.ProcessarPedidoVoto(req))
 }
 
-func (s *Server) handleAnnounceLeader(c *gin.Context) {
-	var req struct {
-		NovoLider string `json:"novo_lider"`
-		Termo     int64  `json:"termo"`
+func (s *Server) handleAppendEntries(c *gin.Context) {
+	var req cluster.RequisicaoAppendEntries
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição de AppendEntries inválida"})
+		return
 	}
+	c.JSON(http.StatusOK, s.clusterManager.ProcessarAppendEntries(req))
+}
+
+func (s *Server) handleSnapshot(c *gin.Context) {
+	var req cluster.RequisicaoSnapshot
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Anúncio de líder inválido"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requisição de snapshot inválida"})
 		return
 	}
-	s.clusterManager.DeclararLider(req.NovoLider, req.Termo)
-	c.JSON(http.StatusOK, gin.H{"status": "líder anunciado recebido"})
+	c.JSON(http.StatusOK, s.clusterManager.ProcessarSnapshot(req))
+}
+
+func (s *Server) handleProporEntrada(c *gin.Context) {
+	var req struct {
+		Tipo  string          `json:"tipo"`
+		Dados json.RawMessage `json:"dados"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Tipo == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Proposta inválida"})
+		return
+	}
+	if !s.clusterManager.SouLider() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Este servidor não é o líder"})
+		return
+	}
+	indice, err := s.clusterManager.Propor(req.Tipo, req.Dados)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"indice": indice})
 }
 
 // Middleware para verificar se a requisição deve ser processada pelo líder
@@ -150,7 +170,7 @@ func (s *Server) handleComprarPacote(c *gin.Context) {
 		return
 	}
 
-	pacote, err := s.servidor.FormarPacote()
+	pacote, err := s.servidor.FormarPacote(req.ClienteID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Falha ao formar pacote"})
 		return
@@ -324,9 +344,14 @@ func (s *Server) handleBuscarCarta(c *gin.Context) {
 // HANDLERS DE MATCHMAKING GLOBAL
 func (s *Server) handleSolicitarOponente(c *gin.Context) {
 	var req struct {
-		SolicitanteID   string `json:"solicitante_id"`
-		SolicitanteNome string `json:"solicitante_nome"`
-		ServidorOrigem  string `json:"servidor_origem"`
+		SolicitanteID       string `json:"solicitante_id"`
+		SolicitanteNome     string `json:"solicitante_nome"`
+		SolicitanteEndereco string `json:"solicitante_endereco"` // Carteira do solicitante (assina as jogadas)
+		ServidorOrigem      string `json:"servidor_origem"`
+		Rating              int    `json:"rating"`  // Rating (ELO) do solicitante
+		Janela              int    `json:"janela"`  // Diferença de rating que o solicitante já aceita
+		Regras              string `json:"regras"`  // Variante de regras pedida pelo solicitante
+		Formato             string `json:"formato"` // Formato de partida pedido pelo solicitante
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -334,17 +359,23 @@ func (s *Server) handleSolicitarOponente(c *gin.Context) {
 		return
 	}
 
-	// Tenta encontrar um oponente na fila local
-	oponente := s.servidor.RemoverPrimeiroDaFila()
+	// Tenta encontrar um oponente compatível na fila local
+	oponente := s.servidor.RemoverOponenteCompativel(req.Rating, req.Janela, req.Regras, req.Formato)
 
 	if oponente != nil {
 		// Oponente encontrado!
 		log.Printf("[MATCHMAKING_RX] Oponente '%s' encontrado localmente para solicitante '%s' de %s", oponente.Nome, req.SolicitanteNome, req.ServidorOrigem)
+		oponente.Mutex.Lock()
+		enderecoOponente := oponente.EnderecoBlockchain
+		oponente.Mutex.Unlock()
 
 		// Cria um objeto Cliente para o solicitante remoto
 		solicitante := &tipos.Cliente{
-			ID:   req.SolicitanteID,
-			Nome: req.SolicitanteNome,
+			ID:                 req.SolicitanteID,
+			Nome:               req.SolicitanteNome,
+			EnderecoBlockchain: req.SolicitanteEndereco,
+			VarianteFila:       req.Regras,
+			FormatoFila:        req.Formato,
 		}
 
 		// Cria a sala. Servidor local será o Host.
@@ -356,12 +387,13 @@ func (s *Server) handleSolicitarOponente(c *gin.Context) {
 			"servidor_host":      s.servidor.GetMeuEndereco(), // <-- CORREÇÃO: Informa quem é o Host
 			"oponente_nome":      oponente.Nome,               // Retorna o nome do jogador local para o solicitante
 			"oponente_id":        oponente.ID,
+			"oponente_endereco":  enderecoOponente,
 		})
 		return
 	}
 
 	// Não encontrou oponente
-	log.Printf("[MATCHMAKING_RX] Nenhum oponente na fila para '%s'", req.SolicitanteNome)
+	log.Printf("[MATCHMAKING_RX] Nenhum oponente na fila para '%s' (rating %d ± %d)", req.SolicitanteNome, req.Rating, req.Janela)
 	c.JSON(http.StatusOK, gin.H{"partida_encontrada": false})
 }
 
@@ -387,6 +419,7 @@ func (s *Server) handleGameEvent(c *gin.Context) {
 		MatchID:   req.MatchID,
 		EventType: req.EventType,
 		PlayerID:  req.PlayerID,
+		Data:      req.Data,
 		Signature: req.Signature,
 	}
 	if !seguranca.VerifyEventSignature(&event) {
@@ -417,6 +450,36 @@ func (s *Server) handleGameReplicate(c *gin.Context) {
 	// ... (código a ser movido)
 }
 
+// handleTurnoEsgotado recebe do Host o pedido de jogada automática para um jogador conectado a este servidor
+func (s *Server) handleTurnoEsgotado(c *gin.Context) {
+	var req tipos.TurnoEsgotadoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payload inválido"})
+		return
+	}
+	if err := s.servidor.JogarAutomaticamente(req.MatchID, req.PlayerID); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "jogada_automatica_enviada"})
+}
+
+// handleAssinarResultado atende o Host que pede a assinatura da Sombra sobre o resultado da partida.
+// 409 indica que o EventLog desta Sombra ainda não confere (ou não chegou ao MATCH_END).
+func (s *Server) handleAssinarResultado(c *gin.Context) {
+	var req tipos.AssinarResultadoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payload inválido"})
+		return
+	}
+	resposta, err := s.servidor.AssinarResultadoComoSombra(req)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resposta)
+}
+
 // handleEncaminharChat recebe uma mensagem de chat do Host e a retransmite para o cliente local (usado pelo Shadow)
 func (s *Server) handleEncaminharChat(c *gin.Context) {
 	var req struct {
@@ -431,3 +494,66 @@ func (s *Server) handleEncaminharChat(c *gin.Context) {
 	s.servidor.PublicarChatRemoto(req.SalaID, req.NomeJogador, req.Texto)
 	c.JSON(http.StatusOK, gin.H{"status": "chat_relayed"})
 }
+
+// handleExportarPartida exporta o EventLog e o estado de uma sala para o replay (cmd/replay)
+func (s *Server) handleExportarPartida(c *gin.Context) {
+	exportacao, ok := s.servidor.ExportarPartida(c.Param("salaID"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sala não encontrada"})
+		return
+	}
+	c.JSON(http.StatusOK, exportacao)
+}
+
+// handleListarAnuncios lista os anúncios ativos do mercado (?vendedor=0x... filtra por vendedor)
+func (s *Server) handleListarAnuncios(c *gin.Context) {
+	anuncios, err := s.servidor.ListarAnunciosMercado()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if vendedor := c.Query("vendedor"); vendedor != "" {
+		filtrados := make([]tipos.AnuncioMercado, 0, len(anuncios))
+		for _, anuncio := range anuncios {
+			if strings.EqualFold(anuncio.Vendedor, vendedor) {
+				filtrados = append(filtrados, anuncio)
+			}
+		}
+		anuncios = filtrados
+	}
+	c.JSON(http.StatusOK, gin.H{"anuncios": anuncios})
+}
+
+// handleObterAnuncio devolve um anúncio ativo do mercado
+func (s *Server) handleObterAnuncio(c *gin.Context) {
+	anuncio, ativo, err := s.servidor.ObterAnuncioMercado(c.Param("anuncioID"))
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if !ativo {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Anúncio não encontrado ou encerrado"})
+		return
+	}
+	c.JSON(http.StatusOK, anuncio)
+}
+
+// handleRelatorioReconciliacao devolve o relatório da última reconciliação de inventários
+// (executa uma passada se ainda não houve nenhuma)
+func (s *Server) handleRelatorioReconciliacao(c *gin.Context) {
+	if relatorio, ok := s.servidor.UltimaReconciliacao(); ok {
+		c.JSON(http.StatusOK, relatorio)
+		return
+	}
+	s.handleExecutarReconciliacao(c)
+}
+
+// handleExecutarReconciliacao executa uma passada da reconciliação agora e devolve o relatório
+func (s *Server) handleExecutarReconciliacao(c *gin.Context) {
+	relatorio, err := s.servidor.ReconciliarInventarios()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, relatorio)
+}
@@ -0,0 +1,19 @@
+package blockchain
+
+import "github.com/ethereum/go-ethereum"
+
+// Backend é o que o Manager (e o cliente) usa do nó Ethereum. Em produção é o *ethclient.Client
+// conectado ao geth; em testes, o cliente do simulated backend do go-ethereum (pacote simulado),
+// com o GameEconomy implantado em processo.
+type Backend interface {
+	ethereum.ContractCaller
+	ethereum.ChainStateReader
+	ethereum.PendingStateReader
+	ethereum.GasPricer
+	ethereum.GasEstimator
+	ethereum.TransactionReader
+	ethereum.TransactionSender
+	ethereum.ChainIDReader
+	ethereum.BlockNumberReader
+	ethereum.LogFilterer
+}
@@ -2,20 +2,26 @@ package blockchain
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"math/big"
-	"strings"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
+	"jogodistribuido/servidor/blockchain/contrato"
+	"jogodistribuido/servidor/indexador"
 	"jogodistribuido/servidor/tipos"
 )
 
@@ -24,19 +30,30 @@ const (
 	DefaultRPCURL = "http://geth-node:8545"
 	// Gas limit para transações
 	DefaultGasLimit = uint64(80000000)
+	// Quanto ObterInventario espera o indexador alcançar o bloco da última transação do servidor
+	EsperaIndexador = 5 * time.Second
 )
 
 // Manager gerencia a interação com a blockchain
 type Manager struct {
-	client           *ethclient.Client
+	client           Backend
 	rpcClient        *rpc.Client
 	contractAddress  common.Address
-	contractABI      abi.ABI
+	leitor           *contrato.GameEconomyCaller // Consultas tipadas (bindings gerados)
 	serverAccount    common.Address
-	serverKey        *keystore.Key
+	chaves           map[common.Address]*ecdsa.PrivateKey // Contas assinadas localmente (servidor e AdicionarChave)
 	serverPassword   string
 	keystorePath     string
 	gasLimit         uint64
+
+	fila               *filaTransacoes    // Nonces por remetente e transações pendentes (fila.go)
+	indexador          *indexador.Cliente // Opcional: consultas de inventário sem chamar o contrato
+	assinaturas        Backend            // Opcional: conexão websocket para ObservarInventarios (ponte.go)
+	mutexBloco         sync.Mutex
+	ultimoBlocoEscrito uint64 // Bloco da última transação confirmada (ou evento observado) por este Manager
+
+	compras      map[common.Address]CompraPendente // Compras comprometidas cujo segredo está aqui (pacote.go)
+	mutexCompras sync.Mutex
 }
 
 // NewManager cria um novo gerenciador de blockchain
@@ -57,122 +74,114 @@ func NewManager(rpcURL, contractAddressHex, keystorePath, serverPassword string)
 	// Parse do endereço do contrato
 	contractAddress := common.HexToAddress(contractAddressHex)
 
-	// Carrega o ABI do contrato
-	// Tenta vários caminhos possíveis
-	abiPaths := []string{
-		"../Blockchain/contracts/GameEconomy.abi",
-		"../../Blockchain/contracts/GameEconomy.abi",
-		"../../../Blockchain/contracts/GameEconomy.abi",
-		"/app/Blockchain/contracts/GameEconomy.abi", // Docker
-	}
-	
-	var abiBytes []byte
-	for _, abiPath := range abiPaths {
-		abiBytes, err = ioutil.ReadFile(abiPath)
-		if err == nil {
-			break
-		}
-	}
-	
-	if err != nil {
-		return nil, fmt.Errorf("erro ao ler arquivo ABI (tentou: %v): %v", abiPaths, err)
-	}
-
-	parsedABI, err := abi.JSON(strings.NewReader(string(abiBytes)))
-	if err != nil {
-		return nil, fmt.Errorf("erro ao fazer parse do ABI: %v", err)
-	}
-
 	// Carrega a conta do servidor (para registrar partidas)
-	var serverAccount common.Address
-	var serverKey *keystore.Key
+	var serverKey *ecdsa.PrivateKey
 
 	if keystorePath != "" && serverPassword != "" {
-		// Lista arquivos do keystore
-		files, err := ioutil.ReadDir(keystorePath)
-		if err == nil && len(files) > 0 {
-			// Usa o primeiro arquivo encontrado
-			keyFile := files[0].Name()
-			keyPath := keystorePath + "/" + keyFile
-
-			jsonBytes, err := ioutil.ReadFile(keyPath)
-			if err == nil {
-				key, err := keystore.DecryptKey(jsonBytes, serverPassword)
-				if err == nil {
-					serverKey = key
-					serverAccount = key.Address
-				}
-			}
+		keyPath, err := arquivoDaConta(keystorePath)
+		if err != nil {
+			return nil, err
+		}
+		jsonBytes, err := ioutil.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao ler o keystore %s: %v", keyPath, err)
+		}
+		key, err := keystore.DecryptKey(jsonBytes, serverPassword)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao abrir o keystore %s: %v", keyPath, err)
 		}
+		serverKey = key.PrivateKey
 	}
 
-	return &Manager{
-		client:          client,
-		rpcClient:       rpcClient,
-		contractAddress: contractAddress,
-		contractABI:     parsedABI,
-		serverAccount:   serverAccount,
-		serverKey:       serverKey,
-		serverPassword:  serverPassword,
-		keystorePath:    keystorePath,
-		gasLimit:        DefaultGasLimit,
-	}, nil
+	m, err := NewManagerComBackend(client, rpcClient, contractAddress, serverKey)
+	if err != nil {
+		return nil, err
+	}
+	m.serverPassword = serverPassword
+	m.keystorePath = keystorePath
+	return m, nil
 }
 
-// ComprarPacote processa a compra de um pacote de cartas na blockchain
-// Retorna os IDs das cartas criadas
-func (m *Manager) ComprarPacote(jogadorAddress common.Address, valor *big.Int) ([]*big.Int, error) {
-	// Prepara a chamada à função comprarPacote
-	data, err := m.contractABI.Pack("comprarPacote")
+// arquivoDaConta resolve KEYSTORE_PATH para o arquivo da conta deste servidor. O caminho deve ser o
+// arquivo do keystore; um diretório só é aceito se tiver um único arquivo. Cada servidor precisa de
+// uma conta própria: o contrato recusa resultados com Host e Sombra na mesma conta, e um diretório
+// compartilhado faria todos os servidores assinarem com o primeiro arquivo encontrado.
+func arquivoDaConta(keystorePath string) (string, error) {
+	info, err := os.Stat(keystorePath)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao preparar chamada: %v", err)
+		return "", fmt.Errorf("KEYSTORE_PATH inválido: %v", err)
 	}
-
-	// Envia a transação
-	tx, err := m.enviarTransacao(jogadorAddress, data, valor)
+	if !info.IsDir() {
+		return keystorePath, nil
+	}
+	files, err := ioutil.ReadDir(keystorePath)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao enviar transação: %v", err)
+		return "", fmt.Errorf("falha ao listar o keystore %s: %v", keystorePath, err)
 	}
+	contas := make([]string, 0, len(files))
+	for _, f := range files {
+		if !f.IsDir() {
+			contas = append(contas, filepath.Join(keystorePath, f.Name()))
+		}
+	}
+	if len(contas) != 1 {
+		return "", fmt.Errorf("KEYSTORE_PATH (%s) tem %d contas; aponte para o arquivo da conta deste servidor", keystorePath, len(contas))
+	}
+	return contas[0], nil
+}
 
-	// Aguarda confirmação
-	receipt, err := m.aguardarConfirmacao(tx.Hash())
+// NewManagerComBackend cria o gerenciador sobre um backend já conectado (geth ou simulado)
+// rpcClient é opcional: só é usado para contas sem chave local (personal_sendTransaction)
+// serverKey pode ser nil quando o servidor não registra transações próprias
+func NewManagerComBackend(backend Backend, rpcClient *rpc.Client, contractAddress common.Address, serverKey *ecdsa.PrivateKey) (*Manager, error) {
+	leitor, err := contrato.NewGameEconomyCaller(contractAddress, backend)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao aguardar confirmação: %v", err)
+		return nil, fmt.Errorf("erro ao criar bindings do contrato: %v", err)
 	}
 
-	if receipt.Status == 0 {
-		return nil, fmt.Errorf("transação falhou")
+	m := &Manager{
+		client:          backend,
+		rpcClient:       rpcClient,
+		contractAddress: contractAddress,
+		leitor:          leitor,
+		chaves:          make(map[common.Address]*ecdsa.PrivateKey),
+		gasLimit:        DefaultGasLimit,
+		fila:            novaFilaTransacoes(),
+		compras:         make(map[common.Address]CompraPendente),
+	}
+	if serverKey != nil {
+		m.serverAccount = m.AdicionarChave(serverKey)
 	}
+	return m, nil
+}
+
+// AdicionarChave faz o Manager assinar localmente as transações dessa conta
+func (m *Manager) AdicionarChave(chave *ecdsa.PrivateKey) common.Address {
+	endereco := crypto.PubkeyToAddress(chave.PublicKey)
+	m.chaves[endereco] = chave
+	return endereco
+}
 
-	// Lê o evento PacoteComprado para obter os IDs das cartas
-	// Por enquanto, retorna vazio - o servidor pode consultar o inventário depois
-	return []*big.Int{}, nil
+// UsarIndexador passa a responder ObterInventario pelo indexador de eventos em url
+func (m *Manager) UsarIndexador(url string) {
+	m.indexador = indexador.NewCliente(url)
 }
 
 // ObterInventario retorna o inventário de cartas de um jogador
+// Com indexador configurado, a consulta é uma única chamada HTTP; sem ele (ou se ele falhar),
+// lê os IDs do contrato e busca cada carta
 func (m *Manager) ObterInventario(jogadorAddress common.Address) ([]tipos.Carta, error) {
-	// Prepara a chamada à função obterInventario
-	data, err := m.contractABI.Pack("obterInventario", jogadorAddress)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao preparar chamada: %v", err)
-	}
-
-	// Faz a chamada ao contrato
-	msg := ethereum.CallMsg{
-		To:   &m.contractAddress,
-		Data: data,
-	}
-
-	result, err := m.client.CallContract(context.Background(), msg, nil)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao chamar contrato: %v", err)
+	if m.indexador != nil {
+		cartas, err := m.obterInventarioIndexado(jogadorAddress)
+		if err == nil {
+			return cartas, nil
+		}
+		log.Printf("[BLOCKCHAIN] Indexador indisponível (%v). Consultando o contrato.", err)
 	}
 
-	// Desempacota o resultado (array de uint256)
-	var ids []*big.Int
-	err = m.contractABI.UnpackIntoInterface(&ids, "obterInventario", result)
+	ids, err := m.leitor.ObterInventario(m.opcoesLeitura(), jogadorAddress)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao desempacotar resultado: %v", err)
+		return nil, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
 	}
 
 	log.Printf("[BLOCKCHAIN_DEBUG] ObterInventario(%s) retornou %d IDs: %v", jogadorAddress.Hex(), len(ids), ids)
@@ -191,71 +200,45 @@ func (m *Manager) ObterInventario(jogadorAddress common.Address) ([]tipos.Carta,
 	return cartas, nil
 }
 
-// ObterCarta retorna os dados de uma carta específica
-// Usa o mapeamento público 'cartas' que retorna campos individuais (mais confiável)
-func (m *Manager) ObterCarta(cartaID *big.Int) (tipos.Carta, error) {
-	// Usa o mapeamento público 'cartas' em vez de 'obterCarta'
-	// O mapeamento retorna campos individuais, não uma struct
-	data, err := m.contractABI.Pack("cartas", cartaID)
-	if err != nil {
-		return tipos.Carta{}, fmt.Errorf("erro ao preparar chamada: %v", err)
-	}
-
-	// Faz a chamada ao contrato
-	msg := ethereum.CallMsg{
-		To:   &m.contractAddress,
-		Data: data,
-	}
+// obterInventarioIndexado consulta o indexador, esperando até EsperaIndexador que ele processe
+// o bloco da última transação deste Manager (ex: a compra de pacote que acabou de ser confirmada)
+func (m *Manager) obterInventarioIndexado(jogadorAddress common.Address) ([]tipos.Carta, error) {
+	m.mutexBloco.Lock()
+	blocoNecessario := m.ultimoBlocoEscrito
+	m.mutexBloco.Unlock()
 
-	result, err := m.client.CallContract(context.Background(), msg, nil)
-	if err != nil {
-		return tipos.Carta{}, fmt.Errorf("erro ao chamar contrato: %v", err)
+	limite := time.Now().Add(EsperaIndexador)
+	for {
+		cartas, proximoBloco, err := m.indexador.Inventario(jogadorAddress)
+		if err != nil {
+			return nil, err
+		}
+		if proximoBloco > blocoNecessario {
+			log.Printf("[BLOCKCHAIN_DEBUG] ObterInventario(%s) via indexador: %d cartas", jogadorAddress.Hex(), len(cartas))
+			return cartas, nil
+		}
+		if time.Now().After(limite) {
+			return nil, fmt.Errorf("indexador no bloco %d, esperado %d", proximoBloco, blocoNecessario+1)
+		}
+		time.Sleep(500 * time.Millisecond)
 	}
+}
 
-	// O mapeamento público retorna os campos individualmente (não como struct)
-	// Saída: id, nome, naipe, valor, raridade, timestamp
-	values, err := m.contractABI.Unpack("cartas", result)
+// ObterCarta retorna os dados de uma carta específica
+// Usa o mapeamento público 'cartas' (não reverte para IDs inexistentes, devolve a carta vazia)
+func (m *Manager) ObterCarta(cartaID *big.Int) (tipos.Carta, error) {
+	carta, err := m.leitor.Cartas(m.opcoesLeitura(), cartaID)
 	if err != nil {
-		return tipos.Carta{}, fmt.Errorf("erro ao desempacotar resultado: %v", err)
-	}
-
-	if len(values) < 6 {
-		return tipos.Carta{}, fmt.Errorf("resposta incompleta: esperado 6 campos, recebido %d", len(values))
-	}
-
-	// Extrai os valores individuais
-	id, ok := values[0].(*big.Int)
-	if !ok {
-		return tipos.Carta{}, fmt.Errorf("tipo inválido para id: %T", values[0])
-	}
-
-	nome, ok := values[1].(string)
-	if !ok {
-		return tipos.Carta{}, fmt.Errorf("tipo inválido para nome: %T", values[1])
-	}
-
-	naipe, ok := values[2].(string)
-	if !ok {
-		return tipos.Carta{}, fmt.Errorf("tipo inválido para naipe: %T", values[2])
-	}
-
-	valor, ok := values[3].(*big.Int)
-	if !ok {
-		return tipos.Carta{}, fmt.Errorf("tipo inválido para valor: %T", values[3])
-	}
-
-	raridade, ok := values[4].(string)
-	if !ok {
-		return tipos.Carta{}, fmt.Errorf("tipo inválido para raridade: %T", values[4])
+		return tipos.Carta{}, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
 	}
 
 	// Converte para tipos.Carta
 	return tipos.Carta{
-		ID:       id.String(),
-		Nome:     nome,
-		Naipe:    naipe,
-		Valor:    int(valor.Int64()),
-		Raridade: raridade,
+		ID:       carta.Id.String(),
+		Nome:     carta.Nome,
+		Naipe:    carta.Naipe,
+		Valor:    int(carta.Valor.Int64()),
+		Raridade: carta.Raridade,
 	}, nil
 }
 
@@ -263,7 +246,7 @@ func (m *Manager) ObterCarta(cartaID *big.Int) (tipos.Carta, error) {
 // NOTA: Esta função está DEPRECATED - use RegistrarTrocaAdmin para trocas coordenadas pelo servidor
 func (m *Manager) CriarPropostaTroca(jogador1, jogador2 common.Address, carta1, carta2 *big.Int) (*big.Int, error) {
 	// Prepara a chamada à função criarPropostaTroca
-	data, err := m.contractABI.Pack("criarPropostaTroca", jogador2, carta1, carta2)
+	data, err := contrato.DadosCriarPropostaTroca(jogador2, carta1, carta2)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao preparar chamada: %v", err)
 	}
@@ -271,17 +254,13 @@ func (m *Manager) CriarPropostaTroca(jogador1, jogador2 common.Address, carta1,
 	// Envia a transação (jogador1 é quem cria a proposta)
 	tx, err := m.enviarTransacao(jogador1, data, big.NewInt(0))
 	if err != nil {
-		return nil, fmt.Errorf("erro ao enviar transação: %v", err)
+		return nil, fmt.Errorf("erro ao enviar transação: %w", err)
 	}
 
 	// Aguarda confirmação
-	receipt, err := m.aguardarConfirmacao(tx.Hash())
+	receipt, err := m.confirmarTransacao(jogador1, data, big.NewInt(0), tx)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao aguardar confirmação: %v", err)
-	}
-
-	if receipt.Status == 0 {
-		return nil, fmt.Errorf("transação falhou")
+		return nil, err
 	}
 
 	// Lê o evento PropostaTrocaCriada para obter o ID
@@ -306,7 +285,7 @@ func (m *Manager) RegistrarTrocaAdmin(jogador1, jogador2 common.Address, carta1,
 
 	// Prepara a chamada à função registrarTrocaAdmin
 	// Esta função aceita os 4 parâmetros: jogador1, jogador2, cartaJogador1, cartaJogador2
-	data, err := m.contractABI.Pack("registrarTrocaAdmin", jogador1, jogador2, carta1, carta2)
+	data, err := contrato.DadosRegistrarTrocaAdmin(jogador1, jogador2, carta1, carta2)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao preparar chamada registrarTrocaAdmin: %v", err)
 	}
@@ -315,19 +294,15 @@ func (m *Manager) RegistrarTrocaAdmin(jogador1, jogador2 common.Address, carta1,
 	// Isso é crucial: usamos serverAccount para que msg.sender seja o owner
 	tx, err := m.enviarTransacao(m.serverAccount, data, big.NewInt(0))
 	if err != nil {
-		return nil, fmt.Errorf("erro ao enviar transação: %v", err)
+		return nil, fmt.Errorf("erro ao enviar transação: %w", err)
 	}
 
 	log.Printf("[BLOCKCHAIN] Transação enviada: %s", tx.Hash().Hex())
 
 	// Aguarda confirmação
-	receipt, err := m.aguardarConfirmacao(tx.Hash())
+	receipt, err := m.confirmarTransacao(m.serverAccount, data, big.NewInt(0), tx)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao aguardar confirmação: %v", err)
-	}
-
-	if receipt.Status == 0 {
-		return nil, fmt.Errorf("transação falhou (status=0)")
+		return nil, err
 	}
 
 	log.Printf("[BLOCKCHAIN] Transação confirmada! Status=%d, Logs=%d", receipt.Status, len(receipt.Logs))
@@ -352,7 +327,7 @@ func (m *Manager) RegistrarTrocaAdmin(jogador1, jogador2 common.Address, carta1,
 // AceitarPropostaTroca aceita uma proposta de troca
 func (m *Manager) AceitarPropostaTroca(jogador2 common.Address, propostaID *big.Int) error {
 	// Prepara a chamada à função aceitarPropostaTroca
-	data, err := m.contractABI.Pack("aceitarPropostaTroca", propostaID)
+	data, err := contrato.DadosAceitarPropostaTroca(propostaID)
 	if err != nil {
 		return fmt.Errorf("erro ao preparar chamada: %v", err)
 	}
@@ -360,86 +335,27 @@ func (m *Manager) AceitarPropostaTroca(jogador2 common.Address, propostaID *big.
 	// Envia a transação (jogador2 é quem aceita)
 	tx, err := m.enviarTransacao(jogador2, data, big.NewInt(0))
 	if err != nil {
-		return fmt.Errorf("erro ao enviar transação: %v", err)
+		return fmt.Errorf("erro ao enviar transação: %w", err)
 	}
 
 	// Aguarda confirmação
-	receipt, err := m.aguardarConfirmacao(tx.Hash())
-	if err != nil {
-		return fmt.Errorf("erro ao aguardar confirmação: %v", err)
-	}
-
-	if receipt.Status == 0 {
-		return fmt.Errorf("transação falhou")
-	}
-
-	return nil
-}
-
-// RegistrarPartida registra o resultado de uma partida na blockchain
-func (m *Manager) RegistrarPartida(jogador1, jogador2, vencedor common.Address) error {
-	// Prepara a chamada à função registrarPartida
-	data, err := m.contractABI.Pack("registrarPartida", jogador1, jogador2, vencedor)
-	if err != nil {
-		return fmt.Errorf("erro ao preparar chamada: %v", err)
-	}
-
-	// Envia a transação usando a conta do servidor
-	tx, err := m.enviarTransacao(m.serverAccount, data, big.NewInt(0))
-	if err != nil {
-		return fmt.Errorf("erro ao enviar transação: %v", err)
-	}
-
-	// Aguarda confirmação
-	receipt, err := m.aguardarConfirmacao(tx.Hash())
-	if err != nil {
-		return fmt.Errorf("erro ao aguardar confirmação: %v", err)
-	}
-
-	if receipt.Status == 0 {
-		return fmt.Errorf("transação falhou")
-	}
-
-	return nil
+	_, err = m.confirmarTransacao(jogador2, data, big.NewInt(0), tx)
+	return err
 }
 
 // VerificarPropriedadeCarta verifica se um jogador possui uma carta específica
 func (m *Manager) VerificarPropriedadeCarta(jogador common.Address, cartaID *big.Int) (bool, error) {
-	// Prepara a chamada à função proprietario
-	data, err := m.contractABI.Pack("proprietario", cartaID)
-	if err != nil {
-		return false, fmt.Errorf("erro ao preparar chamada: %v", err)
-	}
-
-	// Faz a chamada ao contrato
-	msg := ethereum.CallMsg{
-		To:   &m.contractAddress,
-		Data: data,
-	}
-
-	result, err := m.client.CallContract(context.Background(), msg, nil)
+	proprietario, err := m.leitor.Proprietario(m.opcoesLeitura(), cartaID)
 	if err != nil {
-		return false, fmt.Errorf("erro ao chamar contrato: %v", err)
-	}
-
-	// Desempacota o resultado (address)
-	var proprietario common.Address
-	err = m.contractABI.UnpackIntoInterface(&proprietario, "proprietario", result)
-	if err != nil {
-		return false, fmt.Errorf("erro ao desempacotar resultado: %v", err)
+		return false, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
 	}
 
 	return proprietario == jogador, nil
 }
 
 // enviarTransacao envia uma transação para a blockchain
+// O nonce vem da fila do remetente (fila.go), o que permite envios concorrentes da mesma conta
 func (m *Manager) enviarTransacao(from common.Address, data []byte, valor *big.Int) (*types.Transaction, error) {
-	// Obtém nonce
-	nonce, err := m.client.PendingNonceAt(context.Background(), from)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao obter nonce: %v", err)
-	}
-
 	// Obtém gas price
 	gasPrice, err := m.client.SuggestGasPrice(context.Background())
 	if err != nil {
@@ -455,6 +371,10 @@ func (m *Manager) enviarTransacao(from common.Address, data []byte, valor *big.I
 		Data:  data,
 	}
 	gasEstimate, err := m.client.EstimateGas(context.Background(), msg)
+	if erroContrato, ok := contrato.Revertido(err); ok {
+		// O require já falha na estimativa: não adianta gastar gas enviando
+		return nil, erroContrato
+	}
 	if err == nil {
 		gasToUse = gasEstimate * 120 / 100
 		if gasToUse > m.gasLimit {
@@ -465,95 +385,76 @@ func (m *Manager) enviarTransacao(from common.Address, data []byte, valor *big.I
 		}
 	}
 
-	// Obtém chain ID
-	chainID, err := m.client.NetworkID(context.Background())
-	if err != nil {
-		return nil, fmt.Errorf("erro ao obter chain ID: %v", err)
-	}
-
-	// Cria transação
-	tx := types.NewTransaction(nonce, m.contractAddress, valor, gasToUse, gasPrice, data)
-
-	// Se for a conta do servidor, assina com a chave privada
-	if from == m.serverAccount && m.serverKey != nil {
-		txAssinada, err := types.SignTx(tx, types.NewEIP155Signer(chainID), m.serverKey.PrivateKey)
-		if err != nil {
-			return nil, fmt.Errorf("erro ao assinar transação: %v", err)
-		}
-
-		err = m.client.SendTransaction(context.Background(), txAssinada)
-		if err != nil {
-			return nil, fmt.Errorf("erro ao enviar transação: %v", err)
-		}
+	return m.enfileirarTransacao(from, data, valor, gasToUse, gasPrice)
+}
 
-		return txAssinada, nil
+// confirmarTransacao aguarda o recibo da transação enviada com enviarTransacao(from, data, valor)
+// Se ela reverteu, repete a chamada no estado anterior ao bloco para recuperar o motivo do require
+// como *contrato.ErroContrato
+func (m *Manager) confirmarTransacao(from common.Address, data []byte, valor *big.Int, tx *types.Transaction) (*types.Receipt, error) {
+	receipt, err := m.aguardarConfirmacao(tx.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao aguardar confirmação: %v", err)
 	}
-
-	// Para outras contas, usa personal_sendTransaction (requer que a conta esteja desbloqueada)
-	if m.rpcClient != nil {
-		// Desbloqueia a conta
-		var unlockResult bool
-		err := m.rpcClient.Call(&unlockResult, "personal_unlockAccount", from, m.serverPassword, 0)
-		if err != nil || !unlockResult {
-			return nil, fmt.Errorf("erro ao desbloquear conta: %v", err)
-		}
-
-		// Prepara parâmetros
-		txParams := map[string]interface{}{
-			"from":     from.Hex(),
-			"to":       m.contractAddress.Hex(),
-			"value":    fmt.Sprintf("0x%x", valor),
-			"data":     fmt.Sprintf("0x%x", data),
-			"gas":      fmt.Sprintf("0x%x", gasToUse),
-			"gasPrice": fmt.Sprintf("0x%x", gasPrice),
-		}
-
-		// Envia via personal_sendTransaction
-		var txHashStr string
-		err = m.rpcClient.Call(&txHashStr, "personal_sendTransaction", txParams, m.serverPassword)
-		if err != nil {
-			return nil, fmt.Errorf("erro ao enviar transação via RPC: %v", err)
-		}
-
-		txHash := common.HexToHash(txHashStr)
-		// Aguarda um pouco e tenta obter a transação
-		time.Sleep(1 * time.Second)
-		tx, _, err := m.client.TransactionByHash(context.Background(), txHash)
-		if err != nil {
-			// Cria uma transação dummy com o hash correto
-			tx = types.NewTransaction(nonce, m.contractAddress, valor, gasToUse, gasPrice, data)
+	if receipt.Status == 0 {
+		msg := ethereum.CallMsg{From: from, To: &m.contractAddress, Value: valor, Data: data}
+		anterior := new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+		_, err := m.client.CallContract(context.Background(), msg, anterior)
+		if erroContrato, ok := contrato.Revertido(err); ok {
+			return nil, fmt.Errorf("transação falhou: %w", erroContrato)
 		}
-
-		return tx, nil
+		return nil, fmt.Errorf("transação falhou (status=0)")
 	}
+	return receipt, nil
+}
 
-	return nil, fmt.Errorf("não foi possível enviar transação: conta não é do servidor e RPC não disponível")
+// opcoesLeitura são as opções das consultas pelos bindings (bloco mais recente)
+func (m *Manager) opcoesLeitura() *bind.CallOpts {
+	return &bind.CallOpts{Context: context.Background()}
 }
 
 // aguardarConfirmacao aguarda a confirmação de uma transação
+// Se a transação está na fila, qualquer uma das suas versões vale. As substituições (e o
+// cancelamento do nonce) ficam com acompanharPendentes, que continua depois do timeout daqui.
 func (m *Manager) aguardarConfirmacao(txHash common.Hash) (*types.Receipt, error) {
 	timeout := 60 * time.Second
 	startTime := time.Now()
 
 	for {
 		if time.Since(startTime) > timeout {
-			return nil, fmt.Errorf("timeout aguardando confirmação")
+			return nil, fmt.Errorf("timeout aguardando confirmação (a transação segue acompanhada em segundo plano)")
 		}
 
-		receipt, err := m.client.TransactionReceipt(context.Background(), txHash)
-		if err == nil {
-			return receipt, nil
+		hashes := []common.Hash{txHash}
+		pendente, naFila := m.fila.porHashCopia(txHash)
+		if naFila {
+			hashes = pendente.Hashes
 		}
 
-		if err != ethereum.NotFound {
-			time.Sleep(1 * time.Second)
-			continue
+		if receipt := m.buscarRecibo(hashes); receipt != nil {
+			if naFila {
+				m.concluir("CONFIRMADA", pendente.chave())
+			}
+			m.marcarBloco(receipt.BlockNumber.Uint64())
+			if naFila && pendente.cancelamento(receipt.TxHash) {
+				return nil, fmt.Errorf("transação cancelada para liberar o nonce %d", pendente.Nonce)
+			}
+			return receipt, nil
 		}
 
 		time.Sleep(2 * time.Second)
 	}
 }
 
+// marcarBloco registra um bloco com mudanças que as consultas ao indexador precisam enxergar
+func (m *Manager) marcarBloco(bloco uint64) {
+	m.mutexBloco.Lock()
+	if bloco > m.ultimoBlocoEscrito {
+		m.ultimoBlocoEscrito = bloco
+	}
+	m.mutexBloco.Unlock()
+}
+
 // GetContractAddress retorna o endereço do contrato
 func (m *Manager) GetContractAddress() common.Address {
 	return m.contractAddress
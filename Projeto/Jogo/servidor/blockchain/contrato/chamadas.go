@@ -0,0 +1,109 @@
+package contrato
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Calldata das transações do GameEconomy. O Manager envia pela fila de nonces e o cliente assina
+// com a própria carteira, então os dois precisam só dos dados da chamada, com argumentos tipados.
+
+// DadosComprometerCompraPacote paga o pacote e compromete HashCompromisso(comprador, segredo);
+// as cartas só saem em DadosRevelarPacote, num bloco posterior.
+func DadosComprometerCompraPacote(compromisso common.Hash) ([]byte, error) {
+	return empacotar("comprometerCompraPacote", [32]byte(compromisso))
+}
+
+func DadosRevelarPacote(segredo common.Hash) ([]byte, error) {
+	return empacotar("revelarPacote", [32]byte(segredo))
+}
+
+func DadosExpirarCompraPacote(comprador common.Address) ([]byte, error) {
+	return empacotar("expirarCompraPacote", comprador)
+}
+
+// HashCompromisso reproduz hashCompromisso do contrato (keccak256 do comprador e do segredo).
+func HashCompromisso(comprador common.Address, segredo common.Hash) common.Hash {
+	return crypto.Keccak256Hash(comprador.Bytes(), segredo.Bytes())
+}
+
+func DadosCriarPropostaTroca(jogador2 common.Address, minhaCarta, cartaDesejada *big.Int) ([]byte, error) {
+	return empacotar("criarPropostaTroca", jogador2, minhaCarta, cartaDesejada)
+}
+
+func DadosAceitarPropostaTroca(propostaID *big.Int) ([]byte, error) {
+	return empacotar("aceitarPropostaTroca", propostaID)
+}
+
+func DadosCancelarPropostaTroca(propostaID *big.Int) ([]byte, error) {
+	return empacotar("cancelarPropostaTroca", propostaID)
+}
+
+func DadosRecusarPropostaTroca(propostaID *big.Int) ([]byte, error) {
+	return empacotar("recusarPropostaTroca", propostaID)
+}
+
+func DadosRegistrarTrocaAdmin(jogador1, jogador2 common.Address, cartaJogador1, cartaJogador2 *big.Int) ([]byte, error) {
+	return empacotar("registrarTrocaAdmin", jogador1, jogador2, cartaJogador1, cartaJogador2)
+}
+
+func DadosAnunciarCarta(tokenID, preco *big.Int) ([]byte, error) {
+	return empacotar("anunciarCarta", tokenID, preco)
+}
+
+// DadosComprarCartaAnunciada vai com valor igual ao preço do anúncio.
+func DadosComprarCartaAnunciada(anuncioID *big.Int) ([]byte, error) {
+	return empacotar("comprarCartaAnunciada", anuncioID)
+}
+
+func DadosRetirarAnuncio(anuncioID *big.Int) ([]byte, error) {
+	return empacotar("retirarAnuncio", anuncioID)
+}
+
+func DadosSacarVendas() ([]byte, error) {
+	return empacotar("sacarVendas")
+}
+
+// DadosFundirCartas funde CARTAS_POR_FUSAO cartas de mesmo nome e raridade do remetente
+func DadosFundirCartas(cartas []*big.Int) ([]byte, error) {
+	return empacotar("fundirCartas", cartas)
+}
+
+// DadosRegistrarPartida registra o resultado co-assinado. assinaturas segue a ordem
+// [host, sombra, jogador1, jogador2]; em partida local sombra é o endereço zero e a assinatura
+// da sombra vai vazia.
+func DadosRegistrarPartida(jogador1, jogador2, vencedor common.Address, hashLog common.Hash, host, sombra common.Address, assinaturas [][]byte) ([]byte, error) {
+	return empacotar("registrarPartida", jogador1, jogador2, vencedor, [32]byte(hashLog), host, sombra, assinaturas)
+}
+
+func DadosAutorizarServidor(servidor common.Address, autorizado bool) ([]byte, error) {
+	return empacotar("autorizarServidor", servidor, autorizado)
+}
+
+// HashResultado reproduz hashResultado do contrato: é o hash que Host, Sombra e jogadores
+// assinam (personal_sign) para registrar a partida.
+func HashResultado(contrato, jogador1, jogador2, vencedor common.Address, hashLog common.Hash) common.Hash {
+	return crypto.Keccak256Hash(contrato.Bytes(), jogador1.Bytes(), jogador2.Bytes(), vencedor.Bytes(), hashLog.Bytes())
+}
+
+func DadosTransferirCarta(para common.Address, tokenID *big.Int) ([]byte, error) {
+	return empacotar("transferirCarta", para, tokenID)
+}
+
+func DadosDefinirPrecoPacote(preco *big.Int) ([]byte, error) {
+	return empacotar("definirPrecoPacote", preco)
+}
+
+func DadosRetirarFundos() ([]byte, error) {
+	return empacotar("retirarFundos")
+}
+
+func empacotar(metodo string, args ...interface{}) ([]byte, error) {
+	parsed, err := GameEconomyMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Pack(metodo, args...)
+}
@@ -0,0 +1,39 @@
+// Package contrato reúne o que o servidor e o cliente compartilham sobre o GameEconomy: os bindings
+// tipados (gameeconomy.go, gerado pelo abigen), o ABI e o bytecode embutidos no binário, o calldata
+// tipado das transações e a decodificação dos require do contrato em erros Go.
+//
+// GameEconomy.abi e GameEconomy.bin são versionados em Blockchain/contracts. Depois de alterar
+// GameEconomy.sol, rode Blockchain/scripts/compile-contract.sh (gera os dois de novo) e regenere os
+// bindings a partir de Jogo/:
+//
+//	go generate ./servidor/blockchain/contrato
+package contrato
+
+//go:generate go run github.com/ethereum/go-ethereum/cmd/abigen@v1.13.15 --abi ../../../../Blockchain/contracts/GameEconomy.abi --bin ../../../../Blockchain/contracts/GameEconomy.bin --pkg contrato --type GameEconomy --out gameeconomy.go
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrSemBytecode indica bindings gerados sem GameEconomy.bin (só com o ABI).
+var ErrSemBytecode = errors.New("bytecode do GameEconomy não embutido: rode compile-contract.sh e go generate")
+
+// ABI devolve o ABI embutido do GameEconomy.
+func ABI() (abi.ABI, error) {
+	parsed, err := GameEconomyMetaData.GetAbi()
+	if err != nil {
+		return abi.ABI{}, err
+	}
+	return *parsed, nil
+}
+
+// Bytecode devolve o bytecode de implantação embutido do GameEconomy.
+func Bytecode() ([]byte, error) {
+	if GameEconomyMetaData.Bin == "" {
+		return nil, ErrSemBytecode
+	}
+	return common.FromHex(GameEconomyMetaData.Bin), nil
+}
@@ -0,0 +1,115 @@
+package contrato
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErroContrato é um require do GameEconomy que reverteu. errors.Is compara pelo motivo, então
+// um erro decodificado de qualquer chamada casa com as variáveis abaixo.
+type ErroContrato struct {
+	Motivo string
+}
+
+func (e *ErroContrato) Error() string {
+	return "contrato recusou: " + e.Motivo
+}
+
+func (e *ErroContrato) Is(alvo error) bool {
+	outro, ok := alvo.(*ErroContrato)
+	return ok && outro.Motivo == e.Motivo
+}
+
+// Motivos dos require de GameEconomy.sol (devem acompanhar o texto do contrato)
+var (
+	ErrApenasDono              = &ErroContrato{"Apenas o dono pode executar esta funcao"}
+	ErrNaoPossuiCarta          = &ErroContrato{"Voce nao possui esta carta"}
+	ErrValorInsuficiente       = &ErroContrato{"Valor insuficiente para comprar pacote"}
+	ErrCartaNaoExiste          = &ErroContrato{"Carta nao existe"}
+	ErrTransferirEnderecoZero  = &ErroContrato{"Nao pode transferir para endereco zero"}
+	ErrTransferirParaSi        = &ErroContrato{"Nao pode transferir para si mesmo"}
+	ErrJogadorInvalido         = &ErroContrato{"Jogador invalido"}
+	ErrTrocaConsigoMesmo       = &ErroContrato{"Nao pode trocar consigo mesmo"}
+	ErrJogador1Invalido        = &ErroContrato{"Jogador1 invalido"}
+	ErrJogador2Invalido        = &ErroContrato{"Jogador2 invalido"}
+	ErrJogador1NaoPossuiCarta  = &ErroContrato{"Jogador1 nao possui esta carta"}
+	ErrJogador2NaoPossuiCarta  = &ErroContrato{"Jogador2 nao possui esta carta"}
+	ErrPropostaNaoExiste       = &ErroContrato{"Proposta nao existe"}
+	ErrPropostaInvalida        = &ErroContrato{"Proposta invalida"}
+	ErrNaoEDestinatario        = &ErroContrato{"Voce nao e o destinatario desta proposta"}
+	ErrPropostaExecutada       = &ErroContrato{"Proposta ja foi executada"}
+	ErrJogador1SemCarta        = &ErroContrato{"Jogador1 nao possui mais esta carta"}
+	ErrDestinatarioSemCarta    = &ErroContrato{"Voce nao possui mais esta carta"}
+	ErrRemetenteNaoPossuiCarta = &ErroContrato{"Remetente nao possui a carta"}
+	ErrPartidaConsigoMesmo     = &ErroContrato{"Nao pode jogar consigo mesmo"}
+	ErrVencedorInvalido        = &ErroContrato{"Vencedor deve ser um dos jogadores ou empate"}
+	ErrIndiceInvalido          = &ErroContrato{"Indice invalido"}
+	ErrHashLogInvalido         = &ErroContrato{"Hash do log invalido"}
+	ErrPartidaJaRegistrada     = &ErroContrato{"Partida ja registrada"}
+	ErrAssinaturasIncompletas  = &ErroContrato{"Assinaturas incompletas"}
+	ErrServidorNaoAutorizado   = &ErroContrato{"Servidor nao autorizado"}
+	ErrServidorInvalido        = &ErroContrato{"Servidor invalido"}
+	ErrAssinaturaHost          = &ErroContrato{"Assinatura do Host invalida"}
+	ErrAssinaturaSombra        = &ErroContrato{"Assinatura da Sombra invalida"}
+	ErrAssinaturaJogador1      = &ErroContrato{"Assinatura do jogador1 invalida"}
+	ErrAssinaturaJogador2      = &ErroContrato{"Assinatura do jogador2 invalida"}
+	ErrCompromissoInvalido     = &ErroContrato{"Compromisso invalido"}
+	ErrCompraPendente          = &ErroContrato{"Compra pendente de revelacao"}
+	ErrSemCompraPendente       = &ErroContrato{"Nenhuma compra pendente"}
+	ErrRevelacaoAntecipada     = &ErroContrato{"Revele em um bloco posterior"}
+	ErrRevelacaoExpirada       = &ErroContrato{"Prazo de revelacao expirado"}
+	ErrSegredoNaoConfere       = &ErroContrato{"Segredo nao confere"}
+	ErrCompraNaoExpirada       = &ErroContrato{"Compra ainda pode ser revelada"}
+	ErrPropostaCancelada       = &ErroContrato{"Proposta foi cancelada"}
+	ErrPropostaRecusada        = &ErroContrato{"Proposta foi recusada"}
+	ErrPropostaExpirada        = &ErroContrato{"Proposta expirada"}
+	ErrNaoCriouProposta        = &ErroContrato{"Voce nao criou esta proposta"}
+	ErrPrecoInvalido           = &ErroContrato{"Preco invalido"}
+	ErrAnuncioInativo          = &ErroContrato{"Anuncio inativo"}
+	ErrCompraProprioAnuncio    = &ErroContrato{"Nao pode comprar o proprio anuncio"}
+	ErrValorDiferentePreco     = &ErroContrato{"Valor diferente do preco"}
+	ErrNaoEVendedor            = &ErroContrato{"Voce nao e o vendedor"}
+	ErrNadaASacar              = &ErroContrato{"Nada a sacar"}
+	ErrQuantidadeFusao         = &ErroContrato{"Quantidade de cartas invalida para fusao"}
+	ErrCartaRepetidaFusao      = &ErroContrato{"Carta repetida na fusao"}
+	ErrNomesDiferentes         = &ErroContrato{"Cartas com nomes diferentes"}
+	ErrRaridadesDiferentes     = &ErroContrato{"Cartas com raridades diferentes"}
+	ErrRaridadeNaoFundivel     = &ErroContrato{"Raridade nao pode ser fundida"}
+)
+
+// DecodificarErro devolve o *ErroContrato contido em err (eth_call ou eth_estimateGas que
+// reverteu com motivo) ou o próprio err quando ele não traz dados de revert.
+func DecodificarErro(err error) error {
+	if erroContrato, ok := Revertido(err); ok {
+		return erroContrato
+	}
+	return err
+}
+
+// Revertido diz se err é um revert do contrato com motivo e qual.
+func Revertido(err error) (*ErroContrato, bool) {
+	var comDados rpc.DataError
+	if err == nil || !errors.As(err, &comDados) {
+		return nil, false
+	}
+	var dados []byte
+	switch d := comDados.ErrorData().(type) {
+	case string:
+		dados = common.FromHex(d)
+	case []byte:
+		dados = d
+	}
+	return MotivoRevert(dados)
+}
+
+// MotivoRevert decodifica os dados de revert de um require (Error(string)).
+func MotivoRevert(dados []byte) (*ErroContrato, bool) {
+	motivo, err := abi.UnpackRevert(dados)
+	if err != nil {
+		return nil, false
+	}
+	return &ErroContrato{Motivo: motivo}, true
+}
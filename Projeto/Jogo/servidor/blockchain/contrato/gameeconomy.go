@@ -0,0 +1,4385 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contrato
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// Anuncio is an auto generated low-level Go binding around an user-defined struct.
+type Anuncio struct {
+	Vendedor  common.Address
+	CartaId   *big.Int
+	Preco     *big.Int
+	Ativo     bool
+	Timestamp *big.Int
+}
+
+// Carta is an auto generated low-level Go binding around an user-defined struct.
+type Carta struct {
+	Id        *big.Int
+	Nome      string
+	Naipe     string
+	Valor     *big.Int
+	Raridade  string
+	Timestamp *big.Int
+}
+
+// Partida is an auto generated low-level Go binding around an user-defined struct.
+type Partida struct {
+	Jogador1       common.Address
+	Jogador2       common.Address
+	Vencedor       common.Address
+	Timestamp      *big.Int
+	HashLog        [32]byte
+	ServidorHost   common.Address
+	ServidorSombra common.Address
+}
+
+// PropostaTroca is an auto generated low-level Go binding around an user-defined struct.
+type PropostaTroca struct {
+	Jogador1      common.Address
+	Jogador2      common.Address
+	CartaJogador1 *big.Int
+	CartaJogador2 *big.Int
+	Aceita        bool
+	Executada     bool
+	Timestamp     *big.Int
+	ExpiraEm      *big.Int
+	Cancelada     bool
+	Recusada      bool
+}
+
+// GameEconomyMetaData contains all meta data concerning the GameEconomy contract.
+var GameEconomyMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"anuncioId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"vendedor\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"cartaId\",\"type\":\"uint256\"}],\"name\":\"AnuncioRetirado\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"anuncioId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"vendedor\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"cartaId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"preco\",\"type\":\"uint256\"}],\"name\":\"CartaAnunciada\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"proprietario\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"nome\",\"type\":\"string\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"raridade\",\"type\":\"string\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"valor\",\"type\":\"uint256\"}],\"name\":\"CartaCriada\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"proprietario\",\"type\":\"address\"}],\"name\":\"CartaQueimada\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"de\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"para\",\"type\":\"address\"}],\"name\":\"CartaTransferida\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"anuncioId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"vendedor\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"comprador\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"cartaId\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"preco\",\"type\":\"uint256\"}],\"name\":\"CartaVendida\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"proprietario\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256[]\",\"name\":\"queimadas\",\"type\":\"uint256[]\"},{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"novaCarta\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"string\",\"name\":\"raridade\",\"type\":\"string\"}],\"name\":\"CartasFundidas\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"comprador\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"compromisso\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"bloco\",\"type\":\"uint256\"}],\"name\":\"CompraComprometida\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"comprador\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"bloco\",\"type\":\"uint256\"}],\"name\":\"CompraExpirada\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"comprador\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256[]\",\"name\":\"tokenIds\",\"type\":\"uint256[]\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"}],\"name\":\"PacoteComprado\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"jogador1\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"jogador2\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"vencedor\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"hashLog\",\"type\":\"bytes32\"}],\"name\":\"PartidaRegistrada\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"propostaId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"jogador1\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"jogador2\",\"type\":\"address\"}],\"name\":\"PropostaTrocaCancelada\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"propostaId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"jogador1\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"jogador2\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"cartaJogador1\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"cartaJogador2\",\"type\":\"uint256\"}],\"name\":\"PropostaTrocaCriada\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"propostaId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"jogador1\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"jogador2\",\"type\":\"address\"}],\"name\":\"PropostaTrocaRecusada\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"servidor\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"bool\",\"name\":\"autorizado\",\"type\":\"bool\"}],\"name\":\"ServidorAutorizado\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint256\",\"name\":\"propostaId\",\"type\":\"uint256\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"jogador1\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"jogador2\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"cartaJogador1\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"cartaJogador2\",\"type\":\"uint256\"}],\"name\":\"TrocaExecutada\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"vendedor\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"valor\",\"type\":\"uint256\"}],\"name\":\"VendasSacadas\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"CARTAS_POR_FUSAO\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"CARTAS_POR_PACOTE\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"PRAZO_REVELACAO\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"VALIDADE_PROPOSTA\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"propostaId\",\"type\":\"uint256\"}],\"name\":\"aceitarPropostaTroca\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"_preco\",\"type\":\"uint256\"}],\"name\":\"anunciarCarta\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"anuncios\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"vendedor\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"cartaId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"preco\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"ativo\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_servidor\",\"type\":\"address\"},{\"internalType\":\"bool\",\"name\":\"_autorizado\",\"type\":\"bool\"}],\"name\":\"autorizarServidor\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"propostaId\",\"type\":\"uint256\"}],\"name\":\"cancelarPropostaTroca\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"cartas\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"id\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"nome\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"naipe\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"valor\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"raridade\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"anuncioId\",\"type\":\"uint256\"}],\"name\":\"comprarCartaAnunciada\",\"outputs\":[],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"comprasComprometidas\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"comprasPendentes\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"compromisso\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"bloco\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"_compromisso\",\"type\":\"bytes32\"}],\"name\":\"comprometerCompraPacote\",\"outputs\":[],\"stateMutability\":\"payable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_jogador2\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"_minhaCarta\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"_cartaDesejada\",\"type\":\"uint256\"}],\"name\":\"criarPropostaTroca\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_novoPreco\",\"type\":\"uint256\"}],\"name\":\"definirPrecoPacote\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_comprador\",\"type\":\"address\"}],\"name\":\"expirarCompraPacote\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256[]\",\"name\":\"_cartas\",\"type\":\"uint256[]\"}],\"name\":\"fundirCartas\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_comprador\",\"type\":\"address\"},{\"internalType\":\"bytes32\",\"name\":\"_segredo\",\"type\":\"bytes32\"}],\"name\":\"hashCompromisso\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"pure\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_jogador1\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"_jogador2\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"_vencedor\",\"type\":\"address\"},{\"internalType\":\"bytes32\",\"name\":\"_hashLog\",\"type\":\"bytes32\"}],\"name\":\"hashResultado\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"inventario\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"anuncioId\",\"type\":\"uint256\"}],\"name\":\"obterAnuncio\",\"outputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"vendedor\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"cartaId\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"preco\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"ativo\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"}],\"internalType\":\"structAnuncio\",\"name\":\"\",\"type\":\"tuple\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"obterAnunciosAtivos\",\"outputs\":[{\"internalType\":\"uint256[]\",\"name\":\"\",\"type\":\"uint256[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"}],\"name\":\"obterCarta\",\"outputs\":[{\"components\":[{\"internalType\":\"uint256\",\"name\":\"id\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"nome\",\"type\":\"string\"},{\"internalType\":\"string\",\"name\":\"naipe\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"valor\",\"type\":\"uint256\"},{\"internalType\":\"string\",\"name\":\"raridade\",\"type\":\"string\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"}],\"internalType\":\"structCarta\",\"name\":\"\",\"type\":\"tuple\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_jogador\",\"type\":\"address\"}],\"name\":\"obterInventario\",\"outputs\":[{\"internalType\":\"uint256[]\",\"name\":\"\",\"type\":\"uint256[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"indice\",\"type\":\"uint256\"}],\"name\":\"obterPartida\",\"outputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"jogador1\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"jogador2\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"vencedor\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"hashLog\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"servidorHost\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"servidorSombra\",\"type\":\"address\"}],\"internalType\":\"structPartida\",\"name\":\"\",\"type\":\"tuple\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"propostaId\",\"type\":\"uint256\"}],\"name\":\"obterPropostaTroca\",\"outputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"jogador1\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"jogador2\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"cartaJogador1\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"cartaJogador2\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"aceita\",\"type\":\"bool\"},{\"internalType\":\"bool\",\"name\":\"executada\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"expiraEm\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"cancelada\",\"type\":\"bool\"},{\"internalType\":\"bool\",\"name\":\"recusada\",\"type\":\"bool\"}],\"internalType\":\"structPropostaTroca\",\"name\":\"\",\"type\":\"tuple\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_jogador\",\"type\":\"address\"}],\"name\":\"obterPropostasEnviadas\",\"outputs\":[{\"internalType\":\"uint256[]\",\"name\":\"\",\"type\":\"uint256[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_jogador\",\"type\":\"address\"}],\"name\":\"obterPropostasRecebidas\",\"outputs\":[{\"internalType\":\"uint256[]\",\"name\":\"\",\"type\":\"uint256[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_jogador\",\"type\":\"address\"}],\"name\":\"obterSaldo\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"obterTotalPartidas\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"partidaPorLog\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"partidas\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"jogador1\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"jogador2\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"vencedor\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"hashLog\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"servidorHost\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"servidorSombra\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"precoPacote\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"propostasTroca\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"jogador1\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"jogador2\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"cartaJogador1\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"cartaJogador2\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"aceita\",\"type\":\"bool\"},{\"internalType\":\"bool\",\"name\":\"executada\",\"type\":\"bool\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"expiraEm\",\"type\":\"uint256\"},{\"internalType\":\"bool\",\"name\":\"cancelada\",\"type\":\"bool\"},{\"internalType\":\"bool\",\"name\":\"recusada\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"name\":\"proprietario\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"propostaId\",\"type\":\"uint256\"}],\"name\":\"recusarPropostaTroca\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_jogador1\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"_jogador2\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"_vencedor\",\"type\":\"address\"},{\"internalType\":\"bytes32\",\"name\":\"_hashLog\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"_servidorHost\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"_servidorSombra\",\"type\":\"address\"},{\"internalType\":\"bytes[]\",\"name\":\"_assinaturas\",\"type\":\"bytes[]\"}],\"name\":\"registrarPartida\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_jogador1\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"_jogador2\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"_cartaJogador1\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"_cartaJogador2\",\"type\":\"uint256\"}],\"name\":\"registrarTrocaAdmin\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"anuncioId\",\"type\":\"uint256\"}],\"name\":\"retirarAnuncio\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"retirarFundos\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"_segredo\",\"type\":\"bytes32\"}],\"name\":\"revelarPacote\",\"outputs\":[{\"internalType\":\"uint256[]\",\"name\":\"\",\"type\":\"uint256[]\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"sacarVendas\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"saldo\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"saldoVendas\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"servidoresAutorizados\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_para\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"tokenId\",\"type\":\"uint256\"}],\"name\":\"transferirCarta\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+	Bin: "0x6080604052670de0b6b3a764000060125534801561001c57600080fd5b50601580546001600160a01b03191633179055600080805560065561562c80620000476000396000f3fe6080604052600436106102935760003560e01c806389e34d1a1161015a578063cba5ca8b116100c1578063ed097d5c1161007a578063ed097d5c14610b09578063f070432614610b29578063f1bd0d4a14610b69578063f4aaff2f14610b89578063f651bcbc14610c0c578063feaac08c14610c2157600080fd5b8063cba5ca8b14610a3c578063d0ab4ba714610a5c578063d26bc1c814610a7c578063d591e1ce14610a9c578063df9c139614610ac9578063e4d594dc14610ae957600080fd5b8063aa2e4c3811610113578063aa2e4c38146108e2578063ab97fd6014610918578063b289029614610938578063c4c7ab5a1461094d578063c4fb8027146109b4578063c905b101146109d457600080fd5b806389e34d1a1461082b5780638da5cb5b146108425780638e0c7ceb1461086257806395fad6161461088257806398cd484d146108a2578063a49591dc146108c257600080fd5b8063422fb525116101fe5780635b102ab8116101b75780635b102ab8146107415780635dbabf6c146107735780637475a6a91461079357806375a9ec02146107b35780637e3dcd1f146107c85780637f8f472f146107dd57600080fd5b8063422fb525146105b757806346c0a4c7146105e45780634b071da9146105f757806351317a1f14610617578063535c898a1461064457806355f7f13f1461072157600080fd5b8063275a299e11610250578063275a299e146104b257806328fbad5d146104d257806338e7b059146104ff5780633a5ecc9f1461052c5780633c3d312e146105415780633fa49fae1461056e57600080fd5b806308bb2b0b146102985780630d142b54146102c05780630df61e14146103da5780631002ea4d146103ef578063147321721461040557806314ac61f014610490575b600080fd5b3480156102a457600080fd5b506102ad60fa81565b6040519081526020015b60405180910390f35b3480156102cc57600080fd5b506103cd6102db366004614c2d565b6040805161014081018252600080825260208201819052918101829052606081018290526080810182905260a0810182905260c0810182905260e0810182905261010081018290526101208101919091525060009081526005602081815260409283902083516101408101855281546001600160a01b0390811682526001830154169281019290925260028101549382019390935260038301546060820152600483015460ff80821615156080840152610100918290048116151560a08401529284015460c0830152600684015460e08301526007909301548083161515848301529290920416151561012082015290565b6040516102b79190614c46565b3480156103e657600080fd5b50600f546102ad565b3480156103fb57600080fd5b506102ad60125481565b34801561041157600080fd5b5061045c610420366004614c2d565b600960205260009081526040902080546001820154600283015460038401546004909401546001600160a01b03909316939192909160ff169085565b604080516001600160a01b03909616865260208601949094529284019190915215156060830152608082015260a0016102b7565b34801561049c57600080fd5b506104b06104ab366004614c2d565b610c34565b005b3480156104be57600080fd5b506104b06104cd366004614d06565b610c6c565b3480156104de57600080fd5b506102ad6104ed366004614c2d565b60106020526000908152604090205481565b34801561050b57600080fd5b5061051f61051a366004614d42565b610d40565b6040516102b79190614d9f565b34801561053857600080fd5b506102ad600381565b34801561054d57600080fd5b5061056161055c366004614c2d565b610dac565b6040516102b79190614df8565b34801561057a57600080fd5b506105a2610589366004614d42565b6013602052600090815260409020805460019091015482565b604080519283526020830191909152016102b7565b3480156105c357600080fd5b506102ad6105d2366004614d42565b60046020526000908152604090205481565b6104b06105f2366004614c2d565b61102e565b34801561060357600080fd5b506104b0610612366004614c2d565b6111bb565b34801561062357600080fd5b506102ad610632366004614d42565b600d6020526000908152604090205481565b34801561065057600080fd5b506106c661065f366004614c2d565b6005602081905260009182526040909120805460018201546002830154600384015460048501549585015460068601546007909601546001600160a01b039586169794909516959294919360ff80821694610100928390048216949092818316929104168a565b604080516001600160a01b039b8c1681529a90991660208b01529789019690965260608801949094529115156080870152151560a086015260c085015260e084015215156101008301521515610120820152610140016102b7565b34801561072d57600080fd5b506102ad61073c366004614ee6565b61127d565b34801561074d57600080fd5b5061076161075c366004614c2d565b611994565b6040516102b796959493929190614f7c565b34801561077f57600080fd5b506104b061078e366004614fd5565b611b60565b34801561079f57600080fd5b506102ad6107ae366004615017565b611fb1565b3480156107bf57600080fd5b506104b0612148565b3480156107d457600080fd5b5061051f61221d565b3480156107e957600080fd5b506108136107f8366004614c2d565b6003602052600090815260409020546001600160a01b031681565b6040516001600160a01b0390911681526020016102b7565b34801561083757600080fd5b506102ad6201518081565b34801561084e57600080fd5b50601554610813906001600160a01b031681565b34801561086e57600080fd5b5061051f61087d366004614d42565b612275565b34801561088e57600080fd5b506104b061089d366004614d42565b6122df565b3480156108ae57600080fd5b506104b06108bd366004615039565b61240d565b3480156108ce57600080fd5b506104b06108dd366004615063565b6126b8565b3480156108ee57600080fd5b506102ad6108fd366004614d42565b6001600160a01b031660009081526004602052604090205490565b34801561092457600080fd5b506102ad610933366004615039565b612e8e565b34801561094457600080fd5b506102ad600581565b34801561095957600080fd5b5061096d610968366004614c2d565b612ebf565b604080516001600160a01b03988916815296881660208801529487169486019490945260608501929092526080840152831660a083015290911660c082015260e0016102b7565b3480156109c057600080fd5b5061051f6109cf366004614c2d565b612f20565b3480156109e057600080fd5b506109f46109ef366004614c2d565b6131dd565b6040516102b7919081516001600160a01b0316815260208083015190820152604080830151908201526060808301511515908201526080918201519181019190915260a00190565b348015610a4857600080fd5b506102ad610a573660046151e3565b613277565b348015610a6857600080fd5b506104b0610a77366004614c2d565b61358d565b348015610a8857600080fd5b506102ad610a97366004615039565b61367b565b348015610aa857600080fd5b506102ad610ab7366004614d42565b60146020526000908152604090205481565b348015610ad557600080fd5b506104b0610ae4366004614c2d565b6136c3565b348015610af557600080fd5b5061051f610b04366004614d42565b6137b6565b348015610b1557600080fd5b506104b0610b24366004614c2d565b613820565b348015610b3557600080fd5b50610b59610b44366004614d42565b60116020526000908152604090205460ff1681565b60405190151581526020016102b7565b348015610b7557600080fd5b506102ad610b84366004615216565b613a81565b348015610b9557600080fd5b50610ba9610ba4366004614c2d565b613ae9565b6040516102b7919081516001600160a01b039081168252602080840151821690830152604080840151821690830152606080840151908301526080808401519083015260a08381015182169083015260c092830151169181019190915260e00190565b348015610c1857600080fd5b506104b0613bf2565b6104b0610c2f366004614c2d565b613c67565b6015546001600160a01b03163314610c675760405162461bcd60e51b8152600401610c5e90615261565b60405180910390fd5b601255565b6015546001600160a01b03163314610c965760405162461bcd60e51b8152600401610c5e90615261565b6001600160a01b038216610ce05760405162461bcd60e51b81526020600482015260116024820152705365727669646f7220696e76616c69646f60781b6044820152606401610c5e565b6001600160a01b038216600081815260116020908152604091829020805460ff191685151590811790915591519182527f1129c1fc3519a3c3d011ea3d0b7d0dee794ddfbf7d23708ba30178a4eb176c5191015b60405180910390a25050565b6001600160a01b038116600090815260076020908152604091829020805483518184028101840190945280845260609392830182828015610da057602002820191906000526020600020905b815481526020019060010190808311610d8c575b50505050509050919050565b610de56040518060c001604052806000815260200160608152602001606081526020016000815260200160608152602001600081525090565b6000828152600360205260409020546001600160a01b0316610e3c5760405162461bcd60e51b815260206004820152601060248201526f4361727461206e616f2065786973746560801b6044820152606401610c5e565b600082815260016020818152604092839020835160c0810190945280548452918201805491840191610e6d906152a8565b80601f0160208091040260200160405190810160405280929190818152602001828054610e99906152a8565b8015610ee65780601f10610ebb57610100808354040283529160200191610ee6565b820191906000526020600020905b815481529060010190602001808311610ec957829003601f168201915b50505050508152602001600282018054610eff906152a8565b80601f0160208091040260200160405190810160405280929190818152602001828054610f2b906152a8565b8015610f785780601f10610f4d57610100808354040283529160200191610f78565b820191906000526020600020905b815481529060010190602001808311610f5b57829003601f168201915b5050505050815260200160038201548152602001600482018054610f9b906152a8565b80601f0160208091040260200160405190810160405280929190818152602001828054610fc7906152a8565b80156110145780601f10610fe957610100808354040283529160200191611014565b820191906000526020600020905b815481529060010190602001808311610ff757829003601f168201915b505050505081526020016005820154815250509050919050565b60125434101561108f5760405162461bcd60e51b815260206004820152602660248201527f56616c6f7220696e737566696369656e7465207061726120636f6d70726172206044820152657061636f746560d01b6064820152608401610c5e565b806110d35760405162461bcd60e51b8152602060048201526014602482015273436f6d70726f6d6973736f20696e76616c69646f60601b6044820152606401610c5e565b33600090815260136020526040902060010154156111335760405162461bcd60e51b815260206004820152601c60248201527f436f6d7072612070656e64656e746520646520726576656c6163616f000000006044820152606401610c5e565b604080518082018252828152436020808301918252336000908152601382528481209351845591516001909301929092556014909152908120805491611178836152f8565b90915550506040805182815243602082015233917f3c8c6ef0d2d048e2c41fff64b378e721174342f6bf05b7b6dd6029df97c0f23991015b60405180910390a250565b600081815260056020526040902080546001600160a01b03166111f05760405162461bcd60e51b8152600401610c5e90615311565b60018101546001600160a01b0316331461121c5760405162461bcd60e51b8152600401610c5e9061533e565b61122581613e1f565b60078101805461ff001916610100179055600181015481546040516001600160a01b0392831692919091169084907ffa2eda418dc7fd239e8508952baa4f3b389c341484affd653fb9c21f2f7f5f4e90600090a45050565b600060038251146112e15760405162461bcd60e51b815260206004820152602860248201527f5175616e7469646164652064652063617274617320696e76616c696461207061604482015267726120667573616f60c01b6064820152608401610c5e565b600060016000846000815181106112fa576112fa615386565b602002602001015181526020019081526020016000206040518060c001604052908160008201548152602001600182018054611335906152a8565b80601f0160208091040260200160405190810160405280929190818152602001828054611361906152a8565b80156113ae5780601f10611383576101008083540402835291602001916113ae565b820191906000526020600020905b81548152906001019060200180831161139157829003601f168201915b505050505081526020016002820180546113c7906152a8565b80601f01602080910402602001604051908101604052809291908181526020018280546113f3906152a8565b80156114405780601f1061141557610100808354040283529160200191611440565b820191906000526020600020905b81548152906001019060200180831161142357829003601f168201915b5050505050815260200160038201548152602001600482018054611463906152a8565b80601f016020809104026020016040519081016040528092919081815260200182805461148f906152a8565b80156114dc5780601f106114b1576101008083540402835291602001916114dc565b820191906000526020600020905b8154815290600101906020018083116114bf57829003601f168201915b50505050508152602001600582015481525050905060008060006115038460800151613f61565b9250925092506000805b87518110156118d457600088828151811061152a5761152a615386565b602090810291909101810151600081815260039092526040909120549091506001600160a01b031633146115705760405162461bcd60e51b8152600401610c5e9061539c565b60005b828110156115f557818a828151811061158e5761158e615386565b6020026020010151036115e35760405162461bcd60e51b815260206004820152601760248201527f4361727461207265706574696461206e6120667573616f0000000000000000006044820152606401610c5e565b806115ed816152f8565b915050611573565b506000818152600160208181526040808420815160c08101909252805482529283018054919392840191611628906152a8565b80601f0160208091040260200160405190810160405280929190818152602001828054611654906152a8565b80156116a15780601f10611676576101008083540402835291602001916116a1565b820191906000526020600020905b81548152906001019060200180831161168457829003601f168201915b505050505081526020016002820180546116ba906152a8565b80601f01602080910402602001604051908101604052809291908181526020018280546116e6906152a8565b80156117335780601f1061170857610100808354040283529160200191611733565b820191906000526020600020905b81548152906001019060200180831161171657829003601f168201915b5050505050815260200160038201548152602001600482018054611756906152a8565b80601f0160208091040260200160405190810160405280929190818152602001828054611782906152a8565b80156117cf5780601f106117a4576101008083540402835291602001916117cf565b820191906000526020600020905b8154815290600101906020018083116117b257829003601f168201915b505050505081526020016005820154815250509050876020015180519060200120816020015180519060200120146118495760405162461bcd60e51b815260206004820152601b60248201527f43617274617320636f6d206e6f6d6573206469666572656e74657300000000006044820152606401610c5e565b876080015180519060200120816080015180519060200120146118ae5760405162461bcd60e51b815260206004820152601f60248201527f43617274617320636f6d20726172696461646573206469666572656e746573006044820152606401610c5e565b60608101516118bd90856153d3565b9350505080806118cc906152f8565b91505061150d565b5060005b875181101561191657611904338983815181106118f7576118f7615386565b60200260200101516140dd565b8061190e816152f8565b9150506118d8565b5060006119433387602001518860400151868661193391906153e6565b61193d90896153d3565b89614277565b905080336001600160a01b03167fe34c4f669795a0456cd6bdc19146571d51bd11f15d7595656e5890465b55f5e88a88604051611981929190615408565b60405180910390a3979650505050505050565b6001602081905260009182526040909120805491810180546119b5906152a8565b80601f01602080910402602001604051908101604052809291908181526020018280546119e1906152a8565b8015611a2e5780601f10611a0357610100808354040283529160200191611a2e565b820191906000526020600020905b815481529060010190602001808311611a1157829003601f168201915b505050505090806002018054611a43906152a8565b80601f0160208091040260200160405190810160405280929190818152602001828054611a6f906152a8565b8015611abc5780601f10611a9157610100808354040283529160200191611abc565b820191906000526020600020905b815481529060010190602001808311611a9f57829003601f168201915b505050505090806003015490806004018054611ad7906152a8565b80601f0160208091040260200160405190810160405280929190818152602001828054611b03906152a8565b8015611b505780601f10611b2557610100808354040283529160200191611b50565b820191906000526020600020905b815481529060010190602001808311611b3357829003601f168201915b5050505050908060050154905086565b6015546001600160a01b03163314611b8a5760405162461bcd60e51b8152600401610c5e90615261565b6001600160a01b038416611bd45760405162461bcd60e51b81526020600482015260116024820152704a6f6761646f723120696e76616c69646f60781b6044820152606401610c5e565b6001600160a01b038316611c1e5760405162461bcd60e51b81526020600482015260116024820152704a6f6761646f723220696e76616c69646f60781b6044820152606401610c5e565b826001600160a01b0316846001600160a01b031603611c7f5760405162461bcd60e51b815260206004820152601d60248201527f4e616f20706f64652074726f63617220636f6e7369676f206d65736d6f0000006044820152606401610c5e565b6000828152600360205260409020546001600160a01b03858116911614611ce85760405162461bcd60e51b815260206004820152601e60248201527f4a6f6761646f7231206e616f20706f73737569206573746120636172746100006044820152606401610c5e565b6000818152600360205260409020546001600160a01b03848116911614611d515760405162461bcd60e51b815260206004820152601e60248201527f4a6f6761646f7232206e616f20706f73737569206573746120636172746100006044820152606401610c5e565b600680549081906000611d63836152f8565b9190505550604051806101400160405280866001600160a01b03168152602001856001600160a01b03168152602001848152602001838152602001600115158152602001600115158152602001428152602001428152602001600015158152602001600015158152506005600083815260200190815260200160002060008201518160000160006101000a8154816001600160a01b0302191690836001600160a01b0316021790555060208201518160010160006101000a8154816001600160a01b0302191690836001600160a01b03160217905550604082015181600201556060820151816003015560808201518160040160006101000a81548160ff02191690831515021790555060a08201518160040160016101000a81548160ff02191690831515021790555060c0820151816005015560e082015181600601556101008201518160070160006101000a81548160ff0219169083151502179055506101208201518160070160016101000a81548160ff021916908315150217905550905050611ef18585856143d6565b611efc8486846143d6565b836001600160a01b0316856001600160a01b0316827f6ef90ab6f0f3945a2022e02420b8115030d04eb05939152ee8157c0d8cfb1a9b8686604051611f4b929190918252602082015260400190565b60405180910390a4836001600160a01b0316856001600160a01b0316827f5495a4bb69557efe1bbe80aa1e863cd5bea9c24a8860faa9d5b9c7b8b305dc608686604051611fa2929190918252602082015260400190565b60405180910390a45050505050565b60008281526003602052604081205483906001600160a01b03163314611fe95760405162461bcd60e51b8152600401610c5e9061539c565b6000831161202a5760405162461bcd60e51b815260206004820152600e60248201526d507265636f20696e76616c69646f60901b6044820152606401610c5e565b6120353330866143d6565b600a80549081906000612047836152f8565b90915550506040805160a081018252338082526020808301898152838501898152600160608601818152426080880190815260008a815260098752898120985189546001600160a01b0319166001600160a01b03909116178955945188840155925160028801555160038701805460ff19169115159190911790559051600490950194909455600b805494850181557f0175b7a638427703f0dbe7bb9bbf987a2551717b34e79f33b5b1008d1fa01db99094018690559254600c82529284902092909255915186815287929184917f26bc5a73d1fd032df4b3060c5e83db643ac9787fbd8a97dbfb5622581dd18a1f910160405180910390a4949350505050565b336000908152600d6020526040902054806121945760405162461bcd60e51b815260206004820152600c60248201526b2730b23090309039b0b1b0b960a11b6044820152606401610c5e565b336000908152600d60205260408120819055600e80548392906121b8908490615436565b9091555050604051339082156108fc029083906000818181858888f193505050501580156121ea573d6000803e3d6000fd5b5060405181815233907f78a40354f9a9cb80eddaab56242ac4c2dc6441d910a113078c58c4ab84847820906020016111b0565b6060600b80548060200260200160405190810160405280929190818152602001828054801561226b57602002820191906000526020600020905b815481526020019060010190808311612257575b5050505050905090565b6001600160a01b038116600090815260086020908152604091829020805483518184028101840190945280845260609392830182828015610da05760200282019190600052602060002090815481526020019060010190808311610d8c5750505050509050919050565b6001600160a01b03811660009081526013602090815260408083208151808301909252805482526001015491810182905291036123585760405162461bcd60e51b81526020600482015260176024820152764e656e68756d6120636f6d7072612070656e64656e746560481b6044820152606401610c5e565b60fa816020015161236991906153d3565b43116123b75760405162461bcd60e51b815260206004820152601e60248201527f436f6d7072612061696e646120706f64652073657220726576656c61646100006044820152606401610c5e565b6001600160a01b0382166000818152601360209081526040808320838155600101929092558381015191519182527f362fc9120f0a303d5433dd4d84858f8390114f5d407e8152319ed058420dfc949101610d34565b60008181526003602052604090205481906001600160a01b031633146124455760405162461bcd60e51b8152600401610c5e9061539c565b6001600160a01b0383166124aa5760405162461bcd60e51b815260206004820152602660248201527f4e616f20706f6465207472616e736665726972207061726120656e64657265636044820152656f207a65726f60d01b6064820152608401610c5e565b336001600160a01b0384160361250c5760405162461bcd60e51b815260206004820152602160248201527f4e616f20706f6465207472616e7366657269722070617261207369206d65736d6044820152606f60f81b6064820152608401610c5e565b600082815260036020908152604080832080546001600160a01b038881166001600160a01b0319831617909255168084526002909252822090915b81548110156125fb578482828154811061256357612563615386565b9060005260206000200154036125e9578154829061258390600190615436565b8154811061259357612593615386565b90600052602060002001548282815481106125b0576125b0615386565b9060005260206000200181905550818054806125ce576125ce615449565b600190038181906000526020600020016000905590556125fb565b806125f3816152f8565b915050612547565b506001600160a01b0380861660009081526002602090815260408083208054600181018255908452828420018890559285168252600490529081208054916126428361545f565b90915550506001600160a01b038516600090815260046020526040812080549161266b836152f8565b9190505550846001600160a01b0316826001600160a01b0316857f0500118e3c25051431ae1a1ba893c78e881f535977bcc624a69a53d194c1e5f760405160405180910390a45050505050565b6001600160a01b0387166127025760405162461bcd60e51b81526020600482015260116024820152704a6f6761646f723120696e76616c69646f60781b6044820152606401610c5e565b6001600160a01b03861661274c5760405162461bcd60e51b81526020600482015260116024820152704a6f6761646f723220696e76616c69646f60781b6044820152606401610c5e565b856001600160a01b0316876001600160a01b0316036127ad5760405162461bcd60e51b815260206004820152601c60248201527f4e616f20706f6465206a6f67617220636f6e7369676f206d65736d6f000000006044820152606401610c5e565b866001600160a01b0316856001600160a01b031614806127de5750856001600160a01b0316856001600160a01b0316145b806127f057506001600160a01b038516155b6128515760405162461bcd60e51b815260206004820152602c60248201527f56656e6365646f7220646576652073657220756d20646f73206a6f6761646f7260448201526b6573206f7520656d7061746560a01b6064820152608401610c5e565b836128955760405162461bcd60e51b81526020600482015260146024820152734861736820646f206c6f6720696e76616c69646f60601b6044820152606401610c5e565b600084815260106020526040902054156128e95760405162461bcd60e51b815260206004820152601560248201527450617274696461206a61207265676973747261646160581b6044820152606401610c5e565b805160041461293a5760405162461bcd60e51b815260206004820152601760248201527f417373696e61747572617320696e636f6d706c657461730000000000000000006044820152606401610c5e565b6001600160a01b03831660009081526011602052604090205460ff1661299c5760405162461bcd60e51b81526020600482015260176024820152765365727669646f72206e616f206175746f72697a61646f60481b6044820152606401610c5e565b6001600160a01b03821615806129e757506001600160a01b03821660009081526011602052604090205460ff1680156129e75750826001600160a01b0316826001600160a01b031614155b612a2d5760405162461bcd60e51b81526020600482015260176024820152765365727669646f72206e616f206175746f72697a61646f60481b6044820152606401610c5e565b6000612a3b88888888613a81565b6040517f19457468657265756d205369676e6564204d6573736167653a0a3332000000006020820152603c810191909152605c01604051602081830303815290604052805190602001209050836001600160a01b0316612ab58284600081518110612aa857612aa8615386565b60200260200101516145eb565b6001600160a01b031614612b0b5760405162461bcd60e51b815260206004820152601b60248201527f417373696e617475726120646f20486f737420696e76616c69646100000000006044820152606401610c5e565b6001600160a01b03831615612b9157826001600160a01b0316612b3b8284600181518110612aa857612aa8615386565b6001600160a01b031614612b915760405162461bcd60e51b815260206004820152601d60248201527f417373696e617475726120646120536f6d62726120696e76616c6964610000006044820152606401610c5e565b876001600160a01b0316612bb28284600281518110612aa857612aa8615386565b6001600160a01b031614612c085760405162461bcd60e51b815260206004820152601f60248201527f417373696e617475726120646f206a6f6761646f723120696e76616c696461006044820152606401610c5e565b866001600160a01b0316612c298284600381518110612aa857612aa8615386565b6001600160a01b031614612c7f5760405162461bcd60e51b815260206004820152601f60248201527f417373696e617475726120646f206a6f6761646f723220696e76616c696461006044820152606401610c5e565b60006040518060e001604052808a6001600160a01b03168152602001896001600160a01b03168152602001886001600160a01b03168152602001428152602001878152602001866001600160a01b03168152602001856001600160a01b03168152509050600f81908060018154018082558091505060019003906000526020600020906007020160009091909190915060008201518160000160006101000a8154816001600160a01b0302191690836001600160a01b0316021790555060208201518160010160006101000a8154816001600160a01b0302191690836001600160a01b0316021790555060408201518160020160006101000a8154816001600160a01b0302191690836001600160a01b03160217905550606082015181600301556080820151816004015560a08201518160050160006101000a8154816001600160a01b0302191690836001600160a01b0316021790555060c08201518160060160006101000a8154816001600160a01b0302191690836001600160a01b031602179055505050600f805490506010600088815260200190815260200160002081905550866001600160a01b0316886001600160a01b03168a6001600160a01b03167f83a4a74eaf8a8e5a2de91b5474234a59fab72d01cd65e8a4474098bf640bcf92428a604051612e7b929190918252602082015260400190565b60405180910390a4505050505050505050565b60026020528160005260406000208181548110612eaa57600080fd5b90600052602060002001600091509150505481565b600f8181548110612ecf57600080fd5b600091825260209091206007909102018054600182015460028301546003840154600485015460058601546006909601546001600160a01b0395861697509385169592851694919390928216911687565b3360009081526013602090815260408083208151808301909252805482526001015491810182905260609290919003612f955760405162461bcd60e51b81526020600482015260176024820152764e656e68756d6120636f6d7072612070656e64656e746560481b6044820152606401610c5e565b80602001514311612fe85760405162461bcd60e51b815260206004820152601c60248201527f526576656c6520656d20756d20626c6f636f20706f73746572696f72000000006044820152606401610c5e565b60fa8160200151612ff991906153d3565b4311156130485760405162461bcd60e51b815260206004820152601b60248201527f5072617a6f20646520726576656c6163616f20657870697261646f00000000006044820152606401610c5e565b8051613054338561367b565b146130975760405162461bcd60e51b81526020600482015260136024820152725365677265646f206e616f20636f6e6665726560681b6044820152606401610c5e565b3360009081526013602090815260408083208381556001018390558382015181518084018890529040818301528151808203830181526060820180845281519190940120600580855261012083019093529392919060800160a08036833701905050905060005b600581101561319157600080600080613147878660405160200161312c929190918252602082015260400190565b6040516020818303038152906040528051906020012061468f565b935093509350935061315c3385858585614277565b86868151811061316e5761316e615386565b602002602001018181525050505050508080613189906152f8565b9150506130fe565b50336001600160a01b03167ff58b45d7b661f9a0f0e7c734de1c8725e0b999e8b8405964abb3e72f57520f8082426040516131cd929190615476565b60405180910390a2949350505050565b61321a6040518060a0016040528060006001600160a01b031681526020016000815260200160008152602001600015158152602001600081525090565b50600090815260096020908152604091829020825160a08101845281546001600160a01b03168152600182015492810192909252600281015492820192909252600382015460ff1615156060820152600490910154608082015290565b60008281526003602052604081205483906001600160a01b031633146132af5760405162461bcd60e51b8152600401610c5e9061539c565b6001600160a01b0385166132f85760405162461bcd60e51b815260206004820152601060248201526f4a6f6761646f7220696e76616c69646f60801b6044820152606401610c5e565b336001600160a01b038616036133505760405162461bcd60e51b815260206004820152601d60248201527f4e616f20706f64652074726f63617220636f6e7369676f206d65736d6f0000006044820152606401610c5e565b6000838152600360205260409020546001600160a01b038681169116146133b95760405162461bcd60e51b815260206004820152601e60248201527f4a6f6761646f7232206e616f20706f73737569206573746120636172746100006044820152606401610c5e565b6006805490819060006133cb836152f8565b9190505550604051806101400160405280336001600160a01b03168152602001876001600160a01b03168152602001868152602001858152602001600015158152602001600015158152602001428152602001620151804261342d91906153d3565b81526000602080830182905260409283018290528482526005808252838320855181546001600160a01b039182166001600160a01b03199182161783558785015160018481018054928516929093169190911790915587870151600284015560608801516003840155608088015160048401805460a08b0151151561010090810261ff0019941515851661ffff19938416171790925560c08b01519686019690965560e08a01516006860155808a015160079586018054610120909c01511515909202901515909216999095169890981797909717909255338085529083528484208054808801825590855283852001879055908b168084526008835284842080549687018155845291909220909301849055905183907f6ef90ab6f0f3945a2022e02420b8115030d04eb05939152ee8157c0d8cfb1a9b9061357c908a908a90918252602082015260400190565b60405180910390a495945050505050565b600081815260056020526040902080546001600160a01b03166135c25760405162461bcd60e51b8152600401610c5e90615311565b80546001600160a01b0316331461361b5760405162461bcd60e51b815260206004820152601c60248201527f566f6365206e616f206372696f7520657374612070726f706f737461000000006044820152606401610c5e565b61362481613e1f565b60078101805460ff1916600190811790915581015481546040516001600160a01b0392831692919091169084907f910baa9a3ca144f4c6c387162c058a9f74e14e683915ffe33a46a9213a176be390600090a45050565b6040516bffffffffffffffffffffffff19606084901b166020820152603481018290526000906054016040516020818303038152906040528051906020012090505b92915050565b6000818152600960205260409020600381015460ff166137175760405162461bcd60e51b815260206004820152600f60248201526e416e756e63696f20696e617469766f60881b6044820152606401610c5e565b80546001600160a01b031633146137685760405162461bcd60e51b81526020600482015260156024820152742b37b1b2903730b790329037903b32b73232b237b960591b6044820152606401610c5e565b61377182614b05565b613780303383600101546143d6565b6001810154604051339084907fca93f2f88bf5f663075e7c6d532a724181a56f8fbfbadaf87fb537965e4069ff90600090a45050565b6001600160a01b038116600090815260026020908152604091829020805483518184028101840190945280845260609392830182828015610da05760200282019190600052602060002090815481526020019060010190808311610d8c5750505050509050919050565b600081815260056020526040902080546001600160a01b03166138555760405162461bcd60e51b8152600401610c5e90615311565b600081600501541161389d5760405162461bcd60e51b815260206004820152601160248201527050726f706f73746120696e76616c69646160781b6044820152606401610c5e565b60018101546001600160a01b031633146138c95760405162461bcd60e51b8152600401610c5e9061533e565b6138d281613e1f565b805460028201546000908152600360205260409020546001600160a01b0390811691161461394e5760405162461bcd60e51b815260206004820152602360248201527f4a6f6761646f7231206e616f20706f73737569206d616973206573746120636160448201526272746160e81b6064820152608401610c5e565b6001810154600380830154600090815260209190915260409020546001600160a01b039081169116146139c35760405162461bcd60e51b815260206004820152601f60248201527f566f6365206e616f20706f73737569206d6169732065737461206361727461006044820152606401610c5e565b60048101805461ffff19166101011790558054600182015460028301546139f7926001600160a01b039081169216906143d6565b600181015481546003830154613a1a926001600160a01b039081169216906143d6565b60018101548154600283015460038401546040516001600160a01b0394851694939093169286927f5495a4bb69557efe1bbe80aa1e863cd5bea9c24a8860faa9d5b9c7b8b305dc6092613a7592918252602082015260400190565b60405180910390a45050565b6040516bffffffffffffffffffffffff1930606090811b8216602084015286811b8216603484015285811b8216604884015284901b16605c82015260708101829052600090609001604051602081830303815290604052805190602001209050949350505050565b6040805160e081018252600080825260208201819052918101829052606081018290526080810182905260a0810182905260c0810191909152600f548210613b655760405162461bcd60e51b815260206004820152600f60248201526e496e6469636520696e76616c69646f60881b6044820152606401610c5e565b600f8281548110613b7857613b78615386565b60009182526020918290206040805160e081018252600790930290910180546001600160a01b0390811684526001820154811694840194909452600281015484169183019190915260038101546060830152600481015460808301526005810154831660a08301526006015490911660c082015292915050565b6015546001600160a01b03163314613c1c5760405162461bcd60e51b8152600401610c5e90615261565b601554600e546001600160a01b03909116906108fc90613c3c9047615436565b6040518115909202916000818181858888f19350505050158015613c64573d6000803e3d6000fd5b50565b6000818152600960205260409020600381015460ff16613cbb5760405162461bcd60e51b815260206004820152600f60248201526e416e756e63696f20696e617469766f60881b6044820152606401610c5e565b8054336001600160a01b0390911603613d215760405162461bcd60e51b815260206004820152602260248201527f4e616f20706f646520636f6d70726172206f2070726f7072696f20616e756e63604482015261696f60f01b6064820152608401610c5e565b80600201543414613d745760405162461bcd60e51b815260206004820152601860248201527f56616c6f72206469666572656e746520646f20707265636f00000000000000006044820152606401610c5e565b613d7d82614b05565b80546001600160a01b03166000908152600d602052604081208054349290613da69084906153d3565b9250508190555034600e6000828254613dbf91906153d3565b92505081905550613dd5303383600101546143d6565b805460018201546040805191825234602083015233926001600160a01b03169185917f014da2b837bb433e166def3b8ad4c39550598b2c1aee7643c24b02a15271a5159101613a75565b6004810154610100900460ff1615613e795760405162461bcd60e51b815260206004820152601960248201527f50726f706f737461206a6120666f6920657865637574616461000000000000006044820152606401610c5e565b600781015460ff1615613ec75760405162461bcd60e51b815260206004820152601660248201527550726f706f73746120666f692063616e63656c61646160501b6044820152606401610c5e565b6007810154610100900460ff1615613f195760405162461bcd60e51b815260206004820152601560248201527450726f706f73746120666f6920726563757361646160581b6044820152606401610c5e565b8060060154421115613c645760405162461bcd60e51b815260206004820152601160248201527050726f706f73746120657870697261646160781b6044820152606401610c5e565b6060600080600084805190602001209050604051806040016040528060018152602001604360f81b815250805190602001208103613fc05750506040805180820190915260018152605560f81b6020820152915060339050601e6140d6565b6040805180820190915260018152605560f81b6020909101527fc840ddc74ee4973237c7d31317d9ae4a62c3c67778c491f0cc28696b55ba0e4281016140275750506040805180820190915260018152602960f91b602082015291506051905060146140d6565b6040805180820190915260018152602960f91b6020909101527f10dd4222caf46bce8f5982cae6e3d81cef8f65d73c74a89d5ead009bfef70a4e810161408e5750506040805180820190915260018152601360fa1b602082015291506065905060146140d6565b60405162461bcd60e51b815260206004820152601d60248201527f5261726964616465206e616f20706f6465207365722066756e646964610000006044820152606401610c5e565b9193909250565b600081815260036020908152604080832080546001600160a01b03191690556001918290528220828155919061411590830182614bdf565b614123600283016000614bdf565b600382016000905560048201600061413b9190614bdf565b5060006005919091018190556001600160a01b0383168152600260205260408120905b8154811015614212578282828154811061417a5761417a615386565b906000526020600020015403614200578154829061419a90600190615436565b815481106141aa576141aa615386565b90600052602060002001548282815481106141c7576141c7615386565b9060005260206000200181905550818054806141e5576141e5615449565b60019003818190600052602060002001600090559055614212565b8061420a816152f8565b91505061415e565b506001600160a01b03831660009081526004602052604081208054916142378361545f565b90915550506040516001600160a01b0384169083907f6e40250a2d1d644349c4ba9bfb38bc9bd8d38c347e73d8c36566879e4040049290600090a3505050565b60008054808280614287836152f8565b90915550506040805160c081018252828152602080820189815282840189905260608301889052608083018790524260a0840152600085815260019283905293909320825181559251919291908201906142e190826154e7565b50604082015160028201906142f690826154e7565b50606082015160038201556080820151600482019061431590826154e7565b5060a09190910151600590910155600081815260036020908152604080832080546001600160a01b0319166001600160a01b038c1690811790915580845260028352818420805460018101825590855283852001859055835260049091528120805491614381836152f8565b9190505550866001600160a01b0316817f5e295699131beed3bdc465c4e891fdb9dc790be12d3aa8ab60f1fa2b8b28da1b8886886040516143c4939291906155a7565b60405180910390a39695505050505050565b6000818152600360205260409020546001600160a01b0384811691161461443f5760405162461bcd60e51b815260206004820152601c60248201527f52656d6574656e7465206e616f20706f737375692061206361727461000000006044820152606401610c5e565b600081815260036020908152604080832080546001600160a01b0319166001600160a01b03878116919091179091558616835260029091528120905b815481101561452f578282828154811061449757614497615386565b90600052602060002001540361451d57815482906144b790600190615436565b815481106144c7576144c7615386565b90600052602060002001548282815481106144e4576144e4615386565b90600052602060002001819055508180548061450257614502615449565b6001900381819060005260206000200160009055905561452f565b80614527816152f8565b91505061447b565b506001600160a01b0380841660009081526002602090815260408083208054600181018255908452828420018690559287168252600490529081208054916145768361545f565b90915550506001600160a01b038316600090815260046020526040812080549161459f836152f8565b9190505550826001600160a01b0316846001600160a01b0316837f0500118e3c25051431ae1a1ba893c78e881f535977bcc624a69a53d194c1e5f760405160405180910390a450505050565b600081516041146145fe575060006136bd565b60208201516040830151606084015160001a601b81101561462757614624601b826155dd565b90505b60408051600081526020810180835288905260ff831691810191909152606081018490526080810183905260019060a0016020604051602081039080840390855afa15801561467a573d6000803e3d6000fd5b5050604051601f190151979650505050505050565b606080600060606000856040516020016146ab91815260200190565b60408051601f1981840301815282825280516020918201206102c084018352600661028085018181526544726167616f60d01b6102a087015285528351808501855260098082526847756572726569726f60b81b8286015286850191909152845180860186526004808252634d61676f60e01b82870152878701919091528551808701875281815263416e6a6f60e01b818701526060808901919091528651808801885260078082526644656d6f6e696f60c81b828901526080808b01929092528851808a018a5260058082526408ccadcd2f60db1b828b015260a0808d01929092528a51808c018c52818152642a34ba30b760d91b818c015260c0808e01919091528b51808d018d528981526553657265696160d01b818d015260e08e01528b51808d018d52878152634c6f626f60e01b818d01526101008e01528b51808d018d5282815264416775696160d81b818d01526101208e01528b51808d018d5288815268436176616c6569726f60b81b818d01526101408e01528b51808d018d52600880825267417271756569726f60c01b828e01526101608f01919091528c51808e018e52858152664261726261726f60c81b818e01526101808f01528c51808e018e529081526750616c6164696e6f60c01b818d01526101a08e01528b51808d018d52898152652930b733b2b960d11b818d01526101c08e01528b51808d018d5282815264427275786f60d81b818d01526101e08e01528b51808d018d529889526544727569646160d01b898c01526102008d01989098528a51808c018c52818152644d6f6e676560d81b818c01526102208d01528a51808c018c5296875268417373617373696e6f60b81b878b01526102408c01969096528951808b018b5286815264426172646f60d81b818b01526102608c015289519687018a52918601908152664573706164617360c81b9186019190915284528651808801885283815264436f70617360d81b818801528487015286518088018852928352644f75726f7360d81b838701528387019290925285518087019096528552635061757360e01b938501939093529182019290925290925060006149cd6064856153e6565b90506046811015614a0f576040805180820190915260018152604360f81b602082015294506149fd6032856153e6565b614a089060016153d3565b9550614ab4565b605a811015614a48576040805180820190915260018152605560f81b60208201529450614a3d601e856153e6565b614a089060336153d3565b6063811015614a81576040805180820190915260018152602960f91b60208201529450614a766014856153e6565b614a089060516153d3565b6040805180820190915260018152601360fa1b60208201529450614aa66014856153e6565b614ab19060656153d3565b95505b82614ac06014866153e6565b60148110614ad057614ad0615386565b6020020151975081614ae36004866153e6565b60048110614af357614af3615386565b60200201519650505050509193509193565b6000818152600960209081526040808320600301805460ff19169055600c909152812054614b3590600190615436565b600b8054919250600091614b4b90600190615436565b81548110614b5b57614b5b615386565b9060005260206000200154905080600b8381548110614b7c57614b7c615386565b600091825260209091200155614b938260016153d3565b6000828152600c6020526040902055600b805480614bb357614bb3615449565b600082815260208082208301600019908101839055909201909255938152600c90935250506040812055565b508054614beb906152a8565b6000825580601f10614bfb575050565b601f016020900490600052602060002090810190613c6491905b80821115614c295760008155600101614c15565b5090565b600060208284031215614c3f57600080fd5b5035919050565b81516001600160a01b0316815261014081016020830151614c7260208401826001600160a01b03169052565b5060408301516040830152606083015160608301526080830151614c9a608084018215159052565b5060a0830151614cae60a084018215159052565b5060c083015160c083015260e083015160e083015261010080840151614cd78285018215159052565b5050610120928301511515919092015290565b80356001600160a01b0381168114614d0157600080fd5b919050565b60008060408385031215614d1957600080fd5b614d2283614cea565b915060208301358015158114614d3757600080fd5b809150509250929050565b600060208284031215614d5457600080fd5b614d5d82614cea565b9392505050565b600081518084526020808501945080840160005b83811015614d9457815187529582019590820190600101614d78565b509495945050505050565b602081526000614d5d6020830184614d64565b6000815180845260005b81811015614dd857602081850181015186830182015201614dbc565b506000602082860101526020601f19601f83011685010191505092915050565b60208152815160208201526000602083015160c06040840152614e1e60e0840182614db2565b90506040840151601f1980858403016060860152614e3c8383614db2565b92506060860151608086015260808601519150808584030160a086015250614e648282614db2565b91505060a084015160c08401528091505092915050565b634e487b7160e01b600052604160045260246000fd5b604051601f8201601f1916810167ffffffffffffffff81118282101715614eba57614eba614e7b565b604052919050565b600067ffffffffffffffff821115614edc57614edc614e7b565b5060051b60200190565b60006020808385031215614ef957600080fd5b823567ffffffffffffffff811115614f1057600080fd5b8301601f81018513614f2157600080fd5b8035614f34614f2f82614ec2565b614e91565b81815260059190911b82018301908381019087831115614f5357600080fd5b928401925b82841015614f7157833582529284019290840190614f58565b979650505050505050565b86815260c060208201526000614f9560c0830188614db2565b8281036040840152614fa78188614db2565b90508560608401528281036080840152614fc18186614db2565b9150508260a0830152979650505050505050565b60008060008060808587031215614feb57600080fd5b614ff485614cea565b935061500260208601614cea565b93969395505050506040820135916060013590565b6000806040838503121561502a57600080fd5b50508035926020909101359150565b6000806040838503121561504c57600080fd5b61505583614cea565b946020939093013593505050565b600080600080600080600060e0888a03121561507e57600080fd5b61508788614cea565b965061509560208901614cea565b95506150a360408901614cea565b9450606088013593506150b860808901614cea565b92506150c660a08901614cea565b915067ffffffffffffffff60c089013511156150e157600080fd5b60c0880135880189601f8201126150f757600080fd5b615104614f2f8235614ec2565b81358082526020808301929160051b8401018c101561512257600080fd5b602083015b6020843560051b8501018110156151d05767ffffffffffffffff8135111561514e57600080fd5b803584018d603f82011261516157600080fd5b602081013567ffffffffffffffff81111561517e5761517e614e7b565b615191601f8201601f1916602001614e91565b8181528f60408385010111156151a657600080fd5b81604084016020830137600060208383010152808652505050602083019250602081019050615127565b5080935050505092959891949750929550565b6000806000606084860312156151f857600080fd5b61520184614cea565b95602085013595506040909401359392505050565b6000806000806080858703121561522c57600080fd5b61523585614cea565b935061524360208601614cea565b925061525160408601614cea565b9396929550929360600135925050565b60208082526027908201527f4170656e6173206f20646f6e6f20706f646520657865637574617220657374616040820152662066756e63616f60c81b606082015260800190565b600181811c908216806152bc57607f821691505b6020821081036152dc57634e487b7160e01b600052602260045260246000fd5b50919050565b634e487b7160e01b600052601160045260246000fd5b60006001820161530a5761530a6152e2565b5060010190565b60208082526013908201527250726f706f737461206e616f2065786973746560681b604082015260600190565b60208082526028908201527f566f6365206e616f2065206f2064657374696e61746172696f2064657374612060408201526770726f706f73746160c01b606082015260800190565b634e487b7160e01b600052603260045260246000fd5b6020808252601a908201527f566f6365206e616f20706f737375692065737461206361727461000000000000604082015260600190565b808201808211156136bd576136bd6152e2565b60008261540357634e487b7160e01b600052601260045260246000fd5b500690565b60408152600061541b6040830185614d64565b828103602084015261542d8185614db2565b95945050505050565b818103818111156136bd576136bd6152e2565b634e487b7160e01b600052603160045260246000fd5b60008161546e5761546e6152e2565b506000190190565b6040815260006154896040830185614d64565b90508260208301529392505050565b601f8211156154e257600081815260208120601f850160051c810160208610156154bf5750805b601f850160051c820191505b818110156154de578281556001016154cb565b5050505b505050565b815167ffffffffffffffff81111561550157615501614e7b565b6155158161550f84546152a8565b84615498565b602080601f83116001811461554a57600084156155325750858301515b600019600386901b1c1916600185901b1785556154de565b600085815260208120601f198616915b828110156155795788860151825594840194600190910190840161555a565b50858210156155975787850151600019600388901b60f8161c191681555b5050505050600190811b01905550565b6060815260006155ba6060830186614db2565b82810360208401526155cc8186614db2565b915050826040830152949350505050565b60ff81811683821601908111156136bd576136bd6152e256fea264697066735822122018b640f324efdeaa9a1f733867abdc25955a36cf0c0f32eefdceb03f4655ed8164736f6c63430008150033",
+}
+
+// GameEconomyABI is the input ABI used to generate the binding from.
+// Deprecated: Use GameEconomyMetaData.ABI instead.
+var GameEconomyABI = GameEconomyMetaData.ABI
+
+// GameEconomyBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use GameEconomyMetaData.Bin instead.
+var GameEconomyBin = GameEconomyMetaData.Bin
+
+// DeployGameEconomy deploys a new Ethereum contract, binding an instance of GameEconomy to it.
+func DeployGameEconomy(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *GameEconomy, error) {
+	parsed, err := GameEconomyMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(GameEconomyBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &GameEconomy{GameEconomyCaller: GameEconomyCaller{contract: contract}, GameEconomyTransactor: GameEconomyTransactor{contract: contract}, GameEconomyFilterer: GameEconomyFilterer{contract: contract}}, nil
+}
+
+// GameEconomy is an auto generated Go binding around an Ethereum contract.
+type GameEconomy struct {
+	GameEconomyCaller     // Read-only binding to the contract
+	GameEconomyTransactor // Write-only binding to the contract
+	GameEconomyFilterer   // Log filterer for contract events
+}
+
+// GameEconomyCaller is an auto generated read-only Go binding around an Ethereum contract.
+type GameEconomyCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// GameEconomyTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type GameEconomyTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// GameEconomyFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type GameEconomyFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// GameEconomySession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type GameEconomySession struct {
+	Contract     *GameEconomy      // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// GameEconomyCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type GameEconomyCallerSession struct {
+	Contract *GameEconomyCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts      // Call options to use throughout this session
+}
+
+// GameEconomyTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type GameEconomyTransactorSession struct {
+	Contract     *GameEconomyTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts      // Transaction auth options to use throughout this session
+}
+
+// GameEconomyRaw is an auto generated low-level Go binding around an Ethereum contract.
+type GameEconomyRaw struct {
+	Contract *GameEconomy // Generic contract binding to access the raw methods on
+}
+
+// GameEconomyCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type GameEconomyCallerRaw struct {
+	Contract *GameEconomyCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// GameEconomyTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type GameEconomyTransactorRaw struct {
+	Contract *GameEconomyTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewGameEconomy creates a new instance of GameEconomy, bound to a specific deployed contract.
+func NewGameEconomy(address common.Address, backend bind.ContractBackend) (*GameEconomy, error) {
+	contract, err := bindGameEconomy(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomy{GameEconomyCaller: GameEconomyCaller{contract: contract}, GameEconomyTransactor: GameEconomyTransactor{contract: contract}, GameEconomyFilterer: GameEconomyFilterer{contract: contract}}, nil
+}
+
+// NewGameEconomyCaller creates a new read-only instance of GameEconomy, bound to a specific deployed contract.
+func NewGameEconomyCaller(address common.Address, caller bind.ContractCaller) (*GameEconomyCaller, error) {
+	contract, err := bindGameEconomy(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyCaller{contract: contract}, nil
+}
+
+// NewGameEconomyTransactor creates a new write-only instance of GameEconomy, bound to a specific deployed contract.
+func NewGameEconomyTransactor(address common.Address, transactor bind.ContractTransactor) (*GameEconomyTransactor, error) {
+	contract, err := bindGameEconomy(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyTransactor{contract: contract}, nil
+}
+
+// NewGameEconomyFilterer creates a new log filterer instance of GameEconomy, bound to a specific deployed contract.
+func NewGameEconomyFilterer(address common.Address, filterer bind.ContractFilterer) (*GameEconomyFilterer, error) {
+	contract, err := bindGameEconomy(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyFilterer{contract: contract}, nil
+}
+
+// bindGameEconomy binds a generic wrapper to an already deployed contract.
+func bindGameEconomy(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := GameEconomyMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_GameEconomy *GameEconomyRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _GameEconomy.Contract.GameEconomyCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_GameEconomy *GameEconomyRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _GameEconomy.Contract.GameEconomyTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_GameEconomy *GameEconomyRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _GameEconomy.Contract.GameEconomyTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_GameEconomy *GameEconomyCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _GameEconomy.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_GameEconomy *GameEconomyTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _GameEconomy.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_GameEconomy *GameEconomyTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _GameEconomy.Contract.contract.Transact(opts, method, params...)
+}
+
+// CARTASPORFUSAO is a free data retrieval call binding the contract method 0x3a5ecc9f.
+//
+// Solidity: function CARTAS_POR_FUSAO() view returns(uint256)
+func (_GameEconomy *GameEconomyCaller) CARTASPORFUSAO(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "CARTAS_POR_FUSAO")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// CARTASPORFUSAO is a free data retrieval call binding the contract method 0x3a5ecc9f.
+//
+// Solidity: function CARTAS_POR_FUSAO() view returns(uint256)
+func (_GameEconomy *GameEconomySession) CARTASPORFUSAO() (*big.Int, error) {
+	return _GameEconomy.Contract.CARTASPORFUSAO(&_GameEconomy.CallOpts)
+}
+
+// CARTASPORFUSAO is a free data retrieval call binding the contract method 0x3a5ecc9f.
+//
+// Solidity: function CARTAS_POR_FUSAO() view returns(uint256)
+func (_GameEconomy *GameEconomyCallerSession) CARTASPORFUSAO() (*big.Int, error) {
+	return _GameEconomy.Contract.CARTASPORFUSAO(&_GameEconomy.CallOpts)
+}
+
+// CARTASPORPACOTE is a free data retrieval call binding the contract method 0xb2890296.
+//
+// Solidity: function CARTAS_POR_PACOTE() view returns(uint256)
+func (_GameEconomy *GameEconomyCaller) CARTASPORPACOTE(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "CARTAS_POR_PACOTE")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// CARTASPORPACOTE is a free data retrieval call binding the contract method 0xb2890296.
+//
+// Solidity: function CARTAS_POR_PACOTE() view returns(uint256)
+func (_GameEconomy *GameEconomySession) CARTASPORPACOTE() (*big.Int, error) {
+	return _GameEconomy.Contract.CARTASPORPACOTE(&_GameEconomy.CallOpts)
+}
+
+// CARTASPORPACOTE is a free data retrieval call binding the contract method 0xb2890296.
+//
+// Solidity: function CARTAS_POR_PACOTE() view returns(uint256)
+func (_GameEconomy *GameEconomyCallerSession) CARTASPORPACOTE() (*big.Int, error) {
+	return _GameEconomy.Contract.CARTASPORPACOTE(&_GameEconomy.CallOpts)
+}
+
+// PRAZOREVELACAO is a free data retrieval call binding the contract method 0x08bb2b0b.
+//
+// Solidity: function PRAZO_REVELACAO() view returns(uint256)
+func (_GameEconomy *GameEconomyCaller) PRAZOREVELACAO(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "PRAZO_REVELACAO")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// PRAZOREVELACAO is a free data retrieval call binding the contract method 0x08bb2b0b.
+//
+// Solidity: function PRAZO_REVELACAO() view returns(uint256)
+func (_GameEconomy *GameEconomySession) PRAZOREVELACAO() (*big.Int, error) {
+	return _GameEconomy.Contract.PRAZOREVELACAO(&_GameEconomy.CallOpts)
+}
+
+// PRAZOREVELACAO is a free data retrieval call binding the contract method 0x08bb2b0b.
+//
+// Solidity: function PRAZO_REVELACAO() view returns(uint256)
+func (_GameEconomy *GameEconomyCallerSession) PRAZOREVELACAO() (*big.Int, error) {
+	return _GameEconomy.Contract.PRAZOREVELACAO(&_GameEconomy.CallOpts)
+}
+
+// VALIDADEPROPOSTA is a free data retrieval call binding the contract method 0x89e34d1a.
+//
+// Solidity: function VALIDADE_PROPOSTA() view returns(uint256)
+func (_GameEconomy *GameEconomyCaller) VALIDADEPROPOSTA(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "VALIDADE_PROPOSTA")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// VALIDADEPROPOSTA is a free data retrieval call binding the contract method 0x89e34d1a.
+//
+// Solidity: function VALIDADE_PROPOSTA() view returns(uint256)
+func (_GameEconomy *GameEconomySession) VALIDADEPROPOSTA() (*big.Int, error) {
+	return _GameEconomy.Contract.VALIDADEPROPOSTA(&_GameEconomy.CallOpts)
+}
+
+// VALIDADEPROPOSTA is a free data retrieval call binding the contract method 0x89e34d1a.
+//
+// Solidity: function VALIDADE_PROPOSTA() view returns(uint256)
+func (_GameEconomy *GameEconomyCallerSession) VALIDADEPROPOSTA() (*big.Int, error) {
+	return _GameEconomy.Contract.VALIDADEPROPOSTA(&_GameEconomy.CallOpts)
+}
+
+// Anuncios is a free data retrieval call binding the contract method 0x14732172.
+//
+// Solidity: function anuncios(uint256 ) view returns(address vendedor, uint256 cartaId, uint256 preco, bool ativo, uint256 timestamp)
+func (_GameEconomy *GameEconomyCaller) Anuncios(opts *bind.CallOpts, arg0 *big.Int) (struct {
+	Vendedor  common.Address
+	CartaId   *big.Int
+	Preco     *big.Int
+	Ativo     bool
+	Timestamp *big.Int
+}, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "anuncios", arg0)
+
+	outstruct := new(struct {
+		Vendedor  common.Address
+		CartaId   *big.Int
+		Preco     *big.Int
+		Ativo     bool
+		Timestamp *big.Int
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Vendedor = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	outstruct.CartaId = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	outstruct.Preco = *abi.ConvertType(out[2], new(*big.Int)).(**big.Int)
+	outstruct.Ativo = *abi.ConvertType(out[3], new(bool)).(*bool)
+	outstruct.Timestamp = *abi.ConvertType(out[4], new(*big.Int)).(**big.Int)
+
+	return *outstruct, err
+
+}
+
+// Anuncios is a free data retrieval call binding the contract method 0x14732172.
+//
+// Solidity: function anuncios(uint256 ) view returns(address vendedor, uint256 cartaId, uint256 preco, bool ativo, uint256 timestamp)
+func (_GameEconomy *GameEconomySession) Anuncios(arg0 *big.Int) (struct {
+	Vendedor  common.Address
+	CartaId   *big.Int
+	Preco     *big.Int
+	Ativo     bool
+	Timestamp *big.Int
+}, error) {
+	return _GameEconomy.Contract.Anuncios(&_GameEconomy.CallOpts, arg0)
+}
+
+// Anuncios is a free data retrieval call binding the contract method 0x14732172.
+//
+// Solidity: function anuncios(uint256 ) view returns(address vendedor, uint256 cartaId, uint256 preco, bool ativo, uint256 timestamp)
+func (_GameEconomy *GameEconomyCallerSession) Anuncios(arg0 *big.Int) (struct {
+	Vendedor  common.Address
+	CartaId   *big.Int
+	Preco     *big.Int
+	Ativo     bool
+	Timestamp *big.Int
+}, error) {
+	return _GameEconomy.Contract.Anuncios(&_GameEconomy.CallOpts, arg0)
+}
+
+// Cartas is a free data retrieval call binding the contract method 0x5b102ab8.
+//
+// Solidity: function cartas(uint256 ) view returns(uint256 id, string nome, string naipe, uint256 valor, string raridade, uint256 timestamp)
+func (_GameEconomy *GameEconomyCaller) Cartas(opts *bind.CallOpts, arg0 *big.Int) (struct {
+	Id        *big.Int
+	Nome      string
+	Naipe     string
+	Valor     *big.Int
+	Raridade  string
+	Timestamp *big.Int
+}, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "cartas", arg0)
+
+	outstruct := new(struct {
+		Id        *big.Int
+		Nome      string
+		Naipe     string
+		Valor     *big.Int
+		Raridade  string
+		Timestamp *big.Int
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Id = *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	outstruct.Nome = *abi.ConvertType(out[1], new(string)).(*string)
+	outstruct.Naipe = *abi.ConvertType(out[2], new(string)).(*string)
+	outstruct.Valor = *abi.ConvertType(out[3], new(*big.Int)).(**big.Int)
+	outstruct.Raridade = *abi.ConvertType(out[4], new(string)).(*string)
+	outstruct.Timestamp = *abi.ConvertType(out[5], new(*big.Int)).(**big.Int)
+
+	return *outstruct, err
+
+}
+
+// Cartas is a free data retrieval call binding the contract method 0x5b102ab8.
+//
+// Solidity: function cartas(uint256 ) view returns(uint256 id, string nome, string naipe, uint256 valor, string raridade, uint256 timestamp)
+func (_GameEconomy *GameEconomySession) Cartas(arg0 *big.Int) (struct {
+	Id        *big.Int
+	Nome      string
+	Naipe     string
+	Valor     *big.Int
+	Raridade  string
+	Timestamp *big.Int
+}, error) {
+	return _GameEconomy.Contract.Cartas(&_GameEconomy.CallOpts, arg0)
+}
+
+// Cartas is a free data retrieval call binding the contract method 0x5b102ab8.
+//
+// Solidity: function cartas(uint256 ) view returns(uint256 id, string nome, string naipe, uint256 valor, string raridade, uint256 timestamp)
+func (_GameEconomy *GameEconomyCallerSession) Cartas(arg0 *big.Int) (struct {
+	Id        *big.Int
+	Nome      string
+	Naipe     string
+	Valor     *big.Int
+	Raridade  string
+	Timestamp *big.Int
+}, error) {
+	return _GameEconomy.Contract.Cartas(&_GameEconomy.CallOpts, arg0)
+}
+
+// ComprasComprometidas is a free data retrieval call binding the contract method 0xd591e1ce.
+//
+// Solidity: function comprasComprometidas(address ) view returns(uint256)
+func (_GameEconomy *GameEconomyCaller) ComprasComprometidas(opts *bind.CallOpts, arg0 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "comprasComprometidas", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// ComprasComprometidas is a free data retrieval call binding the contract method 0xd591e1ce.
+//
+// Solidity: function comprasComprometidas(address ) view returns(uint256)
+func (_GameEconomy *GameEconomySession) ComprasComprometidas(arg0 common.Address) (*big.Int, error) {
+	return _GameEconomy.Contract.ComprasComprometidas(&_GameEconomy.CallOpts, arg0)
+}
+
+// ComprasComprometidas is a free data retrieval call binding the contract method 0xd591e1ce.
+//
+// Solidity: function comprasComprometidas(address ) view returns(uint256)
+func (_GameEconomy *GameEconomyCallerSession) ComprasComprometidas(arg0 common.Address) (*big.Int, error) {
+	return _GameEconomy.Contract.ComprasComprometidas(&_GameEconomy.CallOpts, arg0)
+}
+
+// ComprasPendentes is a free data retrieval call binding the contract method 0x3fa49fae.
+//
+// Solidity: function comprasPendentes(address ) view returns(bytes32 compromisso, uint256 bloco)
+func (_GameEconomy *GameEconomyCaller) ComprasPendentes(opts *bind.CallOpts, arg0 common.Address) (struct {
+	Compromisso [32]byte
+	Bloco       *big.Int
+}, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "comprasPendentes", arg0)
+
+	outstruct := new(struct {
+		Compromisso [32]byte
+		Bloco       *big.Int
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Compromisso = *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+	outstruct.Bloco = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+
+	return *outstruct, err
+
+}
+
+// ComprasPendentes is a free data retrieval call binding the contract method 0x3fa49fae.
+//
+// Solidity: function comprasPendentes(address ) view returns(bytes32 compromisso, uint256 bloco)
+func (_GameEconomy *GameEconomySession) ComprasPendentes(arg0 common.Address) (struct {
+	Compromisso [32]byte
+	Bloco       *big.Int
+}, error) {
+	return _GameEconomy.Contract.ComprasPendentes(&_GameEconomy.CallOpts, arg0)
+}
+
+// ComprasPendentes is a free data retrieval call binding the contract method 0x3fa49fae.
+//
+// Solidity: function comprasPendentes(address ) view returns(bytes32 compromisso, uint256 bloco)
+func (_GameEconomy *GameEconomyCallerSession) ComprasPendentes(arg0 common.Address) (struct {
+	Compromisso [32]byte
+	Bloco       *big.Int
+}, error) {
+	return _GameEconomy.Contract.ComprasPendentes(&_GameEconomy.CallOpts, arg0)
+}
+
+// HashCompromisso is a free data retrieval call binding the contract method 0xd26bc1c8.
+//
+// Solidity: function hashCompromisso(address _comprador, bytes32 _segredo) pure returns(bytes32)
+func (_GameEconomy *GameEconomyCaller) HashCompromisso(opts *bind.CallOpts, _comprador common.Address, _segredo [32]byte) ([32]byte, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "hashCompromisso", _comprador, _segredo)
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// HashCompromisso is a free data retrieval call binding the contract method 0xd26bc1c8.
+//
+// Solidity: function hashCompromisso(address _comprador, bytes32 _segredo) pure returns(bytes32)
+func (_GameEconomy *GameEconomySession) HashCompromisso(_comprador common.Address, _segredo [32]byte) ([32]byte, error) {
+	return _GameEconomy.Contract.HashCompromisso(&_GameEconomy.CallOpts, _comprador, _segredo)
+}
+
+// HashCompromisso is a free data retrieval call binding the contract method 0xd26bc1c8.
+//
+// Solidity: function hashCompromisso(address _comprador, bytes32 _segredo) pure returns(bytes32)
+func (_GameEconomy *GameEconomyCallerSession) HashCompromisso(_comprador common.Address, _segredo [32]byte) ([32]byte, error) {
+	return _GameEconomy.Contract.HashCompromisso(&_GameEconomy.CallOpts, _comprador, _segredo)
+}
+
+// HashResultado is a free data retrieval call binding the contract method 0xf1bd0d4a.
+//
+// Solidity: function hashResultado(address _jogador1, address _jogador2, address _vencedor, bytes32 _hashLog) view returns(bytes32)
+func (_GameEconomy *GameEconomyCaller) HashResultado(opts *bind.CallOpts, _jogador1 common.Address, _jogador2 common.Address, _vencedor common.Address, _hashLog [32]byte) ([32]byte, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "hashResultado", _jogador1, _jogador2, _vencedor, _hashLog)
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// HashResultado is a free data retrieval call binding the contract method 0xf1bd0d4a.
+//
+// Solidity: function hashResultado(address _jogador1, address _jogador2, address _vencedor, bytes32 _hashLog) view returns(bytes32)
+func (_GameEconomy *GameEconomySession) HashResultado(_jogador1 common.Address, _jogador2 common.Address, _vencedor common.Address, _hashLog [32]byte) ([32]byte, error) {
+	return _GameEconomy.Contract.HashResultado(&_GameEconomy.CallOpts, _jogador1, _jogador2, _vencedor, _hashLog)
+}
+
+// HashResultado is a free data retrieval call binding the contract method 0xf1bd0d4a.
+//
+// Solidity: function hashResultado(address _jogador1, address _jogador2, address _vencedor, bytes32 _hashLog) view returns(bytes32)
+func (_GameEconomy *GameEconomyCallerSession) HashResultado(_jogador1 common.Address, _jogador2 common.Address, _vencedor common.Address, _hashLog [32]byte) ([32]byte, error) {
+	return _GameEconomy.Contract.HashResultado(&_GameEconomy.CallOpts, _jogador1, _jogador2, _vencedor, _hashLog)
+}
+
+// Inventario is a free data retrieval call binding the contract method 0xab97fd60.
+//
+// Solidity: function inventario(address , uint256 ) view returns(uint256)
+func (_GameEconomy *GameEconomyCaller) Inventario(opts *bind.CallOpts, arg0 common.Address, arg1 *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "inventario", arg0, arg1)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// Inventario is a free data retrieval call binding the contract method 0xab97fd60.
+//
+// Solidity: function inventario(address , uint256 ) view returns(uint256)
+func (_GameEconomy *GameEconomySession) Inventario(arg0 common.Address, arg1 *big.Int) (*big.Int, error) {
+	return _GameEconomy.Contract.Inventario(&_GameEconomy.CallOpts, arg0, arg1)
+}
+
+// Inventario is a free data retrieval call binding the contract method 0xab97fd60.
+//
+// Solidity: function inventario(address , uint256 ) view returns(uint256)
+func (_GameEconomy *GameEconomyCallerSession) Inventario(arg0 common.Address, arg1 *big.Int) (*big.Int, error) {
+	return _GameEconomy.Contract.Inventario(&_GameEconomy.CallOpts, arg0, arg1)
+}
+
+// ObterAnuncio is a free data retrieval call binding the contract method 0xc905b101.
+//
+// Solidity: function obterAnuncio(uint256 anuncioId) view returns((address,uint256,uint256,bool,uint256))
+func (_GameEconomy *GameEconomyCaller) ObterAnuncio(opts *bind.CallOpts, anuncioId *big.Int) (Anuncio, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "obterAnuncio", anuncioId)
+
+	if err != nil {
+		return *new(Anuncio), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(Anuncio)).(*Anuncio)
+
+	return out0, err
+
+}
+
+// ObterAnuncio is a free data retrieval call binding the contract method 0xc905b101.
+//
+// Solidity: function obterAnuncio(uint256 anuncioId) view returns((address,uint256,uint256,bool,uint256))
+func (_GameEconomy *GameEconomySession) ObterAnuncio(anuncioId *big.Int) (Anuncio, error) {
+	return _GameEconomy.Contract.ObterAnuncio(&_GameEconomy.CallOpts, anuncioId)
+}
+
+// ObterAnuncio is a free data retrieval call binding the contract method 0xc905b101.
+//
+// Solidity: function obterAnuncio(uint256 anuncioId) view returns((address,uint256,uint256,bool,uint256))
+func (_GameEconomy *GameEconomyCallerSession) ObterAnuncio(anuncioId *big.Int) (Anuncio, error) {
+	return _GameEconomy.Contract.ObterAnuncio(&_GameEconomy.CallOpts, anuncioId)
+}
+
+// ObterAnunciosAtivos is a free data retrieval call binding the contract method 0x7e3dcd1f.
+//
+// Solidity: function obterAnunciosAtivos() view returns(uint256[])
+func (_GameEconomy *GameEconomyCaller) ObterAnunciosAtivos(opts *bind.CallOpts) ([]*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "obterAnunciosAtivos")
+
+	if err != nil {
+		return *new([]*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]*big.Int)).(*[]*big.Int)
+
+	return out0, err
+
+}
+
+// ObterAnunciosAtivos is a free data retrieval call binding the contract method 0x7e3dcd1f.
+//
+// Solidity: function obterAnunciosAtivos() view returns(uint256[])
+func (_GameEconomy *GameEconomySession) ObterAnunciosAtivos() ([]*big.Int, error) {
+	return _GameEconomy.Contract.ObterAnunciosAtivos(&_GameEconomy.CallOpts)
+}
+
+// ObterAnunciosAtivos is a free data retrieval call binding the contract method 0x7e3dcd1f.
+//
+// Solidity: function obterAnunciosAtivos() view returns(uint256[])
+func (_GameEconomy *GameEconomyCallerSession) ObterAnunciosAtivos() ([]*big.Int, error) {
+	return _GameEconomy.Contract.ObterAnunciosAtivos(&_GameEconomy.CallOpts)
+}
+
+// ObterCarta is a free data retrieval call binding the contract method 0x3c3d312e.
+//
+// Solidity: function obterCarta(uint256 tokenId) view returns((uint256,string,string,uint256,string,uint256))
+func (_GameEconomy *GameEconomyCaller) ObterCarta(opts *bind.CallOpts, tokenId *big.Int) (Carta, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "obterCarta", tokenId)
+
+	if err != nil {
+		return *new(Carta), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(Carta)).(*Carta)
+
+	return out0, err
+
+}
+
+// ObterCarta is a free data retrieval call binding the contract method 0x3c3d312e.
+//
+// Solidity: function obterCarta(uint256 tokenId) view returns((uint256,string,string,uint256,string,uint256))
+func (_GameEconomy *GameEconomySession) ObterCarta(tokenId *big.Int) (Carta, error) {
+	return _GameEconomy.Contract.ObterCarta(&_GameEconomy.CallOpts, tokenId)
+}
+
+// ObterCarta is a free data retrieval call binding the contract method 0x3c3d312e.
+//
+// Solidity: function obterCarta(uint256 tokenId) view returns((uint256,string,string,uint256,string,uint256))
+func (_GameEconomy *GameEconomyCallerSession) ObterCarta(tokenId *big.Int) (Carta, error) {
+	return _GameEconomy.Contract.ObterCarta(&_GameEconomy.CallOpts, tokenId)
+}
+
+// ObterInventario is a free data retrieval call binding the contract method 0xe4d594dc.
+//
+// Solidity: function obterInventario(address _jogador) view returns(uint256[])
+func (_GameEconomy *GameEconomyCaller) ObterInventario(opts *bind.CallOpts, _jogador common.Address) ([]*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "obterInventario", _jogador)
+
+	if err != nil {
+		return *new([]*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]*big.Int)).(*[]*big.Int)
+
+	return out0, err
+
+}
+
+// ObterInventario is a free data retrieval call binding the contract method 0xe4d594dc.
+//
+// Solidity: function obterInventario(address _jogador) view returns(uint256[])
+func (_GameEconomy *GameEconomySession) ObterInventario(_jogador common.Address) ([]*big.Int, error) {
+	return _GameEconomy.Contract.ObterInventario(&_GameEconomy.CallOpts, _jogador)
+}
+
+// ObterInventario is a free data retrieval call binding the contract method 0xe4d594dc.
+//
+// Solidity: function obterInventario(address _jogador) view returns(uint256[])
+func (_GameEconomy *GameEconomyCallerSession) ObterInventario(_jogador common.Address) ([]*big.Int, error) {
+	return _GameEconomy.Contract.ObterInventario(&_GameEconomy.CallOpts, _jogador)
+}
+
+// ObterPartida is a free data retrieval call binding the contract method 0xf4aaff2f.
+//
+// Solidity: function obterPartida(uint256 indice) view returns((address,address,address,uint256,bytes32,address,address))
+func (_GameEconomy *GameEconomyCaller) ObterPartida(opts *bind.CallOpts, indice *big.Int) (Partida, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "obterPartida", indice)
+
+	if err != nil {
+		return *new(Partida), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(Partida)).(*Partida)
+
+	return out0, err
+
+}
+
+// ObterPartida is a free data retrieval call binding the contract method 0xf4aaff2f.
+//
+// Solidity: function obterPartida(uint256 indice) view returns((address,address,address,uint256,bytes32,address,address))
+func (_GameEconomy *GameEconomySession) ObterPartida(indice *big.Int) (Partida, error) {
+	return _GameEconomy.Contract.ObterPartida(&_GameEconomy.CallOpts, indice)
+}
+
+// ObterPartida is a free data retrieval call binding the contract method 0xf4aaff2f.
+//
+// Solidity: function obterPartida(uint256 indice) view returns((address,address,address,uint256,bytes32,address,address))
+func (_GameEconomy *GameEconomyCallerSession) ObterPartida(indice *big.Int) (Partida, error) {
+	return _GameEconomy.Contract.ObterPartida(&_GameEconomy.CallOpts, indice)
+}
+
+// ObterPropostaTroca is a free data retrieval call binding the contract method 0x0d142b54.
+//
+// Solidity: function obterPropostaTroca(uint256 propostaId) view returns((address,address,uint256,uint256,bool,bool,uint256,uint256,bool,bool))
+func (_GameEconomy *GameEconomyCaller) ObterPropostaTroca(opts *bind.CallOpts, propostaId *big.Int) (PropostaTroca, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "obterPropostaTroca", propostaId)
+
+	if err != nil {
+		return *new(PropostaTroca), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(PropostaTroca)).(*PropostaTroca)
+
+	return out0, err
+
+}
+
+// ObterPropostaTroca is a free data retrieval call binding the contract method 0x0d142b54.
+//
+// Solidity: function obterPropostaTroca(uint256 propostaId) view returns((address,address,uint256,uint256,bool,bool,uint256,uint256,bool,bool))
+func (_GameEconomy *GameEconomySession) ObterPropostaTroca(propostaId *big.Int) (PropostaTroca, error) {
+	return _GameEconomy.Contract.ObterPropostaTroca(&_GameEconomy.CallOpts, propostaId)
+}
+
+// ObterPropostaTroca is a free data retrieval call binding the contract method 0x0d142b54.
+//
+// Solidity: function obterPropostaTroca(uint256 propostaId) view returns((address,address,uint256,uint256,bool,bool,uint256,uint256,bool,bool))
+func (_GameEconomy *GameEconomyCallerSession) ObterPropostaTroca(propostaId *big.Int) (PropostaTroca, error) {
+	return _GameEconomy.Contract.ObterPropostaTroca(&_GameEconomy.CallOpts, propostaId)
+}
+
+// ObterPropostasEnviadas is a free data retrieval call binding the contract method 0x38e7b059.
+//
+// Solidity: function obterPropostasEnviadas(address _jogador) view returns(uint256[])
+func (_GameEconomy *GameEconomyCaller) ObterPropostasEnviadas(opts *bind.CallOpts, _jogador common.Address) ([]*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "obterPropostasEnviadas", _jogador)
+
+	if err != nil {
+		return *new([]*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]*big.Int)).(*[]*big.Int)
+
+	return out0, err
+
+}
+
+// ObterPropostasEnviadas is a free data retrieval call binding the contract method 0x38e7b059.
+//
+// Solidity: function obterPropostasEnviadas(address _jogador) view returns(uint256[])
+func (_GameEconomy *GameEconomySession) ObterPropostasEnviadas(_jogador common.Address) ([]*big.Int, error) {
+	return _GameEconomy.Contract.ObterPropostasEnviadas(&_GameEconomy.CallOpts, _jogador)
+}
+
+// ObterPropostasEnviadas is a free data retrieval call binding the contract method 0x38e7b059.
+//
+// Solidity: function obterPropostasEnviadas(address _jogador) view returns(uint256[])
+func (_GameEconomy *GameEconomyCallerSession) ObterPropostasEnviadas(_jogador common.Address) ([]*big.Int, error) {
+	return _GameEconomy.Contract.ObterPropostasEnviadas(&_GameEconomy.CallOpts, _jogador)
+}
+
+// ObterPropostasRecebidas is a free data retrieval call binding the contract method 0x8e0c7ceb.
+//
+// Solidity: function obterPropostasRecebidas(address _jogador) view returns(uint256[])
+func (_GameEconomy *GameEconomyCaller) ObterPropostasRecebidas(opts *bind.CallOpts, _jogador common.Address) ([]*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "obterPropostasRecebidas", _jogador)
+
+	if err != nil {
+		return *new([]*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]*big.Int)).(*[]*big.Int)
+
+	return out0, err
+
+}
+
+// ObterPropostasRecebidas is a free data retrieval call binding the contract method 0x8e0c7ceb.
+//
+// Solidity: function obterPropostasRecebidas(address _jogador) view returns(uint256[])
+func (_GameEconomy *GameEconomySession) ObterPropostasRecebidas(_jogador common.Address) ([]*big.Int, error) {
+	return _GameEconomy.Contract.ObterPropostasRecebidas(&_GameEconomy.CallOpts, _jogador)
+}
+
+// ObterPropostasRecebidas is a free data retrieval call binding the contract method 0x8e0c7ceb.
+//
+// Solidity: function obterPropostasRecebidas(address _jogador) view returns(uint256[])
+func (_GameEconomy *GameEconomyCallerSession) ObterPropostasRecebidas(_jogador common.Address) ([]*big.Int, error) {
+	return _GameEconomy.Contract.ObterPropostasRecebidas(&_GameEconomy.CallOpts, _jogador)
+}
+
+// ObterSaldo is a free data retrieval call binding the contract method 0xaa2e4c38.
+//
+// Solidity: function obterSaldo(address _jogador) view returns(uint256)
+func (_GameEconomy *GameEconomyCaller) ObterSaldo(opts *bind.CallOpts, _jogador common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "obterSaldo", _jogador)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// ObterSaldo is a free data retrieval call binding the contract method 0xaa2e4c38.
+//
+// Solidity: function obterSaldo(address _jogador) view returns(uint256)
+func (_GameEconomy *GameEconomySession) ObterSaldo(_jogador common.Address) (*big.Int, error) {
+	return _GameEconomy.Contract.ObterSaldo(&_GameEconomy.CallOpts, _jogador)
+}
+
+// ObterSaldo is a free data retrieval call binding the contract method 0xaa2e4c38.
+//
+// Solidity: function obterSaldo(address _jogador) view returns(uint256)
+func (_GameEconomy *GameEconomyCallerSession) ObterSaldo(_jogador common.Address) (*big.Int, error) {
+	return _GameEconomy.Contract.ObterSaldo(&_GameEconomy.CallOpts, _jogador)
+}
+
+// ObterTotalPartidas is a free data retrieval call binding the contract method 0x0df61e14.
+//
+// Solidity: function obterTotalPartidas() view returns(uint256)
+func (_GameEconomy *GameEconomyCaller) ObterTotalPartidas(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "obterTotalPartidas")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// ObterTotalPartidas is a free data retrieval call binding the contract method 0x0df61e14.
+//
+// Solidity: function obterTotalPartidas() view returns(uint256)
+func (_GameEconomy *GameEconomySession) ObterTotalPartidas() (*big.Int, error) {
+	return _GameEconomy.Contract.ObterTotalPartidas(&_GameEconomy.CallOpts)
+}
+
+// ObterTotalPartidas is a free data retrieval call binding the contract method 0x0df61e14.
+//
+// Solidity: function obterTotalPartidas() view returns(uint256)
+func (_GameEconomy *GameEconomyCallerSession) ObterTotalPartidas() (*big.Int, error) {
+	return _GameEconomy.Contract.ObterTotalPartidas(&_GameEconomy.CallOpts)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_GameEconomy *GameEconomyCaller) Owner(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "owner")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_GameEconomy *GameEconomySession) Owner() (common.Address, error) {
+	return _GameEconomy.Contract.Owner(&_GameEconomy.CallOpts)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_GameEconomy *GameEconomyCallerSession) Owner() (common.Address, error) {
+	return _GameEconomy.Contract.Owner(&_GameEconomy.CallOpts)
+}
+
+// PartidaPorLog is a free data retrieval call binding the contract method 0x28fbad5d.
+//
+// Solidity: function partidaPorLog(bytes32 ) view returns(uint256)
+func (_GameEconomy *GameEconomyCaller) PartidaPorLog(opts *bind.CallOpts, arg0 [32]byte) (*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "partidaPorLog", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// PartidaPorLog is a free data retrieval call binding the contract method 0x28fbad5d.
+//
+// Solidity: function partidaPorLog(bytes32 ) view returns(uint256)
+func (_GameEconomy *GameEconomySession) PartidaPorLog(arg0 [32]byte) (*big.Int, error) {
+	return _GameEconomy.Contract.PartidaPorLog(&_GameEconomy.CallOpts, arg0)
+}
+
+// PartidaPorLog is a free data retrieval call binding the contract method 0x28fbad5d.
+//
+// Solidity: function partidaPorLog(bytes32 ) view returns(uint256)
+func (_GameEconomy *GameEconomyCallerSession) PartidaPorLog(arg0 [32]byte) (*big.Int, error) {
+	return _GameEconomy.Contract.PartidaPorLog(&_GameEconomy.CallOpts, arg0)
+}
+
+// Partidas is a free data retrieval call binding the contract method 0xc4c7ab5a.
+//
+// Solidity: function partidas(uint256 ) view returns(address jogador1, address jogador2, address vencedor, uint256 timestamp, bytes32 hashLog, address servidorHost, address servidorSombra)
+func (_GameEconomy *GameEconomyCaller) Partidas(opts *bind.CallOpts, arg0 *big.Int) (struct {
+	Jogador1       common.Address
+	Jogador2       common.Address
+	Vencedor       common.Address
+	Timestamp      *big.Int
+	HashLog        [32]byte
+	ServidorHost   common.Address
+	ServidorSombra common.Address
+}, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "partidas", arg0)
+
+	outstruct := new(struct {
+		Jogador1       common.Address
+		Jogador2       common.Address
+		Vencedor       common.Address
+		Timestamp      *big.Int
+		HashLog        [32]byte
+		ServidorHost   common.Address
+		ServidorSombra common.Address
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Jogador1 = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	outstruct.Jogador2 = *abi.ConvertType(out[1], new(common.Address)).(*common.Address)
+	outstruct.Vencedor = *abi.ConvertType(out[2], new(common.Address)).(*common.Address)
+	outstruct.Timestamp = *abi.ConvertType(out[3], new(*big.Int)).(**big.Int)
+	outstruct.HashLog = *abi.ConvertType(out[4], new([32]byte)).(*[32]byte)
+	outstruct.ServidorHost = *abi.ConvertType(out[5], new(common.Address)).(*common.Address)
+	outstruct.ServidorSombra = *abi.ConvertType(out[6], new(common.Address)).(*common.Address)
+
+	return *outstruct, err
+
+}
+
+// Partidas is a free data retrieval call binding the contract method 0xc4c7ab5a.
+//
+// Solidity: function partidas(uint256 ) view returns(address jogador1, address jogador2, address vencedor, uint256 timestamp, bytes32 hashLog, address servidorHost, address servidorSombra)
+func (_GameEconomy *GameEconomySession) Partidas(arg0 *big.Int) (struct {
+	Jogador1       common.Address
+	Jogador2       common.Address
+	Vencedor       common.Address
+	Timestamp      *big.Int
+	HashLog        [32]byte
+	ServidorHost   common.Address
+	ServidorSombra common.Address
+}, error) {
+	return _GameEconomy.Contract.Partidas(&_GameEconomy.CallOpts, arg0)
+}
+
+// Partidas is a free data retrieval call binding the contract method 0xc4c7ab5a.
+//
+// Solidity: function partidas(uint256 ) view returns(address jogador1, address jogador2, address vencedor, uint256 timestamp, bytes32 hashLog, address servidorHost, address servidorSombra)
+func (_GameEconomy *GameEconomyCallerSession) Partidas(arg0 *big.Int) (struct {
+	Jogador1       common.Address
+	Jogador2       common.Address
+	Vencedor       common.Address
+	Timestamp      *big.Int
+	HashLog        [32]byte
+	ServidorHost   common.Address
+	ServidorSombra common.Address
+}, error) {
+	return _GameEconomy.Contract.Partidas(&_GameEconomy.CallOpts, arg0)
+}
+
+// PrecoPacote is a free data retrieval call binding the contract method 0x1002ea4d.
+//
+// Solidity: function precoPacote() view returns(uint256)
+func (_GameEconomy *GameEconomyCaller) PrecoPacote(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "precoPacote")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// PrecoPacote is a free data retrieval call binding the contract method 0x1002ea4d.
+//
+// Solidity: function precoPacote() view returns(uint256)
+func (_GameEconomy *GameEconomySession) PrecoPacote() (*big.Int, error) {
+	return _GameEconomy.Contract.PrecoPacote(&_GameEconomy.CallOpts)
+}
+
+// PrecoPacote is a free data retrieval call binding the contract method 0x1002ea4d.
+//
+// Solidity: function precoPacote() view returns(uint256)
+func (_GameEconomy *GameEconomyCallerSession) PrecoPacote() (*big.Int, error) {
+	return _GameEconomy.Contract.PrecoPacote(&_GameEconomy.CallOpts)
+}
+
+// PropostasTroca is a free data retrieval call binding the contract method 0x535c898a.
+//
+// Solidity: function propostasTroca(uint256 ) view returns(address jogador1, address jogador2, uint256 cartaJogador1, uint256 cartaJogador2, bool aceita, bool executada, uint256 timestamp, uint256 expiraEm, bool cancelada, bool recusada)
+func (_GameEconomy *GameEconomyCaller) PropostasTroca(opts *bind.CallOpts, arg0 *big.Int) (struct {
+	Jogador1      common.Address
+	Jogador2      common.Address
+	CartaJogador1 *big.Int
+	CartaJogador2 *big.Int
+	Aceita        bool
+	Executada     bool
+	Timestamp     *big.Int
+	ExpiraEm      *big.Int
+	Cancelada     bool
+	Recusada      bool
+}, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "propostasTroca", arg0)
+
+	outstruct := new(struct {
+		Jogador1      common.Address
+		Jogador2      common.Address
+		CartaJogador1 *big.Int
+		CartaJogador2 *big.Int
+		Aceita        bool
+		Executada     bool
+		Timestamp     *big.Int
+		ExpiraEm      *big.Int
+		Cancelada     bool
+		Recusada      bool
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.Jogador1 = *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	outstruct.Jogador2 = *abi.ConvertType(out[1], new(common.Address)).(*common.Address)
+	outstruct.CartaJogador1 = *abi.ConvertType(out[2], new(*big.Int)).(**big.Int)
+	outstruct.CartaJogador2 = *abi.ConvertType(out[3], new(*big.Int)).(**big.Int)
+	outstruct.Aceita = *abi.ConvertType(out[4], new(bool)).(*bool)
+	outstruct.Executada = *abi.ConvertType(out[5], new(bool)).(*bool)
+	outstruct.Timestamp = *abi.ConvertType(out[6], new(*big.Int)).(**big.Int)
+	outstruct.ExpiraEm = *abi.ConvertType(out[7], new(*big.Int)).(**big.Int)
+	outstruct.Cancelada = *abi.ConvertType(out[8], new(bool)).(*bool)
+	outstruct.Recusada = *abi.ConvertType(out[9], new(bool)).(*bool)
+
+	return *outstruct, err
+
+}
+
+// PropostasTroca is a free data retrieval call binding the contract method 0x535c898a.
+//
+// Solidity: function propostasTroca(uint256 ) view returns(address jogador1, address jogador2, uint256 cartaJogador1, uint256 cartaJogador2, bool aceita, bool executada, uint256 timestamp, uint256 expiraEm, bool cancelada, bool recusada)
+func (_GameEconomy *GameEconomySession) PropostasTroca(arg0 *big.Int) (struct {
+	Jogador1      common.Address
+	Jogador2      common.Address
+	CartaJogador1 *big.Int
+	CartaJogador2 *big.Int
+	Aceita        bool
+	Executada     bool
+	Timestamp     *big.Int
+	ExpiraEm      *big.Int
+	Cancelada     bool
+	Recusada      bool
+}, error) {
+	return _GameEconomy.Contract.PropostasTroca(&_GameEconomy.CallOpts, arg0)
+}
+
+// PropostasTroca is a free data retrieval call binding the contract method 0x535c898a.
+//
+// Solidity: function propostasTroca(uint256 ) view returns(address jogador1, address jogador2, uint256 cartaJogador1, uint256 cartaJogador2, bool aceita, bool executada, uint256 timestamp, uint256 expiraEm, bool cancelada, bool recusada)
+func (_GameEconomy *GameEconomyCallerSession) PropostasTroca(arg0 *big.Int) (struct {
+	Jogador1      common.Address
+	Jogador2      common.Address
+	CartaJogador1 *big.Int
+	CartaJogador2 *big.Int
+	Aceita        bool
+	Executada     bool
+	Timestamp     *big.Int
+	ExpiraEm      *big.Int
+	Cancelada     bool
+	Recusada      bool
+}, error) {
+	return _GameEconomy.Contract.PropostasTroca(&_GameEconomy.CallOpts, arg0)
+}
+
+// Proprietario is a free data retrieval call binding the contract method 0x7f8f472f.
+//
+// Solidity: function proprietario(uint256 ) view returns(address)
+func (_GameEconomy *GameEconomyCaller) Proprietario(opts *bind.CallOpts, arg0 *big.Int) (common.Address, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "proprietario", arg0)
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Proprietario is a free data retrieval call binding the contract method 0x7f8f472f.
+//
+// Solidity: function proprietario(uint256 ) view returns(address)
+func (_GameEconomy *GameEconomySession) Proprietario(arg0 *big.Int) (common.Address, error) {
+	return _GameEconomy.Contract.Proprietario(&_GameEconomy.CallOpts, arg0)
+}
+
+// Proprietario is a free data retrieval call binding the contract method 0x7f8f472f.
+//
+// Solidity: function proprietario(uint256 ) view returns(address)
+func (_GameEconomy *GameEconomyCallerSession) Proprietario(arg0 *big.Int) (common.Address, error) {
+	return _GameEconomy.Contract.Proprietario(&_GameEconomy.CallOpts, arg0)
+}
+
+// Saldo is a free data retrieval call binding the contract method 0x422fb525.
+//
+// Solidity: function saldo(address ) view returns(uint256)
+func (_GameEconomy *GameEconomyCaller) Saldo(opts *bind.CallOpts, arg0 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "saldo", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// Saldo is a free data retrieval call binding the contract method 0x422fb525.
+//
+// Solidity: function saldo(address ) view returns(uint256)
+func (_GameEconomy *GameEconomySession) Saldo(arg0 common.Address) (*big.Int, error) {
+	return _GameEconomy.Contract.Saldo(&_GameEconomy.CallOpts, arg0)
+}
+
+// Saldo is a free data retrieval call binding the contract method 0x422fb525.
+//
+// Solidity: function saldo(address ) view returns(uint256)
+func (_GameEconomy *GameEconomyCallerSession) Saldo(arg0 common.Address) (*big.Int, error) {
+	return _GameEconomy.Contract.Saldo(&_GameEconomy.CallOpts, arg0)
+}
+
+// SaldoVendas is a free data retrieval call binding the contract method 0x51317a1f.
+//
+// Solidity: function saldoVendas(address ) view returns(uint256)
+func (_GameEconomy *GameEconomyCaller) SaldoVendas(opts *bind.CallOpts, arg0 common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "saldoVendas", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// SaldoVendas is a free data retrieval call binding the contract method 0x51317a1f.
+//
+// Solidity: function saldoVendas(address ) view returns(uint256)
+func (_GameEconomy *GameEconomySession) SaldoVendas(arg0 common.Address) (*big.Int, error) {
+	return _GameEconomy.Contract.SaldoVendas(&_GameEconomy.CallOpts, arg0)
+}
+
+// SaldoVendas is a free data retrieval call binding the contract method 0x51317a1f.
+//
+// Solidity: function saldoVendas(address ) view returns(uint256)
+func (_GameEconomy *GameEconomyCallerSession) SaldoVendas(arg0 common.Address) (*big.Int, error) {
+	return _GameEconomy.Contract.SaldoVendas(&_GameEconomy.CallOpts, arg0)
+}
+
+// ServidoresAutorizados is a free data retrieval call binding the contract method 0xf0704326.
+//
+// Solidity: function servidoresAutorizados(address ) view returns(bool)
+func (_GameEconomy *GameEconomyCaller) ServidoresAutorizados(opts *bind.CallOpts, arg0 common.Address) (bool, error) {
+	var out []interface{}
+	err := _GameEconomy.contract.Call(opts, &out, "servidoresAutorizados", arg0)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// ServidoresAutorizados is a free data retrieval call binding the contract method 0xf0704326.
+//
+// Solidity: function servidoresAutorizados(address ) view returns(bool)
+func (_GameEconomy *GameEconomySession) ServidoresAutorizados(arg0 common.Address) (bool, error) {
+	return _GameEconomy.Contract.ServidoresAutorizados(&_GameEconomy.CallOpts, arg0)
+}
+
+// ServidoresAutorizados is a free data retrieval call binding the contract method 0xf0704326.
+//
+// Solidity: function servidoresAutorizados(address ) view returns(bool)
+func (_GameEconomy *GameEconomyCallerSession) ServidoresAutorizados(arg0 common.Address) (bool, error) {
+	return _GameEconomy.Contract.ServidoresAutorizados(&_GameEconomy.CallOpts, arg0)
+}
+
+// AceitarPropostaTroca is a paid mutator transaction binding the contract method 0xed097d5c.
+//
+// Solidity: function aceitarPropostaTroca(uint256 propostaId) returns()
+func (_GameEconomy *GameEconomyTransactor) AceitarPropostaTroca(opts *bind.TransactOpts, propostaId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "aceitarPropostaTroca", propostaId)
+}
+
+// AceitarPropostaTroca is a paid mutator transaction binding the contract method 0xed097d5c.
+//
+// Solidity: function aceitarPropostaTroca(uint256 propostaId) returns()
+func (_GameEconomy *GameEconomySession) AceitarPropostaTroca(propostaId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.AceitarPropostaTroca(&_GameEconomy.TransactOpts, propostaId)
+}
+
+// AceitarPropostaTroca is a paid mutator transaction binding the contract method 0xed097d5c.
+//
+// Solidity: function aceitarPropostaTroca(uint256 propostaId) returns()
+func (_GameEconomy *GameEconomyTransactorSession) AceitarPropostaTroca(propostaId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.AceitarPropostaTroca(&_GameEconomy.TransactOpts, propostaId)
+}
+
+// AnunciarCarta is a paid mutator transaction binding the contract method 0x7475a6a9.
+//
+// Solidity: function anunciarCarta(uint256 tokenId, uint256 _preco) returns(uint256)
+func (_GameEconomy *GameEconomyTransactor) AnunciarCarta(opts *bind.TransactOpts, tokenId *big.Int, _preco *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "anunciarCarta", tokenId, _preco)
+}
+
+// AnunciarCarta is a paid mutator transaction binding the contract method 0x7475a6a9.
+//
+// Solidity: function anunciarCarta(uint256 tokenId, uint256 _preco) returns(uint256)
+func (_GameEconomy *GameEconomySession) AnunciarCarta(tokenId *big.Int, _preco *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.AnunciarCarta(&_GameEconomy.TransactOpts, tokenId, _preco)
+}
+
+// AnunciarCarta is a paid mutator transaction binding the contract method 0x7475a6a9.
+//
+// Solidity: function anunciarCarta(uint256 tokenId, uint256 _preco) returns(uint256)
+func (_GameEconomy *GameEconomyTransactorSession) AnunciarCarta(tokenId *big.Int, _preco *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.AnunciarCarta(&_GameEconomy.TransactOpts, tokenId, _preco)
+}
+
+// AutorizarServidor is a paid mutator transaction binding the contract method 0x275a299e.
+//
+// Solidity: function autorizarServidor(address _servidor, bool _autorizado) returns()
+func (_GameEconomy *GameEconomyTransactor) AutorizarServidor(opts *bind.TransactOpts, _servidor common.Address, _autorizado bool) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "autorizarServidor", _servidor, _autorizado)
+}
+
+// AutorizarServidor is a paid mutator transaction binding the contract method 0x275a299e.
+//
+// Solidity: function autorizarServidor(address _servidor, bool _autorizado) returns()
+func (_GameEconomy *GameEconomySession) AutorizarServidor(_servidor common.Address, _autorizado bool) (*types.Transaction, error) {
+	return _GameEconomy.Contract.AutorizarServidor(&_GameEconomy.TransactOpts, _servidor, _autorizado)
+}
+
+// AutorizarServidor is a paid mutator transaction binding the contract method 0x275a299e.
+//
+// Solidity: function autorizarServidor(address _servidor, bool _autorizado) returns()
+func (_GameEconomy *GameEconomyTransactorSession) AutorizarServidor(_servidor common.Address, _autorizado bool) (*types.Transaction, error) {
+	return _GameEconomy.Contract.AutorizarServidor(&_GameEconomy.TransactOpts, _servidor, _autorizado)
+}
+
+// CancelarPropostaTroca is a paid mutator transaction binding the contract method 0xd0ab4ba7.
+//
+// Solidity: function cancelarPropostaTroca(uint256 propostaId) returns()
+func (_GameEconomy *GameEconomyTransactor) CancelarPropostaTroca(opts *bind.TransactOpts, propostaId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "cancelarPropostaTroca", propostaId)
+}
+
+// CancelarPropostaTroca is a paid mutator transaction binding the contract method 0xd0ab4ba7.
+//
+// Solidity: function cancelarPropostaTroca(uint256 propostaId) returns()
+func (_GameEconomy *GameEconomySession) CancelarPropostaTroca(propostaId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.CancelarPropostaTroca(&_GameEconomy.TransactOpts, propostaId)
+}
+
+// CancelarPropostaTroca is a paid mutator transaction binding the contract method 0xd0ab4ba7.
+//
+// Solidity: function cancelarPropostaTroca(uint256 propostaId) returns()
+func (_GameEconomy *GameEconomyTransactorSession) CancelarPropostaTroca(propostaId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.CancelarPropostaTroca(&_GameEconomy.TransactOpts, propostaId)
+}
+
+// ComprarCartaAnunciada is a paid mutator transaction binding the contract method 0xfeaac08c.
+//
+// Solidity: function comprarCartaAnunciada(uint256 anuncioId) payable returns()
+func (_GameEconomy *GameEconomyTransactor) ComprarCartaAnunciada(opts *bind.TransactOpts, anuncioId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "comprarCartaAnunciada", anuncioId)
+}
+
+// ComprarCartaAnunciada is a paid mutator transaction binding the contract method 0xfeaac08c.
+//
+// Solidity: function comprarCartaAnunciada(uint256 anuncioId) payable returns()
+func (_GameEconomy *GameEconomySession) ComprarCartaAnunciada(anuncioId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.ComprarCartaAnunciada(&_GameEconomy.TransactOpts, anuncioId)
+}
+
+// ComprarCartaAnunciada is a paid mutator transaction binding the contract method 0xfeaac08c.
+//
+// Solidity: function comprarCartaAnunciada(uint256 anuncioId) payable returns()
+func (_GameEconomy *GameEconomyTransactorSession) ComprarCartaAnunciada(anuncioId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.ComprarCartaAnunciada(&_GameEconomy.TransactOpts, anuncioId)
+}
+
+// ComprometerCompraPacote is a paid mutator transaction binding the contract method 0x46c0a4c7.
+//
+// Solidity: function comprometerCompraPacote(bytes32 _compromisso) payable returns()
+func (_GameEconomy *GameEconomyTransactor) ComprometerCompraPacote(opts *bind.TransactOpts, _compromisso [32]byte) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "comprometerCompraPacote", _compromisso)
+}
+
+// ComprometerCompraPacote is a paid mutator transaction binding the contract method 0x46c0a4c7.
+//
+// Solidity: function comprometerCompraPacote(bytes32 _compromisso) payable returns()
+func (_GameEconomy *GameEconomySession) ComprometerCompraPacote(_compromisso [32]byte) (*types.Transaction, error) {
+	return _GameEconomy.Contract.ComprometerCompraPacote(&_GameEconomy.TransactOpts, _compromisso)
+}
+
+// ComprometerCompraPacote is a paid mutator transaction binding the contract method 0x46c0a4c7.
+//
+// Solidity: function comprometerCompraPacote(bytes32 _compromisso) payable returns()
+func (_GameEconomy *GameEconomyTransactorSession) ComprometerCompraPacote(_compromisso [32]byte) (*types.Transaction, error) {
+	return _GameEconomy.Contract.ComprometerCompraPacote(&_GameEconomy.TransactOpts, _compromisso)
+}
+
+// CriarPropostaTroca is a paid mutator transaction binding the contract method 0xcba5ca8b.
+//
+// Solidity: function criarPropostaTroca(address _jogador2, uint256 _minhaCarta, uint256 _cartaDesejada) returns(uint256)
+func (_GameEconomy *GameEconomyTransactor) CriarPropostaTroca(opts *bind.TransactOpts, _jogador2 common.Address, _minhaCarta *big.Int, _cartaDesejada *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "criarPropostaTroca", _jogador2, _minhaCarta, _cartaDesejada)
+}
+
+// CriarPropostaTroca is a paid mutator transaction binding the contract method 0xcba5ca8b.
+//
+// Solidity: function criarPropostaTroca(address _jogador2, uint256 _minhaCarta, uint256 _cartaDesejada) returns(uint256)
+func (_GameEconomy *GameEconomySession) CriarPropostaTroca(_jogador2 common.Address, _minhaCarta *big.Int, _cartaDesejada *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.CriarPropostaTroca(&_GameEconomy.TransactOpts, _jogador2, _minhaCarta, _cartaDesejada)
+}
+
+// CriarPropostaTroca is a paid mutator transaction binding the contract method 0xcba5ca8b.
+//
+// Solidity: function criarPropostaTroca(address _jogador2, uint256 _minhaCarta, uint256 _cartaDesejada) returns(uint256)
+func (_GameEconomy *GameEconomyTransactorSession) CriarPropostaTroca(_jogador2 common.Address, _minhaCarta *big.Int, _cartaDesejada *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.CriarPropostaTroca(&_GameEconomy.TransactOpts, _jogador2, _minhaCarta, _cartaDesejada)
+}
+
+// DefinirPrecoPacote is a paid mutator transaction binding the contract method 0x14ac61f0.
+//
+// Solidity: function definirPrecoPacote(uint256 _novoPreco) returns()
+func (_GameEconomy *GameEconomyTransactor) DefinirPrecoPacote(opts *bind.TransactOpts, _novoPreco *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "definirPrecoPacote", _novoPreco)
+}
+
+// DefinirPrecoPacote is a paid mutator transaction binding the contract method 0x14ac61f0.
+//
+// Solidity: function definirPrecoPacote(uint256 _novoPreco) returns()
+func (_GameEconomy *GameEconomySession) DefinirPrecoPacote(_novoPreco *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.DefinirPrecoPacote(&_GameEconomy.TransactOpts, _novoPreco)
+}
+
+// DefinirPrecoPacote is a paid mutator transaction binding the contract method 0x14ac61f0.
+//
+// Solidity: function definirPrecoPacote(uint256 _novoPreco) returns()
+func (_GameEconomy *GameEconomyTransactorSession) DefinirPrecoPacote(_novoPreco *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.DefinirPrecoPacote(&_GameEconomy.TransactOpts, _novoPreco)
+}
+
+// ExpirarCompraPacote is a paid mutator transaction binding the contract method 0x95fad616.
+//
+// Solidity: function expirarCompraPacote(address _comprador) returns()
+func (_GameEconomy *GameEconomyTransactor) ExpirarCompraPacote(opts *bind.TransactOpts, _comprador common.Address) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "expirarCompraPacote", _comprador)
+}
+
+// ExpirarCompraPacote is a paid mutator transaction binding the contract method 0x95fad616.
+//
+// Solidity: function expirarCompraPacote(address _comprador) returns()
+func (_GameEconomy *GameEconomySession) ExpirarCompraPacote(_comprador common.Address) (*types.Transaction, error) {
+	return _GameEconomy.Contract.ExpirarCompraPacote(&_GameEconomy.TransactOpts, _comprador)
+}
+
+// ExpirarCompraPacote is a paid mutator transaction binding the contract method 0x95fad616.
+//
+// Solidity: function expirarCompraPacote(address _comprador) returns()
+func (_GameEconomy *GameEconomyTransactorSession) ExpirarCompraPacote(_comprador common.Address) (*types.Transaction, error) {
+	return _GameEconomy.Contract.ExpirarCompraPacote(&_GameEconomy.TransactOpts, _comprador)
+}
+
+// FundirCartas is a paid mutator transaction binding the contract method 0x55f7f13f.
+//
+// Solidity: function fundirCartas(uint256[] _cartas) returns(uint256)
+func (_GameEconomy *GameEconomyTransactor) FundirCartas(opts *bind.TransactOpts, _cartas []*big.Int) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "fundirCartas", _cartas)
+}
+
+// FundirCartas is a paid mutator transaction binding the contract method 0x55f7f13f.
+//
+// Solidity: function fundirCartas(uint256[] _cartas) returns(uint256)
+func (_GameEconomy *GameEconomySession) FundirCartas(_cartas []*big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.FundirCartas(&_GameEconomy.TransactOpts, _cartas)
+}
+
+// FundirCartas is a paid mutator transaction binding the contract method 0x55f7f13f.
+//
+// Solidity: function fundirCartas(uint256[] _cartas) returns(uint256)
+func (_GameEconomy *GameEconomyTransactorSession) FundirCartas(_cartas []*big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.FundirCartas(&_GameEconomy.TransactOpts, _cartas)
+}
+
+// RecusarPropostaTroca is a paid mutator transaction binding the contract method 0x4b071da9.
+//
+// Solidity: function recusarPropostaTroca(uint256 propostaId) returns()
+func (_GameEconomy *GameEconomyTransactor) RecusarPropostaTroca(opts *bind.TransactOpts, propostaId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "recusarPropostaTroca", propostaId)
+}
+
+// RecusarPropostaTroca is a paid mutator transaction binding the contract method 0x4b071da9.
+//
+// Solidity: function recusarPropostaTroca(uint256 propostaId) returns()
+func (_GameEconomy *GameEconomySession) RecusarPropostaTroca(propostaId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.RecusarPropostaTroca(&_GameEconomy.TransactOpts, propostaId)
+}
+
+// RecusarPropostaTroca is a paid mutator transaction binding the contract method 0x4b071da9.
+//
+// Solidity: function recusarPropostaTroca(uint256 propostaId) returns()
+func (_GameEconomy *GameEconomyTransactorSession) RecusarPropostaTroca(propostaId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.RecusarPropostaTroca(&_GameEconomy.TransactOpts, propostaId)
+}
+
+// RegistrarPartida is a paid mutator transaction binding the contract method 0xa49591dc.
+//
+// Solidity: function registrarPartida(address _jogador1, address _jogador2, address _vencedor, bytes32 _hashLog, address _servidorHost, address _servidorSombra, bytes[] _assinaturas) returns()
+func (_GameEconomy *GameEconomyTransactor) RegistrarPartida(opts *bind.TransactOpts, _jogador1 common.Address, _jogador2 common.Address, _vencedor common.Address, _hashLog [32]byte, _servidorHost common.Address, _servidorSombra common.Address, _assinaturas [][]byte) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "registrarPartida", _jogador1, _jogador2, _vencedor, _hashLog, _servidorHost, _servidorSombra, _assinaturas)
+}
+
+// RegistrarPartida is a paid mutator transaction binding the contract method 0xa49591dc.
+//
+// Solidity: function registrarPartida(address _jogador1, address _jogador2, address _vencedor, bytes32 _hashLog, address _servidorHost, address _servidorSombra, bytes[] _assinaturas) returns()
+func (_GameEconomy *GameEconomySession) RegistrarPartida(_jogador1 common.Address, _jogador2 common.Address, _vencedor common.Address, _hashLog [32]byte, _servidorHost common.Address, _servidorSombra common.Address, _assinaturas [][]byte) (*types.Transaction, error) {
+	return _GameEconomy.Contract.RegistrarPartida(&_GameEconomy.TransactOpts, _jogador1, _jogador2, _vencedor, _hashLog, _servidorHost, _servidorSombra, _assinaturas)
+}
+
+// RegistrarPartida is a paid mutator transaction binding the contract method 0xa49591dc.
+//
+// Solidity: function registrarPartida(address _jogador1, address _jogador2, address _vencedor, bytes32 _hashLog, address _servidorHost, address _servidorSombra, bytes[] _assinaturas) returns()
+func (_GameEconomy *GameEconomyTransactorSession) RegistrarPartida(_jogador1 common.Address, _jogador2 common.Address, _vencedor common.Address, _hashLog [32]byte, _servidorHost common.Address, _servidorSombra common.Address, _assinaturas [][]byte) (*types.Transaction, error) {
+	return _GameEconomy.Contract.RegistrarPartida(&_GameEconomy.TransactOpts, _jogador1, _jogador2, _vencedor, _hashLog, _servidorHost, _servidorSombra, _assinaturas)
+}
+
+// RegistrarTrocaAdmin is a paid mutator transaction binding the contract method 0x5dbabf6c.
+//
+// Solidity: function registrarTrocaAdmin(address _jogador1, address _jogador2, uint256 _cartaJogador1, uint256 _cartaJogador2) returns()
+func (_GameEconomy *GameEconomyTransactor) RegistrarTrocaAdmin(opts *bind.TransactOpts, _jogador1 common.Address, _jogador2 common.Address, _cartaJogador1 *big.Int, _cartaJogador2 *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "registrarTrocaAdmin", _jogador1, _jogador2, _cartaJogador1, _cartaJogador2)
+}
+
+// RegistrarTrocaAdmin is a paid mutator transaction binding the contract method 0x5dbabf6c.
+//
+// Solidity: function registrarTrocaAdmin(address _jogador1, address _jogador2, uint256 _cartaJogador1, uint256 _cartaJogador2) returns()
+func (_GameEconomy *GameEconomySession) RegistrarTrocaAdmin(_jogador1 common.Address, _jogador2 common.Address, _cartaJogador1 *big.Int, _cartaJogador2 *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.RegistrarTrocaAdmin(&_GameEconomy.TransactOpts, _jogador1, _jogador2, _cartaJogador1, _cartaJogador2)
+}
+
+// RegistrarTrocaAdmin is a paid mutator transaction binding the contract method 0x5dbabf6c.
+//
+// Solidity: function registrarTrocaAdmin(address _jogador1, address _jogador2, uint256 _cartaJogador1, uint256 _cartaJogador2) returns()
+func (_GameEconomy *GameEconomyTransactorSession) RegistrarTrocaAdmin(_jogador1 common.Address, _jogador2 common.Address, _cartaJogador1 *big.Int, _cartaJogador2 *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.RegistrarTrocaAdmin(&_GameEconomy.TransactOpts, _jogador1, _jogador2, _cartaJogador1, _cartaJogador2)
+}
+
+// RetirarAnuncio is a paid mutator transaction binding the contract method 0xdf9c1396.
+//
+// Solidity: function retirarAnuncio(uint256 anuncioId) returns()
+func (_GameEconomy *GameEconomyTransactor) RetirarAnuncio(opts *bind.TransactOpts, anuncioId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "retirarAnuncio", anuncioId)
+}
+
+// RetirarAnuncio is a paid mutator transaction binding the contract method 0xdf9c1396.
+//
+// Solidity: function retirarAnuncio(uint256 anuncioId) returns()
+func (_GameEconomy *GameEconomySession) RetirarAnuncio(anuncioId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.RetirarAnuncio(&_GameEconomy.TransactOpts, anuncioId)
+}
+
+// RetirarAnuncio is a paid mutator transaction binding the contract method 0xdf9c1396.
+//
+// Solidity: function retirarAnuncio(uint256 anuncioId) returns()
+func (_GameEconomy *GameEconomyTransactorSession) RetirarAnuncio(anuncioId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.RetirarAnuncio(&_GameEconomy.TransactOpts, anuncioId)
+}
+
+// RetirarFundos is a paid mutator transaction binding the contract method 0xf651bcbc.
+//
+// Solidity: function retirarFundos() returns()
+func (_GameEconomy *GameEconomyTransactor) RetirarFundos(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "retirarFundos")
+}
+
+// RetirarFundos is a paid mutator transaction binding the contract method 0xf651bcbc.
+//
+// Solidity: function retirarFundos() returns()
+func (_GameEconomy *GameEconomySession) RetirarFundos() (*types.Transaction, error) {
+	return _GameEconomy.Contract.RetirarFundos(&_GameEconomy.TransactOpts)
+}
+
+// RetirarFundos is a paid mutator transaction binding the contract method 0xf651bcbc.
+//
+// Solidity: function retirarFundos() returns()
+func (_GameEconomy *GameEconomyTransactorSession) RetirarFundos() (*types.Transaction, error) {
+	return _GameEconomy.Contract.RetirarFundos(&_GameEconomy.TransactOpts)
+}
+
+// RevelarPacote is a paid mutator transaction binding the contract method 0xc4fb8027.
+//
+// Solidity: function revelarPacote(bytes32 _segredo) returns(uint256[])
+func (_GameEconomy *GameEconomyTransactor) RevelarPacote(opts *bind.TransactOpts, _segredo [32]byte) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "revelarPacote", _segredo)
+}
+
+// RevelarPacote is a paid mutator transaction binding the contract method 0xc4fb8027.
+//
+// Solidity: function revelarPacote(bytes32 _segredo) returns(uint256[])
+func (_GameEconomy *GameEconomySession) RevelarPacote(_segredo [32]byte) (*types.Transaction, error) {
+	return _GameEconomy.Contract.RevelarPacote(&_GameEconomy.TransactOpts, _segredo)
+}
+
+// RevelarPacote is a paid mutator transaction binding the contract method 0xc4fb8027.
+//
+// Solidity: function revelarPacote(bytes32 _segredo) returns(uint256[])
+func (_GameEconomy *GameEconomyTransactorSession) RevelarPacote(_segredo [32]byte) (*types.Transaction, error) {
+	return _GameEconomy.Contract.RevelarPacote(&_GameEconomy.TransactOpts, _segredo)
+}
+
+// SacarVendas is a paid mutator transaction binding the contract method 0x75a9ec02.
+//
+// Solidity: function sacarVendas() returns()
+func (_GameEconomy *GameEconomyTransactor) SacarVendas(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "sacarVendas")
+}
+
+// SacarVendas is a paid mutator transaction binding the contract method 0x75a9ec02.
+//
+// Solidity: function sacarVendas() returns()
+func (_GameEconomy *GameEconomySession) SacarVendas() (*types.Transaction, error) {
+	return _GameEconomy.Contract.SacarVendas(&_GameEconomy.TransactOpts)
+}
+
+// SacarVendas is a paid mutator transaction binding the contract method 0x75a9ec02.
+//
+// Solidity: function sacarVendas() returns()
+func (_GameEconomy *GameEconomyTransactorSession) SacarVendas() (*types.Transaction, error) {
+	return _GameEconomy.Contract.SacarVendas(&_GameEconomy.TransactOpts)
+}
+
+// TransferirCarta is a paid mutator transaction binding the contract method 0x98cd484d.
+//
+// Solidity: function transferirCarta(address _para, uint256 tokenId) returns()
+func (_GameEconomy *GameEconomyTransactor) TransferirCarta(opts *bind.TransactOpts, _para common.Address, tokenId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.contract.Transact(opts, "transferirCarta", _para, tokenId)
+}
+
+// TransferirCarta is a paid mutator transaction binding the contract method 0x98cd484d.
+//
+// Solidity: function transferirCarta(address _para, uint256 tokenId) returns()
+func (_GameEconomy *GameEconomySession) TransferirCarta(_para common.Address, tokenId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.TransferirCarta(&_GameEconomy.TransactOpts, _para, tokenId)
+}
+
+// TransferirCarta is a paid mutator transaction binding the contract method 0x98cd484d.
+//
+// Solidity: function transferirCarta(address _para, uint256 tokenId) returns()
+func (_GameEconomy *GameEconomyTransactorSession) TransferirCarta(_para common.Address, tokenId *big.Int) (*types.Transaction, error) {
+	return _GameEconomy.Contract.TransferirCarta(&_GameEconomy.TransactOpts, _para, tokenId)
+}
+
+// GameEconomyAnuncioRetiradoIterator is returned from FilterAnuncioRetirado and is used to iterate over the raw logs and unpacked data for AnuncioRetirado events raised by the GameEconomy contract.
+type GameEconomyAnuncioRetiradoIterator struct {
+	Event *GameEconomyAnuncioRetirado // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyAnuncioRetiradoIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyAnuncioRetirado)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyAnuncioRetirado)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyAnuncioRetiradoIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyAnuncioRetiradoIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyAnuncioRetirado represents a AnuncioRetirado event raised by the GameEconomy contract.
+type GameEconomyAnuncioRetirado struct {
+	AnuncioId *big.Int
+	Vendedor  common.Address
+	CartaId   *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterAnuncioRetirado is a free log retrieval operation binding the contract event 0xca93f2f88bf5f663075e7c6d532a724181a56f8fbfbadaf87fb537965e4069ff.
+//
+// Solidity: event AnuncioRetirado(uint256 indexed anuncioId, address indexed vendedor, uint256 indexed cartaId)
+func (_GameEconomy *GameEconomyFilterer) FilterAnuncioRetirado(opts *bind.FilterOpts, anuncioId []*big.Int, vendedor []common.Address, cartaId []*big.Int) (*GameEconomyAnuncioRetiradoIterator, error) {
+
+	var anuncioIdRule []interface{}
+	for _, anuncioIdItem := range anuncioId {
+		anuncioIdRule = append(anuncioIdRule, anuncioIdItem)
+	}
+	var vendedorRule []interface{}
+	for _, vendedorItem := range vendedor {
+		vendedorRule = append(vendedorRule, vendedorItem)
+	}
+	var cartaIdRule []interface{}
+	for _, cartaIdItem := range cartaId {
+		cartaIdRule = append(cartaIdRule, cartaIdItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "AnuncioRetirado", anuncioIdRule, vendedorRule, cartaIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyAnuncioRetiradoIterator{contract: _GameEconomy.contract, event: "AnuncioRetirado", logs: logs, sub: sub}, nil
+}
+
+// WatchAnuncioRetirado is a free log subscription operation binding the contract event 0xca93f2f88bf5f663075e7c6d532a724181a56f8fbfbadaf87fb537965e4069ff.
+//
+// Solidity: event AnuncioRetirado(uint256 indexed anuncioId, address indexed vendedor, uint256 indexed cartaId)
+func (_GameEconomy *GameEconomyFilterer) WatchAnuncioRetirado(opts *bind.WatchOpts, sink chan<- *GameEconomyAnuncioRetirado, anuncioId []*big.Int, vendedor []common.Address, cartaId []*big.Int) (event.Subscription, error) {
+
+	var anuncioIdRule []interface{}
+	for _, anuncioIdItem := range anuncioId {
+		anuncioIdRule = append(anuncioIdRule, anuncioIdItem)
+	}
+	var vendedorRule []interface{}
+	for _, vendedorItem := range vendedor {
+		vendedorRule = append(vendedorRule, vendedorItem)
+	}
+	var cartaIdRule []interface{}
+	for _, cartaIdItem := range cartaId {
+		cartaIdRule = append(cartaIdRule, cartaIdItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "AnuncioRetirado", anuncioIdRule, vendedorRule, cartaIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyAnuncioRetirado)
+				if err := _GameEconomy.contract.UnpackLog(event, "AnuncioRetirado", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseAnuncioRetirado is a log parse operation binding the contract event 0xca93f2f88bf5f663075e7c6d532a724181a56f8fbfbadaf87fb537965e4069ff.
+//
+// Solidity: event AnuncioRetirado(uint256 indexed anuncioId, address indexed vendedor, uint256 indexed cartaId)
+func (_GameEconomy *GameEconomyFilterer) ParseAnuncioRetirado(log types.Log) (*GameEconomyAnuncioRetirado, error) {
+	event := new(GameEconomyAnuncioRetirado)
+	if err := _GameEconomy.contract.UnpackLog(event, "AnuncioRetirado", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyCartaAnunciadaIterator is returned from FilterCartaAnunciada and is used to iterate over the raw logs and unpacked data for CartaAnunciada events raised by the GameEconomy contract.
+type GameEconomyCartaAnunciadaIterator struct {
+	Event *GameEconomyCartaAnunciada // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyCartaAnunciadaIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyCartaAnunciada)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyCartaAnunciada)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyCartaAnunciadaIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyCartaAnunciadaIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyCartaAnunciada represents a CartaAnunciada event raised by the GameEconomy contract.
+type GameEconomyCartaAnunciada struct {
+	AnuncioId *big.Int
+	Vendedor  common.Address
+	CartaId   *big.Int
+	Preco     *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterCartaAnunciada is a free log retrieval operation binding the contract event 0x26bc5a73d1fd032df4b3060c5e83db643ac9787fbd8a97dbfb5622581dd18a1f.
+//
+// Solidity: event CartaAnunciada(uint256 indexed anuncioId, address indexed vendedor, uint256 indexed cartaId, uint256 preco)
+func (_GameEconomy *GameEconomyFilterer) FilterCartaAnunciada(opts *bind.FilterOpts, anuncioId []*big.Int, vendedor []common.Address, cartaId []*big.Int) (*GameEconomyCartaAnunciadaIterator, error) {
+
+	var anuncioIdRule []interface{}
+	for _, anuncioIdItem := range anuncioId {
+		anuncioIdRule = append(anuncioIdRule, anuncioIdItem)
+	}
+	var vendedorRule []interface{}
+	for _, vendedorItem := range vendedor {
+		vendedorRule = append(vendedorRule, vendedorItem)
+	}
+	var cartaIdRule []interface{}
+	for _, cartaIdItem := range cartaId {
+		cartaIdRule = append(cartaIdRule, cartaIdItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "CartaAnunciada", anuncioIdRule, vendedorRule, cartaIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyCartaAnunciadaIterator{contract: _GameEconomy.contract, event: "CartaAnunciada", logs: logs, sub: sub}, nil
+}
+
+// WatchCartaAnunciada is a free log subscription operation binding the contract event 0x26bc5a73d1fd032df4b3060c5e83db643ac9787fbd8a97dbfb5622581dd18a1f.
+//
+// Solidity: event CartaAnunciada(uint256 indexed anuncioId, address indexed vendedor, uint256 indexed cartaId, uint256 preco)
+func (_GameEconomy *GameEconomyFilterer) WatchCartaAnunciada(opts *bind.WatchOpts, sink chan<- *GameEconomyCartaAnunciada, anuncioId []*big.Int, vendedor []common.Address, cartaId []*big.Int) (event.Subscription, error) {
+
+	var anuncioIdRule []interface{}
+	for _, anuncioIdItem := range anuncioId {
+		anuncioIdRule = append(anuncioIdRule, anuncioIdItem)
+	}
+	var vendedorRule []interface{}
+	for _, vendedorItem := range vendedor {
+		vendedorRule = append(vendedorRule, vendedorItem)
+	}
+	var cartaIdRule []interface{}
+	for _, cartaIdItem := range cartaId {
+		cartaIdRule = append(cartaIdRule, cartaIdItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "CartaAnunciada", anuncioIdRule, vendedorRule, cartaIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyCartaAnunciada)
+				if err := _GameEconomy.contract.UnpackLog(event, "CartaAnunciada", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseCartaAnunciada is a log parse operation binding the contract event 0x26bc5a73d1fd032df4b3060c5e83db643ac9787fbd8a97dbfb5622581dd18a1f.
+//
+// Solidity: event CartaAnunciada(uint256 indexed anuncioId, address indexed vendedor, uint256 indexed cartaId, uint256 preco)
+func (_GameEconomy *GameEconomyFilterer) ParseCartaAnunciada(log types.Log) (*GameEconomyCartaAnunciada, error) {
+	event := new(GameEconomyCartaAnunciada)
+	if err := _GameEconomy.contract.UnpackLog(event, "CartaAnunciada", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyCartaCriadaIterator is returned from FilterCartaCriada and is used to iterate over the raw logs and unpacked data for CartaCriada events raised by the GameEconomy contract.
+type GameEconomyCartaCriadaIterator struct {
+	Event *GameEconomyCartaCriada // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyCartaCriadaIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyCartaCriada)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyCartaCriada)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyCartaCriadaIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyCartaCriadaIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyCartaCriada represents a CartaCriada event raised by the GameEconomy contract.
+type GameEconomyCartaCriada struct {
+	TokenId      *big.Int
+	Proprietario common.Address
+	Nome         string
+	Raridade     string
+	Valor        *big.Int
+	Raw          types.Log // Blockchain specific contextual infos
+}
+
+// FilterCartaCriada is a free log retrieval operation binding the contract event 0x5e295699131beed3bdc465c4e891fdb9dc790be12d3aa8ab60f1fa2b8b28da1b.
+//
+// Solidity: event CartaCriada(uint256 indexed tokenId, address indexed proprietario, string nome, string raridade, uint256 valor)
+func (_GameEconomy *GameEconomyFilterer) FilterCartaCriada(opts *bind.FilterOpts, tokenId []*big.Int, proprietario []common.Address) (*GameEconomyCartaCriadaIterator, error) {
+
+	var tokenIdRule []interface{}
+	for _, tokenIdItem := range tokenId {
+		tokenIdRule = append(tokenIdRule, tokenIdItem)
+	}
+	var proprietarioRule []interface{}
+	for _, proprietarioItem := range proprietario {
+		proprietarioRule = append(proprietarioRule, proprietarioItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "CartaCriada", tokenIdRule, proprietarioRule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyCartaCriadaIterator{contract: _GameEconomy.contract, event: "CartaCriada", logs: logs, sub: sub}, nil
+}
+
+// WatchCartaCriada is a free log subscription operation binding the contract event 0x5e295699131beed3bdc465c4e891fdb9dc790be12d3aa8ab60f1fa2b8b28da1b.
+//
+// Solidity: event CartaCriada(uint256 indexed tokenId, address indexed proprietario, string nome, string raridade, uint256 valor)
+func (_GameEconomy *GameEconomyFilterer) WatchCartaCriada(opts *bind.WatchOpts, sink chan<- *GameEconomyCartaCriada, tokenId []*big.Int, proprietario []common.Address) (event.Subscription, error) {
+
+	var tokenIdRule []interface{}
+	for _, tokenIdItem := range tokenId {
+		tokenIdRule = append(tokenIdRule, tokenIdItem)
+	}
+	var proprietarioRule []interface{}
+	for _, proprietarioItem := range proprietario {
+		proprietarioRule = append(proprietarioRule, proprietarioItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "CartaCriada", tokenIdRule, proprietarioRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyCartaCriada)
+				if err := _GameEconomy.contract.UnpackLog(event, "CartaCriada", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseCartaCriada is a log parse operation binding the contract event 0x5e295699131beed3bdc465c4e891fdb9dc790be12d3aa8ab60f1fa2b8b28da1b.
+//
+// Solidity: event CartaCriada(uint256 indexed tokenId, address indexed proprietario, string nome, string raridade, uint256 valor)
+func (_GameEconomy *GameEconomyFilterer) ParseCartaCriada(log types.Log) (*GameEconomyCartaCriada, error) {
+	event := new(GameEconomyCartaCriada)
+	if err := _GameEconomy.contract.UnpackLog(event, "CartaCriada", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyCartaQueimadaIterator is returned from FilterCartaQueimada and is used to iterate over the raw logs and unpacked data for CartaQueimada events raised by the GameEconomy contract.
+type GameEconomyCartaQueimadaIterator struct {
+	Event *GameEconomyCartaQueimada // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyCartaQueimadaIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyCartaQueimada)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyCartaQueimada)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyCartaQueimadaIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyCartaQueimadaIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyCartaQueimada represents a CartaQueimada event raised by the GameEconomy contract.
+type GameEconomyCartaQueimada struct {
+	TokenId      *big.Int
+	Proprietario common.Address
+	Raw          types.Log // Blockchain specific contextual infos
+}
+
+// FilterCartaQueimada is a free log retrieval operation binding the contract event 0x6e40250a2d1d644349c4ba9bfb38bc9bd8d38c347e73d8c36566879e40400492.
+//
+// Solidity: event CartaQueimada(uint256 indexed tokenId, address indexed proprietario)
+func (_GameEconomy *GameEconomyFilterer) FilterCartaQueimada(opts *bind.FilterOpts, tokenId []*big.Int, proprietario []common.Address) (*GameEconomyCartaQueimadaIterator, error) {
+
+	var tokenIdRule []interface{}
+	for _, tokenIdItem := range tokenId {
+		tokenIdRule = append(tokenIdRule, tokenIdItem)
+	}
+	var proprietarioRule []interface{}
+	for _, proprietarioItem := range proprietario {
+		proprietarioRule = append(proprietarioRule, proprietarioItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "CartaQueimada", tokenIdRule, proprietarioRule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyCartaQueimadaIterator{contract: _GameEconomy.contract, event: "CartaQueimada", logs: logs, sub: sub}, nil
+}
+
+// WatchCartaQueimada is a free log subscription operation binding the contract event 0x6e40250a2d1d644349c4ba9bfb38bc9bd8d38c347e73d8c36566879e40400492.
+//
+// Solidity: event CartaQueimada(uint256 indexed tokenId, address indexed proprietario)
+func (_GameEconomy *GameEconomyFilterer) WatchCartaQueimada(opts *bind.WatchOpts, sink chan<- *GameEconomyCartaQueimada, tokenId []*big.Int, proprietario []common.Address) (event.Subscription, error) {
+
+	var tokenIdRule []interface{}
+	for _, tokenIdItem := range tokenId {
+		tokenIdRule = append(tokenIdRule, tokenIdItem)
+	}
+	var proprietarioRule []interface{}
+	for _, proprietarioItem := range proprietario {
+		proprietarioRule = append(proprietarioRule, proprietarioItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "CartaQueimada", tokenIdRule, proprietarioRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyCartaQueimada)
+				if err := _GameEconomy.contract.UnpackLog(event, "CartaQueimada", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseCartaQueimada is a log parse operation binding the contract event 0x6e40250a2d1d644349c4ba9bfb38bc9bd8d38c347e73d8c36566879e40400492.
+//
+// Solidity: event CartaQueimada(uint256 indexed tokenId, address indexed proprietario)
+func (_GameEconomy *GameEconomyFilterer) ParseCartaQueimada(log types.Log) (*GameEconomyCartaQueimada, error) {
+	event := new(GameEconomyCartaQueimada)
+	if err := _GameEconomy.contract.UnpackLog(event, "CartaQueimada", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyCartaTransferidaIterator is returned from FilterCartaTransferida and is used to iterate over the raw logs and unpacked data for CartaTransferida events raised by the GameEconomy contract.
+type GameEconomyCartaTransferidaIterator struct {
+	Event *GameEconomyCartaTransferida // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyCartaTransferidaIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyCartaTransferida)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyCartaTransferida)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyCartaTransferidaIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyCartaTransferidaIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyCartaTransferida represents a CartaTransferida event raised by the GameEconomy contract.
+type GameEconomyCartaTransferida struct {
+	TokenId *big.Int
+	De      common.Address
+	Para    common.Address
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterCartaTransferida is a free log retrieval operation binding the contract event 0x0500118e3c25051431ae1a1ba893c78e881f535977bcc624a69a53d194c1e5f7.
+//
+// Solidity: event CartaTransferida(uint256 indexed tokenId, address indexed de, address indexed para)
+func (_GameEconomy *GameEconomyFilterer) FilterCartaTransferida(opts *bind.FilterOpts, tokenId []*big.Int, de []common.Address, para []common.Address) (*GameEconomyCartaTransferidaIterator, error) {
+
+	var tokenIdRule []interface{}
+	for _, tokenIdItem := range tokenId {
+		tokenIdRule = append(tokenIdRule, tokenIdItem)
+	}
+	var deRule []interface{}
+	for _, deItem := range de {
+		deRule = append(deRule, deItem)
+	}
+	var paraRule []interface{}
+	for _, paraItem := range para {
+		paraRule = append(paraRule, paraItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "CartaTransferida", tokenIdRule, deRule, paraRule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyCartaTransferidaIterator{contract: _GameEconomy.contract, event: "CartaTransferida", logs: logs, sub: sub}, nil
+}
+
+// WatchCartaTransferida is a free log subscription operation binding the contract event 0x0500118e3c25051431ae1a1ba893c78e881f535977bcc624a69a53d194c1e5f7.
+//
+// Solidity: event CartaTransferida(uint256 indexed tokenId, address indexed de, address indexed para)
+func (_GameEconomy *GameEconomyFilterer) WatchCartaTransferida(opts *bind.WatchOpts, sink chan<- *GameEconomyCartaTransferida, tokenId []*big.Int, de []common.Address, para []common.Address) (event.Subscription, error) {
+
+	var tokenIdRule []interface{}
+	for _, tokenIdItem := range tokenId {
+		tokenIdRule = append(tokenIdRule, tokenIdItem)
+	}
+	var deRule []interface{}
+	for _, deItem := range de {
+		deRule = append(deRule, deItem)
+	}
+	var paraRule []interface{}
+	for _, paraItem := range para {
+		paraRule = append(paraRule, paraItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "CartaTransferida", tokenIdRule, deRule, paraRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyCartaTransferida)
+				if err := _GameEconomy.contract.UnpackLog(event, "CartaTransferida", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseCartaTransferida is a log parse operation binding the contract event 0x0500118e3c25051431ae1a1ba893c78e881f535977bcc624a69a53d194c1e5f7.
+//
+// Solidity: event CartaTransferida(uint256 indexed tokenId, address indexed de, address indexed para)
+func (_GameEconomy *GameEconomyFilterer) ParseCartaTransferida(log types.Log) (*GameEconomyCartaTransferida, error) {
+	event := new(GameEconomyCartaTransferida)
+	if err := _GameEconomy.contract.UnpackLog(event, "CartaTransferida", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyCartaVendidaIterator is returned from FilterCartaVendida and is used to iterate over the raw logs and unpacked data for CartaVendida events raised by the GameEconomy contract.
+type GameEconomyCartaVendidaIterator struct {
+	Event *GameEconomyCartaVendida // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyCartaVendidaIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyCartaVendida)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyCartaVendida)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyCartaVendidaIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyCartaVendidaIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyCartaVendida represents a CartaVendida event raised by the GameEconomy contract.
+type GameEconomyCartaVendida struct {
+	AnuncioId *big.Int
+	Vendedor  common.Address
+	Comprador common.Address
+	CartaId   *big.Int
+	Preco     *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterCartaVendida is a free log retrieval operation binding the contract event 0x014da2b837bb433e166def3b8ad4c39550598b2c1aee7643c24b02a15271a515.
+//
+// Solidity: event CartaVendida(uint256 indexed anuncioId, address indexed vendedor, address indexed comprador, uint256 cartaId, uint256 preco)
+func (_GameEconomy *GameEconomyFilterer) FilterCartaVendida(opts *bind.FilterOpts, anuncioId []*big.Int, vendedor []common.Address, comprador []common.Address) (*GameEconomyCartaVendidaIterator, error) {
+
+	var anuncioIdRule []interface{}
+	for _, anuncioIdItem := range anuncioId {
+		anuncioIdRule = append(anuncioIdRule, anuncioIdItem)
+	}
+	var vendedorRule []interface{}
+	for _, vendedorItem := range vendedor {
+		vendedorRule = append(vendedorRule, vendedorItem)
+	}
+	var compradorRule []interface{}
+	for _, compradorItem := range comprador {
+		compradorRule = append(compradorRule, compradorItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "CartaVendida", anuncioIdRule, vendedorRule, compradorRule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyCartaVendidaIterator{contract: _GameEconomy.contract, event: "CartaVendida", logs: logs, sub: sub}, nil
+}
+
+// WatchCartaVendida is a free log subscription operation binding the contract event 0x014da2b837bb433e166def3b8ad4c39550598b2c1aee7643c24b02a15271a515.
+//
+// Solidity: event CartaVendida(uint256 indexed anuncioId, address indexed vendedor, address indexed comprador, uint256 cartaId, uint256 preco)
+func (_GameEconomy *GameEconomyFilterer) WatchCartaVendida(opts *bind.WatchOpts, sink chan<- *GameEconomyCartaVendida, anuncioId []*big.Int, vendedor []common.Address, comprador []common.Address) (event.Subscription, error) {
+
+	var anuncioIdRule []interface{}
+	for _, anuncioIdItem := range anuncioId {
+		anuncioIdRule = append(anuncioIdRule, anuncioIdItem)
+	}
+	var vendedorRule []interface{}
+	for _, vendedorItem := range vendedor {
+		vendedorRule = append(vendedorRule, vendedorItem)
+	}
+	var compradorRule []interface{}
+	for _, compradorItem := range comprador {
+		compradorRule = append(compradorRule, compradorItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "CartaVendida", anuncioIdRule, vendedorRule, compradorRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyCartaVendida)
+				if err := _GameEconomy.contract.UnpackLog(event, "CartaVendida", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseCartaVendida is a log parse operation binding the contract event 0x014da2b837bb433e166def3b8ad4c39550598b2c1aee7643c24b02a15271a515.
+//
+// Solidity: event CartaVendida(uint256 indexed anuncioId, address indexed vendedor, address indexed comprador, uint256 cartaId, uint256 preco)
+func (_GameEconomy *GameEconomyFilterer) ParseCartaVendida(log types.Log) (*GameEconomyCartaVendida, error) {
+	event := new(GameEconomyCartaVendida)
+	if err := _GameEconomy.contract.UnpackLog(event, "CartaVendida", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyCartasFundidasIterator is returned from FilterCartasFundidas and is used to iterate over the raw logs and unpacked data for CartasFundidas events raised by the GameEconomy contract.
+type GameEconomyCartasFundidasIterator struct {
+	Event *GameEconomyCartasFundidas // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyCartasFundidasIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyCartasFundidas)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyCartasFundidas)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyCartasFundidasIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyCartasFundidasIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyCartasFundidas represents a CartasFundidas event raised by the GameEconomy contract.
+type GameEconomyCartasFundidas struct {
+	Proprietario common.Address
+	Queimadas    []*big.Int
+	NovaCarta    *big.Int
+	Raridade     string
+	Raw          types.Log // Blockchain specific contextual infos
+}
+
+// FilterCartasFundidas is a free log retrieval operation binding the contract event 0xe34c4f669795a0456cd6bdc19146571d51bd11f15d7595656e5890465b55f5e8.
+//
+// Solidity: event CartasFundidas(address indexed proprietario, uint256[] queimadas, uint256 indexed novaCarta, string raridade)
+func (_GameEconomy *GameEconomyFilterer) FilterCartasFundidas(opts *bind.FilterOpts, proprietario []common.Address, novaCarta []*big.Int) (*GameEconomyCartasFundidasIterator, error) {
+
+	var proprietarioRule []interface{}
+	for _, proprietarioItem := range proprietario {
+		proprietarioRule = append(proprietarioRule, proprietarioItem)
+	}
+
+	var novaCartaRule []interface{}
+	for _, novaCartaItem := range novaCarta {
+		novaCartaRule = append(novaCartaRule, novaCartaItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "CartasFundidas", proprietarioRule, novaCartaRule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyCartasFundidasIterator{contract: _GameEconomy.contract, event: "CartasFundidas", logs: logs, sub: sub}, nil
+}
+
+// WatchCartasFundidas is a free log subscription operation binding the contract event 0xe34c4f669795a0456cd6bdc19146571d51bd11f15d7595656e5890465b55f5e8.
+//
+// Solidity: event CartasFundidas(address indexed proprietario, uint256[] queimadas, uint256 indexed novaCarta, string raridade)
+func (_GameEconomy *GameEconomyFilterer) WatchCartasFundidas(opts *bind.WatchOpts, sink chan<- *GameEconomyCartasFundidas, proprietario []common.Address, novaCarta []*big.Int) (event.Subscription, error) {
+
+	var proprietarioRule []interface{}
+	for _, proprietarioItem := range proprietario {
+		proprietarioRule = append(proprietarioRule, proprietarioItem)
+	}
+
+	var novaCartaRule []interface{}
+	for _, novaCartaItem := range novaCarta {
+		novaCartaRule = append(novaCartaRule, novaCartaItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "CartasFundidas", proprietarioRule, novaCartaRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyCartasFundidas)
+				if err := _GameEconomy.contract.UnpackLog(event, "CartasFundidas", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseCartasFundidas is a log parse operation binding the contract event 0xe34c4f669795a0456cd6bdc19146571d51bd11f15d7595656e5890465b55f5e8.
+//
+// Solidity: event CartasFundidas(address indexed proprietario, uint256[] queimadas, uint256 indexed novaCarta, string raridade)
+func (_GameEconomy *GameEconomyFilterer) ParseCartasFundidas(log types.Log) (*GameEconomyCartasFundidas, error) {
+	event := new(GameEconomyCartasFundidas)
+	if err := _GameEconomy.contract.UnpackLog(event, "CartasFundidas", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyCompraComprometidaIterator is returned from FilterCompraComprometida and is used to iterate over the raw logs and unpacked data for CompraComprometida events raised by the GameEconomy contract.
+type GameEconomyCompraComprometidaIterator struct {
+	Event *GameEconomyCompraComprometida // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyCompraComprometidaIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyCompraComprometida)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyCompraComprometida)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyCompraComprometidaIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyCompraComprometidaIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyCompraComprometida represents a CompraComprometida event raised by the GameEconomy contract.
+type GameEconomyCompraComprometida struct {
+	Comprador   common.Address
+	Compromisso [32]byte
+	Bloco       *big.Int
+	Raw         types.Log // Blockchain specific contextual infos
+}
+
+// FilterCompraComprometida is a free log retrieval operation binding the contract event 0x3c8c6ef0d2d048e2c41fff64b378e721174342f6bf05b7b6dd6029df97c0f239.
+//
+// Solidity: event CompraComprometida(address indexed comprador, bytes32 compromisso, uint256 bloco)
+func (_GameEconomy *GameEconomyFilterer) FilterCompraComprometida(opts *bind.FilterOpts, comprador []common.Address) (*GameEconomyCompraComprometidaIterator, error) {
+
+	var compradorRule []interface{}
+	for _, compradorItem := range comprador {
+		compradorRule = append(compradorRule, compradorItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "CompraComprometida", compradorRule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyCompraComprometidaIterator{contract: _GameEconomy.contract, event: "CompraComprometida", logs: logs, sub: sub}, nil
+}
+
+// WatchCompraComprometida is a free log subscription operation binding the contract event 0x3c8c6ef0d2d048e2c41fff64b378e721174342f6bf05b7b6dd6029df97c0f239.
+//
+// Solidity: event CompraComprometida(address indexed comprador, bytes32 compromisso, uint256 bloco)
+func (_GameEconomy *GameEconomyFilterer) WatchCompraComprometida(opts *bind.WatchOpts, sink chan<- *GameEconomyCompraComprometida, comprador []common.Address) (event.Subscription, error) {
+
+	var compradorRule []interface{}
+	for _, compradorItem := range comprador {
+		compradorRule = append(compradorRule, compradorItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "CompraComprometida", compradorRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyCompraComprometida)
+				if err := _GameEconomy.contract.UnpackLog(event, "CompraComprometida", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseCompraComprometida is a log parse operation binding the contract event 0x3c8c6ef0d2d048e2c41fff64b378e721174342f6bf05b7b6dd6029df97c0f239.
+//
+// Solidity: event CompraComprometida(address indexed comprador, bytes32 compromisso, uint256 bloco)
+func (_GameEconomy *GameEconomyFilterer) ParseCompraComprometida(log types.Log) (*GameEconomyCompraComprometida, error) {
+	event := new(GameEconomyCompraComprometida)
+	if err := _GameEconomy.contract.UnpackLog(event, "CompraComprometida", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyCompraExpiradaIterator is returned from FilterCompraExpirada and is used to iterate over the raw logs and unpacked data for CompraExpirada events raised by the GameEconomy contract.
+type GameEconomyCompraExpiradaIterator struct {
+	Event *GameEconomyCompraExpirada // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyCompraExpiradaIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyCompraExpirada)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyCompraExpirada)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyCompraExpiradaIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyCompraExpiradaIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyCompraExpirada represents a CompraExpirada event raised by the GameEconomy contract.
+type GameEconomyCompraExpirada struct {
+	Comprador common.Address
+	Bloco     *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterCompraExpirada is a free log retrieval operation binding the contract event 0x362fc9120f0a303d5433dd4d84858f8390114f5d407e8152319ed058420dfc94.
+//
+// Solidity: event CompraExpirada(address indexed comprador, uint256 bloco)
+func (_GameEconomy *GameEconomyFilterer) FilterCompraExpirada(opts *bind.FilterOpts, comprador []common.Address) (*GameEconomyCompraExpiradaIterator, error) {
+
+	var compradorRule []interface{}
+	for _, compradorItem := range comprador {
+		compradorRule = append(compradorRule, compradorItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "CompraExpirada", compradorRule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyCompraExpiradaIterator{contract: _GameEconomy.contract, event: "CompraExpirada", logs: logs, sub: sub}, nil
+}
+
+// WatchCompraExpirada is a free log subscription operation binding the contract event 0x362fc9120f0a303d5433dd4d84858f8390114f5d407e8152319ed058420dfc94.
+//
+// Solidity: event CompraExpirada(address indexed comprador, uint256 bloco)
+func (_GameEconomy *GameEconomyFilterer) WatchCompraExpirada(opts *bind.WatchOpts, sink chan<- *GameEconomyCompraExpirada, comprador []common.Address) (event.Subscription, error) {
+
+	var compradorRule []interface{}
+	for _, compradorItem := range comprador {
+		compradorRule = append(compradorRule, compradorItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "CompraExpirada", compradorRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyCompraExpirada)
+				if err := _GameEconomy.contract.UnpackLog(event, "CompraExpirada", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseCompraExpirada is a log parse operation binding the contract event 0x362fc9120f0a303d5433dd4d84858f8390114f5d407e8152319ed058420dfc94.
+//
+// Solidity: event CompraExpirada(address indexed comprador, uint256 bloco)
+func (_GameEconomy *GameEconomyFilterer) ParseCompraExpirada(log types.Log) (*GameEconomyCompraExpirada, error) {
+	event := new(GameEconomyCompraExpirada)
+	if err := _GameEconomy.contract.UnpackLog(event, "CompraExpirada", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyPacoteCompradoIterator is returned from FilterPacoteComprado and is used to iterate over the raw logs and unpacked data for PacoteComprado events raised by the GameEconomy contract.
+type GameEconomyPacoteCompradoIterator struct {
+	Event *GameEconomyPacoteComprado // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyPacoteCompradoIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyPacoteComprado)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyPacoteComprado)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyPacoteCompradoIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyPacoteCompradoIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyPacoteComprado represents a PacoteComprado event raised by the GameEconomy contract.
+type GameEconomyPacoteComprado struct {
+	Comprador common.Address
+	TokenIds  []*big.Int
+	Timestamp *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterPacoteComprado is a free log retrieval operation binding the contract event 0xf58b45d7b661f9a0f0e7c734de1c8725e0b999e8b8405964abb3e72f57520f80.
+//
+// Solidity: event PacoteComprado(address indexed comprador, uint256[] tokenIds, uint256 timestamp)
+func (_GameEconomy *GameEconomyFilterer) FilterPacoteComprado(opts *bind.FilterOpts, comprador []common.Address) (*GameEconomyPacoteCompradoIterator, error) {
+
+	var compradorRule []interface{}
+	for _, compradorItem := range comprador {
+		compradorRule = append(compradorRule, compradorItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "PacoteComprado", compradorRule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyPacoteCompradoIterator{contract: _GameEconomy.contract, event: "PacoteComprado", logs: logs, sub: sub}, nil
+}
+
+// WatchPacoteComprado is a free log subscription operation binding the contract event 0xf58b45d7b661f9a0f0e7c734de1c8725e0b999e8b8405964abb3e72f57520f80.
+//
+// Solidity: event PacoteComprado(address indexed comprador, uint256[] tokenIds, uint256 timestamp)
+func (_GameEconomy *GameEconomyFilterer) WatchPacoteComprado(opts *bind.WatchOpts, sink chan<- *GameEconomyPacoteComprado, comprador []common.Address) (event.Subscription, error) {
+
+	var compradorRule []interface{}
+	for _, compradorItem := range comprador {
+		compradorRule = append(compradorRule, compradorItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "PacoteComprado", compradorRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyPacoteComprado)
+				if err := _GameEconomy.contract.UnpackLog(event, "PacoteComprado", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParsePacoteComprado is a log parse operation binding the contract event 0xf58b45d7b661f9a0f0e7c734de1c8725e0b999e8b8405964abb3e72f57520f80.
+//
+// Solidity: event PacoteComprado(address indexed comprador, uint256[] tokenIds, uint256 timestamp)
+func (_GameEconomy *GameEconomyFilterer) ParsePacoteComprado(log types.Log) (*GameEconomyPacoteComprado, error) {
+	event := new(GameEconomyPacoteComprado)
+	if err := _GameEconomy.contract.UnpackLog(event, "PacoteComprado", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyPartidaRegistradaIterator is returned from FilterPartidaRegistrada and is used to iterate over the raw logs and unpacked data for PartidaRegistrada events raised by the GameEconomy contract.
+type GameEconomyPartidaRegistradaIterator struct {
+	Event *GameEconomyPartidaRegistrada // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyPartidaRegistradaIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyPartidaRegistrada)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyPartidaRegistrada)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyPartidaRegistradaIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyPartidaRegistradaIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyPartidaRegistrada represents a PartidaRegistrada event raised by the GameEconomy contract.
+type GameEconomyPartidaRegistrada struct {
+	Jogador1  common.Address
+	Jogador2  common.Address
+	Vencedor  common.Address
+	Timestamp *big.Int
+	HashLog   [32]byte
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterPartidaRegistrada is a free log retrieval operation binding the contract event 0x83a4a74eaf8a8e5a2de91b5474234a59fab72d01cd65e8a4474098bf640bcf92.
+//
+// Solidity: event PartidaRegistrada(address indexed jogador1, address indexed jogador2, address indexed vencedor, uint256 timestamp, bytes32 hashLog)
+func (_GameEconomy *GameEconomyFilterer) FilterPartidaRegistrada(opts *bind.FilterOpts, jogador1 []common.Address, jogador2 []common.Address, vencedor []common.Address) (*GameEconomyPartidaRegistradaIterator, error) {
+
+	var jogador1Rule []interface{}
+	for _, jogador1Item := range jogador1 {
+		jogador1Rule = append(jogador1Rule, jogador1Item)
+	}
+	var jogador2Rule []interface{}
+	for _, jogador2Item := range jogador2 {
+		jogador2Rule = append(jogador2Rule, jogador2Item)
+	}
+	var vencedorRule []interface{}
+	for _, vencedorItem := range vencedor {
+		vencedorRule = append(vencedorRule, vencedorItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "PartidaRegistrada", jogador1Rule, jogador2Rule, vencedorRule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyPartidaRegistradaIterator{contract: _GameEconomy.contract, event: "PartidaRegistrada", logs: logs, sub: sub}, nil
+}
+
+// WatchPartidaRegistrada is a free log subscription operation binding the contract event 0x83a4a74eaf8a8e5a2de91b5474234a59fab72d01cd65e8a4474098bf640bcf92.
+//
+// Solidity: event PartidaRegistrada(address indexed jogador1, address indexed jogador2, address indexed vencedor, uint256 timestamp, bytes32 hashLog)
+func (_GameEconomy *GameEconomyFilterer) WatchPartidaRegistrada(opts *bind.WatchOpts, sink chan<- *GameEconomyPartidaRegistrada, jogador1 []common.Address, jogador2 []common.Address, vencedor []common.Address) (event.Subscription, error) {
+
+	var jogador1Rule []interface{}
+	for _, jogador1Item := range jogador1 {
+		jogador1Rule = append(jogador1Rule, jogador1Item)
+	}
+	var jogador2Rule []interface{}
+	for _, jogador2Item := range jogador2 {
+		jogador2Rule = append(jogador2Rule, jogador2Item)
+	}
+	var vencedorRule []interface{}
+	for _, vencedorItem := range vencedor {
+		vencedorRule = append(vencedorRule, vencedorItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "PartidaRegistrada", jogador1Rule, jogador2Rule, vencedorRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyPartidaRegistrada)
+				if err := _GameEconomy.contract.UnpackLog(event, "PartidaRegistrada", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParsePartidaRegistrada is a log parse operation binding the contract event 0x83a4a74eaf8a8e5a2de91b5474234a59fab72d01cd65e8a4474098bf640bcf92.
+//
+// Solidity: event PartidaRegistrada(address indexed jogador1, address indexed jogador2, address indexed vencedor, uint256 timestamp, bytes32 hashLog)
+func (_GameEconomy *GameEconomyFilterer) ParsePartidaRegistrada(log types.Log) (*GameEconomyPartidaRegistrada, error) {
+	event := new(GameEconomyPartidaRegistrada)
+	if err := _GameEconomy.contract.UnpackLog(event, "PartidaRegistrada", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyPropostaTrocaCanceladaIterator is returned from FilterPropostaTrocaCancelada and is used to iterate over the raw logs and unpacked data for PropostaTrocaCancelada events raised by the GameEconomy contract.
+type GameEconomyPropostaTrocaCanceladaIterator struct {
+	Event *GameEconomyPropostaTrocaCancelada // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyPropostaTrocaCanceladaIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyPropostaTrocaCancelada)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyPropostaTrocaCancelada)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyPropostaTrocaCanceladaIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyPropostaTrocaCanceladaIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyPropostaTrocaCancelada represents a PropostaTrocaCancelada event raised by the GameEconomy contract.
+type GameEconomyPropostaTrocaCancelada struct {
+	PropostaId *big.Int
+	Jogador1   common.Address
+	Jogador2   common.Address
+	Raw        types.Log // Blockchain specific contextual infos
+}
+
+// FilterPropostaTrocaCancelada is a free log retrieval operation binding the contract event 0x910baa9a3ca144f4c6c387162c058a9f74e14e683915ffe33a46a9213a176be3.
+//
+// Solidity: event PropostaTrocaCancelada(uint256 indexed propostaId, address indexed jogador1, address indexed jogador2)
+func (_GameEconomy *GameEconomyFilterer) FilterPropostaTrocaCancelada(opts *bind.FilterOpts, propostaId []*big.Int, jogador1 []common.Address, jogador2 []common.Address) (*GameEconomyPropostaTrocaCanceladaIterator, error) {
+
+	var propostaIdRule []interface{}
+	for _, propostaIdItem := range propostaId {
+		propostaIdRule = append(propostaIdRule, propostaIdItem)
+	}
+	var jogador1Rule []interface{}
+	for _, jogador1Item := range jogador1 {
+		jogador1Rule = append(jogador1Rule, jogador1Item)
+	}
+	var jogador2Rule []interface{}
+	for _, jogador2Item := range jogador2 {
+		jogador2Rule = append(jogador2Rule, jogador2Item)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "PropostaTrocaCancelada", propostaIdRule, jogador1Rule, jogador2Rule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyPropostaTrocaCanceladaIterator{contract: _GameEconomy.contract, event: "PropostaTrocaCancelada", logs: logs, sub: sub}, nil
+}
+
+// WatchPropostaTrocaCancelada is a free log subscription operation binding the contract event 0x910baa9a3ca144f4c6c387162c058a9f74e14e683915ffe33a46a9213a176be3.
+//
+// Solidity: event PropostaTrocaCancelada(uint256 indexed propostaId, address indexed jogador1, address indexed jogador2)
+func (_GameEconomy *GameEconomyFilterer) WatchPropostaTrocaCancelada(opts *bind.WatchOpts, sink chan<- *GameEconomyPropostaTrocaCancelada, propostaId []*big.Int, jogador1 []common.Address, jogador2 []common.Address) (event.Subscription, error) {
+
+	var propostaIdRule []interface{}
+	for _, propostaIdItem := range propostaId {
+		propostaIdRule = append(propostaIdRule, propostaIdItem)
+	}
+	var jogador1Rule []interface{}
+	for _, jogador1Item := range jogador1 {
+		jogador1Rule = append(jogador1Rule, jogador1Item)
+	}
+	var jogador2Rule []interface{}
+	for _, jogador2Item := range jogador2 {
+		jogador2Rule = append(jogador2Rule, jogador2Item)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "PropostaTrocaCancelada", propostaIdRule, jogador1Rule, jogador2Rule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyPropostaTrocaCancelada)
+				if err := _GameEconomy.contract.UnpackLog(event, "PropostaTrocaCancelada", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParsePropostaTrocaCancelada is a log parse operation binding the contract event 0x910baa9a3ca144f4c6c387162c058a9f74e14e683915ffe33a46a9213a176be3.
+//
+// Solidity: event PropostaTrocaCancelada(uint256 indexed propostaId, address indexed jogador1, address indexed jogador2)
+func (_GameEconomy *GameEconomyFilterer) ParsePropostaTrocaCancelada(log types.Log) (*GameEconomyPropostaTrocaCancelada, error) {
+	event := new(GameEconomyPropostaTrocaCancelada)
+	if err := _GameEconomy.contract.UnpackLog(event, "PropostaTrocaCancelada", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyPropostaTrocaCriadaIterator is returned from FilterPropostaTrocaCriada and is used to iterate over the raw logs and unpacked data for PropostaTrocaCriada events raised by the GameEconomy contract.
+type GameEconomyPropostaTrocaCriadaIterator struct {
+	Event *GameEconomyPropostaTrocaCriada // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyPropostaTrocaCriadaIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyPropostaTrocaCriada)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyPropostaTrocaCriada)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyPropostaTrocaCriadaIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyPropostaTrocaCriadaIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyPropostaTrocaCriada represents a PropostaTrocaCriada event raised by the GameEconomy contract.
+type GameEconomyPropostaTrocaCriada struct {
+	PropostaId    *big.Int
+	Jogador1      common.Address
+	Jogador2      common.Address
+	CartaJogador1 *big.Int
+	CartaJogador2 *big.Int
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterPropostaTrocaCriada is a free log retrieval operation binding the contract event 0x6ef90ab6f0f3945a2022e02420b8115030d04eb05939152ee8157c0d8cfb1a9b.
+//
+// Solidity: event PropostaTrocaCriada(uint256 indexed propostaId, address indexed jogador1, address indexed jogador2, uint256 cartaJogador1, uint256 cartaJogador2)
+func (_GameEconomy *GameEconomyFilterer) FilterPropostaTrocaCriada(opts *bind.FilterOpts, propostaId []*big.Int, jogador1 []common.Address, jogador2 []common.Address) (*GameEconomyPropostaTrocaCriadaIterator, error) {
+
+	var propostaIdRule []interface{}
+	for _, propostaIdItem := range propostaId {
+		propostaIdRule = append(propostaIdRule, propostaIdItem)
+	}
+	var jogador1Rule []interface{}
+	for _, jogador1Item := range jogador1 {
+		jogador1Rule = append(jogador1Rule, jogador1Item)
+	}
+	var jogador2Rule []interface{}
+	for _, jogador2Item := range jogador2 {
+		jogador2Rule = append(jogador2Rule, jogador2Item)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "PropostaTrocaCriada", propostaIdRule, jogador1Rule, jogador2Rule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyPropostaTrocaCriadaIterator{contract: _GameEconomy.contract, event: "PropostaTrocaCriada", logs: logs, sub: sub}, nil
+}
+
+// WatchPropostaTrocaCriada is a free log subscription operation binding the contract event 0x6ef90ab6f0f3945a2022e02420b8115030d04eb05939152ee8157c0d8cfb1a9b.
+//
+// Solidity: event PropostaTrocaCriada(uint256 indexed propostaId, address indexed jogador1, address indexed jogador2, uint256 cartaJogador1, uint256 cartaJogador2)
+func (_GameEconomy *GameEconomyFilterer) WatchPropostaTrocaCriada(opts *bind.WatchOpts, sink chan<- *GameEconomyPropostaTrocaCriada, propostaId []*big.Int, jogador1 []common.Address, jogador2 []common.Address) (event.Subscription, error) {
+
+	var propostaIdRule []interface{}
+	for _, propostaIdItem := range propostaId {
+		propostaIdRule = append(propostaIdRule, propostaIdItem)
+	}
+	var jogador1Rule []interface{}
+	for _, jogador1Item := range jogador1 {
+		jogador1Rule = append(jogador1Rule, jogador1Item)
+	}
+	var jogador2Rule []interface{}
+	for _, jogador2Item := range jogador2 {
+		jogador2Rule = append(jogador2Rule, jogador2Item)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "PropostaTrocaCriada", propostaIdRule, jogador1Rule, jogador2Rule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyPropostaTrocaCriada)
+				if err := _GameEconomy.contract.UnpackLog(event, "PropostaTrocaCriada", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParsePropostaTrocaCriada is a log parse operation binding the contract event 0x6ef90ab6f0f3945a2022e02420b8115030d04eb05939152ee8157c0d8cfb1a9b.
+//
+// Solidity: event PropostaTrocaCriada(uint256 indexed propostaId, address indexed jogador1, address indexed jogador2, uint256 cartaJogador1, uint256 cartaJogador2)
+func (_GameEconomy *GameEconomyFilterer) ParsePropostaTrocaCriada(log types.Log) (*GameEconomyPropostaTrocaCriada, error) {
+	event := new(GameEconomyPropostaTrocaCriada)
+	if err := _GameEconomy.contract.UnpackLog(event, "PropostaTrocaCriada", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyPropostaTrocaRecusadaIterator is returned from FilterPropostaTrocaRecusada and is used to iterate over the raw logs and unpacked data for PropostaTrocaRecusada events raised by the GameEconomy contract.
+type GameEconomyPropostaTrocaRecusadaIterator struct {
+	Event *GameEconomyPropostaTrocaRecusada // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyPropostaTrocaRecusadaIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyPropostaTrocaRecusada)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyPropostaTrocaRecusada)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyPropostaTrocaRecusadaIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyPropostaTrocaRecusadaIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyPropostaTrocaRecusada represents a PropostaTrocaRecusada event raised by the GameEconomy contract.
+type GameEconomyPropostaTrocaRecusada struct {
+	PropostaId *big.Int
+	Jogador1   common.Address
+	Jogador2   common.Address
+	Raw        types.Log // Blockchain specific contextual infos
+}
+
+// FilterPropostaTrocaRecusada is a free log retrieval operation binding the contract event 0xfa2eda418dc7fd239e8508952baa4f3b389c341484affd653fb9c21f2f7f5f4e.
+//
+// Solidity: event PropostaTrocaRecusada(uint256 indexed propostaId, address indexed jogador1, address indexed jogador2)
+func (_GameEconomy *GameEconomyFilterer) FilterPropostaTrocaRecusada(opts *bind.FilterOpts, propostaId []*big.Int, jogador1 []common.Address, jogador2 []common.Address) (*GameEconomyPropostaTrocaRecusadaIterator, error) {
+
+	var propostaIdRule []interface{}
+	for _, propostaIdItem := range propostaId {
+		propostaIdRule = append(propostaIdRule, propostaIdItem)
+	}
+	var jogador1Rule []interface{}
+	for _, jogador1Item := range jogador1 {
+		jogador1Rule = append(jogador1Rule, jogador1Item)
+	}
+	var jogador2Rule []interface{}
+	for _, jogador2Item := range jogador2 {
+		jogador2Rule = append(jogador2Rule, jogador2Item)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "PropostaTrocaRecusada", propostaIdRule, jogador1Rule, jogador2Rule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyPropostaTrocaRecusadaIterator{contract: _GameEconomy.contract, event: "PropostaTrocaRecusada", logs: logs, sub: sub}, nil
+}
+
+// WatchPropostaTrocaRecusada is a free log subscription operation binding the contract event 0xfa2eda418dc7fd239e8508952baa4f3b389c341484affd653fb9c21f2f7f5f4e.
+//
+// Solidity: event PropostaTrocaRecusada(uint256 indexed propostaId, address indexed jogador1, address indexed jogador2)
+func (_GameEconomy *GameEconomyFilterer) WatchPropostaTrocaRecusada(opts *bind.WatchOpts, sink chan<- *GameEconomyPropostaTrocaRecusada, propostaId []*big.Int, jogador1 []common.Address, jogador2 []common.Address) (event.Subscription, error) {
+
+	var propostaIdRule []interface{}
+	for _, propostaIdItem := range propostaId {
+		propostaIdRule = append(propostaIdRule, propostaIdItem)
+	}
+	var jogador1Rule []interface{}
+	for _, jogador1Item := range jogador1 {
+		jogador1Rule = append(jogador1Rule, jogador1Item)
+	}
+	var jogador2Rule []interface{}
+	for _, jogador2Item := range jogador2 {
+		jogador2Rule = append(jogador2Rule, jogador2Item)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "PropostaTrocaRecusada", propostaIdRule, jogador1Rule, jogador2Rule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyPropostaTrocaRecusada)
+				if err := _GameEconomy.contract.UnpackLog(event, "PropostaTrocaRecusada", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParsePropostaTrocaRecusada is a log parse operation binding the contract event 0xfa2eda418dc7fd239e8508952baa4f3b389c341484affd653fb9c21f2f7f5f4e.
+//
+// Solidity: event PropostaTrocaRecusada(uint256 indexed propostaId, address indexed jogador1, address indexed jogador2)
+func (_GameEconomy *GameEconomyFilterer) ParsePropostaTrocaRecusada(log types.Log) (*GameEconomyPropostaTrocaRecusada, error) {
+	event := new(GameEconomyPropostaTrocaRecusada)
+	if err := _GameEconomy.contract.UnpackLog(event, "PropostaTrocaRecusada", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyServidorAutorizadoIterator is returned from FilterServidorAutorizado and is used to iterate over the raw logs and unpacked data for ServidorAutorizado events raised by the GameEconomy contract.
+type GameEconomyServidorAutorizadoIterator struct {
+	Event *GameEconomyServidorAutorizado // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyServidorAutorizadoIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyServidorAutorizado)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyServidorAutorizado)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyServidorAutorizadoIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyServidorAutorizadoIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyServidorAutorizado represents a ServidorAutorizado event raised by the GameEconomy contract.
+type GameEconomyServidorAutorizado struct {
+	Servidor   common.Address
+	Autorizado bool
+	Raw        types.Log // Blockchain specific contextual infos
+}
+
+// FilterServidorAutorizado is a free log retrieval operation binding the contract event 0x1129c1fc3519a3c3d011ea3d0b7d0dee794ddfbf7d23708ba30178a4eb176c51.
+//
+// Solidity: event ServidorAutorizado(address indexed servidor, bool autorizado)
+func (_GameEconomy *GameEconomyFilterer) FilterServidorAutorizado(opts *bind.FilterOpts, servidor []common.Address) (*GameEconomyServidorAutorizadoIterator, error) {
+
+	var servidorRule []interface{}
+	for _, servidorItem := range servidor {
+		servidorRule = append(servidorRule, servidorItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "ServidorAutorizado", servidorRule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyServidorAutorizadoIterator{contract: _GameEconomy.contract, event: "ServidorAutorizado", logs: logs, sub: sub}, nil
+}
+
+// WatchServidorAutorizado is a free log subscription operation binding the contract event 0x1129c1fc3519a3c3d011ea3d0b7d0dee794ddfbf7d23708ba30178a4eb176c51.
+//
+// Solidity: event ServidorAutorizado(address indexed servidor, bool autorizado)
+func (_GameEconomy *GameEconomyFilterer) WatchServidorAutorizado(opts *bind.WatchOpts, sink chan<- *GameEconomyServidorAutorizado, servidor []common.Address) (event.Subscription, error) {
+
+	var servidorRule []interface{}
+	for _, servidorItem := range servidor {
+		servidorRule = append(servidorRule, servidorItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "ServidorAutorizado", servidorRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyServidorAutorizado)
+				if err := _GameEconomy.contract.UnpackLog(event, "ServidorAutorizado", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseServidorAutorizado is a log parse operation binding the contract event 0x1129c1fc3519a3c3d011ea3d0b7d0dee794ddfbf7d23708ba30178a4eb176c51.
+//
+// Solidity: event ServidorAutorizado(address indexed servidor, bool autorizado)
+func (_GameEconomy *GameEconomyFilterer) ParseServidorAutorizado(log types.Log) (*GameEconomyServidorAutorizado, error) {
+	event := new(GameEconomyServidorAutorizado)
+	if err := _GameEconomy.contract.UnpackLog(event, "ServidorAutorizado", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyTrocaExecutadaIterator is returned from FilterTrocaExecutada and is used to iterate over the raw logs and unpacked data for TrocaExecutada events raised by the GameEconomy contract.
+type GameEconomyTrocaExecutadaIterator struct {
+	Event *GameEconomyTrocaExecutada // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyTrocaExecutadaIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyTrocaExecutada)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyTrocaExecutada)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyTrocaExecutadaIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyTrocaExecutadaIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyTrocaExecutada represents a TrocaExecutada event raised by the GameEconomy contract.
+type GameEconomyTrocaExecutada struct {
+	PropostaId    *big.Int
+	Jogador1      common.Address
+	Jogador2      common.Address
+	CartaJogador1 *big.Int
+	CartaJogador2 *big.Int
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterTrocaExecutada is a free log retrieval operation binding the contract event 0x5495a4bb69557efe1bbe80aa1e863cd5bea9c24a8860faa9d5b9c7b8b305dc60.
+//
+// Solidity: event TrocaExecutada(uint256 indexed propostaId, address indexed jogador1, address indexed jogador2, uint256 cartaJogador1, uint256 cartaJogador2)
+func (_GameEconomy *GameEconomyFilterer) FilterTrocaExecutada(opts *bind.FilterOpts, propostaId []*big.Int, jogador1 []common.Address, jogador2 []common.Address) (*GameEconomyTrocaExecutadaIterator, error) {
+
+	var propostaIdRule []interface{}
+	for _, propostaIdItem := range propostaId {
+		propostaIdRule = append(propostaIdRule, propostaIdItem)
+	}
+	var jogador1Rule []interface{}
+	for _, jogador1Item := range jogador1 {
+		jogador1Rule = append(jogador1Rule, jogador1Item)
+	}
+	var jogador2Rule []interface{}
+	for _, jogador2Item := range jogador2 {
+		jogador2Rule = append(jogador2Rule, jogador2Item)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "TrocaExecutada", propostaIdRule, jogador1Rule, jogador2Rule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyTrocaExecutadaIterator{contract: _GameEconomy.contract, event: "TrocaExecutada", logs: logs, sub: sub}, nil
+}
+
+// WatchTrocaExecutada is a free log subscription operation binding the contract event 0x5495a4bb69557efe1bbe80aa1e863cd5bea9c24a8860faa9d5b9c7b8b305dc60.
+//
+// Solidity: event TrocaExecutada(uint256 indexed propostaId, address indexed jogador1, address indexed jogador2, uint256 cartaJogador1, uint256 cartaJogador2)
+func (_GameEconomy *GameEconomyFilterer) WatchTrocaExecutada(opts *bind.WatchOpts, sink chan<- *GameEconomyTrocaExecutada, propostaId []*big.Int, jogador1 []common.Address, jogador2 []common.Address) (event.Subscription, error) {
+
+	var propostaIdRule []interface{}
+	for _, propostaIdItem := range propostaId {
+		propostaIdRule = append(propostaIdRule, propostaIdItem)
+	}
+	var jogador1Rule []interface{}
+	for _, jogador1Item := range jogador1 {
+		jogador1Rule = append(jogador1Rule, jogador1Item)
+	}
+	var jogador2Rule []interface{}
+	for _, jogador2Item := range jogador2 {
+		jogador2Rule = append(jogador2Rule, jogador2Item)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "TrocaExecutada", propostaIdRule, jogador1Rule, jogador2Rule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyTrocaExecutada)
+				if err := _GameEconomy.contract.UnpackLog(event, "TrocaExecutada", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseTrocaExecutada is a log parse operation binding the contract event 0x5495a4bb69557efe1bbe80aa1e863cd5bea9c24a8860faa9d5b9c7b8b305dc60.
+//
+// Solidity: event TrocaExecutada(uint256 indexed propostaId, address indexed jogador1, address indexed jogador2, uint256 cartaJogador1, uint256 cartaJogador2)
+func (_GameEconomy *GameEconomyFilterer) ParseTrocaExecutada(log types.Log) (*GameEconomyTrocaExecutada, error) {
+	event := new(GameEconomyTrocaExecutada)
+	if err := _GameEconomy.contract.UnpackLog(event, "TrocaExecutada", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// GameEconomyVendasSacadasIterator is returned from FilterVendasSacadas and is used to iterate over the raw logs and unpacked data for VendasSacadas events raised by the GameEconomy contract.
+type GameEconomyVendasSacadasIterator struct {
+	Event *GameEconomyVendasSacadas // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *GameEconomyVendasSacadasIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(GameEconomyVendasSacadas)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(GameEconomyVendasSacadas)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *GameEconomyVendasSacadasIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *GameEconomyVendasSacadasIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// GameEconomyVendasSacadas represents a VendasSacadas event raised by the GameEconomy contract.
+type GameEconomyVendasSacadas struct {
+	Vendedor common.Address
+	Valor    *big.Int
+	Raw      types.Log // Blockchain specific contextual infos
+}
+
+// FilterVendasSacadas is a free log retrieval operation binding the contract event 0x78a40354f9a9cb80eddaab56242ac4c2dc6441d910a113078c58c4ab84847820.
+//
+// Solidity: event VendasSacadas(address indexed vendedor, uint256 valor)
+func (_GameEconomy *GameEconomyFilterer) FilterVendasSacadas(opts *bind.FilterOpts, vendedor []common.Address) (*GameEconomyVendasSacadasIterator, error) {
+
+	var vendedorRule []interface{}
+	for _, vendedorItem := range vendedor {
+		vendedorRule = append(vendedorRule, vendedorItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.FilterLogs(opts, "VendasSacadas", vendedorRule)
+	if err != nil {
+		return nil, err
+	}
+	return &GameEconomyVendasSacadasIterator{contract: _GameEconomy.contract, event: "VendasSacadas", logs: logs, sub: sub}, nil
+}
+
+// WatchVendasSacadas is a free log subscription operation binding the contract event 0x78a40354f9a9cb80eddaab56242ac4c2dc6441d910a113078c58c4ab84847820.
+//
+// Solidity: event VendasSacadas(address indexed vendedor, uint256 valor)
+func (_GameEconomy *GameEconomyFilterer) WatchVendasSacadas(opts *bind.WatchOpts, sink chan<- *GameEconomyVendasSacadas, vendedor []common.Address) (event.Subscription, error) {
+
+	var vendedorRule []interface{}
+	for _, vendedorItem := range vendedor {
+		vendedorRule = append(vendedorRule, vendedorItem)
+	}
+
+	logs, sub, err := _GameEconomy.contract.WatchLogs(opts, "VendasSacadas", vendedorRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(GameEconomyVendasSacadas)
+				if err := _GameEconomy.contract.UnpackLog(event, "VendasSacadas", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseVendasSacadas is a log parse operation binding the contract event 0x78a40354f9a9cb80eddaab56242ac4c2dc6441d910a113078c58c4ab84847820.
+//
+// Solidity: event VendasSacadas(address indexed vendedor, uint256 valor)
+func (_GameEconomy *GameEconomyFilterer) ParseVendasSacadas(log types.Log) (*GameEconomyVendasSacadas, error) {
+	event := new(GameEconomyVendasSacadas)
+	if err := _GameEconomy.contract.UnpackLog(event, "VendasSacadas", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
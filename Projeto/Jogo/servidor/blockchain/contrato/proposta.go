@@ -0,0 +1,39 @@
+package contrato
+
+import (
+	"math/big"
+	"time"
+)
+
+// Estados de uma PropostaTroca, como o contrato os decide em aceitarPropostaTroca
+const (
+	PropostaPendente  = "pendente"
+	PropostaExecutada = "executada"
+	PropostaCancelada = "cancelada"
+	PropostaRecusada  = "recusada"
+	PropostaExpirada  = "expirada"
+)
+
+// Estado devolve o estado da proposta no instante agora. O contrato compara expiraEm com o
+// timestamp do bloco, então perto do prazo o relógio local pode divergir por alguns segundos.
+func (p PropostaTroca) Estado(agora time.Time) string {
+	switch {
+	case p.Executada:
+		return PropostaExecutada
+	case p.Cancelada:
+		return PropostaCancelada
+	case p.Recusada:
+		return PropostaRecusada
+	case p.ExpiraEm == nil || p.ExpiraEm.Cmp(big.NewInt(agora.Unix())) < 0:
+		return PropostaExpirada
+	}
+	return PropostaPendente
+}
+
+// Expiracao é o instante a partir do qual a proposta não pode mais ser aceita
+func (p PropostaTroca) Expiracao() time.Time {
+	if p.ExpiraEm == nil {
+		return time.Time{}
+	}
+	return time.Unix(p.ExpiraEm.Int64(), 0)
+}
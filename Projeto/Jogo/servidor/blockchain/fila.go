@@ -0,0 +1,640 @@
+package blockchain
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// Sem recibo nesse prazo, a transação é reenviada com o mesmo nonce e gas price maior
+	PrazoSubstituicao = 20 * time.Second
+	// Aumento do gas price em cada substituição (o nó só aceita substituições com pelo menos 10%)
+	AumentoGasPercentual = 20
+	// Máximo de substituições de uma mesma transação; depois disso o nonce é cancelado
+	MaxSubstituicoes = 5
+	// Intervalo em que acompanharPendentes confere as transações sem recibo
+	IntervaloAcompanhamento = 5 * time.Second
+	// Gas de uma transferência sem dados, usada para cancelar um nonce
+	GasCancelamento = 21000
+)
+
+// TransacaoPendente é uma transação enviada e ainda sem recibo. As substituições mantêm o nonce
+// e acumulam em Hashes todas as versões enviadas, já que qualquer uma delas pode ser minerada.
+type TransacaoPendente struct {
+	De        common.Address `json:"de"`
+	Nonce     uint64         `json:"nonce"`
+	Para      common.Address `json:"para"`
+	Valor     *big.Int       `json:"valor"`
+	Dados     hexutil.Bytes  `json:"dados"`
+	Gas       uint64         `json:"gas"`
+	PrecoGas  *big.Int       `json:"preco_gas"`
+	Hashes    []common.Hash  `json:"hashes"`
+	EnviadaEm time.Time      `json:"enviada_em"`
+	// Índice em Hashes da primeira versão de cancelamento (0 = não cancelada): a partir dela, as
+	// versões são transferências vazias para o próprio remetente, só para liberar o nonce
+	CanceladaEm int `json:"cancelada_em,omitempty"`
+}
+
+func (p *TransacaoPendente) chave() string {
+	return fmt.Sprintf("%s/%d", p.De.Hex(), p.Nonce)
+}
+
+// cancelamento indica se o hash é de uma versão de cancelamento da transação.
+func (p *TransacaoPendente) cancelamento(h common.Hash) bool {
+	if p.CanceladaEm == 0 {
+		return false
+	}
+	for _, cancelada := range p.Hashes[p.CanceladaEm:] {
+		if cancelada == h {
+			return true
+		}
+	}
+	return false
+}
+
+// registroTransacao é uma linha do diário: ENVIADA, SUBSTITUIDA, CONFIRMADA ou DESCARTADA.
+type registroTransacao struct {
+	Tipo      string            `json:"tipo"`
+	Transacao TransacaoPendente `json:"transacao"`
+}
+
+// filaRemetente serializa os envios de um remetente e controla o próximo nonce localmente,
+// sem consultar PendingNonceAt a cada envio.
+type filaRemetente struct {
+	mutex        sync.Mutex
+	proximoNonce uint64
+	sincronizada bool
+}
+
+// filaTransacoes guarda as filas por remetente, as transações pendentes e o diário em disco.
+type filaTransacoes struct {
+	mutex     sync.Mutex
+	filas     map[common.Address]*filaRemetente
+	pendentes map[string]*TransacaoPendente      // "remetente/nonce" -> transação
+	porHash   map[common.Hash]*TransacaoPendente // Qualquer hash já devolvido para a transação
+	diario    *os.File                           // nil enquanto o diário não for configurado
+
+	acompanhando bool // acompanharPendentes em execução
+}
+
+func novaFilaTransacoes() *filaTransacoes {
+	return &filaTransacoes{
+		filas:     make(map[common.Address]*filaRemetente),
+		pendentes: make(map[string]*TransacaoPendente),
+		porHash:   make(map[common.Hash]*TransacaoPendente),
+	}
+}
+
+func (f *filaTransacoes) remetente(de common.Address) *filaRemetente {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	fila, ok := f.filas[de]
+	if !ok {
+		fila = &filaRemetente{}
+		f.filas[de] = fila
+	}
+	return fila
+}
+
+// registrar guarda a transação (e os hashes pelos quais ela pode ser procurada) e grava no diário.
+func (f *filaTransacoes) registrar(tipo string, p *TransacaoPendente, hashes ...common.Hash) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, h := range hashes {
+		f.porHash[h] = p
+	}
+	switch tipo {
+	case "CONFIRMADA", "DESCARTADA":
+		delete(f.pendentes, p.chave())
+		for h, pendente := range f.porHash {
+			if pendente.chave() == p.chave() {
+				delete(f.porHash, h)
+			}
+		}
+	default:
+		f.pendentes[p.chave()] = p
+	}
+	f.gravar(tipo, p)
+}
+
+// gravar assume que f.mutex já está bloqueado.
+func (f *filaTransacoes) gravar(tipo string, p *TransacaoPendente) {
+	if f.diario == nil {
+		return
+	}
+	linha, err := json.Marshal(registroTransacao{Tipo: tipo, Transacao: *p})
+	if err == nil {
+		_, err = f.diario.Write(append(linha, '\n'))
+	}
+	if err == nil {
+		err = f.diario.Sync()
+	}
+	if err != nil {
+		log.Printf("[BLOCKCHAIN_ERRO] Falha ao gravar o diário de transações: %v", err)
+	}
+}
+
+// porHashCopia retorna uma cópia da transação pendente que tem esse hash.
+func (f *filaTransacoes) porHashCopia(h common.Hash) (*TransacaoPendente, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	p, ok := f.porHash[h]
+	if !ok {
+		return nil, false
+	}
+	return copiar(p), true
+}
+
+// copiaAtual retorna uma cópia da transação pendente com a chave dada, ou nil se ela já foi concluída.
+func (f *filaTransacoes) copiaAtual(chave string) *TransacaoPendente {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	p, ok := f.pendentes[chave]
+	if !ok {
+		return nil
+	}
+	return copiar(p)
+}
+
+// copiar assume que f.mutex já está bloqueado (ou que p não está na fila).
+func copiar(p *TransacaoPendente) *TransacaoPendente {
+	copia := *p
+	copia.Hashes = append([]common.Hash(nil), p.Hashes...)
+	return &copia
+}
+
+// atual retorna a transação pendente com a chave dada, ou nil se ela já foi concluída.
+func (f *filaTransacoes) atual(chave string) *TransacaoPendente {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.pendentes[chave]
+}
+
+// maiorNoncePendente retorna o maior nonce pendente do remetente no diário.
+func (f *filaTransacoes) maiorNoncePendente(de common.Address) (uint64, bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var maior uint64
+	achou := false
+	for _, p := range f.pendentes {
+		if p.De == de && (!achou || p.Nonce > maior) {
+			maior = p.Nonce
+			achou = true
+		}
+	}
+	return maior, achou
+}
+
+// listarPendentes retorna as chaves das transações pendentes.
+func (f *filaTransacoes) listarPendentes() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	lista := make([]string, 0, len(f.pendentes))
+	for chave := range f.pendentes {
+		lista = append(lista, chave)
+	}
+	return lista
+}
+
+// UsarDiarioTransacoes grava as transações pendentes em caminho e retoma as que ficaram sem
+// confirmação na execução anterior. O diário é compactado na abertura (só as pendentes ficam).
+func (m *Manager) UsarDiarioTransacoes(caminho string) error {
+	if err := os.MkdirAll(filepath.Dir(caminho), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de dados: %v", err)
+	}
+	if err := m.fila.carregarDiario(caminho); err != nil {
+		return err
+	}
+
+	temporario := caminho + ".tmp"
+	arquivo, err := os.Create(temporario)
+	if err != nil {
+		return fmt.Errorf("erro ao compactar o diário de transações: %v", err)
+	}
+	m.fila.mutex.Lock()
+	m.fila.diario = arquivo
+	for _, p := range m.fila.pendentes {
+		m.fila.gravar("ENVIADA", p)
+	}
+	m.fila.diario = nil
+	m.fila.mutex.Unlock()
+	arquivo.Close()
+	if err := os.Rename(temporario, caminho); err != nil {
+		return fmt.Errorf("erro ao compactar o diário de transações: %v", err)
+	}
+
+	diario, err := os.OpenFile(caminho, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir o diário de transações: %v", err)
+	}
+	m.fila.mutex.Lock()
+	m.fila.diario = diario
+	pendentes := len(m.fila.pendentes)
+	m.fila.mutex.Unlock()
+
+	log.Printf("[BLOCKCHAIN] Diário de transações em %s (%d pendentes)", caminho, pendentes)
+	if pendentes > 0 {
+		go m.retomarPendentes()
+	}
+	return nil
+}
+
+func (f *filaTransacoes) carregarDiario(caminho string) error {
+	arquivo, err := os.Open(caminho)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao ler o diário de transações: %v", err)
+	}
+	defer arquivo.Close()
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	scanner := bufio.NewScanner(arquivo)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	linha := 0
+	for scanner.Scan() {
+		linha++
+		var reg registroTransacao
+		if err := json.Unmarshal(scanner.Bytes(), &reg); err != nil {
+			log.Printf("[BLOCKCHAIN_AVISO] Linha %d ignorada em %s: %v", linha, caminho, err)
+			continue
+		}
+		p := reg.Transacao
+		if reg.Tipo == "CONFIRMADA" || reg.Tipo == "DESCARTADA" {
+			delete(f.pendentes, p.chave())
+			continue
+		}
+		f.pendentes[p.chave()] = &p
+	}
+	for _, p := range f.pendentes {
+		for _, h := range p.Hashes {
+			f.porHash[h] = p
+		}
+	}
+	return scanner.Err()
+}
+
+// retomarPendentes confere as transações do diário: as mineradas são concluídas, as que perderam
+// o nonce para outra transação são descartadas e as demais são reenviadas e acompanhadas.
+func (m *Manager) retomarPendentes() {
+	for _, chave := range m.fila.listarPendentes() {
+		if m.conferirPendente(chave) {
+			log.Printf("[BLOCKCHAIN] Retomando transação pendente %s", chave)
+			if err := m.substituir(chave); err != nil {
+				log.Printf("[BLOCKCHAIN_ERRO] Falha ao reenviar %s: %v", chave, err)
+			}
+		}
+	}
+	m.iniciarAcompanhamento()
+}
+
+// iniciarAcompanhamento garante um acompanharPendentes em execução.
+func (m *Manager) iniciarAcompanhamento() {
+	m.fila.mutex.Lock()
+	defer m.fila.mutex.Unlock()
+	if m.fila.acompanhando || len(m.fila.pendentes) == 0 {
+		return
+	}
+	m.fila.acompanhando = true
+	go m.acompanharPendentes()
+}
+
+// acompanharPendentes segue as transações sem recibo, inclusive depois que aguardarConfirmacao
+// desiste de esperar: um nonce travado impede que as transações seguintes do remetente sejam
+// mineradas. Sem recibo em PrazoSubstituicao, a transação é substituída com gas price maior; depois
+// de MaxSubstituicoes, o nonce é cancelado (cancelar). Termina quando não há mais pendentes.
+func (m *Manager) acompanharPendentes() {
+	ticker := time.NewTicker(IntervaloAcompanhamento)
+	defer ticker.Stop()
+	for range ticker.C {
+		for _, chave := range m.fila.listarPendentes() {
+			if !m.conferirPendente(chave) {
+				continue
+			}
+			p := m.fila.copiaAtual(chave)
+			if p == nil || time.Since(p.EnviadaEm) <= PrazoSubstituicao {
+				continue
+			}
+			var err error
+			if p.CanceladaEm == 0 && len(p.Hashes) > MaxSubstituicoes {
+				err = m.cancelar(chave)
+			} else {
+				err = m.substituir(chave)
+			}
+			if err != nil {
+				log.Printf("[BLOCKCHAIN_ERRO] Transação %s continua travada: %v", chave, err)
+			}
+		}
+
+		m.fila.mutex.Lock()
+		if len(m.fila.pendentes) == 0 {
+			m.fila.acompanhando = false
+			m.fila.mutex.Unlock()
+			return
+		}
+		m.fila.mutex.Unlock()
+	}
+}
+
+// conferirPendente conclui a transação se uma das versões foi minerada e a descarta se o nonce foi
+// usado por outra transação. Retorna true se ela continua pendente.
+func (m *Manager) conferirPendente(chave string) bool {
+	p := m.fila.copiaAtual(chave)
+	if p == nil {
+		return false
+	}
+	if recibo := m.buscarRecibo(p.Hashes); recibo != nil {
+		if p.cancelamento(recibo.TxHash) {
+			log.Printf("[BLOCKCHAIN_AVISO] Transação %s cancelada: o nonce foi liberado sem executar a chamada", chave)
+		}
+		m.concluir("CONFIRMADA", chave)
+		m.marcarBloco(recibo.BlockNumber.Uint64())
+		return false
+	}
+	nonceMinerado, err := m.client.NonceAt(context.Background(), p.De, nil)
+	if err != nil {
+		log.Printf("[BLOCKCHAIN_ERRO] Não foi possível conferir a transação %s: %v", chave, err)
+		return true
+	}
+	if nonceMinerado > p.Nonce {
+		// A transação pode ter sido minerada entre as duas consultas
+		if recibo := m.buscarRecibo(p.Hashes); recibo != nil {
+			m.concluir("CONFIRMADA", chave)
+			m.marcarBloco(recibo.BlockNumber.Uint64())
+			return false
+		}
+		log.Printf("[BLOCKCHAIN_AVISO] Nonce %d de %s já foi usado por outra transação; descartando %s", p.Nonce, p.De.Hex(), chave)
+		m.concluir("DESCARTADA", chave)
+		return false
+	}
+	return true
+}
+
+// concluir registra CONFIRMADA ou DESCARTADA para a transação pendente com a chave dada.
+func (m *Manager) concluir(tipo, chave string) {
+	if atual := m.fila.atual(chave); atual != nil {
+		m.fila.registrar(tipo, atual)
+	}
+}
+
+// cancelar libera o nonce de uma transação que não foi minerada nem com MaxSubstituicoes: envia no
+// mesmo nonce uma transferência vazia para o próprio remetente, com gas price maior. As versões de
+// cancelamento seguem sendo substituídas até o limite de 2*MaxSubstituicoes.
+func (m *Manager) cancelar(chave string) error {
+	atual := m.fila.atual(chave)
+	if atual == nil {
+		return nil
+	}
+	fila := m.fila.remetente(atual.De)
+	fila.mutex.Lock()
+	defer fila.mutex.Unlock()
+
+	m.fila.mutex.Lock()
+	p := copiar(atual)
+	m.fila.mutex.Unlock()
+
+	p.Para = p.De
+	p.Valor = big.NewInt(0)
+	p.Dados = nil
+	p.Gas = GasCancelamento
+	p.PrecoGas = aumentarPreco(p.PrecoGas)
+	if sugerido, err := m.client.SuggestGasPrice(context.Background()); err == nil && sugerido.Cmp(p.PrecoGas) > 0 {
+		p.PrecoGas = sugerido
+	}
+	tx, hash, err := m.transmitir(p)
+	if err != nil {
+		if erroNonceBaixo(err) {
+			// Uma das versões já foi minerada; o recibo aparece na próxima consulta
+			return nil
+		}
+		return err
+	}
+	log.Printf("[BLOCKCHAIN_AVISO] Transação %s sem recibo após %d substituições; nonce cancelado com %s", chave, MaxSubstituicoes, hash.Hex())
+
+	m.fila.mutex.Lock()
+	atual.CanceladaEm = len(atual.Hashes)
+	atual.Para = p.Para
+	atual.Valor = p.Valor
+	atual.Dados = p.Dados
+	atual.Gas = p.Gas
+	atual.PrecoGas = p.PrecoGas
+	atual.Hashes = append(atual.Hashes, hash)
+	atual.EnviadaEm = time.Now()
+	m.fila.mutex.Unlock()
+	m.fila.registrar("SUBSTITUIDA", atual, hash, tx.Hash())
+	return nil
+}
+
+// enfileirarTransacao envia a transação com o próximo nonce do remetente. Se o envio falhar, o
+// nonce não é consumido; se o nonce já estiver ocupado no pool, a transação é reenviada com gas
+// price maior (substituição).
+func (m *Manager) enfileirarTransacao(from common.Address, data []byte, valor *big.Int, gas uint64, gasPrice *big.Int) (*types.Transaction, error) {
+	fila := m.fila.remetente(from)
+	fila.mutex.Lock()
+	defer fila.mutex.Unlock()
+
+	if err := m.sincronizarNonce(from, fila); err != nil {
+		return nil, err
+	}
+	p := &TransacaoPendente{
+		De:       from,
+		Nonce:    fila.proximoNonce,
+		Para:     m.contractAddress,
+		Valor:    valor,
+		Dados:    data,
+		Gas:      gas,
+		PrecoGas: gasPrice,
+	}
+
+	tx, hash, err := m.transmitir(p)
+	for tentativa := 0; err != nil && tentativa < MaxSubstituicoes; tentativa++ {
+		switch {
+		case erroNonceBaixo(err):
+			// O nonce foi usado fora desta fila (ex: mesma conta em outro processo)
+			fila.sincronizada = false
+			if errSync := m.sincronizarNonce(from, fila); errSync != nil {
+				return nil, errSync
+			}
+			p.Nonce = fila.proximoNonce
+		case erroSubstituicao(err):
+			p.PrecoGas = aumentarPreco(p.PrecoGas)
+		default:
+			return nil, err
+		}
+		tx, hash, err = m.transmitir(p)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fila.proximoNonce = p.Nonce + 1
+	p.Hashes = []common.Hash{hash}
+	p.EnviadaEm = time.Now()
+	m.fila.registrar("ENVIADA", p, hash, tx.Hash())
+	m.iniciarAcompanhamento()
+	return tx, nil
+}
+
+// sincronizarNonce lê o nonce do nó na primeira vez (ou após um erro de nonce) e o combina com
+// o diário, para não reutilizar nonces de transações pendentes que o nó ainda não conhece.
+func (m *Manager) sincronizarNonce(from common.Address, fila *filaRemetente) error {
+	if fila.sincronizada {
+		return nil
+	}
+	nonce, err := m.client.PendingNonceAt(context.Background(), from)
+	if err != nil {
+		return fmt.Errorf("erro ao obter nonce: %v", err)
+	}
+	if maior, ok := m.fila.maiorNoncePendente(from); ok && maior+1 > nonce {
+		nonce = maior + 1
+	}
+	fila.proximoNonce = nonce
+	fila.sincronizada = true
+	return nil
+}
+
+// substituir reenvia uma transação pendente com o mesmo nonce e gas price maior.
+func (m *Manager) substituir(chave string) error {
+	atual := m.fila.atual(chave)
+	if atual == nil {
+		return nil
+	}
+	fila := m.fila.remetente(atual.De)
+	fila.mutex.Lock()
+	defer fila.mutex.Unlock()
+
+	m.fila.mutex.Lock()
+	p := *copiar(atual)
+	m.fila.mutex.Unlock()
+	if time.Since(p.EnviadaEm) <= PrazoSubstituicao {
+		return nil // Já substituída por outra chamada
+	}
+	limite := MaxSubstituicoes
+	if p.CanceladaEm > 0 {
+		limite = 2 * MaxSubstituicoes
+	}
+	if len(p.Hashes) > limite {
+		return fmt.Errorf("limite de %d substituições atingido", limite)
+	}
+
+	p.PrecoGas = aumentarPreco(p.PrecoGas)
+	if sugerido, err := m.client.SuggestGasPrice(context.Background()); err == nil && sugerido.Cmp(p.PrecoGas) > 0 {
+		p.PrecoGas = sugerido
+	}
+	tx, hash, err := m.transmitir(&p)
+	if err != nil {
+		if erroNonceBaixo(err) {
+			// Uma das versões já foi minerada; o recibo aparece na próxima consulta
+			return nil
+		}
+		return err
+	}
+	log.Printf("[BLOCKCHAIN] Transação %s substituída: %s (gas price %s)", chave, hash.Hex(), p.PrecoGas)
+
+	m.fila.mutex.Lock()
+	atual.PrecoGas = p.PrecoGas
+	atual.Hashes = append(atual.Hashes, hash)
+	atual.EnviadaEm = time.Now()
+	m.fila.mutex.Unlock()
+	m.fila.registrar("SUBSTITUIDA", atual, hash, tx.Hash())
+	return nil
+}
+
+// transmitir envia a transação com os parâmetros de p. Retorna também o hash informado pelo nó,
+// que no envio via personal_sendTransaction pode diferir do hash de tx.
+func (m *Manager) transmitir(p *TransacaoPendente) (*types.Transaction, common.Hash, error) {
+	tx := types.NewTransaction(p.Nonce, p.Para, p.Valor, p.Gas, p.PrecoGas, p.Dados)
+
+	// Se a chave da conta for conhecida (servidor ou AdicionarChave), assina localmente
+	if chave, ok := m.chaves[p.De]; ok {
+		chainID, err := m.client.ChainID(context.Background())
+		if err != nil {
+			return nil, common.Hash{}, fmt.Errorf("erro ao obter chain ID: %v", err)
+		}
+		txAssinada, err := types.SignTx(tx, types.NewEIP155Signer(chainID), chave)
+		if err != nil {
+			return nil, common.Hash{}, fmt.Errorf("erro ao assinar transação: %v", err)
+		}
+		if err := m.client.SendTransaction(context.Background(), txAssinada); err != nil {
+			return nil, common.Hash{}, fmt.Errorf("erro ao enviar transação: %v", err)
+		}
+		return txAssinada, txAssinada.Hash(), nil
+	}
+
+	// Para outras contas, usa personal_sendTransaction (requer que a conta esteja desbloqueada)
+	if m.rpcClient == nil {
+		return nil, common.Hash{}, fmt.Errorf("não foi possível enviar transação: conta não é do servidor e RPC não disponível")
+	}
+	var unlockResult bool
+	err := m.rpcClient.Call(&unlockResult, "personal_unlockAccount", p.De, m.serverPassword, 0)
+	if err != nil || !unlockResult {
+		return nil, common.Hash{}, fmt.Errorf("erro ao desbloquear conta: %v", err)
+	}
+
+	txParams := map[string]interface{}{
+		"from":     p.De.Hex(),
+		"to":       p.Para.Hex(),
+		"value":    fmt.Sprintf("0x%x", p.Valor),
+		"data":     fmt.Sprintf("0x%x", []byte(p.Dados)),
+		"gas":      fmt.Sprintf("0x%x", p.Gas),
+		"gasPrice": fmt.Sprintf("0x%x", p.PrecoGas),
+		"nonce":    fmt.Sprintf("0x%x", p.Nonce),
+	}
+	var txHashStr string
+	if err := m.rpcClient.Call(&txHashStr, "personal_sendTransaction", txParams, m.serverPassword); err != nil {
+		return nil, common.Hash{}, fmt.Errorf("erro ao enviar transação via RPC: %v", err)
+	}
+	txHash := common.HexToHash(txHashStr)
+
+	// Aguarda um pouco e tenta obter a transação
+	time.Sleep(1 * time.Second)
+	if enviada, _, err := m.client.TransactionByHash(context.Background(), txHash); err == nil {
+		tx = enviada
+	}
+	return tx, txHash, nil
+}
+
+// buscarRecibo retorna o recibo da primeira versão minerada, se houver.
+func (m *Manager) buscarRecibo(hashes []common.Hash) *types.Receipt {
+	for _, h := range hashes {
+		if recibo, err := m.client.TransactionReceipt(context.Background(), h); err == nil {
+			return recibo
+		}
+	}
+	return nil
+}
+
+func aumentarPreco(preco *big.Int) *big.Int {
+	novo := new(big.Int).Mul(preco, big.NewInt(100+AumentoGasPercentual))
+	novo.Div(novo, big.NewInt(100))
+	if novo.Cmp(preco) <= 0 {
+		novo.Add(preco, big.NewInt(1))
+	}
+	return novo
+}
+
+func erroNonceBaixo(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
+func erroSubstituicao(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "replacement transaction underpriced") ||
+		strings.Contains(msg, "already known") ||
+		strings.Contains(msg, "known transaction")
+}
@@ -0,0 +1,270 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// backendFalso responde só ao que enfileirarTransacao usa. PendingNonceAt devolve 'nonces' em
+// ordem (repetindo o último) e SendTransaction devolve 'erros' em ordem (nil depois deles).
+type backendFalso struct {
+	Backend
+	mutex    sync.Mutex
+	nonces   []uint64
+	erros    []error
+	enviadas []*types.Transaction
+}
+
+func (b *backendFalso) PendingNonceAt(ctx context.Context, conta common.Address) (uint64, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	nonce := b.nonces[0]
+	if len(b.nonces) > 1 {
+		b.nonces = b.nonces[1:]
+	}
+	return nonce, nil
+}
+
+func (b *backendFalso) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if len(b.erros) > 0 {
+		err := b.erros[0]
+		b.erros = b.erros[1:]
+		if err != nil {
+			return err
+		}
+	}
+	b.enviadas = append(b.enviadas, tx)
+	return nil
+}
+
+func (b *backendFalso) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1337), nil
+}
+
+// managerFalso é um Manager que assina localmente pela conta devolvida
+func managerFalso(t *testing.T, backend *backendFalso) (*Manager, common.Address) {
+	t.Helper()
+	chave, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conta := crypto.PubkeyToAddress(chave.PublicKey)
+	m := &Manager{
+		client: backend,
+		chaves: map[common.Address]*ecdsa.PrivateKey{conta: chave},
+		fila:   novaFilaTransacoes(),
+	}
+	m.fila.acompanhando = true // Sem acompanharPendentes: o backend falso não tem recibos
+	return m, conta
+}
+
+func TestEnfileirarTransacao(t *testing.T) {
+	errNonceBaixo := errors.New("nonce too low")
+	errSubstituicao := errors.New("replacement transaction underpriced")
+	errNo := errors.New("insufficient funds for gas * price + value")
+
+	type envio struct {
+		nonce    uint64 // nonce esperado da transação enviada
+		precoGas int64  // gas price esperado
+		erro     bool
+	}
+	casos := []struct {
+		nome      string
+		nonces    []uint64 // respostas de PendingNonceAt
+		erros     []error  // respostas de SendTransaction
+		pendentes []uint64 // nonces já pendentes no diário
+		envios    []envio
+	}{
+		{
+			nome:   "envios seguidos recebem nonces consecutivos",
+			nonces: []uint64{5},
+			envios: []envio{{5, 100, false}, {6, 100, false}, {7, 100, false}},
+		},
+		{
+			nome:   "nonce usado fora da fila ressincroniza",
+			nonces: []uint64{5, 8},
+			erros:  []error{errNonceBaixo},
+			envios: []envio{{8, 100, false}, {9, 100, false}},
+		},
+		{
+			nome:   "nonce ocupado no pool sobe o gas price",
+			nonces: []uint64{3},
+			erros:  []error{errSubstituicao, errSubstituicao},
+			envios: []envio{{3, 144, false}, {4, 100, false}},
+		},
+		{
+			nome:   "falha no envio não consome o nonce",
+			nonces: []uint64{2},
+			erros:  []error{errNo},
+			envios: []envio{{0, 0, true}, {2, 100, false}},
+		},
+		{
+			nome:      "pendentes do diário à frente do nó",
+			nonces:    []uint64{7},
+			pendentes: []uint64{7, 9},
+			envios:    []envio{{10, 100, false}},
+		},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			backend := &backendFalso{nonces: c.nonces, erros: c.erros}
+			m, conta := managerFalso(t, backend)
+			for _, nonce := range c.pendentes {
+				m.fila.pendentes[(&TransacaoPendente{De: conta, Nonce: nonce}).chave()] = &TransacaoPendente{De: conta, Nonce: nonce}
+			}
+
+			for i, e := range c.envios {
+				tx, err := m.enfileirarTransacao(conta, []byte{1}, big.NewInt(0), 21000, big.NewInt(100))
+				if (err != nil) != e.erro {
+					t.Fatalf("envio %d: erro %v, esperado erro=%v", i, err, e.erro)
+				}
+				if e.erro {
+					continue
+				}
+				if tx.Nonce() != e.nonce || tx.GasPrice().Int64() != e.precoGas {
+					t.Errorf("envio %d: nonce %d e gas price %s, esperado %d e %d", i, tx.Nonce(), tx.GasPrice(), e.nonce, e.precoGas)
+				}
+				pendente := m.fila.copiaAtual((&TransacaoPendente{De: conta, Nonce: tx.Nonce()}).chave())
+				if pendente == nil || len(pendente.Hashes) != 1 || pendente.Hashes[0] != tx.Hash() {
+					t.Errorf("envio %d: transação não registrada como pendente: %+v", i, pendente)
+				}
+			}
+		})
+	}
+}
+
+func TestDiarioTransacoes(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "transacoes.jsonl")
+	diario, err := os.Create(caminho)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conta := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	transacao := func(nonce uint64, hashes ...common.Hash) *TransacaoPendente {
+		return &TransacaoPendente{De: conta, Nonce: nonce, Valor: big.NewInt(0), PrecoGas: big.NewInt(1), Hashes: hashes}
+	}
+	h := func(n byte) common.Hash { return common.BytesToHash([]byte{n}) }
+
+	f := novaFilaTransacoes()
+	f.diario = diario
+	registros := []struct {
+		tipo      string
+		transacao *TransacaoPendente
+	}{
+		{"ENVIADA", transacao(1, h(1))},
+		{"ENVIADA", transacao(2, h(2))},
+		{"ENVIADA", transacao(3, h(3))},
+		{"SUBSTITUIDA", transacao(2, h(2), h(22))},
+		{"CONFIRMADA", transacao(1, h(1))},
+		{"DESCARTADA", transacao(3, h(3))},
+	}
+	for _, r := range registros {
+		f.registrar(r.tipo, r.transacao, r.transacao.Hashes...)
+	}
+	diario.Close()
+	// Uma linha truncada no fim (queda no meio da escrita) é ignorada
+	arquivo, _ := os.OpenFile(caminho, os.O_APPEND|os.O_WRONLY, 0644)
+	arquivo.WriteString(`{"tipo":"ENVIADA","transacao":{"de":`)
+	arquivo.Close()
+
+	recarregada := novaFilaTransacoes()
+	if err := recarregada.carregarDiario(caminho); err != nil {
+		t.Fatalf("carregarDiario: %v", err)
+	}
+	casos := []struct {
+		nome     string
+		hash     common.Hash
+		pendente bool
+		nonce    uint64
+	}{
+		{"confirmada", h(1), false, 0},
+		{"substituída pela versão original", h(2), true, 2},
+		{"substituída pela versão nova", h(22), true, 2},
+		{"descartada", h(3), false, 0},
+	}
+	for _, c := range casos {
+		p, ok := recarregada.porHashCopia(c.hash)
+		if ok != c.pendente {
+			t.Errorf("%s: pendente=%v, esperado %v", c.nome, ok, c.pendente)
+			continue
+		}
+		if ok && p.Nonce != c.nonce {
+			t.Errorf("%s: nonce %d, esperado %d", c.nome, p.Nonce, c.nonce)
+		}
+	}
+	if maior, ok := recarregada.maiorNoncePendente(conta); !ok || maior != 2 {
+		t.Errorf("maiorNoncePendente = %d, %v; esperado 2", maior, ok)
+	}
+}
+
+func TestCancelamento(t *testing.T) {
+	h := func(n byte) common.Hash { return common.BytesToHash([]byte{n}) }
+	p := &TransacaoPendente{Hashes: []common.Hash{h(1), h(2), h(3), h(4)}, CanceladaEm: 2}
+	casos := []struct {
+		hash         common.Hash
+		cancelamento bool
+	}{
+		{h(1), false},
+		{h(2), false},
+		{h(3), true},
+		{h(4), true},
+		{h(9), false},
+	}
+	for _, c := range casos {
+		if cancelamento := p.cancelamento(c.hash); cancelamento != c.cancelamento {
+			t.Errorf("cancelamento(%s) = %v, esperado %v", c.hash.Hex(), cancelamento, c.cancelamento)
+		}
+	}
+	if (&TransacaoPendente{Hashes: []common.Hash{h(1)}}).cancelamento(h(1)) {
+		t.Error("transação não cancelada reconhecida como cancelamento")
+	}
+}
+
+func TestAumentarPreco(t *testing.T) {
+	casos := []struct {
+		preco, novo int64
+	}{
+		{100, 120},
+		{1000000000, 1200000000},
+		{1, 2}, // 20% de 1 arredonda para baixo: o aumento mínimo é 1
+		{0, 1},
+	}
+	for _, c := range casos {
+		if novo := aumentarPreco(big.NewInt(c.preco)); novo.Int64() != c.novo {
+			t.Errorf("aumentarPreco(%d) = %s, esperado %d", c.preco, novo, c.novo)
+		}
+	}
+}
+
+func TestClassificarErros(t *testing.T) {
+	casos := []struct {
+		mensagem     string
+		nonceBaixo   bool
+		substituicao bool
+	}{
+		{"nonce too low: next nonce 7, tx nonce 5", true, false},
+		{"Nonce Too Low", true, false},
+		{"replacement transaction underpriced", false, true},
+		{"already known", false, true},
+		{"known transaction: 0xabc", false, true},
+		{"insufficient funds for gas * price + value", false, false},
+	}
+	for _, c := range casos {
+		err := errors.New(c.mensagem)
+		if erroNonceBaixo(err) != c.nonceBaixo || erroSubstituicao(err) != c.substituicao {
+			t.Errorf("%q: nonceBaixo=%v substituicao=%v, esperado %v e %v", c.mensagem, erroNonceBaixo(err), erroSubstituicao(err), c.nonceBaixo, c.substituicao)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"jogodistribuido/servidor/blockchain/contrato"
+	"jogodistribuido/servidor/tipos"
+)
+
+// FundirCartas queima as cartas do dono (CARTAS_POR_FUSAO de mesmo nome e raridade) e retorna a
+// carta da raridade seguinte criada no lugar delas
+func (m *Manager) FundirCartas(dono common.Address, cartaIDs []*big.Int) (tipos.Carta, error) {
+	data, err := contrato.DadosFundirCartas(cartaIDs)
+	if err != nil {
+		return tipos.Carta{}, fmt.Errorf("erro ao preparar chamada: %v", err)
+	}
+
+	tx, err := m.enviarTransacao(dono, data, big.NewInt(0))
+	if err != nil {
+		return tipos.Carta{}, fmt.Errorf("erro ao enviar transação: %w", err)
+	}
+	receipt, err := m.confirmarTransacao(dono, data, big.NewInt(0), tx)
+	if err != nil {
+		return tipos.Carta{}, err
+	}
+
+	// Lê o evento CartasFundidas para obter o ID da nova carta
+	filtro, err := contrato.NewGameEconomyFilterer(m.contractAddress, nil)
+	if err != nil {
+		return tipos.Carta{}, fmt.Errorf("erro ao criar bindings do contrato: %v", err)
+	}
+	for _, vLog := range receipt.Logs {
+		if vLog.Address != m.contractAddress {
+			continue
+		}
+		if evento, err := filtro.ParseCartasFundidas(*vLog); err == nil {
+			return m.ObterCarta(evento.NovaCarta)
+		}
+	}
+	return tipos.Carta{}, fmt.Errorf("carta criada na fusão não encontrada nos logs")
+}
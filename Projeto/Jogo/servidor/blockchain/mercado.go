@@ -0,0 +1,139 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"jogodistribuido/servidor/blockchain/contrato"
+	"jogodistribuido/servidor/tipos"
+)
+
+// AnunciarCarta coloca a carta do vendedor à venda por 'preco' wei e retorna o ID do anúncio.
+// A carta passa à custódia do contrato até ser vendida ou retirada.
+func (m *Manager) AnunciarCarta(vendedor common.Address, cartaID, preco *big.Int) (*big.Int, error) {
+	data, err := contrato.DadosAnunciarCarta(cartaID, preco)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao preparar chamada: %v", err)
+	}
+
+	tx, err := m.enviarTransacao(vendedor, data, big.NewInt(0))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao enviar transação: %w", err)
+	}
+	receipt, err := m.confirmarTransacao(vendedor, data, big.NewInt(0), tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lê o evento CartaAnunciada para obter o ID do anúncio
+	filtro, err := contrato.NewGameEconomyFilterer(m.contractAddress, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar bindings do contrato: %v", err)
+	}
+	for _, vLog := range receipt.Logs {
+		if vLog.Address != m.contractAddress {
+			continue
+		}
+		if evento, err := filtro.ParseCartaAnunciada(*vLog); err == nil {
+			return evento.AnuncioId, nil
+		}
+	}
+	return nil, fmt.Errorf("id do anúncio não encontrado nos logs")
+}
+
+// ComprarCartaAnunciada compra a carta do anúncio pagando o preço anunciado
+func (m *Manager) ComprarCartaAnunciada(comprador common.Address, anuncioID *big.Int) error {
+	anuncio, err := m.leitor.ObterAnuncio(m.opcoesLeitura(), anuncioID)
+	if err != nil {
+		return fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	if !anuncio.Ativo {
+		return contrato.ErrAnuncioInativo
+	}
+
+	data, err := contrato.DadosComprarCartaAnunciada(anuncioID)
+	if err != nil {
+		return fmt.Errorf("erro ao preparar chamada: %v", err)
+	}
+	tx, err := m.enviarTransacao(comprador, data, anuncio.Preco)
+	if err != nil {
+		return fmt.Errorf("erro ao enviar transação: %w", err)
+	}
+	_, err = m.confirmarTransacao(comprador, data, anuncio.Preco, tx)
+	return err
+}
+
+// RetirarAnuncio encerra um anúncio ativo e devolve a carta ao vendedor
+func (m *Manager) RetirarAnuncio(vendedor common.Address, anuncioID *big.Int) error {
+	data, err := contrato.DadosRetirarAnuncio(anuncioID)
+	if err != nil {
+		return fmt.Errorf("erro ao preparar chamada: %v", err)
+	}
+	return m.executarSemValor(vendedor, data)
+}
+
+// SacarVendas transfere ao vendedor o valor acumulado das vendas dele
+func (m *Manager) SacarVendas(vendedor common.Address) error {
+	data, err := contrato.DadosSacarVendas()
+	if err != nil {
+		return fmt.Errorf("erro ao preparar chamada: %v", err)
+	}
+	return m.executarSemValor(vendedor, data)
+}
+
+// SaldoVendas é o valor (wei) das vendas que o vendedor ainda não sacou
+func (m *Manager) SaldoVendas(vendedor common.Address) (*big.Int, error) {
+	saldo, err := m.leitor.SaldoVendas(m.opcoesLeitura(), vendedor)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	return saldo, nil
+}
+
+// ListarAnuncios devolve os anúncios ativos, do mais antigo para o mais recente
+func (m *Manager) ListarAnuncios() ([]tipos.AnuncioMercado, error) {
+	ids, err := m.leitor.ObterAnunciosAtivos(m.opcoesLeitura())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	// O contrato não preserva a ordem dos ativos; IDs crescem com o tempo
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Cmp(ids[j]) < 0 })
+
+	anuncios := make([]tipos.AnuncioMercado, 0, len(ids))
+	for _, id := range ids {
+		anuncio, ativo, err := m.ObterAnuncio(id)
+		if err != nil {
+			return nil, err
+		}
+		if ativo {
+			anuncios = append(anuncios, anuncio)
+		}
+	}
+	return anuncios, nil
+}
+
+// ObterAnuncio consulta um anúncio e a carta anunciada. O bool é falso se o anúncio não existe ou
+// não está mais ativo.
+func (m *Manager) ObterAnuncio(anuncioID *big.Int) (tipos.AnuncioMercado, bool, error) {
+	anuncio, err := m.leitor.ObterAnuncio(m.opcoesLeitura(), anuncioID)
+	if err != nil {
+		return tipos.AnuncioMercado{}, false, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	if !anuncio.Ativo {
+		return tipos.AnuncioMercado{}, false, nil
+	}
+
+	carta, err := m.ObterCarta(anuncio.CartaId)
+	if err != nil {
+		return tipos.AnuncioMercado{}, false, err
+	}
+	return tipos.AnuncioMercado{
+		ID:        anuncioID.String(),
+		Vendedor:  anuncio.Vendedor.Hex(),
+		Carta:     carta,
+		PrecoWei:  anuncio.Preco.String(),
+		Timestamp: anuncio.Timestamp.Int64(),
+	}, true, nil
+}
@@ -0,0 +1,259 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"jogodistribuido/servidor/blockchain/contrato"
+)
+
+const (
+	// Blocos após o compromisso em que revelarPacote é aceito (PRAZO_REVELACAO no contrato)
+	PrazoRevelacao = 250
+	// Intervalo entre consultas do bloco atual enquanto a revelação espera um bloco novo
+	IntervaloRevelacao = 1 * time.Second
+	// Espera máxima por um bloco posterior ao do compromisso
+	EsperaRevelacao = 2 * time.Minute
+)
+
+// CompraPendente é uma compra de pacote comprometida e ainda não revelada. O segredo é derivado da
+// chave do servidor (segredoCompra), então só este servidor o conhece e ele sobrevive a um reinício;
+// sem chave do servidor é aleatório e, se se perder, a compra expira sem cartas.
+type CompraPendente struct {
+	Comprador common.Address
+	Segredo   common.Hash
+	Bloco     uint64 // Bloco do compromisso
+}
+
+// PrecoPacote é o valor (wei) que o contrato cobra por um pacote
+func (m *Manager) PrecoPacote() (*big.Int, error) {
+	preco, err := m.leitor.PrecoPacote(m.opcoesLeitura())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	return preco, nil
+}
+
+// ComprarPacote compra um pacote nas duas etapas do contrato (compromisso e revelação)
+// Retorna os IDs das cartas criadas. Na cadeia simulada, que só minera quando recebe transações,
+// a revelação espera alguém chamar Minerar.
+func (m *Manager) ComprarPacote(jogadorAddress common.Address, valor *big.Int) ([]*big.Int, error) {
+	compra, err := m.ComprometerCompraPacote(jogadorAddress, valor)
+	if err != nil {
+		return nil, err
+	}
+	return m.RevelarPacote(compra)
+}
+
+// ComprometerCompraPacote paga o pacote e compromete o hash de um segredo aleatório. Uma compra
+// anterior do jogador ainda pendente no contrato é resolvida antes (resolverCompraAnterior).
+func (m *Manager) ComprometerCompraPacote(jogadorAddress common.Address, valor *big.Int) (CompraPendente, error) {
+	if err := m.resolverCompraAnterior(jogadorAddress); err != nil {
+		return CompraPendente{}, err
+	}
+
+	numero, err := m.leitor.ComprasComprometidas(m.opcoesLeitura(), jogadorAddress)
+	if err != nil {
+		return CompraPendente{}, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	segredo, ok := m.segredoCompra(jogadorAddress, numero)
+	if !ok {
+		if _, err := rand.Read(segredo[:]); err != nil {
+			return CompraPendente{}, fmt.Errorf("erro ao gerar segredo: %v", err)
+		}
+	}
+	data, err := contrato.DadosComprometerCompraPacote(contrato.HashCompromisso(jogadorAddress, segredo))
+	if err != nil {
+		return CompraPendente{}, fmt.Errorf("erro ao preparar chamada: %v", err)
+	}
+
+	tx, err := m.enviarTransacao(jogadorAddress, data, valor)
+	if err != nil {
+		return CompraPendente{}, fmt.Errorf("erro ao enviar transação: %w", err)
+	}
+	receipt, err := m.confirmarTransacao(jogadorAddress, data, valor, tx)
+	if err != nil {
+		return CompraPendente{}, err
+	}
+
+	compra := CompraPendente{Comprador: jogadorAddress, Segredo: segredo, Bloco: receipt.BlockNumber.Uint64()}
+	m.mutexCompras.Lock()
+	m.compras[jogadorAddress] = compra
+	m.mutexCompras.Unlock()
+	return compra, nil
+}
+
+// RevelarPacote revela o segredo de uma compra comprometida e retorna os IDs das cartas criadas.
+// O contrato só aceita a revelação depois do bloco do compromisso, cujo hash entra no sorteio.
+func (m *Manager) RevelarPacote(compra CompraPendente) ([]*big.Int, error) {
+	if err := m.aguardarBlocoApos(compra.Bloco); err != nil {
+		return nil, err
+	}
+
+	data, err := contrato.DadosRevelarPacote(compra.Segredo)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao preparar chamada: %v", err)
+	}
+	tx, err := m.enviarTransacao(compra.Comprador, data, big.NewInt(0))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao enviar transação: %w", err)
+	}
+	receipt, err := m.confirmarTransacao(compra.Comprador, data, big.NewInt(0), tx)
+	if err != nil {
+		return nil, err
+	}
+	m.esquecerCompra(compra.Comprador, compra.Bloco)
+
+	// Lê o evento PacoteComprado para obter os IDs das cartas
+	filtro, err := contrato.NewGameEconomyFilterer(m.contractAddress, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar bindings do contrato: %v", err)
+	}
+	for _, vLog := range receipt.Logs {
+		if vLog.Address != m.contractAddress {
+			continue
+		}
+		if evento, err := filtro.ParsePacoteComprado(*vLog); err == nil {
+			return evento.TokenIds, nil
+		}
+	}
+	return []*big.Int{}, nil
+}
+
+// PacoteRevelado lê a transação de revelação tx e devolve o comprador e os IDs das cartas do evento
+// PacoteComprado que ela emitiu. Falha se a transação não foi confirmada, reverteu ou não revelou um
+// pacote deste contrato.
+func (m *Manager) PacoteRevelado(tx common.Hash) (common.Address, []*big.Int, error) {
+	receipt, err := m.client.TransactionReceipt(context.Background(), tx)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("transação %s não confirmada: %v", tx.Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return common.Address{}, nil, fmt.Errorf("transação %s reverteu", tx.Hex())
+	}
+	filtro, err := contrato.NewGameEconomyFilterer(m.contractAddress, nil)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("erro ao criar bindings do contrato: %v", err)
+	}
+	for _, vLog := range receipt.Logs {
+		if vLog.Address != m.contractAddress {
+			continue
+		}
+		if evento, err := filtro.ParsePacoteComprado(*vLog); err == nil {
+			return evento.Comprador, evento.TokenIds, nil
+		}
+	}
+	return common.Address{}, nil, fmt.Errorf("transação %s não revelou um pacote do contrato", tx.Hex())
+}
+
+// ExpirarCompraPacote descarta no contrato a compra do jogador que passou do prazo de revelação,
+// liberando-o para comprar de novo. O pagamento não volta; a transação sai da conta do servidor.
+func (m *Manager) ExpirarCompraPacote(comprador common.Address) error {
+	data, err := contrato.DadosExpirarCompraPacote(comprador)
+	if err != nil {
+		return fmt.Errorf("erro ao preparar chamada: %v", err)
+	}
+
+	tx, err := m.enviarTransacao(m.serverAccount, data, big.NewInt(0))
+	if err != nil {
+		return fmt.Errorf("erro ao enviar transação: %w", err)
+	}
+	if _, err := m.confirmarTransacao(m.serverAccount, data, big.NewInt(0), tx); err != nil {
+		return err
+	}
+
+	m.mutexCompras.Lock()
+	delete(m.compras, comprador)
+	m.mutexCompras.Unlock()
+	return nil
+}
+
+// resolverCompraAnterior trata a compra do jogador que ainda está pendente no contrato: revela se
+// o segredo é deste servidor, descarta se o prazo passou. Uma pendente sem segredo conhecido e
+// ainda no prazo impede a nova compra até expirar.
+func (m *Manager) resolverCompraAnterior(jogador common.Address) error {
+	pendente, err := m.leitor.ComprasPendentes(m.opcoesLeitura(), jogador)
+	if err != nil {
+		return fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	if pendente.Bloco.Sign() == 0 {
+		return nil
+	}
+	bloco := pendente.Bloco.Uint64()
+
+	atual, err := m.client.BlockNumber(context.Background())
+	if err != nil {
+		return fmt.Errorf("erro ao obter bloco atual: %v", err)
+	}
+	if atual > bloco+PrazoRevelacao {
+		log.Printf("[BLOCKCHAIN] Compra de %s do bloco %d não foi revelada no prazo; descartando", jogador.Hex(), bloco)
+		return m.ExpirarCompraPacote(jogador)
+	}
+
+	m.mutexCompras.Lock()
+	compra, ok := m.compras[jogador]
+	m.mutexCompras.Unlock()
+	if !ok || compra.Bloco != bloco {
+		// Segredo perdido num reinício: a compra pendente é a última comprometida pelo jogador
+		numero, err := m.leitor.ComprasComprometidas(m.opcoesLeitura(), jogador)
+		if err != nil {
+			return fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+		}
+		compra = CompraPendente{Comprador: jogador, Bloco: bloco}
+		ok = false
+		if numero.Sign() > 0 {
+			compra.Segredo, ok = m.segredoCompra(jogador, new(big.Int).Sub(numero, big.NewInt(1)))
+		}
+		ok = ok && contrato.HashCompromisso(jogador, compra.Segredo) == common.Hash(pendente.Compromisso)
+	}
+	if ok {
+		log.Printf("[BLOCKCHAIN] Revelando compra pendente de %s (bloco %d) antes da nova compra", jogador.Hex(), bloco)
+		_, err := m.RevelarPacote(compra)
+		return err
+	}
+	return fmt.Errorf("compra do bloco %d sem segredo neste servidor, expira após o bloco %d: %w",
+		bloco, bloco+PrazoRevelacao, contrato.ErrCompraPendente)
+}
+
+// segredoCompra deriva da chave do servidor o segredo da compra de número n (comprasComprometidas
+// antes do compromisso) do comprador, como o cliente faz com a carteira. Retorna false se o Manager
+// não tem chave do servidor.
+func (m *Manager) segredoCompra(comprador common.Address, n *big.Int) (common.Hash, bool) {
+	chave := m.chaves[m.serverAccount]
+	if m.serverAccount == (common.Address{}) || chave == nil {
+		return common.Hash{}, false
+	}
+	return crypto.Keccak256Hash(crypto.FromECDSA(chave), []byte("pacote"), comprador.Bytes(), common.BigToHash(n).Bytes()), true
+}
+
+// aguardarBlocoApos espera o nó ter um bloco posterior ao informado: a estimativa de gas roda
+// sobre o último bloco, e antes disso revelarPacote reverte com "Revele em um bloco posterior".
+func (m *Manager) aguardarBlocoApos(bloco uint64) error {
+	limite := time.Now().Add(EsperaRevelacao)
+	for {
+		atual, err := m.client.BlockNumber(context.Background())
+		if err == nil && atual > bloco {
+			return nil
+		}
+		if time.Now().After(limite) {
+			return fmt.Errorf("nenhum bloco após o %d em %v", bloco, EsperaRevelacao)
+		}
+		time.Sleep(IntervaloRevelacao)
+	}
+}
+
+// esquecerCompra remove o segredo de uma compra já revelada
+func (m *Manager) esquecerCompra(comprador common.Address, bloco uint64) {
+	m.mutexCompras.Lock()
+	defer m.mutexCompras.Unlock()
+	if compra, ok := m.compras[comprador]; ok && compra.Bloco == bloco {
+		delete(m.compras, comprador)
+	}
+}
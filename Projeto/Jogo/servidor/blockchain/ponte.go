@@ -0,0 +1,229 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"jogodistribuido/servidor/blockchain/contrato"
+)
+
+const (
+	// Intervalo entre consultas de logs quando o nó não aceita assinaturas
+	IntervaloPonte = 2 * time.Second
+	// Logs de uma mesma compra/troca chegam juntos; a ponte espera esse silêncio antes de notificar
+	JanelaPonte = 300 * time.Millisecond
+	// Espera antes de tentar assinar de novo após a assinatura cair
+	EsperaReassinatura = 5 * time.Second
+)
+
+// Eventos do GameEconomy que mudam cartas de dono
+var eventosInventario = []string{"CartaCriada", "CartaTransferida", "CartaQueimada", "PacoteComprado", "TrocaExecutada"}
+
+// MudancaInventario são os endereços cujas cartas mudaram e os eventos que causaram a mudança
+type MudancaInventario struct {
+	Enderecos []common.Address
+	Eventos   []string
+	Bloco     uint64 // Bloco mais recente entre os eventos
+}
+
+// UsarWebsocket passa a receber os eventos do contrato por assinatura no endpoint ws:// informado
+// (o RPC principal pode continuar em HTTP)
+func (m *Manager) UsarWebsocket(url string) error {
+	client, err := ethclient.Dial(url)
+	if err != nil {
+		return err
+	}
+	m.assinaturas = client
+	return nil
+}
+
+// ObservarInventarios chama notificar a cada lote de eventos que muda cartas de dono, até o contexto
+// ser cancelado. Usa assinatura de logs quando o nó suporta (websocket, cadeia simulada) e consulta
+// periódica caso contrário. Só eventos a partir do bloco atual são considerados.
+func (m *Manager) ObservarInventarios(ctx context.Context, notificar func(MudancaInventario)) {
+	proximo, err := m.client.BlockNumber(ctx)
+	for err != nil {
+		log.Printf("[PONTE_ERRO] Falha ao obter o último bloco: %v", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(IntervaloPonte):
+		}
+		proximo, err = m.client.BlockNumber(ctx)
+	}
+	proximo++
+
+	fonte := m.assinaturas
+	if fonte == nil {
+		fonte = m.client
+	}
+	for ctx.Err() == nil {
+		ultimo, err := m.assinarInventarios(ctx, fonte, notificar)
+		if ultimo >= proximo {
+			proximo = ultimo + 1
+		}
+		if err == nil {
+			return
+		}
+		if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+			log.Printf("[PONTE] Nó sem assinatura de logs; consultando a cada %v", IntervaloPonte)
+			m.consultarInventarios(ctx, proximo, notificar)
+			return
+		}
+		log.Printf("[PONTE_ERRO] Assinatura de logs interrompida: %v. Tentando de novo em %v", err, EsperaReassinatura)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(EsperaReassinatura):
+		}
+		// Recupera o que passou enquanto a assinatura estava fora
+		if ultimo, err := m.buscarMudancas(ctx, proximo, notificar); err == nil {
+			proximo = ultimo + 1
+		}
+	}
+}
+
+// assinarInventarios recebe os logs por assinatura, agrupando os que chegam em sequência.
+// Retorna o último bloco notificado e o erro que encerrou a assinatura (nil se o contexto acabou).
+func (m *Manager) assinarInventarios(ctx context.Context, fonte Backend, notificar func(MudancaInventario)) (uint64, error) {
+	logs := make(chan types.Log, 64)
+	filtro := m.filtroInventario(0, nil)
+	filtro.FromBlock = nil // Só logs novos; o que ficou para trás é buscado por buscarMudancas
+	sub, err := fonte.SubscribeFilterLogs(ctx, filtro, logs)
+	if err != nil {
+		return 0, err
+	}
+	defer sub.Unsubscribe()
+	log.Printf("[PONTE] Assinando eventos de inventário do contrato %s", m.contractAddress.Hex())
+
+	var lote []types.Log
+	var ultimo uint64
+	janela := time.NewTimer(JanelaPonte)
+	janela.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ultimo, nil
+		case err := <-sub.Err():
+			if len(lote) > 0 {
+				notificar(m.mudancaDosLogs(lote))
+			}
+			return ultimo, err
+		case vLog := <-logs:
+			lote = append(lote, vLog)
+			if vLog.BlockNumber > ultimo {
+				ultimo = vLog.BlockNumber
+			}
+			janela.Reset(JanelaPonte)
+		case <-janela.C:
+			notificar(m.mudancaDosLogs(lote))
+			lote = nil
+		}
+	}
+}
+
+// consultarInventarios busca os logs novos a cada IntervaloPonte
+func (m *Manager) consultarInventarios(ctx context.Context, proximo uint64, notificar func(MudancaInventario)) {
+	ticker := time.NewTicker(IntervaloPonte)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		ultimo, err := m.buscarMudancas(ctx, proximo, notificar)
+		if err != nil {
+			log.Printf("[PONTE_ERRO] %v", err)
+			continue
+		}
+		proximo = ultimo + 1
+	}
+}
+
+// buscarMudancas notifica os eventos de [desde, último bloco] e retorna o último bloco processado
+func (m *Manager) buscarMudancas(ctx context.Context, desde uint64, notificar func(MudancaInventario)) (uint64, error) {
+	cabeca, err := m.client.BlockNumber(ctx)
+	if err != nil {
+		return desde - 1, err
+	}
+	if cabeca < desde {
+		return desde - 1, nil
+	}
+	logs, err := m.client.FilterLogs(ctx, m.filtroInventario(desde, &cabeca))
+	if err != nil {
+		return desde - 1, err
+	}
+	if len(logs) > 0 {
+		notificar(m.mudancaDosLogs(logs))
+	}
+	return cabeca, nil
+}
+
+func (m *Manager) filtroInventario(desde uint64, ate *uint64) ethereum.FilterQuery {
+	topicos := make([]common.Hash, 0, len(eventosInventario))
+	if parsed, err := contrato.GameEconomyMetaData.GetAbi(); err == nil {
+		for _, nome := range eventosInventario {
+			topicos = append(topicos, parsed.Events[nome].ID)
+		}
+	}
+	filtro := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(desde),
+		Addresses: []common.Address{m.contractAddress},
+		Topics:    [][]common.Hash{topicos},
+	}
+	if ate != nil {
+		filtro.ToBlock = new(big.Int).SetUint64(*ate)
+	}
+	return filtro
+}
+
+// mudancaDosLogs decodifica os logs pelos bindings e junta os endereços afetados, sem repetição
+func (m *Manager) mudancaDosLogs(logs []types.Log) MudancaInventario {
+	var mudanca MudancaInventario
+	filtro, err := contrato.NewGameEconomyFilterer(m.contractAddress, nil)
+	if err != nil {
+		return mudanca
+	}
+	vistos := make(map[common.Address]bool)
+	eventos := make(map[string]bool)
+	adicionar := func(evento string, enderecos ...common.Address) {
+		for _, endereco := range enderecos {
+			if endereco != (common.Address{}) && !vistos[endereco] {
+				vistos[endereco] = true
+				mudanca.Enderecos = append(mudanca.Enderecos, endereco)
+			}
+		}
+		if !eventos[evento] {
+			eventos[evento] = true
+			mudanca.Eventos = append(mudanca.Eventos, evento)
+		}
+	}
+	for _, vLog := range logs {
+		if vLog.BlockNumber > mudanca.Bloco {
+			mudanca.Bloco = vLog.BlockNumber
+		}
+		if e, err := filtro.ParseCartaCriada(vLog); err == nil {
+			adicionar("CartaCriada", e.Proprietario)
+		} else if e, err := filtro.ParseCartaTransferida(vLog); err == nil {
+			adicionar("CartaTransferida", e.De, e.Para)
+		} else if e, err := filtro.ParseCartaQueimada(vLog); err == nil {
+			adicionar("CartaQueimada", e.Proprietario)
+		} else if e, err := filtro.ParsePacoteComprado(vLog); err == nil {
+			adicionar("PacoteComprado", e.Comprador)
+		} else if e, err := filtro.ParseTrocaExecutada(vLog); err == nil {
+			adicionar("TrocaExecutada", e.Jogador1, e.Jogador2)
+		}
+	}
+	// Consultas de inventário pelo indexador esperam ele alcançar este bloco
+	m.marcarBloco(mudanca.Bloco)
+	return mudanca
+}
@@ -0,0 +1,94 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"jogodistribuido/servidor/blockchain/contrato"
+)
+
+// ErrSemContaServidor indica um Manager criado sem a chave do servidor (KEYSTORE_PATH)
+var ErrSemContaServidor = errors.New("conta do servidor não configurada")
+
+// ResultadoPartida é o que Host, Sombra e jogadores assinam ao fim da partida. HashLog é o
+// keccak256 do EventLog (seguranca.HashEventLog), que o replay recalcula na auditoria.
+type ResultadoPartida struct {
+	Jogador1 common.Address
+	Jogador2 common.Address
+	Vencedor common.Address // Endereço zero em caso de empate
+	HashLog  common.Hash
+}
+
+// ContaServidor devolve a conta com que este servidor assina resultados e envia transações administrativas
+func (m *Manager) ContaServidor() common.Address {
+	return m.serverAccount
+}
+
+// HashResultado é o hash do resultado para este contrato (igual a hashResultado no GameEconomy)
+func (m *Manager) HashResultado(r ResultadoPartida) common.Hash {
+	return contrato.HashResultado(m.contractAddress, r.Jogador1, r.Jogador2, r.Vencedor, r.HashLog)
+}
+
+// AssinarResultado assina o resultado com a conta do servidor (personal_sign, como as carteiras)
+func (m *Manager) AssinarResultado(r ResultadoPartida) ([]byte, error) {
+	chave, ok := m.chaves[m.serverAccount]
+	if !ok {
+		return nil, ErrSemContaServidor
+	}
+	hash := m.HashResultado(r)
+	assinatura, err := crypto.Sign(accounts.TextHash(hash.Bytes()), chave)
+	if err != nil {
+		return nil, err
+	}
+	assinatura[crypto.RecoveryIDOffset] += 27
+	return assinatura, nil
+}
+
+// ServidorAutorizado consulta se a conta pode assinar resultados como Host ou Sombra
+func (m *Manager) ServidorAutorizado(conta common.Address) (bool, error) {
+	autorizado, err := m.leitor.ServidoresAutorizados(m.opcoesLeitura(), conta)
+	if err != nil {
+		return false, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	return autorizado, nil
+}
+
+// AutorizarServidor autoriza (ou revoga) a conta de um servidor de jogo. Só o dono do contrato
+// pode fazê-lo, então a transação sai da conta do servidor, que precisa ser o dono.
+func (m *Manager) AutorizarServidor(conta common.Address, autorizado bool) error {
+	data, err := contrato.DadosAutorizarServidor(conta, autorizado)
+	if err != nil {
+		return fmt.Errorf("erro ao preparar chamada: %v", err)
+	}
+
+	tx, err := m.enviarTransacao(m.serverAccount, data, big.NewInt(0))
+	if err != nil {
+		return fmt.Errorf("erro ao enviar transação: %w", err)
+	}
+
+	_, err = m.confirmarTransacao(m.serverAccount, data, big.NewInt(0), tx)
+	return err
+}
+
+// RegistrarPartida registra o resultado co-assinado na blockchain. assinaturas segue a ordem
+// [host, sombra, jogador1, jogador2] e sombra é o endereço zero em partidas locais. O contrato
+// confere as assinaturas, então qualquer conta pode enviar: aqui sai da conta deste servidor.
+func (m *Manager) RegistrarPartida(r ResultadoPartida, host, sombra common.Address, assinaturas [][]byte) error {
+	data, err := contrato.DadosRegistrarPartida(r.Jogador1, r.Jogador2, r.Vencedor, r.HashLog, host, sombra, assinaturas)
+	if err != nil {
+		return fmt.Errorf("erro ao preparar chamada: %v", err)
+	}
+
+	tx, err := m.enviarTransacao(m.serverAccount, data, big.NewInt(0))
+	if err != nil {
+		return fmt.Errorf("erro ao enviar transação: %w", err)
+	}
+
+	// Aguarda confirmação
+	_, err = m.confirmarTransacao(m.serverAccount, data, big.NewInt(0), tx)
+	return err
+}
@@ -0,0 +1,146 @@
+// Package simulado sobe uma cadeia Ethereum em processo (simulated backend do go-ethereum) com o
+// GameEconomy implantado, para exercitar o blockchain.Manager e o cliente sem geth nem Docker.
+//
+// Uso:
+//
+//	cadeia, err := simulado.NovaCadeia(2)
+//	defer cadeia.Fechar()
+//	m, err := cadeia.Manager()
+//	m.ComprometerCompraPacote(crypto.PubkeyToAddress(cadeia.Jogadores[0].PublicKey), preco)
+//
+// ABI e bytecode são os embutidos no pacote contrato (gerados de Blockchain/contracts/GameEconomy.bin);
+// NovaCadeiaComBytecode implanta outro bytecode em mãos. Cada transação enviada é minerada na hora,
+// então os recibos aparecem imediatamente para aguardarConfirmacao.
+//
+// O go-ethereum fica em v1.14 ou mais novo: até a v1.13 o nó simulado dependia de fjl/memsize, que
+// não linka com Go 1.23+ sem -ldflags=-checklinkname=0.
+package simulado
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"jogodistribuido/servidor/blockchain"
+	"jogodistribuido/servidor/blockchain/contrato"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+// SaldoInicial é o saldo de cada conta criada pela cadeia (1000 ETH).
+var SaldoInicial = new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+
+// Cadeia é uma rede simulada com o GameEconomy implantado. Dono é a conta que implantou o
+// contrato (e faz o papel da conta do servidor); Jogadores são contas com saldo para comprar
+// pacotes e trocar cartas.
+type Cadeia struct {
+	sim       *simulated.Backend
+	Backend   blockchain.Backend
+	Contrato  common.Address
+	Dono      *ecdsa.PrivateKey
+	Jogadores []*ecdsa.PrivateKey
+}
+
+// NovaCadeia implanta o GameEconomy com o bytecode embutido no pacote contrato.
+func NovaCadeia(jogadores int) (*Cadeia, error) {
+	bytecode, err := contrato.Bytecode()
+	if err != nil {
+		return nil, err
+	}
+	return NovaCadeiaComBytecode(bytecode, jogadores)
+}
+
+// NovaCadeiaComBytecode cria a cadeia com o dono e 'jogadores' contas financiadas e implanta o
+// GameEconomy a partir do bytecode informado.
+func NovaCadeiaComBytecode(bytecode []byte, jogadores int) (*Cadeia, error) {
+	if len(bytecode) == 0 {
+		return nil, fmt.Errorf("bytecode do contrato vazio")
+	}
+	contratoABI, err := contrato.ABI()
+	if err != nil {
+		return nil, err
+	}
+
+	chaves := make([]*ecdsa.PrivateKey, jogadores+1)
+	alloc := make(types.GenesisAlloc, len(chaves))
+	for i := range chaves {
+		chave, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, fmt.Errorf("erro ao gerar chave: %v", err)
+		}
+		chaves[i] = chave
+		alloc[crypto.PubkeyToAddress(chave.PublicKey)] = types.Account{Balance: SaldoInicial}
+	}
+
+	sim := simulated.NewBackend(alloc)
+	backend := &autoMinerador{Client: sim.Client(), sim: sim}
+
+	chainID, err := backend.ChainID(context.Background())
+	if err != nil {
+		sim.Close()
+		return nil, fmt.Errorf("erro ao obter chain ID: %v", err)
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(chaves[0], chainID)
+	if err != nil {
+		sim.Close()
+		return nil, err
+	}
+	endereco, _, _, err := bind.DeployContract(auth, contratoABI, bytecode, backend)
+	if err != nil {
+		sim.Close()
+		return nil, fmt.Errorf("erro ao implantar contrato: %v", err)
+	}
+
+	return &Cadeia{
+		sim:       sim,
+		Backend:   backend,
+		Contrato:  endereco,
+		Dono:      chaves[0],
+		Jogadores: chaves[1:],
+	}, nil
+}
+
+// Manager devolve um blockchain.Manager ligado à cadeia, assinando localmente pelo dono (conta
+// do servidor) e por todos os jogadores.
+func (c *Cadeia) Manager() (*blockchain.Manager, error) {
+	m, err := blockchain.NewManagerComBackend(c.Backend, nil, c.Contrato, c.Dono)
+	if err != nil {
+		return nil, err
+	}
+	for _, chave := range c.Jogadores {
+		m.AdicionarChave(chave)
+	}
+	return m, nil
+}
+
+// Minerar fecha um bloco com as transações pendentes (útil para avançar a cadeia sem transações).
+func (c *Cadeia) Minerar() common.Hash {
+	return c.sim.Commit()
+}
+
+// Fechar encerra o nó simulado.
+func (c *Cadeia) Fechar() error {
+	return c.sim.Close()
+}
+
+// autoMinerador minera um bloco a cada transação enviada, como um geth com --dev.period 0.
+type autoMinerador struct {
+	simulated.Client
+	sim   *simulated.Backend
+	mutex sync.Mutex
+}
+
+func (a *autoMinerador) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if err := a.Client.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	a.sim.Commit()
+	return nil
+}
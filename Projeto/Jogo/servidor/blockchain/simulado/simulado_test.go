@@ -0,0 +1,337 @@
+package simulado
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"jogodistribuido/servidor/blockchain"
+	"jogodistribuido/servidor/blockchain/contrato"
+)
+
+// novaCadeiaTeste sobe a cadeia com 'jogadores' contas e o Manager do dono, fechando tudo no fim do teste
+func novaCadeiaTeste(t *testing.T, jogadores int) (*Cadeia, *blockchain.Manager) {
+	t.Helper()
+	cadeia, err := NovaCadeia(jogadores)
+	if err != nil {
+		t.Fatalf("NovaCadeia: %v", err)
+	}
+	t.Cleanup(func() { cadeia.Fechar() })
+	m, err := cadeia.Manager()
+	if err != nil {
+		t.Fatalf("Manager: %v", err)
+	}
+	return cadeia, m
+}
+
+// comprarPacote faz o compromisso, fecha um bloco e revela, como ComprarPacote faz num nó que minera sozinho
+func comprarPacote(t *testing.T, cadeia *Cadeia, m *blockchain.Manager, jogador common.Address) []*big.Int {
+	t.Helper()
+	preco, err := m.PrecoPacote()
+	if err != nil {
+		t.Fatalf("PrecoPacote: %v", err)
+	}
+	compra, err := m.ComprometerCompraPacote(jogador, preco)
+	if err != nil {
+		t.Fatalf("ComprometerCompraPacote: %v", err)
+	}
+	cadeia.Minerar()
+	ids, err := m.RevelarPacote(compra)
+	if err != nil {
+		t.Fatalf("RevelarPacote: %v", err)
+	}
+	return ids
+}
+
+func TestComprarPacoteCompromissoERevelacao(t *testing.T) {
+	cadeia, m := novaCadeiaTeste(t, 1)
+	jogador := crypto.PubkeyToAddress(cadeia.Jogadores[0].PublicKey)
+
+	ids := comprarPacote(t, cadeia, m, jogador)
+	if len(ids) == 0 {
+		t.Fatal("revelação não criou cartas")
+	}
+	for _, id := range ids {
+		dono, err := m.VerificarPropriedadeCarta(jogador, id)
+		if err != nil {
+			t.Fatalf("VerificarPropriedadeCarta(%s): %v", id, err)
+		}
+		if !dono {
+			t.Errorf("carta %s não pertence ao comprador", id)
+		}
+	}
+
+	inventario, err := m.ObterInventario(jogador)
+	if err != nil {
+		t.Fatalf("ObterInventario: %v", err)
+	}
+	if len(inventario) != len(ids) {
+		t.Errorf("inventário com %d cartas, esperado %d", len(inventario), len(ids))
+	}
+}
+
+func TestNovaCompraRevelaCompraAnterior(t *testing.T) {
+	cadeia, m := novaCadeiaTeste(t, 1)
+	jogador := crypto.PubkeyToAddress(cadeia.Jogadores[0].PublicKey)
+
+	preco, err := m.PrecoPacote()
+	if err != nil {
+		t.Fatalf("PrecoPacote: %v", err)
+	}
+	if _, err := m.ComprometerCompraPacote(jogador, preco); err != nil {
+		t.Fatalf("ComprometerCompraPacote: %v", err)
+	}
+	// Com o segredo ainda neste Manager, a nova compra revela a anterior antes de comprometer
+	cadeia.Minerar()
+	if _, err := m.ComprometerCompraPacote(jogador, preco); err != nil {
+		t.Fatalf("segunda compra: %v", err)
+	}
+	inventario, err := m.ObterInventario(jogador)
+	if err != nil {
+		t.Fatalf("ObterInventario: %v", err)
+	}
+	if len(inventario) == 0 {
+		t.Error("compra anterior não foi revelada antes da nova")
+	}
+}
+
+func TestCompraPendenteSemSegredoImpedeNovaCompra(t *testing.T) {
+	cadeia, m := novaCadeiaTeste(t, 1)
+	jogador := crypto.PubkeyToAddress(cadeia.Jogadores[0].PublicKey)
+
+	preco, err := m.PrecoPacote()
+	if err != nil {
+		t.Fatalf("PrecoPacote: %v", err)
+	}
+	if _, err := m.ComprometerCompraPacote(jogador, preco); err != nil {
+		t.Fatalf("ComprometerCompraPacote: %v", err)
+	}
+
+	// Outro servidor, com outra chave, não conhece o segredo
+	chaveOutro, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	outro, err := blockchain.NewManagerComBackend(cadeia.Backend, nil, cadeia.Contrato, chaveOutro)
+	if err != nil {
+		t.Fatalf("Manager do outro servidor: %v", err)
+	}
+	outro.AdicionarChave(cadeia.Jogadores[0])
+	_, err = outro.ComprometerCompraPacote(jogador, preco)
+	if !errors.Is(err, contrato.ErrCompraPendente) {
+		t.Fatalf("erro = %v, esperado ErrCompraPendente", err)
+	}
+}
+
+func TestCompraPendenteReveladaAposReinicio(t *testing.T) {
+	cadeia, m := novaCadeiaTeste(t, 1)
+	jogador := crypto.PubkeyToAddress(cadeia.Jogadores[0].PublicKey)
+
+	preco, err := m.PrecoPacote()
+	if err != nil {
+		t.Fatalf("PrecoPacote: %v", err)
+	}
+	if _, err := m.ComprometerCompraPacote(jogador, preco); err != nil {
+		t.Fatalf("ComprometerCompraPacote: %v", err)
+	}
+	cadeia.Minerar()
+
+	// Um Manager novo com a mesma chave (o servidor reiniciado) deriva o segredo e revela a compra
+	reiniciado, err := cadeia.Manager()
+	if err != nil {
+		t.Fatalf("Manager: %v", err)
+	}
+	if _, err := reiniciado.ComprometerCompraPacote(jogador, preco); err != nil {
+		t.Fatalf("compra após reinício: %v", err)
+	}
+	inventario, err := reiniciado.ObterInventario(jogador)
+	if err != nil {
+		t.Fatalf("ObterInventario: %v", err)
+	}
+	if len(inventario) == 0 {
+		t.Error("compra anterior ao reinício não foi revelada")
+	}
+}
+
+func TestPacoteReveladoConfereCompradorECartas(t *testing.T) {
+	cadeia, m := novaCadeiaTeste(t, 1)
+	jogador := crypto.PubkeyToAddress(cadeia.Jogadores[0].PublicKey)
+	ids := comprarPacote(t, cadeia, m, jogador)
+
+	filtro, err := contrato.NewGameEconomyFilterer(cadeia.Contrato, cadeia.Backend)
+	if err != nil {
+		t.Fatalf("NewGameEconomyFilterer: %v", err)
+	}
+	eventos, err := filtro.FilterPacoteComprado(&bind.FilterOpts{Start: 0}, []common.Address{jogador})
+	if err != nil {
+		t.Fatalf("FilterPacoteComprado: %v", err)
+	}
+	if !eventos.Next() {
+		t.Fatal("nenhum PacoteComprado para o comprador")
+	}
+	revelacao := eventos.Event.Raw.TxHash
+
+	comprador, tokenIds, err := m.PacoteRevelado(revelacao)
+	if err != nil {
+		t.Fatalf("PacoteRevelado: %v", err)
+	}
+	if comprador != jogador {
+		t.Errorf("comprador %s, esperado %s", comprador.Hex(), jogador.Hex())
+	}
+	if len(tokenIds) != len(ids) {
+		t.Fatalf("%d cartas no evento, esperado %d", len(tokenIds), len(ids))
+	}
+	for i := range ids {
+		if tokenIds[i].Cmp(ids[i]) != 0 {
+			t.Errorf("carta %d: %s, esperado %s", i, tokenIds[i], ids[i])
+		}
+	}
+
+	// Uma transação que não revelou pacote (o compromisso da próxima compra) é recusada
+	preco, err := m.PrecoPacote()
+	if err != nil {
+		t.Fatalf("PrecoPacote: %v", err)
+	}
+	if _, err := m.ComprometerCompraPacote(jogador, preco); err != nil {
+		t.Fatalf("ComprometerCompraPacote: %v", err)
+	}
+	bloco, err := cadeia.Backend.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatalf("BlockNumber: %v", err)
+	}
+	compromissos, err := filtro.FilterCompraComprometida(&bind.FilterOpts{Start: bloco}, []common.Address{jogador})
+	if err != nil {
+		t.Fatalf("FilterCompraComprometida: %v", err)
+	}
+	if !compromissos.Next() {
+		t.Fatal("nenhuma CompraComprometida no último bloco")
+	}
+	if _, _, err := m.PacoteRevelado(compromissos.Event.Raw.TxHash); err == nil {
+		t.Error("PacoteRevelado aceitou a transação do compromisso")
+	}
+}
+
+func TestPropostaTrocaCriarEAceitar(t *testing.T) {
+	cadeia, m := novaCadeiaTeste(t, 2)
+	jogador1 := crypto.PubkeyToAddress(cadeia.Jogadores[0].PublicKey)
+	jogador2 := crypto.PubkeyToAddress(cadeia.Jogadores[1].PublicKey)
+
+	carta1 := comprarPacote(t, cadeia, m, jogador1)[0]
+	carta2 := comprarPacote(t, cadeia, m, jogador2)[0]
+
+	propostaID, err := m.CriarPropostaTroca(jogador1, jogador2, carta1, carta2)
+	if err != nil {
+		t.Fatalf("CriarPropostaTroca: %v", err)
+	}
+	recebidas, _, err := m.ListarPropostasPendentes(jogador2)
+	if err != nil {
+		t.Fatalf("ListarPropostasPendentes: %v", err)
+	}
+	if len(recebidas) != 1 {
+		t.Fatalf("%d propostas recebidas, esperado 1", len(recebidas))
+	}
+
+	if err := m.AceitarPropostaTroca(jogador2, propostaID); err != nil {
+		t.Fatalf("AceitarPropostaTroca: %v", err)
+	}
+	if dono, err := m.VerificarPropriedadeCarta(jogador2, carta1); err != nil || !dono {
+		t.Errorf("carta %s não passou para o jogador2 (err=%v)", carta1, err)
+	}
+	if dono, err := m.VerificarPropriedadeCarta(jogador1, carta2); err != nil || !dono {
+		t.Errorf("carta %s não passou para o jogador1 (err=%v)", carta2, err)
+	}
+
+	// A proposta executada não pode ser aceita de novo
+	if err := m.AceitarPropostaTroca(jogador2, propostaID); err == nil {
+		t.Error("segunda aceitação da mesma proposta deveria falhar")
+	}
+}
+
+func TestAceitarPropostaDeOutroDestinatarioFalha(t *testing.T) {
+	cadeia, m := novaCadeiaTeste(t, 2)
+	jogador1 := crypto.PubkeyToAddress(cadeia.Jogadores[0].PublicKey)
+	jogador2 := crypto.PubkeyToAddress(cadeia.Jogadores[1].PublicKey)
+
+	carta1 := comprarPacote(t, cadeia, m, jogador1)[0]
+	carta2 := comprarPacote(t, cadeia, m, jogador2)[0]
+	propostaID, err := m.CriarPropostaTroca(jogador1, jogador2, carta1, carta2)
+	if err != nil {
+		t.Fatalf("CriarPropostaTroca: %v", err)
+	}
+
+	if err := m.AceitarPropostaTroca(jogador1, propostaID); err == nil {
+		t.Fatal("o próprio proponente não deveria aceitar a proposta")
+	}
+}
+
+// assinarComo assina o resultado com a chave de um jogador, como a carteira faz com personal_sign
+func assinarComo(t *testing.T, m *blockchain.Manager, r blockchain.ResultadoPartida, chave *ecdsa.PrivateKey) []byte {
+	t.Helper()
+	hash := m.HashResultado(r)
+	assinatura, err := crypto.Sign(accounts.TextHash(hash.Bytes()), chave)
+	if err != nil {
+		t.Fatalf("assinar: %v", err)
+	}
+	assinatura[crypto.RecoveryIDOffset] += 27
+	return assinatura
+}
+
+func TestRegistrarPartidaComQuatroAssinaturas(t *testing.T) {
+	cadeia, host := novaCadeiaTeste(t, 3)
+	jogador1 := crypto.PubkeyToAddress(cadeia.Jogadores[0].PublicKey)
+	jogador2 := crypto.PubkeyToAddress(cadeia.Jogadores[1].PublicKey)
+
+	// A terceira conta faz o papel do servidor Sombra, com o próprio Manager
+	chaveSombra := cadeia.Jogadores[2]
+	sombra, err := blockchain.NewManagerComBackend(cadeia.Backend, nil, cadeia.Contrato, chaveSombra)
+	if err != nil {
+		t.Fatalf("Manager da Sombra: %v", err)
+	}
+	for _, conta := range []common.Address{host.ContaServidor(), sombra.ContaServidor()} {
+		if err := host.AutorizarServidor(conta, true); err != nil {
+			t.Fatalf("AutorizarServidor(%s): %v", conta.Hex(), err)
+		}
+	}
+
+	r := blockchain.ResultadoPartida{
+		Jogador1: jogador1,
+		Jogador2: jogador2,
+		Vencedor: jogador1,
+		HashLog:  crypto.Keccak256Hash([]byte("log da partida")),
+	}
+	assinaturaHost, err := host.AssinarResultado(r)
+	if err != nil {
+		t.Fatalf("AssinarResultado (Host): %v", err)
+	}
+	assinaturaSombra, err := sombra.AssinarResultado(r)
+	if err != nil {
+		t.Fatalf("AssinarResultado (Sombra): %v", err)
+	}
+	assinaturas := [][]byte{
+		assinaturaHost,
+		assinaturaSombra,
+		assinarComo(t, host, r, cadeia.Jogadores[0]),
+		assinarComo(t, host, r, cadeia.Jogadores[1]),
+	}
+
+	// Assinaturas fora de ordem não conferem
+	trocadas := [][]byte{assinaturas[0], assinaturas[1], assinaturas[3], assinaturas[2]}
+	if err := host.RegistrarPartida(r, host.ContaServidor(), sombra.ContaServidor(), trocadas); err == nil {
+		t.Fatal("registro com assinaturas dos jogadores trocadas deveria falhar")
+	}
+
+	if err := host.RegistrarPartida(r, host.ContaServidor(), sombra.ContaServidor(), assinaturas); err != nil {
+		t.Fatalf("RegistrarPartida: %v", err)
+	}
+
+	// O mesmo log não pode ser registrado duas vezes
+	if err := host.RegistrarPartida(r, host.ContaServidor(), sombra.ContaServidor(), assinaturas); err == nil {
+		t.Error("registro repetido do mesmo log deveria falhar")
+	}
+}
@@ -0,0 +1,89 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"jogodistribuido/servidor/blockchain/contrato"
+)
+
+// PropostaListada é uma proposta de troca com o ID que o contrato lhe deu
+type PropostaListada struct {
+	ID *big.Int
+	contrato.PropostaTroca
+}
+
+// ObterPropostaTroca consulta uma proposta de troca (a proposta vazia se o ID não existe)
+func (m *Manager) ObterPropostaTroca(propostaID *big.Int) (contrato.PropostaTroca, error) {
+	proposta, err := m.leitor.ObterPropostaTroca(m.opcoesLeitura(), propostaID)
+	if err != nil {
+		return contrato.PropostaTroca{}, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	return proposta, nil
+}
+
+// CancelarPropostaTroca desiste de uma proposta pendente (jogador1 é quem a criou)
+func (m *Manager) CancelarPropostaTroca(jogador1 common.Address, propostaID *big.Int) error {
+	data, err := contrato.DadosCancelarPropostaTroca(propostaID)
+	if err != nil {
+		return fmt.Errorf("erro ao preparar chamada: %v", err)
+	}
+	return m.executarSemValor(jogador1, data)
+}
+
+// RecusarPropostaTroca recusa uma proposta pendente (jogador2 é o destinatário)
+func (m *Manager) RecusarPropostaTroca(jogador2 common.Address, propostaID *big.Int) error {
+	data, err := contrato.DadosRecusarPropostaTroca(propostaID)
+	if err != nil {
+		return fmt.Errorf("erro ao preparar chamada: %v", err)
+	}
+	return m.executarSemValor(jogador2, data)
+}
+
+// ListarPropostasPendentes devolve as propostas ainda aceitáveis que o jogador recebeu e as que enviou,
+// da mais antiga para a mais recente
+func (m *Manager) ListarPropostasPendentes(jogador common.Address) (recebidas, enviadas []PropostaListada, err error) {
+	idsRecebidas, err := m.leitor.ObterPropostasRecebidas(m.opcoesLeitura(), jogador)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+	idsEnviadas, err := m.leitor.ObterPropostasEnviadas(m.opcoesLeitura(), jogador)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao chamar contrato: %w", contrato.DecodificarErro(err))
+	}
+
+	agora := time.Now()
+	if recebidas, err = m.filtrarPendentes(idsRecebidas, agora); err != nil {
+		return nil, nil, err
+	}
+	if enviadas, err = m.filtrarPendentes(idsEnviadas, agora); err != nil {
+		return nil, nil, err
+	}
+	return recebidas, enviadas, nil
+}
+
+func (m *Manager) filtrarPendentes(ids []*big.Int, agora time.Time) ([]PropostaListada, error) {
+	pendentes := make([]PropostaListada, 0, len(ids))
+	for _, id := range ids {
+		proposta, err := m.ObterPropostaTroca(id)
+		if err != nil {
+			return nil, err
+		}
+		if proposta.Estado(agora) == contrato.PropostaPendente {
+			pendentes = append(pendentes, PropostaListada{ID: id, PropostaTroca: proposta})
+		}
+	}
+	return pendentes, nil
+}
+
+// executarSemValor envia uma transação sem valor e aguarda a confirmação
+func (m *Manager) executarSemValor(from common.Address, data []byte) error {
+	tx, err := m.enviarTransacao(from, data, big.NewInt(0))
+	if err != nil {
+		return fmt.Errorf("erro ao enviar transação: %w", err)
+	}
+	_, err = m.confirmarTransacao(from, data, big.NewInt(0), tx)
+	return err
+}
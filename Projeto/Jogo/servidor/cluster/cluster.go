@@ -2,26 +2,29 @@ package cluster
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"fmt"
 	"jogodistribuido/servidor/tipos"
 	"log"
-	"math/rand"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 )
 
 const (
-	ELEICAO_TIMEOUT     = 30 * time.Second // Aumentado para 30 segundos
-	HEARTBEAT_INTERVALO = 5 * time.Second  // Aumentado para 5 segundos
+	HEARTBEAT_INTERVALO = 5 * time.Second // Heartbeat de descoberta/atividade entre servidores
 )
 
 type ServidorInterface interface {
 	GetMeuEndereco() string
+	AplicarEntradaLog(entrada EntradaLog) // Chamado, em ordem, para cada entrada confirmada do log Raft
+	// GerarSnapshot serializa o estado construído pelas entradas aplicadas, para compactar o log.
+	GerarSnapshot() (json.RawMessage, error)
+	// RestaurarSnapshot substitui esse estado pelo de um snapshot (ao reiniciar ou vindo do líder).
+	RestaurarSnapshot(estado json.RawMessage) error
 }
 
 // ClusterManagerInterface define as operações que o manager do cluster expõe
@@ -30,15 +33,17 @@ type ClusterManagerInterface interface {
 	GetServidores() map[string]*tipos.InfoServidor
 	GetServidoresAtivos(meuEndereco string) []string
 	ProcessarHeartbeat(string, map[string]interface{})
-	ProcessarVoto(string, int64) (bool, int64)
-	DeclararLider(string, int64)
+	ProcessarPedidoVoto(RequisicaoVoto) RespostaVoto
+	ProcessarAppendEntries(RequisicaoAppendEntries) RespostaAppendEntries
+	ProcessarSnapshot(RequisicaoSnapshot) RespostaSnapshot
+	Propor(tipo string, dados interface{}) (int64, error)
 	RegistrarServidor(*tipos.InfoServidor) map[string]*tipos.InfoServidor
 	GetLider() string
 	SouLider() bool
 	Run()
 }
 
-// Manager gere o estado do cluster, descoberta e eleições.
+// Manager gere o estado do cluster, descoberta e o consenso Raft.
 type Manager struct {
 	servidor ServidorInterface
 	mutex    sync.RWMutex
@@ -47,20 +52,62 @@ type Manager struct {
 	souLider        bool
 	LiderAtual      string
 	TermoAtual      int64
-	UltimoHeartbeat time.Time
+	UltimoHeartbeat time.Time // Último contato recebido do líder
+
+	// Estado Raft (ver raft.go)
+	estado            string
+	votouEm           string
+	log               []EntradaLog // log[0] é a última entrada do snapshot (Indice 0, Termo 0 sem snapshot)
+	indiceCommit      int64
+	ultimoAplicado    int64
+	proximoIndice     map[string]int64 // líder: próxima entrada a enviar para cada peer
+	indiceReplicado   map[string]int64 // líder: maior entrada sabidamente replicada em cada peer
+	prazoEleicao      time.Time
+	ultimoAppend      time.Time
+	aguardandoCommit  map[int64]propostaPendente
+	sinalAplicar      chan struct{}
+	caminhoEstado     string
+	caminhoLog        string
+	caminhoSnapshot   string
+	arquivoLog        *os.File        // Log em disco, aberto para acréscimo
+	snapshot          json.RawMessage // Estado do servidor até log[0] (ver snapshot.go)
+	mutexAplicacao    sync.Mutex      // Serializa a aplicação de entradas, a compactação e a instalação de snapshots
+	peersConfigurados []string        // Membros do cluster informados em PEERS
 }
 
 func NewManager(s ServidorInterface) *Manager {
-	return &Manager{
-		servidor:   s,
-		Servidores: make(map[string]*tipos.InfoServidor),
+	m := &Manager{
+		servidor:          s,
+		Servidores:        make(map[string]*tipos.InfoServidor),
+		estado:            ESTADO_SEGUIDOR,
+		log:               []EntradaLog{{}},
+		proximoIndice:     make(map[string]int64),
+		indiceReplicado:   make(map[string]int64),
+		aguardandoCommit:  make(map[int64]propostaPendente),
+		sinalAplicar:      make(chan struct{}, 1),
+		peersConfigurados: lerPeersConfigurados(),
 	}
+
+	dataDir := os.Getenv("DATA_DIR")
+	if dataDir == "" {
+		dataDir = "dados"
+	}
+	prefixo := filepath.Join(dataDir, "raft_"+strings.NewReplacer(":", "_", "/", "_").Replace(s.GetMeuEndereco()))
+	m.caminhoEstado = prefixo + ".json"
+	m.caminhoLog = prefixo + ".log"
+	m.caminhoSnapshot = prefixo + ".snapshot.json"
+	if err := m.carregarEstado(); err != nil {
+		log.Printf("[RAFT] ⚠ Falha ao carregar estado persistido: %v. Iniciando com log vazio.", err)
+	}
+	return m
 }
 
 func (m *Manager) Run() {
+	m.restaurarSnapshotCarregado()
 	go m.descobrirServidores()
 	go m.enviarHeartbeats()
-	go m.processoEleicao()
+	go m.cicloRaft()
+	go m.aplicarEntradasConfirmadas()
 }
 
 // descobrirServidores tenta se conectar a peers conhecidos para se registrar.
@@ -135,15 +182,10 @@ func (m *Manager) enviarHeartbeats() {
 		}
 		m.mutex.RUnlock()
 
+		// A liderança é anunciada pelo AppendEntries do Raft; este heartbeat só mantém a lista de servidores ativos
 		payload := map[string]interface{}{
 			"remetente": m.servidor.GetMeuEndereco(),
 		}
-		// Somente o líder anexa seu status ao heartbeat
-		m.mutex.RLock()
-		if m.souLider {
-			payload["lider"] = m.LiderAtual
-		}
-		m.mutex.RUnlock()
 
 		jsonData, _ := json.Marshal(payload)
 
@@ -156,138 +198,6 @@ func (m *Manager) enviarHeartbeats() {
 	}
 }
 
-// processoEleicao verifica a necessidade de uma nova eleição.
-func (m *Manager) processoEleicao() {
-	// Atraso inicial aleatório para evitar eleições simultâneas no início
-	time.Sleep(time.Duration(5+rand.Intn(5)) * time.Second)
-
-	for {
-		m.mutex.RLock()
-		ultimoPingLider := m.UltimoHeartbeat
-		liderExiste := m.LiderAtual != ""
-		souLider := m.souLider
-		m.mutex.RUnlock()
-
-		// Se não sou o líder, verifico se o líder atual está ativo.
-		// Se não há líder ou o último heartbeat do líder foi há muito tempo, inicia a eleição.
-		if !souLider && (!liderExiste || time.Since(ultimoPingLider) > ELEICAO_TIMEOUT) {
-			log.Printf("Líder inativo ou inexistente. Iniciando nova eleição.")
-			m.iniciarEleicao()
-		}
-		// Verifica a cada X segundos
-		time.Sleep(ELEICAO_TIMEOUT / 2)
-	}
-}
-
-func (m *Manager) iniciarEleicao() {
-	m.mutex.Lock()
-	m.TermoAtual++
-	termoCandidato := m.TermoAtual
-	// Vota em si mesmo
-	votos := 1
-	m.mutex.Unlock()
-
-	log.Printf("Iniciando eleição para o termo %d", termoCandidato)
-
-	m.mutex.RLock()
-	totalServidores := len(m.Servidores)
-	peers := make([]string, 0, totalServidores)
-	for addr, srv := range m.Servidores {
-		// Envia pedido de voto para todos os outros servidores ativos
-		if addr != m.servidor.GetMeuEndereco() && srv.Ativo {
-			peers = append(peers, addr)
-		}
-	}
-	m.mutex.RUnlock()
-
-	// Se for o único servidor, torna-se líder imediatamente
-	if totalServidores <= 1 {
-		log.Println("Servidor único, tornando-se líder.")
-		m.tornarLider()
-		return
-	}
-
-	maioriaNecessaria := (totalServidores / 2) + 1
-	votosRecebidos := make(chan bool, len(peers))
-	ctx, cancel := context.WithTimeout(context.Background(), ELEICAO_TIMEOUT)
-	defer cancel()
-
-	// Envia pedidos de voto em paralelo
-	for _, addr := range peers {
-		go func(addr string) {
-			url := fmt.Sprintf("http://%s/election/vote", addr)
-			reqBody, _ := json.Marshal(map[string]interface{}{
-				"candidato": m.servidor.GetMeuEndereco(),
-				"termo":     termoCandidato,
-			})
-
-			httpClient := &http.Client{Timeout: 2 * time.Second}
-			req, _ := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(reqBody))
-			req.Header.Set("Content-Type", "application/json")
-
-			resp, err := httpClient.Do(req)
-			if err == nil && resp.StatusCode == http.StatusOK {
-				var res map[string]interface{}
-				if json.NewDecoder(resp.Body).Decode(&res) == nil {
-					if voto, ok := res["voto_concedido"].(bool); ok && voto {
-						votosRecebidos <- true
-					}
-				}
-				resp.Body.Close()
-			}
-		}(addr)
-	}
-
-	// Coleta de votos
-	for {
-		select {
-		case <-votosRecebidos:
-			votos++
-			log.Printf("Voto recebido. Total de votos: %d/%d", votos, maioriaNecessaria)
-			if votos >= maioriaNecessaria {
-				log.Printf("Maioria alcançada. Tornando-se líder para o termo %d.", termoCandidato)
-				m.tornarLider()
-				return
-			}
-		case <-ctx.Done():
-			log.Printf("Timeout da eleição para o termo %d. Votos obtidos: %d.", termoCandidato, votos)
-			return // Fim da eleição por timeout
-		}
-	}
-}
-
-func (m *Manager) tornarLider() {
-	m.mutex.Lock()
-	m.souLider = true
-	m.LiderAtual = m.servidor.GetMeuEndereco()
-	termoAtual := m.TermoAtual
-	m.mutex.Unlock()
-
-	log.Printf("================ SOU O LÍDER (Termo: %d) ================", termoAtual)
-
-	// Notifica todos os outros servidores sobre a liderança
-	m.mutex.RLock()
-	peers := make([]string, 0, len(m.Servidores))
-	for addr := range m.Servidores {
-		if addr != m.servidor.GetMeuEndereco() {
-			peers = append(peers, addr)
-		}
-	}
-	m.mutex.RUnlock()
-
-	reqBody, _ := json.Marshal(map[string]interface{}{
-		"novo_lider": m.servidor.GetMeuEndereco(),
-		"termo":      termoAtual,
-	})
-
-	for _, addr := range peers {
-		go func(addr string) {
-			url := fmt.Sprintf("http://%s/election/leader", addr)
-			http.Post(url, "application/json", bytes.NewBuffer(reqBody))
-		}(addr)
-	}
-}
-
 // Implementação da ClusterManagerInterface
 
 func (m *Manager) GetServidores() map[string]*tipos.InfoServidor {
@@ -328,39 +238,6 @@ func (m *Manager) ProcessarHeartbeat(endereco string, dados map[string]interface
 		}
 		log.Printf("Novo servidor descoberto via heartbeat: %s", endereco)
 	}
-
-	if lider, ok := dados["lider"].(string); ok && lider != "" {
-		if m.LiderAtual != lider {
-			log.Printf("Heartbeat recebido de %s, que reporta o líder como %s", endereco, lider)
-		}
-		m.LiderAtual = lider
-		m.souLider = (lider == m.servidor.GetMeuEndereco())
-		m.UltimoHeartbeat = time.Now()
-	}
-}
-
-func (m *Manager) ProcessarVoto(candidato string, termo int64) (bool, int64) {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-	if termo > m.TermoAtual {
-		m.TermoAtual = termo
-		m.LiderAtual = "" // Anula o líder ao votar em um novo termo
-		log.Printf("Votando em %s para termo %d", candidato, termo)
-		return true, m.TermoAtual
-	}
-	return false, m.TermoAtual
-}
-
-func (m *Manager) DeclararLider(novoLider string, termo int64) {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-	if termo >= m.TermoAtual {
-		m.TermoAtual = termo
-		m.LiderAtual = novoLider
-		m.souLider = (novoLider == m.servidor.GetMeuEndereco())
-		m.UltimoHeartbeat = time.Now()
-		log.Printf("Novo líder reconhecido via declaração: %s (termo %d)", novoLider, termo)
-	}
 }
 
 func (m *Manager) RegistrarServidor(novoServidor *tipos.InfoServidor) map[string]*tipos.InfoServidor {
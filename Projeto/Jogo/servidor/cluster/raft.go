@@ -0,0 +1,917 @@
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"jogodistribuido/servidor/seguranca"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Implementação do consenso Raft usado para eleger o líder e replicar as
+// operações que precisam de uma ordem única no cluster (compras de pacotes,
+// resultados de partidas). O estado persistente (termo, voto e log) é salvo
+// em disco, com fsync, antes de responder a qualquer RPC; se a gravação falhar,
+// o servidor não vota nem confirma entradas. O log é gravado só por acréscimo
+// e compactado num snapshot do estado do servidor a cada ENTRADAS_POR_SNAPSHOT
+// entradas aplicadas (ver snapshot.go).
+//
+// Os votantes são fixos: os endereços de PEERS. Servidores descobertos em tempo
+// de execução (heartbeat, /register) não entram na conta da maioria.
+
+const (
+	APPEND_INTERVALO      = 1 * time.Second        // Intervalo entre AppendEntries do líder (também serve de heartbeat)
+	ELEICAO_TIMEOUT_MIN   = 3 * time.Second        // Menor prazo sem notícias do líder antes de iniciar eleição
+	ELEICAO_TIMEOUT_MAX   = 6 * time.Second        // Maior prazo (o valor é sorteado entre os dois)
+	PROPOSTA_TIMEOUT      = 10 * time.Second       // Tempo máximo esperando uma proposta ser confirmada
+	RPC_TIMEOUT           = 1 * time.Second        // Timeout das chamadas de voto/append entre servidores
+	CICLO_RAFT            = 100 * time.Millisecond // Resolução do relógio do Raft
+	MAX_ENTRADAS_APPEND   = 100                    // Máximo de entradas enviadas por AppendEntries
+	ENTRADAS_POR_SNAPSHOT = 1000                   // Entradas aplicadas acumuladas no log antes de compactá-lo num snapshot
+)
+
+const (
+	ESTADO_SEGUIDOR  = "SEGUIDOR"
+	ESTADO_CANDIDATO = "CANDIDATO"
+	ESTADO_LIDER     = "LIDER"
+)
+
+// ENTRADA_NOOP é adicionada por todo líder recém-eleito para confirmar as entradas de termos anteriores.
+const ENTRADA_NOOP = "NOOP"
+
+var clienteRaft = &http.Client{Timeout: RPC_TIMEOUT}
+
+// EntradaLog é uma operação replicada. Tipo e Dados são definidos pelo servidor (ex: "COMPRA_PACOTE").
+type EntradaLog struct {
+	Termo  int64           `json:"termo"`
+	Indice int64           `json:"indice"`
+	Tipo   string          `json:"tipo"`
+	Dados  json.RawMessage `json:"dados,omitempty"`
+}
+
+type RequisicaoVoto struct {
+	Candidato       string `json:"candidato"`
+	Termo           int64  `json:"termo"`
+	UltimoIndiceLog int64  `json:"ultimo_indice_log"`
+	UltimoTermoLog  int64  `json:"ultimo_termo_log"`
+}
+
+type RespostaVoto struct {
+	Termo         int64 `json:"termo"`
+	VotoConcedido bool  `json:"voto_concedido"`
+}
+
+type RequisicaoAppendEntries struct {
+	Termo             int64        `json:"termo"`
+	Lider             string       `json:"lider"`
+	IndiceLogAnterior int64        `json:"indice_log_anterior"`
+	TermoLogAnterior  int64        `json:"termo_log_anterior"`
+	Entradas          []EntradaLog `json:"entradas"`
+	IndiceCommitLider int64        `json:"indice_commit_lider"`
+}
+
+type RespostaAppendEntries struct {
+	Termo        int64 `json:"termo"`
+	Sucesso      bool  `json:"sucesso"`
+	UltimoIndice int64 `json:"ultimo_indice"` // Em caso de falha, dica de onde o líder deve recomeçar
+}
+
+// propostaPendente é uma entrada proposta localmente aguardando ser confirmada e aplicada.
+type propostaPendente struct {
+	termo int64
+	ch    chan error
+}
+
+// estadoPersistido é o termo e o voto, gravados a cada mudança. O log fica num arquivo à parte
+// (caminhoLog), uma entrada JSON por linha.
+type estadoPersistido struct {
+	TermoAtual int64        `json:"termo_atual"`
+	VotouEm    string       `json:"votou_em"`
+	Log        []EntradaLog `json:"log,omitempty"` // Formato antigo, com o log inteiro no mesmo arquivo; migrado ao carregar
+}
+
+// ==================== RELÓGIO E ELEIÇÃO ====================
+
+// cicloRaft dispara eleições quando o prazo expira e, no líder, replica o log periodicamente.
+func (m *Manager) cicloRaft() {
+	m.mutex.Lock()
+	m.reiniciarPrazoEleicao()
+	m.mutex.Unlock()
+
+	ticker := time.NewTicker(CICLO_RAFT)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.mutex.RLock()
+		agora := time.Now()
+		iniciarEleicao := m.estado != ESTADO_LIDER && agora.After(m.prazoEleicao)
+		replicar := m.estado == ESTADO_LIDER && agora.Sub(m.ultimoAppend) >= APPEND_INTERVALO
+		m.mutex.RUnlock()
+
+		if iniciarEleicao {
+			m.iniciarEleicao()
+		} else if replicar {
+			m.replicarParaTodos()
+		}
+	}
+}
+
+func (m *Manager) iniciarEleicao() {
+	m.mutex.Lock()
+	meuEndereco := m.servidor.GetMeuEndereco()
+	m.estado = ESTADO_CANDIDATO
+	m.souLider = false
+	m.LiderAtual = ""
+	m.TermoAtual++
+	m.votouEm = meuEndereco
+	m.reiniciarPrazoEleicao()
+	if err := m.persistirEstado(); err != nil {
+		// Sem o voto em si mesmo gravado, a candidatura poderia votar duas vezes no termo após reiniciar
+		log.Printf("[RAFT_ERRO] Eleição do termo %d abortada: %v", m.TermoAtual, err)
+		m.estado = ESTADO_SEGUIDOR
+		m.mutex.Unlock()
+		return
+	}
+
+	termo := m.TermoAtual
+	req := RequisicaoVoto{
+		Candidato:       meuEndereco,
+		Termo:           termo,
+		UltimoIndiceLog: m.ultimoIndice(),
+		UltimoTermoLog:  m.ultimoTermo(),
+	}
+	peers := m.peersRaft()
+	maioria := m.maioria()
+	m.mutex.Unlock()
+
+	log.Printf("[RAFT] Iniciando eleição para o termo %d (%d servidores, maioria %d)", termo, len(peers)+1, maioria)
+
+	votos := 1 // Voto em si mesmo
+	if votos >= maioria {
+		m.tornarLider(termo)
+		return
+	}
+
+	respostas := make(chan RespostaVoto, len(peers))
+	for _, peer := range peers {
+		go func(peer string) {
+			resp, err := m.enviarPedidoVoto(peer, req)
+			if err != nil {
+				respostas <- RespostaVoto{}
+				return
+			}
+			respostas <- resp
+		}(peer)
+	}
+
+	for range peers {
+		resp := <-respostas
+		if resp.Termo > termo {
+			m.mutex.Lock()
+			m.tornarSeguidor(resp.Termo, "")
+			m.mutex.Unlock()
+			return
+		}
+		if resp.VotoConcedido {
+			votos++
+			if votos >= maioria {
+				m.tornarLider(termo)
+				return
+			}
+		}
+	}
+	log.Printf("[RAFT] Eleição do termo %d sem maioria (%d/%d votos)", termo, votos, maioria)
+}
+
+func (m *Manager) enviarPedidoVoto(peer string, req RequisicaoVoto) (RespostaVoto, error) {
+	var resp RespostaVoto
+	err := m.postRaft(fmt.Sprintf("http://%s/election/vote", peer), req, &resp)
+	return resp, err
+}
+
+// tornarLider assume a liderança do termo, se ainda for candidato nele.
+func (m *Manager) tornarLider(termo int64) {
+	m.mutex.Lock()
+	if m.estado != ESTADO_CANDIDATO || m.TermoAtual != termo {
+		m.mutex.Unlock()
+		return
+	}
+	meuEndereco := m.servidor.GetMeuEndereco()
+	m.estado = ESTADO_LIDER
+	m.souLider = true
+	m.LiderAtual = meuEndereco
+	m.proximoIndice = make(map[string]int64)
+	m.indiceReplicado = make(map[string]int64)
+	for _, peer := range m.peersRaft() {
+		m.proximoIndice[peer] = m.ultimoIndice() + 1
+	}
+	noop := EntradaLog{Termo: termo, Indice: m.ultimoIndice() + 1, Tipo: ENTRADA_NOOP}
+	m.log = append(m.log, noop)
+	if err := m.anexarLog([]EntradaLog{noop}); err != nil {
+		// O líder conta a si mesmo na maioria; sem o NOOP em disco, não pode liderar
+		log.Printf("[RAFT_ERRO] Liderança do termo %d recusada: %v", termo, err)
+		m.log = m.log[:len(m.log)-1]
+		m.tornarSeguidor(termo, "")
+		m.mutex.Unlock()
+		return
+	}
+	m.mutex.Unlock()
+
+	log.Printf("[RAFT] Eleito líder para o termo %d", termo)
+	m.replicarParaTodos()
+}
+
+// tornarSeguidor volta ao estado de seguidor. Retorna o erro de gravação do novo termo, caso em
+// que o servidor não pode votar nem confirmar entradas nele. Assume que m.mutex já está bloqueado.
+func (m *Manager) tornarSeguidor(termo int64, lider string) error {
+	var errPersistencia error
+	if termo > m.TermoAtual {
+		m.TermoAtual = termo
+		m.votouEm = ""
+		errPersistencia = m.persistirEstado()
+	}
+	if m.estado == ESTADO_LIDER {
+		log.Printf("[RAFT] Deixando a liderança (termo %d)", m.TermoAtual)
+	}
+	if lider != "" && lider != m.LiderAtual {
+		log.Printf("[RAFT] Novo líder reconhecido: %s (termo %d)", lider, m.TermoAtual)
+	}
+	m.estado = ESTADO_SEGUIDOR
+	m.souLider = false
+	m.LiderAtual = lider
+	m.reiniciarPrazoEleicao()
+	return errPersistencia
+}
+
+// ProcessarPedidoVoto responde ao RequestVote de um candidato.
+func (m *Manager) ProcessarPedidoVoto(req RequisicaoVoto) RespostaVoto {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if req.Termo > m.TermoAtual {
+		if err := m.tornarSeguidor(req.Termo, ""); err != nil {
+			log.Printf("[RAFT_ERRO] Voto a %s recusado: %v", req.Candidato, err)
+			return RespostaVoto{Termo: m.TermoAtual}
+		}
+	}
+	resp := RespostaVoto{Termo: m.TermoAtual}
+	if req.Termo < m.TermoAtual {
+		return resp
+	}
+
+	// Só vota em quem tem um log pelo menos tão atualizado quanto o local
+	logAtualizado := req.UltimoTermoLog > m.ultimoTermo() ||
+		(req.UltimoTermoLog == m.ultimoTermo() && req.UltimoIndiceLog >= m.ultimoIndice())
+	if (m.votouEm == "" || m.votouEm == req.Candidato) && logAtualizado {
+		votoAnterior := m.votouEm
+		m.votouEm = req.Candidato
+		if err := m.persistirEstado(); err != nil {
+			log.Printf("[RAFT_ERRO] Voto a %s recusado: %v", req.Candidato, err)
+			m.votouEm = votoAnterior
+			return resp
+		}
+		m.reiniciarPrazoEleicao()
+		resp.VotoConcedido = true
+		log.Printf("[RAFT] Voto concedido a %s no termo %d", req.Candidato, req.Termo)
+	}
+	return resp
+}
+
+// ==================== REPLICAÇÃO DO LOG ====================
+
+// ProcessarAppendEntries recebe entradas (ou apenas o heartbeat) do líder.
+func (m *Manager) ProcessarAppendEntries(req RequisicaoAppendEntries) RespostaAppendEntries {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	resp := RespostaAppendEntries{Termo: m.TermoAtual, UltimoIndice: m.ultimoIndice()}
+	if req.Termo < m.TermoAtual {
+		return resp
+	}
+
+	errPersistencia := m.tornarSeguidor(req.Termo, req.Lider)
+	m.UltimoHeartbeat = time.Now()
+	resp.Termo = m.TermoAtual
+	if errPersistencia != nil {
+		log.Printf("[RAFT_ERRO] AppendEntries de %s recusado: %v", req.Lider, errPersistencia)
+		return resp
+	}
+
+	// As entradas até a base do log já foram aplicadas e estão no snapshot; são as mesmas do líder
+	anterior, termoAnterior, entradas := req.IndiceLogAnterior, req.TermoLogAnterior, req.Entradas
+	if base := m.indiceBase(); anterior < base {
+		pular := base - anterior
+		if pular > int64(len(entradas)) {
+			pular = int64(len(entradas))
+		}
+		anterior, termoAnterior, entradas = base, m.log[0].Termo, entradas[pular:]
+	}
+
+	// Verificação de consistência: o log local precisa conter a entrada anterior com o mesmo termo
+	if anterior > m.ultimoIndice() || m.entrada(anterior).Termo != termoAnterior {
+		if anterior-1 < resp.UltimoIndice {
+			resp.UltimoIndice = anterior - 1
+		}
+		return resp
+	}
+
+	var novas []EntradaLog
+	truncado := false
+	for i, entrada := range entradas {
+		if entrada.Indice <= m.ultimoIndice() {
+			if m.entrada(entrada.Indice).Termo == entrada.Termo {
+				continue
+			}
+			// Conflito: descarta a entrada divergente e tudo o que vem depois
+			log.Printf("[RAFT] Conflito no índice %d: truncando log local", entrada.Indice)
+			m.log = m.log[:entrada.Indice-m.indiceBase()]
+			truncado = true
+		}
+		novas = entradas[i:]
+		m.log = append(m.log, novas...)
+		break
+	}
+	// Sem as entradas em disco, o ack deixaria o líder contar uma réplica que some ao reiniciar
+	if truncado {
+		if err := m.reescreverLog(); err != nil {
+			log.Printf("[RAFT_ERRO] Entradas de %s não confirmadas: %v", req.Lider, err)
+			return resp
+		}
+	} else if len(novas) > 0 {
+		if err := m.anexarLog(novas); err != nil {
+			log.Printf("[RAFT_ERRO] Entradas de %s não confirmadas: %v", req.Lider, err)
+			return resp
+		}
+	}
+
+	ultimoNovo := req.IndiceLogAnterior + int64(len(req.Entradas))
+	novoCommit := req.IndiceCommitLider
+	if ultimoNovo < novoCommit {
+		novoCommit = ultimoNovo
+	}
+	if novoCommit > m.indiceCommit {
+		m.indiceCommit = novoCommit
+		m.sinalizarAplicacao()
+	}
+
+	resp.Sucesso = true
+	resp.UltimoIndice = ultimoNovo
+	return resp
+}
+
+// replicarParaTodos envia AppendEntries para todos os peers. Só tem efeito no líder.
+func (m *Manager) replicarParaTodos() {
+	m.mutex.Lock()
+	if m.estado != ESTADO_LIDER {
+		m.mutex.Unlock()
+		return
+	}
+	m.ultimoAppend = time.Now()
+	peers := m.peersRaft()
+	m.avancarCommit() // Num cluster de um servidor só, a entrada já está confirmada
+	m.mutex.Unlock()
+
+	for _, peer := range peers {
+		go m.replicarPara(peer)
+	}
+}
+
+func (m *Manager) replicarPara(peer string) {
+	m.mutex.Lock()
+	if m.estado != ESTADO_LIDER {
+		m.mutex.Unlock()
+		return
+	}
+	termo := m.TermoAtual
+	proximo := m.proximoIndice[peer]
+	if proximo < 1 || proximo > m.ultimoIndice()+1 {
+		proximo = m.ultimoIndice() + 1
+	}
+	if proximo <= m.indiceBase() {
+		// As entradas que o peer precisa já foram compactadas: ele recebe o snapshot
+		req := RequisicaoSnapshot{
+			Termo:       termo,
+			Lider:       m.servidor.GetMeuEndereco(),
+			Indice:      m.indiceBase(),
+			TermoIndice: m.log[0].Termo,
+			Estado:      m.snapshot,
+		}
+		m.mutex.Unlock()
+		m.enviarSnapshot(peer, req)
+		return
+	}
+	anterior := proximo - 1
+	fim := m.ultimoIndice() + 1
+	if fim-proximo > MAX_ENTRADAS_APPEND {
+		fim = proximo + MAX_ENTRADAS_APPEND
+	}
+	req := RequisicaoAppendEntries{
+		Termo:             termo,
+		Lider:             m.servidor.GetMeuEndereco(),
+		IndiceLogAnterior: anterior,
+		TermoLogAnterior:  m.entrada(anterior).Termo,
+		Entradas:          append([]EntradaLog(nil), m.log[proximo-m.indiceBase():fim-m.indiceBase()]...),
+		IndiceCommitLider: m.indiceCommit,
+	}
+	m.mutex.Unlock()
+
+	var resp RespostaAppendEntries
+	if err := m.postRaft(fmt.Sprintf("http://%s/election/append_entries", peer), req, &resp); err != nil {
+		return // Peer fora do ar; tenta de novo no próximo ciclo
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if resp.Termo > m.TermoAtual {
+		m.tornarSeguidor(resp.Termo, "")
+		return
+	}
+	if m.estado != ESTADO_LIDER || m.TermoAtual != termo {
+		return
+	}
+
+	if resp.Sucesso {
+		replicado := anterior + int64(len(req.Entradas))
+		if replicado > m.indiceReplicado[peer] {
+			m.indiceReplicado[peer] = replicado
+		}
+		m.proximoIndice[peer] = m.indiceReplicado[peer] + 1
+		m.avancarCommit()
+		if m.proximoIndice[peer] <= m.ultimoIndice() {
+			go m.replicarPara(peer) // Ainda há entradas pendentes para este peer
+		}
+		return
+	}
+
+	// Log do peer diverge: recua usando a dica e tenta de novo
+	novoProximo := resp.UltimoIndice + 1
+	if novoProximo > anterior {
+		novoProximo = anterior
+	}
+	if novoProximo <= m.indiceReplicado[peer] {
+		novoProximo = m.indiceReplicado[peer] + 1
+	}
+	if novoProximo < 1 {
+		novoProximo = 1
+	}
+	m.proximoIndice[peer] = novoProximo
+	go m.replicarPara(peer)
+}
+
+// avancarCommit confirma a maior entrada do termo atual replicada na maioria.
+// Entradas de termos anteriores só são confirmadas indiretamente. Assume que m.mutex já está bloqueado.
+func (m *Manager) avancarCommit() {
+	peers := m.peersRaft()
+	maioria := m.maioria()
+	for n := m.ultimoIndice(); n > m.indiceCommit; n-- {
+		if m.entrada(n).Termo != m.TermoAtual {
+			break
+		}
+		replicas := 1
+		for _, peer := range peers {
+			if m.indiceReplicado[peer] >= n {
+				replicas++
+			}
+		}
+		if replicas >= maioria {
+			m.indiceCommit = n
+			m.sinalizarAplicacao()
+			return
+		}
+	}
+}
+
+// aplicarEntradasConfirmadas entrega ao servidor, em ordem, as entradas confirmadas, e compacta o
+// log quando as entradas aplicadas passam de ENTRADAS_POR_SNAPSHOT.
+func (m *Manager) aplicarEntradasConfirmadas() {
+	for range m.sinalAplicar {
+		for m.aplicarProximaEntrada() {
+		}
+		m.compactarLog()
+	}
+}
+
+// aplicarProximaEntrada aplica a entrada seguinte a ultimoAplicado, se já confirmada. Retorna false
+// quando não há entrada a aplicar.
+func (m *Manager) aplicarProximaEntrada() bool {
+	m.mutexAplicacao.Lock()
+	defer m.mutexAplicacao.Unlock()
+
+	m.mutex.Lock()
+	if m.ultimoAplicado >= m.indiceCommit {
+		m.mutex.Unlock()
+		return false
+	}
+	m.ultimoAplicado++
+	entrada := m.entrada(m.ultimoAplicado)
+	pendente, aguardando := m.aguardandoCommit[entrada.Indice]
+	delete(m.aguardandoCommit, entrada.Indice)
+	m.mutex.Unlock()
+
+	if entrada.Tipo != ENTRADA_NOOP {
+		m.servidor.AplicarEntradaLog(entrada)
+	}
+	if aguardando {
+		if pendente.termo == entrada.Termo {
+			pendente.ch <- nil
+		} else {
+			pendente.ch <- fmt.Errorf("entrada %d substituída por outro líder", entrada.Indice)
+		}
+	}
+	return true
+}
+
+// sinalizarAplicacao acorda o aplicador sem bloquear.
+func (m *Manager) sinalizarAplicacao() {
+	select {
+	case m.sinalAplicar <- struct{}{}:
+	default:
+	}
+}
+
+// ==================== PROPOSTAS ====================
+
+// Propor adiciona uma operação ao log replicado e aguarda até que ela seja confirmada
+// e aplicada localmente. Em um seguidor, a proposta é encaminhada ao líder.
+// Retorna o índice da entrada no log.
+func (m *Manager) Propor(tipo string, dados interface{}) (int64, error) {
+	dadosJSON, err := json.Marshal(dados)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao serializar entrada %s: %v", tipo, err)
+	}
+
+	m.mutex.Lock()
+	if m.estado != ESTADO_LIDER {
+		lider := m.LiderAtual
+		m.mutex.Unlock()
+		if lider == "" {
+			return 0, fmt.Errorf("nenhum líder eleito no momento")
+		}
+		return m.encaminharProposta(lider, tipo, dadosJSON)
+	}
+
+	entrada := EntradaLog{Termo: m.TermoAtual, Indice: m.ultimoIndice() + 1, Tipo: tipo, Dados: dadosJSON}
+	m.log = append(m.log, entrada)
+	if err := m.anexarLog([]EntradaLog{entrada}); err != nil {
+		m.log = m.log[:len(m.log)-1]
+		m.mutex.Unlock()
+		return 0, fmt.Errorf("entrada %s não gravada: %v", tipo, err)
+	}
+	ch := make(chan error, 1)
+	m.aguardandoCommit[entrada.Indice] = propostaPendente{termo: entrada.Termo, ch: ch}
+	m.mutex.Unlock()
+
+	m.replicarParaTodos()
+
+	select {
+	case err := <-ch:
+		if err != nil {
+			return 0, err
+		}
+		return entrada.Indice, nil
+	case <-time.After(PROPOSTA_TIMEOUT):
+		m.mutex.Lock()
+		delete(m.aguardandoCommit, entrada.Indice)
+		m.mutex.Unlock()
+		return 0, fmt.Errorf("timeout aguardando confirmação da entrada %d (%s)", entrada.Indice, tipo)
+	}
+}
+
+func (m *Manager) encaminharProposta(lider, tipo string, dados json.RawMessage) (int64, error) {
+	corpo, _ := json.Marshal(map[string]interface{}{"tipo": tipo, "dados": dados})
+	req, err := http.NewRequest("POST", fmt.Sprintf("http://%s/raft/propor", lider), bytes.NewBuffer(corpo))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao criar proposta para o líder: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+seguranca.GenerateJWT(m.servidor.GetMeuEndereco()))
+
+	client := &http.Client{Timeout: PROPOSTA_TIMEOUT + 2*time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao encaminhar proposta ao líder %s: %v", lider, err)
+	}
+	defer resp.Body.Close()
+
+	var resultado struct {
+		Indice int64  `json:"indice"`
+		Erro   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&resultado); err != nil {
+		return 0, fmt.Errorf("erro ao decodificar resposta do líder: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("líder %s recusou a proposta: %s", lider, resultado.Erro)
+	}
+	return resultado.Indice, nil
+}
+
+// ==================== AUXILIARES ====================
+
+func (m *Manager) postRaft(url string, corpo interface{}, resposta interface{}) error {
+	jsonData, err := json.Marshal(corpo)
+	if err != nil {
+		return err
+	}
+	resp, err := clienteRaft.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(resposta)
+}
+
+// peersRaft retorna os demais votantes: os membros configurados em PEERS, exceto este servidor.
+// O conjunto é fixo, então a maioria não muda quando a descoberta encontra (ou perde) servidores.
+// Assume que m.mutex já está bloqueado.
+func (m *Manager) peersRaft() []string {
+	meuEndereco := m.servidor.GetMeuEndereco()
+	peers := make([]string, 0, len(m.peersConfigurados))
+	for _, addr := range m.peersConfigurados {
+		if addr != meuEndereco {
+			peers = append(peers, addr)
+		}
+	}
+	return peers
+}
+
+// maioria é o número de votos (ou réplicas) que decide uma eleição ou confirma uma entrada,
+// contando este servidor.
+func (m *Manager) maioria() int {
+	return (len(m.peersRaft())+1)/2 + 1
+}
+
+// indiceBase é o índice da última entrada compactada no snapshot (0 sem snapshot); o log em memória
+// começa nela. Assume que m.mutex já está bloqueado.
+func (m *Manager) indiceBase() int64 {
+	return m.log[0].Indice
+}
+
+// entrada retorna a entrada de índice informado, que precisa estar entre indiceBase e ultimoIndice.
+func (m *Manager) entrada(indice int64) EntradaLog {
+	return m.log[indice-m.indiceBase()]
+}
+
+func (m *Manager) ultimoIndice() int64 {
+	return m.log[len(m.log)-1].Indice
+}
+
+func (m *Manager) ultimoTermo() int64 {
+	return m.log[len(m.log)-1].Termo
+}
+
+// reiniciarPrazoEleicao sorteia um novo prazo para evitar eleições simultâneas. Assume que m.mutex já está bloqueado.
+func (m *Manager) reiniciarPrazoEleicao() {
+	intervalo := ELEICAO_TIMEOUT_MAX - ELEICAO_TIMEOUT_MIN
+	m.prazoEleicao = time.Now().Add(ELEICAO_TIMEOUT_MIN + time.Duration(rand.Int63n(int64(intervalo))))
+}
+
+// persistirEstado grava termo e voto em disco e só retorna depois do fsync do arquivo e do
+// diretório (o rename só é durável com o diretório sincronizado). Assume que m.mutex já está bloqueado.
+func (m *Manager) persistirEstado() error {
+	if m.caminhoEstado == "" {
+		return nil
+	}
+	dados, err := json.Marshal(estadoPersistido{TermoAtual: m.TermoAtual, VotouEm: m.votouEm})
+	if err != nil {
+		return fmt.Errorf("erro ao serializar estado: %v", err)
+	}
+	if err := substituirArquivo(m.caminhoEstado, dados); err != nil {
+		return fmt.Errorf("erro ao gravar estado: %v", err)
+	}
+	return nil
+}
+
+// anexarLog acrescenta as entradas ao fim do arquivo do log, uma por linha, e faz fsync. Se a
+// gravação falhar, o arquivo volta ao tamanho anterior. Assume que m.mutex já está bloqueado.
+func (m *Manager) anexarLog(entradas []EntradaLog) error {
+	if m.caminhoLog == "" {
+		return nil
+	}
+	if m.arquivoLog == nil {
+		if err := m.abrirLog(); err != nil {
+			return err
+		}
+	}
+	var linhas bytes.Buffer
+	codificador := json.NewEncoder(&linhas)
+	for _, entrada := range entradas {
+		if err := codificador.Encode(entrada); err != nil {
+			return fmt.Errorf("erro ao serializar entrada %d: %v", entrada.Indice, err)
+		}
+	}
+	info, err := m.arquivoLog.Stat()
+	if err != nil {
+		return fmt.Errorf("erro ao ler tamanho do log: %v", err)
+	}
+	if _, err := m.arquivoLog.Write(linhas.Bytes()); err != nil {
+		m.arquivoLog.Truncate(info.Size())
+		return fmt.Errorf("erro ao gravar entradas: %v", err)
+	}
+	if err := m.arquivoLog.Sync(); err != nil {
+		m.arquivoLog.Truncate(info.Size())
+		return fmt.Errorf("erro ao sincronizar log: %v", err)
+	}
+	return nil
+}
+
+// reescreverLog substitui o arquivo do log pelas entradas em memória após a base. Usado só quando o
+// log perde entradas do fim (conflito com o líder) ou do início (compactação). Assume que m.mutex
+// já está bloqueado.
+func (m *Manager) reescreverLog() error {
+	if m.caminhoLog == "" {
+		return nil
+	}
+	var linhas bytes.Buffer
+	codificador := json.NewEncoder(&linhas)
+	for _, entrada := range m.log[1:] {
+		if err := codificador.Encode(entrada); err != nil {
+			return fmt.Errorf("erro ao serializar entrada %d: %v", entrada.Indice, err)
+		}
+	}
+	if m.arquivoLog != nil {
+		m.arquivoLog.Close()
+		m.arquivoLog = nil
+	}
+	if err := substituirArquivo(m.caminhoLog, linhas.Bytes()); err != nil {
+		return fmt.Errorf("erro ao reescrever log: %v", err)
+	}
+	return m.abrirLog()
+}
+
+// abrirLog abre o arquivo do log para acréscimo, criando-o (e sincronizando o diretório) se preciso.
+func (m *Manager) abrirLog() error {
+	diretorio := filepath.Dir(m.caminhoLog)
+	if err := os.MkdirAll(diretorio, 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de dados: %v", err)
+	}
+	_, errExiste := os.Stat(m.caminhoLog)
+	arquivo, err := os.OpenFile(m.caminhoLog, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir log: %v", err)
+	}
+	if os.IsNotExist(errExiste) {
+		if err := sincronizarDiretorio(diretorio); err != nil {
+			arquivo.Close()
+			return fmt.Errorf("erro ao sincronizar diretório de dados: %v", err)
+		}
+	}
+	m.arquivoLog = arquivo
+	return nil
+}
+
+// substituirArquivo grava dados num arquivo temporário e o renomeia sobre caminho, com fsync do
+// arquivo e do diretório.
+func substituirArquivo(caminho string, dados []byte) error {
+	diretorio := filepath.Dir(caminho)
+	if err := os.MkdirAll(diretorio, 0755); err != nil {
+		return err
+	}
+	tmp := caminho + ".tmp"
+	if err := gravarSincronizado(tmp, dados); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, caminho); err != nil {
+		return err
+	}
+	return sincronizarDiretorio(diretorio)
+}
+
+// gravarSincronizado escreve o arquivo e faz fsync antes de fechá-lo.
+func gravarSincronizado(caminho string, dados []byte) error {
+	arquivo, err := os.OpenFile(caminho, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := arquivo.Write(dados); err != nil {
+		arquivo.Close()
+		return err
+	}
+	if err := arquivo.Sync(); err != nil {
+		arquivo.Close()
+		return err
+	}
+	return arquivo.Close()
+}
+
+func sincronizarDiretorio(diretorio string) error {
+	d, err := os.Open(diretorio)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// carregarEstado restaura termo, voto, snapshot e log. Uma última linha incompleta no log (queda no
+// meio de uma gravação) é descartada e o arquivo é reescrito sem ela.
+func (m *Manager) carregarEstado() error {
+	var estado estadoPersistido
+	dados, err := os.ReadFile(m.caminhoEstado)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("erro ao ler estado do raft: %v", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(dados, &estado); err != nil {
+			return fmt.Errorf("erro ao decodificar estado do raft: %v", err)
+		}
+	}
+	if err := m.carregarSnapshot(); err != nil {
+		return err
+	}
+
+	reescrever := false
+	entradas := estado.Log
+	if len(entradas) > 0 {
+		if entradas[0].Indice != 0 {
+			return fmt.Errorf("log do raft sem a entrada sentinela")
+		}
+		entradas = entradas[1:]
+		reescrever = true
+	} else {
+		entradas, reescrever, err = lerLog(m.caminhoLog)
+		if err != nil {
+			return err
+		}
+	}
+	for _, entrada := range entradas {
+		if entrada.Indice <= m.indiceBase() {
+			continue // Já compactada no snapshot
+		}
+		if entrada.Indice != m.ultimoIndice()+1 {
+			log.Printf("[RAFT] ⚠ Log em disco salta do índice %d para %d; entradas seguintes descartadas", m.ultimoIndice(), entrada.Indice)
+			reescrever = true
+			break
+		}
+		m.log = append(m.log, entrada)
+	}
+	m.TermoAtual = estado.TermoAtual
+	m.votouEm = estado.VotouEm
+
+	if reescrever {
+		if err := m.reescreverLog(); err != nil {
+			return err
+		}
+	}
+	if len(estado.Log) > 0 {
+		// Migra o formato antigo: com o log já no próprio arquivo, ele sai do estado
+		if err := m.persistirEstado(); err != nil {
+			return err
+		}
+	}
+	log.Printf("[RAFT] Estado restaurado: termo %d, snapshot até %d, %d entradas no log", m.TermoAtual, m.indiceBase(), m.ultimoIndice())
+	return nil
+}
+
+// lerLog lê as entradas do arquivo do log. incompleto indica que a última linha foi descartada.
+func lerLog(caminho string) (entradas []EntradaLog, incompleto bool, err error) {
+	arquivo, err := os.Open(caminho)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("erro ao ler log do raft: %v", err)
+	}
+	defer arquivo.Close()
+
+	leitor := bufio.NewReader(arquivo)
+	for {
+		linha, err := leitor.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return entradas, len(linha) > 0, nil
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("erro ao ler log do raft: %v", err)
+		}
+		var entrada EntradaLog
+		if err := json.Unmarshal(linha, &entrada); err != nil {
+			log.Printf("[RAFT] ⚠ Linha inválida no log em disco descartada com as seguintes: %v", err)
+			return entradas, true, nil
+		}
+		entradas = append(entradas, entrada)
+	}
+}
+
+// lerPeersConfigurados lê a lista de membros do cluster da variável PEERS. Todos os servidores
+// devem receber a mesma lista (incluindo o próprio endereço); sem PEERS, o servidor é um cluster de um só.
+func lerPeersConfigurados() []string {
+	peers := make([]string, 0)
+	for _, addr := range strings.Split(os.Getenv("PEERS"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			peers = append(peers, addr)
+		}
+	}
+	return peers
+}
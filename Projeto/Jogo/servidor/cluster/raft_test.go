@@ -0,0 +1,370 @@
+package cluster
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const enderecoTeste = "s1:8080"
+
+// servidorFalso conta as entradas aplicadas; o snapshot é essa contagem
+type servidorFalso struct {
+	aplicadas int
+}
+
+func (s *servidorFalso) GetMeuEndereco() string { return enderecoTeste }
+
+func (s *servidorFalso) AplicarEntradaLog(entrada EntradaLog) { s.aplicadas++ }
+
+func (s *servidorFalso) GerarSnapshot() (json.RawMessage, error) {
+	return json.Marshal(s.aplicadas)
+}
+
+func (s *servidorFalso) RestaurarSnapshot(estado json.RawMessage) error {
+	return json.Unmarshal(estado, &s.aplicadas)
+}
+
+// managerTeste cria um Manager de um cluster de um só servidor com os dados em dir, sem iniciar o Run
+func managerTeste(t *testing.T, dir string) (*Manager, *servidorFalso) {
+	t.Helper()
+	t.Setenv("DATA_DIR", dir)
+	t.Setenv("PEERS", "")
+	servidor := &servidorFalso{}
+	m := NewManager(servidor)
+	t.Cleanup(func() {
+		if m.arquivoLog != nil {
+			m.arquivoLog.Close()
+		}
+	})
+	return m, servidor
+}
+
+// entradas gera as entradas de 'de' até 'ate', todas do termo dado
+func entradas(de, ate, termo int64) []EntradaLog {
+	lista := []EntradaLog{}
+	for i := de; i <= ate; i++ {
+		lista = append(lista, EntradaLog{Termo: termo, Indice: i, Tipo: "TESTE"})
+	}
+	return lista
+}
+
+func gravarArquivo(t *testing.T, caminho, conteudo string) {
+	t.Helper()
+	if err := os.WriteFile(caminho, []byte(conteudo), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// linhasLog gera o conteúdo do arquivo do log com as entradas informadas
+func linhasLog(lista []EntradaLog) string {
+	var conteudo strings.Builder
+	for _, entrada := range lista {
+		linha, _ := json.Marshal(entrada)
+		conteudo.Write(linha)
+		conteudo.WriteString("\n")
+	}
+	return conteudo.String()
+}
+
+func contarLinhas(t *testing.T, caminho string) int {
+	t.Helper()
+	conteudo, err := os.ReadFile(caminho)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return strings.Count(string(conteudo), "\n")
+}
+
+func TestProcessarPedidoVoto(t *testing.T) {
+	casos := []struct {
+		nome      string
+		votouEm   string
+		req       RequisicaoVoto
+		concede   bool
+		termo     int64
+		votoSalvo string
+	}{
+		{"termo antigo", "", RequisicaoVoto{Candidato: "b", Termo: 1, UltimoIndiceLog: 9, UltimoTermoLog: 2}, false, 2, ""},
+		{"log atualizado", "", RequisicaoVoto{Candidato: "b", Termo: 3, UltimoIndiceLog: 2, UltimoTermoLog: 2}, true, 3, "b"},
+		{"último termo do log menor", "", RequisicaoVoto{Candidato: "b", Termo: 3, UltimoIndiceLog: 9, UltimoTermoLog: 1}, false, 3, ""},
+		{"mesmo termo com log mais curto", "", RequisicaoVoto{Candidato: "b", Termo: 3, UltimoIndiceLog: 1, UltimoTermoLog: 2}, false, 3, ""},
+		{"já votou em outro no termo", "c", RequisicaoVoto{Candidato: "b", Termo: 2, UltimoIndiceLog: 2, UltimoTermoLog: 2}, false, 2, "c"},
+		{"mesmo candidato pede de novo", "b", RequisicaoVoto{Candidato: "b", Termo: 2, UltimoIndiceLog: 2, UltimoTermoLog: 2}, true, 2, "b"},
+		{"termo novo libera o voto", "c", RequisicaoVoto{Candidato: "b", Termo: 3, UltimoIndiceLog: 2, UltimoTermoLog: 2}, true, 3, "b"},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			dir := t.TempDir()
+			m, _ := managerTeste(t, dir)
+			m.TermoAtual, m.votouEm = 2, c.votouEm
+			m.log = append(m.log, EntradaLog{Termo: 1, Indice: 1}, EntradaLog{Termo: 2, Indice: 2})
+
+			resp := m.ProcessarPedidoVoto(c.req)
+			if resp.VotoConcedido != c.concede || resp.Termo != c.termo {
+				t.Errorf("voto %v no termo %d, esperado %v no termo %d", resp.VotoConcedido, resp.Termo, c.concede, c.termo)
+			}
+
+			// Termo e voto sobrevivem ao reinício
+			reiniciado, _ := managerTeste(t, dir)
+			if c.termo > 2 || c.concede {
+				if reiniciado.TermoAtual != c.termo || reiniciado.votouEm != c.votoSalvo {
+					t.Errorf("depois do reinício: termo %d e voto '%s', esperado %d e '%s'", reiniciado.TermoAtual, reiniciado.votouEm, c.termo, c.votoSalvo)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessarAppendEntries(t *testing.T) {
+	dir := t.TempDir()
+	m, _ := managerTeste(t, dir)
+
+	passos := []struct {
+		nome         string
+		req          RequisicaoAppendEntries
+		sucesso      bool
+		ultimoIndice int64 // da resposta
+		logAte       int64 // último índice do log local
+		ultimoTermo  int64
+		commit       int64
+	}{
+		{"heartbeat", RequisicaoAppendEntries{Termo: 1, Lider: "l1"}, true, 0, 0, 0, 0},
+		{"entradas novas", RequisicaoAppendEntries{Termo: 1, Lider: "l1", Entradas: entradas(1, 3, 1), IndiceCommitLider: 1}, true, 3, 3, 1, 1},
+		{"entrada anterior ausente", RequisicaoAppendEntries{Termo: 1, Lider: "l1", IndiceLogAnterior: 5, TermoLogAnterior: 1, Entradas: entradas(6, 6, 1)}, false, 3, 3, 1, 1},
+		{"entrada anterior de outro termo", RequisicaoAppendEntries{Termo: 2, Lider: "l2", IndiceLogAnterior: 3, TermoLogAnterior: 2, Entradas: entradas(4, 4, 2)}, false, 2, 3, 1, 1},
+		{"líder de termo antigo", RequisicaoAppendEntries{Termo: 1, Lider: "l1", IndiceLogAnterior: 3, TermoLogAnterior: 1, Entradas: entradas(4, 4, 1)}, false, 3, 3, 1, 1},
+		{"conflito trunca o log", RequisicaoAppendEntries{Termo: 2, Lider: "l2", IndiceLogAnterior: 1, TermoLogAnterior: 1, Entradas: entradas(2, 2, 2), IndiceCommitLider: 3}, true, 2, 2, 2, 2},
+		{"entradas repetidas", RequisicaoAppendEntries{Termo: 2, Lider: "l2", Entradas: append(entradas(1, 1, 1), entradas(2, 2, 2)...), IndiceCommitLider: 2}, true, 2, 2, 2, 2},
+		{"commit limitado às entradas recebidas", RequisicaoAppendEntries{Termo: 2, Lider: "l2", IndiceLogAnterior: 2, TermoLogAnterior: 2, Entradas: entradas(3, 4, 2), IndiceCommitLider: 9}, true, 4, 4, 2, 4},
+	}
+	for _, p := range passos {
+		resp := m.ProcessarAppendEntries(p.req)
+		if resp.Sucesso != p.sucesso || resp.UltimoIndice != p.ultimoIndice {
+			t.Errorf("%s: sucesso %v e último índice %d na resposta, esperado %v e %d", p.nome, resp.Sucesso, resp.UltimoIndice, p.sucesso, p.ultimoIndice)
+		}
+		if m.ultimoIndice() != p.logAte || m.ultimoTermo() != p.ultimoTermo || m.indiceCommit != p.commit {
+			t.Errorf("%s: log até %d (termo %d), commit %d; esperado %d (termo %d), commit %d",
+				p.nome, m.ultimoIndice(), m.ultimoTermo(), m.indiceCommit, p.logAte, p.ultimoTermo, p.commit)
+		}
+	}
+	if m.LiderAtual != "l2" || m.estado != ESTADO_SEGUIDOR {
+		t.Errorf("líder '%s' e estado %s, esperado l2 e %s", m.LiderAtual, m.estado, ESTADO_SEGUIDOR)
+	}
+
+	// O log reescrito depois do conflito e os acréscimos seguintes estão em disco
+	reiniciado, _ := managerTeste(t, dir)
+	esperado := []int64{0, 1, 2, 2, 2} // termos dos índices 0 a 4
+	if len(reiniciado.log) != len(esperado) {
+		t.Fatalf("%d entradas depois do reinício, esperado %d", len(reiniciado.log), len(esperado))
+	}
+	for i, termo := range esperado {
+		if e := reiniciado.log[i]; e.Indice != int64(i) || e.Termo != termo {
+			t.Errorf("entrada %d depois do reinício: índice %d, termo %d; esperado termo %d", i, e.Indice, e.Termo, termo)
+		}
+	}
+	if reiniciado.TermoAtual != 2 {
+		t.Errorf("termo %d depois do reinício, esperado 2", reiniciado.TermoAtual)
+	}
+}
+
+func TestLerLog(t *testing.T) {
+	tres := linhasLog(entradas(1, 3, 1))
+	casos := []struct {
+		nome       string
+		existe     bool
+		conteudo   string
+		entradas   int
+		incompleto bool
+	}{
+		{"arquivo inexistente", false, "", 0, false},
+		{"arquivo vazio", true, "", 0, false},
+		{"linhas completas", true, tres, 3, false},
+		{"última linha truncada", true, tres + `{"termo":1,"indice":4,"ti`, 3, true},
+		{"linha inválida no meio", true, linhasLog(entradas(1, 1, 1)) + "lixo\n" + linhasLog(entradas(2, 2, 1)), 1, true},
+	}
+	for _, c := range casos {
+		caminho := filepath.Join(t.TempDir(), "raft.log")
+		if c.existe {
+			gravarArquivo(t, caminho, c.conteudo)
+		}
+		lidas, incompleto, err := lerLog(caminho)
+		if err != nil {
+			t.Errorf("%s: %v", c.nome, err)
+			continue
+		}
+		if len(lidas) != c.entradas || incompleto != c.incompleto {
+			t.Errorf("%s: %d entradas (incompleto=%v), esperado %d (incompleto=%v)", c.nome, len(lidas), incompleto, c.entradas, c.incompleto)
+		}
+	}
+}
+
+func TestCarregarEstado(t *testing.T) {
+	casos := []struct {
+		nome         string
+		estado       string
+		log          string
+		snapshot     string
+		termo        int64
+		base         int64
+		ultimoIndice int64
+		linhas       int // linhas do arquivo do log depois de carregar
+	}{
+		{"sem arquivos", "", "", "", 0, 0, 0, -1},
+		{"log contínuo", `{"termo_atual":2,"votou_em":"b"}`, linhasLog(entradas(1, 3, 1)), "", 2, 0, 3, 3},
+		{"salto no log", `{"termo_atual":2}`, linhasLog(append(entradas(1, 2, 1), entradas(4, 5, 1)...)), "", 2, 0, 2, 2},
+		{"linha truncada", `{"termo_atual":2}`, linhasLog(entradas(1, 2, 1)) + `{"termo":1,`, "", 2, 0, 2, 2},
+		{"entradas já no snapshot", `{"termo_atual":2}`, linhasLog(entradas(1, 4, 1)), `{"indice":2,"termo":1,"estado":2}`, 2, 2, 4, 4},
+		{"formato antigo com o log no estado", `{"termo_atual":3,"log":[{"termo":0,"indice":0},{"termo":1,"indice":1},{"termo":3,"indice":2}]}`, "", "", 3, 0, 2, 2},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			dir := t.TempDir()
+			prefixo := filepath.Join(dir, "raft_s1_8080")
+			if c.estado != "" {
+				gravarArquivo(t, prefixo+".json", c.estado)
+			}
+			if c.log != "" {
+				gravarArquivo(t, prefixo+".log", c.log)
+			}
+			if c.snapshot != "" {
+				gravarArquivo(t, prefixo+".snapshot.json", c.snapshot)
+			}
+
+			m, _ := managerTeste(t, dir)
+			if m.TermoAtual != c.termo || m.indiceBase() != c.base || m.ultimoIndice() != c.ultimoIndice {
+				t.Errorf("termo %d, base %d, último índice %d; esperado %d, %d, %d",
+					m.TermoAtual, m.indiceBase(), m.ultimoIndice(), c.termo, c.base, c.ultimoIndice)
+			}
+			if m.indiceCommit != c.base || m.ultimoAplicado != c.base {
+				t.Errorf("commit %d e aplicado %d, esperado o snapshot %d", m.indiceCommit, m.ultimoAplicado, c.base)
+			}
+			if c.linhas >= 0 {
+				if linhas := contarLinhas(t, m.caminhoLog); linhas != c.linhas {
+					t.Errorf("%d linhas no log em disco, esperado %d", linhas, c.linhas)
+				}
+			}
+			// O estado migrado não guarda mais o log
+			if dados, err := os.ReadFile(m.caminhoEstado); err == nil && strings.Contains(string(dados), `"log"`) {
+				t.Errorf("estado ainda contém o log: %s", dados)
+			}
+		})
+	}
+}
+
+func TestCompactarLog(t *testing.T) {
+	casos := []struct {
+		nome      string
+		entradas  int64
+		commit    int64
+		base      int64
+		restantes int // entradas depois da base
+	}{
+		{"abaixo do limite", ENTRADAS_POR_SNAPSHOT + 5, ENTRADAS_POR_SNAPSHOT - 1, 0, ENTRADAS_POR_SNAPSHOT + 5},
+		{"todas aplicadas", ENTRADAS_POR_SNAPSHOT, ENTRADAS_POR_SNAPSHOT, ENTRADAS_POR_SNAPSHOT, 0},
+		{"entradas não confirmadas ficam no log", ENTRADAS_POR_SNAPSHOT + 10, ENTRADAS_POR_SNAPSHOT + 5, ENTRADAS_POR_SNAPSHOT + 5, 5},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			dir := t.TempDir()
+			m, servidor := managerTeste(t, dir)
+			resp := m.ProcessarAppendEntries(RequisicaoAppendEntries{Termo: 1, Lider: "l1", Entradas: entradas(1, c.entradas, 1), IndiceCommitLider: c.commit})
+			if !resp.Sucesso {
+				t.Fatalf("AppendEntries recusado: %+v", resp)
+			}
+			for m.aplicarProximaEntrada() {
+			}
+			m.compactarLog()
+
+			if servidor.aplicadas != int(c.commit) {
+				t.Errorf("%d entradas aplicadas, esperado %d", servidor.aplicadas, c.commit)
+			}
+			if m.indiceBase() != c.base || len(m.log)-1 != c.restantes {
+				t.Errorf("base %d com %d entradas, esperado %d com %d", m.indiceBase(), len(m.log)-1, c.base, c.restantes)
+			}
+			if linhas := contarLinhas(t, m.caminhoLog); linhas != c.restantes {
+				t.Errorf("%d linhas no log em disco, esperado %d", linhas, c.restantes)
+			}
+
+			// Ao reiniciar, o servidor recebe o estado do snapshot e o log continua de onde parou
+			reiniciado, servidorReiniciado := managerTeste(t, dir)
+			reiniciado.restaurarSnapshotCarregado()
+			if reiniciado.indiceBase() != c.base || reiniciado.ultimoIndice() != c.entradas {
+				t.Errorf("depois do reinício: base %d e último índice %d, esperado %d e %d", reiniciado.indiceBase(), reiniciado.ultimoIndice(), c.base, c.entradas)
+			}
+			if servidorReiniciado.aplicadas != int(c.base) {
+				t.Errorf("depois do reinício: estado com %d entradas, esperado %d", servidorReiniciado.aplicadas, c.base)
+			}
+		})
+	}
+}
+
+func TestProcessarSnapshot(t *testing.T) {
+	m, servidor := managerTeste(t, t.TempDir())
+	m.ProcessarAppendEntries(RequisicaoAppendEntries{Termo: 2, Lider: "l1", Entradas: entradas(1, 3, 2), IndiceCommitLider: 3})
+	for m.aplicarProximaEntrada() {
+	}
+
+	passos := []struct {
+		nome      string
+		req       RequisicaoSnapshot
+		sucesso   bool
+		base      int64
+		aplicadas int
+	}{
+		{"líder de termo antigo", RequisicaoSnapshot{Termo: 1, Lider: "l0", Indice: 9, TermoIndice: 1, Estado: json.RawMessage("9")}, false, 0, 3},
+		{"snapshot já aplicado", RequisicaoSnapshot{Termo: 2, Lider: "l1", Indice: 2, TermoIndice: 2, Estado: json.RawMessage("2")}, true, 0, 3},
+		{"snapshot à frente do log", RequisicaoSnapshot{Termo: 2, Lider: "l1", Indice: 10, TermoIndice: 2, Estado: json.RawMessage("10")}, true, 10, 10},
+	}
+	for _, p := range passos {
+		resp := m.ProcessarSnapshot(p.req)
+		if resp.Sucesso != p.sucesso {
+			t.Errorf("%s: sucesso %v, esperado %v", p.nome, resp.Sucesso, p.sucesso)
+		}
+		if m.indiceBase() != p.base || servidor.aplicadas != p.aplicadas {
+			t.Errorf("%s: base %d e estado com %d entradas, esperado %d e %d", p.nome, m.indiceBase(), servidor.aplicadas, p.base, p.aplicadas)
+		}
+	}
+	if m.indiceCommit != 10 || m.ultimoAplicado != 10 || len(m.log) != 1 {
+		t.Errorf("commit %d, aplicado %d, %d entradas no log; esperado 10, 10 e 1", m.indiceCommit, m.ultimoAplicado, len(m.log))
+	}
+
+	// Um AppendEntries seguinte continua a partir da base
+	resp := m.ProcessarAppendEntries(RequisicaoAppendEntries{Termo: 2, Lider: "l1", IndiceLogAnterior: 10, TermoLogAnterior: 2, Entradas: entradas(11, 11, 2)})
+	if !resp.Sucesso || m.ultimoIndice() != 11 {
+		t.Errorf("AppendEntries depois do snapshot: sucesso %v, último índice %d", resp.Sucesso, m.ultimoIndice())
+	}
+}
+
+func TestAvancarCommit(t *testing.T) {
+	casos := []struct {
+		nome       string
+		peers      []string
+		termos     []int64 // termos das entradas 1..n
+		replicados map[string]int64
+		commit     int64
+	}{
+		{"servidor único", nil, []int64{2, 2}, nil, 2},
+		{"maioria de três", []string{enderecoTeste, "b", "c"}, []int64{2, 2, 2}, map[string]int64{"b": 2}, 2},
+		{"sem maioria", []string{enderecoTeste, "b", "c", "d", "e"}, []int64{2, 2}, map[string]int64{"b": 2}, 0},
+		{"termo anterior só é confirmado indiretamente", []string{enderecoTeste, "b", "c"}, []int64{1, 1}, map[string]int64{"b": 2}, 0},
+		{"entrada do termo atual confirma as anteriores", []string{enderecoTeste, "b", "c"}, []int64{1, 2}, map[string]int64{"b": 2, "c": 1}, 2},
+	}
+	for _, c := range casos {
+		m, _ := managerTeste(t, t.TempDir())
+		m.peersConfigurados = c.peers
+		m.TermoAtual, m.estado = 2, ESTADO_LIDER
+		for i, termo := range c.termos {
+			m.log = append(m.log, EntradaLog{Termo: termo, Indice: int64(i + 1)})
+		}
+		for peer, indice := range c.replicados {
+			m.indiceReplicado[peer] = indice
+		}
+		m.avancarCommit()
+		if m.indiceCommit != c.commit {
+			t.Errorf("%s: commit %d, esperado %d", c.nome, m.indiceCommit, c.commit)
+		}
+	}
+}
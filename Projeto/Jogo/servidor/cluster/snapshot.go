@@ -0,0 +1,203 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// Compactação do log: quando as entradas aplicadas passam de ENTRADAS_POR_SNAPSHOT, o estado do
+// servidor (ServidorInterface.GerarSnapshot) é gravado em caminhoSnapshot e as entradas cobertas
+// por ele saem do log. O log em memória passa a começar na última entrada do snapshot (log[0]).
+// Um seguidor que precise de entradas já compactadas recebe o snapshot do líder (ProcessarSnapshot).
+
+// RequisicaoSnapshot é o InstallSnapshot do Raft: o estado do servidor até a entrada Indice.
+type RequisicaoSnapshot struct {
+	Termo       int64           `json:"termo"`
+	Lider       string          `json:"lider"`
+	Indice      int64           `json:"indice"`       // Última entrada incluída no snapshot
+	TermoIndice int64           `json:"termo_indice"` // Termo dessa entrada
+	Estado      json.RawMessage `json:"estado"`
+}
+
+type RespostaSnapshot struct {
+	Termo   int64 `json:"termo"`
+	Sucesso bool  `json:"sucesso"`
+}
+
+// snapshotPersistido é o conteúdo de caminhoSnapshot.
+type snapshotPersistido struct {
+	Indice int64           `json:"indice"`
+	Termo  int64           `json:"termo"`
+	Estado json.RawMessage `json:"estado"`
+}
+
+// compactarLog gera um snapshot do estado aplicado e descarta do log as entradas cobertas por ele,
+// se já houver ENTRADAS_POR_SNAPSHOT entradas aplicadas desde o último.
+func (m *Manager) compactarLog() {
+	m.mutex.RLock()
+	aplicadas := m.ultimoAplicado - m.indiceBase()
+	m.mutex.RUnlock()
+	if aplicadas < ENTRADAS_POR_SNAPSHOT {
+		return
+	}
+
+	// Com a aplicação bloqueada, o estado gerado corresponde exatamente a ultimoAplicado
+	m.mutexAplicacao.Lock()
+	defer m.mutexAplicacao.Unlock()
+	estado, err := m.servidor.GerarSnapshot()
+	if err != nil {
+		log.Printf("[RAFT_ERRO] Snapshot não gerado: %v", err)
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	indice := m.ultimoAplicado
+	if err := m.gravarSnapshot(indice, m.entrada(indice).Termo, estado); err != nil {
+		log.Printf("[RAFT_ERRO] Snapshot até a entrada %d não gravado: %v", indice, err)
+		return
+	}
+	log.Printf("[RAFT] Log compactado: snapshot até a entrada %d, %d entradas restantes", indice, len(m.log)-1)
+}
+
+// gravarSnapshot grava o snapshot em disco e só então descarta do log as entradas cobertas por ele.
+// As entradas posteriores são mantidas se o log local contiver a entrada indice com o mesmo termo.
+// Se o arquivo do log não puder ser reescrito, o antigo continua válido: ao carregar, as entradas já
+// cobertas pelo snapshot são ignoradas. Assume que m.mutex já está bloqueado.
+func (m *Manager) gravarSnapshot(indice, termo int64, estado json.RawMessage) error {
+	if m.caminhoSnapshot != "" {
+		dados, err := json.Marshal(snapshotPersistido{Indice: indice, Termo: termo, Estado: estado})
+		if err != nil {
+			return fmt.Errorf("erro ao serializar snapshot: %v", err)
+		}
+		if err := substituirArquivo(m.caminhoSnapshot, dados); err != nil {
+			return fmt.Errorf("erro ao gravar snapshot: %v", err)
+		}
+	}
+
+	restantes := []EntradaLog{{Indice: indice, Termo: termo}}
+	if indice >= m.indiceBase() && indice <= m.ultimoIndice() && m.entrada(indice).Termo == termo {
+		restantes = append(restantes, m.log[indice-m.indiceBase()+1:]...)
+	}
+	m.log = restantes
+	m.snapshot = estado
+	if err := m.reescreverLog(); err != nil {
+		log.Printf("[RAFT_ERRO] Log não reescrito após o snapshot até %d: %v", indice, err)
+	}
+	return nil
+}
+
+// carregarSnapshot lê o snapshot gravado, se houver; o estado é entregue ao servidor em Run.
+func (m *Manager) carregarSnapshot() error {
+	dados, err := os.ReadFile(m.caminhoSnapshot)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao ler snapshot do raft: %v", err)
+	}
+	var snapshot snapshotPersistido
+	if err := json.Unmarshal(dados, &snapshot); err != nil {
+		return fmt.Errorf("erro ao decodificar snapshot do raft: %v", err)
+	}
+	m.log = []EntradaLog{{Indice: snapshot.Indice, Termo: snapshot.Termo}}
+	m.snapshot = snapshot.Estado
+	m.indiceCommit = snapshot.Indice
+	m.ultimoAplicado = snapshot.Indice
+	return nil
+}
+
+// restaurarSnapshotCarregado entrega ao servidor o estado do snapshot lido do disco. Chamada em Run,
+// depois de o servidor estar montado e antes de qualquer entrada ser aplicada.
+func (m *Manager) restaurarSnapshotCarregado() {
+	m.mutexAplicacao.Lock()
+	defer m.mutexAplicacao.Unlock()
+	m.mutex.RLock()
+	estado, indice := m.snapshot, m.indiceBase()
+	m.mutex.RUnlock()
+	if estado == nil {
+		return
+	}
+	if err := m.servidor.RestaurarSnapshot(estado); err != nil {
+		log.Printf("[RAFT_ERRO] Snapshot até a entrada %d não restaurado: %v", indice, err)
+		return
+	}
+	log.Printf("[RAFT] Estado do servidor restaurado do snapshot até a entrada %d", indice)
+}
+
+// enviarSnapshot envia o snapshot do líder a um peer cujo log está atrás da base.
+func (m *Manager) enviarSnapshot(peer string, req RequisicaoSnapshot) {
+	var resp RespostaSnapshot
+	if err := m.postRaft(fmt.Sprintf("http://%s/election/snapshot", peer), req, &resp); err != nil {
+		return // Peer fora do ar; tenta de novo no próximo ciclo
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if resp.Termo > m.TermoAtual {
+		m.tornarSeguidor(resp.Termo, "")
+		return
+	}
+	if m.estado != ESTADO_LIDER || m.TermoAtual != req.Termo || !resp.Sucesso {
+		return
+	}
+	if req.Indice > m.indiceReplicado[peer] {
+		m.indiceReplicado[peer] = req.Indice
+	}
+	m.proximoIndice[peer] = m.indiceReplicado[peer] + 1
+	log.Printf("[RAFT] Snapshot até a entrada %d instalado em %s", req.Indice, peer)
+	if m.proximoIndice[peer] <= m.ultimoIndice() {
+		go m.replicarPara(peer)
+	}
+}
+
+// ProcessarSnapshot instala o snapshot enviado pelo líder: o log local passa a começar nele e o
+// estado do servidor é substituído pelo do snapshot.
+func (m *Manager) ProcessarSnapshot(req RequisicaoSnapshot) RespostaSnapshot {
+	m.mutexAplicacao.Lock()
+	defer m.mutexAplicacao.Unlock()
+
+	m.mutex.Lock()
+	resp := RespostaSnapshot{Termo: m.TermoAtual}
+	if req.Termo < m.TermoAtual {
+		m.mutex.Unlock()
+		return resp
+	}
+	errPersistencia := m.tornarSeguidor(req.Termo, req.Lider)
+	m.UltimoHeartbeat = time.Now()
+	resp.Termo = m.TermoAtual
+	if errPersistencia != nil {
+		log.Printf("[RAFT_ERRO] Snapshot de %s recusado: %v", req.Lider, errPersistencia)
+		m.mutex.Unlock()
+		return resp
+	}
+	if req.Indice <= m.ultimoAplicado {
+		// O estado local já inclui essas entradas
+		resp.Sucesso = true
+		m.mutex.Unlock()
+		return resp
+	}
+	if err := m.gravarSnapshot(req.Indice, req.TermoIndice, req.Estado); err != nil {
+		log.Printf("[RAFT_ERRO] Snapshot de %s recusado: %v", req.Lider, err)
+		m.mutex.Unlock()
+		return resp
+	}
+	m.ultimoAplicado = req.Indice
+	if m.indiceCommit < req.Indice {
+		m.indiceCommit = req.Indice
+	}
+	m.mutex.Unlock()
+
+	// A aplicação de entradas continua bloqueada até o estado ser substituído
+	if err := m.servidor.RestaurarSnapshot(req.Estado); err != nil {
+		log.Printf("[RAFT_ERRO] Snapshot de %s gravado, mas não restaurado: %v", req.Lider, err)
+		return resp
+	}
+	log.Printf("[RAFT] Snapshot até a entrada %d recebido de %s", req.Indice, req.Lider)
+	resp.Sucesso = true
+	return resp
+}
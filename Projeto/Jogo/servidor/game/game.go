@@ -3,6 +3,7 @@ package game
 import (
 	"fmt"
 	"jogodistribuido/protocolo"
+	"jogodistribuido/servidor/regras"
 	"jogodistribuido/servidor/seguranca"
 	"jogodistribuido/servidor/tipos"
 	"log"
@@ -93,7 +94,6 @@ func (m *Manager) CriarSala(j1, j2 *tipos.Cliente) {
 		Estado:         "AGUARDANDO_COMPRAS",
 		ServidorHost:   j1.ID,
 		ServidorSombra: j2.ID,
-		Mutex:          sync.Mutex{},
 		CartasJogadas:  make(map[string]tipos.Carta),
 		TurnoDe:        "",
 	}
@@ -159,7 +159,6 @@ func (m *Manager) ProcessarCompraPacote(clienteID string, sala *tipos.Sala) {
 	if m.getClienteLocal(clienteID) != nil {
 		m.gameInterface.PublicarParaCliente(clienteID, msg)
 	} else {
-		sala.Mutex.Lock()
 		if sala.ServidorHost == clienteID {
 			// Notify shadow server
 			m.notificarSombra(sala, msg)
@@ -167,23 +166,18 @@ func (m *Manager) ProcessarCompraPacote(clienteID string, sala *tipos.Sala) {
 			// Notify host server
 			m.notificarHost(sala, msg)
 		}
-		sala.Mutex.Unlock()
 	}
 
 	// Mark as ready and check if both players are ready
-	sala.Mutex.Lock()
 	sala.Prontos[clienteID] = true
-	sala.Mutex.Unlock()
 
 	m.VerificarEIniciarPartidaSeProntos(sala)
 }
 
 // VerificarEIniciarPartidaSeProntos checks if both players are ready and starts the game
 func (m *Manager) VerificarEIniciarPartidaSeProntos(sala *tipos.Sala) {
-	sala.Mutex.Lock()
 	prontos := len(sala.Prontos)
 	totalJogadores := len(sala.Jogadores)
-	sala.Mutex.Unlock()
 
 	if prontos == totalJogadores {
 		log.Printf("Ambos os jogadores prontos, iniciando partida na sala %s", sala.ID)
@@ -195,7 +189,6 @@ func (m *Manager) VerificarEIniciarPartidaSeProntos(sala *tipos.Sala) {
 
 // IniciarPartida starts the game
 func (m *Manager) IniciarPartida(sala *tipos.Sala) {
-	sala.Mutex.Lock()
 	sala.Estado = "EM_JOGO"
 	// Define o primeiro jogador como o dono do turno inicial
 	if len(sala.Jogadores) > 0 {
@@ -205,7 +198,6 @@ func (m *Manager) IniciarPartida(sala *tipos.Sala) {
 	if sala.CartasJogadas == nil {
 		sala.CartasJogadas = make(map[string]tipos.Carta)
 	}
-	sala.Mutex.Unlock()
 
 	// Notify both players
 	msg := protocolo.Mensagem{
@@ -271,13 +263,7 @@ func (m *Manager) ProcessarJogada(salaID, clienteID, cartaID string) {
 		return
 	}
 
-	sala.Mutex.Lock()
-	log.Printf("[GAME_DEBUG] Lock adquirido para sala %s", salaID)
 	log.Printf("[GAME_DEBUG] Estado atual da sala: Estado=%s, TurnoDe=%s, CartasJogadas=%d", sala.Estado, sala.TurnoDe, len(sala.CartasJogadas))
-	defer func() {
-		sala.Mutex.Unlock()
-		log.Printf("[GAME_DEBUG] Lock liberado para sala %s", salaID)
-	}()
 
 	// 1. Validações básicas
 	if sala.Estado != "EM_JOGO" {
@@ -358,24 +344,11 @@ func (m *Manager) ResolverTurno(sala *tipos.Sala) {
 
 	vencedorTurno := ""
 
-	// Comparação baseada no Valor da carta
-	poder1 := c1.Valor
-	poder2 := c2.Valor
-
-	// Mapa de força dos naipes para desempate: Espadas > Copas > Ouros > Paus
-	naipes := map[string]int{"♠": 4, "♥": 3, "♦": 2, "♣": 1}
-
-	if poder1 > poder2 {
+	// Comparação definida pelas regras escolhidas para a sala
+	if regras.Obter(sala.Regras).CompararCartas(c1, c2) > 0 {
 		vencedorTurno = j1.ID
-	} else if poder2 > poder1 {
-		vencedorTurno = j2.ID
 	} else {
-		// Empate no valor: decide pelo naipe
-		if naipes[c1.Naipe] > naipes[c2.Naipe] {
-			vencedorTurno = j1.ID
-		} else {
-			vencedorTurno = j2.ID
-		}
+		vencedorTurno = j2.ID
 	}
 
 	// Limpar mesa para o próximo turno
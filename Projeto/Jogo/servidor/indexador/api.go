@@ -0,0 +1,90 @@
+package indexador
+
+import (
+	"jogodistribuido/servidor/tipos"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+const LIMITE_EVENTOS_PADRAO = 500 // Eventos por resposta de GET /eventos
+
+// StatusIndexador é a resposta de GET /status.
+type StatusIndexador struct {
+	ProximoBloco uint64 `json:"proximo_bloco"`
+	Eventos      int    `json:"eventos"`
+}
+
+// RespostaInventario é a resposta de GET /inventario/:endereco. ProximoBloco indica até onde
+// a resposta está atualizada: só eventos de blocos anteriores a ele foram considerados.
+type RespostaInventario struct {
+	Endereco     string        `json:"endereco"`
+	ProximoBloco uint64        `json:"proximo_bloco"`
+	Cartas       []tipos.Carta `json:"cartas"`
+}
+
+// RegistrarRotas expõe as consultas somente leitura do banco do indexador.
+func RegistrarRotas(router gin.IRouter, banco *Banco) {
+	router.GET("/status", func(c *gin.Context) {
+		c.JSON(http.StatusOK, StatusIndexador{ProximoBloco: banco.ProximoBloco(), Eventos: banco.TotalEventos()})
+	})
+
+	router.GET("/cartas/:id", func(c *gin.Context) {
+		carta, ok := banco.Carta(c.Param("id"))
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "carta não encontrada"})
+			return
+		}
+		c.JSON(http.StatusOK, carta)
+	})
+
+	router.GET("/inventario/:endereco", func(c *gin.Context) {
+		endereco, ok := enderecoParam(c)
+		if !ok {
+			return
+		}
+		// O próximo bloco é lido antes das cartas: a resposta nunca se diz mais nova do que é
+		proximo := banco.ProximoBloco()
+		c.JSON(http.StatusOK, RespostaInventario{Endereco: endereco, ProximoBloco: proximo, Cartas: banco.Inventario(endereco)})
+	})
+
+	router.GET("/pacotes/:endereco", func(c *gin.Context) {
+		if endereco, ok := enderecoParam(c); ok {
+			c.JSON(http.StatusOK, banco.Pacotes(endereco))
+		}
+	})
+
+	router.GET("/trocas/:endereco", func(c *gin.Context) {
+		if endereco, ok := enderecoParam(c); ok {
+			c.JSON(http.StatusOK, banco.Trocas(endereco))
+		}
+	})
+
+	router.GET("/partidas/:endereco", func(c *gin.Context) {
+		if endereco, ok := enderecoParam(c); ok {
+			c.JSON(http.StatusOK, banco.Partidas(endereco))
+		}
+	})
+
+	// GET /eventos?desde_bloco=N&tipo=CartaCriada&limite=100
+	router.GET("/eventos", func(c *gin.Context) {
+		desde, _ := strconv.ParseUint(c.Query("desde_bloco"), 10, 64)
+		limite, err := strconv.Atoi(c.Query("limite"))
+		if err != nil || limite <= 0 || limite > LIMITE_EVENTOS_PADRAO {
+			limite = LIMITE_EVENTOS_PADRAO
+		}
+		c.JSON(http.StatusOK, banco.Eventos(desde, c.Query("tipo"), limite))
+	})
+}
+
+// enderecoParam valida o endereço da rota e o normaliza para o formato gravado no banco.
+func enderecoParam(c *gin.Context) (string, bool) {
+	valor := c.Param("endereco")
+	if !common.IsHexAddress(valor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "endereço inválido"})
+		return "", false
+	}
+	return common.HexToAddress(valor).Hex(), true
+}
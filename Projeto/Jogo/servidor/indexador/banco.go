@@ -0,0 +1,448 @@
+package indexador
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"jogodistribuido/servidor/tipos"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const BLOCOS_RECENTES = 128 // Hashes de blocos guardados para detectar reorganizações da cadeia
+
+// Tipos de evento do contrato GameEconomy acompanhados pelo indexador
+const (
+	EVENTO_CARTA_CRIADA          = "CartaCriada"
+	EVENTO_CARTA_TRANSFERIDA     = "CartaTransferida"
+	EVENTO_CARTA_QUEIMADA        = "CartaQueimada"
+	EVENTO_PACOTE_COMPRADO       = "PacoteComprado"
+	EVENTO_PROPOSTA_TROCA_CRIADA = "PropostaTrocaCriada"
+	EVENTO_TROCA_EXECUTADA       = "TrocaExecutada"
+	EVENTO_PARTIDA_REGISTRADA    = "PartidaRegistrada"
+)
+
+// Evento é um log do contrato já decodificado. Só os campos do tipo do evento são preenchidos.
+type Evento struct {
+	Tipo      string `json:"tipo"`
+	Bloco     uint64 `json:"bloco"`
+	HashBloco string `json:"hash_bloco"`
+	HashTx    string `json:"hash_tx"`
+	IndiceLog uint   `json:"indice_log"`
+
+	TokenID       string   `json:"token_id,omitempty"`
+	TokenIDs      []string `json:"token_ids,omitempty"`
+	Proprietario  string   `json:"proprietario,omitempty"`
+	De            string   `json:"de,omitempty"`
+	Para          string   `json:"para,omitempty"`
+	PropostaID    string   `json:"proposta_id,omitempty"`
+	Jogador1      string   `json:"jogador1,omitempty"`
+	Jogador2      string   `json:"jogador2,omitempty"`
+	CartaJogador1 string   `json:"carta_jogador1,omitempty"`
+	CartaJogador2 string   `json:"carta_jogador2,omitempty"`
+	Vencedor      string   `json:"vencedor,omitempty"`
+	Nome          string   `json:"nome,omitempty"`
+	Naipe         string   `json:"naipe,omitempty"`
+	Raridade      string   `json:"raridade,omitempty"`
+	Valor         int      `json:"valor,omitempty"`
+	Timestamp     int64    `json:"timestamp,omitempty"`
+	HashLog       string   `json:"hash_log,omitempty"`
+}
+
+// BlocoIndexado guarda o hash de um bloco já processado, comparado depois com a cadeia canônica.
+type BlocoIndexado struct {
+	Numero uint64 `json:"numero"`
+	Hash   string `json:"hash"`
+}
+
+// CartaIndexada é a carta com seu proprietário atual segundo os eventos.
+type CartaIndexada struct {
+	tipos.Carta
+	Proprietario string `json:"proprietario"`
+	BlocoCriacao uint64 `json:"bloco_criacao"`
+}
+
+// TrocaIndexada é uma proposta de troca e se ela já foi executada.
+type TrocaIndexada struct {
+	PropostaID    string `json:"proposta_id"`
+	Jogador1      string `json:"jogador1"`
+	Jogador2      string `json:"jogador2"`
+	CartaJogador1 string `json:"carta_jogador1"`
+	CartaJogador2 string `json:"carta_jogador2"`
+	Executada     bool   `json:"executada"`
+	Bloco         uint64 `json:"bloco"`
+	HashTx        string `json:"hash_tx"`
+}
+
+// PacoteIndexado é uma compra de pacote.
+type PacoteIndexado struct {
+	Comprador string   `json:"comprador"`
+	TokenIDs  []string `json:"token_ids"`
+	Timestamp int64    `json:"timestamp"`
+	Bloco     uint64   `json:"bloco"`
+	HashTx    string   `json:"hash_tx"`
+}
+
+// PartidaIndexada é um resultado de partida registrado no contrato.
+type PartidaIndexada struct {
+	Jogador1  string `json:"jogador1"`
+	Jogador2  string `json:"jogador2"`
+	Vencedor  string `json:"vencedor"` // Endereço zero em caso de empate
+	Timestamp int64  `json:"timestamp"`
+	HashLog   string `json:"hash_log"` // keccak256 do EventLog, para auditar a partida com o replay
+	Bloco     uint64 `json:"bloco"`
+	HashTx    string `json:"hash_tx"`
+}
+
+// registro é uma linha do arquivo do banco: um lote de blocos processados ou uma reversão por reorg.
+type registro struct {
+	Tipo         string          `json:"tipo"` // LOTE | REVERTER
+	ProximoBloco uint64          `json:"proximo_bloco"`
+	Blocos       []BlocoIndexado `json:"blocos,omitempty"`
+	Eventos      []Evento        `json:"eventos,omitempty"`
+}
+
+// Banco é o armazenamento embutido do indexador. Como o repositório de jogadores, é um arquivo
+// JSON append-only reaplicado na abertura; as consultas usam os índices mantidos em memória.
+type Banco struct {
+	mutex        sync.RWMutex
+	caminho      string
+	arquivo      *os.File
+	proximoBloco uint64
+	blocos       []BlocoIndexado // Últimos BLOCOS_RECENTES blocos processados, em ordem crescente
+	eventos      []Evento
+
+	// Índices derivados de 'eventos' (refeitos após uma reversão)
+	cartas          map[string]*CartaIndexada
+	porProprietario map[string]map[string]bool // endereço -> IDs das cartas
+	trocas          map[string]*TrocaIndexada
+	pacotes         []PacoteIndexado
+	partidas        []PartidaIndexada
+}
+
+// NewBanco abre (ou cria) o arquivo do indexador e reconstrói os índices a partir dele.
+func NewBanco(caminho string) (*Banco, error) {
+	if err := os.MkdirAll(filepath.Dir(caminho), 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de dados: %v", err)
+	}
+
+	b := &Banco{caminho: caminho}
+	b.limparIndices()
+	if err := b.carregar(); err != nil {
+		return nil, err
+	}
+
+	arquivo, err := os.OpenFile(caminho, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo do indexador: %v", err)
+	}
+	b.arquivo = arquivo
+
+	log.Printf("[INDEXADOR] %d eventos carregados de %s (próximo bloco: %d)", len(b.eventos), caminho, b.proximoBloco)
+	return b, nil
+}
+
+func (b *Banco) carregar() error {
+	arquivo, err := os.Open(b.caminho)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao ler arquivo do indexador: %v", err)
+	}
+	defer arquivo.Close()
+
+	scanner := bufio.NewScanner(arquivo)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	linha := 0
+	for scanner.Scan() {
+		linha++
+		var reg registro
+		if err := json.Unmarshal(scanner.Bytes(), &reg); err != nil {
+			// Um lote truncado é descartado inteiro; os blocos dele são buscados de novo
+			log.Printf("[INDEXADOR_AVISO] Linha %d ignorada em %s: %v", linha, b.caminho, err)
+			continue
+		}
+		b.aplicar(reg)
+	}
+	return scanner.Err()
+}
+
+// aplicar assume que b.mutex já está bloqueado (ou que o banco ainda não foi publicado).
+func (b *Banco) aplicar(reg registro) {
+	switch reg.Tipo {
+	case "LOTE":
+		for _, evento := range reg.Eventos {
+			b.eventos = append(b.eventos, evento)
+			b.indexar(evento)
+		}
+		b.blocos = append(b.blocos, reg.Blocos...)
+		if len(b.blocos) > BLOCOS_RECENTES {
+			b.blocos = append([]BlocoIndexado(nil), b.blocos[len(b.blocos)-BLOCOS_RECENTES:]...)
+		}
+	case "REVERTER":
+		eventos := b.eventos[:0]
+		for _, evento := range b.eventos {
+			if evento.Bloco < reg.ProximoBloco {
+				eventos = append(eventos, evento)
+			}
+		}
+		b.eventos = eventos
+		blocos := b.blocos[:0]
+		for _, bloco := range b.blocos {
+			if bloco.Numero < reg.ProximoBloco {
+				blocos = append(blocos, bloco)
+			}
+		}
+		b.blocos = blocos
+		b.limparIndices()
+		for _, evento := range b.eventos {
+			b.indexar(evento)
+		}
+	}
+	b.proximoBloco = reg.ProximoBloco
+}
+
+func (b *Banco) limparIndices() {
+	b.cartas = make(map[string]*CartaIndexada)
+	b.porProprietario = make(map[string]map[string]bool)
+	b.trocas = make(map[string]*TrocaIndexada)
+	b.pacotes = nil
+	b.partidas = nil
+}
+
+// indexar atualiza os índices com um evento. Assume que b.mutex já está bloqueado.
+func (b *Banco) indexar(evento Evento) {
+	switch evento.Tipo {
+	case EVENTO_CARTA_CRIADA:
+		b.cartas[evento.TokenID] = &CartaIndexada{
+			Carta: tipos.Carta{
+				ID:       evento.TokenID,
+				Nome:     evento.Nome,
+				Naipe:    evento.Naipe,
+				Valor:    evento.Valor,
+				Raridade: evento.Raridade,
+			},
+			Proprietario: evento.Proprietario,
+			BlocoCriacao: evento.Bloco,
+		}
+		b.moverCarta(evento.TokenID, "", evento.Proprietario)
+
+	case EVENTO_CARTA_TRANSFERIDA:
+		if carta, ok := b.cartas[evento.TokenID]; ok {
+			carta.Proprietario = evento.Para
+		}
+		b.moverCarta(evento.TokenID, evento.De, evento.Para)
+
+	case EVENTO_CARTA_QUEIMADA:
+		delete(b.cartas, evento.TokenID)
+		b.moverCarta(evento.TokenID, evento.Proprietario, "")
+
+	case EVENTO_PACOTE_COMPRADO:
+		b.pacotes = append(b.pacotes, PacoteIndexado{
+			Comprador: evento.Proprietario,
+			TokenIDs:  evento.TokenIDs,
+			Timestamp: evento.Timestamp,
+			Bloco:     evento.Bloco,
+			HashTx:    evento.HashTx,
+		})
+
+	case EVENTO_PROPOSTA_TROCA_CRIADA, EVENTO_TROCA_EXECUTADA:
+		troca, ok := b.trocas[evento.PropostaID]
+		if !ok {
+			troca = &TrocaIndexada{
+				PropostaID:    evento.PropostaID,
+				Jogador1:      evento.Jogador1,
+				Jogador2:      evento.Jogador2,
+				CartaJogador1: evento.CartaJogador1,
+				CartaJogador2: evento.CartaJogador2,
+				Bloco:         evento.Bloco,
+				HashTx:        evento.HashTx,
+			}
+			b.trocas[evento.PropostaID] = troca
+		}
+		if evento.Tipo == EVENTO_TROCA_EXECUTADA {
+			troca.Executada = true
+		}
+
+	case EVENTO_PARTIDA_REGISTRADA:
+		b.partidas = append(b.partidas, PartidaIndexada{
+			Jogador1:  evento.Jogador1,
+			Jogador2:  evento.Jogador2,
+			Vencedor:  evento.Vencedor,
+			Timestamp: evento.Timestamp,
+			HashLog:   evento.HashLog,
+			Bloco:     evento.Bloco,
+			HashTx:    evento.HashTx,
+		})
+	}
+}
+
+func (b *Banco) moverCarta(tokenID, de, para string) {
+	if ids, ok := b.porProprietario[de]; ok {
+		delete(ids, tokenID)
+	}
+	if para == "" {
+		return
+	}
+	if _, ok := b.porProprietario[para]; !ok {
+		b.porProprietario[para] = make(map[string]bool)
+	}
+	b.porProprietario[para][tokenID] = true
+}
+
+// RegistrarLote grava os eventos dos blocos [ProximoBloco anterior, proximoBloco) de uma vez.
+func (b *Banco) RegistrarLote(proximoBloco uint64, blocos []BlocoIndexado, eventos []Evento) error {
+	return b.gravar(registro{Tipo: "LOTE", ProximoBloco: proximoBloco, Blocos: blocos, Eventos: eventos})
+}
+
+// Reverter descarta tudo o que foi indexado a partir do bloco 'desde' (reorganização da cadeia).
+func (b *Banco) Reverter(desde uint64) error {
+	return b.gravar(registro{Tipo: "REVERTER", ProximoBloco: desde})
+}
+
+func (b *Banco) gravar(reg registro) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	linha, err := json.Marshal(reg)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar registro do indexador: %v", err)
+	}
+	if _, err := b.arquivo.Write(append(linha, '\n')); err != nil {
+		return fmt.Errorf("erro ao gravar registro do indexador: %v", err)
+	}
+	if err := b.arquivo.Sync(); err != nil {
+		return fmt.Errorf("erro ao sincronizar arquivo do indexador: %v", err)
+	}
+	b.aplicar(reg)
+	return nil
+}
+
+// ProximoBloco retorna o primeiro bloco ainda não indexado.
+func (b *Banco) ProximoBloco() uint64 {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.proximoBloco
+}
+
+// BlocosRecentes retorna os últimos blocos processados, do mais novo para o mais antigo.
+func (b *Banco) BlocosRecentes() []BlocoIndexado {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	blocos := make([]BlocoIndexado, 0, len(b.blocos))
+	for i := len(b.blocos) - 1; i >= 0; i-- {
+		blocos = append(blocos, b.blocos[i])
+	}
+	return blocos
+}
+
+// Carta retorna uma carta indexada pelo ID do token.
+func (b *Banco) Carta(tokenID string) (CartaIndexada, bool) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	carta, ok := b.cartas[tokenID]
+	if !ok {
+		return CartaIndexada{}, false
+	}
+	return *carta, true
+}
+
+// Inventario retorna as cartas de um endereço, ordenadas pelo ID do token.
+func (b *Banco) Inventario(endereco string) []tipos.Carta {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	cartas := make([]tipos.Carta, 0, len(b.porProprietario[endereco]))
+	for id := range b.porProprietario[endereco] {
+		if carta, ok := b.cartas[id]; ok {
+			cartas = append(cartas, carta.Carta)
+		}
+	}
+	sort.Slice(cartas, func(i, j int) bool { return menorID(cartas[i].ID, cartas[j].ID) })
+	return cartas
+}
+
+// Pacotes retorna as compras de pacote de um endereço.
+func (b *Banco) Pacotes(endereco string) []PacoteIndexado {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	pacotes := make([]PacoteIndexado, 0)
+	for _, p := range b.pacotes {
+		if p.Comprador == endereco {
+			pacotes = append(pacotes, p)
+		}
+	}
+	return pacotes
+}
+
+// Trocas retorna as propostas de troca em que o endereço participa, ordenadas pelo ID.
+func (b *Banco) Trocas(endereco string) []TrocaIndexada {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	trocas := make([]TrocaIndexada, 0)
+	for _, t := range b.trocas {
+		if t.Jogador1 == endereco || t.Jogador2 == endereco {
+			trocas = append(trocas, *t)
+		}
+	}
+	sort.Slice(trocas, func(i, j int) bool { return menorID(trocas[i].PropostaID, trocas[j].PropostaID) })
+	return trocas
+}
+
+// Partidas retorna as partidas registradas de um endereço.
+func (b *Banco) Partidas(endereco string) []PartidaIndexada {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	partidas := make([]PartidaIndexada, 0)
+	for _, p := range b.partidas {
+		if p.Jogador1 == endereco || p.Jogador2 == endereco {
+			partidas = append(partidas, p)
+		}
+	}
+	return partidas
+}
+
+// Eventos retorna os eventos a partir de um bloco, opcionalmente filtrados por tipo, até 'limite'.
+func (b *Banco) Eventos(desdeBloco uint64, tipo string, limite int) []Evento {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	eventos := make([]Evento, 0)
+	for _, e := range b.eventos {
+		if e.Bloco < desdeBloco || (tipo != "" && e.Tipo != tipo) {
+			continue
+		}
+		eventos = append(eventos, e)
+		if len(eventos) >= limite {
+			break
+		}
+	}
+	return eventos
+}
+
+// TotalEventos retorna quantos eventos estão indexados.
+func (b *Banco) TotalEventos() int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return len(b.eventos)
+}
+
+// Fechar fecha o arquivo do indexador.
+func (b *Banco) Fechar() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.arquivo.Close()
+}
+
+// menorID compara IDs de token (uint256 em decimal) numericamente.
+func menorID(a, b string) bool {
+	x, okX := new(big.Int).SetString(a, 10)
+	y, okY := new(big.Int).SetString(b, 10)
+	if !okX || !okY {
+		return a < b
+	}
+	return x.Cmp(y) < 0
+}
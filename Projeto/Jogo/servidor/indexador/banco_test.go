@@ -0,0 +1,178 @@
+package indexador
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const (
+	ana = "0x00000000000000000000000000000000000000a1"
+	bia = "0x00000000000000000000000000000000000000b1"
+)
+
+func abrirBancoTeste(t *testing.T, caminho string) *Banco {
+	t.Helper()
+	b, err := NewBanco(caminho)
+	if err != nil {
+		t.Fatalf("NewBanco: %v", err)
+	}
+	t.Cleanup(func() { b.Fechar() })
+	return b
+}
+
+// criada é o CartaCriada de uma carta no bloco dado
+func criada(bloco uint64, tokenID, dono string) Evento {
+	return Evento{Tipo: EVENTO_CARTA_CRIADA, Bloco: bloco, TokenID: tokenID, Proprietario: dono, Nome: "Carta " + tokenID, Naipe: "♥", Valor: 5, Raridade: "C"}
+}
+
+func transferida(bloco uint64, tokenID, de, para string) Evento {
+	return Evento{Tipo: EVENTO_CARTA_TRANSFERIDA, Bloco: bloco, TokenID: tokenID, De: de, Para: para}
+}
+
+func idsDoInventario(b *Banco, endereco string) []string {
+	ids := []string{}
+	for _, carta := range b.Inventario(endereco) {
+		ids = append(ids, carta.ID)
+	}
+	return ids
+}
+
+func TestBancoIndices(t *testing.T) {
+	b := abrirBancoTeste(t, filepath.Join(t.TempDir(), "indexador.jsonl"))
+
+	passos := []struct {
+		nome          string
+		aplicar       func() error
+		proximoBloco  uint64
+		inventarioAna []string
+		inventarioBia []string
+	}{
+		{"pacote comprado", func() error {
+			return b.RegistrarLote(11, []BlocoIndexado{{10, "0x10"}}, []Evento{
+				criada(10, "2", ana), criada(10, "10", ana), criada(10, "9", ana),
+				{Tipo: EVENTO_PACOTE_COMPRADO, Bloco: 10, Proprietario: ana, TokenIDs: []string{"2", "9", "10"}},
+			})
+		}, 11, []string{"2", "9", "10"}, []string{}},
+		{"troca executada", func() error {
+			return b.RegistrarLote(13, []BlocoIndexado{{11, "0x11"}, {12, "0x12"}}, []Evento{
+				criada(11, "20", bia),
+				{Tipo: EVENTO_PROPOSTA_TROCA_CRIADA, Bloco: 11, PropostaID: "1", Jogador1: ana, Jogador2: bia, CartaJogador1: "9", CartaJogador2: "20"},
+				transferida(12, "9", ana, bia), transferida(12, "20", bia, ana),
+				{Tipo: EVENTO_TROCA_EXECUTADA, Bloco: 12, PropostaID: "1"},
+			})
+		}, 13, []string{"2", "10", "20"}, []string{"9"}},
+		{"fusão queima cartas", func() error {
+			return b.RegistrarLote(14, []BlocoIndexado{{13, "0x13"}}, []Evento{
+				{Tipo: EVENTO_CARTA_QUEIMADA, Bloco: 13, TokenID: "2", Proprietario: ana},
+				{Tipo: EVENTO_CARTA_QUEIMADA, Bloco: 13, TokenID: "10", Proprietario: ana},
+				criada(13, "30", ana),
+			})
+		}, 14, []string{"20", "30"}, []string{"9"}},
+		{"reorganização desfaz a fusão", func() error { return b.Reverter(13) }, 13, []string{"2", "10", "20"}, []string{"9"}},
+		{"reorganização desfaz a troca", func() error { return b.Reverter(12) }, 12, []string{"2", "9", "10"}, []string{"20"}},
+	}
+	for _, p := range passos {
+		if err := p.aplicar(); err != nil {
+			t.Fatalf("%s: %v", p.nome, err)
+		}
+		if b.ProximoBloco() != p.proximoBloco {
+			t.Errorf("%s: próximo bloco %d, esperado %d", p.nome, b.ProximoBloco(), p.proximoBloco)
+		}
+		if ids := idsDoInventario(b, ana); !reflect.DeepEqual(ids, p.inventarioAna) {
+			t.Errorf("%s: inventário de ana %v, esperado %v", p.nome, ids, p.inventarioAna)
+		}
+		if ids := idsDoInventario(b, bia); !reflect.DeepEqual(ids, p.inventarioBia) {
+			t.Errorf("%s: inventário de bia %v, esperado %v", p.nome, ids, p.inventarioBia)
+		}
+	}
+
+	// A proposta continua indexada depois da reversão, mas não executada
+	trocas := b.Trocas(bia)
+	if len(trocas) != 1 || trocas[0].Executada {
+		t.Errorf("trocas de bia depois da reversão: %+v", trocas)
+	}
+	if pacotes := b.Pacotes(ana); len(pacotes) != 1 || len(pacotes[0].TokenIDs) != 3 {
+		t.Errorf("pacotes de ana: %+v", pacotes)
+	}
+	if blocos := b.BlocosRecentes(); len(blocos) != 2 || blocos[0].Numero != 11 {
+		t.Errorf("blocos recentes depois da reversão: %+v", blocos)
+	}
+}
+
+func TestBancoReabertura(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "indexador.jsonl")
+	b := abrirBancoTeste(t, caminho)
+	b.RegistrarLote(6, []BlocoIndexado{{5, "0x5"}}, []Evento{criada(5, "1", ana)})
+	b.RegistrarLote(7, []BlocoIndexado{{6, "0x6"}}, []Evento{transferida(6, "1", ana, bia)})
+	b.Fechar()
+
+	// Um lote truncado no fim do arquivo (queda no meio da escrita) é descartado
+	arquivo, _ := os.OpenFile(caminho, os.O_APPEND|os.O_WRONLY, 0644)
+	arquivo.WriteString(`{"tipo":"LOTE","proximo_bloco":8,"eventos":[{"tipo":"CartaCr`)
+	arquivo.Close()
+
+	reaberto := abrirBancoTeste(t, caminho)
+	casos := []struct {
+		nome           string
+		obtido, espera interface{}
+	}{
+		{"próximo bloco", reaberto.ProximoBloco(), uint64(7)},
+		{"eventos", reaberto.TotalEventos(), 2},
+		{"inventário de ana", idsDoInventario(reaberto, ana), []string{}},
+		{"inventário de bia", idsDoInventario(reaberto, bia), []string{"1"}},
+	}
+	for _, c := range casos {
+		if !reflect.DeepEqual(c.obtido, c.espera) {
+			t.Errorf("%s: %v, esperado %v", c.nome, c.obtido, c.espera)
+		}
+	}
+	if carta, ok := reaberto.Carta("1"); !ok || carta.Proprietario != bia || carta.BlocoCriacao != 5 {
+		t.Errorf("Carta(1) = %+v, %v", carta, ok)
+	}
+}
+
+func TestBancoEventos(t *testing.T) {
+	b := abrirBancoTeste(t, filepath.Join(t.TempDir(), "indexador.jsonl"))
+	b.RegistrarLote(4, nil, []Evento{
+		criada(1, "1", ana), criada(2, "2", ana), transferida(3, "1", ana, bia), criada(3, "3", bia),
+	})
+
+	casos := []struct {
+		desde  uint64
+		tipo   string
+		limite int
+		total  int
+	}{
+		{0, "", 10, 4},
+		{2, "", 10, 3},
+		{0, EVENTO_CARTA_CRIADA, 10, 3},
+		{3, EVENTO_CARTA_TRANSFERIDA, 10, 1},
+		{0, "", 2, 2},
+		{9, "", 10, 0},
+	}
+	for _, c := range casos {
+		if eventos := b.Eventos(c.desde, c.tipo, c.limite); len(eventos) != c.total {
+			t.Errorf("Eventos(%d, %q, %d) = %d eventos, esperado %d", c.desde, c.tipo, c.limite, len(eventos), c.total)
+		}
+	}
+}
+
+func TestMenorID(t *testing.T) {
+	casos := []struct {
+		a, b  string
+		menor bool
+	}{
+		{"2", "10", true},
+		{"10", "2", false},
+		{"7", "7", false},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639935", "2", false},
+		{"abc", "abd", true}, // IDs não numéricos caem na ordem de texto
+	}
+	for _, c := range casos {
+		if menor := menorID(c.a, c.b); menor != c.menor {
+			t.Errorf("menorID(%s, %s) = %v, esperado %v", c.a, c.b, menor, c.menor)
+		}
+	}
+}
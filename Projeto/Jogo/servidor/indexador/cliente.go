@@ -0,0 +1,40 @@
+package indexador
+
+import (
+	"encoding/json"
+	"fmt"
+	"jogodistribuido/servidor/tipos"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Cliente consulta a API de um indexador em execução (usado pelo servidor de jogo).
+type Cliente struct {
+	url  string
+	http *http.Client
+}
+
+// NewCliente cria um cliente para o indexador em url (ex: http://indexador:8090).
+func NewCliente(url string) *Cliente {
+	return &Cliente{url: url, http: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// Inventario retorna as cartas do endereço e o primeiro bloco que o indexador ainda não processou.
+func (c *Cliente) Inventario(endereco common.Address) ([]tipos.Carta, uint64, error) {
+	resp, err := c.http.Get(fmt.Sprintf("%s/inventario/%s", c.url, endereco.Hex()))
+	if err != nil {
+		return nil, 0, fmt.Errorf("erro ao consultar indexador: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("indexador respondeu com status %d", resp.StatusCode)
+	}
+	var resposta RespostaInventario
+	if err := json.NewDecoder(resp.Body).Decode(&resposta); err != nil {
+		return nil, 0, fmt.Errorf("erro ao decodificar resposta do indexador: %v", err)
+	}
+	return resposta.Cartas, resposta.ProximoBloco, nil
+}
@@ -0,0 +1,302 @@
+package indexador
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	INTERVALO_PADRAO = 2 * time.Second // Intervalo entre consultas ao nó quando o indexador está em dia
+	LOTE_BLOCOS      = 500             // Máximo de blocos por chamada a eth_getLogs
+)
+
+// Indexador acompanha os eventos do contrato GameEconomy e os grava no Banco. A cada ciclo ele
+// confere os hashes dos últimos blocos processados com a cadeia canônica: se algum mudou (reorg),
+// descarta o que foi indexado depois do último bloco em comum e processa de novo a partir dali.
+type Indexador struct {
+	client       *ethclient.Client
+	contrato     common.Address
+	contratoABI  abi.ABI
+	banco        *Banco
+	blocoInicial uint64 // Bloco de implantação do contrato (nada antes disso é buscado)
+	confirmacoes uint64 // Blocos mais novos que head-confirmacoes ainda não são indexados
+	intervalo    time.Duration
+}
+
+// NewIndexador cria o indexador. blocoInicial evita varrer a cadeia desde o gênesis.
+func NewIndexador(client *ethclient.Client, contrato common.Address, contratoABI abi.ABI, banco *Banco, blocoInicial, confirmacoes uint64) *Indexador {
+	return &Indexador{
+		client:       client,
+		contrato:     contrato,
+		contratoABI:  contratoABI,
+		banco:        banco,
+		blocoInicial: blocoInicial,
+		confirmacoes: confirmacoes,
+		intervalo:    INTERVALO_PADRAO,
+	}
+}
+
+// Executar segue a cadeia até o contexto ser cancelado.
+func (ix *Indexador) Executar(ctx context.Context) {
+	log.Printf("[INDEXADOR] Acompanhando o contrato %s a partir do bloco %d", ix.contrato.Hex(), ix.inicio())
+	for {
+		emDia, err := ix.sincronizar(ctx)
+		if err != nil {
+			log.Printf("[INDEXADOR_ERRO] %v", err)
+		}
+		if emDia || err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(ix.intervalo):
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (ix *Indexador) inicio() uint64 {
+	if proximo := ix.banco.ProximoBloco(); proximo > ix.blocoInicial {
+		return proximo
+	}
+	return ix.blocoInicial
+}
+
+// sincronizar trata uma eventual reorg e indexa até LOTE_BLOCOS blocos. Retorna true quando
+// não há mais blocos confirmados a processar.
+func (ix *Indexador) sincronizar(ctx context.Context) (bool, error) {
+	cabeca, err := ix.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("erro ao obter o último bloco: %v", err)
+	}
+	if cabeca.Number.Uint64() < ix.confirmacoes {
+		return true, nil
+	}
+	ultimoConfirmado := cabeca.Number.Uint64() - ix.confirmacoes
+
+	if err := ix.verificarReorg(ctx); err != nil {
+		return false, err
+	}
+
+	de := ix.inicio()
+	if de > ultimoConfirmado {
+		return true, nil
+	}
+	ate := de + LOTE_BLOCOS - 1
+	if ate > ultimoConfirmado {
+		ate = ultimoConfirmado
+	}
+
+	eventos, blocos, err := ix.buscarEventos(ctx, de, ate)
+	if err != nil {
+		return false, err
+	}
+	if err := ix.banco.RegistrarLote(ate+1, blocos, eventos); err != nil {
+		return false, err
+	}
+	if len(eventos) > 0 {
+		log.Printf("[INDEXADOR] Blocos %d-%d: %d eventos indexados", de, ate, len(eventos))
+	}
+	return ate == ultimoConfirmado, nil
+}
+
+// verificarReorg compara os blocos processados mais recentes com a cadeia canônica e reverte o
+// banco até o último bloco em comum.
+func (ix *Indexador) verificarReorg(ctx context.Context) error {
+	recentes := ix.banco.BlocosRecentes()
+	if len(recentes) == 0 {
+		return nil
+	}
+	for i, bloco := range recentes {
+		header, err := ix.client.HeaderByNumber(ctx, new(big.Int).SetUint64(bloco.Numero))
+		if err != nil && err != ethereum.NotFound {
+			return fmt.Errorf("erro ao obter o bloco %d: %v", bloco.Numero, err)
+		}
+		if err == nil && header.Hash().Hex() == bloco.Hash {
+			if i == 0 {
+				return nil
+			}
+			log.Printf("[INDEXADOR_REORG] Bloco %d mudou; revertendo eventos a partir do bloco %d", recentes[i-1].Numero, bloco.Numero+1)
+			return ix.banco.Reverter(bloco.Numero + 1)
+		}
+	}
+	// Reorg mais profunda que os blocos guardados: indexa tudo de novo
+	log.Printf("[INDEXADOR_REORG] Nenhum dos últimos %d blocos está na cadeia canônica; reindexando desde o bloco %d", len(recentes), ix.blocoInicial)
+	return ix.banco.Reverter(ix.blocoInicial)
+}
+
+// buscarEventos decodifica os logs do contrato em [de, ate]. Os blocos retornados (os que têm
+// eventos e o próprio 'ate') são os usados depois na detecção de reorg.
+func (ix *Indexador) buscarEventos(ctx context.Context, de, ate uint64) ([]Evento, []BlocoIndexado, error) {
+	topicos := make([]common.Hash, 0, 7)
+	for _, nome := range []string{EVENTO_CARTA_CRIADA, EVENTO_CARTA_TRANSFERIDA, EVENTO_CARTA_QUEIMADA, EVENTO_PACOTE_COMPRADO,
+		EVENTO_PROPOSTA_TROCA_CRIADA, EVENTO_TROCA_EXECUTADA, EVENTO_PARTIDA_REGISTRADA} {
+		if evento, ok := ix.contratoABI.Events[nome]; ok {
+			topicos = append(topicos, evento.ID)
+		}
+	}
+
+	logs, err := ix.client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(de),
+		ToBlock:   new(big.Int).SetUint64(ate),
+		Addresses: []common.Address{ix.contrato},
+		Topics:    [][]common.Hash{topicos},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar logs dos blocos %d-%d: %v", de, ate, err)
+	}
+
+	cabecaLote, err := ix.client.HeaderByNumber(ctx, new(big.Int).SetUint64(ate))
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao obter o bloco %d: %v", ate, err)
+	}
+
+	eventos := make([]Evento, 0, len(logs))
+	blocos := make([]BlocoIndexado, 0)
+	for _, vLog := range logs {
+		if vLog.Removed {
+			continue
+		}
+		if len(blocos) == 0 || blocos[len(blocos)-1].Numero != vLog.BlockNumber {
+			blocos = append(blocos, BlocoIndexado{Numero: vLog.BlockNumber, Hash: vLog.BlockHash.Hex()})
+		}
+		evento, err := ix.decodificar(ctx, vLog)
+		if err != nil {
+			return nil, nil, fmt.Errorf("erro ao decodificar log %s/%d: %v", vLog.TxHash.Hex(), vLog.Index, err)
+		}
+		eventos = append(eventos, evento)
+	}
+	if len(blocos) == 0 || blocos[len(blocos)-1].Numero != ate {
+		blocos = append(blocos, BlocoIndexado{Numero: ate, Hash: cabecaLote.Hash().Hex()})
+	}
+	return eventos, blocos, nil
+}
+
+// decodificar converte um log do contrato em Evento.
+func (ix *Indexador) decodificar(ctx context.Context, vLog types.Log) (Evento, error) {
+	definicao, err := ix.contratoABI.EventByID(vLog.Topics[0])
+	if err != nil {
+		return Evento{}, err
+	}
+	campos := make(map[string]interface{})
+	if err := ix.contratoABI.UnpackIntoMap(campos, definicao.Name, vLog.Data); err != nil {
+		return Evento{}, err
+	}
+	var indexados abi.Arguments
+	for _, arg := range definicao.Inputs {
+		if arg.Indexed {
+			indexados = append(indexados, arg)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(campos, indexados, vLog.Topics[1:]); err != nil {
+		return Evento{}, err
+	}
+
+	evento := Evento{
+		Tipo:      definicao.Name,
+		Bloco:     vLog.BlockNumber,
+		HashBloco: vLog.BlockHash.Hex(),
+		HashTx:    vLog.TxHash.Hex(),
+		IndiceLog: vLog.Index,
+	}
+	switch definicao.Name {
+	case EVENTO_CARTA_CRIADA:
+		evento.TokenID = inteiro(campos["tokenId"])
+		evento.Proprietario = endereco(campos["proprietario"])
+		evento.Nome, _ = campos["nome"].(string)
+		evento.Raridade, _ = campos["raridade"].(string)
+		if valor, ok := campos["valor"].(*big.Int); ok {
+			evento.Valor = int(valor.Int64())
+		}
+		// O evento não traz o naipe; ele é lido uma única vez aqui, no momento da indexação
+		naipe, err := ix.lerNaipe(ctx, campos["tokenId"])
+		if err != nil {
+			return Evento{}, err
+		}
+		evento.Naipe = naipe
+
+	case EVENTO_CARTA_TRANSFERIDA:
+		evento.TokenID = inteiro(campos["tokenId"])
+		evento.De = endereco(campos["de"])
+		evento.Para = endereco(campos["para"])
+
+	case EVENTO_CARTA_QUEIMADA:
+		evento.TokenID = inteiro(campos["tokenId"])
+		evento.Proprietario = endereco(campos["proprietario"])
+
+	case EVENTO_PACOTE_COMPRADO:
+		evento.Proprietario = endereco(campos["comprador"])
+		if ids, ok := campos["tokenIds"].([]*big.Int); ok {
+			for _, id := range ids {
+				evento.TokenIDs = append(evento.TokenIDs, id.String())
+			}
+		}
+		evento.Timestamp = timestamp(campos["timestamp"])
+
+	case EVENTO_PROPOSTA_TROCA_CRIADA, EVENTO_TROCA_EXECUTADA:
+		evento.PropostaID = inteiro(campos["propostaId"])
+		evento.Jogador1 = endereco(campos["jogador1"])
+		evento.Jogador2 = endereco(campos["jogador2"])
+		evento.CartaJogador1 = inteiro(campos["cartaJogador1"])
+		evento.CartaJogador2 = inteiro(campos["cartaJogador2"])
+
+	case EVENTO_PARTIDA_REGISTRADA:
+		evento.Jogador1 = endereco(campos["jogador1"])
+		evento.Jogador2 = endereco(campos["jogador2"])
+		evento.Vencedor = endereco(campos["vencedor"])
+		evento.Timestamp = timestamp(campos["timestamp"])
+		if hashLog, ok := campos["hashLog"].([32]byte); ok {
+			evento.HashLog = common.Hash(hashLog).Hex()
+		}
+	}
+	return evento, nil
+}
+
+// lerNaipe consulta o mapeamento público 'cartas' do contrato.
+func (ix *Indexador) lerNaipe(ctx context.Context, tokenID interface{}) (string, error) {
+	data, err := ix.contratoABI.Pack("cartas", tokenID)
+	if err != nil {
+		return "", fmt.Errorf("erro ao preparar chamada: %v", err)
+	}
+	resultado, err := ix.client.CallContract(ctx, ethereum.CallMsg{To: &ix.contrato, Data: data}, nil)
+	if err != nil {
+		return "", fmt.Errorf("erro ao chamar contrato: %v", err)
+	}
+	valores, err := ix.contratoABI.Unpack("cartas", resultado)
+	if err != nil || len(valores) < 3 {
+		return "", fmt.Errorf("erro ao desempacotar carta: %v", err)
+	}
+	naipe, _ := valores[2].(string)
+	return naipe, nil
+}
+
+func inteiro(v interface{}) string {
+	if n, ok := v.(*big.Int); ok {
+		return n.String()
+	}
+	return ""
+}
+
+func endereco(v interface{}) string {
+	if a, ok := v.(common.Address); ok {
+		return a.Hex()
+	}
+	return ""
+}
+
+func timestamp(v interface{}) int64 {
+	if n, ok := v.(*big.Int); ok {
+		return n.Int64()
+	}
+	return 0
+}
@@ -13,6 +13,10 @@ import (
 	"jogodistribuido/servidor/cluster"
 	"jogodistribuido/servidor/game"
 	mqttManager "jogodistribuido/servidor/mqtt"
+	"jogodistribuido/servidor/persistencia"
+	"jogodistribuido/servidor/ranking"
+	"jogodistribuido/servidor/regras"
+	"jogodistribuido/servidor/replay"
 	"jogodistribuido/servidor/seguranca"
 	"jogodistribuido/servidor/store"
 	"jogodistribuido/servidor/tipos"
@@ -21,6 +25,7 @@ import (
 	"math/rand"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -28,6 +33,7 @@ import (
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -36,11 +42,58 @@ import (
 // ==================== CONFIGURAÇÃO E CONSTANTES ====================
 
 const (
-	ELEICAO_TIMEOUT     = 30 * time.Second // Aumentado para 30 segundos
-	HEARTBEAT_INTERVALO = 5 * time.Second  // Aumentado para 5 segundos
-	PACOTE_SIZE         = 5
-	JWT_SECRET          = "jogo_distribuido_secret_key_2025" // Chave secreta compartilhada entre servidores
-	JWT_EXPIRATION      = 24 * time.Hour                     // Tokens expiram em 24 horas
+	HEARTBEAT_INTERVALO         = 5 * time.Second // Aumentado para 5 segundos
+	PACOTE_SIZE                 = 5
+	JWT_SECRET                  = "jogo_distribuido_secret_key_2025" // Chave secreta compartilhada entre servidores
+	JWT_EXPIRATION              = 24 * time.Hour                     // Tokens expiram em 24 horas
+	PRESENCA_INTERVALO          = 5 * time.Second                    // Intervalo dos sinais de presença enviados pelos clientes
+	PRESENCA_TIMEOUT            = 15 * time.Second                   // Sem presença por este tempo, o jogador é considerado ausente
+	RECONEXAO_TOLERANCIA_PADRAO = 60 * time.Second                   // Tempo para um jogador ausente retomar a sessão antes de perder por W.O.
+	TEMPO_TURNO_PADRAO          = 30 * time.Second                   // Prazo de cada jogada (TEMPO_TURNO=0 desativa o relógio)
+	TURNO_AVISO                 = 10 * time.Second                   // Tempo restante em que os jogadores são avisados
+	TURNO_MINIMO_FAILOVER       = 10 * time.Second                   // Tempo mínimo de jogada garantido após a Sombra assumir a sala
+	TOLERANCIA_RELOGIO_SOMBRA   = 3 * time.Second                    // Antecedência aceita numa jogada automática pelo relógio da Sombra (atraso da réplica)
+	RELOGIO_TURNO_INTERVALO     = 1 * time.Second
+	FILA_COMANDOS_SALA          = 64               // Comandos pendentes por sala; com a fila cheia, novos comandos são recusados
+	RESPOSTA_SALA_TIMEOUT       = 20 * time.Second // Espera máxima por um comando síncrono (maior que os timeouts HTTP entre servidores)
+	SALA_OCIOSA_TIMEOUT         = 30 * time.Minute // Sem nenhum comando por este tempo, a sala é encerrada e a goroutine termina
+	FILA_PERSISTENCIA           = 256              // Gravações em disco pendentes enviadas pelas goroutines das salas
+	INTERVALO_COMPACTACAO       = 10 * time.Minute // Intervalo entre compactações do arquivo de jogadores
+	ESPERA_VINCULO_CARTEIRA     = 3 * time.Second  // Espera pela aplicação local de um VINCULO_CARTEIRA proposto no login
+	PRAZO_ASSINATURAS_RESULTADO = 2 * time.Minute  // Prazo para Sombra e jogadores assinarem o resultado da partida
+	INTERVALO_ASSINATURA_SOMBRA = 2 * time.Second  // Espera entre pedidos à Sombra (a réplica do MATCH_END pode atrasar)
+
+	// Ação do Host quando o prazo de uma jogada termina (TURNO_ESGOTADO)
+	TURNO_ESGOTADO_AUTOMATICO = "AUTOMATICO" // Joga a carta mais fraca do jogador
+	TURNO_ESGOTADO_WO         = "WO"         // O jogador perde a partida
+
+	// Fonte que prevalece quando o inventário em memória e o da blockchain divergem (INVENTARIO_AUTORIDADE)
+	AUTORIDADE_CADEIA       = "CADEIA"   // O inventário do servidor é substituído pelo da blockchain
+	AUTORIDADE_SERVIDOR     = "SERVIDOR" // O servidor mantém o seu; as divergências só são relatadas
+	RECONCILIACAO_INTERVALO = 1 * time.Minute
+
+	// Origem das cartas entregues nos pacotes (ESTOQUE_MODO)
+	ESTOQUE_MEMORIA    = "MEMORIA"    // store.Store: estoque global derivado do log replicado
+	ESTOQUE_BLOCKCHAIN = "BLOCKCHAIN" // store.StoreBlockchain: pacotes comprados pela carteira no GameEconomy
+)
+
+// Comandos internos da goroutine da sala, além dos comandos publicados pelos jogadores
+// (COMPRAR_PACOTE, JOGAR_CARTA, CHAT, TROCAR_CARTAS, SINCRONIZAR_CARTAS)
+const (
+	CMD_EVENTO_HOST       = "EVENTO_HOST"       // Payload: tipos.GameEventRequest; resposta: *tipos.EstadoPartida
+	CMD_ABANDONO          = "ABANDONO"          // W.O. de ClienteID; Payload: {"motivo": ...}
+	CMD_RELOGIO_TURNO     = "RELOGIO_TURNO"     // Prazo de TurnoDe acabando ou esgotado
+	CMD_JOGADA_AUTOMATICA = "JOGADA_AUTOMATICA" // Jogada automática de ClienteID; resposta: error
+	CMD_PROMOVER_SOMBRA   = "PROMOVER_SOMBRA"   // Failover: a Sombra assume a sala
+	CMD_ESTADO_REMOTO     = "ESTADO_REMOTO"     // Payload: tipos.EstadoPartida enviado pelo Host
+	CMD_REPLICAR_ESTADO   = "REPLICAR_ESTADO"   // Payload: tipos.GameReplicateRequest; resposta: bool
+	CMD_TROCA_DIRETA      = "TROCA_DIRETA"      // Payload: protocolo.TrocarCartasReq encaminhada pela Sombra
+
+	// Resultados das operações lentas que a sala dispara em outras goroutines
+	CMD_HOST_INACESSIVEL = "HOST_INACESSIVEL" // Payload: tipos.GameEventRequest que o Host não recebeu
+	CMD_PACOTE_OBTIDO    = "PACOTE_OBTIDO"    // Payload: pacoteObtido
+	CMD_TROCA_CONCLUIDA  = "TROCA_CONCLUIDA"  // Payload: trocaConcluida
+	CMD_INVENTARIO_LIDO  = "INVENTARIO_LIDO"  // Payload: inventarioLido
 )
 
 // ==================== TIPOS ====================
@@ -49,26 +102,68 @@ type Carta = protocolo.Carta
 
 // Servidor é a estrutura principal que gerencia o servidor distribuído
 type Servidor struct {
-	ServerID          string
-	MeuEndereco       string
-	MeuEnderecoHTTP   string
-	BrokerMQTT        string
-	MQTTClient        mqtt.Client
-	ClusterManager    cluster.ClusterManagerInterface
-	Store             store.StoreInterface
-	GameManager       game.GameManagerInterface
-	MQTTManager       mqttManager.MQTTManagerInterface
-	BlockchainManager *blockchain.Manager // Gerenciador de blockchain (opcional)
+	ServerID             string
+	MeuEndereco          string
+	MeuEnderecoHTTP      string
+	BrokerMQTT           string
+	MQTTClient           mqtt.Client
+	ClusterManager       cluster.ClusterManagerInterface
+	Store                store.StoreInterface
+	GameManager          game.GameManagerInterface
+	MQTTManager          mqttManager.MQTTManagerInterface
+	BlockchainManager    *blockchain.Manager           // Gerenciador de blockchain (opcional)
+	Jogadores            persistencia.PlayerRepository // Contas persistidas (inventário e histórico)
+	VarianteRegras       string                        // Variante de regras padrão, para quem entra na fila sem escolher
+	FormatoPartida       string                        // ID do regras.Formato padrão, para quem entra na fila sem escolher
+	ToleranciaReconexao  time.Duration                 // Prazo para um jogador ausente retomar a sessão
+	TempoTurno           time.Duration                 // Prazo de cada jogada nas salas hospedadas (0 = sem relógio)
+	AcaoTurnoEsgotado    string                        // TURNO_ESGOTADO_AUTOMATICO | TURNO_ESGOTADO_WO
+	AutoridadeInventario string                        // AUTORIDADE_CADEIA | AUTORIDADE_SERVIDOR
 
 	// Gerenciamento de Partidas
 	Clientes        map[string]*tipos.Cliente // clienteID -> Cliente
 	mutexClientes   sync.RWMutex
-	Salas           map[string]*tipos.Sala // salaID -> Sala
+	desafiosLogin   map[string]desafioLogin // ID temporário -> desafio de login com carteira (protegido por mutexClientes)
+	Salas           map[string]*tipos.Sala  // salaID -> Sala
 	mutexSalas      sync.RWMutex
 	FilaDeEspera    []*tipos.Cliente
 	mutexFila       sync.Mutex
-	ComandosPartida map[string]chan protocolo.Comando
+	ComandosPartida map[string]chan protocolo.Comando // salaID -> fila da goroutine dona da sala (executarSala)
 	mutexComandos   sync.Mutex
+
+	// Gravações em disco das goroutines das salas, feitas em ordem por executarPersistencia
+	filaPersistencia chan func()
+
+	// Espectadores (somente leitura) atendidos por este servidor
+	Espectadores      map[string]string // clienteID -> salaID assistida
+	mutexEspectadores sync.Mutex
+
+	// Resultados de partidas confirmados pelo log replicado (Raft)
+	ResultadosPartidas map[string]tipos.ResultadoPartida // salaID -> resultado
+	mutexResultados    sync.RWMutex
+
+	// Compras de pacote propostas por este servidor: CompraID -> se a entrada retirou as cartas ao ser aplicada
+	comprasPropostas map[string]bool
+	// Resgates de pacotes da blockchain propostos por este servidor: CompraID -> se a entrada resgatou a compra
+	resgatesPropostos map[string]chan bool
+	mutexCompras      sync.Mutex
+
+	// Ratings (ELO) dos jogadores, aplicados a partir dos resultados do log replicado
+	Ratings      map[string]int // nome -> rating
+	mutexRatings sync.RWMutex
+
+	// Carteiras vinculadas aos nomes dos jogadores, aplicadas a partir do log replicado (VINCULO_CARTEIRA)
+	carteirasVinculadas map[string]string // nome -> endereço
+	mutexCarteiras      sync.RWMutex
+
+	// Resultados de partidas hospedadas aguardando as assinaturas para o registro no contrato
+	resultadosPendentes map[string]*resultadoPendente // salaID -> assinaturas coletadas
+	mutexAssinaturas    sync.Mutex
+
+	// Última passada da reconciliação entre o inventário em memória e o da blockchain
+	ultimaReconciliacao  *tipos.RelatorioReconciliacao
+	mutexReconciliacao   sync.Mutex // Protege ultimaReconciliacao; não fica preso durante as leituras da blockchain
+	passadaReconciliacao sync.Mutex // Impede duas passadas simultâneas
 }
 
 // ==================== INICIALIZAÇÃO ====================
@@ -99,6 +194,18 @@ func (s *Servidor) Run() {
 	// O ClusterManager é iniciado primeiro para que a descoberta comece imediatamente
 	s.ClusterManager.Run()
 	go s.tentarMatchmakingGlobalPeriodicamente() // Inicia a busca proativa
+	go s.monitorarSessoes()
+	go s.executarPersistencia()
+	if s.Jogadores != nil {
+		go s.compactarJogadoresPeriodicamente()
+	}
+	if s.TempoTurno > 0 {
+		go s.monitorarRelogiosTurno()
+	}
+	if s.BlockchainManager != nil {
+		go s.BlockchainManager.ObservarInventarios(context.Background(), s.notificarInventariosAlterados)
+		go s.reconciliarInventariosPeriodicamente()
+	}
 
 	// A API Server agora recebe o servidor e o cluster manager
 	apiServer := api.NewServer(s.MeuEndereco, s, s.ClusterManager)
@@ -229,6 +336,7 @@ func (s *Servidor) NotificarCompraSucesso(clienteID string, cartas []tipos.Carta
 	s.publicarParaCliente(clienteID, msg)
 }
 
+// AtualizarEstadoSalaRemoto recebe o estado enviado pelo Host; quem o aplica é a goroutine da sala.
 func (s *Servidor) AtualizarEstadoSalaRemoto(estado tipos.EstadoPartida) {
 	s.mutexSalas.Lock()
 	sala, ok := s.Salas[estado.SalaID]
@@ -238,12 +346,37 @@ func (s *Servidor) AtualizarEstadoSalaRemoto(estado tipos.EstadoPartida) {
 		log.Printf("[SYNC_SOMBRA_ERRO] Sala %s não encontrada para atualização remota", estado.SalaID)
 		return
 	}
+	if err := s.enviarComandoSala(sala, protocolo.Comando{Tipo: CMD_ESTADO_REMOTO, Payload: seguranca.MustJSON(estado)}); err != nil {
+		log.Printf("[SYNC_SOMBRA_ERRO] Estado remoto da sala %s descartado: %v", estado.SalaID, err)
+	}
+}
 
-	sala.Mutex.Lock()
+// aplicarEstadoRemoto atualiza a sala (e os inventários locais) com o estado enviado pelo Host.
+func (s *Servidor) aplicarEstadoRemoto(sala *tipos.Sala, estado tipos.EstadoPartida) {
+
+	// As jogadas novas passam pela mesma verificação da réplica (replicarEstadoNaSala)
+	var novos []tipos.GameEvent
+	if len(estado.EventLog) > len(sala.EventLog) {
+		novos = estado.EventLog[len(sala.EventLog):]
+		if err := s.verificarJogadasAssinadas(sala, novos); err != nil {
+			log.Printf("[SYNC_SOMBRA_ERRO] Estado remoto da sala %s recusado: %v", sala.ID, err)
+			return
+		}
+	}
 
 	// Atualiza estado da sala
+	s.acompanharTurnoNaSombra(sala, estado.TurnoDe, novos, estado.PrazoTurno != 0)
 	sala.Estado = estado.Estado
 	sala.TurnoDe = estado.TurnoDe
+	if estado.Regras != "" {
+		sala.Regras = estado.Regras
+	}
+	if estado.Formato != "" {
+		sala.Formato = estado.Formato
+	}
+	if len(estado.EventLog) >= len(sala.EventLog) {
+		sala.EventLog = estado.EventLog
+	}
 
 	// ATUALIZA TAMBÉM OS INVENTÁRIOS DOS JOGADORES REAIS
 	// Importante: sincronizar as mudanças do estado para os jogadores reais do Shadow
@@ -263,6 +396,7 @@ func (s *Servidor) AtualizarEstadoSalaRemoto(estado tipos.EstadoPartida) {
 				jogadorReal.Inventario = jogadorEstado.Inventario
 				jogadorReal.Mutex.Unlock()
 				log.Printf("[SYNC_SOMBRA] Inventário real atualizado para jogador local %s (%d -> %d cartas)", jogadorReal.Nome, oldCount, len(jogadorEstado.Inventario))
+				s.agendarPersistencia(func() { s.persistirJogador(jogadorReal) })
 
 				// Se o inventário mudou, notifica o cliente com o inventário atualizado
 				if oldCount != len(jogadorEstado.Inventario) {
@@ -297,8 +431,6 @@ func (s *Servidor) AtualizarEstadoSalaRemoto(estado tipos.EstadoPartida) {
 		}
 	}
 
-	sala.Mutex.Unlock()
-
 	log.Printf("[SYNC_SOMBRA_OK] Sala %s atualizada. Novo estado: %s, Turno de: %s", sala.ID, sala.Estado, sala.TurnoDe)
 	log.Printf("[SYNC_SOMBRA_DEBUG] Jogadores na sala: %v", func() []string {
 		ids := make([]string, len(sala.Jogadores))
@@ -326,20 +458,116 @@ func (s *Servidor) CriarSalaRemotaComSombra(solicitante, oponente *tipos.Cliente
 	return s.criarSala(oponente, solicitante, sombraAddr)
 }
 
-func (s *Servidor) RemoverPrimeiroDaFila() *tipos.Cliente {
+// RemoverOponenteCompativel retira da fila o jogador de rating mais próximo que aceite enfrentar
+// alguém com 'rating' na mesma variante de regras e no mesmo formato. Vale a maior janela entre a do
+// solicitante e a de quem está esperando. Variante ou formato vazios (servidor que não os envia) são
+// os padrões deste servidor.
+func (s *Servidor) RemoverOponenteCompativel(rating, janela int, variante, formato string) *tipos.Cliente {
+	formatoID, err := s.normalizarFormato(formato)
+	if err != nil {
+		log.Printf("[MATCHMAKING_RX] %v", err)
+		return nil
+	}
 	s.mutexFila.Lock()
 	defer s.mutexFila.Unlock()
-	if len(s.FilaDeEspera) == 0 {
+	return s.removerOponenteCompativelLocked(rating, janela, s.normalizarVariante(variante), formatoID, "")
+}
+
+// Assume que mutexFila JÁ ESTÁ ATIVO.
+func (s *Servidor) removerOponenteCompativelLocked(rating, janela int, variante, formato, ignorarID string) *tipos.Cliente {
+	indice := s.indiceOponenteCompativel(rating, janela, variante, formato, ignorarID)
+	if indice < 0 {
 		return nil
 	}
-	oponente := s.FilaDeEspera[0]
-	s.FilaDeEspera = s.FilaDeEspera[1:]
+	oponente := s.FilaDeEspera[indice]
+	s.FilaDeEspera = append(s.FilaDeEspera[:indice], s.FilaDeEspera[indice+1:]...)
 	return oponente
 }
 
+// parearFilaLocal forma partidas entre jogadores da fila local cujas janelas de rating já se alcançam.
+func (s *Servidor) parearFilaLocal() {
+	for {
+		s.mutexFila.Lock()
+		var j1, j2 *tipos.Cliente
+		for _, c := range s.FilaDeEspera {
+			janela := ranking.Janela(time.Since(c.EntradaFila))
+			indice := s.indiceOponenteCompativel(s.ratingDe(c.Nome), janela, c.VarianteFila, c.FormatoFila, c.ID)
+			if indice >= 0 {
+				j1, j2 = c, s.FilaDeEspera[indice]
+				break
+			}
+		}
+		if j1 == nil {
+			s.mutexFila.Unlock()
+			return
+		}
+		restante := make([]*tipos.Cliente, 0, len(s.FilaDeEspera)-2)
+		for _, c := range s.FilaDeEspera {
+			if c != j1 && c != j2 {
+				restante = append(restante, c)
+			}
+		}
+		s.FilaDeEspera = restante
+		s.mutexFila.Unlock()
+
+		log.Printf("[MATCHMAKING] Janela de rating alcançada: %s (%d) x %s (%d)", j1.Nome, s.ratingDe(j1.Nome), j2.Nome, s.ratingDe(j2.Nome))
+		s.criarSala(j1, j2, "")
+	}
+}
+
+// indiceOponenteCompativel retorna a posição na fila do melhor oponente para 'rating' que espera
+// pela mesma variante de regras e pelo mesmo formato (-1 se nenhum). Assume que mutexFila JÁ ESTÁ ATIVO.
+func (s *Servidor) indiceOponenteCompativel(rating, janela int, variante, formato, ignorarID string) int {
+	melhor, menorDiferenca := -1, 0
+	for i, c := range s.FilaDeEspera {
+		if c.ID == ignorarID || c.VarianteFila != variante || c.FormatoFila != formato {
+			continue
+		}
+		ratingOponente := s.ratingDe(c.Nome)
+		janelaPar := janela
+		if janelaOponente := ranking.Janela(time.Since(c.EntradaFila)); janelaOponente > janelaPar {
+			janelaPar = janelaOponente
+		}
+		if !ranking.Compativeis(rating, ratingOponente, janelaPar) {
+			continue
+		}
+		diferenca := rating - ratingOponente
+		if diferenca < 0 {
+			diferenca = -diferenca
+		}
+		if melhor < 0 || diferenca < menorDiferenca {
+			melhor, menorDiferenca = i, diferenca
+		}
+	}
+	return melhor
+}
+
+// removerDaFila retira um jogador específico da fila. Retorna false se ele já não estava nela.
+func (s *Servidor) removerDaFila(clienteID string) bool {
+	s.mutexFila.Lock()
+	defer s.mutexFila.Unlock()
+	for i, c := range s.FilaDeEspera {
+		if c.ID == clienteID {
+			s.FilaDeEspera = append(s.FilaDeEspera[:i], s.FilaDeEspera[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ratingDe retorna o rating atual de um jogador (RATING_INICIAL se ainda não jogou).
+func (s *Servidor) ratingDe(nome string) int {
+	s.mutexRatings.RLock()
+	defer s.mutexRatings.RUnlock()
+	if rating, ok := s.Ratings[nome]; ok {
+		return rating
+	}
+	return ranking.RATING_INICIAL
+}
+
 func (s *Servidor) ProcessarComandoRemoto(salaID string, mensagem protocolo.Mensagem) error {
 	s.mutexSalas.RLock()
-	_, ok := s.Salas[salaID]
+	sala, ok := s.Salas[salaID]
 	s.mutexSalas.RUnlock()
 
 	if !ok {
@@ -353,6 +581,13 @@ func (s *Servidor) ProcessarComandoRemoto(salaID string, mensagem protocolo.Mens
 	if err := json.Unmarshal(mensagem.Dados, &dadosComClienteID); err != nil {
 		return fmt.Errorf("não foi possível extrair cliente_id do comando remoto: %v", err)
 	}
+	if !ehJogadorDaSala(sala, dadosComClienteID.ClienteID) {
+		return fmt.Errorf("cliente %q não é jogador da sala %s", dadosComClienteID.ClienteID, salaID)
+	}
+	if mensagem.Comando == "ASSINATURA_RESULTADO" {
+		s.tratarAssinaturaResultado(sala, dadosComClienteID.ClienteID, mensagem)
+		return nil
+	}
 
 	// Constrói o comando no formato esperado pelo canal
 	comando := protocolo.Comando{
@@ -362,8 +597,7 @@ func (s *Servidor) ProcessarComandoRemoto(salaID string, mensagem protocolo.Mens
 	}
 
 	// Envia o comando para a goroutine da partida
-	s.ComandosPartida[salaID] <- comando
-	return nil
+	return s.enviarComandoSala(sala, comando)
 }
 
 func (s *Servidor) GetStatusEstoque() (map[string]int, int) {
@@ -381,15 +615,91 @@ func novoServidor(endereco, broker string) *Servidor {
 		MeuEndereco:     endereco,
 		MeuEnderecoHTTP: "http://" + endereco,
 		BrokerMQTT:      broker,
-		Store:           store.NewStore(),
 		Clientes:        make(map[string]*tipos.Cliente),
+		desafiosLogin:   make(map[string]desafioLogin),
 		Salas:           make(map[string]*tipos.Sala),
 		FilaDeEspera:    make([]*tipos.Cliente, 0),
 		ComandosPartida: make(map[string]chan protocolo.Comando),
+		Espectadores:    make(map[string]string),
+
+		filaPersistencia: make(chan func(), FILA_PERSISTENCIA),
+
+		ResultadosPartidas:  make(map[string]tipos.ResultadoPartida),
+		comprasPropostas:    make(map[string]bool),
+		resgatesPropostos:   make(map[string]chan bool),
+		Ratings:             make(map[string]int),
+		carteirasVinculadas: make(map[string]string),
+		resultadosPendentes: make(map[string]*resultadoPendente),
 	}
 
 	// Initialize managers
 	servidor.ClusterManager = cluster.NewManager(servidor)
+
+	dataDir := os.Getenv("DATA_DIR")
+	if dataDir == "" {
+		dataDir = "dados"
+	}
+
+	// Estoque inicial igual em todos os servidores; as compras chegam pelo log replicado (COMPRA_PACOTE)
+	servidor.Store = store.NewStore()
+
+	// Repositório de jogadores: mantém inventário e histórico entre reinícios
+	repositorio, err := persistencia.NewRepositorioArquivo(filepath.Join(dataDir, "jogadores_"+serverID+".jsonl"))
+	if err != nil {
+		log.Printf("⚠ Aviso: Falha ao abrir repositório de jogadores: %v. Contas não serão persistidas.", err)
+	} else {
+		servidor.Jogadores = repositorio
+	}
+	// Variante de regras padrão; cada jogador pode pedir outra ao entrar na fila
+	servidor.VarianteRegras = strings.ToUpper(strings.TrimSpace(os.Getenv("REGRAS_VARIANTE")))
+	if _, err := regras.Nova(servidor.VarianteRegras); err != nil {
+		log.Printf("⚠ Aviso: %v. Usando regras clássicas.", err)
+		servidor.VarianteRegras = regras.CLASSICO
+	}
+	// Formato padrão das partidas (ex: "MD3x1", "3x2"; vazio = livre); cada jogador pode pedir outro na fila
+	formato, err := regras.NovoFormato(os.Getenv("FORMATO_PARTIDA"))
+	if err != nil {
+		log.Printf("⚠ Aviso: %v. Usando formato livre.", err)
+	}
+	servidor.FormatoPartida = formato.ID()
+
+	// Prazo para jogadores desconectados voltarem à partida (ex: "90s", "2m")
+	servidor.ToleranciaReconexao = RECONEXAO_TOLERANCIA_PADRAO
+	if valor := os.Getenv("RECONEXAO_TOLERANCIA"); valor != "" {
+		tolerancia, err := time.ParseDuration(valor)
+		if err != nil || tolerancia < PRESENCA_TIMEOUT {
+			log.Printf("⚠ Aviso: RECONEXAO_TOLERANCIA inválida (%s); mínimo %v. Usando %v.", valor, PRESENCA_TIMEOUT, RECONEXAO_TOLERANCIA_PADRAO)
+		} else {
+			servidor.ToleranciaReconexao = tolerancia
+		}
+	}
+
+	// Relógio de turno das salas hospedadas por este servidor
+	servidor.TempoTurno = TEMPO_TURNO_PADRAO
+	if valor := os.Getenv("TEMPO_TURNO"); valor != "" {
+		tempo, err := time.ParseDuration(valor)
+		if err != nil || (tempo > 0 && tempo < TURNO_AVISO) {
+			log.Printf("⚠ Aviso: TEMPO_TURNO inválido (%s); use 0 ou no mínimo %v. Usando %v.", valor, TURNO_AVISO, TEMPO_TURNO_PADRAO)
+		} else {
+			servidor.TempoTurno = tempo
+		}
+	}
+	servidor.AcaoTurnoEsgotado = strings.ToUpper(os.Getenv("TURNO_ESGOTADO"))
+	if servidor.AcaoTurnoEsgotado != TURNO_ESGOTADO_WO {
+		if servidor.AcaoTurnoEsgotado != "" && servidor.AcaoTurnoEsgotado != TURNO_ESGOTADO_AUTOMATICO {
+			log.Printf("⚠ Aviso: TURNO_ESGOTADO desconhecido (%s). Usando %s.", servidor.AcaoTurnoEsgotado, TURNO_ESGOTADO_AUTOMATICO)
+		}
+		servidor.AcaoTurnoEsgotado = TURNO_ESGOTADO_AUTOMATICO
+	}
+	// A substituição do inventário pelo da blockchain só acontece se pedida explicitamente
+	servidor.AutoridadeInventario = strings.ToUpper(os.Getenv("INVENTARIO_AUTORIDADE"))
+	if servidor.AutoridadeInventario != AUTORIDADE_CADEIA {
+		if servidor.AutoridadeInventario != "" && servidor.AutoridadeInventario != AUTORIDADE_SERVIDOR {
+			log.Printf("⚠ Aviso: INVENTARIO_AUTORIDADE desconhecida (%s). Usando %s.", servidor.AutoridadeInventario, AUTORIDADE_SERVIDOR)
+		}
+		servidor.AutoridadeInventario = AUTORIDADE_SERVIDOR
+	}
+
 	// TODO: Initialize game and MQTT managers when interfaces are simplified
 	// servidor.GameManager = game.NewManager(servidor)
 	// servidor.MQTTManager = mqttManager.NewManager(servidor)
@@ -408,11 +718,48 @@ func novoServidor(endereco, broker string) *Servidor {
 		} else {
 			servidor.BlockchainManager = blockchainManager
 			log.Printf("✓ Blockchain inicializado com sucesso")
+			// Transações pendentes sobrevivem a reinícios e são retomadas com o mesmo nonce
+			if err := blockchainManager.UsarDiarioTransacoes(filepath.Join(dataDir, "transacoes_"+serverID+".jsonl")); err != nil {
+				log.Printf("⚠ Aviso: Diário de transações indisponível: %v. Pendentes não serão retomadas após reinício.", err)
+			}
+			// Inventários vindos do indexador de eventos em vez de uma chamada ao contrato por carta
+			if indexadorURL := os.Getenv("INDEXADOR_URL"); indexadorURL != "" {
+				blockchainManager.UsarIndexador(indexadorURL)
+				log.Printf("✓ Inventários consultados no indexador %s", indexadorURL)
+			}
+			// Sem autorização no contrato, os resultados assinados por este servidor são recusados
+			if autorizado, err := blockchainManager.ServidorAutorizado(blockchainManager.ContaServidor()); err == nil && !autorizado {
+				log.Printf("⚠ Aviso: Conta %s não autorizada no contrato (autorizarServidor). Resultados de partidas não serão registrados.", blockchainManager.ContaServidor().Hex())
+			}
+			// Eventos do contrato por assinatura websocket (sem ele, consulta periódica pelo RPC)
+			if wsURL := os.Getenv("BLOCKCHAIN_WS_URL"); wsURL != "" {
+				if err := blockchainManager.UsarWebsocket(wsURL); err != nil {
+					log.Printf("⚠ Aviso: Websocket %s indisponível: %v. Eventos do contrato por consulta periódica.", wsURL, err)
+				}
+			}
 		}
 	} else {
 		log.Printf("ℹ Blockchain não configurado (variáveis de ambiente não definidas). Usando modo tradicional.")
 	}
 
+	// Origem das cartas: estoque em memória (padrão) ou NFTs cunhados no contrato
+	switch modo := strings.ToUpper(os.Getenv("ESTOQUE_MODO")); modo {
+	case "", ESTOQUE_MEMORIA:
+	case ESTOQUE_BLOCKCHAIN:
+		if servidor.BlockchainManager == nil {
+			log.Printf("⚠ Aviso: ESTOQUE_MODO=%s requer o blockchain configurado. Usando estoque em memória.", modo)
+			break
+		}
+		servidor.Store = store.NewStoreBlockchain(servidor.BlockchainManager)
+		if servidor.AutoridadeInventario != AUTORIDADE_CADEIA {
+			log.Printf("⚠ Aviso: com ESTOQUE_MODO=%s a blockchain é a autoridade dos inventários; INVENTARIO_AUTORIDADE=%s ignorada.", modo, servidor.AutoridadeInventario)
+			servidor.AutoridadeInventario = AUTORIDADE_CADEIA
+		}
+		log.Printf("✓ Pacotes comprados pela carteira do jogador; partidas usam os inventários da blockchain")
+	default:
+		log.Printf("⚠ Aviso: ESTOQUE_MODO desconhecido (%s). Usando %s.", modo, ESTOQUE_MEMORIA)
+	}
+
 	return servidor
 }
 
@@ -448,6 +795,10 @@ func (s *Servidor) subscreverTopicos() {
 
 	s.MQTTClient.Subscribe("clientes/+/login", 0, s.handleClienteLogin)
 	s.MQTTClient.Subscribe("clientes/+/entrar_fila", 0, s.handleClienteEntrarFila)
+	s.MQTTClient.Subscribe("clientes/+/espectar", 0, s.handleClienteEspectar)
+	s.MQTTClient.Subscribe("clientes/+/retomar_sessao", 0, s.handleRetomarSessao)
+	s.MQTTClient.Subscribe("clientes/+/presenca", 0, s.handlePresencaCliente)
+	s.MQTTClient.Subscribe("clientes/+/fundir", 0, s.handleClienteFundir)
 	s.MQTTClient.Subscribe("partidas/+/comandos", 0, s.handleComandoPartida)
 	log.Println("Subscreveu aos tópicos MQTT essenciais")
 }
@@ -478,6 +829,14 @@ func (s *Servidor) handleInfoRequest(client mqtt.Client, msg mqtt.Message) {
 	s.publicarParaCliente(clientID, responseMsg)
 }
 
+// desafioLogin é um nonce enviado em DESAFIO_LOGIN que ainda aguarda a assinatura do cliente.
+type desafioLogin struct {
+	Nome     string
+	Endereco common.Address
+	Nonce    string
+	Expira   time.Time
+}
+
 func (s *Servidor) handleClienteLogin(client mqtt.Client, msg mqtt.Message) {
 	s.mutexClientes.Lock()         // Bloqueia logo no início
 	defer s.mutexClientes.Unlock() // Garante que desbloqueia ao sair
@@ -509,17 +868,105 @@ func (s *Servidor) handleClienteLogin(client mqtt.Client, msg mqtt.Message) {
 		return
 	}
 
-	log.Printf("[LOGIN_DEBUG:%s] Nome válido, criando cliente...", s.ServerID)
-	clienteID := uuid.New().String() // ID permanente
-	novoCliente := &tipos.Cliente{
-		ID:         clienteID,
-		Nome:       dados.Nome,
-		Inventario: make([]protocolo.Carta, 0),
+	// Login com carteira: só continua depois que a assinatura do desafio for verificada
+	enderecoProvado, autenticado := s.autenticarCarteira(tempClientID, dados)
+	if !autenticado {
+		return
+	}
+
+	// Primeiro login com carteira deste nome: o vínculo vai para o log replicado, para que nenhum
+	// servidor aceite depois o nome sem a carteira. A proposta espera o commit (e pode ir ao líder
+	// por HTTP), então não segura mutexClientes.
+	if enderecoProvado != "" && s.carteiraVinculada(dados.Nome) == "" {
+		s.mutexClientes.Unlock()
+		vinculada, err := s.vincularCarteiraNoCluster(dados.Nome, enderecoProvado)
+		s.mutexClientes.Lock()
+		if err != nil {
+			log.Printf("[LOGIN_ERRO:%s] Vínculo da carteira %s com %s não confirmado: %v", s.ServerID, enderecoProvado, dados.Nome, err)
+			s.notificarErro(tempClientID, "Não foi possível vincular a carteira à conta no cluster. Tente novamente.")
+			return
+		}
+		if !strings.EqualFold(vinculada, enderecoProvado) {
+			s.notificarErro(tempClientID, fmt.Sprintf("A conta %s está vinculada à carteira %s. Conecte essa carteira para entrar.", dados.Nome, vinculada))
+			return
+		}
+	}
+
+	respostaLogin := protocolo.DadosLoginOK{Servidor: s.MeuEndereco}
+
+	// Jogador que está retornando: reaproveita o ID, o inventário e o histórico salvos
+	var registro persistencia.RegistroJogador
+	retornando := false
+	if s.Jogadores != nil {
+		registro, retornando = s.Jogadores.BuscarPorNome(dados.Nome)
+	}
+
+	// Conta vinculada a uma carteira só aceita login assinado por ela
+	if retornando && registro.EnderecoBlockchain != "" && !strings.EqualFold(registro.EnderecoBlockchain, enderecoProvado) {
+		log.Printf("[LOGIN:%s] Login de %s recusado: conta vinculada à carteira %s (provada: '%s').", s.ServerID, dados.Nome, registro.EnderecoBlockchain, enderecoProvado)
+		s.notificarErro(tempClientID, fmt.Sprintf("A conta %s está vinculada à carteira %s. Conecte essa carteira para entrar.", dados.Nome, registro.EnderecoBlockchain))
+		return
+	}
+
+	var clienteID string
+	if retornando {
+		clienteID = registro.ID
+		cliente, jaConectado := s.Clientes[clienteID]
+		if !jaConectado {
+			cliente = &tipos.Cliente{
+				ID:                 clienteID,
+				Nome:               registro.Nome,
+				Inventario:         registro.Inventario,
+				EnderecoBlockchain: registro.EnderecoBlockchain,
+			}
+			if cliente.Inventario == nil {
+				cliente.Inventario = make([]protocolo.Carta, 0)
+			}
+			s.Clientes[clienteID] = cliente
+		}
+		cliente.Mutex.Lock()
+		respostaLogin.Perfil = &protocolo.DadosPerfilJogador{
+			Inventario: append([]protocolo.Carta(nil), cliente.Inventario...),
+			Historico:  registro.Historico,
+		}
+		cliente.Mutex.Unlock()
+		log.Printf("[LOGIN:%s] Jogador %s retornando (ID: %s, %d cartas, %d partidas no histórico).", s.ServerID, dados.Nome, clienteID, len(respostaLogin.Perfil.Inventario), len(registro.Historico))
+	} else {
+		log.Printf("[LOGIN_DEBUG:%s] Nome válido, criando cliente...", s.ServerID)
+		clienteID = uuid.New().String() // ID permanente
+		novoCliente := &tipos.Cliente{
+			ID:                 clienteID,
+			Nome:               dados.Nome,
+			Inventario:         make([]protocolo.Carta, 0),
+			EnderecoBlockchain: enderecoProvado,
+		}
+
+		log.Printf("[LOGIN_DEBUG:%s] Adicionando cliente ao mapa...", s.ServerID)
+		s.Clientes[clienteID] = novoCliente
+		log.Printf("[LOGIN_DEBUG:%s] Cliente adicionado ao mapa.", s.ServerID)
+
+		// Registra a conta para que compras e partidas futuras sejam persistidas
+		s.persistirJogador(novoCliente)
 	}
+	respostaLogin.ClienteID = clienteID
 
-	log.Printf("[LOGIN_DEBUG:%s] Adicionando cliente ao mapa...", s.ServerID)
-	s.Clientes[clienteID] = novoCliente
-	log.Printf("[LOGIN_DEBUG:%s] Cliente adicionado ao mapa.", s.ServerID)
+	// Novo token a cada login: permite retomar a sessão se a conexão MQTT cair
+	cliente := s.Clientes[clienteID]
+	cliente.Mutex.Lock()
+	cliente.TokenSessao = uuid.New().String()
+	cliente.UltimoContato = time.Now()
+	cliente.Ausente = false
+	respostaLogin.TokenSessao = cliente.TokenSessao
+	vincular := enderecoProvado != "" && cliente.EnderecoBlockchain != enderecoProvado
+	if vincular {
+		cliente.EnderecoBlockchain = enderecoProvado
+	}
+	cliente.Mutex.Unlock()
+	if vincular {
+		log.Printf("[LOGIN:%s] Carteira %s vinculada ao jogador %s.", s.ServerID, enderecoProvado, dados.Nome)
+		s.persistirJogador(cliente)
+	}
+	respostaLogin.Rating = s.ratingDe(dados.Nome)
 
 	log.Printf("[LOGIN:%s] Cliente %s (ID temp: %s, ID perm: %s) registrado e pronto.", s.ServerID, dados.Nome, tempClientID, clienteID)
 
@@ -527,1360 +974,2342 @@ func (s *Servidor) handleClienteLogin(client mqtt.Client, msg mqtt.Message) {
 	log.Printf("[LOGIN_DEBUG:%s] Enviando resposta LOGIN_OK...", s.ServerID)
 	resposta := protocolo.Mensagem{
 		Comando: "LOGIN_OK",
-		Dados:   seguranca.MustJSON(map[string]string{"cliente_id": clienteID, "servidor": s.MeuEndereco}),
+		Dados:   seguranca.MustJSON(respostaLogin),
 	}
 	s.publicarParaCliente(tempClientID, resposta)
 	log.Printf("[LOGIN_DEBUG:%s] Resposta LOGIN_OK enviada.", s.ServerID)
 }
 
-func (s *Servidor) handleClienteEntrarFila(client mqtt.Client, msg mqtt.Message) {
-	var dados map[string]string
-	if err := json.Unmarshal(msg.Payload(), &dados); err != nil {
-		log.Printf("[ENTRAR_FILA_ERRO:%s] Erro ao decodificar JSON: %v", s.ServerID, err)
-		return
+// carteiraVinculada retorna a carteira vinculada ao nome no log replicado ("" se não há vínculo).
+func (s *Servidor) carteiraVinculada(nome string) string {
+	s.mutexCarteiras.RLock()
+	defer s.mutexCarteiras.RUnlock()
+	return s.carteirasVinculadas[nome]
+}
+
+// vincularCarteiraNoCluster propõe o vínculo do nome com a carteira ao log replicado e espera a
+// entrada ser aplicada neste servidor. Retorna a carteira que ficou vinculada ao nome, que é outra
+// se um vínculo anterior do log venceu.
+func (s *Servidor) vincularCarteiraNoCluster(nome, endereco string) (string, error) {
+	if _, err := s.ClusterManager.Propor("VINCULO_CARTEIRA", tipos.EntradaVinculoCarteira{Nome: nome, Endereco: endereco}); err != nil {
+		return "", err
 	}
-	clienteID := dados["cliente_id"] // ID PERMANENTE enviado pelo cliente
+	// Num seguidor, a entrada confirmada pelo líder chega a este servidor no próximo AppendEntries
+	limite := time.Now().Add(ESPERA_VINCULO_CARTEIRA)
+	for {
+		if vinculada := s.carteiraVinculada(nome); vinculada != "" {
+			return vinculada, nil
+		}
+		if time.Now().After(limite) {
+			return "", fmt.Errorf("vínculo confirmado pelo cluster ainda não aplicado neste servidor")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
 
-	s.mutexClientes.RLock() // Lock de leitura para verificar
-	cliente, existe := s.Clientes[clienteID]
-	nomeCliente := ""
-	if existe {
-		cliente.Mutex.Lock() // Lock no cliente específico para ler o nome
-		nomeCliente = cliente.Nome
-		cliente.Mutex.Unlock()
+// autenticarCarteira trata a parte do login que prova a posse de uma carteira. Sem Endereco o login
+// segue só pelo nome (endereço vazio), a menos que o nome já esteja vinculado a uma carteira no log
+// replicado. Com Endereco e sem assinatura, envia um DESAFIO_LOGIN e interrompe o login; com a
+// assinatura, confere o nonce pendente e recupera o endereço que assinou.
+// Deve ser chamada com s.mutexClientes travado.
+func (s *Servidor) autenticarCarteira(tempClientID string, dados protocolo.DadosLogin) (string, bool) {
+	vinculada := s.carteiraVinculada(dados.Nome)
+	if dados.Endereco == "" {
+		if vinculada != "" {
+			log.Printf("[LOGIN:%s] Login de %s sem carteira recusado: conta vinculada à carteira %s.", s.ServerID, dados.Nome, vinculada)
+			s.notificarErro(tempClientID, fmt.Sprintf("A conta %s está vinculada à carteira %s. Conecte essa carteira para entrar.", dados.Nome, vinculada))
+			return "", false
+		}
+		return "", true
+	}
+	if !common.IsHexAddress(dados.Endereco) {
+		s.notificarErro(tempClientID, "Endereço de carteira inválido.")
+		return "", false
+	}
+	endereco := common.HexToAddress(dados.Endereco)
+	if vinculada != "" && !strings.EqualFold(vinculada, endereco.Hex()) {
+		s.notificarErro(tempClientID, fmt.Sprintf("A conta %s está vinculada à carteira %s. Conecte essa carteira para entrar.", dados.Nome, vinculada))
+		return "", false
 	}
-	s.mutexClientes.RUnlock()
 
-	// Verifica se o cliente existe E se o nome não está vazio
-	if !existe || nomeCliente == "" {
-		log.Printf("[ENTRAR_FILA_ERRO:%s] Cliente %s não encontrado ou nome ainda vazio (login pode não ter sido concluído).", s.ServerID, clienteID)
-		// Notificar o cliente seria ideal aqui
-		s.publicarParaCliente(clienteID, protocolo.Mensagem{Comando: "ERRO", Dados: seguranca.MustJSON(protocolo.DadosErro{Mensagem: "Erro ao entrar na fila. Tente novamente."})})
-		return
+	agora := time.Now()
+	for id, desafio := range s.desafiosLogin {
+		if agora.After(desafio.Expira) {
+			delete(s.desafiosLogin, id)
+		}
 	}
 
-	// Se chegou aqui, o cliente existe e tem nome (login concluído)
-	log.Printf("[ENTRAR_FILA:%s] Cliente %s (%s) encontrado. Adicionando à fila.", s.ServerID, nomeCliente, clienteID)
-	s.entrarFila(cliente) // Chama a função que adiciona à fila e inicia a busca
-}
+	if dados.Assinatura == "" {
+		desafio := desafioLogin{
+			Nome:     dados.Nome,
+			Endereco: endereco,
+			Nonce:    seguranca.NovoNonce(),
+			Expira:   agora.Add(seguranca.VALIDADE_DESAFIO_LOGIN),
+		}
+		s.desafiosLogin[tempClientID] = desafio
+		log.Printf("[LOGIN:%s] Desafio de carteira enviado para %s (%s).", s.ServerID, dados.Nome, endereco.Hex())
+		s.publicarParaCliente(tempClientID, protocolo.Mensagem{
+			Comando: "DESAFIO_LOGIN",
+			Dados: seguranca.MustJSON(protocolo.DadosDesafioLogin{
+				Nonce:    desafio.Nonce,
+				Mensagem: seguranca.MensagemDesafio(desafio.Nome, desafio.Endereco.Hex(), desafio.Nonce),
+				Expira:   desafio.Expira.Unix(),
+			}),
+		})
+		return "", false
+	}
 
-func (s *Servidor) handleComandoPartida(client mqtt.Client, msg mqtt.Message) {
-	// CORREÇÃO: Adicionar logs detalhados para debugging
-	timestamp := time.Now().Format("15:04:05.000")
-	log.Printf("[%s][COMANDO_DEBUG] === INÍCIO PROCESSAMENTO COMANDO ===", timestamp)
+	// O desafio vale uma única tentativa, com o mesmo nome e carteira do pedido original
+	desafio, ok := s.desafiosLogin[tempClientID]
+	delete(s.desafiosLogin, tempClientID)
+	if !ok || desafio.Nonce != dados.Nonce || desafio.Nome != dados.Nome || desafio.Endereco != endereco {
+		s.notificarErro(tempClientID, "Desafio de login desconhecido ou expirado. Tente novamente.")
+		return "", false
+	}
+	assinante, err := seguranca.RecuperarEndereco(seguranca.MensagemDesafio(desafio.Nome, desafio.Endereco.Hex(), desafio.Nonce), dados.Assinatura)
+	if err != nil || assinante != endereco {
+		log.Printf("[LOGIN:%s] Assinatura de carteira recusada para %s (%s): assinante %s, erro %v", s.ServerID, dados.Nome, endereco.Hex(), assinante.Hex(), err)
+		s.notificarErro(tempClientID, "Assinatura da carteira inválida.")
+		return "", false
+	}
+	return endereco.Hex(), true
+}
 
-	// Extrai o ID da sala do tópico
-	topico := msg.Topic()
-	// topico formato: "partidas/{salaID}/comandos"
-	partes := strings.Split(topico, "/")
-	if len(partes) < 2 {
-		log.Printf("[%s][COMANDO_ERRO] Tópico inválido: %s", timestamp, topico)
+// handleRetomarSessao reassocia um cliente que perdeu a conexão ao seu tipos.Cliente (mesmo ID,
+// inventário e sala) a partir do token emitido no LOGIN_OK. Como no login, a resposta vai para o
+// tópico temporário clientes/{tempID}/eventos.
+func (s *Servidor) handleRetomarSessao(client mqtt.Client, msg mqtt.Message) {
+	parts := strings.Split(msg.Topic(), "/")
+	if len(parts) < 3 {
+		log.Printf("[SESSAO_ERRO:%s] Tópico de retomada inválido: %s", s.ServerID, msg.Topic())
 		return
 	}
-	salaID := partes[1]
-
-	log.Printf("[%s][COMANDO_DEBUG] Comando recebido no tópico: %s", timestamp, topico)
-	log.Printf("[%s][COMANDO_DEBUG] Payload: %s", timestamp, string(msg.Payload()))
+	tempClientID := parts[1]
 
 	var mensagem protocolo.Mensagem
 	if err := json.Unmarshal(msg.Payload(), &mensagem); err != nil {
-		log.Printf("[%s][COMANDO_ERRO] Erro ao decodificar comando: %v", timestamp, err)
+		log.Printf("[SESSAO_ERRO:%s] Erro ao decodificar mensagem: %v", s.ServerID, err)
 		return
 	}
+	var dados protocolo.DadosRetomarSessao
+	json.Unmarshal(mensagem.Dados, &dados)
 
-	log.Printf("[%s][COMANDO_DEBUG] Comando decodificado: %s", timestamp, mensagem.Comando)
-	log.Printf("[%s][COMANDO_DEBUG] Buscando sala %s...", timestamp, salaID)
-
-	s.mutexSalas.RLock()
-	sala, existe := s.Salas[salaID]
-	s.mutexSalas.RUnlock()
-
-	if !existe {
-		log.Printf("[%s][COMANDO_DEBUG] ⚠️ Sala %s NÃO encontrada! Comando será ignorado.", timestamp, salaID)
+	cliente := s.buscarClientePorToken(dados.TokenSessao)
+	if cliente == nil {
+		log.Printf("[SESSAO:%s] Token de sessão desconhecido recebido de %s.", s.ServerID, tempClientID)
+		s.notificarErro(tempClientID, "Sessão inválida ou expirada. Faça login novamente.")
 		return
 	}
 
-	log.Printf("[%s][COMANDO_DEBUG] ✅ Sala %s encontrada. Estado: %s", timestamp, salaID, sala.Estado)
+	cliente.Mutex.Lock()
+	estavaAusente := cliente.Ausente
+	cliente.UltimoContato = time.Now()
+	cliente.Ausente = false
+	resposta := protocolo.DadosSessaoRetomada{
+		ClienteID:  cliente.ID,
+		Servidor:   s.MeuEndereco,
+		Inventario: append([]protocolo.Carta(nil), cliente.Inventario...),
+	}
+	nome := cliente.Nome
+	cliente.Mutex.Unlock()
+	resposta.Rating = s.ratingDe(nome)
+
+	if sala := s.salaAtivaDoJogador(cliente.ID); sala != nil {
+		estado := s.criarEstadoDaSala(sala.Retrato())
+		// O cliente só recebe o próprio inventário
+		for _, j := range estado.Jogadores {
+			if j.ID == cliente.ID {
+				estado.Jogadores = []tipos.JogadorEstado{j}
+				break
+			}
+		}
+		for _, j := range sala.Jogadores {
+			if j.ID != cliente.ID {
+				resposta.OponenteID = j.ID
+				resposta.OponenteNome = j.Nome
+			}
+		}
+		resposta.SalaID = sala.ID
+		resposta.Estado = seguranca.MustJSON(estado)
 
-	// Verifica se este servidor é o Host ou Sombra
-	sala.Mutex.Lock()
-	servidorHost := sala.ServidorHost
-	servidorSombra := sala.ServidorSombra
-	sala.Mutex.Unlock()
+		if estavaAusente {
+			s.difundirEventoPartida(sala, protocolo.Mensagem{
+				Comando: "SISTEMA",
+				Dados:   seguranca.MustJSON(protocolo.DadosErro{Mensagem: fmt.Sprintf("%s reconectou.", nome)}),
+			})
+		}
+	}
 
-	// Processa comando baseado no tipo
-	log.Printf("[%s][COMANDO_DEBUG] Processando comando: %s", timestamp, mensagem.Comando)
-	switch mensagem.Comando {
-	case "COMPRAR_PACOTE":
-		var dados map[string]string
-		json.Unmarshal(mensagem.Dados, &dados)
-		clienteID := dados["cliente_id"]
+	log.Printf("[SESSAO:%s] Sessão de %s (%s) retomada. Sala: '%s'", s.ServerID, nome, cliente.ID, resposta.SalaID)
+	s.publicarParaCliente(tempClientID, protocolo.Mensagem{Comando: "SESSAO_RETOMADA", Dados: seguranca.MustJSON(resposta)})
+}
 
-		// Armazena endereço da blockchain se fornecido
-		if enderecoBlockchain, ok := dados["endereco"]; ok && enderecoBlockchain != "" {
-			s.mutexClientes.Lock()
-			if cliente, existe := s.Clientes[clienteID]; existe {
-				cliente.EnderecoBlockchain = enderecoBlockchain
-				log.Printf("[BLOCKCHAIN] Endereço blockchain armazenado para jogador %s: %s", clienteID, enderecoBlockchain)
-			}
-			s.mutexClientes.Unlock()
+// handlePresencaCliente registra o sinal de presença periódico enviado pelo cliente.
+func (s *Servidor) handlePresencaCliente(client mqtt.Client, msg mqtt.Message) {
+	parts := strings.Split(msg.Topic(), "/")
+	if len(parts) < 3 {
+		return
+	}
+	cliente := s.getClienteLocal(parts[1])
+	if cliente == nil {
+		return
+	}
+	cliente.Mutex.Lock()
+	cliente.UltimoContato = time.Now()
+	cliente.Mutex.Unlock()
+}
+
+func (s *Servidor) buscarClientePorToken(token string) *tipos.Cliente {
+	if token == "" {
+		return nil
+	}
+	s.mutexClientes.RLock()
+	defer s.mutexClientes.RUnlock()
+	for _, cliente := range s.Clientes {
+		cliente.Mutex.Lock()
+		valido := cliente.TokenSessao == token
+		cliente.Mutex.Unlock()
+		if valido {
+			return cliente
 		}
+	}
+	return nil
+}
 
-		// CORREÇÃO: Aplicar lógica Host/Shadow
-		sala.Mutex.Lock()
-		servidorHost := sala.ServidorHost
-		servidorSombra := sala.ServidorSombra
-		sala.Mutex.Unlock()
+// salaAtivaDoJogador retorna a sala não finalizada em que o jogador está, se houver.
+func (s *Servidor) salaAtivaDoJogador(clienteID string) *tipos.Sala {
+	s.mutexSalas.RLock()
+	salas := make([]*tipos.Sala, 0, len(s.Salas))
+	for _, sala := range s.Salas {
+		salas = append(salas, sala)
+	}
+	s.mutexSalas.RUnlock()
 
-		// Sempre processa compra localmente
-		s.processarCompraPacote(clienteID, sala)
+	for _, sala := range salas {
+		if sala.Retrato().Estado != "FINALIZADO" && s.getClienteDaSala(sala, clienteID) != nil {
+			return sala
+		}
+	}
+	return nil
+}
 
-		// AGORA, notificamos o Host se formos o Shadow
-		if servidorHost == s.MeuEndereco {
-			// Eu sou o Host. processarCompraPacote já chamou verificarEIniciarPartidaSeProntos.
-			log.Printf("[HOST] Compra processada localmente para %s. Verificando prontos.", clienteID)
-		} else if servidorSombra == s.MeuEndereco {
-			// Eu sou o Shadow. Além de processar a compra,
-			// devo notificar o Host que este jogador está PRONTO.
-			log.Printf("[SHADOW] Compra processada localmente para %s. Notificando Host %s que estou pronto.", clienteID, servidorHost)
-			go s.encaminharEventoParaHost(sala, clienteID, "PLAYER_READY", nil)
+// monitorarSessoes acompanha os jogadores locais em partidas: sem presença por PRESENCA_TIMEOUT
+// o jogador é anunciado como ausente; sem retomar a sessão dentro de ToleranciaReconexao,
+// perde a partida por W.O.
+func (s *Servidor) monitorarSessoes() {
+	ticker := time.NewTicker(PRESENCA_INTERVALO)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.mutexSalas.RLock()
+		salas := make([]*tipos.Sala, 0, len(s.Salas))
+		for _, sala := range s.Salas {
+			salas = append(salas, sala)
 		}
+		s.mutexSalas.RUnlock()
 
-	case "JOGAR_CARTA":
-		log.Printf("[MQTT_CMD_DEBUG] === JOGAR_CARTA recebido no main.go ===")
-		var dados map[string]interface{}
-		json.Unmarshal(mensagem.Dados, &dados)
-		clienteID := dados["cliente_id"].(string)
-		cartaID := dados["carta_id"].(string)
+		for _, sala := range salas {
+			if sala.Retrato().Estado == "FINALIZADO" {
+				continue
+			}
 
-		log.Printf("[MQTT_CMD_DEBUG] clienteID=%s, cartaID=%s", clienteID, cartaID)
+			for _, j := range sala.Jogadores {
+				cliente := s.getClienteLocal(j.ID)
+				if cliente == nil {
+					continue // Jogador de outro servidor: monitorado por ele
+				}
+				cliente.Mutex.Lock()
+				ultimoContato := cliente.UltimoContato
+				jaAvisado := cliente.Ausente
+				nome := cliente.Nome
+				if !ultimoContato.IsZero() && time.Since(ultimoContato) > PRESENCA_TIMEOUT {
+					cliente.Ausente = true
+				}
+				cliente.Mutex.Unlock()
+				if ultimoContato.IsZero() {
+					continue
+				}
 
-		// Se este servidor é o Host, processa diretamente
-		if servidorHost == s.MeuEndereco {
-			log.Printf("[MQTT_CMD_DEBUG] Sou o Host. Criando GameEventRequest...")
-			// CORREÇÃO: Construir o objeto GameEventRequest
-			eventoReq := &tipos.GameEventRequest{
-				MatchID:   sala.ID,
-				EventSeq:  0, // O Host definirá o eventSeq correto
-				EventType: "CARD_PLAYED",
-				PlayerID:  clienteID,
-				Data: map[string]interface{}{
-					"carta_id": cartaID,
-				},
+				ausencia := time.Since(ultimoContato)
+				switch {
+				case ausencia > s.ToleranciaReconexao:
+					log.Printf("[SESSAO:%s] %s (%s) não retomou a sessão em %v. Declarando W.O. na sala %s.", s.ServerID, nome, j.ID, s.ToleranciaReconexao, sala.ID)
+					s.declararAbandono(sala, j.ID, "desconexao")
+				case ausencia > PRESENCA_TIMEOUT && !jaAvisado:
+					restante := (s.ToleranciaReconexao - ausencia).Round(time.Second)
+					log.Printf("[SESSAO:%s] %s (%s) ausente na sala %s. Prazo para voltar: %v", s.ServerID, nome, j.ID, sala.ID, restante)
+					s.difundirEventoPartida(sala, protocolo.Mensagem{
+						Comando: "SISTEMA",
+						Dados:   seguranca.MustJSON(protocolo.DadosErro{Mensagem: fmt.Sprintf("%s desconectou. Se não voltar em %v, perde a partida por W.O.", nome, restante)}),
+					})
+				}
 			}
-			log.Printf("[MQTT_CMD_DEBUG] Chamando processarEventoComoHost...")
-			s.processarEventoComoHost(sala, eventoReq) // <-- CHAMADA CORRIGIDA
-			log.Printf("[MQTT_CMD_DEBUG] processarEventoComoHost retornou")
-
-		} else if servidorSombra == s.MeuEndereco {
-			// Se é a Sombra, encaminha para o Host via API REST
-			s.encaminharJogadaParaHost(sala, clienteID, cartaID)
 		}
+	}
+}
 
-	// Em func (s *Servidor) handleComandoPartida
-	case "CHAT":
-		var dadosCliente protocolo.DadosEnviarChat
-		if err := json.Unmarshal(mensagem.Dados, &dadosCliente); err != nil {
-			log.Printf("[CHAT_ERRO] Erro ao decodificar dados do chat: %v", err)
-			return
-		}
+// declararAbandono pede à goroutine da sala o encerramento da partida com derrota por W.O. do jogador.
+func (s *Servidor) declararAbandono(sala *tipos.Sala, clienteID, motivo string) {
+	err := s.enviarComandoSala(sala, protocolo.Comando{
+		ClienteID: clienteID,
+		Tipo:      CMD_ABANDONO,
+		Payload:   seguranca.MustJSON(map[string]string{"motivo": motivo}),
+	})
+	if err != nil {
+		log.Printf("[SESSAO:%s] W.O. de %s na sala %s não enviado: %v", s.ServerID, clienteID, sala.ID, err)
+	}
+}
 
-		// CORREÇÃO: Aplicar lógica Host/Shadow
-		sala.Mutex.Lock()
-		servidorHost := sala.ServidorHost
-		servidorSombra := sala.ServidorSombra
-		sala.Mutex.Unlock()
+// executarAbandono aplica o W.O. A decisão é do Host; se este servidor for a Sombra, o evento é encaminhado a ele.
+func (s *Servidor) executarAbandono(sala *tipos.Sala, clienteID, motivo string) {
+	if sala.ServidorHost == s.MeuEndereco {
+		s.processarEventoComoHost(sala, &tipos.GameEventRequest{
+			MatchID:   sala.ID,
+			EventType: "PLAYER_FORFEIT",
+			PlayerID:  clienteID,
+			Data:      map[string]interface{}{"motivo": motivo},
+		})
+		return
+	}
+	go s.encaminharEventoParaHost(sala, clienteID, "PLAYER_FORFEIT", map[string]interface{}{"motivo": motivo})
+}
 
-		s.mutexClientes.RLock()
-		cliente := s.Clientes[dadosCliente.ClienteID]
-		s.mutexClientes.RUnlock()
-		if cliente == nil {
-			return
-		}
+func (s *Servidor) handleClienteEntrarFila(client mqtt.Client, msg mqtt.Message) {
+	var dados map[string]string
+	if err := json.Unmarshal(msg.Payload(), &dados); err != nil {
+		log.Printf("[ENTRAR_FILA_ERRO:%s] Erro ao decodificar JSON: %v", s.ServerID, err)
+		return
+	}
+	clienteID := dados["cliente_id"] // ID PERMANENTE enviado pelo cliente
+	variante := s.normalizarVariante(dados["regras"])
+	if _, err := regras.Nova(variante); err != nil {
+		s.notificarErro(clienteID, fmt.Sprintf("Não foi possível entrar na fila: %v", err))
+		return
+	}
+	formato, err := s.normalizarFormato(dados["formato"])
+	if err != nil {
+		s.notificarErro(clienteID, fmt.Sprintf("Não foi possível entrar na fila: %v", err))
+		return
+	}
 
-		if servidorHost == s.MeuEndereco {
-			// Eu sou o Host, eu faço o broadcast
-			log.Printf("[HOST-CHAT] Recebido chat de %s. Fazendo broadcast.", cliente.Nome)
-			s.retransmitirChat(sala, cliente, dadosCliente.Texto)
-		} else if servidorSombra == s.MeuEndereco {
-			// Eu sou o Shadow, encaminho para o Host
-			log.Printf("[SHADOW-CHAT] Recebido chat de %s. Encaminhando para Host %s.", cliente.Nome, servidorHost)
-			dadosEvento := map[string]interface{}{
-				"texto": dadosCliente.Texto,
-			}
-			go s.encaminharEventoParaHost(sala, dadosCliente.ClienteID, "CHAT", dadosEvento)
-		}
+	s.mutexClientes.RLock() // Lock de leitura para verificar
+	cliente, existe := s.Clientes[clienteID]
+	nomeCliente := ""
+	if existe {
+		cliente.Mutex.Lock() // Lock no cliente específico para ler o nome
+		nomeCliente = cliente.Nome
+		cliente.Mutex.Unlock()
+	}
+	s.mutexClientes.RUnlock()
 
-	case "TROCAR_CARTAS", "TROCAR_CARTAS_OFERTA":
-		var req protocolo.TrocarCartasReq
-		if err := json.Unmarshal(mensagem.Dados, &req); err != nil {
-			log.Printf("[TROCA_ERRO] Erro ao decodificar requisição de troca: %v", err)
-			return
-		}
-		s.processarTrocaCartas(sala, &req)
+	// Verifica se o cliente existe E se o nome não está vazio
+	if !existe || nomeCliente == "" {
+		log.Printf("[ENTRAR_FILA_ERRO:%s] Cliente %s não encontrado ou nome ainda vazio (login pode não ter sido concluído).", s.ServerID, clienteID)
+		// Notificar o cliente seria ideal aqui
+		s.publicarParaCliente(clienteID, protocolo.Mensagem{Comando: "ERRO", Dados: seguranca.MustJSON(protocolo.DadosErro{Mensagem: "Erro ao entrar na fila. Tente novamente."})})
+		return
+	}
 
-	case "SINCRONIZAR_CARTAS":
-		log.Printf("[SYNC_CARTAS] === COMANDO SINCRONIZAR_CARTAS RECEBIDO ===")
-		var dados struct {
-			ClienteID string            `json:"cliente_id"`
-			Cartas    []protocolo.Carta `json:"cartas"`
-		}
-		if err := json.Unmarshal(mensagem.Dados, &dados); err != nil {
-			log.Printf("[SYNC_ERRO] Erro ao decodificar sincronização: %v", err)
-			return
-		}
+	// Se chegou aqui, o cliente existe e tem nome (login concluído)
+	log.Printf("[ENTRAR_FILA:%s] Cliente %s (%s) encontrado. Adicionando à fila (regras %s, formato %s).", s.ServerID, nomeCliente, clienteID, variante, formato)
+	cliente.Mutex.Lock()
+	cliente.VarianteFila = variante
+	cliente.FormatoFila = formato
+	cliente.Mutex.Unlock()
+	s.entrarFila(cliente) // Chama a função que adiciona à fila e inicia a busca
+}
 
-		log.Printf("[SYNC_CARTAS] 🔄 Sincronizando %d cartas para clienteID=%s", len(dados.Cartas), dados.ClienteID)
-		if len(dados.Cartas) > 0 {
-			log.Printf("[SYNC_CARTAS] Primeira carta: ID='%s' (len=%d), Nome=%s, Valor=%d", dados.Cartas[0].ID, len(dados.Cartas[0].ID), dados.Cartas[0].Nome, dados.Cartas[0].Valor)
-			log.Printf("[SYNC_CARTAS] TODAS as cartas recebidas: %+v", dados.Cartas)
-			// Lista todos os IDs recebidos
-			idsRecebidos := make([]string, len(dados.Cartas))
-			for i, c := range dados.Cartas {
-				idsRecebidos[i] = c.ID
-			}
-			log.Printf("[SYNC_CARTAS] 📋 IDs recebidos: %v", idsRecebidos)
-		}
+// handleClienteEspectar registra (ESPECTAR) ou remove (PARAR_ESPECTAR) um espectador de uma sala.
+// O espectador só recebe os eventos públicos de partidas/{salaID}/eventos; comandos publicados
+// por ele em partidas/{salaID}/comandos são rejeitados em handleComandoPartida.
+func (s *Servidor) handleClienteEspectar(client mqtt.Client, msg mqtt.Message) {
+	var mensagem protocolo.Mensagem
+	if err := json.Unmarshal(msg.Payload(), &mensagem); err != nil {
+		log.Printf("[ESPECTAR_ERRO:%s] Erro ao decodificar mensagem: %v", s.ServerID, err)
+		return
+	}
+	var dados protocolo.DadosEspectar
+	if err := json.Unmarshal(mensagem.Dados, &dados); err != nil {
+		log.Printf("[ESPECTAR_ERRO:%s] Erro ao decodificar dados: %v", s.ServerID, err)
+		return
+	}
 
-		// Atualiza inventário no servidor local (Host ou Sombra)
-		s.mutexClientes.RLock()
-		cliente := s.Clientes[dados.ClienteID]
-		s.mutexClientes.RUnlock()
+	if mensagem.Comando == "PARAR_ESPECTAR" {
+		s.removerEspectador(dados.ClienteID)
+		s.publicarParaCliente(dados.ClienteID, protocolo.Mensagem{Comando: "SISTEMA", Dados: seguranca.MustJSON(protocolo.DadosErro{Mensagem: "Você deixou de assistir a partida."})})
+		return
+	}
 
-		if cliente != nil {
-			cliente.Mutex.Lock()
-			antigoTamanho := len(cliente.Inventario)
-			antigosIDs := make([]string, len(cliente.Inventario))
-			for i, c := range cliente.Inventario {
-				antigosIDs[i] = c.ID
-			}
-			cliente.Inventario = dados.Cartas
-			novosIDs := make([]string, len(cliente.Inventario))
-			for i, c := range cliente.Inventario {
-				novosIDs[i] = c.ID
-			}
-			cliente.Mutex.Unlock()
-			log.Printf("[SYNC_CARTAS] ✅✅✅ Inventário atualizado para %s: %d -> %d cartas ✅✅✅", cliente.Nome, antigoTamanho, len(dados.Cartas))
-			log.Printf("[SYNC_CARTAS] 📋 IDs ANTES: %v", antigosIDs)
-			log.Printf("[SYNC_CARTAS] 📋 IDs DEPOIS: %v", novosIDs)
-			// Verifica se a carta "23" está presente
-			for _, id := range novosIDs {
-				if id == "23" {
-					log.Printf("[SYNC_CARTAS] ✅ Carta '23' está presente no inventário após sincronização!")
-					break
-				}
-			}
+	if s.getClienteLocal(dados.ClienteID) == nil {
+		log.Printf("[ESPECTAR_ERRO:%s] Cliente %s não está logado neste servidor.", s.ServerID, dados.ClienteID)
+		s.notificarErro(dados.ClienteID, "Faça login neste servidor antes de assistir uma partida.")
+		return
+	}
 
-			// IMPORTANTE: Também atualiza o inventário do jogador na sala para manter sincronizado
-			sala.Mutex.Lock()
-			for i, jog := range sala.Jogadores {
-				if jog.ID == dados.ClienteID {
-					jog.Mutex.Lock()
-					jog.Inventario = dados.Cartas
-					jog.Mutex.Unlock()
-					log.Printf("[SYNC_CARTAS] ✅ Inventário do jogador na sala também atualizado (índice %d)", i)
-					break
-				}
-			}
-			sala.Mutex.Unlock()
-		} else {
-			log.Printf("[SYNC_CARTAS] ⚠️ AVISO: Cliente %s não encontrado no mapa local!", dados.ClienteID)
-		}
+	s.mutexSalas.RLock()
+	sala, existe := s.Salas[dados.SalaID]
+	s.mutexSalas.RUnlock()
+	if !existe {
+		s.notificarErro(dados.ClienteID, "Sala não encontrada neste servidor.")
+		return
+	}
+	if s.getClienteDaSala(sala, dados.ClienteID) != nil {
+		s.notificarErro(dados.ClienteID, "Você é jogador desta partida e não pode assisti-la como espectador.")
+		return
+	}
 
-		// Se for Sombra, encaminha para o Host
-		sala.Mutex.Lock()
-		host := sala.ServidorHost
-		sombra := sala.ServidorSombra
-		sala.Mutex.Unlock()
+	// Troca de sala: deixa de contar como espectador da anterior
+	s.removerEspectador(dados.ClienteID)
 
-		if s.MeuEndereco == sombra {
-			log.Printf("[SYNC_CARTAS] Sou Sombra. Encaminhando sincronização para Host %s", host)
-			go s.encaminharEventoParaHost(sala, dados.ClienteID, "SYNC_INVENTARIO", map[string]interface{}{
-				"cartas": dados.Cartas,
-			})
-		}
+	retrato := sala.Retrato()
+	if retrato.Estado == "FINALIZADO" {
+		s.notificarErro(dados.ClienteID, "Esta partida já terminou.")
+		return
 	}
-}
+	s.mutexEspectadores.Lock()
+	s.Espectadores[dados.ClienteID] = sala.ID
+	s.mutexEspectadores.Unlock()
+	nomes := make(map[string]string, len(sala.Jogadores))
+	for _, j := range sala.Jogadores {
+		nomes[j.ID] = j.Nome
+	}
+	resposta := protocolo.DadosEspectando{
+		SalaID:    sala.ID,
+		Jogadores: nomes,
+		Regras:    regras.Obter(retrato.Regras).Descricao(),
+		Estado:    s.atualizacaoParaEspectador(retrato),
+	}
+	totalEspectadores := resposta.Estado.Espectadores
 
-func (s *Servidor) publicarParaCliente(clienteID string, msg protocolo.Mensagem) {
-	payload, _ := json.Marshal(msg)
-	topico := fmt.Sprintf("clientes/%s/eventos", clienteID)
-	log.Printf("[PUBLICAR_CLIENTE] Enviando para %s no tópico %s: %s", clienteID, topico, string(payload))
-	s.MQTTClient.Publish(topico, 0, false, payload)
+	log.Printf("[ESPECTAR:%s] Cliente %s assistindo a sala %s (%d espectadores).", s.ServerID, dados.ClienteID, sala.ID, totalEspectadores)
+	s.publicarParaCliente(dados.ClienteID, protocolo.Mensagem{Comando: "ESPECTANDO", Dados: seguranca.MustJSON(resposta)})
 }
 
-func (s *Servidor) publicarEventoPartida(salaID string, msg protocolo.Mensagem) {
-	log.Printf("[PUB_EVENTO_DEBUG] === publicarEventoPartida INICIADO ===")
-	log.Printf("[PUB_EVENTO_DEBUG] salaID=%s, Comando=%s", salaID, msg.Comando)
-
-	payload, _ := json.Marshal(msg)
-	topico := fmt.Sprintf("partidas/%s/eventos", salaID)
-
-	log.Printf("[PUB_EVENTO_DEBUG] Tópico: %s", topico)
-	log.Printf("[PUB_EVENTO_DEBUG] Payload (primeiros 300 chars): %s", string(payload)[:min(300, len(string(payload)))])
-
-	token := s.MQTTClient.Publish(topico, 0, false, payload)
-	if token.Wait() && token.Error() != nil {
-		log.Printf("[PUB_EVENTO_DEBUG] ERRO ao publicar: %v", token.Error())
-	} else {
-		log.Printf("[PUB_EVENTO_DEBUG] Mensagem publicada com sucesso no tópico %s", topico)
+// handleClienteFundir funde cartas do inventário mantido pelo servidor (jogadores sem carteira).
+// Com carteira, a fusão é feita pelo próprio cliente no contrato e chega aqui pelos eventos da ponte.
+func (s *Servidor) handleClienteFundir(client mqtt.Client, msg mqtt.Message) {
+	// O jogador é o do tópico clientes/{id}/fundir, não o informado no payload
+	parts := strings.Split(msg.Topic(), "/")
+	if len(parts) < 3 {
+		log.Printf("[FUSAO_ERRO:%s] Tópico de fusão inválido: %s", s.ServerID, msg.Topic())
+		return
 	}
-	log.Printf("[PUB_EVENTO_DEBUG] === publicarEventoPartida FINALIZADO ===")
-}
+	clienteID := parts[1]
 
-func min(a, b int) int {
-	if a < b {
-		return a
+	var mensagem protocolo.Mensagem
+	if err := json.Unmarshal(msg.Payload(), &mensagem); err != nil {
+		log.Printf("[FUSAO_ERRO:%s] Erro ao decodificar mensagem: %v", s.ServerID, err)
+		return
+	}
+	var dados protocolo.DadosFundirCartas
+	if err := json.Unmarshal(mensagem.Dados, &dados); err != nil {
+		log.Printf("[FUSAO_ERRO:%s] Erro ao decodificar dados: %v", s.ServerID, err)
+		return
 	}
-	return b
-}
 
-// ==================== MATCHMAKING E LÓGICA DE JOGO ====================
+	cliente := s.getClienteLocal(clienteID)
+	if cliente == nil {
+		log.Printf("[FUSAO_ERRO:%s] Cliente %s não está logado neste servidor.", s.ServerID, clienteID)
+		s.notificarErro(clienteID, "Faça login neste servidor antes de fundir cartas.")
+		return
+	}
 
-func (s *Servidor) tentarMatchmakingGlobalPeriodicamente() {
-	// Aguarda um pouco no início para a rede de servidores se estabilizar
-	time.Sleep(10 * time.Second)
+	// A sala fica no cliente depois do fim da partida: só uma partida em andamento impede a fusão.
+	// O estado é lido antes do lock do cliente (a sala trava os jogadores sob o próprio lock).
+	cliente.Mutex.Lock()
+	sala := cliente.Sala
+	cliente.Mutex.Unlock()
+	emPartida := salaEmAndamento(sala)
 
-	ticker := time.NewTicker(5 * time.Second) // Tenta a cada 5 segundos
-	defer ticker.Stop()
+	cliente.Mutex.Lock()
+	if s.BlockchainManager != nil && cliente.EnderecoBlockchain != "" {
+		cliente.Mutex.Unlock()
+		s.notificarErro(clienteID, "Suas cartas estão na blockchain: a fusão deve ser feita com a sua carteira.")
+		return
+	}
+	if emPartida || cliente.Sala != sala {
+		cliente.Mutex.Unlock()
+		s.notificarErro(clienteID, "Não é possível fundir cartas durante uma partida.")
+		return
+	}
 
-	for range ticker.C {
-		s.mutexFila.Lock()
-		if len(s.FilaDeEspera) == 0 {
-			s.mutexFila.Unlock()
-			continue // Pula se a fila estiver vazia
+	posicoes := make(map[string]int, len(cliente.Inventario))
+	for i, carta := range cliente.Inventario {
+		posicoes[carta.ID] = i
+	}
+	cartas := make([]tipos.Carta, 0, len(dados.Cartas))
+	for _, id := range dados.Cartas {
+		i, ok := posicoes[id]
+		if !ok {
+			cliente.Mutex.Unlock()
+			s.notificarErro(clienteID, fmt.Sprintf("Você não possui a carta %s.", id))
+			return
 		}
-		// Pega o primeiro jogador sem removê-lo ainda
-		jogadorLocal := s.FilaDeEspera[0]
-		s.mutexFila.Unlock()
-
-		log.Printf("[MATCHMAKING-GLOBAL] Buscando oponente para %s (%s)...", jogadorLocal.Nome, jogadorLocal.ID)
+		cartas = append(cartas, cliente.Inventario[i])
+	}
 
-		// Pega a lista de servidores ativos, excluindo a si mesmo
-		servidoresAtivos := s.ClusterManager.GetServidoresAtivos(s.MeuEndereco)
+	nova, err := store.Fundir(cartas)
+	if err != nil {
+		cliente.Mutex.Unlock()
+		s.notificarErro(clienteID, fmt.Sprintf("Fusão recusada: %v.", err))
+		return
+	}
 
-		// Itera sobre os outros servidores para encontrar um oponente
-		for _, addr := range servidoresAtivos {
-			if s.realizarSolicitacaoMatchmaking(addr, jogadorLocal) {
-				// Sucesso! A partida foi formada. O jogador já foi removido da fila pela lógica de sucesso.
-				// A `realizarSolicitacaoMatchmaking` agora precisa remover o jogador da fila em caso de sucesso.
-				break // Para de procurar por este jogador
-			}
+	queimar := make(map[string]bool, len(dados.Cartas))
+	for _, id := range dados.Cartas {
+		queimar[id] = true
+	}
+	restantes := make([]tipos.Carta, 0, len(cliente.Inventario)-len(queimar)+1)
+	for _, carta := range cliente.Inventario {
+		if !queimar[carta.ID] {
+			restantes = append(restantes, carta)
 		}
 	}
-}
+	cliente.Inventario = append(restantes, nova)
+	inventario := append([]tipos.Carta(nil), cliente.Inventario...)
+	cliente.Mutex.Unlock()
 
-func (s *Servidor) entrarFila(cliente *tipos.Cliente) {
-	s.mutexFila.Lock()
-	// Tenta encontrar oponente na fila local primeiro
-	if len(s.FilaDeEspera) > 0 {
-		oponente := s.FilaDeEspera[0]
-		s.FilaDeEspera = s.FilaDeEspera[1:]
-		s.mutexFila.Unlock()
+	s.persistirJogador(cliente)
+	log.Printf("[FUSAO:%s] %s fundiu %v em %s (%s, raridade %s, valor %d)", s.ServerID, cliente.Nome, dados.Cartas, nova.ID, nova.Nome, nova.Raridade, nova.Valor)
 
-		// Cria sala localmente
-		s.criarSala(oponente, cliente, "")
+	s.publicarParaCliente(clienteID, protocolo.Mensagem{
+		Comando: "FUSAO_CONCLUIDA",
+		Dados: seguranca.MustJSON(protocolo.DadosCartasFundidas{
+			Queimadas:  dados.Cartas,
+			NovaCarta:  nova,
+			Inventario: inventario,
+		}),
+	})
+}
+
+// removerEspectador desfaz o registro de um espectador.
+func (s *Servidor) removerEspectador(clienteID string) {
+	s.mutexEspectadores.Lock()
+	salaID, assistindo := s.Espectadores[clienteID]
+	delete(s.Espectadores, clienteID)
+	s.mutexEspectadores.Unlock()
+	if !assistindo {
 		return
 	}
+	log.Printf("[ESPECTAR:%s] Cliente %s deixou de assistir a sala %s.", s.ServerID, clienteID, salaID)
+}
 
-	// Se não encontrou, adiciona à fila local
-	s.FilaDeEspera = append(s.FilaDeEspera, cliente)
-	s.mutexFila.Unlock()
+// ehJogadorDaSala indica se o cliente é um dos jogadores da sala.
+func ehJogadorDaSala(sala *tipos.Sala, clienteID string) bool {
+	if clienteID == "" {
+		return false
+	}
+	for _, jogador := range sala.Jogadores {
+		if jogador.ID == clienteID {
+			return true
+		}
+	}
+	return false
+}
 
-	log.Printf("Cliente %s (%s) entrou na fila de espera.", cliente.Nome, cliente.ID)
-	s.publicarParaCliente(cliente.ID, protocolo.Mensagem{
-		Comando: "AGUARDANDO_OPONENTE",
-		Dados:   seguranca.MustJSON(map[string]string{"mensagem": "Procurando oponente em todos os servidores..."}),
-	})
+// contarEspectadores é o número atual de espectadores da sala atendidos por este servidor. A contagem
+// fica só em s.Espectadores, fora da sala, então pode ser chamada pela goroutine da sala.
+func (s *Servidor) contarEspectadores(salaID string) int {
+	s.mutexEspectadores.Lock()
+	defer s.mutexEspectadores.Unlock()
+	total := 0
+	for _, assistida := range s.Espectadores {
+		if assistida == salaID {
+			total++
+		}
+	}
+	return total
+}
 
-	// O matchmaking global já é persistente, não precisamos mais do 'go func' aqui
-	// O go s.tentarMatchmakingGlobalPeriodicamente() no 'Run' cuidará disso
+// ehEspectadorDaSala indica se o cliente está registrado como espectador da sala.
+func (s *Servidor) ehEspectadorDaSala(clienteID, salaID string) bool {
+	s.mutexEspectadores.Lock()
+	defer s.mutexEspectadores.Unlock()
+	return clienteID != "" && s.Espectadores[clienteID] == salaID
 }
 
-// gerenciarBuscaGlobalPersistente tenta encontrar um oponente global periodicamente.
-func (s *Servidor) gerenciarBuscaGlobalPersistente(cliente *tipos.Cliente) {
-	ticker := time.NewTicker(5 * time.Second) // Tenta a cada 5 segundos
-	defer ticker.Stop()
-
-	// Cria um contexto para poder cancelar esta goroutine se o cliente sair/for matchado
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel() // Garante que o cancelamento seja chamado ao sair
-
-	// Armazena a função de cancelamento para uso posterior (ex: quando o cliente for matchado localmente)
-	// Assumindo que você adicione um campo `cancelBuscaGlobal context.CancelFunc` à struct Cliente
-	// cliente.cancelBuscaGlobal = cancel // (Descomente se adicionar o campo)
-
-	for {
-		select {
-		case <-ticker.C:
-			// 1. Verifica se o cliente AINDA está na fila deste servidor
-			s.mutexFila.Lock()
-			aindaNaFila := false
-			for _, c := range s.FilaDeEspera {
-				if c.ID == cliente.ID {
-					aindaNaFila = true
-					break
-				}
-			}
-			s.mutexFila.Unlock()
-
-			if !aindaNaFila {
-				log.Printf("[MATCHMAKING-PERSIST] Cliente %s (%s) não está mais na fila local. Parando busca global.", cliente.Nome, cliente.ID)
-				return // Cliente já foi matchado (localmente ou por outra tentativa), sai da rotina
-			}
-
-			// 2. Tenta encontrar um oponente global AGORA (sem remover da fila!)
-			log.Printf("[MATCHMAKING-PERSIST] Tentando busca global para %s (%s)", cliente.Nome, cliente.ID)
-			encontrou := s.tentarMatchmakingGlobalAgora(cliente)
-
-			if encontrou {
-				log.Printf("[MATCHMAKING-PERSIST] Busca global para %s (%s) encontrou partida! A busca será finalizada.", cliente.Nome, cliente.ID)
-				// A remoção da fila agora é feita dentro de `realizarSolicitacaoMatchmaking` no momento da confirmação.
-				// Apenas precisamos parar a busca.
-				return // Encontrou, sai da rotina
-			}
-			// Se não encontrou, o loop continua na próxima iteração do ticker.
-
-		case <-ctx.Done():
-			log.Printf("[MATCHMAKING-PERSIST] Busca global para %s (%s) cancelada.", cliente.Nome, cliente.ID)
-			return // Sai da rotina se o contexto for cancelado
+// liberarEspectadores remove os registros de espectadores de uma sala encerrada.
+// Executada na goroutine da sala (a partir de finalizarPartida).
+func (s *Servidor) liberarEspectadores(sala *tipos.Sala) {
+	s.mutexEspectadores.Lock()
+	for clienteID, salaID := range s.Espectadores {
+		if salaID == sala.ID {
+			delete(s.Espectadores, clienteID)
 		}
 	}
+	s.mutexEspectadores.Unlock()
 }
 
-// tentarMatchmakingGlobalAgora faz UMA tentativa de encontrar um oponente global.
-func (s *Servidor) tentarMatchmakingGlobalAgora(cliente *tipos.Cliente) bool {
-	// Busca oponente em outros servidores ATIVOS
-	servidores := s.ClusterManager.GetServidores()
-	servidoresAtivos := make([]string, 0, len(servidores))
-	for addr, info := range servidores {
-		if addr != s.MeuEndereco && info.Ativo {
-			servidoresAtivos = append(servidoresAtivos, addr)
+// atualizacaoParaEspectador monta a visão pública da sala: mesa, placar e quantidade de cartas
+// de cada jogador, sem o conteúdo das mãos. Executada na goroutine da sala ou sobre um Retrato.
+func (s *Servidor) atualizacaoParaEspectador(sala *tipos.Sala) protocolo.DadosAtualizacaoJogo {
+	contagemCartas := make(map[string]int, len(sala.Jogadores))
+	nomeDoTurno := ""
+	for _, j := range sala.Jogadores {
+		j.Mutex.Lock()
+		contagemCartas[j.Nome] = len(j.Inventario)
+		j.Mutex.Unlock()
+		if j.ID == sala.TurnoDe {
+			nomeDoTurno = j.Nome
 		}
 	}
 
-	if len(servidoresAtivos) == 0 {
-		// log.Printf("[MATCHMAKING-AGORA] Nenhum outro servidor ativo para buscar oponente para %s.", cliente.Nome)
-		return false // Não há ninguém para perguntar
+	cartasNaMesa := make(map[string]Carta, len(sala.CartasNaMesa))
+	for nome, carta := range sala.CartasNaMesa {
+		cartasNaMesa[nome] = carta
+	}
+	pontosRodada := make(map[string]int, len(sala.PontosRodada))
+	for nome, pontos := range sala.PontosRodada {
+		pontosRodada[nome] = pontos
+	}
+	pontosPartida := make(map[string]int, len(sala.PontosPartida))
+	for nome, pontos := range sala.PontosPartida {
+		pontosPartida[nome] = pontos
 	}
 
-	// Embaralha a ordem para não sobrecarregar sempre o mesmo servidor
-	rand.Shuffle(len(servidoresAtivos), func(i, j int) {
-		servidoresAtivos[i], servidoresAtivos[j] = servidoresAtivos[j], servidoresAtivos[i]
-	})
-
-	for _, addr := range servidoresAtivos {
-		if s.realizarSolicitacaoMatchmaking(addr, cliente) {
-			return true // Encontrou partida! A função interna já tratou de tudo.
-		}
+	mensagem := "Partida aguardando os jogadores comprarem cartas."
+	if sala.Estado == "JOGANDO" {
+		mensagem = fmt.Sprintf("Partida em andamento. Vez de %s.", nomeDoTurno)
 	}
 
-	// Se chegou aqui, não encontrou oponente em NENHUM servidor nesta tentativa.
-	return false
+	return protocolo.DadosAtualizacaoJogo{
+		MensagemDoTurno: mensagem,
+		ContagemCartas:  contagemCartas,
+		UltimaJogada:    cartasNaMesa,
+		NumeroRodada:    sala.NumeroRodada,
+		PontosRodada:    pontosRodada,
+		PontosPartida:   pontosPartida,
+		SalaID:          sala.ID,
+		TurnoDe:         sala.TurnoDe,
+		Espectadores:    s.contarEspectadores(sala.ID),
+		TempoRestante:   segundosRestantesTurno(sala),
+	}
 }
 
-// realizarSolicitacaoMatchmaking envia uma requisição de oponente para um servidor específico.
-func (s *Servidor) realizarSolicitacaoMatchmaking(addr string, cliente *tipos.Cliente) bool {
-	log.Printf("[MATCHMAKING-TX] Enviando solicitação para %s", addr)
-	reqBody, _ := json.Marshal(map[string]string{
-		"solicitante_id":   cliente.ID,
-		"solicitante_nome": cliente.Nome,
-		"servidor_origem":  s.MeuEndereco,
-	})
-
-	httpClient := &http.Client{Timeout: 15 * time.Second}
-	req, err := http.NewRequest("POST", fmt.Sprintf("http://%s/matchmaking/solicitar_oponente", addr), bytes.NewBuffer(reqBody))
-	if err != nil {
-		log.Printf("[MATCHMAKING-TX] Erro ao criar requisição para %s: %v", addr, err)
-		return false
+// remetenteDoComando identifica o jogador que publicou um comando de partida pelo token de sessão
+// da mensagem, emitido no LOGIN_OK deste servidor. Qualquer cliente MQTT pode publicar no tópico da
+// sala e escrever qualquer cliente_id no payload, mas só o próprio jogador conhece o token. Sem token
+// válido, retorna "".
+func (s *Servidor) remetenteDoComando(mensagem protocolo.Mensagem) string {
+	cliente := s.buscarClientePorToken(mensagem.Token)
+	if cliente == nil {
+		return ""
 	}
+	return cliente.ID
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+seguranca.GenerateJWT(s.ServerID))
+func (s *Servidor) handleComandoPartida(client mqtt.Client, msg mqtt.Message) {
+	// CORREÇÃO: Adicionar logs detalhados para debugging
+	timestamp := time.Now().Format("15:04:05.000")
+	log.Printf("[%s][COMANDO_DEBUG] === INÍCIO PROCESSAMENTO COMANDO ===", timestamp)
 
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		log.Printf("[MATCHMAKING-TX] Erro ao contatar servidor %s: %v", addr, err)
-		return false
+	// Extrai o ID da sala do tópico
+	topico := msg.Topic()
+	// topico formato: "partidas/{salaID}/comandos"
+	partes := strings.Split(topico, "/")
+	if len(partes) < 2 {
+		log.Printf("[%s][COMANDO_ERRO] Tópico inválido: %s", timestamp, topico)
+		return
 	}
-	defer resp.Body.Close()
+	salaID := partes[1]
 
-	var res struct {
-		PartidaEncontrada bool   `json:"partida_encontrada"`
-		SalaID            string `json:"sala_id"`
-		OponenteNome      string `json:"oponente_nome"`
-		OponenteID        string `json:"oponente_id"`
-		ServidorHost      string `json:"servidor_host"`
-	}
+	log.Printf("[%s][COMANDO_DEBUG] Comando recebido no tópico: %s", timestamp, topico)
+	log.Printf("[%s][COMANDO_DEBUG] Payload: %s", timestamp, string(msg.Payload()))
 
-	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
-		log.Printf("[MATCHMAKING-TX] Erro ao decodificar resposta de %s: %v", addr, err)
-		return false
+	var mensagem protocolo.Mensagem
+	if err := json.Unmarshal(msg.Payload(), &mensagem); err != nil {
+		log.Printf("[%s][COMANDO_ERRO] Erro ao decodificar comando: %v", timestamp, err)
+		return
 	}
 
-	if res.PartidaEncontrada {
-		log.Printf("[MATCHMAKING-TX] Partida encontrada no servidor %s! Oponente: %s", addr, res.OponenteNome)
-
-		// A requisição foi bem-sucedida, então removemos nosso cliente da fila local.
-		s.RemoverPrimeiroDaFila()
+	log.Printf("[%s][COMANDO_DEBUG] Comando decodificado: %s", timestamp, mensagem.Comando)
+	log.Printf("[%s][COMANDO_DEBUG] Buscando sala %s...", timestamp, salaID)
 
-		// Cria um objeto Cliente para o oponente remoto
+	s.mutexSalas.RLock()
+	sala, existe := s.Salas[salaID]
+	s.mutexSalas.RUnlock()
 
-		s.criarSalaComoSombra(cliente, res.SalaID, res.OponenteID, res.OponenteNome, res.ServidorHost)
-		return true // Sucesso!
+	if !existe {
+		log.Printf("[%s][COMANDO_DEBUG] ⚠️ Sala %s NÃO encontrada! Comando será ignorado.", timestamp, salaID)
+		return
 	}
 
-	log.Printf("[MATCHMAKING-TX] Servidor %s não encontrou oponente.", addr)
-	return false
-}
-
-// Em: servidor/main.go
-// ADICIONAR esta nova função
-// Em: servidor/main.go
-// SUBSTITUA a função 'criarSalaComoSombra' (Etapa 7) por esta:
+	log.Printf("[%s][COMANDO_DEBUG] ✅ Sala %s encontrada. Estado: %s", timestamp, salaID, sala.Retrato().Estado)
 
-func (s *Servidor) criarSalaComoSombra(jogadorLocal *tipos.Cliente, salaID string, oponenteID string, oponenteNome string, hostAddr string) {
-	// Cria objeto para oponente remoto (o Host)
-	oponenteRemoto := &tipos.Cliente{
-		ID:   oponenteID,
-		Nome: oponenteNome,
+	remetente := s.remetenteDoComando(mensagem)
+	if remetente == "" {
+		log.Printf("[%s][COMANDO_ERRO] Comando %s rejeitado: sem token de sessão válido", timestamp, mensagem.Comando)
+		return
 	}
-
-	// Busca info completa do jogador local (que ESTÁ no mapa)
-	s.mutexClientes.RLock()
-	clienteLocalCompleto, ok := s.Clientes[jogadorLocal.ID]
-	s.mutexClientes.RUnlock()
-	if !ok || clienteLocalCompleto == nil {
-		log.Printf("[CRIAR_SALA_SOMBRA_ERRO] Cliente local %s não encontrado no mapa principal.", jogadorLocal.ID)
+	// Espectadores só podem ler os eventos da partida
+	if s.ehEspectadorDaSala(remetente, salaID) {
+		log.Printf("[%s][COMANDO_ERRO] Comando %s rejeitado: %s é espectador da sala %s", timestamp, mensagem.Comando, remetente, salaID)
+		s.notificarErro(remetente, "Espectadores não podem enviar comandos para a partida.")
 		return
 	}
-
-	clienteLocalCompleto.Mutex.Lock()
-	nomeJ1 := clienteLocalCompleto.Nome
-	clienteLocalCompleto.Mutex.Unlock()
-
-	nomeJ2 := oponenteRemoto.Nome // Nome do oponente remoto (Host)
-
-	log.Printf("[CRIAR_SALA_SOMBRA:%s] Criando sala %s (Host: %s) para %s vs %s", s.ServerID, salaID, hostAddr, nomeJ1, nomeJ2)
-
-	novaSala := &tipos.Sala{
-		ID:             salaID,
-		Jogadores:      []*tipos.Cliente{clienteLocalCompleto, oponenteRemoto}, // Usa o local (completo) e o remoto (DTO)
-		Estado:         "AGUARDANDO_COMPRA",
-		CartasNaMesa:   make(map[string]Carta),
-		PontosRodada:   make(map[string]int),
-		PontosPartida:  make(map[string]int),
-		NumeroRodada:   1,
-		Prontos:        make(map[string]bool),
-		ServidorHost:   hostAddr,      // Aponta para o Host
-		ServidorSombra: s.MeuEndereco, // Eu sou a Sombra
+	// Qualquer cliente pode publicar no tópico da sala; só os jogadores dela têm comandos aceitos
+	if !ehJogadorDaSala(sala, remetente) {
+		log.Printf("[%s][COMANDO_ERRO] Comando %s rejeitado: %q não é jogador da sala %s", timestamp, mensagem.Comando, remetente, salaID)
+		s.notificarErro(remetente, "Você não é jogador desta partida.")
+		return
 	}
 
-	s.mutexSalas.Lock()
-	s.Salas[salaID] = novaSala
-	s.mutexSalas.Unlock()
-
-	// Associa a sala ao jogador LOCAL
-	clienteLocalCompleto.Mutex.Lock()
-	clienteLocalCompleto.Sala = novaSala
-	clienteLocalCompleto.Mutex.Unlock()
-
-	// Associa a sala ao jogador REMOTO (DTO)
-	oponenteRemoto.Mutex.Lock()
-	oponenteRemoto.Sala = novaSala
-	oponenteRemoto.Mutex.Unlock()
+	if mensagem.Comando == "ASSINATURA_RESULTADO" {
+		s.tratarAssinaturaResultado(sala, remetente, mensagem)
+		return
+	}
 
-	log.Printf("Sala %s criada como SOMBRA. Host: %s", salaID, hostAddr)
+	// O processamento fica com a goroutine da sala, que executa os comandos na ordem de chegada
+	if err := s.enviarComandoSala(sala, protocolo.Comando{ClienteID: remetente, Tipo: mensagem.Comando, Payload: mensagem.Dados}); err != nil {
+		log.Printf("[%s][COMANDO_ERRO] Comando %s de %s recusado: %v", timestamp, mensagem.Comando, remetente, err)
+		s.notificarErro(remetente, fmt.Sprintf("Comando não aceito: %v", err))
+	}
+}
 
-	// Notifica jogador local (o Sombra notifica seu jogador)
-	msg := protocolo.Mensagem{
-		Comando: "PARTIDA_ENCONTRADA",
-		Dados:   seguranca.MustJSON(protocolo.DadosPartidaEncontrada{SalaID: salaID, OponenteID: oponenteID, OponenteNome: oponenteNome}),
+// tratarAssinaturaResultado recebe a assinatura do resultado enviada por um jogador. Chega depois do
+// fim da partida, com a fila da sala já fechada, e só usa resultadosPendentes, que tem lock próprio.
+func (s *Servidor) tratarAssinaturaResultado(sala *tipos.Sala, remetente string, mensagem protocolo.Mensagem) {
+	var dados protocolo.DadosAssinaturaResultado
+	if err := json.Unmarshal(mensagem.Dados, &dados); err != nil {
+		log.Printf("[RESULTADO_ERRO:%s] Assinatura de resultado inválida: %v", sala.ID, err)
+		return
+	}
+	// A assinatura vale para o remetente identificado, não para o cliente_id escrito no payload
+	dados.ClienteID = remetente
+	mensagem.Dados = seguranca.MustJSON(dados)
+	retrato := sala.Retrato()
+	servidorHost := retrato.ServidorHost
+	servidorSombra := retrato.ServidorSombra
+	if servidorHost == s.MeuEndereco {
+		s.receberAssinaturaResultado(sala.ID, dados.ClienteID, dados.Assinatura)
+	} else if servidorSombra == s.MeuEndereco {
+		// O resultado pendente fica no Host; a assinatura do jogador local segue para ele
+		go s.encaminharComandoParaHost(servidorHost, sala.ID, mensagem)
 	}
-	s.publicarParaCliente(jogadorLocal.ID, msg)
 }
 
-// Em: servidor/main.go
-// SUBSTITUA a função 'criarSala' (Etapa 1) por esta:
+// executarComandoJogador processa, na goroutine da sala, um comando publicado por um jogador em
+// partidas/{salaID}/comandos ou encaminhado por outro servidor (ProcessarComandoRemoto).
+func (s *Servidor) executarComandoJogador(sala *tipos.Sala, comando protocolo.Comando) {
+	mensagem := protocolo.Mensagem{Comando: comando.Tipo, Dados: comando.Payload}
 
-func (s *Servidor) criarSala(j1 *tipos.Cliente, j2 *tipos.Cliente, sombraAddr string) string {
-	salaID := uuid.New().String()
+	// Verifica se este servidor é o Host ou Sombra. Só esta goroutine altera a sala, então lê os
+	// campos diretamente, sem passar pelo retrato.
+	servidorHost := sala.ServidorHost
+	servidorSombra := sala.ServidorSombra
 
-	// --- LÓGICA DE BUSCA CORRIGIDA ---
-	// j1 é o jogador local (oponente) que ESTÁ no mapa.
-	// j2 é o jogador remoto (solicitante) que NÃO ESTÁ no mapa.
+	// Processa comando baseado no tipo
+	log.Printf("[COMANDO_DEBUG:%s] Processando comando: %s", sala.ID, mensagem.Comando)
+	switch mensagem.Comando {
+	case "COMPRAR_PACOTE":
+		var dados map[string]string
+		json.Unmarshal(mensagem.Dados, &dados)
+		clienteID := comando.ClienteID
 
-	s.mutexClientes.RLock()
-	// Busca o struct completo do jogador local
-	cliente1Completo, ok1 := s.Clientes[j1.ID]
-	s.mutexClientes.RUnlock()
+		// O endereço blockchain do jogador vem do login assinado (autenticarCarteira); um
+		// "endereco" no payload não é mais aceito como prova de posse da carteira
 
-	if !ok1 {
-		log.Printf("[CRIAR_SALA_ERRO:%s] Falha ao buscar informações completas do JOGADOR LOCAL %s (%s) no mapa principal.", s.ServerID, j1.Nome, j1.ID)
-		return "" // Aborta
-	}
+		// Sempre processa compra localmente. Quando o pacote chega, concluirCompraPacote marca o
+		// jogador como pronto e, na Sombra, avisa o Host com PLAYER_READY.
+		s.processarCompraPacote(clienteID, sala, dados["compra_blockchain"])
 
-	// Usa o struct DTO (Data Transfer Object) do jogador remoto diretamente.
-	// Este struct foi criado no 'api/handlers.go'
-	cliente2Completo := j2
-	// --- FIM DA LÓGICA DE BUSCA ---
+	case "JOGAR_CARTA":
+		log.Printf("[MQTT_CMD_DEBUG] === JOGAR_CARTA recebido no main.go ===")
+		var dados protocolo.DadosJogarCarta
+		json.Unmarshal(mensagem.Dados, &dados)
+		clienteID := comando.ClienteID
+		cartaID := dados.CartaID
 
-	// Leitura segura dos nomes
-	cliente1Completo.Mutex.Lock()
-	nomeJ1 := cliente1Completo.Nome
-	cliente1Completo.Mutex.Unlock()
+		log.Printf("[MQTT_CMD_DEBUG] clienteID=%s, cartaID=%s", clienteID, cartaID)
 
-	// O cliente remoto (j2) é só um DTO, usamos o nome que veio na requisição
-	nomeJ2 := cliente2Completo.Nome
+		// Se este servidor é o Host, processa diretamente
+		if servidorHost == s.MeuEndereco {
+			log.Printf("[MQTT_CMD_DEBUG] Sou o Host. Criando GameEventRequest...")
+			// CORREÇÃO: Construir o objeto GameEventRequest
+			eventoReq := &tipos.GameEventRequest{
+				MatchID:   sala.ID,
+				EventSeq:  0, // O Host definirá o eventSeq correto
+				EventType: "CARD_PLAYED",
+				PlayerID:  clienteID,
+				Data: map[string]interface{}{
+					"carta_id": cartaID,
+				},
+				AssinaturaJogador: dados.Assinatura,
+			}
+			log.Printf("[MQTT_CMD_DEBUG] Chamando processarEventoComoHost...")
+			s.processarEventoComoHost(sala, eventoReq) // <-- CHAMADA CORRIGIDA
+			log.Printf("[MQTT_CMD_DEBUG] processarEventoComoHost retornou")
 
-	idJ1 := cliente1Completo.ID
-	idJ2 := cliente2Completo.ID
-
-	log.Printf("[CRIAR_SALA:%s] Tentando criar sala %s para %s (%s) vs %s (%s)", s.ServerID, salaID, nomeJ1, idJ1, nomeJ2, idJ2)
-	if nomeJ1 == "" || nomeJ2 == "" {
-		log.Printf("[CRIAR_SALA_ERRO:%s] Nomes vazios detectados! Abortando.", s.ServerID)
-		return ""
-	}
-
-	novaSala := &tipos.Sala{
-		ID:             salaID,
-		Jogadores:      []*tipos.Cliente{cliente1Completo, cliente2Completo}, // Usa o local (completo) e o remoto (DTO)
-		Estado:         "AGUARDANDO_COMPRA",
-		CartasNaMesa:   make(map[string]Carta),
-		PontosRodada:   make(map[string]int),
-		PontosPartida:  make(map[string]int),
-		NumeroRodada:   1,
-		Prontos:        make(map[string]bool),
-		ServidorHost:   s.MeuEndereco,
-		ServidorSombra: sombraAddr, // Salva o endereço da Sombra
-	}
+		} else if servidorSombra == s.MeuEndereco {
+			// Se é a Sombra, encaminha para o Host via API REST
+			s.encaminharJogadaParaHost(sala, clienteID, cartaID, dados.Assinatura, false)
+		}
 
-	s.mutexSalas.Lock()
-	s.Salas[salaID] = novaSala
-	s.mutexSalas.Unlock()
+	case "CHAT":
+		var dadosCliente protocolo.DadosEnviarChat
+		if err := json.Unmarshal(mensagem.Dados, &dadosCliente); err != nil {
+			log.Printf("[CHAT_ERRO] Erro ao decodificar dados do chat: %v", err)
+			return
+		}
 
-	// Associa a sala ao jogador LOCAL
-	cliente1Completo.Mutex.Lock()
-	cliente1Completo.Sala = novaSala
-	cliente1Completo.Mutex.Unlock()
+		s.mutexClientes.RLock()
+		cliente := s.Clientes[comando.ClienteID]
+		s.mutexClientes.RUnlock()
+		if cliente == nil {
+			return
+		}
 
-	// Associa a sala ao jogador REMOTO (que está na struct 'novaSala.Jogadores')
-	// O struct 'cliente2Completo' (j2) foi criado no handler e tem seu próprio mutex.
-	cliente2Completo.Mutex.Lock()
-	cliente2Completo.Sala = novaSala
-	cliente2Completo.Mutex.Unlock()
+		if servidorHost == s.MeuEndereco {
+			// Eu sou o Host, eu faço o broadcast
+			log.Printf("[HOST-CHAT] Recebido chat de %s. Fazendo broadcast.", cliente.Nome)
+			s.retransmitirChat(sala, cliente, dadosCliente.Texto)
+		} else if servidorSombra == s.MeuEndereco {
+			// Eu sou o Shadow, encaminho para o Host
+			log.Printf("[SHADOW-CHAT] Recebido chat de %s. Encaminhando para Host %s.", cliente.Nome, servidorHost)
+			dadosEvento := map[string]interface{}{
+				"texto": dadosCliente.Texto,
+			}
+			go s.encaminharEventoParaHost(sala, comando.ClienteID, "CHAT", dadosEvento)
+		}
 
-	log.Printf("Sala %s criada localmente (HOST): %s vs %s. Sombra: %s", salaID, nomeJ1, nomeJ2, sombraAddr)
+	case "TROCAR_CARTAS", "TROCAR_CARTAS_OFERTA":
+		var req protocolo.TrocarCartasReq
+		if err := json.Unmarshal(mensagem.Dados, &req); err != nil {
+			log.Printf("[TROCA_ERRO] Erro ao decodificar requisição de troca: %v", err)
+			return
+		}
+		// Só quem oferta pode propor a troca; o id_jogador_oferta do payload tem de ser o remetente
+		if req.IDJogadorOferta != comando.ClienteID {
+			log.Printf("[TROCA_ERRO:%s] %s tentou propor troca em nome de %s", sala.ID, comando.ClienteID, req.IDJogadorOferta)
+			s.notificarErro(comando.ClienteID, "Você só pode propor trocas das suas próprias cartas.")
+			return
+		}
+		s.processarTrocaCartas(sala, &req)
 
-	// Notifica jogadores (o sistema MQTT/API fará o roteamento)
-	msg1 := protocolo.Mensagem{
-		Comando: "PARTIDA_ENCONTRADA",
-		Dados:   seguranca.MustJSON(protocolo.DadosPartidaEncontrada{SalaID: salaID, OponenteID: idJ2, OponenteNome: nomeJ2}),
+	case "SINCRONIZAR_CARTAS":
+		var dados struct {
+			Cartas []protocolo.Carta `json:"cartas"`
+		}
+		if err := json.Unmarshal(mensagem.Dados, &dados); err != nil {
+			log.Printf("[SYNC_ERRO] Erro ao decodificar sincronização: %v", err)
+			return
+		}
+		s.sincronizarCartas(sala, comando.ClienteID, dados.Cartas)
 	}
+}
 
-	s.publicarParaCliente(j1.ID, msg1) // Notifica o jogador local
-	log.Printf("[CRIAR_SALA:NOTIFICACAO] Enviando PARTIDA_ENCONTRADA para JOGADOR LOCAL %s (ID: %s)", nomeJ1, idJ1)
+// inventarioLido é o inventário de um jogador lido da blockchain para SINCRONIZAR_CARTAS, devolvido
+// à sala como CMD_INVENTARIO_LIDO.
+type inventarioLido struct {
+	ClienteID string  `json:"cliente_id"`
+	Cartas    []Carta `json:"cartas"`
+	Erro      string  `json:"erro,omitempty"`
+}
 
-	// CORREÇÃO: Se for uma partida local (sem sombra), notifica o j2 também.
-	if sombraAddr == "" {
-		msg2 := protocolo.Mensagem{
-			Comando: "PARTIDA_ENCONTRADA",
-			Dados:   seguranca.MustJSON(protocolo.DadosPartidaEncontrada{SalaID: salaID, OponenteID: idJ1, OponenteNome: nomeJ1}),
-		}
-		s.publicarParaCliente(j2.ID, msg2)
-		log.Printf("[CRIAR_SALA:NOTIFICACAO] Enviando PARTIDA_ENCONTRADA para JOGADOR LOCAL %s (ID: %s)", nomeJ2, idJ2)
+// sincronizarCartas trata SINCRONIZAR_CARTAS na goroutine da sala. A lista enviada pelo cliente nunca
+// é adotada: com a blockchain como autoridade (CADEIA ou estoque na blockchain) o inventário é lido do
+// contrato em outra goroutine e volta como CMD_INVENTARIO_LIDO; com o servidor como autoridade, a lista
+// só é comparada com a do servidor, que é reenviada ao jogador se as duas divergirem.
+func (s *Servidor) sincronizarCartas(sala *tipos.Sala, clienteID string, cartas []Carta) {
+	cliente := s.getClienteLocal(clienteID)
+	if cliente == nil {
+		log.Printf("[SYNC_CARTAS] Cliente %s não está neste servidor. Sincronização ignorada.", clienteID)
+		return
 	}
+	cliente.Mutex.Lock()
+	endereco := cliente.EnderecoBlockchain
+	inventario := append([]Carta(nil), cliente.Inventario...)
+	cliente.Mutex.Unlock()
 
-	// A notificação para j2 (remoto) será feita pelo servidor Sombra quando ele criar sua sala.
-	log.Printf("[CRIAR_SALA:NOTIFICACAO] Enviando PARTIDA_ENCONTRADA para JOGADOR REMOTO %s (ID: %s)", nomeJ2, idJ2)
+	cadeiaAutoridade := s.BlockchainManager != nil && (s.AutoridadeInventario == AUTORIDADE_CADEIA || s.Store.NaBlockchain())
+	if cadeiaAutoridade && common.IsHexAddress(endereco) {
+		go func() {
+			resultado := inventarioLido{ClienteID: clienteID}
+			cadeia, err := s.BlockchainManager.ObterInventario(common.HexToAddress(endereco))
+			if err != nil {
+				resultado.Erro = err.Error()
+			}
+			resultado.Cartas = cadeia
+			if err := s.enviarComandoSala(sala, protocolo.Comando{
+				ClienteID: clienteID,
+				Tipo:      CMD_INVENTARIO_LIDO,
+				Payload:   seguranca.MustJSON(resultado),
+			}); err != nil {
+				log.Printf("[SYNC_ERRO] Inventário de %s não voltou para a sala %s: %v", clienteID, sala.ID, err)
+			}
+		}()
+		return
+	}
+	if s.Store.NaBlockchain() {
+		log.Printf("[SYNC_ERRO] %s não tem carteira e o estoque está na blockchain. Sincronização ignorada.", clienteID)
+		return
+	}
 
-	// O handler da API (Etapa 5) usará este ID para responder ao Sombra.
-	return salaID
+	soNoCliente, soNoServidor := diferencaCartas(cartas, inventario)
+	if len(soNoCliente) == 0 && len(soNoServidor) == 0 {
+		return
+	}
+	log.Printf("[SYNC_CARTAS] Lista de %s difere do servidor (%d cartas só no cliente, %d só no servidor). Mantido o inventário do servidor.",
+		clienteID, len(soNoCliente), len(soNoServidor))
+	s.publicarParaCliente(clienteID, protocolo.Mensagem{
+		Comando: "INVENTARIO_ATUALIZADO",
+		Dados:   seguranca.MustJSON(protocolo.DadosInventarioAtualizado{Cartas: inventario}),
+	})
 }
 
-// CORREÇÃO: Funções para comunicação cross-server
-func (s *Servidor) CriarSalaCrossServer(matchID string, players []tipos.Player, hostServer string) string {
-	log.Printf("[CRIAR_SALA_CROSS] Criando sala %s como Host", matchID)
-
-	// Cria sala como Host
-	novaSala := &tipos.Sala{
-		ID:             matchID,
-		Jogadores:      make([]*tipos.Cliente, 0, len(players)),
-		Estado:         "AGUARDANDO_COMPRA",
-		CartasNaMesa:   make(map[string]Carta),
-		PontosRodada:   make(map[string]int),
-		PontosPartida:  make(map[string]int),
-		NumeroRodada:   1,
-		Prontos:        make(map[string]bool),
-		ServidorHost:   s.MeuEndereco,
-		ServidorSombra: "", // Será definido quando Shadow se conectar
+// aplicarInventarioLido adota, na goroutine da sala, o inventário lido da blockchain por sincronizarCartas.
+func (s *Servidor) aplicarInventarioLido(sala *tipos.Sala, resultado inventarioLido) {
+	if resultado.Erro != "" {
+		log.Printf("[SYNC_ERRO] Falha ao conferir as cartas de %s na blockchain: %s. Sincronização ignorada.", resultado.ClienteID, resultado.Erro)
+		return
 	}
-
-	// Adiciona jogadores à sala
-	for _, player := range players {
-		cliente := &tipos.Cliente{
-			ID:   player.ID,
-			Nome: player.Nome,
+	cliente := s.getClienteLocal(resultado.ClienteID)
+	if cliente == nil {
+		return
+	}
+	cliente.Mutex.Lock()
+	antigoTamanho := len(cliente.Inventario)
+	cliente.Inventario = append([]Carta(nil), resultado.Cartas...)
+	cliente.Mutex.Unlock()
+	log.Printf("[SYNC_CARTAS] Inventário de %s substituído pelo da blockchain: %d -> %d cartas", cliente.Nome, antigoTamanho, len(resultado.Cartas))
+	s.agendarPersistencia(func() { s.persistirJogador(cliente) })
+
+	// A cópia do jogador na sala acompanha o inventário
+	for _, jog := range sala.Jogadores {
+		if jog.ID == cliente.ID && jog != cliente {
+			jog.Mutex.Lock()
+			jog.Inventario = append([]Carta(nil), resultado.Cartas...)
+			jog.Mutex.Unlock()
 		}
-		novaSala.Jogadores = append(novaSala.Jogadores, cliente)
 	}
+	s.publicarParaCliente(cliente.ID, protocolo.Mensagem{
+		Comando: "INVENTARIO_ATUALIZADO",
+		Dados:   seguranca.MustJSON(protocolo.DadosInventarioAtualizado{Cartas: resultado.Cartas}),
+	})
 
-	s.mutexSalas.Lock()
-	s.Salas[matchID] = novaSala
-	s.mutexSalas.Unlock()
-
-	log.Printf("[CRIAR_SALA_CROSS] Sala %s criada como Host", matchID)
-	return matchID
+	// Se for Sombra, avisa o Host
+	if s.MeuEndereco == sala.ServidorSombra {
+		go s.encaminharEventoParaHost(sala, cliente.ID, "SYNC_INVENTARIO", map[string]interface{}{
+			"cartas": resultado.Cartas,
+		})
+	}
 }
 
-func (s *Servidor) ProcessarEventoComoHost(sala *tipos.Sala, evento *tipos.GameEventRequest) *tipos.EstadoPartida {
-	return s.processarEventoComoHost(sala, evento)
+func (s *Servidor) publicarParaCliente(clienteID string, msg protocolo.Mensagem) {
+	payload, _ := json.Marshal(msg)
+	topico := fmt.Sprintf("clientes/%s/eventos", clienteID)
+	log.Printf("[PUBLICAR_CLIENTE] Enviando para %s no tópico %s: %s", clienteID, topico, string(payload))
+	s.MQTTClient.Publish(topico, 0, false, payload)
 }
 
-func (s *Servidor) ReplicarEstadoComoShadow(matchID string, eventSeq int64, state tipos.EstadoPartida) bool {
-	s.mutexSalas.Lock()
-	sala, ok := s.Salas[matchID]
-	s.mutexSalas.Unlock()
+func (s *Servidor) publicarEventoPartida(salaID string, msg protocolo.Mensagem) {
+	log.Printf("[PUB_EVENTO_DEBUG] === publicarEventoPartida INICIADO ===")
+	log.Printf("[PUB_EVENTO_DEBUG] salaID=%s, Comando=%s", salaID, msg.Comando)
 
-	if !ok {
-		// Cria sala como Shadow se não existir
-		log.Printf("[REPLICAR_ESTADO] Criando sala %s como Shadow", matchID)
-		sala = &tipos.Sala{
-			ID:        matchID,
-			Jogadores: make([]*tipos.Cliente, 0),
+	// Toda atualização pública leva a contagem de espectadores no momento da publicação
+	if msg.Comando == "ATUALIZACAO_JOGO" {
+		var atualizacao protocolo.DadosAtualizacaoJogo
+		if err := json.Unmarshal(msg.Dados, &atualizacao); err == nil {
+			atualizacao.Espectadores = s.contarEspectadores(salaID)
+			msg.Dados = seguranca.MustJSON(atualizacao)
 		}
-
-		s.mutexSalas.Lock()
-		s.Salas[matchID] = sala
-		s.mutexSalas.Unlock()
 	}
 
-	sala.Mutex.Lock()
-	defer sala.Mutex.Unlock()
+	payload, _ := json.Marshal(msg)
+	topico := fmt.Sprintf("partidas/%s/eventos", salaID)
 
-	// Valida eventSeq
-	if eventSeq <= sala.EventSeq {
-		log.Printf("[REPLICAR_ESTADO] EventSeq %d é menor ou igual ao atual %d", eventSeq, sala.EventSeq)
-		return false
-	}
+	log.Printf("[PUB_EVENTO_DEBUG] Tópico: %s", topico)
+	log.Printf("[PUB_EVENTO_DEBUG] Payload (primeiros 300 chars): %s", string(payload)[:min(300, len(string(payload)))])
 
-	// Atualiza estado
-	sala.Estado = state.Estado
-	sala.CartasNaMesa = state.CartasNaMesa
-	sala.PontosRodada = state.PontosRodada
-	sala.PontosPartida = state.PontosPartida
-	sala.NumeroRodada = state.NumeroRodada
-	sala.Prontos = state.Prontos
-	sala.EventSeq = eventSeq
+	token := s.MQTTClient.Publish(topico, 0, false, payload)
+	if token.Wait() && token.Error() != nil {
+		log.Printf("[PUB_EVENTO_DEBUG] ERRO ao publicar: %v", token.Error())
+	} else {
+		log.Printf("[PUB_EVENTO_DEBUG] Mensagem publicada com sucesso no tópico %s", topico)
+	}
+	log.Printf("[PUB_EVENTO_DEBUG] === publicarEventoPartida FINALIZADO ===")
+}
 
-	log.Printf("[REPLICAR_ESTADO] Estado da sala %s sincronizado (eventSeq: %d)", matchID, eventSeq)
-	return true
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
 }
 
-// CORREÇÃO: Funções auxiliares para a API (GetMeuEndereco já existe)
+// ==================== MATCHMAKING E LÓGICA DE JOGO ====================
 
-// encaminharEventoParaHost envia um evento genérico do Shadow para o Host via API REST
-func (s *Servidor) encaminharEventoParaHost(sala *tipos.Sala, clienteID, eventType string, data map[string]interface{}) {
-	sala.Mutex.Lock()
-	host := sala.ServidorHost
-	eventSeq := int64(0)
-	sala.Mutex.Unlock()
+func (s *Servidor) tentarMatchmakingGlobalPeriodicamente() {
+	// Aguarda um pouco no início para a rede de servidores se estabilizar
+	time.Sleep(10 * time.Second)
 
-	log.Printf("[SHADOW] Encaminhando evento %s de %s para o Host %s (eventSeq será definido pelo Host)", eventType, clienteID, host)
+	ticker := time.NewTicker(5 * time.Second) // Tenta a cada 5 segundos
+	defer ticker.Stop()
 
-	req := tipos.GameEventRequest{
-		MatchID:   sala.ID,
-		EventSeq:  eventSeq,
-		EventType: eventType,
-		PlayerID:  clienteID,
-		Data:      data,
-	}
+	for range ticker.C {
+		// As janelas de rating crescem com a espera: pares locais que antes não eram compatíveis podem ser agora
+		s.parearFilaLocal()
 
-	// Criar evento e assinar
-	event := &tipos.GameEvent{
-		EventSeq:  req.EventSeq,
-		MatchID:   req.MatchID,
-		EventType: req.EventType,
-		PlayerID:  req.PlayerID,
-	}
-	seguranca.SignEvent(event)
-	req.Signature = event.Signature
+		s.mutexFila.Lock()
+		if len(s.FilaDeEspera) == 0 {
+			s.mutexFila.Unlock()
+			continue // Pula se a fila estiver vazia
+		}
+		// Copia a fila sem remover ninguém ainda
+		fila := make([]*tipos.Cliente, len(s.FilaDeEspera))
+		copy(fila, s.FilaDeEspera)
+		s.mutexFila.Unlock()
 
-	jsonData, _ := json.Marshal(req)
-	url := fmt.Sprintf("http://%s/game/event", host)
+		// Pega a lista de servidores ativos, excluindo a si mesmo
+		servidoresAtivos := s.ClusterManager.GetServidoresAtivos(s.MeuEndereco)
 
-	maxRetries := 3
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		resp, err := s.enviarRequestComToken("POST", url, jsonData)
-		if err != nil {
-			log.Printf("[SHADOW] Erro ao processar evento %s pelo Host (tentativa %d/%d): %v", eventType, attempt, maxRetries, err)
-			if attempt < maxRetries {
-				time.Sleep(time.Duration(attempt) * time.Second)
-				continue
+		for _, jogadorLocal := range fila {
+			log.Printf("[MATCHMAKING-GLOBAL] Buscando oponente para %s (%s)...", jogadorLocal.Nome, jogadorLocal.ID)
+
+			// Itera sobre os outros servidores para encontrar um oponente
+			for _, addr := range servidoresAtivos {
+				if s.realizarSolicitacaoMatchmaking(addr, jogadorLocal) {
+					// Sucesso! A partida foi formada e o jogador já foi removido da fila.
+					break // Para de procurar por este jogador
+				}
 			}
-			return
 		}
-		defer resp.Body.Close()
+	}
+}
 
-		if resp.StatusCode == http.StatusOK {
-			log.Printf("[SHADOW] Evento %s processado pelo Host com sucesso (tentativa %d/%d)", eventType, attempt, maxRetries)
-			return
-		}
+func (s *Servidor) entrarFila(cliente *tipos.Cliente) {
+	rating := s.ratingDe(cliente.Nome)
 
-		log.Printf("[SHADOW] Host retornou status %d ao processar evento %s (tentativa %d/%d)", resp.StatusCode, eventType, attempt, maxRetries)
-		if attempt < maxRetries {
-			log.Printf("[RETRY] Aguardando %ds antes da próxima tentativa...", attempt)
-			time.Sleep(time.Duration(attempt) * time.Second)
+	s.mutexFila.Lock()
+	// Tenta encontrar oponente compatível na fila local primeiro
+	if oponente := s.removerOponenteCompativelLocked(rating, ranking.Janela(0), cliente.VarianteFila, cliente.FormatoFila, cliente.ID); oponente != nil {
+		s.mutexFila.Unlock()
+
+		// Cria sala localmente
+		s.criarSala(oponente, cliente, "")
+		return
+	}
+
+	// Se não encontrou, adiciona à fila local
+	for _, c := range s.FilaDeEspera {
+		if c.ID == cliente.ID {
+			s.mutexFila.Unlock()
+			log.Printf("Cliente %s (%s) já está na fila de espera.", cliente.Nome, cliente.ID)
+			return
 		}
 	}
+	cliente.Mutex.Lock()
+	cliente.EntradaFila = time.Now()
+	cliente.Mutex.Unlock()
+	s.FilaDeEspera = append(s.FilaDeEspera, cliente)
+	s.mutexFila.Unlock()
+
+	log.Printf("Cliente %s (%s) entrou na fila de espera (rating %d).", cliente.Nome, cliente.ID, rating)
+	s.publicarParaCliente(cliente.ID, protocolo.Mensagem{
+		Comando: "AGUARDANDO_OPONENTE",
+		Dados:   seguranca.MustJSON(map[string]string{"mensagem": fmt.Sprintf("Procurando oponente em todos os servidores (rating %d)...", rating)}),
+	})
+
+	// O matchmaking global já é persistente, não precisamos mais do 'go func' aqui
+	// O go s.tentarMatchmakingGlobalPeriodicamente() no 'Run' cuidará disso
 }
 
-func (s *Servidor) processarCompraPacote(clienteID string, sala *tipos.Sala) {
-	// Se não for o líder, faz requisição para o líder
-	souLider := s.ClusterManager.SouLider()
-	lider := s.ClusterManager.GetLider()
+// gerenciarBuscaGlobalPersistente tenta encontrar um oponente global periodicamente.
+func (s *Servidor) gerenciarBuscaGlobalPersistente(cliente *tipos.Cliente) {
+	ticker := time.NewTicker(5 * time.Second) // Tenta a cada 5 segundos
+	defer ticker.Stop()
 
-	log.Printf("[COMPRAR_DEBUG] Processando compra para cliente %s, souLider: %v", clienteID, souLider)
+	// Cria um contexto para poder cancelar esta goroutine se o cliente sair/for matchado
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel() // Garante que o cancelamento seja chamado ao sair
 
-	cartas := make([]Carta, 0) // Inicializa como slice vazio, não nil
+	// Armazena a função de cancelamento para uso posterior (ex: quando o cliente for matchado localmente)
+	// Assumindo que você adicione um campo `cancelBuscaGlobal context.CancelFunc` à struct Cliente
+	// cliente.cancelBuscaGlobal = cancel // (Descomente se adicionar o campo)
 
-	if souLider {
-		cartas = s.Store.FormarPacote(PACOTE_SIZE)
-		log.Printf("[COMPRAR_DEBUG] Líder retirou %d cartas do estoque", len(cartas))
-	} else {
-		// Faz requisição HTTP para o líder
-		dados := map[string]interface{}{
-			"cliente_id": clienteID,
-		}
-		jsonData, _ := json.Marshal(dados)
-		url := fmt.Sprintf("http://%s/estoque/comprar_pacote", lider)
+	for {
+		select {
+		case <-ticker.C:
+			// 1. Verifica se o cliente AINDA está na fila deste servidor
+			s.mutexFila.Lock()
+			aindaNaFila := false
+			for _, c := range s.FilaDeEspera {
+				if c.ID == cliente.ID {
+					aindaNaFila = true
+					break
+				}
+			}
+			s.mutexFila.Unlock()
 
-		// Cria requisição HTTP com autenticação JWT
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-		if err != nil {
-			log.Printf("Erro ao criar requisição para o líder: %v", err)
-			return
-		}
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+seguranca.GenerateJWT(s.ServerID))
+			if !aindaNaFila {
+				log.Printf("[MATCHMAKING-PERSIST] Cliente %s (%s) não está mais na fila local. Parando busca global.", cliente.Nome, cliente.ID)
+				return // Cliente já foi matchado (localmente ou por outra tentativa), sai da rotina
+			}
 
-		client := &http.Client{Timeout: 15 * time.Second}
-		resp, err := client.Do(req)
-		if err != nil {
-			log.Printf("Erro ao requisitar pacote do líder: %v", err)
-			return
-		}
-		defer resp.Body.Close()
+			// 2. Tenta encontrar um oponente global AGORA (sem remover da fila!)
+			log.Printf("[MATCHMAKING-PERSIST] Tentando busca global para %s (%s)", cliente.Nome, cliente.ID)
+			encontrou := s.tentarMatchmakingGlobalAgora(cliente)
 
-		var resultado struct {
-			Pacote []Carta `json:"pacote"`
-		}
-		if err := json.NewDecoder(resp.Body).Decode(&resultado); err != nil {
-			log.Printf("Erro ao decodificar resposta do líder: %v", err)
-			return
-		}
+			if encontrou {
+				log.Printf("[MATCHMAKING-PERSIST] Busca global para %s (%s) encontrou partida! A busca será finalizada.", cliente.Nome, cliente.ID)
+				// A remoção da fila agora é feita dentro de `realizarSolicitacaoMatchmaking` no momento da confirmação.
+				// Apenas precisamos parar a busca.
+				return // Encontrou, sai da rotina
+			}
+			// Se não encontrou, o loop continua na próxima iteração do ticker.
 
-		cartas = resultado.Pacote
+		case <-ctx.Done():
+			log.Printf("[MATCHMAKING-PERSIST] Busca global para %s (%s) cancelada.", cliente.Nome, cliente.ID)
+			return // Sai da rotina se o contexto for cancelado
+		}
 	}
+}
 
-	// Adiciona cartas ao inventário do cliente
-	s.mutexClientes.RLock()
-	cliente := s.Clientes[clienteID]
-	s.mutexClientes.RUnlock()
-
-	// Se o cliente tem endereço blockchain, busca o inventário da blockchain em vez de usar cartas do estoque
-	if cliente != nil && cliente.EnderecoBlockchain != "" && s.BlockchainManager != nil {
-		log.Printf("[COMPRAR_BLOCKCHAIN] Cliente comprou via blockchain, buscando inventário da blockchain...")
-		addr := common.HexToAddress(cliente.EnderecoBlockchain)
-		cartasBlockchain, err := s.BlockchainManager.ObterInventario(addr)
-		if err == nil && len(cartasBlockchain) > 0 {
-			log.Printf("[COMPRAR_BLOCKCHAIN] Inventário da blockchain obtido: %d cartas", len(cartasBlockchain))
-			cliente.Mutex.Lock()
-			cliente.Inventario = cartasBlockchain
-			cliente.Mutex.Unlock()
-			// Usa as cartas da blockchain para notificar o cliente
-			cartas = cartasBlockchain
-		} else {
-			if err != nil {
-				log.Printf("[COMPRAR_BLOCKCHAIN_AVISO] Erro ao buscar inventário da blockchain: %v", err)
-			} else {
-				log.Printf("[COMPRAR_BLOCKCHAIN_AVISO] Inventário da blockchain vazio, usando cartas do estoque")
-			}
-			// Fallback: usa cartas do estoque se não conseguir buscar da blockchain
-			cliente.Mutex.Lock()
-			cliente.Inventario = append(cliente.Inventario, cartas...)
-			cliente.Mutex.Unlock()
+// tentarMatchmakingGlobalAgora faz UMA tentativa de encontrar um oponente global.
+func (s *Servidor) tentarMatchmakingGlobalAgora(cliente *tipos.Cliente) bool {
+	// Busca oponente em outros servidores ATIVOS
+	servidores := s.ClusterManager.GetServidores()
+	servidoresAtivos := make([]string, 0, len(servidores))
+	for addr, info := range servidores {
+		if addr != s.MeuEndereco && info.Ativo {
+			servidoresAtivos = append(servidoresAtivos, addr)
 		}
-	} else {
-		// Cliente não tem blockchain, usa cartas do estoque normalmente
-		cliente.Mutex.Lock()
-		cliente.Inventario = append(cliente.Inventario, cartas...)
-		cliente.Mutex.Unlock()
 	}
 
-	// Notifica cliente
-	_, total := s.Store.GetStatusEstoque()
-	msg := protocolo.Mensagem{
-		Comando: "PACOTE_RESULTADO",
-		Dados: seguranca.MustJSON(protocolo.ComprarPacoteResp{
-			Cartas:          cartas,
-			EstoqueRestante: total,
-		}),
+	if len(servidoresAtivos) == 0 {
+		// log.Printf("[MATCHMAKING-AGORA] Nenhum outro servidor ativo para buscar oponente para %s.", cliente.Nome)
+		return false // Não há ninguém para perguntar
 	}
-	// Notifica o cliente localmente via MQTT
-	s.publicarParaCliente(clienteID, msg)
 
-	// Se for uma partida cross-server, também notifica o servidor remoto
-	sala.Mutex.Lock()
-	sombraAddr := sala.ServidorSombra
-	hostAddr := sala.ServidorHost
-	sala.Mutex.Unlock()
+	// Embaralha a ordem para não sobrecarregar sempre o mesmo servidor
+	rand.Shuffle(len(servidoresAtivos), func(i, j int) {
+		servidoresAtivos[i], servidoresAtivos[j] = servidoresAtivos[j], servidoresAtivos[i]
+	})
 
-	// Se este servidor é o Host e há uma Sombra, notifica a Sombra
-	if hostAddr == s.MeuEndereco && sombraAddr != "" {
-		go s.notificarJogadorRemoto(sombraAddr, clienteID, msg)
-		// Forçar sincronização de estado após compra
-		go s.forcarSincronizacaoEstado(sala.ID)
+	for _, addr := range servidoresAtivos {
+		if s.realizarSolicitacaoMatchmaking(addr, cliente) {
+			return true // Encontrou partida! A função interna já tratou de tudo.
+		}
 	}
 
-	// CORREÇÃO DEADLOCK: Lógica diferenciada por tipo de partida
-	sala.Mutex.Lock()
-	sala.Prontos[cliente.Nome] = true
+	// Se chegou aqui, não encontrou oponente em NENHUM servidor nesta tentativa.
+	return false
+}
 
-	// Captura informações para decisão
-	isHost := sala.ServidorHost == s.MeuEndereco
-	isShadow := sala.ServidorSombra == s.MeuEndereco
-	sombraAddr = sala.ServidorSombra
-	hostAddr = sala.ServidorHost
-	sala.Mutex.Unlock()
+// realizarSolicitacaoMatchmaking envia uma requisição de oponente para um servidor específico.
+func (s *Servidor) realizarSolicitacaoMatchmaking(addr string, cliente *tipos.Cliente) bool {
+	log.Printf("[MATCHMAKING-TX] Enviando solicitação para %s", addr)
+	cliente.Mutex.Lock()
+	espera := time.Since(cliente.EntradaFila)
+	endereco := cliente.EnderecoBlockchain
+	variante := cliente.VarianteFila
+	formato := cliente.FormatoFila
+	cliente.Mutex.Unlock()
+	reqBody, _ := json.Marshal(map[string]interface{}{
+		"solicitante_id":       cliente.ID,
+		"solicitante_nome":     cliente.Nome,
+		"solicitante_endereco": endereco, // Carteira que assina as jogadas (vazia sem login com carteira)
+		"servidor_origem":      s.MeuEndereco,
+		"rating":               s.ratingDe(cliente.Nome),
+		"janela":               ranking.Janela(espera),
+		"regras":               variante, // Só pareia com quem espera pela mesma variante
+		"formato":              formato,  // e pelo mesmo formato
+	})
 
-	// CORREÇÃO CRÍTICA: Evita race condition/deadlock
-	// Em partidas cross-server, apenas o Shadow notifica o Host
-	if isShadow && hostAddr != "" {
-		// Shadow: Envia PLAYER_READY para o Host após compra
-		log.Printf("[SHADOW] Jogador %s pronto. Notificando Host %s.", cliente.Nome, hostAddr)
-		go s.encaminharEventoParaHost(sala, cliente.ID, "PLAYER_READY", nil)
-	} else if isHost && sombraAddr == "" {
-		// Host de partida LOCAL: Verifica imediatamente
-		log.Printf("[HOST-LOCAL] Jogador %s pronto. Verificando início (partida local).", cliente.Nome)
-		go s.verificarEIniciarPartidaSeProntos(sala)
-	} else if isHost && sombraAddr != "" {
-		// Host de partida CROSS-SERVER: Aguarda PLAYER_READY do Shadow
-		// MAS verifica se ambos já estão prontos (caso Felipe tenha enviado PLAYER_READY primeiro)
-		log.Printf("[HOST-CROSS] Jogador %s pronto. Verificando se ambos prontos (Shadow: %s).", cliente.Nome, sombraAddr)
-		go s.verificarEIniciarPartidaSeProntos(sala)
+	httpClient := &http.Client{Timeout: 15 * time.Second}
+	req, err := http.NewRequest("POST", fmt.Sprintf("http://%s/matchmaking/solicitar_oponente", addr), bytes.NewBuffer(reqBody))
+	if err != nil {
+		log.Printf("[MATCHMAKING-TX] Erro ao criar requisição para %s: %v", addr, err)
+		return false
 	}
-}
 
-func (s *Servidor) iniciarPartida(sala *tipos.Sala) {
-	sala.Mutex.Lock()
-	partidaIniciada := s.iniciarPartidaInterna(sala)
-	sala.Mutex.Unlock()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+seguranca.GenerateJWT(s.ServerID))
 
-	if partidaIniciada {
-		s.notificarInicioPartida(sala)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		log.Printf("[MATCHMAKING-TX] Erro ao contatar servidor %s: %v", addr, err)
+		return false
 	}
-}
+	defer resp.Body.Close()
 
-// iniciarPartidaInterna faz o trabalho de iniciar a partida, assumindo que o lock já está ativo
-// Retorna true se a partida foi realmente iniciada, false se já estava jogando
-func (s *Servidor) iniciarPartidaInterna(sala *tipos.Sala) bool {
-	// CORREÇÃO: Verifica se a partida já foi iniciada para evitar iniciar duas vezes
-	if sala.Estado == "JOGANDO" {
-		log.Printf("[INICIAR_PARTIDA:%s] Partida %s já foi iniciada. Ignorando chamada duplicada.", s.ServerID, sala.ID)
+	var res struct {
+		PartidaEncontrada bool   `json:"partida_encontrada"`
+		SalaID            string `json:"sala_id"`
+		OponenteNome      string `json:"oponente_nome"`
+		OponenteID        string `json:"oponente_id"`
+		OponenteEndereco  string `json:"oponente_endereco"`
+		ServidorHost      string `json:"servidor_host"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		log.Printf("[MATCHMAKING-TX] Erro ao decodificar resposta de %s: %v", addr, err)
 		return false
 	}
 
-	log.Printf("[INICIAR_PARTIDA_DEBUG:%s] Estado atual: %s, mudando para JOGANDO", s.ServerID, sala.Estado)
-	sala.Estado = "JOGANDO"
+	if res.PartidaEncontrada {
+		log.Printf("[MATCHMAKING-TX] Partida encontrada no servidor %s! Oponente: %s", addr, res.OponenteNome)
 
-	// CORREÇÃO: Registrar o TurnoDe ANTES de copiar jogadores, para evitar race condition
-	jogadorInicial := sala.Jogadores[rand.Intn(len(sala.Jogadores))]
-	turnoDeID := jogadorInicial.ID
+		// A requisição foi bem-sucedida, então removemos nosso cliente da fila local.
+		if !s.removerDaFila(cliente.ID) {
+			log.Printf("[MATCHMAKING-TX] Aviso: %s já havia saído da fila local antes da confirmação de %s", cliente.Nome, addr)
+		}
 
-	sala.TurnoDe = turnoDeID
+		// Cria um objeto Cliente para o oponente remoto
 
-	return true
+		s.criarSalaComoSombra(cliente, res.SalaID, res.OponenteID, res.OponenteNome, res.OponenteEndereco, res.ServidorHost)
+		return true // Sucesso!
+	}
+
+	log.Printf("[MATCHMAKING-TX] Servidor %s não encontrou oponente.", addr)
+	return false
 }
 
-// notificarInicioPartida realiza as notificações depois que a partida foi iniciada
-func (s *Servidor) notificarInicioPartida(sala *tipos.Sala) {
-	// Copia dados sob lock
-	sala.Mutex.Lock()
-	jogadorInicialNome := ""
-	sombraAddr := sala.ServidorSombra
-	hostAddr := sala.ServidorHost
-	turnoDeID := sala.TurnoDe
-	jogadoresCopy := make([]*tipos.Cliente, len(sala.Jogadores))
-	copy(jogadoresCopy, sala.Jogadores)
-	sala.Mutex.Unlock()
+// Em: servidor/main.go
+// ADICIONAR esta nova função
+// Em: servidor/main.go
+// SUBSTITUA a função 'criarSalaComoSombra' (Etapa 7) por esta:
 
-	// Coleta contagem de cartas FORA do lock da sala para evitar contenção
-	// CORREÇÃO CRÍTICA: Buscar jogadores do mapa global para obter inventários atualizados
-	contagemCartas := make(map[string]int)
-	for _, j := range jogadoresCopy {
-		// Encontra o nome do jogador inicial para a mensagem
-		if j.ID == turnoDeID {
-			jogadorInicialNome = j.Nome
-		}
-
-		// Busca o jogador no mapa global para obter inventário atualizado
-		s.mutexClientes.RLock()
-		jogadorAtualizado := s.Clientes[j.ID]
-		s.mutexClientes.RUnlock()
-
-		if jogadorAtualizado != nil {
-			// Jogador está neste servidor - usa dados atuais
-			jogadorAtualizado.Mutex.Lock()
-			contagemCartas[j.Nome] = len(jogadorAtualizado.Inventario)
-			jogadorAtualizado.Mutex.Unlock()
-		}
-		// Se jogador não está neste servidor (remoto), não inclui na contagem
-		// O Shadow irá preencher a contagem para seus jogadores locais
+func (s *Servidor) criarSalaComoSombra(jogadorLocal *tipos.Cliente, salaID string, oponenteID string, oponenteNome string, oponenteEndereco string, hostAddr string) {
+	// Cria objeto para oponente remoto (o Host)
+	oponenteRemoto := &tipos.Cliente{
+		ID:                 oponenteID,
+		Nome:               oponenteNome,
+		EnderecoBlockchain: oponenteEndereco,
 	}
 
-	log.Printf("[JOGO_DEBUG] Partida %s iniciada. Jogador inicial: %s (%s)", sala.ID, jogadorInicialNome, turnoDeID)
-	log.Printf("[JOGO_DEBUG] TurnoDe definido como: %s", turnoDeID)
-
-	// Envia um estado inicial completo em vez de apenas uma mensagem de texto
-	msg := protocolo.Mensagem{
-		Comando: "ATUALIZACAO_JOGO",
-		Dados: seguranca.MustJSON(protocolo.DadosAtualizacaoJogo{
-			MensagemDoTurno: fmt.Sprintf("Partida iniciada! É a vez de %s.", jogadorInicialNome),
-			NumeroRodada:    sala.NumeroRodada,
-			ContagemCartas:  contagemCartas,
-			TurnoDe:         turnoDeID,
-		}),
+	// Busca info completa do jogador local (que ESTÁ no mapa)
+	s.mutexClientes.RLock()
+	clienteLocalCompleto, ok := s.Clientes[jogadorLocal.ID]
+	s.mutexClientes.RUnlock()
+	if !ok || clienteLocalCompleto == nil {
+		log.Printf("[CRIAR_SALA_SOMBRA_ERRO] Cliente local %s não encontrado no mapa principal.", jogadorLocal.ID)
+		return
 	}
 
-	// CORREÇÃO CRUCIAL: Em partidas cross-server, publica MQTT local E notifica jogadores remotos via HTTP
-	if hostAddr == s.MeuEndereco && sombraAddr != "" {
-		// Este servidor é o Host, partida é cross-server
-		log.Printf("[INICIAR_PARTIDA_CROSS] Publicando no MQTT local e notificando Shadow via HTTP")
-		// Publica no MQTT local (vai chegar apenas aos clientes conectados a este servidor)
-		s.publicarEventoPartida(sala.ID, msg)
-		// Notifica jogadores remotos (do Shadow) via HTTP
-		go s.notificarJogadoresRemotosDaPartida(sala.ID, sombraAddr, jogadoresCopy, msg)
-	} else {
-		// Partida local - apenas publica no MQTT
-		s.publicarEventoPartida(sala.ID, msg)
-	}
-}
+	clienteLocalCompleto.Mutex.Lock()
+	nomeJ1 := clienteLocalCompleto.Nome
+	clienteLocalCompleto.Mutex.Unlock()
 
-// notificarJogadoresRemotosDaPartida envia evento de partida para jogadores em outro servidor
-func (s *Servidor) notificarJogadoresRemotosDaPartida(salaID, servidorRemoto string, todosJogadores []*tipos.Cliente, msg protocolo.Mensagem) {
-	log.Printf("[NOTIFICAR_REMOTOS] Notificando jogadores remotos na partida %s via servidor %s", salaID, servidorRemoto)
+	nomeJ2 := oponenteRemoto.Nome // Nome do oponente remoto (Host)
 
-	// Envia para cada jogador que não está local
-	for _, jogador := range todosJogadores {
-		if s.getClienteLocal(jogador.ID) == nil {
-			// Este jogador está no servidor remoto
-			log.Printf("[NOTIFICAR_REMOTOS] Enviando evento para jogador remoto %s (%s) via %s", jogador.Nome, jogador.ID, servidorRemoto)
-			s.notificarJogadorRemoto(servidorRemoto, jogador.ID, msg)
-		}
+	log.Printf("[CRIAR_SALA_SOMBRA:%s] Criando sala %s (Host: %s) para %s vs %s", s.ServerID, salaID, hostAddr, nomeJ1, nomeJ2)
+
+	novaSala := &tipos.Sala{
+		ID:             salaID,
+		Jogadores:      []*tipos.Cliente{clienteLocalCompleto, oponenteRemoto}, // Usa o local (completo) e o remoto (DTO)
+		Estado:         "AGUARDANDO_COMPRA",
+		CartasNaMesa:   make(map[string]Carta),
+		PontosRodada:   make(map[string]int),
+		PontosPartida:  make(map[string]int),
+		NumeroRodada:   1,
+		Prontos:        make(map[string]bool),
+		ServidorHost:   hostAddr,      // Aponta para o Host
+		ServidorSombra: s.MeuEndereco, // Eu sou a Sombra
 	}
-}
 
-// verificarEIniciarPartidaSeProntos verifica se todos compraram e inicia a partida,
-// notificando a Sombra se necessário.
-func (s *Servidor) verificarEIniciarPartidaSeProntos(sala *tipos.Sala) {
-	sala.Mutex.Lock()
-	prontos := len(sala.Prontos)
-	total := len(sala.Jogadores)
-	estadoAtual := sala.Estado
-	salaID := sala.ID
+	s.registrarSala(novaSala)
 
-	// DEBUG: Lista quais jogadores estão prontos
-	var jogadoresProntos []string
-	for nome := range sala.Prontos {
-		jogadoresProntos = append(jogadoresProntos, nome)
-	}
-	var todosJogadores []string
-	for _, j := range sala.Jogadores {
-		todosJogadores = append(todosJogadores, j.Nome)
-	}
+	// Associa a sala ao jogador LOCAL
+	clienteLocalCompleto.Mutex.Lock()
+	clienteLocalCompleto.Sala = novaSala
+	clienteLocalCompleto.Mutex.Unlock()
 
-	log.Printf("[VERIFICAR_INICIO:%s] Sala %s - Prontos: %d/%d (%v). Estado: %s. Todos jogadores: %v",
-		s.ServerID, salaID, prontos, total, jogadoresProntos, estadoAtual, todosJogadores)
-	sala.Mutex.Unlock()
+	// Associa a sala ao jogador REMOTO (DTO)
+	oponenteRemoto.Mutex.Lock()
+	oponenteRemoto.Sala = novaSala
+	oponenteRemoto.Mutex.Unlock()
 
-	// CORREÇÃO CRÍTICA: Verificar e iniciar com o LOCK ATIVO para evitar race condition
-	// Só inicia se estiver aguardando e todos estiverem prontos
-	sala.Mutex.Lock()
-	partidaIniciada := false
-	if estadoAtual == "AGUARDANDO_COMPRA" && prontos == total && total == 2 {
-		log.Printf("[INICIAR_PARTIDA:%s] Todos os jogadores da sala %s estão prontos. Iniciando.", s.ServerID, salaID)
-		// iniciarPartidaInterna espera que o lock já esteja ativo
-		partidaIniciada = s.iniciarPartidaInterna(sala)
-	} else {
-		log.Printf("[VERIFICAR_INICIO_DEBUG:%s] Não iniciando partida. Estado: %s, Prontos: %d, Total: %d",
-			s.ServerID, estadoAtual, prontos, total)
-	}
-	sala.Mutex.Unlock()
+	log.Printf("Sala %s criada como SOMBRA. Host: %s", salaID, hostAddr)
 
-	// Se a partida foi iniciada, faz as notificações
-	if partidaIniciada {
-		s.notificarInicioPartida(sala)
+	// Notifica jogador local (o Sombra notifica seu jogador)
+	msg := protocolo.Mensagem{
+		Comando: "PARTIDA_ENCONTRADA",
+		Dados:   seguranca.MustJSON(protocolo.DadosPartidaEncontrada{SalaID: salaID, OponenteID: oponenteID, OponenteNome: oponenteNome}),
 	}
+	s.publicarParaCliente(jogadorLocal.ID, msg)
 }
 
-// encaminharJogadaParaHost encaminha uma jogada da Sombra para o Host via API REST
-func (s *Servidor) encaminharJogadaParaHost(sala *tipos.Sala, clienteID, cartaID string) {
-	sala.Mutex.Lock()
-	host := sala.ServidorHost
-	// CORREÇÃO: Não incrementar o eventSeq aqui. O Host é a autoridade sobre o eventSeq.
-	eventSeq := int64(0) // Será definido pelo Host
-	sala.Mutex.Unlock()
+// Em: servidor/main.go
+// SUBSTITUA a função 'criarSala' (Etapa 1) por esta:
 
-	log.Printf("[SHADOW] Encaminhando jogada de %s para o Host %s (eventSeq será definido pelo Host)", clienteID, host)
+func (s *Servidor) criarSala(j1 *tipos.Cliente, j2 *tipos.Cliente, sombraAddr string) string {
+	salaID := uuid.New().String()
+
+	// --- LÓGICA DE BUSCA CORRIGIDA ---
+	// j1 é o jogador local (oponente) que ESTÁ no mapa.
+	// j2 é o jogador remoto (solicitante) que NÃO ESTÁ no mapa.
 
-	// CORREÇÃO: Buscar e validar a carta no inventário local do Shadow
-	// IMPORTANTE: NÃO remove a carta ainda - apenas obtém os dados
 	s.mutexClientes.RLock()
-	cliente := s.Clientes[clienteID]
+	// Busca o struct completo do jogador local
+	cliente1Completo, ok1 := s.Clientes[j1.ID]
 	s.mutexClientes.RUnlock()
 
-	if cliente == nil {
-		log.Printf("[SHADOW] Cliente %s não encontrado localmente", clienteID)
-		return
+	if !ok1 {
+		log.Printf("[CRIAR_SALA_ERRO:%s] Falha ao buscar informações completas do JOGADOR LOCAL %s (%s) no mapa principal.", s.ServerID, j1.Nome, j1.ID)
+		return "" // Aborta
 	}
 
-	cliente.Mutex.Lock()
-	var carta Carta
-	cartaIndex := -1
-	for i, c := range cliente.Inventario {
-		if c.ID == cartaID {
-			carta = c
-			cartaIndex = i
-			break
-		}
-	}
+	// Usa o struct DTO (Data Transfer Object) do jogador remoto diretamente.
+	// Este struct foi criado no 'api/handlers.go'
+	cliente2Completo := j2
+	// --- FIM DA LÓGICA DE BUSCA ---
 
-	if cartaIndex == -1 {
-		cliente.Mutex.Unlock()
-		log.Printf("[SHADOW] Carta %s não encontrada no inventário de %s", cartaID, clienteID)
-		return
-	}
-	// Remove a carta do inventário local ANTES de enviar ao Host
-	cliente.Inventario = append(cliente.Inventario[:cartaIndex], cliente.Inventario[cartaIndex+1:]...)
-	cliente.Mutex.Unlock()
+	// Leitura segura dos nomes
+	cliente1Completo.Mutex.Lock()
+	nomeJ1 := cliente1Completo.Nome
+	cliente1Completo.Mutex.Unlock()
 
-	// Usa o novo endpoint /game/event
-	req := tipos.GameEventRequest{
-		MatchID:   sala.ID,
-		EventSeq:  eventSeq,
-		EventType: "CARD_PLAYED",
-		PlayerID:  clienteID,
-		Data: map[string]interface{}{
-			"carta_id":       cartaID,
-			"carta_nome":     carta.Nome,
-			"carta_naipe":    carta.Naipe,
-			"carta_valor":    carta.Valor,
-			"carta_raridade": carta.Raridade,
-		},
-		Token: seguranca.GenerateJWT(s.ServerID),
-	}
+	// O cliente remoto (j2) é só um DTO, usamos o nome que veio na requisição
+	nomeJ2 := cliente2Completo.Nome
 
-	// Gera assinatura
-	event := tipos.GameEvent{
-		EventSeq:  req.EventSeq,
-		MatchID:   req.MatchID,
-		EventType: req.EventType,
-		PlayerID:  req.PlayerID,
-	}
-	seguranca.SignEvent(&event)
-	req.Signature = event.Signature
+	idJ1 := cliente1Completo.ID
+	idJ2 := cliente2Completo.ID
 
-	jsonData, _ := json.Marshal(req)
-	url := fmt.Sprintf("http://%s/game/event", host)
+	log.Printf("[CRIAR_SALA:%s] Tentando criar sala %s para %s (%s) vs %s (%s)", s.ServerID, salaID, nomeJ1, idJ1, nomeJ2, idJ2)
+	if nomeJ1 == "" || nomeJ2 == "" {
+		log.Printf("[CRIAR_SALA_ERRO:%s] Nomes vazios detectados! Abortando.", s.ServerID)
+		return ""
+	}
 
-	// Adiciona timeout para detectar falha do Host
-	httpClient := &http.Client{
-		Timeout: 15 * time.Second,
+	novaSala := &tipos.Sala{
+		ID:             salaID,
+		Jogadores:      []*tipos.Cliente{cliente1Completo, cliente2Completo}, // Usa o local (completo) e o remoto (DTO)
+		Estado:         "AGUARDANDO_COMPRA",
+		CartasNaMesa:   make(map[string]Carta),
+		PontosRodada:   make(map[string]int),
+		PontosPartida:  make(map[string]int),
+		NumeroRodada:   1,
+		Prontos:        make(map[string]bool),
+		ServidorHost:   s.MeuEndereco,
+		ServidorSombra: sombraAddr, // Salva o endereço da Sombra
+		Regras:         s.escolherRegras(j1.VarianteFila).ID(),
+		Formato:        s.escolherFormato(j1.FormatoFila),
 	}
 
-	// Adiciona header de autenticação
-	httpReq, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+req.Token)
+	s.registrarSala(novaSala)
 
-	resp, err := httpClient.Do(httpReq)
-	if err != nil {
-		log.Printf("[FAILOVER] Host %s inacessível: %v. Iniciando promoção da Sombra...", host, err)
-		s.promoverSombraAHost(sala)
+	// Associa a sala ao jogador LOCAL
+	cliente1Completo.Mutex.Lock()
+	cliente1Completo.Sala = novaSala
+	cliente1Completo.Mutex.Unlock()
 
-		// CORREÇÃO: Processa o evento 'req' que já foi criado nesta função (na linha 1182)
-		s.processarEventoComoHost(sala, &req)
+	// Associa a sala ao jogador REMOTO (que está na struct 'novaSala.Jogadores')
+	// O struct 'cliente2Completo' (j2) foi criado no handler e tem seu próprio mutex.
+	cliente2Completo.Mutex.Lock()
+	cliente2Completo.Sala = novaSala
+	cliente2Completo.Mutex.Unlock()
 
-		return
-	}
-	defer resp.Body.Close()
+	log.Printf("Sala %s criada localmente (HOST): %s vs %s. Sombra: %s", salaID, nomeJ1, nomeJ2, sombraAddr)
 
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("[SHADOW] Host retornou status %d ao processar jogada", resp.StatusCode)
-		return
+	// Notifica jogadores (o sistema MQTT/API fará o roteamento)
+	msg1 := protocolo.Mensagem{
+		Comando: "PARTIDA_ENCONTRADA",
+		Dados:   seguranca.MustJSON(protocolo.DadosPartidaEncontrada{SalaID: salaID, OponenteID: idJ2, OponenteNome: nomeJ2}),
 	}
 
-	log.Printf("[SHADOW] Jogada processada pelo Host com sucesso")
+	s.publicarParaCliente(j1.ID, msg1) // Notifica o jogador local
+	log.Printf("[CRIAR_SALA:NOTIFICACAO] Enviando PARTIDA_ENCONTRADA para JOGADOR LOCAL %s (ID: %s)", nomeJ1, idJ1)
 
-	// CORREÇÃO: Remove a carta do inventário APENAS após confirmação do Host
-	cliente.Mutex.Lock()
-	// Remove a carta que foi jogada
-	cartaIndex = -1
-	for i, c := range cliente.Inventario {
-		if c.ID == cartaID {
-			cartaIndex = i
-			break
-		}
-	}
-	if cartaIndex != -1 {
-		cliente.Inventario = append(cliente.Inventario[:cartaIndex], cliente.Inventario[cartaIndex+1:]...)
-		log.Printf("[SHADOW] Carta %s removida do inventário de %s", cartaID, clienteID)
+	// CORREÇÃO: Se for uma partida local (sem sombra), notifica o j2 também.
+	if sombraAddr == "" {
+		msg2 := protocolo.Mensagem{
+			Comando: "PARTIDA_ENCONTRADA",
+			Dados:   seguranca.MustJSON(protocolo.DadosPartidaEncontrada{SalaID: salaID, OponenteID: idJ1, OponenteNome: nomeJ1}),
+		}
+		s.publicarParaCliente(j2.ID, msg2)
+		log.Printf("[CRIAR_SALA:NOTIFICACAO] Enviando PARTIDA_ENCONTRADA para JOGADOR LOCAL %s (ID: %s)", nomeJ2, idJ2)
 	}
-	cliente.Mutex.Unlock()
+
+	// A notificação para j2 (remoto) será feita pelo servidor Sombra quando ele criar sua sala.
+	log.Printf("[CRIAR_SALA:NOTIFICACAO] Enviando PARTIDA_ENCONTRADA para JOGADOR REMOTO %s (ID: %s)", nomeJ2, idJ2)
+
+	// O handler da API (Etapa 5) usará este ID para responder ao Sombra.
+	return salaID
 }
 
-// promoverSombraAHost promove a Sombra a Host quando o Host original falha
-func (s *Servidor) promoverSombraAHost(sala *tipos.Sala) {
-	sala.Mutex.Lock()
-	defer sala.Mutex.Unlock()
+// CORREÇÃO: Funções para comunicação cross-server
+func (s *Servidor) CriarSalaCrossServer(matchID string, players []tipos.Player, hostServer string) string {
+	log.Printf("[CRIAR_SALA_CROSS] Criando sala %s como Host", matchID)
 
-	if sala.ServidorHost == s.MeuEndereco {
-		return // Já sou o host, não fazer nada
+	// Cria sala como Host
+	novaSala := &tipos.Sala{
+		ID:             matchID,
+		Jogadores:      make([]*tipos.Cliente, 0, len(players)),
+		Estado:         "AGUARDANDO_COMPRA",
+		CartasNaMesa:   make(map[string]Carta),
+		PontosRodada:   make(map[string]int),
+		PontosPartida:  make(map[string]int),
+		NumeroRodada:   1,
+		Prontos:        make(map[string]bool),
+		ServidorHost:   s.MeuEndereco,
+		ServidorSombra: "", // Será definido quando Shadow se conectar
+		Regras:         s.escolherRegras("").ID(),
+		Formato:        s.escolherFormato(""),
 	}
 
-	antigoHost := sala.ServidorHost
-	sala.ServidorHost = s.MeuEndereco
-	sala.ServidorSombra = "" // Eu sou o novo Host
+	// Adiciona jogadores à sala
+	for _, player := range players {
+		cliente := &tipos.Cliente{
+			ID:                 player.ID,
+			Nome:               player.Nome,
+			EnderecoBlockchain: player.Endereco,
+		}
+		novaSala.Jogadores = append(novaSala.Jogadores, cliente)
+	}
 
-	log.Printf("[FAILOVER] Sombra promovida a Host para a sala %s. Antigo Host: %s", sala.ID, antigoHost)
+	s.registrarSala(novaSala)
 
-	// Notifica jogadores da promoção
-	msg := protocolo.Mensagem{
-		Comando: "ATUALIZACAO_JOGO",
-		Dados: seguranca.MustJSON(protocolo.DadosAtualizacaoJogo{
-			MensagemDoTurno: "O servidor da partida falhou. A partida continuará em um servidor reserva.",
-		}),
+	log.Printf("[CRIAR_SALA_CROSS] Sala %s criada como Host", matchID)
+	return matchID
+}
+
+// ProcessarEventoComoHost entrega à goroutine da sala um evento recebido pela API (/game/event) e espera o resultado.
+func (s *Servidor) ProcessarEventoComoHost(sala *tipos.Sala, evento *tipos.GameEventRequest) *tipos.EstadoPartida {
+	resultado, err := s.executarNaSala(sala, protocolo.Comando{ClienteID: evento.PlayerID, Tipo: CMD_EVENTO_HOST, Payload: seguranca.MustJSON(evento)})
+	if err != nil {
+		log.Printf("[EVENTO_HOST:%s] %v", sala.ID, err)
+		return nil
 	}
-	s.publicarEventoPartida(sala.ID, msg)
+	estado, _ := resultado.(*tipos.EstadoPartida)
+	return estado
 }
 
-// (No arquivo servidor/main.go)
+func (s *Servidor) ReplicarEstadoComoShadow(matchID string, eventSeq int64, state tipos.EstadoPartida) bool {
+	s.mutexSalas.Lock()
+	sala, ok := s.Salas[matchID]
+	s.mutexSalas.Unlock()
 
-// SUBSTITUA a função 'processarJogadaComoHost' inteira por esta:
-func (s *Servidor) processarEventoComoHost(sala *tipos.Sala, evento *tipos.GameEventRequest) *tipos.EstadoPartida {
-	log.Printf("[HOST_EVENT_DEBUG] === processarEventoComoHost INICIADO ===")
-	log.Printf("[HOST_EVENT_DEBUG] EventType=%s, PlayerID=%s", evento.EventType, evento.PlayerID)
+	if !ok {
+		// Cria sala como Shadow se não existir
+		log.Printf("[REPLICAR_ESTADO] Criando sala %s como Shadow", matchID)
+		sala = &tipos.Sala{
+			ID:        matchID,
+			Jogadores: make([]*tipos.Cliente, 0),
+		}
+		s.registrarSala(sala)
+	}
 
-	timestamp := time.Now().Format("15:04:05.000")
-	log.Printf("[%s][EVENTO_HOST:%s] === INÍCIO PROCESSAMENTO EVENTO: %s ===", timestamp, sala.ID, evento.EventType)
-	log.Printf("[%s][EVENTO_HOST:%s] Cliente: %s", timestamp, sala.ID, evento.PlayerID)
-	defer log.Printf("[%s][EVENTO_HOST:%s] === FIM PROCESSAMENTO EVENTO: %s ===", timestamp, sala.ID, evento.EventType)
+	resultado, err := s.executarNaSala(sala, protocolo.Comando{
+		Tipo:    CMD_REPLICAR_ESTADO,
+		Payload: seguranca.MustJSON(tipos.GameReplicateRequest{MatchID: matchID, EventSeq: eventSeq, State: state}),
+	})
+	if err != nil {
+		log.Printf("[REPLICAR_ESTADO] %v", err)
+		return false
+	}
+	aplicado, _ := resultado.(bool)
+	return aplicado
+}
 
-	// Variável para armazenar o vencedor da jogada (se houver)
-	var vencedorJogada string
+// replicarEstadoNaSala aplica na Sombra o estado replicado pelo Host, se for mais novo que o atual.
+func (s *Servidor) replicarEstadoNaSala(sala *tipos.Sala, eventSeq int64, state tipos.EstadoPartida) bool {
+	matchID := sala.ID
 
-	// Variáveis para notificação após liberar o lock
-	var precisaNotificarTurno bool
-	var notificacaoTurnoDe string
-	var notificacaoTurnoNome string
-	var notificacaoSalaID string
-	var notificacaoNumeroRodada int
-	var notificacaoCartasNaMesa map[string]Carta
+	// Valida eventSeq
+	if eventSeq <= sala.EventSeq {
+		log.Printf("[REPLICAR_ESTADO] EventSeq %d é menor ou igual ao atual %d", eventSeq, sala.EventSeq)
+		return false
+	}
 
-	log.Printf("[%s][EVENTO_HOST:%s] TENTANDO LOCK DA SALA...", timestamp, sala.ID)
+	// O Host não é confiável para as jogadas: cada jogada nova no log precisa da assinatura do jogador,
+	// ou de um turno vencido no relógio desta Sombra
+	var novos []tipos.GameEvent
+	if len(state.EventLog) > len(sala.EventLog) {
+		novos = state.EventLog[len(sala.EventLog):]
+		if err := s.verificarJogadasAssinadas(sala, novos); err != nil {
+			log.Printf("[REPLICAR_ESTADO] Estado da sala %s recusado: %v", matchID, err)
+			return false
+		}
+	}
 
-	// Lock com timeout para evitar travamento
-	lockAcquired := make(chan bool, 1)
-	go func() {
-		sala.Mutex.Lock()
-		lockAcquired <- true
-	}()
+	// Atualiza estado
+	s.acompanharTurnoNaSombra(sala, state.TurnoDe, novos, state.PrazoTurno != 0)
+	sala.Estado = state.Estado
+	sala.CartasNaMesa = state.CartasNaMesa
+	sala.PontosRodada = state.PontosRodada
+	sala.PontosPartida = state.PontosPartida
+	sala.NumeroRodada = state.NumeroRodada
+	sala.Prontos = state.Prontos
+	sala.EventSeq = eventSeq
+	if len(state.EventLog) >= len(sala.EventLog) {
+		sala.EventLog = state.EventLog
+	}
+	if state.Regras != "" {
+		sala.Regras = state.Regras
+	}
+	if state.Formato != "" {
+		sala.Formato = state.Formato
+	}
+	sala.JogadasNaRodada = state.JogadasNaRodada
+	if state.TurnoDe != "" {
+		sala.TurnoDe = state.TurnoDe
+	}
 
-	select {
-	case <-lockAcquired:
-		log.Printf("[%s][EVENTO_HOST:%s] LOCK DA SALA OBTIDO", timestamp, sala.ID)
-	case <-time.After(2 * time.Second):
-		log.Printf("[%s][EVENTO_HOST:%s] TIMEOUT NO LOCK DA SALA - IGNORANDO EVENTO", timestamp, sala.ID)
-		// Liberar o lock da goroutine
-		go func() { <-lockAcquired; sala.Mutex.Unlock() }()
-		return nil
+	log.Printf("[REPLICAR_ESTADO] Estado da sala %s sincronizado (eventSeq: %d)", matchID, eventSeq)
+	return true
+}
+
+// verificarJogadasAssinadas confere a assinatura HMAC do Host em cada evento e a da carteira em cada
+// jogada, contra o endereço que o jogador provou no login ou, se a sala não o tem, o vinculado ao
+// nome no log replicado. Uma jogada marcada como automática só dispensa a assinatura se for de
+// TurnoDe e o turno já venceu no relógio desta Sombra (acompanharTurnoNaSombra); o prazo e o
+// Timestamp que o Host registra no evento não contam. Executada na goroutine da sala.
+func (s *Servidor) verificarJogadasAssinadas(sala *tipos.Sala, eventos []tipos.GameEvent) error {
+	enderecos := make(map[string]string, len(sala.Jogadores))
+	for _, jogador := range sala.Jogadores {
+		jogador.Mutex.Lock()
+		endereco, nome := jogador.EnderecoBlockchain, jogador.Nome
+		jogador.Mutex.Unlock()
+		if endereco == "" {
+			endereco = s.carteiraVinculada(nome)
+		}
+		enderecos[jogador.ID] = endereco
 	}
 
-	defer func() {
-		log.Printf("[%s][EVENTO_HOST:%s] LIBERANDO LOCK DA SALA...", timestamp, sala.ID)
-		sala.Mutex.Unlock()
-		log.Printf("[%s][EVENTO_HOST:%s] LOCK DA SALA LIBERADO", timestamp, sala.ID)
+	turnoDe, prazo := sala.TurnoDe, sala.PrazoTurno
+	agora := time.Now()
+	for i := range eventos {
+		evento := &eventos[i]
+		if !seguranca.VerifyEventSignature(evento) {
+			return fmt.Errorf("evento #%d: assinatura do Host inválida", evento.EventSeq)
+		}
+		if seguranca.EhJogada(evento) {
+			vencido := s.TempoTurno > 0 && !prazo.IsZero() && !agora.Before(prazo.Add(-TOLERANCIA_RELOGIO_SOMBRA))
+			automatica := seguranca.MarcadaAutomatica(evento) && evento.PlayerID == turnoDe && vencido
+			if !automatica {
+				if err := seguranca.VerificarAssinaturaJogada(evento, enderecos[evento.PlayerID]); err != nil {
+					return fmt.Errorf("evento #%d: %v", evento.EventSeq, err)
+				}
+			}
+		}
+		// Depois de uma jogada ou do início da partida começa um novo turno, com prazo completo
+		if iniciaTurno(evento) {
+			turnoDe, prazo = "", agora.Add(s.TempoTurno)
+		}
+	}
+	return nil
+}
 
-		// CORREÇÃO CRÍTICA: Notificar mudança de turno APÓS liberar o lock
-		log.Printf("[HOST_EVENT_DEBUG] [DEFER] Verificando se precisa notificar turno...")
-		log.Printf("[HOST_EVENT_DEBUG] [DEFER] precisaNotificarTurno=%v, notificacaoTurnoDe='%s'", precisaNotificarTurno, notificacaoTurnoDe)
+// iniciaTurno indica se o evento começa um novo turno no Host (e reinicia o relógio de turno).
+func iniciaTurno(evento *tipos.GameEvent) bool {
+	return evento.EventType == "MATCH_START" || seguranca.EhJogada(evento)
+}
 
-		if precisaNotificarTurno && notificacaoTurnoDe != "" {
-			log.Printf("[HOST_EVENT_DEBUG] [DEFER] ✅ CONDIÇÃO ATENDIDA! Publicando notificação de mudança de turno.")
-			log.Printf("[HOST_EVENT_DEBUG] [DEFER] TurnoDe='%s', Nome='%s', SalaID='%s'", notificacaoTurnoDe, notificacaoTurnoNome, notificacaoSalaID)
+// acompanharTurnoNaSombra mantém na Sombra o seu próprio relógio de turno: um turno novo (TurnoDe
+// mudou, ou chegou uma jogada ou o início da partida) ganha o prazo completo de TempoTurno a contar
+// de agora. O prazo enviado pelo Host só indica se o relógio dele está ligado; o valor não é usado.
+// É contra este relógio que as jogadas automáticas são aceitas, e é ele que a Sombra continua se
+// for promovida. Executada na goroutine da sala, antes de TurnoDe ser atualizado.
+func (s *Servidor) acompanharTurnoNaSombra(sala *tipos.Sala, turnoDe string, novos []tipos.GameEvent, relogioLigado bool) {
+	if !relogioLigado || s.TempoTurno <= 0 {
+		sala.PrazoTurno = time.Time{}
+		sala.PrazoVencido = time.Time{}
+		return
+	}
+	novoTurno := sala.PrazoTurno.IsZero() || (turnoDe != "" && turnoDe != sala.TurnoDe)
+	for i := range novos {
+		if iniciaTurno(&novos[i]) {
+			novoTurno = true
+		}
+	}
+	if novoTurno {
+		sala.PrazoTurno = time.Now().Add(s.TempoTurno)
+		sala.PrazoVencido = time.Time{}
+	}
+}
 
-			msg := protocolo.Mensagem{
-				Comando: "ATUALIZACAO_JOGO",
-				Dados: seguranca.MustJSON(protocolo.DadosAtualizacaoJogo{
-					MensagemDoTurno: fmt.Sprintf("Aguardando jogada de %s...", notificacaoTurnoNome),
-					NumeroRodada:    notificacaoNumeroRodada,
-					UltimaJogada:    notificacaoCartasNaMesa,
-					TurnoDe:         notificacaoTurnoDe,
-				}),
+// dadosJogadaAutomatica registra numa jogada marcada como automática o prazo vencido do turno, que o
+// replay confere (a Sombra confere a marca com o seu próprio relógio). Se o prazo de TurnoDe não venceu, a marca é retirada e a jogada precisa
+// da assinatura do jogador. Executada na goroutine da sala.
+func dadosJogadaAutomatica(sala *tipos.Sala, data interface{}) interface{} {
+	dados, ok := data.(map[string]interface{})
+	if !ok {
+		return data
+	}
+	_, marcada := dados["automatica"]
+	_, comPrazo := dados["prazo_turno"]
+	if !marcada && !comPrazo {
+		return data
+	}
+	copia := make(map[string]interface{}, len(dados))
+	for k, v := range dados {
+		copia[k] = v
+	}
+	delete(copia, "prazo_turno")
+	if automatica, _ := copia["automatica"].(bool); automatica && !sala.PrazoVencido.IsZero() {
+		copia["prazo_turno"] = sala.PrazoVencido.UnixMilli()
+	} else {
+		delete(copia, "automatica")
+	}
+	return copia
+}
+
+// CORREÇÃO: Funções auxiliares para a API (GetMeuEndereco já existe)
+
+// encaminharEventoParaHost envia um evento genérico do Shadow para o Host via API REST
+func (s *Servidor) encaminharEventoParaHost(sala *tipos.Sala, clienteID, eventType string, data map[string]interface{}) {
+	host := sala.Retrato().ServidorHost
+	eventSeq := int64(0)
+
+	log.Printf("[SHADOW] Encaminhando evento %s de %s para o Host %s (eventSeq será definido pelo Host)", eventType, clienteID, host)
+
+	req := tipos.GameEventRequest{
+		MatchID:   sala.ID,
+		EventSeq:  eventSeq,
+		EventType: eventType,
+		PlayerID:  clienteID,
+		Data:      data,
+	}
+
+	// Criar evento e assinar
+	event := &tipos.GameEvent{
+		EventSeq:  req.EventSeq,
+		MatchID:   req.MatchID,
+		EventType: req.EventType,
+		PlayerID:  req.PlayerID,
+		Data:      req.Data,
+	}
+	seguranca.SignEvent(event)
+	req.Signature = event.Signature
+
+	jsonData, _ := json.Marshal(req)
+	url := fmt.Sprintf("http://%s/game/event", host)
+
+	maxRetries := 3
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		resp, err := s.enviarRequestComToken("POST", url, jsonData)
+		if err != nil {
+			log.Printf("[SHADOW] Erro ao processar evento %s pelo Host (tentativa %d/%d): %v", eventType, attempt, maxRetries, err)
+			if attempt < maxRetries {
+				time.Sleep(time.Duration(attempt) * time.Second)
+				continue
 			}
+			return
+		}
+		defer resp.Body.Close()
 
-			log.Printf("[HOST_EVENT_DEBUG] [DEFER] Mensagem criada. Dados: %s", string(msg.Dados))
-			s.publicarEventoPartida(notificacaoSalaID, msg)
-			log.Printf("[HOST_EVENT_DEBUG] [DEFER] ✅✅✅ Notificação publicada com sucesso! ✅✅✅")
-		} else {
-			log.Printf("[HOST_EVENT_DEBUG] [DEFER] ❌ NÃO publicando notificação. precisaNotificarTurno=%v, notificacaoTurnoDe='%s'", precisaNotificarTurno, notificacaoTurnoDe)
+		if resp.StatusCode == http.StatusOK {
+			log.Printf("[SHADOW] Evento %s processado pelo Host com sucesso (tentativa %d/%d)", eventType, attempt, maxRetries)
+			return
 		}
 
-		// CORREÇÃO CRÍTICA: Verificação de início da partida APÓS liberar o lock
-		// Isso evita deadlock em partidas cross-server
-		if evento.EventType == "PLAYER_READY" {
-			log.Printf("[HOST] PLAYER_READY processado. Verificando se pode iniciar partida (lock já liberado)...")
-			go s.verificarEIniciarPartidaSeProntos(sala)
+		log.Printf("[SHADOW] Host retornou status %d ao processar evento %s (tentativa %d/%d)", resp.StatusCode, eventType, attempt, maxRetries)
+		if attempt < maxRetries {
+			log.Printf("[RETRY] Aguardando %ds antes da próxima tentativa...", attempt)
+			time.Sleep(time.Duration(attempt) * time.Second)
 		}
+	}
+}
 
-		// CORREÇÃO: Notificar resultado da jogada APÓS liberar o lock
-		// Isso evita que a goroutine inicie com o lock ativo e causa timeout
-		// Só notifica se a partida ainda não foi finalizada (se chegou aqui, não finalizou)
-		if vencedorJogada != "" && (evento.EventType == "CARD_PLAYED" || evento.EventType == "JOGAR_CARTA") && sala.Estado != "FINALIZADO" {
-			log.Printf("[HOST] Notificando resultado da jogada após lock liberado. Vencedor: %s", vencedorJogada)
-			go s.notificarResultadoJogada(sala, vencedorJogada)
+// pacoteObtido é o payload de CMD_PACOTE_OBTIDO: o pacote entregue por obterPacote ou o erro
+// a mostrar ao jogador. Sem cartas nem erro, a compra ficou com outro servidor.
+type pacoteObtido struct {
+	ClienteID string  `json:"cliente_id"`
+	Cartas    []Carta `json:"cartas"`
+	Erro      string  `json:"erro,omitempty"`
+	Entregue  bool    `json:"entregue"`
+}
+
+// processarCompraPacote roda na goroutine da sala: registra a compra como pendente e obtém o
+// pacote (log replicado, HTTP com o líder ou blockchain) em outra goroutine, que devolve o
+// resultado como CMD_PACOTE_OBTIDO. compraBlockchain é o hash da transação de revelação do pacote
+// que o cliente já comprou com a própria carteira (vazio se ele não comprou pela carteira).
+func (s *Servidor) processarCompraPacote(clienteID string, sala *tipos.Sala, compraBlockchain string) {
+	if sala.ComprasPendentes[clienteID] {
+		s.notificarErro(clienteID, "Sua compra anterior ainda está em andamento.")
+		return
+	}
+	if sala.ComprasPendentes == nil {
+		sala.ComprasPendentes = make(map[string]bool)
+	}
+	sala.ComprasPendentes[clienteID] = true
+
+	go func() {
+		resultado := s.obterPacote(clienteID, compraBlockchain)
+		if err := s.enviarComandoSala(sala, protocolo.Comando{
+			ClienteID: clienteID,
+			Tipo:      CMD_PACOTE_OBTIDO,
+			Payload:   seguranca.MustJSON(resultado),
+		}); err != nil {
+			log.Printf("[COMPRAR_ERRO] Pacote de %s não voltou para a sala %s: %v", clienteID, sala.ID, err)
 		}
 	}()
+}
 
-	// Validação de Evento (do ARQUITETURA_CROSS_SERVER.md)
-	// O Host é a autoridade sobre o eventSeq.
-	// CORREÇÃO: Aceitar eventos com eventSeq = 0 (enviados pelo Shadow sem eventSeq definido)
-	if evento.EventSeq > 0 && evento.EventSeq <= sala.EventSeq {
-		log.Printf("[EVENTO_HOST:%s] Evento desatualizado ou duplicado recebido. Seq Recebido: %d, Seq Atual: %d", sala.ID, evento.EventSeq, sala.EventSeq)
-		return nil // Ignora o evento (idealmente, o handler da API retornaria 409 Conflict)
+// concluirCompraObtida aplica, na goroutine da sala, o resultado de obterPacote.
+func (s *Servidor) concluirCompraObtida(sala *tipos.Sala, resultado pacoteObtido) {
+	delete(sala.ComprasPendentes, resultado.ClienteID)
+
+	if resultado.Erro != "" {
+		s.notificarErro(resultado.ClienteID, resultado.Erro)
+		return
+	}
+	cliente := s.getClienteLocal(resultado.ClienteID)
+	if !resultado.Entregue || cliente == nil {
+		return
 	}
+	s.concluirCompraPacote(cliente, resultado.Cartas, sala)
+}
 
-	// Validação de turno (APENAS para jogadas de carta)
-	if evento.EventType == "CARD_PLAYED" || evento.EventType == "JOGAR_CARTA" {
-		if sala.Estado != "JOGANDO" {
-			log.Printf("[JOGO_ERRO:%s] Partida não está em andamento.", sala.ID)
-			s.notificarErroPartida(evento.PlayerID, "A partida não está em andamento.", sala.ID)
-			return nil
+// obterPacote entrega um pacote ao jogador local, fora da goroutine da sala, e atualiza o
+// inventário dele. O jogador é marcado como pronto depois, por concluirCompraObtida.
+func (s *Servidor) obterPacote(clienteID, compraBlockchain string) pacoteObtido {
+	resultado := pacoteObtido{ClienteID: clienteID}
+	if s.Store.NaBlockchain() {
+		// Só o servidor em que o jogador está logado verifica a compra feita pela carteira
+		cliente := s.getClienteLocal(clienteID)
+		if cliente == nil {
+			log.Printf("[COMPRAR_BLOCKCHAIN] Compra de %s fica com o servidor em que ele está logado.", clienteID)
+			return resultado
 		}
-		if evento.PlayerID != sala.TurnoDe {
-			log.Printf("[JOGO_AVISO] Jogada fora de turno. Cliente: %s, Turno de: %s", evento.PlayerID, sala.TurnoDe)
-			s.notificarErroPartida(evento.PlayerID, "Não é sua vez de jogar.", sala.ID)
-			return nil
+		cartas, err := s.entregarPacoteBlockchain(cliente, compraBlockchain)
+		if err != nil {
+			log.Printf("[COMPRAR_ERRO] Compra de %s na blockchain falhou: %v", clienteID, err)
+			resultado.Erro = fmt.Sprintf("Não foi possível comprar o pacote na blockchain: %v", err)
+			return resultado
 		}
+		resultado.Cartas = cartas
+		resultado.Entregue = true
+		return resultado
 	}
 
-	// O Host define o eventSeq oficial
-	sala.EventSeq++
-	currentEventSeq := sala.EventSeq
+	// O pacote vai para quem está logado neste servidor; na outra ponta da sala não há o que fazer
+	cliente := s.getClienteLocal(clienteID)
+	if cliente == nil {
+		log.Printf("[COMPRAR_DEBUG] Compra de %s fica com o servidor em que ele está logado.", clienteID)
+		return resultado
+	}
 
-	// Encontra o cliente
-	var jogador *tipos.Cliente
+	// Se não for o líder, faz requisição para o líder
+	souLider := s.ClusterManager.SouLider()
+	lider := s.ClusterManager.GetLider()
+
+	log.Printf("[COMPRAR_DEBUG] Processando compra para cliente %s, souLider: %v", clienteID, souLider)
+
+	cartas := make([]Carta, 0) // Inicializa como slice vazio, não nil
+
+	if souLider {
+		var err error
+		cartas, err = s.FormarPacote(clienteID)
+		if err != nil {
+			log.Printf("[COMPRAR_ERRO] Compra de %s não confirmada pelo cluster: %v", clienteID, err)
+			resultado.Erro = "Não foi possível confirmar a compra no cluster. Tente novamente."
+			return resultado
+		}
+		log.Printf("[COMPRAR_DEBUG] Líder retirou %d cartas do estoque", len(cartas))
+	} else {
+		// Faz requisição HTTP para o líder
+		dados := map[string]interface{}{
+			"cliente_id": clienteID,
+		}
+		jsonData, _ := json.Marshal(dados)
+		url := fmt.Sprintf("http://%s/estoque/comprar_pacote", lider)
+
+		// Cria requisição HTTP com autenticação JWT
+		req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+		if err != nil {
+			log.Printf("Erro ao criar requisição para o líder: %v", err)
+			resultado.Erro = "Não foi possível pedir o pacote ao servidor líder. Tente novamente."
+			return resultado
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+seguranca.GenerateJWT(s.ServerID))
+
+		client := &http.Client{Timeout: 15 * time.Second}
+		resp, err := client.Do(req)
+		if err != nil {
+			log.Printf("Erro ao requisitar pacote do líder: %v", err)
+			resultado.Erro = "O servidor líder não respondeu à compra. Tente novamente."
+			return resultado
+		}
+		defer resp.Body.Close()
+
+		var resposta struct {
+			Pacote []Carta `json:"pacote"`
+			Erro   string  `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&resposta); err != nil {
+			log.Printf("Erro ao decodificar resposta do líder: %v", err)
+			resultado.Erro = "Resposta inválida do servidor líder. Tente novamente."
+			return resultado
+		}
+		// Só um 200 com cartas é uma compra confirmada pelo cluster
+		if resp.StatusCode != http.StatusOK || len(resposta.Pacote) == 0 {
+			log.Printf("[COMPRAR_ERRO] Líder recusou a compra de %s: status %d, erro %q", clienteID, resp.StatusCode, resposta.Erro)
+			resultado.Erro = "Não foi possível confirmar a compra no cluster. Tente novamente."
+			return resultado
+		}
+
+		cartas = resposta.Pacote
+	}
+
+	// Se o cliente tem endereço blockchain, busca o inventário da blockchain em vez de usar cartas do estoque
+	if cliente.EnderecoBlockchain != "" && s.BlockchainManager != nil {
+		log.Printf("[COMPRAR_BLOCKCHAIN] Cliente comprou via blockchain, buscando inventário da blockchain...")
+		addr := common.HexToAddress(cliente.EnderecoBlockchain)
+		cartasBlockchain, err := s.BlockchainManager.ObterInventario(addr)
+		if err == nil && len(cartasBlockchain) > 0 {
+			log.Printf("[COMPRAR_BLOCKCHAIN] Inventário da blockchain obtido: %d cartas", len(cartasBlockchain))
+			cliente.Mutex.Lock()
+			cliente.Inventario = cartasBlockchain
+			cliente.Mutex.Unlock()
+			// Usa as cartas da blockchain para notificar o cliente
+			cartas = cartasBlockchain
+		} else {
+			if err != nil {
+				log.Printf("[COMPRAR_BLOCKCHAIN_AVISO] Erro ao buscar inventário da blockchain: %v", err)
+			} else {
+				log.Printf("[COMPRAR_BLOCKCHAIN_AVISO] Inventário da blockchain vazio, usando cartas do estoque")
+			}
+			// Fallback: usa cartas do estoque se não conseguir buscar da blockchain
+			cliente.Mutex.Lock()
+			cliente.Inventario = append(cliente.Inventario, cartas...)
+			cliente.Mutex.Unlock()
+		}
+	} else {
+		// Cliente não tem blockchain, usa cartas do estoque normalmente
+		cliente.Mutex.Lock()
+		cliente.Inventario = append(cliente.Inventario, cartas...)
+		cliente.Mutex.Unlock()
+	}
+
+	resultado.Cartas = cartas
+	resultado.Entregue = true
+	return resultado
+}
+
+// entregarPacoteBlockchain atende o COMPRAR_PACOTE de um pacote que o cliente já comprou com a própria
+// carteira: o servidor não paga nem cunha. compra é o hash da transação de revelação; o StoreBlockchain
+// confere no contrato que ela revelou um pacote para a carteira do jogador, e o resgate é registrado no
+// log replicado para que a mesma compra não valha outro pacote. O inventário do jogador passa a ser o
+// do contrato (é dele que sai a mão da partida) e o jogador vê as cartas do pacote que ainda possui.
+func (s *Servidor) entregarPacoteBlockchain(cliente *tipos.Cliente, compra string) ([]Carta, error) {
+	cliente.Mutex.Lock()
+	carteira := cliente.EnderecoBlockchain
+	cliente.Mutex.Unlock()
+	if !common.IsHexAddress(carteira) {
+		return nil, fmt.Errorf("conecte uma carteira: neste servidor as cartas existem apenas no contrato")
+	}
+	if compra == "" {
+		return nil, fmt.Errorf("compre o pacote com a sua carteira (/comprar); o servidor não compra pacotes")
+	}
+	if len(common.FromHex(compra)) != common.HashLength {
+		return nil, fmt.Errorf("transação de compra inválida: %q", compra)
+	}
+	loja, ok := s.Store.(*store.StoreBlockchain)
+	if !ok {
+		return nil, fmt.Errorf("o estoque deste servidor não está na blockchain")
+	}
+
+	transacao := common.HexToHash(compra)
+	ids, err := loja.VerificarCompra(common.HexToAddress(carteira), transacao)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resgatarPacote(cliente.ID, transacao.Hex()); err != nil {
+		return nil, err
+	}
+
+	inventario, err := s.BlockchainManager.ObterInventario(common.HexToAddress(carteira))
+	if err != nil {
+		return nil, err
+	}
+	cliente.Mutex.Lock()
+	cliente.Inventario = inventario
+	cliente.Mutex.Unlock()
+
+	noPacote := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		noPacote[id] = true
+	}
+	pacote := make([]Carta, 0, len(ids))
+	for _, carta := range inventario {
+		if noPacote[carta.ID] {
+			pacote = append(pacote, carta)
+		}
+	}
+	log.Printf("[COMPRAR_BLOCKCHAIN] Pacote da transação %s entregue a %s: %d cartas (%d no inventário)", transacao.Hex(), cliente.Nome, len(pacote), len(inventario))
+	return pacote, nil
+}
+
+// concluirCompraPacote persiste o inventário, avisa o jogador (e a Sombra) e o marca como pronto
+func (s *Servidor) concluirCompraPacote(cliente *tipos.Cliente, cartas []Carta, sala *tipos.Sala) {
+	clienteID := cliente.ID
+	s.agendarPersistencia(func() { s.persistirJogador(cliente) })
+
+	// Notifica cliente
+	_, total := s.Store.GetStatusEstoque()
+	msg := protocolo.Mensagem{
+		Comando: "PACOTE_RESULTADO",
+		Dados: seguranca.MustJSON(protocolo.ComprarPacoteResp{
+			Cartas:          cartas,
+			EstoqueRestante: total,
+		}),
+	}
+	// Notifica o cliente localmente via MQTT
+	s.publicarParaCliente(clienteID, msg)
+
+	// Se for uma partida cross-server, também notifica o servidor remoto
+	sombraAddr := sala.ServidorSombra
+	hostAddr := sala.ServidorHost
+
+	// Se este servidor é o Host e há uma Sombra, notifica a Sombra
+	if hostAddr == s.MeuEndereco && sombraAddr != "" {
+		go s.notificarJogadorRemoto(sombraAddr, clienteID, msg)
+		// Forçar sincronização de estado após compra
+		go s.forcarSincronizacaoEstado(sombraAddr, s.criarEstadoDaSala(sala))
+	}
+
+	// CORREÇÃO DEADLOCK: Lógica diferenciada por tipo de partida
+	sala.Prontos[cliente.Nome] = true
+
+	// Captura informações para decisão
+	isHost := hostAddr == s.MeuEndereco
+	isShadow := sombraAddr == s.MeuEndereco
+
+	// CORREÇÃO CRÍTICA: Evita race condition/deadlock
+	// Em partidas cross-server, apenas o Shadow notifica o Host
+	if isShadow && hostAddr != "" {
+		// Shadow: Envia PLAYER_READY para o Host após compra
+		log.Printf("[SHADOW] Jogador %s pronto. Notificando Host %s.", cliente.Nome, hostAddr)
+		go s.encaminharEventoParaHost(sala, cliente.ID, "PLAYER_READY", nil)
+	} else if isHost && sombraAddr == "" {
+		// Host de partida LOCAL: Verifica imediatamente
+		log.Printf("[HOST-LOCAL] Jogador %s pronto. Verificando início (partida local).", cliente.Nome)
+		s.verificarEIniciarPartidaSeProntos(sala)
+	} else if isHost && sombraAddr != "" {
+		// Host de partida CROSS-SERVER: Aguarda PLAYER_READY do Shadow
+		// MAS verifica se ambos já estão prontos (caso Felipe tenha enviado PLAYER_READY primeiro)
+		log.Printf("[HOST-CROSS] Jogador %s pronto. Verificando se ambos prontos (Shadow: %s).", cliente.Nome, sombraAddr)
+		s.verificarEIniciarPartidaSeProntos(sala)
+	}
+}
+
+func (s *Servidor) iniciarPartida(sala *tipos.Sala) {
+	if s.iniciarPartidaInterna(sala) {
+		s.notificarInicioPartida(sala)
+	}
+}
+
+// iniciarPartidaInterna faz o trabalho de iniciar a partida, na goroutine da sala
+// Retorna true se a partida foi realmente iniciada, false se já estava jogando
+func (s *Servidor) iniciarPartidaInterna(sala *tipos.Sala) bool {
+	// CORREÇÃO: Verifica se a partida já foi iniciada para evitar iniciar duas vezes
+	if sala.Estado == "JOGANDO" {
+		log.Printf("[INICIAR_PARTIDA:%s] Partida %s já foi iniciada. Ignorando chamada duplicada.", s.ServerID, sala.ID)
+		return false
+	}
+
+	log.Printf("[INICIAR_PARTIDA_DEBUG:%s] Estado atual: %s, mudando para JOGANDO", s.ServerID, sala.Estado)
+	sala.Estado = "JOGANDO"
+
+	// CORREÇÃO: Registrar o TurnoDe ANTES de copiar jogadores, para evitar race condition
+	jogadorInicial := sala.Jogadores[rand.Intn(len(sala.Jogadores))]
+	turnoDeID := jogadorInicial.ID
+
+	sala.TurnoDe = turnoDeID
+	s.reiniciarRelogioTurno(sala)
+	s.registrarEventoHost(sala, "MATCH_START", turnoDeID, map[string]interface{}{"turno_de": turnoDeID})
+
+	return true
+}
+
+// notificarInicioPartida realiza as notificações depois que a partida foi iniciada
+func (s *Servidor) notificarInicioPartida(sala *tipos.Sala) {
+	// Copia os dados da sala (roda na goroutine da sala, que é quem os altera)
+	jogadorInicialNome := ""
+	sombraAddr := sala.ServidorSombra
+	hostAddr := sala.ServidorHost
+	turnoDeID := sala.TurnoDe
+	tempoRestante := segundosRestantesTurno(sala)
+	descricaoRegras := regras.Obter(sala.Regras).Descricao()
+	descricaoFormato := regras.ObterFormato(sala.Formato).Descricao()
+	jogadoresCopy := make([]*tipos.Cliente, len(sala.Jogadores))
+	copy(jogadoresCopy, sala.Jogadores)
+
+	// Coleta contagem de cartas FORA do lock da sala para evitar contenção
+	// CORREÇÃO CRÍTICA: Buscar jogadores do mapa global para obter inventários atualizados
+	contagemCartas := make(map[string]int)
+	for _, j := range jogadoresCopy {
+		// Encontra o nome do jogador inicial para a mensagem
+		if j.ID == turnoDeID {
+			jogadorInicialNome = j.Nome
+		}
+
+		// Busca o jogador no mapa global para obter inventário atualizado
+		s.mutexClientes.RLock()
+		jogadorAtualizado := s.Clientes[j.ID]
+		s.mutexClientes.RUnlock()
+
+		if jogadorAtualizado != nil {
+			// Jogador está neste servidor - usa dados atuais
+			jogadorAtualizado.Mutex.Lock()
+			contagemCartas[j.Nome] = len(jogadorAtualizado.Inventario)
+			jogadorAtualizado.Mutex.Unlock()
+		}
+		// Se jogador não está neste servidor (remoto), não inclui na contagem
+		// O Shadow irá preencher a contagem para seus jogadores locais
+	}
+
+	log.Printf("[JOGO_DEBUG] Partida %s iniciada. Jogador inicial: %s (%s)", sala.ID, jogadorInicialNome, turnoDeID)
+	log.Printf("[JOGO_DEBUG] TurnoDe definido como: %s", turnoDeID)
+
+	// Envia um estado inicial completo em vez de apenas uma mensagem de texto
+	msg := protocolo.Mensagem{
+		Comando: "ATUALIZACAO_JOGO",
+		Dados: seguranca.MustJSON(protocolo.DadosAtualizacaoJogo{
+			MensagemDoTurno: fmt.Sprintf("Partida iniciada! Regras: %s. Formato: %s. É a vez de %s.", descricaoRegras, descricaoFormato, jogadorInicialNome),
+			NumeroRodada:    sala.NumeroRodada,
+			ContagemCartas:  contagemCartas,
+			TurnoDe:         turnoDeID,
+			TempoRestante:   tempoRestante,
+		}),
+	}
+
+	// CORREÇÃO CRUCIAL: Em partidas cross-server, publica MQTT local E notifica jogadores remotos via HTTP
+	if hostAddr == s.MeuEndereco && sombraAddr != "" {
+		// Este servidor é o Host, partida é cross-server
+		log.Printf("[INICIAR_PARTIDA_CROSS] Publicando no MQTT local e notificando Shadow via HTTP")
+		// Publica no MQTT local (vai chegar apenas aos clientes conectados a este servidor)
+		s.publicarEventoPartida(sala.ID, msg)
+		// Notifica jogadores remotos (do Shadow) via HTTP
+		go s.notificarJogadoresRemotosDaPartida(sala.ID, sombraAddr, jogadoresCopy, msg)
+	} else {
+		// Partida local - apenas publica no MQTT
+		s.publicarEventoPartida(sala.ID, msg)
+	}
+}
+
+// notificarJogadoresRemotosDaPartida envia evento de partida para jogadores em outro servidor
+func (s *Servidor) notificarJogadoresRemotosDaPartida(salaID, servidorRemoto string, todosJogadores []*tipos.Cliente, msg protocolo.Mensagem) {
+	log.Printf("[NOTIFICAR_REMOTOS] Notificando jogadores remotos na partida %s via servidor %s", salaID, servidorRemoto)
+
+	// Envia para cada jogador que não está local
+	for _, jogador := range todosJogadores {
+		if s.getClienteLocal(jogador.ID) == nil {
+			// Este jogador está no servidor remoto
+			log.Printf("[NOTIFICAR_REMOTOS] Enviando evento para jogador remoto %s (%s) via %s", jogador.Nome, jogador.ID, servidorRemoto)
+			s.notificarJogadorRemoto(servidorRemoto, jogador.ID, msg)
+		}
+	}
+}
+
+// verificarEIniciarPartidaSeProntos verifica se todos compraram e inicia a partida,
+// notificando a Sombra se necessário.
+func (s *Servidor) verificarEIniciarPartidaSeProntos(sala *tipos.Sala) {
+	prontos := len(sala.Prontos)
+	total := len(sala.Jogadores)
+	estadoAtual := sala.Estado
+	salaID := sala.ID
+
+	// DEBUG: Lista quais jogadores estão prontos
+	var jogadoresProntos []string
+	for nome := range sala.Prontos {
+		jogadoresProntos = append(jogadoresProntos, nome)
+	}
+	var todosJogadores []string
+	for _, j := range sala.Jogadores {
+		todosJogadores = append(todosJogadores, j.Nome)
+	}
+
+	log.Printf("[VERIFICAR_INICIO:%s] Sala %s - Prontos: %d/%d (%v). Estado: %s. Todos jogadores: %v",
+		s.ServerID, salaID, prontos, total, jogadoresProntos, estadoAtual, todosJogadores)
+
+	// Só inicia se estiver aguardando e todos estiverem prontos
+	partidaIniciada := false
+	if estadoAtual == "AGUARDANDO_COMPRA" && prontos == total && total == 2 {
+		log.Printf("[INICIAR_PARTIDA:%s] Todos os jogadores da sala %s estão prontos. Iniciando.", s.ServerID, salaID)
+		partidaIniciada = s.iniciarPartidaInterna(sala)
+	} else {
+		log.Printf("[VERIFICAR_INICIO_DEBUG:%s] Não iniciando partida. Estado: %s, Prontos: %d, Total: %d",
+			s.ServerID, estadoAtual, prontos, total)
+	}
+
+	// Se a partida foi iniciada, faz as notificações
+	if partidaIniciada {
+		s.notificarInicioPartida(sala)
+	}
+}
+
+// encaminharJogadaParaHost encaminha uma jogada da Sombra para o Host via API REST, com a assinatura
+// da carteira do jogador (vazia em jogadas automáticas do relógio de turno)
+func (s *Servidor) encaminharJogadaParaHost(sala *tipos.Sala, clienteID, cartaID, assinatura string, automatica bool) {
+	host := sala.ServidorHost
+	// CORREÇÃO: Não incrementar o eventSeq aqui. O Host é a autoridade sobre o eventSeq.
+	eventSeq := int64(0) // Será definido pelo Host
+
+	log.Printf("[SHADOW] Encaminhando jogada de %s para o Host %s (eventSeq será definido pelo Host)", clienteID, host)
+
+	// CORREÇÃO: Buscar e validar a carta no inventário local do Shadow
+	// IMPORTANTE: NÃO remove a carta ainda - apenas obtém os dados
+	s.mutexClientes.RLock()
+	cliente := s.Clientes[clienteID]
+	s.mutexClientes.RUnlock()
+
+	if cliente == nil {
+		log.Printf("[SHADOW] Cliente %s não encontrado localmente", clienteID)
+		return
+	}
+
+	cliente.Mutex.Lock()
+	var carta Carta
+	cartaIndex := -1
+	for i, c := range cliente.Inventario {
+		if c.ID == cartaID {
+			carta = c
+			cartaIndex = i
+			break
+		}
+	}
+
+	if cartaIndex == -1 {
+		cliente.Mutex.Unlock()
+		log.Printf("[SHADOW] Carta %s não encontrada no inventário de %s", cartaID, clienteID)
+		return
+	}
+	// Remove a carta do inventário local ANTES de enviar ao Host
+	cliente.Inventario = append(cliente.Inventario[:cartaIndex], cliente.Inventario[cartaIndex+1:]...)
+	cliente.Mutex.Unlock()
+
+	dadosJogada := map[string]interface{}{
+		"carta_id":       cartaID,
+		"carta_nome":     carta.Nome,
+		"carta_naipe":    carta.Naipe,
+		"carta_valor":    carta.Valor,
+		"carta_raridade": carta.Raridade,
+	}
+	if automatica {
+		dadosJogada["automatica"] = true
+	}
+
+	// Usa o novo endpoint /game/event
+	req := tipos.GameEventRequest{
+		MatchID:           sala.ID,
+		EventSeq:          eventSeq,
+		EventType:         "CARD_PLAYED",
+		PlayerID:          clienteID,
+		Data:              dadosJogada,
+		Token:             seguranca.GenerateJWT(s.ServerID),
+		AssinaturaJogador: assinatura,
+	}
+
+	// Gera assinatura
+	event := tipos.GameEvent{
+		EventSeq:  req.EventSeq,
+		MatchID:   req.MatchID,
+		EventType: req.EventType,
+		PlayerID:  req.PlayerID,
+		Data:      req.Data,
+	}
+	seguranca.SignEvent(&event)
+	req.Signature = event.Signature
+
+	// O envio pode esperar até 15s pelo Host: não prende a goroutine da sala
+	go s.enviarJogadaAoHost(sala, host, cliente, cartaID, req)
+}
+
+// enviarJogadaAoHost envia ao Host a jogada preparada por encaminharJogadaParaHost. Se o Host não
+// responder, a jogada volta para a sala como CMD_HOST_INACESSIVEL: a Sombra assume e a processa.
+func (s *Servidor) enviarJogadaAoHost(sala *tipos.Sala, host string, cliente *tipos.Cliente, cartaID string, req tipos.GameEventRequest) {
+	jsonData, _ := json.Marshal(req)
+	url := fmt.Sprintf("http://%s/game/event", host)
+
+	// Adiciona timeout para detectar falha do Host
+	httpClient := &http.Client{
+		Timeout: 15 * time.Second,
+	}
+
+	// Adiciona header de autenticação
+	httpReq, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+req.Token)
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		log.Printf("[FAILOVER] Host %s inacessível: %v. Iniciando promoção da Sombra...", host, err)
+		if err := s.enviarComandoSala(sala, protocolo.Comando{
+			ClienteID: req.PlayerID,
+			Tipo:      CMD_HOST_INACESSIVEL,
+			Payload:   seguranca.MustJSON(req),
+		}); err != nil {
+			log.Printf("[FAILOVER] Jogada de %s não voltou para a sala: %v", req.PlayerID, err)
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("[SHADOW] Host retornou status %d ao processar jogada", resp.StatusCode)
+		return
+	}
+
+	log.Printf("[SHADOW] Jogada processada pelo Host com sucesso")
+
+	// CORREÇÃO: Remove a carta do inventário APENAS após confirmação do Host
+	cliente.Mutex.Lock()
+	// Remove a carta que foi jogada
+	cartaIndex := -1
+	for i, c := range cliente.Inventario {
+		if c.ID == cartaID {
+			cartaIndex = i
+			break
+		}
+	}
+	if cartaIndex != -1 {
+		cliente.Inventario = append(cliente.Inventario[:cartaIndex], cliente.Inventario[cartaIndex+1:]...)
+		log.Printf("[SHADOW] Carta %s removida do inventário de %s", cartaID, req.PlayerID)
+	}
+	cliente.Mutex.Unlock()
+}
+
+// promoverSombraAHost promove a Sombra a Host quando o Host original falha
+func (s *Servidor) promoverSombraAHost(sala *tipos.Sala) {
+	if sala.ServidorHost == s.MeuEndereco {
+		return // Já sou o host, não fazer nada
+	}
+
+	antigoHost := sala.ServidorHost
+	sala.ServidorHost = s.MeuEndereco
+	sala.ServidorSombra = "" // Eu sou o novo Host
+	sala.PublicarRetrato()   // Os avisos deste comando (difundirEventoPartida) já vão pelos novos servidores
+
+	log.Printf("[FAILOVER] Sombra promovida a Host para a sala %s. Antigo Host: %s", sala.ID, antigoHost)
+
+	// Assume o relógio do turno: mantém o prazo que esta Sombra acompanhava, mas garante um
+	// tempo mínimo para quem está jogando (a falha não é culpa do jogador)
+	if sala.Estado == "JOGANDO" && s.TempoTurno > 0 {
+		if minimo := time.Now().Add(TURNO_MINIMO_FAILOVER); sala.PrazoTurno.Before(minimo) {
+			sala.PrazoTurno = minimo
+		}
+		sala.AvisoPrazo = false
+		log.Printf("[FAILOVER] Relógio da sala %s assumido. Turno de %s, prazo em %v", sala.ID, sala.TurnoDe, time.Until(sala.PrazoTurno).Round(time.Second))
+	}
+
+	// Notifica jogadores da promoção
+	msg := protocolo.Mensagem{
+		Comando: "ATUALIZACAO_JOGO",
+		Dados: seguranca.MustJSON(protocolo.DadosAtualizacaoJogo{
+			MensagemDoTurno: "O servidor da partida falhou. A partida continuará em um servidor reserva.",
+			NumeroRodada:    sala.NumeroRodada,
+			SalaID:          sala.ID,
+			TurnoDe:         sala.TurnoDe,
+			TempoRestante:   segundosRestantesTurno(sala),
+		}),
+	}
+	s.publicarEventoPartida(sala.ID, msg)
+}
+
+// (No arquivo servidor/main.go)
+
+// processarEventoComoHost aplica um evento da partida com a autoridade do Host. Só é chamada pela
+// goroutine da sala, então nenhum outro evento da sala é processado ao mesmo tempo; as outras
+// goroutines (API, espectadores, monitores) leem o Retrato publicado depois de cada comando.
+func (s *Servidor) processarEventoComoHost(sala *tipos.Sala, evento *tipos.GameEventRequest) *tipos.EstadoPartida {
+	log.Printf("[HOST_EVENT_DEBUG] === processarEventoComoHost INICIADO ===")
+	log.Printf("[HOST_EVENT_DEBUG] EventType=%s, PlayerID=%s", evento.EventType, evento.PlayerID)
+
+	timestamp := time.Now().Format("15:04:05.000")
+	log.Printf("[%s][EVENTO_HOST:%s] === INÍCIO PROCESSAMENTO EVENTO: %s ===", timestamp, sala.ID, evento.EventType)
+	log.Printf("[%s][EVENTO_HOST:%s] Cliente: %s", timestamp, sala.ID, evento.PlayerID)
+	defer log.Printf("[%s][EVENTO_HOST:%s] === FIM PROCESSAMENTO EVENTO: %s ===", timestamp, sala.ID, evento.EventType)
+
+	// Variável para armazenar o vencedor da jogada (se houver)
+	var vencedorJogada string
+
+	// Variáveis para notificação após liberar o lock
+	var precisaNotificarTurno bool
+	var notificacaoTurnoDe string
+	var notificacaoTurnoNome string
+	var notificacaoSalaID string
+	var notificacaoNumeroRodada int
+	var notificacaoCartasNaMesa map[string]Carta
+	var notificacaoTempoRestante int
+	var fimDeRodada *protocolo.Mensagem // RODADA_FIM a publicar depois do resultado da jogada
+
+	defer func() {
+		// Notifica a mudança de turno depois de aplicar o evento
+		log.Printf("[HOST_EVENT_DEBUG] [DEFER] Verificando se precisa notificar turno...")
+		log.Printf("[HOST_EVENT_DEBUG] [DEFER] precisaNotificarTurno=%v, notificacaoTurnoDe='%s'", precisaNotificarTurno, notificacaoTurnoDe)
+
+		if precisaNotificarTurno && notificacaoTurnoDe != "" {
+			log.Printf("[HOST_EVENT_DEBUG] [DEFER] ✅ CONDIÇÃO ATENDIDA! Publicando notificação de mudança de turno.")
+			log.Printf("[HOST_EVENT_DEBUG] [DEFER] TurnoDe='%s', Nome='%s', SalaID='%s'", notificacaoTurnoDe, notificacaoTurnoNome, notificacaoSalaID)
+
+			msg := protocolo.Mensagem{
+				Comando: "ATUALIZACAO_JOGO",
+				Dados: seguranca.MustJSON(protocolo.DadosAtualizacaoJogo{
+					MensagemDoTurno: fmt.Sprintf("Aguardando jogada de %s...", notificacaoTurnoNome),
+					NumeroRodada:    notificacaoNumeroRodada,
+					UltimaJogada:    notificacaoCartasNaMesa,
+					TurnoDe:         notificacaoTurnoDe,
+					TempoRestante:   notificacaoTempoRestante,
+				}),
+			}
+
+			log.Printf("[HOST_EVENT_DEBUG] [DEFER] Mensagem criada. Dados: %s", string(msg.Dados))
+			s.publicarEventoPartida(notificacaoSalaID, msg)
+			log.Printf("[HOST_EVENT_DEBUG] [DEFER] ✅✅✅ Notificação publicada com sucesso! ✅✅✅")
+		} else {
+			log.Printf("[HOST_EVENT_DEBUG] [DEFER] ❌ NÃO publicando notificação. precisaNotificarTurno=%v, notificacaoTurnoDe='%s'", precisaNotificarTurno, notificacaoTurnoDe)
+		}
+
+		// Verificação de início da partida APÓS liberar o lock (ainda na goroutine da sala)
+		if evento.EventType == "PLAYER_READY" {
+			log.Printf("[HOST] PLAYER_READY processado. Verificando se pode iniciar partida...")
+			s.verificarEIniciarPartidaSeProntos(sala)
+		}
+
+		// Resultado da jogada e fim de rodada são publicados antes do próximo comando da sala,
+		// mantendo a ordem dos eventos. Só notifica se a partida ainda não foi finalizada.
+		if vencedorJogada != "" && (evento.EventType == "CARD_PLAYED" || evento.EventType == "JOGAR_CARTA") && sala.Estado != "FINALIZADO" {
+			log.Printf("[HOST] Notificando resultado da jogada após lock liberado. Vencedor: %s", vencedorJogada)
+			s.notificarResultadoJogada(sala, vencedorJogada)
+			if fimDeRodada != nil {
+				s.difundirEventoPartida(sala, *fimDeRodada)
+			}
+		}
+	}()
+
+	// Validação de Evento (do ARQUITETURA_CROSS_SERVER.md)
+	// O Host é a autoridade sobre o eventSeq.
+	// CORREÇÃO: Aceitar eventos com eventSeq = 0 (enviados pelo Shadow sem eventSeq definido)
+	if evento.EventSeq > 0 && evento.EventSeq <= sala.EventSeq {
+		log.Printf("[EVENTO_HOST:%s] Evento desatualizado ou duplicado recebido. Seq Recebido: %d, Seq Atual: %d", sala.ID, evento.EventSeq, sala.EventSeq)
+		return nil // Ignora o evento (idealmente, o handler da API retornaria 409 Conflict)
+	}
+
+	// Validação de turno (APENAS para jogadas de carta)
+	if evento.EventType == "CARD_PLAYED" || evento.EventType == "JOGAR_CARTA" {
+		if sala.Estado != "JOGANDO" {
+			log.Printf("[JOGO_ERRO:%s] Partida não está em andamento.", sala.ID)
+			s.notificarErroPartida(evento.PlayerID, "A partida não está em andamento.", sala.ID)
+			return nil
+		}
+		if evento.PlayerID != sala.TurnoDe {
+			log.Printf("[JOGO_AVISO] Jogada fora de turno. Cliente: %s, Turno de: %s", evento.PlayerID, sala.TurnoDe)
+			s.notificarErroPartida(evento.PlayerID, "Não é sua vez de jogar.", sala.ID)
+			return nil
+		}
+		evento.Data = dadosJogadaAutomatica(sala, evento.Data)
+		jogada := tipos.GameEvent{MatchID: sala.ID, Timestamp: time.Now(), EventType: evento.EventType, PlayerID: evento.PlayerID, Data: evento.Data, AssinaturaJogador: evento.AssinaturaJogador}
+		if err := s.verificarJogadasAssinadas(sala, []tipos.GameEvent{jogada}); err != nil {
+			log.Printf("[JOGO_AVISO] Jogada recusada na sala %s: %v", sala.ID, err)
+			s.notificarErroPartida(evento.PlayerID, "Jogada recusada: falta a assinatura da sua carteira.", sala.ID)
+			return nil
+		}
+	}
+
+	// W.O. pedido por Host e Sombra (ou repetido pelo monitor) só vale uma vez
+	if evento.EventType == "PLAYER_FORFEIT" && sala.Estado == "FINALIZADO" {
+		return nil
+	}
+
+	// O Host define o eventSeq oficial
+	sala.EventSeq++
+	currentEventSeq := sala.EventSeq
+
+	// Encontra o cliente
+	var jogador *tipos.Cliente
 	var nomeJogador string
 	for _, c := range sala.Jogadores {
 		if c.ID == evento.PlayerID {
@@ -1902,9 +3331,12 @@ func (s *Servidor) processarEventoComoHost(sala *tipos.Sala, evento *tipos.GameE
 		EventType: evento.EventType,
 		PlayerID:  evento.PlayerID,
 		Data:      evento.Data,
+
+		AssinaturaJogador: evento.AssinaturaJogador,
 	}
 	seguranca.SignEvent(&logEvent)
 	sala.EventLog = append(sala.EventLog, logEvent)
+	indiceEventoLog := len(sala.EventLog) - 1
 
 	// --- LÓGICA DE CADA EVENTO ---
 	switch evento.EventType {
@@ -1915,6 +3347,15 @@ func (s *Servidor) processarEventoComoHost(sala *tipos.Sala, evento *tipos.GameE
 		// CORREÇÃO: A verificação NÃO acontece aqui para evitar deadlock.
 		// Será feita após liberar o lock.
 
+	case "PLAYER_FORFEIT":
+		motivo := ""
+		if dadosDoEvento, ok := evento.Data.(map[string]interface{}); ok {
+			motivo, _ = dadosDoEvento["motivo"].(string)
+		}
+		log.Printf("[HOST] Jogador %s (%s) perdeu a partida por W.O. (%s)", nomeJogador, evento.PlayerID, motivo)
+		sala.Abandono = evento.PlayerID
+		s.finalizarPartida(sala)
+
 	case "SYNC_INVENTARIO":
 		log.Printf("[HOST] Recebido sincronização de inventário para %s", nomeJogador)
 		// NOTA: A sincronização já foi feita no servidor local quando recebeu SINCRONIZAR_CARTAS
@@ -2010,7 +3451,7 @@ func (s *Servidor) processarEventoComoHost(sala *tipos.Sala, evento *tipos.GameE
 			log.Printf("[EVENTO_HOST:%s] ✅ Jogador global encontrado: %s (inventário tem %d cartas)", sala.ID, jogadorGlobal.Nome, len(jogadorGlobal.Inventario))
 
 			// Verifica também se o jogador na sala tem o mesmo inventário
-			// NOTA: Não precisamos de lock aqui porque já temos o lock da sala obtido anteriormente
+			// A sala pertence a esta goroutine; só o inventário do jogador tem lock próprio
 			var jogadorNaSala *tipos.Cliente
 			for _, j := range sala.Jogadores {
 				if j.ID == evento.PlayerID {
@@ -2100,9 +3541,19 @@ func (s *Servidor) processarEventoComoHost(sala *tipos.Sala, evento *tipos.GameE
 
 		sala.CartasNaMesa[nomeJogador] = carta
 
+		// Registra no log a carta efetivamente jogada, para que a partida possa ser reproduzida (pacote replay).
+		// A assinatura cobre Data, então o evento é assinado de novo com os dados completos.
+		dadosRegistrados := make(map[string]interface{}, len(dadosDoEvento)+1)
+		for k, v := range dadosDoEvento {
+			dadosRegistrados[k] = v
+		}
+		dadosRegistrados["carta"] = carta
+		sala.EventLog[indiceEventoLog].Data = dadosRegistrados
+		seguranca.SignEvent(&sala.EventLog[indiceEventoLog])
+
 		if len(sala.CartasNaMesa) == len(sala.Jogadores) {
 			log.Printf("[HOST_EVENT_DEBUG] Ambos jogaram. Resolvendo jogada...")
-			vencedorJogada = s.resolverJogada(sala)
+			vencedorJogada, fimDeRodada = s.resolverJogada(sala)
 		} else {
 			log.Printf("[HOST_EVENT_DEBUG] Apenas um jogou. Mudando turno...")
 			for _, j := range sala.Jogadores {
@@ -2116,6 +3567,7 @@ func (s *Servidor) processarEventoComoHost(sala *tipos.Sala, evento *tipos.GameE
 					notificacaoTurnoNome = j.Nome
 					notificacaoSalaID = sala.ID
 					notificacaoNumeroRodada = sala.NumeroRodada
+					notificacaoTempoRestante = segundosRestantesTurno(sala)
 					notificacaoCartasNaMesa = make(map[string]Carta)
 					for k, v := range sala.CartasNaMesa {
 						notificacaoCartasNaMesa[k] = v
@@ -2135,17 +3587,21 @@ func (s *Servidor) processarEventoComoHost(sala *tipos.Sala, evento *tipos.GameE
 
 	// --- REPLICAÇÃO ---
 	estado := &tipos.EstadoPartida{
-		SalaID:         sala.ID,
-		Estado:         sala.Estado,
-		CartasNaMesa:   sala.CartasNaMesa,
-		PontosRodada:   sala.PontosRodada,
-		PontosPartida:  sala.PontosPartida,
-		NumeroRodada:   sala.NumeroRodada,
-		Prontos:        sala.Prontos,
-		EventSeq:       currentEventSeq,
-		EventLog:       sala.EventLog,
-		TurnoDe:        sala.TurnoDe,
-		VencedorJogada: vencedorJogada, // Adiciona o vencedor ao estado retornado
+		SalaID:          sala.ID,
+		Estado:          sala.Estado,
+		CartasNaMesa:    sala.CartasNaMesa,
+		PontosRodada:    sala.PontosRodada,
+		PontosPartida:   sala.PontosPartida,
+		NumeroRodada:    sala.NumeroRodada,
+		Prontos:         sala.Prontos,
+		EventSeq:        currentEventSeq,
+		EventLog:        sala.EventLog,
+		TurnoDe:         sala.TurnoDe,
+		VencedorJogada:  vencedorJogada, // Adiciona o vencedor ao estado retornado
+		Regras:          sala.Regras,
+		Formato:         sala.Formato,
+		JogadasNaRodada: sala.JogadasNaRodada,
+		PrazoTurno:      prazoTurnoUnixMilli(sala),
 	}
 
 	if sala.ServidorSombra != "" && sala.ServidorSombra != s.MeuEndereco {
@@ -2194,18 +3650,15 @@ func (s *Servidor) replicarEstadoParaShadow(shadowAddr string, estado *tipos.Est
 	}
 }
 
-// resolverJogada resolve uma jogada quando ambos os jogadores jogaram
-func (s *Servidor) resolverJogada(sala *tipos.Sala) string {
-	// IMPORTANTE: Esta função assume que o `sala.Mutex` JÁ ESTÁ BLOQUEADO pela função que a chamou (ex: processarJogadaComoHost)
+// resolverJogada resolve uma jogada quando ambos os jogadores jogaram. Se a jogada encerrou a
+// rodada (e não a partida), retorna também a notificação RODADA_FIM, publicada pelo chamador
+// depois do resultado da jogada.
+func (s *Servidor) resolverJogada(sala *tipos.Sala) (string, *protocolo.Mensagem) {
 	log.Printf("[JOGADA_RESOLVER:%s] Resolvendo jogada...", sala.ID)
 
-	// Garante que a operação seja atômica na sala
-	// sala.Mutex.Lock() <--- REMOVIDO PARA EVITAR DEADLOCK
-	// defer sala.Mutex.Unlock() <--- REMOVIDO
-
 	if len(sala.CartasNaMesa) != 2 {
 		log.Printf("[JOGO_ERRO:%s] Tentativa de resolver jogada com %d cartas na mesa.", sala.ID, len(sala.CartasNaMesa))
-		return ""
+		return "", nil
 	}
 
 	j1 := sala.Jogadores[0]
@@ -2214,10 +3667,12 @@ func (s *Servidor) resolverJogada(sala *tipos.Sala) string {
 	c1 := sala.CartasNaMesa[j1.Nome]
 	c2 := sala.CartasNaMesa[j2.Nome]
 
+	regrasSala := regras.Obter(sala.Regras)
+
 	vencedorJogada := "EMPATE"
 	var vencedor *tipos.Cliente
 
-	resultado := compararCartas(c1, c2)
+	resultado := regrasSala.CompararCartas(c1, c2)
 	if resultado > 0 {
 		vencedorJogada = j1.Nome
 		vencedor = j1
@@ -2229,19 +3684,24 @@ func (s *Servidor) resolverJogada(sala *tipos.Sala) string {
 	if vencedor != nil {
 		sala.PontosRodada[vencedor.Nome]++
 	}
+	sala.JogadasNaRodada++
 
 	log.Printf("Resultado da jogada: %s venceu", vencedorJogada)
 
 	// Limpa a mesa
 	sala.CartasNaMesa = make(map[string]Carta)
 
-	// CORREÇÃO: Define o próximo turno baseado no vencedor da jogada
+	// O próximo turno é definido pelas regras da sala (na clássica, o vencedor da jogada começa)
+	vencedorID := ""
 	if vencedor != nil {
-		log.Printf("[TURNO:%s] Vencedor da jogada: %s. Próximo turno: %s", sala.ID, vencedorJogada, vencedor.Nome)
-		s.mudarTurnoAtomicamente(sala, vencedor.ID)
+		vencedorID = vencedor.ID
+	}
+	proximoTurno := regrasSala.ProximoTurno([]string{j1.ID, j2.ID}, sala.TurnoDe, vencedorID)
+	if proximoTurno != sala.TurnoDe {
+		log.Printf("[TURNO:%s] Vencedor da jogada: %s. Próximo turno: %s", sala.ID, vencedorJogada, proximoTurno)
+		s.mudarTurnoAtomicamente(sala, proximoTurno)
 	} else {
-		// Em caso de empate, mantém o mesmo jogador
-		log.Printf("[TURNO:%s] Empate na jogada. Mantendo turno atual: %s", sala.ID, sala.TurnoDe)
+		log.Printf("[TURNO:%s] Jogada resolvida (%s). Mantendo turno atual: %s", sala.ID, vencedorJogada, sala.TurnoDe)
 		// Notificação será feita pela função chamadora após liberar o lock
 	}
 
@@ -2278,97 +3738,181 @@ func (s *Servidor) resolverJogada(sala *tipos.Sala) string {
 
 	log.Printf("[VERIFICACAO_CARTAS:%s] Após jogada: %s tem %d cartas, %s tem %d cartas", sala.ID, j1.Nome, j1Cartas, j2.Nome, j2Cartas)
 
-	// CORREÇÃO: Só finalizar se AMBOS tiverem 0 cartas (ou se for realmente um caso de fim de jogo)
-	// Em partidas cross-server, o inventário do jogador remoto pode estar vazio no Host
-	// então não devemos finalizar baseado apenas em um jogador com 0 cartas
-	if j1Cartas == 0 && j2Cartas == 0 {
-		// Ambos sem cartas - fim definitivo
-		log.Printf("[FINALIZACAO:%s] Ambos os jogadores ficaram sem cartas. Finalizando partida.", sala.ID)
+	// Fim de rodada e de partida são decididos pelas regras e pelo formato da sala. Sem cartas, só
+	// finaliza se AMBOS tiverem 0: em partidas cross-server, o inventário do jogador remoto pode estar vazio no Host
+	cartasRestantes := map[string]int{j1.Nome: j1Cartas, j2.Nome: j2Cartas}
+	placar := s.placarDaSala(sala, cartasRestantes)
+	if !regrasSala.FimDeRodada(placar) {
+		return vencedorJogada, nil
+	}
+	fimDeRodada := s.fecharRodada(sala, regrasSala, placar)
+
+	placar = s.placarDaSala(sala, cartasRestantes)
+	placar.RodadasJogadas = sala.NumeroRodada // Inclui a rodada recém-encerrada
+	if regrasSala.FimDePartida(placar) {
+		log.Printf("[FINALIZACAO:%s] Condição de fim de partida atingida (rodada %d; %s tem %d cartas, %s tem %d). Finalizando partida.", sala.ID, sala.NumeroRodada, j1.Nome, j1Cartas, j2.Nome, j2Cartas)
+		// A última rodada é anunciada antes do FIM_DE_JOGO
+		s.difundirEventoPartida(sala, fimDeRodada)
 		s.finalizarPartida(sala)
-	} else if j1Cartas == 0 || j2Cartas == 0 {
-		// Apenas um sem cartas - isso não deveria acontecer normalmente, mas vamos continuar
+		return vencedorJogada, nil
+	}
+	if j1Cartas == 0 || j2Cartas == 0 {
 		log.Printf("[AVISO:%s] Um jogador tem 0 cartas (cross-server?). Continuando partida por segurança.", sala.ID)
-		sala.NumeroRodada++
 	} else {
-		// Ambos com cartas - continuar
 		log.Printf("[CONTINUA_JOGO:%s] Ambos os jogadores ainda têm cartas (%d e %d). Continuando a partida.", sala.ID, j1Cartas, j2Cartas)
-		sala.NumeroRodada++
 	}
+	sala.NumeroRodada++
+	return vencedorJogada, &fimDeRodada
+}
 
-	return vencedorJogada
+// registrarEventoHost adiciona ao EventLog um evento gerado pelo próprio Host (início e fim de partida).
+// Executada na goroutine da sala.
+func (s *Servidor) registrarEventoHost(sala *tipos.Sala, tipo, playerID string, dados map[string]interface{}) {
+	sala.EventSeq++
+	evento := tipos.GameEvent{
+		EventSeq:  sala.EventSeq,
+		MatchID:   sala.ID,
+		Timestamp: time.Now(),
+		EventType: tipo,
+		PlayerID:  playerID,
+		Data:      dados,
+	}
+	seguranca.SignEvent(&evento)
+	sala.EventLog = append(sala.EventLog, evento)
 }
 
-// compararCartas compara duas cartas e retorna o resultado
-func compararCartas(c1, c2 Carta) int {
-	if c1.Valor != c2.Valor {
-		return c1.Valor - c2.Valor
+// ExportarPartida exporta o EventLog e o estado atual de uma sala no formato lido pelo replay.
+func (s *Servidor) ExportarPartida(salaID string) (replay.Exportacao, bool) {
+	s.mutexSalas.RLock()
+	sala, ok := s.Salas[salaID]
+	s.mutexSalas.RUnlock()
+	if !ok {
+		return replay.Exportacao{}, false
 	}
 
-	// Desempate por naipe
-	naipes := map[string]int{"♠": 4, "♥": 3, "♦": 2, "♣": 1}
-	return naipes[c1.Naipe] - naipes[c2.Naipe]
+	sala = sala.Retrato()
+
+	exportacao := replay.Exportacao{
+		SalaID:      sala.ID,
+		Regras:      sala.Regras,
+		Formato:     sala.Formato,
+		Jogadores:   make([]tipos.Player, 0, len(sala.Jogadores)),
+		EventLog:    append([]tipos.GameEvent(nil), sala.EventLog...),
+		EstadoFinal: *s.criarEstadoDaSala(sala),
+	}
+	for _, jogador := range sala.Jogadores {
+		jogador.Mutex.Lock()
+		exportacao.Jogadores = append(exportacao.Jogadores, tipos.Player{ID: jogador.ID, Nome: jogador.Nome, Endereco: jogador.EnderecoBlockchain})
+		jogador.Mutex.Unlock()
+	}
+	exportacao.EstadoFinal.EventLog = nil // Já vai em EventLog
+	return exportacao, true
 }
 
-// notificarAguardandoOponente notifica que está aguardando o oponente jogar
-// IMPORTANTE: Esta função é chamada com o lock da sala ATIVO, então NÃO pode adquirir o lock
-func (s *Servidor) notificarAguardandoOponente(sala *tipos.Sala) {
-	log.Printf("[NOTIFICACAO_DEBUG] === notificarAguardandoOponente INICIADO ===")
-	log.Printf("[NOTIFICACAO_DEBUG] salaID=%s", sala.ID)
+// ListarAnunciosMercado lê do GameEconomy os anúncios ativos do mercado (rota /mercado)
+func (s *Servidor) ListarAnunciosMercado() ([]tipos.AnuncioMercado, error) {
+	if s.BlockchainManager == nil {
+		return nil, fmt.Errorf("blockchain não configurado neste servidor")
+	}
+	return s.BlockchainManager.ListarAnuncios()
+}
 
-	// Pequeno delay para garantir que o lock foi liberado pela função chamadora
-	time.Sleep(50 * time.Millisecond)
-	log.Printf("[NOTIFICACAO_DEBUG] Delay de 50ms concluído")
+// ObterAnuncioMercado lê um anúncio do GameEconomy; o bool é falso se ele não existe ou foi encerrado
+func (s *Servidor) ObterAnuncioMercado(anuncioID string) (tipos.AnuncioMercado, bool, error) {
+	if s.BlockchainManager == nil {
+		return tipos.AnuncioMercado{}, false, fmt.Errorf("blockchain não configurado neste servidor")
+	}
+	id, ok := new(big.Int).SetString(anuncioID, 10)
+	if !ok {
+		return tipos.AnuncioMercado{}, false, nil
+	}
+	return s.BlockchainManager.ObterAnuncio(id)
+}
 
-	// Adquire lock para ler estado
-	sala.Mutex.Lock()
-	log.Printf("[NOTIFICACAO_DEBUG] Lock adquirido")
-	turnoDe := sala.TurnoDe
-	numeroRodada := sala.NumeroRodada
-	salaID := sala.ID
-	log.Printf("[NOTIFICACAO_DEBUG] Estado lido: TurnoDe='%s', NumeroRodada=%d", turnoDe, numeroRodada)
+// placarDaSala monta o placar usado pelas regras. Executada na goroutine da sala.
+func (s *Servidor) placarDaSala(sala *tipos.Sala, cartasRestantes map[string]int) regras.Placar {
+	return regras.Placar{
+		PontosRodada:    sala.PontosRodada,
+		PontosPartida:   sala.PontosPartida,
+		CartasRestantes: cartasRestantes,
+		JogadasNaRodada: sala.JogadasNaRodada,
+		RodadasJogadas:  sala.NumeroRodada - 1,
+		Formato:         regras.ObterFormato(sala.Formato),
+	}
+}
 
-	// Encontra nome do próximo jogador
-	var proximoJogadorNome string
-	for _, j := range sala.Jogadores {
-		if j.ID == turnoDe {
-			proximoJogadorNome = j.Nome
-			log.Printf("[NOTIFICACAO_DEBUG] Próximo jogador encontrado: %s (%s)", j.Nome, j.ID)
-			break
-		}
+// fecharRodada encerra a rodada atual: soma a rodada ao vencedor em PontosPartida, registra
+// ROUND_END no EventLog e zera o placar da rodada. Retorna a notificação RODADA_FIM.
+// Executada na goroutine da sala.
+func (s *Servidor) fecharRodada(sala *tipos.Sala, regrasSala regras.RuleSet, placar regras.Placar) protocolo.Mensagem {
+	vencedorRodada := regrasSala.VencedorRodada(placar)
+	if vencedorRodada != "EMPATE" {
+		sala.PontosPartida[vencedorRodada]++
 	}
+	log.Printf("[RODADA:%s] Rodada %d encerrada após %d jogadas. Vencedor: %s | Partida: %v", sala.ID, sala.NumeroRodada, sala.JogadasNaRodada, vencedorRodada, sala.PontosPartida)
+	s.registrarEventoHost(sala, "ROUND_END", "", map[string]interface{}{"rodada": sala.NumeroRodada, "vencedor": vencedorRodada})
 
-	// Copia cartas na mesa
-	cartasNaMesa := make(map[string]Carta)
-	for k, v := range sala.CartasNaMesa {
-		cartasNaMesa[k] = v
+	dados := protocolo.DadosFimDeRodada{
+		SalaID:         sala.ID,
+		NumeroRodada:   sala.NumeroRodada,
+		VencedorRodada: vencedorRodada,
+		PontosRodada:   make(map[string]int, len(sala.PontosRodada)),
+		PontosPartida:  make(map[string]int, len(sala.PontosPartida)),
+		Formato:        placar.Formato.Descricao(),
+	}
+	for nome, pontos := range sala.PontosRodada {
+		dados.PontosRodada[nome] = pontos
+	}
+	for nome, pontos := range sala.PontosPartida {
+		dados.PontosPartida[nome] = pontos
 	}
-	log.Printf("[NOTIFICACAO_DEBUG] Cartas na mesa copiadas: %d cartas", len(cartasNaMesa))
-	sala.Mutex.Unlock()
-	log.Printf("[NOTIFICACAO_DEBUG] Lock liberado")
 
-	log.Printf("[NOTIFICACAO_DEBUG] Montando mensagem ATUALIZACAO_JOGO")
-	log.Printf("[NOTIFICACAO_DEBUG]   - TurnoDe='%s'", turnoDe)
-	log.Printf("[NOTIFICACAO_DEBUG]   - NumeroRodada=%d", numeroRodada)
-	log.Printf("[NOTIFICACAO_DEBUG]   - ProximoJogador='%s'", proximoJogadorNome)
+	sala.PontosRodada = make(map[string]int)
+	sala.JogadasNaRodada = 0
+	return protocolo.Mensagem{Comando: "RODADA_FIM", Dados: seguranca.MustJSON(dados)}
+}
 
-	msg := protocolo.Mensagem{
-		Comando: "ATUALIZACAO_JOGO",
-		Dados: seguranca.MustJSON(protocolo.DadosAtualizacaoJogo{
-			MensagemDoTurno: fmt.Sprintf("Aguardando jogada de %s...", proximoJogadorNome),
-			NumeroRodada:    numeroRodada,
-			UltimaJogada:    cartasNaMesa,
-			TurnoDe:         turnoDe,
-		}),
+// escolherRegras cria o RuleSet de uma nova sala a partir da variante pedida pelos jogadores
+// (vazia = a padrão do servidor)
+func (s *Servidor) escolherRegras(variante string) regras.RuleSet {
+	regrasSala, err := regras.Nova(s.normalizarVariante(variante))
+	if err != nil {
+		log.Printf("[REGRAS_AVISO] %v. Usando regras clássicas.", err)
+		return regras.Classico{}
 	}
+	return regrasSala
+}
 
-	log.Printf("[NOTIFICACAO_DEBUG] Mensagem criada. Dados JSON: %s", string(msg.Dados))
-	log.Printf("[NOTIFICACAO_DEBUG] Chamando publicarEventoPartida...")
+// normalizarVariante devolve a variante como comparada na fila: maiúscula e, se vazia, a padrão do servidor
+func (s *Servidor) normalizarVariante(variante string) string {
+	variante = strings.ToUpper(strings.TrimSpace(variante))
+	if variante == "" {
+		return s.VarianteRegras
+	}
+	return variante
+}
 
-	// Publica no MQTT
-	s.publicarEventoPartida(salaID, msg)
+// normalizarFormato devolve o ID do formato como comparado na fila; vazio é o padrão do servidor
+func (s *Servidor) normalizarFormato(formato string) (string, error) {
+	if strings.TrimSpace(formato) == "" {
+		return s.FormatoPartida, nil
+	}
+	f, err := regras.NovoFormato(formato)
+	if err != nil {
+		return "", err
+	}
+	return f.ID(), nil
+}
 
-	log.Printf("[NOTIFICACAO_DEBUG] publicarEventoPartida retornou")
-	log.Printf("[NOTIFICACAO_DEBUG] === notificarAguardandoOponente FINALIZADO ===")
+// escolherFormato retorna o formato de uma nova sala a partir do pedido pelos jogadores
+// (vazio ou inválido = o padrão do servidor)
+func (s *Servidor) escolherFormato(formato string) string {
+	id, err := s.normalizarFormato(formato)
+	if err != nil {
+		log.Printf("[REGRAS_AVISO] %v. Usando o formato padrão.", err)
+		return s.FormatoPartida
+	}
+	return id
 }
 
 // notificarResultadoJogada notifica o resultado de uma jogada
@@ -2381,11 +3925,11 @@ func (s *Servidor) notificarResultadoJogada(sala *tipos.Sala, vencedorJogada str
 	var cartasNaMesa map[string]Carta
 	var salaID string
 
-	// Copia dados sob lock da sala (rápido)
-	sala.Mutex.Lock()
+	// Copia os dados da sala (roda na goroutine da sala, que é quem os altera)
 	turnoDe = sala.TurnoDe
 	numeroRodada = sala.NumeroRodada
 	salaID = sala.ID
+	tempoRestante := segundosRestantesTurno(sala)
 	cartasNaMesa = make(map[string]Carta)
 	for k, v := range sala.CartasNaMesa {
 		cartasNaMesa[k] = v
@@ -2394,7 +3938,6 @@ func (s *Servidor) notificarResultadoJogada(sala *tipos.Sala, vencedorJogada str
 	copy(jogadoresCopy, sala.Jogadores)
 	sombraAddr := sala.ServidorSombra
 	hostAddr := sala.ServidorHost
-	sala.Mutex.Unlock()
 
 	// Encontra o nome do próximo jogador e cria contagem de cartas FORA do lock da sala
 	var proximoJogadorNome string
@@ -2431,6 +3974,7 @@ func (s *Servidor) notificarResultadoJogada(sala *tipos.Sala, vencedorJogada str
 			VencedorJogada:  vencedorJogada,
 			SalaID:          salaID,
 			TurnoDe:         turnoDe,
+			TempoRestante:   tempoRestante,
 		}),
 	}
 
@@ -2450,36 +3994,45 @@ func (s *Servidor) notificarResultadoJogada(sala *tipos.Sala, vencedorJogada str
 
 // finalizarPartida finaliza uma partida e determina o vencedor
 func (s *Servidor) finalizarPartida(sala *tipos.Sala) {
-	// CORREÇÃO: Esta função assume que o lock da sala JÁ ESTÁ ATIVO
-	// Quando chamada de dentro de resolverJogada (que está dentro de processarEventoComoHost com lock ativo)
-
 	sala.Estado = "FINALIZADO"
+	sala.PrazoTurno = time.Time{}
 
-	vencedorFinal := "EMPATE"
-	maxPontos := -1
-
-	for nome, pontos := range sala.PontosRodada {
-		if pontos > maxPontos {
-			maxPontos = pontos
-			vencedorFinal = nome
-		} else if pontos == maxPontos {
-			vencedorFinal = "EMPATE"
+	vencedorFinal := regras.Obter(sala.Regras).VencedorPartida(s.placarDaSala(sala, nil))
+	if sala.Abandono != "" {
+		// W.O.: vence o outro jogador, independente do placar
+		for _, j := range sala.Jogadores {
+			if j.ID != sala.Abandono {
+				vencedorFinal = j.Nome
+			}
 		}
 	}
 
 	log.Printf("Partida %s finalizada. Vencedor: %s", sala.ID, vencedorFinal)
 
+	novosRatings := s.calcularNovosRatings(sala, vencedorFinal)
+	pontosPartida := make(map[string]int, len(sala.PontosPartida))
+	for nome, pontos := range sala.PontosPartida {
+		pontosPartida[nome] = pontos
+	}
+	s.registrarEventoHost(sala, "MATCH_END", "", map[string]interface{}{"vencedor": vencedorFinal})
+	s.registrarHistoricoPartida(sala, vencedorFinal)
+	s.iniciarRegistroResultado(sala, vencedorFinal)
+	s.registrarResultadoNoCluster(sala, vencedorFinal)
+	s.liberarEspectadores(sala)
+
 	msg := protocolo.Mensagem{
 		Comando: "FIM_DE_JOGO",
-		Dados:   seguranca.MustJSON(protocolo.DadosFimDeJogo{VencedorNome: vencedorFinal, SalaID: sala.ID}),
+		Dados: seguranca.MustJSON(protocolo.DadosFimDeJogo{
+			VencedorNome:  vencedorFinal,
+			SalaID:        sala.ID,
+			PontosPartida: pontosPartida,
+			Ratings:       novosRatings,
+		}),
 	}
 
-	// CORREÇÃO: Não adquire lock - assume que já está ativo
-	jogadores := make([]*tipos.Cliente, len(sala.Jogadores))
-	copy(jogadores, sala.Jogadores)
 	sombraAddr := sala.ServidorSombra
 
-	for _, jogador := range jogadores {
+	for _, jogador := range sala.Jogadores {
 		if s.getClienteLocal(jogador.ID) != nil {
 			s.publicarParaCliente(jogador.ID, msg)
 		} else {
@@ -2490,6 +4043,125 @@ func (s *Servidor) finalizarPartida(sala *tipos.Sala) {
 	}
 }
 
+// registrarHistoricoPartida salva o resultado da partida no histórico dos jogadores locais.
+// Executada na goroutine da sala (a partir de finalizarPartida); a gravação fica com agendarPersistencia.
+func (s *Servidor) registrarHistoricoPartida(sala *tipos.Sala, vencedorFinal string) {
+	if s.Jogadores == nil {
+		return
+	}
+
+	for _, jogador := range sala.Jogadores {
+		cliente := s.getClienteLocal(jogador.ID)
+		if cliente == nil {
+			continue // O servidor do jogador remoto registra o histórico dele
+		}
+
+		oponenteNome := ""
+		for _, outro := range sala.Jogadores {
+			if outro.ID != jogador.ID {
+				oponenteNome = outro.Nome
+			}
+		}
+
+		nome := jogador.Nome
+		resumo := protocolo.ResumoPartida{
+			SalaID:       sala.ID,
+			OponenteNome: oponenteNome,
+			VencedorNome: vencedorFinal,
+			Timestamp:    time.Now().Unix(),
+		}
+		s.agendarPersistencia(func() {
+			s.persistirJogador(cliente)
+			if err := s.Jogadores.AdicionarPartida(nome, resumo); err != nil {
+				log.Printf("[PERSISTENCIA_ERRO] Falha ao registrar partida %s para %s: %v", resumo.SalaID, nome, err)
+			}
+		})
+	}
+}
+
+// calcularNovosRatings é a prévia do ELO mostrada em FIM_DE_JOGO, a partir dos ratings conhecidos
+// agora. O valor que vale é calculado ao aplicar o resultado no log replicado (aplicarRatings), sobre
+// os ratings de todas as partidas anteriores do log. Executada na goroutine da sala.
+func (s *Servidor) calcularNovosRatings(sala *tipos.Sala, vencedorFinal string) map[string]int {
+	if len(sala.Jogadores) != 2 {
+		return nil
+	}
+	nomeA, nomeB := sala.Jogadores[0].Nome, sala.Jogadores[1].Nome
+	novos := ratingsAposPartida(nomeA, nomeB, s.ratingDe(nomeA), s.ratingDe(nomeB), vencedorFinal)
+	log.Printf("[RANKING:%s] Prévia: %s: %d -> %d | %s: %d -> %d", sala.ID, nomeA, s.ratingDe(nomeA), novos[nomeA], nomeB, s.ratingDe(nomeB), novos[nomeB])
+	return novos
+}
+
+// ratingsAposPartida aplica o ELO ao resultado entre nomeA e nomeB ("EMPATE" ou outro nome = empate)
+func ratingsAposPartida(nomeA, nomeB string, ratingA, ratingB int, vencedor string) map[string]int {
+	resultadoA := 0.5
+	switch vencedor {
+	case nomeA:
+		resultadoA = 1
+	case nomeB:
+		resultadoA = 0
+	}
+	novoA, novoB := ranking.Atualizar(ratingA, ratingB, resultadoA)
+	return map[string]int{nomeA: novoA, nomeB: novoB}
+}
+
+// aplicarRatings atualiza os ratings com o resultado de uma entrada do log e grava os novos valores
+// em resultado.Ratings. Como parte dos ratings atuais, aplicada na ordem do log, nenhuma partida
+// sobrescreve outra confirmada antes dela.
+func (s *Servidor) aplicarRatings(resultado *tipos.ResultadoPartida) {
+	if len(resultado.Jogadores) != 2 {
+		return
+	}
+	nomeA, nomeB := resultado.Jogadores[0].Nome, resultado.Jogadores[1].Nome
+	s.mutexRatings.Lock()
+	defer s.mutexRatings.Unlock()
+	atual := func(nome string) int {
+		if rating, ok := s.Ratings[nome]; ok {
+			return rating
+		}
+		return ranking.RATING_INICIAL
+	}
+	ratingA, ratingB := atual(nomeA), atual(nomeB)
+	resultado.Ratings = ratingsAposPartida(nomeA, nomeB, ratingA, ratingB, resultado.Vencedor)
+	for nome, rating := range resultado.Ratings {
+		s.Ratings[nome] = rating
+	}
+	log.Printf("[RANKING:%s] %s: %d -> %d | %s: %d -> %d", resultado.SalaID, nomeA, ratingA, resultado.Ratings[nomeA], nomeB, ratingB, resultado.Ratings[nomeB])
+}
+
+// registrarResultadoNoCluster propõe o resultado da partida ao log replicado. A entrada leva só o
+// desfecho; os ratings são calculados por cada servidor ao aplicá-la (aplicarRatings).
+// Executada na goroutine da sala; a proposta em si roda em outra goroutine.
+func (s *Servidor) registrarResultadoNoCluster(sala *tipos.Sala, vencedorFinal string) {
+	resultado := tipos.ResultadoPartida{
+		SalaID:        sala.ID,
+		Jogadores:     make([]tipos.Player, 0, len(sala.Jogadores)),
+		Vencedor:      vencedorFinal,
+		PontosRodada:  make(map[string]int, len(sala.PontosRodada)),
+		PontosPartida: make(map[string]int, len(sala.PontosPartida)),
+		Timestamp:     time.Now().Unix(),
+	}
+	for _, jogador := range sala.Jogadores {
+		servidorJogador := sala.ServidorHost
+		if s.getClienteLocal(jogador.ID) == nil {
+			servidorJogador = sala.ServidorSombra
+		}
+		resultado.Jogadores = append(resultado.Jogadores, tipos.Player{ID: jogador.ID, Nome: jogador.Nome, Server: servidorJogador})
+	}
+	for nome, pontos := range sala.PontosRodada {
+		resultado.PontosRodada[nome] = pontos
+	}
+	for nome, pontos := range sala.PontosPartida {
+		resultado.PontosPartida[nome] = pontos
+	}
+
+	go func() {
+		if _, err := s.ClusterManager.Propor("RESULTADO_PARTIDA", resultado); err != nil {
+			log.Printf("[RAFT_ERRO] Falha ao registrar resultado da partida %s: %v", resultado.SalaID, err)
+		}
+	}()
+}
+
 // sincronizarEstadoComSombra envia o estado atualizado da partida para a Sombra
 func (s *Servidor) sincronizarEstadoComSombra(sombra string, estado *tipos.EstadoPartida) {
 	jsonData, _ := json.Marshal(estado)
@@ -2558,24 +4230,337 @@ func (s *Servidor) enviarAtualizacaoParaSombra(sombraAddr string, msg protocolo.
 func (s *Servidor) broadcastChat(sala *tipos.Sala, texto, remetenteNome string) {
 	log.Printf("[HOST-CHAT] Retransmitindo chat de '%s' para sala %s", remetenteNome, sala.ID)
 
-	msgChat := protocolo.Mensagem{
-		Comando: "CHAT_RECEBIDO", // O cliente já trata CHAT_RECEBIDO (em handleMensagemServidor)
-		Dados: seguranca.MustJSON(protocolo.DadosReceberChat{
-			NomeJogador: remetenteNome,
-			Texto:       texto,
-		}),
+	msgChat := protocolo.Mensagem{
+		Comando: "CHAT_RECEBIDO", // O cliente já trata CHAT_RECEBIDO (em handleMensagemServidor)
+		Dados: seguranca.MustJSON(protocolo.DadosReceberChat{
+			NomeJogador: remetenteNome,
+			Texto:       texto,
+		}),
+	}
+
+	// Publica no tópico de eventos da PARTIDA.
+	// Ambos os clientes (no Host e no Shadow) estão inscritos neste tópico
+	// (ver cliente/main.go -> case "PARTIDA_ENCONTRADA")
+	s.publicarEventoPartida(sala.ID, msgChat)
+}
+
+// ==================== RESULTADO CO-ASSINADO ====================
+
+// resultadoPendente reúne as assinaturas do resultado de uma partida hospedada por este servidor
+// até que estejam completas e ele possa ser registrado no contrato (protegido por mutexAssinaturas).
+type resultadoPendente struct {
+	Resultado    blockchain.ResultadoPartida
+	Hash         common.Hash               // hashResultado do contrato, o que cada parte assina
+	VencedorNome string                    // Nome do vencedor / "EMPATE", enviado aos jogadores
+	Sombra       string                    // Endereço HTTP da Sombra ("" em partida local)
+	ContaSombra  common.Address            // Conta blockchain da Sombra, conhecida quando ela assina
+	Assinaturas  map[common.Address][]byte // assinante -> assinatura
+	Registrando  bool                      // Todas as assinaturas chegaram e a transação foi enviada
+}
+
+// resultadoDaSala monta o resultado a partir do EventLog (hash e vencedor do MATCH_END) e das
+// carteiras dos jogadores. Falha se a partida não terminou ou se algum jogador não tem carteira.
+// Executada na goroutine da sala ou sobre um Retrato.
+func resultadoDaSala(sala *tipos.Sala) (blockchain.ResultadoPartida, error) {
+	var resultado blockchain.ResultadoPartida
+	if len(sala.Jogadores) != 2 {
+		return resultado, fmt.Errorf("sala com %d jogadores", len(sala.Jogadores))
+	}
+	hashLog, err := seguranca.HashEventLog(sala.EventLog)
+	if err != nil {
+		return resultado, err
+	}
+	vencedorNome := ""
+	for _, evento := range sala.EventLog {
+		if evento.EventType == "MATCH_END" {
+			dados, _ := evento.Data.(map[string]interface{})
+			vencedorNome, _ = dados["vencedor"].(string)
+			break
+		}
+	}
+
+	enderecos := make([]common.Address, 0, 2)
+	for _, jogador := range sala.Jogadores {
+		jogador.Mutex.Lock()
+		endereco := jogador.EnderecoBlockchain
+		jogador.Mutex.Unlock()
+		if endereco == "" {
+			return resultado, fmt.Errorf("jogador %s sem carteira vinculada", jogador.Nome)
+		}
+		enderecos = append(enderecos, common.HexToAddress(endereco))
+		if jogador.Nome == vencedorNome {
+			resultado.Vencedor = common.HexToAddress(endereco)
+		}
+	}
+	resultado.Jogador1, resultado.Jogador2 = enderecos[0], enderecos[1]
+	resultado.HashLog = hashLog
+	return resultado, nil
+}
+
+// iniciarRegistroResultado assina o resultado como Host e pede as assinaturas da Sombra e dos
+// jogadores. Quando todas chegam, o resultado é registrado no contrato junto com o hash do
+// EventLog; sem elas até PRAZO_ASSINATURAS_RESULTADO, a partida fica só no histórico local.
+// Executada na goroutine da sala (a partir de finalizarPartida).
+func (s *Servidor) iniciarRegistroResultado(sala *tipos.Sala, vencedorFinal string) {
+	if s.BlockchainManager == nil {
+		return
+	}
+	resultado, err := resultadoDaSala(sala)
+	if err != nil {
+		log.Printf("[RESULTADO:%s] Resultado não será registrado no contrato: %v", sala.ID, err)
+		return
+	}
+	assinatura, err := s.BlockchainManager.AssinarResultado(resultado)
+	if err != nil {
+		log.Printf("[RESULTADO_ERRO:%s] Falha ao assinar o resultado como Host: %v", sala.ID, err)
+		return
+	}
+
+	pendente := &resultadoPendente{
+		Resultado:    resultado,
+		Hash:         s.BlockchainManager.HashResultado(resultado),
+		VencedorNome: vencedorFinal,
+		Sombra:       sala.ServidorSombra,
+		Assinaturas:  map[common.Address][]byte{s.BlockchainManager.ContaServidor(): assinatura},
+	}
+	s.mutexAssinaturas.Lock()
+	s.resultadosPendentes[sala.ID] = pendente
+	s.mutexAssinaturas.Unlock()
+
+	salaID := sala.ID
+	time.AfterFunc(PRAZO_ASSINATURAS_RESULTADO, func() { s.expirarResultadoPendente(salaID, pendente) })
+	if pendente.Sombra != "" {
+		go s.solicitarAssinaturaSombra(salaID, pendente.Sombra, resultado)
+	}
+
+	log.Printf("[RESULTADO:%s] Hash do EventLog %s. Aguardando assinaturas da Sombra e dos jogadores.", sala.ID, resultado.HashLog.Hex())
+	msg := protocolo.Mensagem{
+		Comando: "ASSINAR_RESULTADO",
+		Dados: seguranca.MustJSON(protocolo.DadosAssinarResultado{
+			SalaID:       sala.ID,
+			Jogador1:     resultado.Jogador1.Hex(),
+			Jogador2:     resultado.Jogador2.Hex(),
+			Vencedor:     resultado.Vencedor.Hex(),
+			VencedorNome: vencedorFinal,
+			HashLog:      resultado.HashLog.Hex(),
+		}),
+	}
+	for _, jogador := range sala.Jogadores {
+		if s.getClienteLocal(jogador.ID) != nil {
+			s.publicarParaCliente(jogador.ID, msg)
+		} else if pendente.Sombra != "" {
+			go s.notificarJogadorRemoto(pendente.Sombra, jogador.ID, msg)
+		}
+	}
+}
+
+// solicitarAssinaturaSombra pede à Sombra que confira o resultado no EventLog replicado e o
+// assine. A réplica com o MATCH_END pode chegar depois do pedido, então ele é repetido até o prazo.
+func (s *Servidor) solicitarAssinaturaSombra(salaID, sombra string, resultado blockchain.ResultadoPartida) {
+	corpo, _ := json.Marshal(tipos.AssinarResultadoRequest{
+		MatchID:  salaID,
+		Jogador1: resultado.Jogador1.Hex(),
+		Jogador2: resultado.Jogador2.Hex(),
+		Vencedor: resultado.Vencedor.Hex(),
+		HashLog:  resultado.HashLog.Hex(),
+	})
+	url := fmt.Sprintf("http://%s/partida/assinar_resultado", sombra)
+
+	limite := time.Now().Add(PRAZO_ASSINATURAS_RESULTADO)
+	for time.Now().Before(limite) {
+		resp, err := s.enviarRequestComToken("POST", url, corpo)
+		if err == nil {
+			var resposta tipos.AssinarResultadoResponse
+			if resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(&resposta) == nil {
+				resp.Body.Close()
+				s.receberAssinaturaSombra(salaID, resposta)
+				return
+			}
+			corpoErro, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			log.Printf("[RESULTADO:%s] Sombra %s ainda não assinou (status %d): %s", salaID, sombra, resp.StatusCode, strings.TrimSpace(string(corpoErro)))
+		}
+		time.Sleep(INTERVALO_ASSINATURA_SOMBRA)
+	}
+}
+
+// receberAssinaturaSombra confere que a assinatura devolvida pela Sombra é da conta que ela informou
+func (s *Servidor) receberAssinaturaSombra(salaID string, resposta tipos.AssinarResultadoResponse) {
+	s.mutexAssinaturas.Lock()
+	defer s.mutexAssinaturas.Unlock()
+	pendente, ok := s.resultadosPendentes[salaID]
+	if !ok {
+		return
+	}
+	conta := common.HexToAddress(resposta.Conta)
+	assinante, err := seguranca.RecuperarEnderecoHash(pendente.Hash, resposta.Assinatura)
+	if err != nil || assinante != conta {
+		log.Printf("[RESULTADO_ERRO:%s] Assinatura da Sombra não confere com a conta %s", salaID, conta.Hex())
+		return
+	}
+	if conta == s.BlockchainManager.ContaServidor() {
+		log.Printf("[RESULTADO_ERRO:%s] Sombra e Host usam a mesma conta %s; o contrato exige contas distintas", salaID, conta.Hex())
+		return
+	}
+	pendente.ContaSombra = conta
+	pendente.Assinaturas[conta] = common.FromHex(resposta.Assinatura)
+	log.Printf("[RESULTADO:%s] Sombra (%s) assinou o resultado", salaID, conta.Hex())
+	s.registrarSeAssinaturasCompletas(salaID, pendente)
+}
+
+// receberAssinaturaResultado guarda a assinatura de um jogador (ASSINATURA_RESULTADO). Só vale se
+// o assinante for a carteira de um dos jogadores do resultado.
+func (s *Servidor) receberAssinaturaResultado(salaID, clienteID, assinatura string) {
+	s.mutexAssinaturas.Lock()
+	defer s.mutexAssinaturas.Unlock()
+	pendente, ok := s.resultadosPendentes[salaID]
+	if !ok {
+		log.Printf("[RESULTADO:%s] Assinatura de %s sem resultado pendente", salaID, clienteID)
+		return
+	}
+	assinante, err := seguranca.RecuperarEnderecoHash(pendente.Hash, assinatura)
+	if err != nil {
+		log.Printf("[RESULTADO_ERRO:%s] Assinatura de %s inválida: %v", salaID, clienteID, err)
+		return
+	}
+	if assinante != pendente.Resultado.Jogador1 && assinante != pendente.Resultado.Jogador2 {
+		log.Printf("[RESULTADO_ERRO:%s] Assinatura de %s feita por %s, que não é jogador da partida", salaID, clienteID, assinante.Hex())
+		return
+	}
+	pendente.Assinaturas[assinante] = common.FromHex(assinatura)
+	log.Printf("[RESULTADO:%s] Jogador %s (%s) assinou o resultado", salaID, clienteID, assinante.Hex())
+	s.registrarSeAssinaturasCompletas(salaID, pendente)
+}
+
+// registrarSeAssinaturasCompletas envia o resultado ao contrato quando Host, Sombra (se houver) e
+// os dois jogadores já assinaram. Assume que mutexAssinaturas JÁ ESTÁ ATIVO.
+func (s *Servidor) registrarSeAssinaturasCompletas(salaID string, pendente *resultadoPendente) {
+	if pendente.Registrando {
+		return
+	}
+	host := s.BlockchainManager.ContaServidor()
+	var sombra common.Address
+	assinaturaSombra := []byte{}
+	if pendente.Sombra != "" {
+		if pendente.ContaSombra == (common.Address{}) {
+			return
+		}
+		sombra = pendente.ContaSombra
+		assinaturaSombra = pendente.Assinaturas[sombra]
+	}
+	// Mesma ordem de registrarPartida no contrato: [host, sombra, jogador1, jogador2]
+	assinaturas := [][]byte{
+		pendente.Assinaturas[host],
+		assinaturaSombra,
+		pendente.Assinaturas[pendente.Resultado.Jogador1],
+		pendente.Assinaturas[pendente.Resultado.Jogador2],
+	}
+	if len(assinaturas[0]) == 0 || len(assinaturas[2]) == 0 || len(assinaturas[3]) == 0 {
+		return
+	}
+	pendente.Registrando = true
+	go s.registrarResultadoNoContrato(salaID, pendente.Resultado, host, sombra, assinaturas)
+}
+
+// registrarResultadoNoContrato envia o resultado co-assinado e aguarda a confirmação
+func (s *Servidor) registrarResultadoNoContrato(salaID string, resultado blockchain.ResultadoPartida, host, sombra common.Address, assinaturas [][]byte) {
+	err := s.BlockchainManager.RegistrarPartida(resultado, host, sombra, assinaturas)
+
+	s.mutexAssinaturas.Lock()
+	delete(s.resultadosPendentes, salaID)
+	s.mutexAssinaturas.Unlock()
+
+	if err != nil {
+		log.Printf("[RESULTADO_ERRO:%s] Falha ao registrar o resultado no contrato: %v", salaID, err)
+		return
+	}
+	log.Printf("[RESULTADO:%s] ✓ Resultado registrado no contrato (hash do EventLog %s)", salaID, resultado.HashLog.Hex())
+}
+
+// expirarResultadoPendente descarta o resultado que não reuniu as assinaturas no prazo
+func (s *Servidor) expirarResultadoPendente(salaID string, pendente *resultadoPendente) {
+	s.mutexAssinaturas.Lock()
+	defer s.mutexAssinaturas.Unlock()
+	if s.resultadosPendentes[salaID] != pendente || pendente.Registrando {
+		return
+	}
+	delete(s.resultadosPendentes, salaID)
+	log.Printf("[RESULTADO:%s] Prazo de assinaturas esgotado (%d recebidas). Resultado não registrado no contrato.", salaID, len(pendente.Assinaturas))
+}
+
+// AssinarResultadoComoSombra confere o resultado proposto pelo Host contra o EventLog replicado
+// nesta Sombra e, se hash do log, jogadores e vencedor coincidem, assina com a conta do servidor.
+func (s *Servidor) AssinarResultadoComoSombra(req tipos.AssinarResultadoRequest) (tipos.AssinarResultadoResponse, error) {
+	if s.BlockchainManager == nil {
+		return tipos.AssinarResultadoResponse{}, fmt.Errorf("blockchain não configurado neste servidor")
+	}
+	s.mutexSalas.RLock()
+	sala, ok := s.Salas[req.MatchID]
+	s.mutexSalas.RUnlock()
+	if !ok {
+		return tipos.AssinarResultadoResponse{}, fmt.Errorf("sala %s não encontrada", req.MatchID)
+	}
+
+	retrato := sala.Retrato()
+	sombra := retrato.ServidorSombra
+	local, err := resultadoDaSala(retrato)
+	if sombra != s.MeuEndereco {
+		return tipos.AssinarResultadoResponse{}, fmt.Errorf("este servidor não é a Sombra da sala %s", req.MatchID)
+	}
+	if err != nil {
+		return tipos.AssinarResultadoResponse{}, err
+	}
+
+	proposto := blockchain.ResultadoPartida{
+		Jogador1: common.HexToAddress(req.Jogador1),
+		Jogador2: common.HexToAddress(req.Jogador2),
+		Vencedor: common.HexToAddress(req.Vencedor),
+		HashLog:  common.HexToHash(req.HashLog),
+	}
+	if proposto.HashLog != local.HashLog {
+		return tipos.AssinarResultadoResponse{}, fmt.Errorf("hash do EventLog diverge (Host %s, Sombra %s)", proposto.HashLog.Hex(), local.HashLog.Hex())
+	}
+	mesmosJogadores := (proposto.Jogador1 == local.Jogador1 && proposto.Jogador2 == local.Jogador2) ||
+		(proposto.Jogador1 == local.Jogador2 && proposto.Jogador2 == local.Jogador1)
+	if !mesmosJogadores || proposto.Vencedor != local.Vencedor {
+		return tipos.AssinarResultadoResponse{}, fmt.Errorf("jogadores ou vencedor divergem do EventLog da Sombra")
+	}
+
+	assinatura, err := s.BlockchainManager.AssinarResultado(proposto)
+	if err != nil {
+		return tipos.AssinarResultadoResponse{}, err
 	}
+	log.Printf("[RESULTADO:%s] Resultado conferido e assinado como Sombra", req.MatchID)
+	return tipos.AssinarResultadoResponse{
+		Conta:      s.BlockchainManager.ContaServidor().Hex(),
+		Assinatura: hexutil.Encode(assinatura),
+	}, nil
+}
 
-	// Publica no tópico de eventos da PARTIDA.
-	// Ambos os clientes (no Host e no Shadow) estão inscritos neste tópico
-	// (ver cliente/main.go -> case "PARTIDA_ENCONTRADA")
-	s.publicarEventoPartida(sala.ID, msgChat)
+// encaminharComandoParaHost repassa ao Host, pela rota /partida/encaminhar_comando, um comando
+// publicado por um jogador conectado a esta Sombra.
+func (s *Servidor) encaminharComandoParaHost(hostAddr, salaID string, comando protocolo.Mensagem) {
+	corpo, _ := json.Marshal(map[string]interface{}{
+		"sala_id": salaID,
+		"comando": comando,
+	})
+	resp, err := s.enviarRequestComToken("POST", fmt.Sprintf("http://%s/partida/encaminhar_comando", hostAddr), corpo)
+	if err != nil {
+		log.Printf("[SHADOW] Erro ao encaminhar %s ao Host %s: %v", comando.Comando, hostAddr, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("[SHADOW] Host %s retornou status %d para %s", hostAddr, resp.StatusCode, comando.Comando)
+	}
 }
 
 // ==================== LÓGICA DE TROCA DE CARTAS ====================
 
 func (s *Servidor) ProcessarTrocaDireta(sala *tipos.Sala, req *protocolo.TrocarCartasReq) {
-	s.processarTrocaCartas(sala, req)
+	if _, err := s.executarNaSala(sala, protocolo.Comando{ClienteID: req.IDJogadorOferta, Tipo: CMD_TROCA_DIRETA, Payload: seguranca.MustJSON(req)}); err != nil {
+		log.Printf("[TROCA_ERRO] %v", err)
+	}
 }
 
 // AplicarTrocaLocal remove a carta desejada do cliente local e adiciona a carta oferecida
@@ -2644,6 +4629,17 @@ func (s *Servidor) AplicarTrocaLocal(clienteID string, idCartaDesejada string, c
 	return true, cartaRemovida, snapshot
 }
 
+// trocaConcluida é o payload de CMD_TROCA_CONCLUIDA: o inventário do ofertante depois da troca,
+// para a cópia da sala. Sem Aplicada, a troca falhou e só a sala fica livre para outra.
+type trocaConcluida struct {
+	ClienteID  string        `json:"cliente_id"`
+	Inventario []tipos.Carta `json:"inventario"`
+	Aplicada   bool          `json:"aplicada"`
+}
+
+// processarTrocaCartas roda na goroutine da sala. A troca em si (HTTP com o outro servidor e
+// RegistrarTrocaAdmin na blockchain) segue em executarTrocaCartas, numa goroutine própria, e
+// volta para a sala como CMD_TROCA_CONCLUIDA. Uma troca por vez em cada sala.
 func (s *Servidor) processarTrocaCartas(sala *tipos.Sala, req *protocolo.TrocarCartasReq) {
 	log.Printf("[TROCA] === INÍCIO PROCESSAMENTO TROCA FORÇADA ===")
 	log.Printf("[TROCA] Sala: %s", sala.ID)
@@ -2653,11 +4649,53 @@ func (s *Servidor) processarTrocaCartas(sala *tipos.Sala, req *protocolo.TrocarC
 	// NECESSÁRIO: Apenas o Host coordena a troca
 	if sala.ServidorHost != s.MeuEndereco {
 		log.Printf("[TROCA] Shadow (servidor %s) encaminhando troca para o Host %s", s.MeuEndereco, sala.ServidorHost)
-		s.encaminharTrocaParaHost(sala.ServidorHost, sala.ID, req)
+		go s.encaminharTrocaParaHost(sala.ServidorHost, sala.ID, req)
+		return
+	}
+	if sala.TrocaPendente {
+		s.notificarErro(req.IDJogadorOferta, "Há uma troca em andamento nesta partida. Tente novamente em instantes.")
+		return
+	}
+
+	// A goroutine da troca não lê a sala: recebe os servidores e uma cópia dos inventários
+	outroServidor := sala.ServidorSombra
+	inventariosSala := make(map[string][]tipos.Carta, len(sala.Jogadores))
+	for _, j := range sala.Jogadores {
+		inventariosSala[j.ID] = append([]tipos.Carta(nil), j.Inventario...)
+	}
+	sala.TrocaPendente = true
+
+	go func() {
+		resultado := s.executarTrocaCartas(outroServidor, inventariosSala, req)
+		if err := s.enviarComandoSala(sala, protocolo.Comando{
+			ClienteID: req.IDJogadorOferta,
+			Tipo:      CMD_TROCA_CONCLUIDA,
+			Payload:   seguranca.MustJSON(resultado),
+		}); err != nil {
+			log.Printf("[TROCA_ERRO:%s] Resultado da troca não voltou para a sala: %v", sala.ID, err)
+		}
+	}()
+}
+
+// concluirTrocaCartas aplica, na goroutine da sala, o resultado de executarTrocaCartas.
+func (s *Servidor) concluirTrocaCartas(sala *tipos.Sala, resultado trocaConcluida) {
+	sala.TrocaPendente = false
+	if !resultado.Aplicada {
 		return
 	}
+	for i := range sala.Jogadores {
+		if sala.Jogadores[i].ID == resultado.ClienteID {
+			sala.Jogadores[i].Inventario = resultado.Inventario
+			log.Printf("[TROCA] Cópia da sala atualizada para %s", sala.Jogadores[i].Nome)
+			break
+		}
+	}
+}
 
-	// Processa a troca no Host
+// executarTrocaCartas faz a troca no Host, fora da goroutine da sala. outroServidor é a Sombra
+// (onde estão os jogadores que não são locais) e inventariosSala a cópia dos inventários da sala.
+func (s *Servidor) executarTrocaCartas(outroServidor string, inventariosSala map[string][]tipos.Carta, req *protocolo.TrocarCartasReq) trocaConcluida {
+	resultado := trocaConcluida{ClienteID: req.IDJogadorOferta}
 	log.Printf("[TROCA] Processando troca no Host")
 
 	// Busca jogadores
@@ -2667,33 +4705,11 @@ func (s *Servidor) processarTrocaCartas(sala *tipos.Sala, req *protocolo.TrocarC
 	s.mutexClientes.RUnlock()
 
 	// Busca na sala se não encontrou no mapa global
-	if jogadorOferta == nil {
-		sala.Mutex.Lock()
-		for _, j := range sala.Jogadores {
-			if j.ID == req.IDJogadorOferta {
-				jogadorOferta = j
-				log.Printf("[TROCA] Jogador ofertante %s encontrado na cópia da sala", j.Nome)
-				break
-			}
-		}
-		sala.Mutex.Unlock()
-	}
-
-	if jogadorDesejado == nil {
-		sala.Mutex.Lock()
-		for _, j := range sala.Jogadores {
-			if j.ID == req.IDJogadorDesejado {
-				jogadorDesejado = j
-				log.Printf("[TROCA] Jogador desejado %s encontrado na cópia da sala", j.Nome)
-				break
-			}
-		}
-		sala.Mutex.Unlock()
-	}
-
-	if jogadorOferta == nil || jogadorDesejado == nil {
+	_, ofertaNaSala := inventariosSala[req.IDJogadorOferta]
+	_, desejadoNaSala := inventariosSala[req.IDJogadorDesejado]
+	if (jogadorOferta == nil && !ofertaNaSala) || (jogadorDesejado == nil && !desejadoNaSala) {
 		s.notificarErro(req.IDJogadorOferta, "Um dos jogadores não foi encontrado.")
-		return
+		return resultado
 	}
 
 	// Busca carta do ofertante
@@ -2716,658 +4732,1328 @@ func (s *Servidor) processarTrocaCartas(sala *tipos.Sala, req *protocolo.TrocarC
 			}
 		}
 		jogadorReal.Mutex.Unlock()
-		log.Printf("[TROCA] Carta ofertante %s encontrada no inventário real (idx: %d)", cartaOferta.Nome, idxOferta)
-	} else {
-		// Cliente remoto - busca no servidor dele via HTTP
-		log.Printf("[TROCA] Jogador ofertante %s está remoto. Buscando carta via HTTP...", req.NomeJogadorOferta)
-
-		// Determina servidor do jogador ofertante
-		var servidorJogadorOferta string
-		if sala.ServidorHost == s.MeuEndereco {
-			// Sou Host, jogador remoto está na Sombra
-			servidorJogadorOferta = sala.ServidorSombra
-		} else {
-			// Sou Shadow, jogador remoto está no Host
-			servidorJogadorOferta = sala.ServidorHost
-		}
+		log.Printf("[TROCA] Carta ofertante %s encontrada no inventário real (idx: %d)", cartaOferta.Nome, idxOferta)
+	} else {
+		// Cliente remoto - busca no servidor dele via HTTP
+		log.Printf("[TROCA] Jogador ofertante %s está remoto. Buscando carta via HTTP...", req.NomeJogadorOferta)
+
+		// Só o Host executa a troca: o jogador remoto está na Sombra
+		servidorJogadorOferta := outroServidor
+
+		log.Printf("[TROCA] Buscando no servidor %s a carta %s do jogador %s", servidorJogadorOferta, req.IDCartaOferecida, req.IDJogadorOferta)
+
+		// Busca a carta no servidor remoto
+		payload := map[string]interface{}{
+			"cliente_id": req.IDJogadorOferta,
+			"carta_id":   req.IDCartaOferecida,
+		}
+		body, _ := json.Marshal(payload)
+
+		url := fmt.Sprintf("http://%s/partida/buscar_carta", servidorJogadorOferta)
+		resp, err := s.enviarRequestComToken("POST", url, body)
+		if err != nil {
+			log.Printf("[TROCA] Erro ao buscar carta do ofertante no remoto: %v", err)
+		} else if resp != nil {
+			defer resp.Body.Close()
+			if resp.StatusCode == 200 {
+				var result struct {
+					Encontrada bool        `json:"encontrada"`
+					Carta      tipos.Carta `json:"carta"`
+				}
+				if json.NewDecoder(resp.Body).Decode(&result) == nil && result.Encontrada {
+					cartaOferta = result.Carta
+					idxOferta = 99 // Placeholder para indicar que foi encontrada remotamente
+					log.Printf("[TROCA] Carta ofertante %s encontrada no servidor remoto", cartaOferta.Nome)
+				}
+			}
+		}
+
+		// Se ainda não encontrou, tenta na cópia da sala como fallback
+		if cartaOferta.ID == "" {
+			for i, c := range inventariosSala[req.IDJogadorOferta] {
+				if c.ID == req.IDCartaOferecida {
+					cartaOferta = c
+					idxOferta = i
+					log.Printf("[TROCA] Carta ofertante %s encontrada na cópia da sala (fallback)", cartaOferta.Nome)
+					break
+				}
+			}
+		}
+	}
+
+	if cartaOferta.ID == "" {
+		s.notificarErro(req.IDJogadorOferta, "Você não possui esta carta.")
+		return resultado
+	}
+
+	// Busca carta desejada
+	var cartaDesejada tipos.Carta
+	s.mutexClientes.RLock()
+	jogadorDesejadoLocal := s.Clientes[req.IDJogadorDesejado]
+	s.mutexClientes.RUnlock()
+
+	if jogadorDesejadoLocal != nil {
+		// Jogador local - busca no inventário real
+		jogadorDesejadoLocal.Mutex.Lock()
+		for _, c := range jogadorDesejadoLocal.Inventario {
+			if c.ID == req.IDCartaDesejada {
+				cartaDesejada = c
+				break
+			}
+		}
+		jogadorDesejadoLocal.Mutex.Unlock()
+		log.Printf("[TROCA] Carta desejada %s encontrada no inventário real", cartaDesejada.Nome)
+	} else {
+		// Jogador está em outro servidor - busca no servidor remoto via HTTP
+		log.Printf("[TROCA] Jogador desejado %s está remoto. Buscando carta via HTTP no servidor dele...", req.NomeJogadorDesejado)
+
+		// Só o Host executa a troca: o jogador remoto está na Sombra
+		servidorJogadorDesejado := outroServidor
+
+		log.Printf("[TROCA] Buscando no servidor %s a carta %s do jogador %s", servidorJogadorDesejado, req.IDCartaDesejada, req.IDJogadorDesejado)
+
+		// Busca a carta no servidor remoto
+		payload := map[string]interface{}{
+			"cliente_id": req.IDJogadorDesejado,
+			"carta_id":   req.IDCartaDesejada,
+		}
+		body, _ := json.Marshal(payload)
+
+		url := fmt.Sprintf("http://%s/partida/buscar_carta", servidorJogadorDesejado)
+		resp, err := s.enviarRequestComToken("POST", url, body)
+		if err != nil {
+			log.Printf("[TROCA] Erro ao buscar carta no remoto: %v", err)
+		} else if resp != nil {
+			defer resp.Body.Close()
+			if resp.StatusCode == 200 {
+				var result struct {
+					Encontrada bool        `json:"encontrada"`
+					Carta      tipos.Carta `json:"carta"`
+				}
+				if json.NewDecoder(resp.Body).Decode(&result) == nil && result.Encontrada {
+					cartaDesejada = result.Carta
+					log.Printf("[TROCA] Carta desejada %s encontrada no servidor remoto", cartaDesejada.Nome)
+				}
+			}
+		}
+
+		// Se ainda não encontrou, tenta na cópia da sala como fallback
+		if cartaDesejada.ID == "" {
+			inventario := inventariosSala[req.IDJogadorDesejado]
+			log.Printf("[TROCA] Fallback: buscando carta %s no inventário de %s na cópia. Total: %d", req.IDCartaDesejada, req.NomeJogadorDesejado, len(inventario))
+			for _, c := range inventario {
+				if c.ID == req.IDCartaDesejada {
+					cartaDesejada = c
+					log.Printf("[TROCA] Carta desejada %s encontrada na cópia da sala", cartaDesejada.Nome)
+					break
+				}
+			}
+		}
+	}
+
+	if cartaDesejada.ID == "" {
+		s.notificarErro(req.IDJogadorOferta, fmt.Sprintf("%s não possui esta carta.", req.NomeJogadorDesejado))
+		return resultado
+	}
+
+	// ========== EXECUTA TROCA NA BLOCKCHAIN ==========
+	log.Printf("[TROCA_BLOCKCHAIN] Iniciando processo de troca na blockchain...")
+	log.Printf("[TROCA_BLOCKCHAIN] Carta oferecida: ID=%s, Nome=%s", req.IDCartaOferecida, cartaOferta.Nome)
+	log.Printf("[TROCA_BLOCKCHAIN] Carta desejada: ID=%s, Nome=%s", req.IDCartaDesejada, cartaDesejada.Nome)
+
+	// Verifica se ambos os jogadores têm endereços blockchain
+	s.mutexClientes.RLock()
+	clienteOferta := s.Clientes[req.IDJogadorOferta]
+	clienteDesejado := s.Clientes[req.IDJogadorDesejado]
+	s.mutexClientes.RUnlock()
+
+	// Executa troca na blockchain se ambos têm endereços e o blockchain manager está disponível
+	if s.BlockchainManager != nil && clienteOferta != nil && clienteDesejado != nil {
+		enderecoOferta := clienteOferta.EnderecoBlockchain
+		enderecoDesejado := clienteDesejado.EnderecoBlockchain
+
+		log.Printf("[TROCA_BLOCKCHAIN] Endereço jogador ofertante (%s): %s", req.NomeJogadorOferta, enderecoOferta)
+		log.Printf("[TROCA_BLOCKCHAIN] Endereço jogador desejado (%s): %s", req.NomeJogadorDesejado, enderecoDesejado)
+
+		if enderecoOferta != "" && enderecoDesejado != "" {
+			// Converte IDs das cartas para tokenIds (big.Int)
+			// O ID da carta na blockchain é o tokenId convertido para string
+			// Verifica se os IDs são números válidos (cartas da blockchain têm IDs numéricos)
+			tokenIdOferta, err1 := strconv.ParseInt(cartaOferta.ID, 10, 64)
+			tokenIdDesejada, err2 := strconv.ParseInt(cartaDesejada.ID, 10, 64)
+
+			log.Printf("[TROCA_BLOCKCHAIN] Tentando converter IDs: oferta='%s' (err=%v), desejada='%s' (err=%v)",
+				cartaOferta.ID, err1, cartaDesejada.ID, err2)
+
+			if err1 == nil && err2 == nil && tokenIdOferta >= 0 && tokenIdDesejada >= 0 {
+				log.Printf("[TROCA_BLOCKCHAIN] TokenIds: oferta=%d, desejada=%d", tokenIdOferta, tokenIdDesejada)
+
+				// Converte endereços para common.Address
+				addrOferta := common.HexToAddress(enderecoOferta)
+				addrDesejado := common.HexToAddress(enderecoDesejado)
+
+				// CORREÇÃO: Usa RegistrarTrocaAdmin ao invés de CriarPropostaTroca + AceitarPropostaTroca
+				// A função RegistrarTrocaAdmin é executada pelo servidor (admin/owner) e registra
+				// os endereços CORRETOS dos jogadores (ofertante e desejado) na transação
+				log.Printf("[TROCA_BLOCKCHAIN] Registrando troca via RegistrarTrocaAdmin...")
+				log.Printf("[TROCA_BLOCKCHAIN] Jogador1 (ofertante): %s (%s)", req.NomeJogadorOferta, addrOferta.Hex())
+				log.Printf("[TROCA_BLOCKCHAIN] Jogador2 (desejado): %s (%s)", req.NomeJogadorDesejado, addrDesejado.Hex())
+				log.Printf("[TROCA_BLOCKCHAIN] Carta1 (oferecida): ID=%d, Nome=%s", tokenIdOferta, cartaOferta.Nome)
+				log.Printf("[TROCA_BLOCKCHAIN] Carta2 (desejada): ID=%d, Nome=%s", tokenIdDesejada, cartaDesejada.Nome)
+
+				propostaID, err := s.BlockchainManager.RegistrarTrocaAdmin(
+					addrOferta,                  // Endereço do jogador ofertante (jogador1)
+					addrDesejado,                // Endereço do jogador desejado (jogador2)
+					big.NewInt(tokenIdOferta),   // ID da carta oferecida
+					big.NewInt(tokenIdDesejada), // ID da carta desejada
+				)
+
+				if err != nil {
+					log.Printf("[TROCA_BLOCKCHAIN_ERRO] Falha ao registrar troca: %v", err)
+					// Continua com a troca local mesmo se a blockchain falhar
+				} else {
+					log.Printf("[TROCA_BLOCKCHAIN] ✓ Troca registrada com sucesso na blockchain!")
+					log.Printf("[TROCA_BLOCKCHAIN] ID da proposta/troca: %s", propostaID.String())
+					log.Printf("[TROCA_BLOCKCHAIN] Os endereços registrados são:")
+					log.Printf("[TROCA_BLOCKCHAIN]   - Jogador ofertante: %s", addrOferta.Hex())
+					log.Printf("[TROCA_BLOCKCHAIN]   - Jogador desejado: %s", addrDesejado.Hex())
+				}
+			} else {
+				log.Printf("[TROCA_BLOCKCHAIN_AVISO] Não foi possível converter IDs para tokenIds: err1=%v, err2=%v", err1, err2)
+				log.Printf("[TROCA_BLOCKCHAIN_AVISO] ID carta oferta: %s, ID carta desejada: %s", cartaOferta.ID, cartaDesejada.ID)
+			}
+		} else {
+			log.Printf("[TROCA_BLOCKCHAIN_AVISO] Um ou ambos os jogadores não têm endereço blockchain configurado")
+			log.Printf("[TROCA_BLOCKCHAIN_AVISO] Ofertante tem endereço: %v, Desejado tem endereço: %v", enderecoOferta != "", enderecoDesejado != "")
+		}
+	} else {
+		if s.BlockchainManager == nil {
+			log.Printf("[TROCA_BLOCKCHAIN_AVISO] BlockchainManager não está disponível")
+		}
+		if clienteOferta == nil {
+			log.Printf("[TROCA_BLOCKCHAIN_AVISO] Cliente ofertante não encontrado")
+		}
+		if clienteDesejado == nil {
+			log.Printf("[TROCA_BLOCKCHAIN_AVISO] Cliente desejado não encontrado")
+		}
+	}
+	// ========== FIM TROCA NA BLOCKCHAIN ==========
+
+	// EXECUTA A TROCA LOCAL - Remove carta do ofertante e adiciona carta desejada
+	var novoInventario []tipos.Carta
+
+	if jogadorReal != nil && idxOferta != 99 {
+		// Atualiza inventário real do jogador local
+		jogadorReal.Mutex.Lock()
+		inventario := jogadorReal.Inventario
+		inventario = append(inventario[:idxOferta], inventario[idxOferta+1:]...)
+		inventario = append(inventario, cartaDesejada)
+		jogadorReal.Inventario = inventario
+		novoInventario = make([]tipos.Carta, len(inventario))
+		copy(novoInventario, inventario)
+		jogadorReal.Mutex.Unlock()
+		log.Printf("[TROCA] Inventário real do ofertante atualizado: %d cartas", len(inventario))
+	} else if idxOferta == 99 {
+		// Carta foi encontrada em servidor remoto - precisa aplicar remoto
+		log.Printf("[TROCA] Carta do ofertante veio do servidor remoto. Aplicando troca remota...")
 
-		log.Printf("[TROCA] Buscando no servidor %s a carta %s do jogador %s", servidorJogadorOferta, req.IDCartaOferecida, req.IDJogadorOferta)
+		servidorJogadorOferta := outroServidor
 
-		// Busca a carta no servidor remoto
+		// Aplica troca no servidor remoto do ofertante
+		// Remove carta oferecida e adiciona carta desejada
 		payload := map[string]interface{}{
-			"cliente_id": req.IDJogadorOferta,
-			"carta_id":   req.IDCartaOferecida,
+			"cliente_id":        req.IDJogadorOferta,
+			"carta_desejada_id": req.IDCartaOferecida, // Carta que será REMOVIDA
+			"carta_oferecida":   cartaDesejada,        // Carta que será ADICIONADA
 		}
+		log.Printf("[TROCA] Aplicando troca remota: removendo %s e adicionando %s", req.IDCartaOferecida, cartaDesejada.Nome)
 		body, _ := json.Marshal(payload)
 
-		url := fmt.Sprintf("http://%s/partida/buscar_carta", servidorJogadorOferta)
+		url := fmt.Sprintf("http://%s/partida/aplicar_troca_local", servidorJogadorOferta)
+		log.Printf("[TROCA] Chamando %s", url)
 		resp, err := s.enviarRequestComToken("POST", url, body)
 		if err != nil {
-			log.Printf("[TROCA] Erro ao buscar carta do ofertante no remoto: %v", err)
+			log.Printf("[TROCA] Erro ao aplicar troca no ofertante remoto: %v", err)
 		} else if resp != nil {
 			defer resp.Body.Close()
 			if resp.StatusCode == 200 {
 				var result struct {
-					Encontrada bool        `json:"encontrada"`
-					Carta      tipos.Carta `json:"carta"`
-				}
-				if json.NewDecoder(resp.Body).Decode(&result) == nil && result.Encontrada {
-					cartaOferta = result.Carta
-					idxOferta = 99 // Placeholder para indicar que foi encontrada remotamente
-					log.Printf("[TROCA] Carta ofertante %s encontrada no servidor remoto", cartaOferta.Nome)
+					Status     string        `json:"status"`
+					Inventario []tipos.Carta `json:"inventario"`
 				}
-			}
-		}
-
-		// Se ainda não encontrou, tenta na cópia da sala como fallback
-		if cartaOferta.ID == "" {
-			sala.Mutex.Lock()
-			for _, j := range sala.Jogadores {
-				if j.ID == req.IDJogadorOferta {
-					for i, c := range j.Inventario {
-						if c.ID == req.IDCartaOferecida {
-							cartaOferta = c
-							idxOferta = i
-							log.Printf("[TROCA] Carta ofertante %s encontrada na cópia da sala (fallback)", cartaOferta.Nome)
-							break
-						}
-					}
-					break
+				decoder := json.NewDecoder(resp.Body)
+				if err := decoder.Decode(&result); err == nil {
+					novoInventario = result.Inventario
+					log.Printf("[TROCA] Inventário atualizado recebido do ofertante remoto: %d cartas", len(novoInventario))
+					log.Printf("[TROCA] Primeira carta: %+v", result.Inventario[0])
+				} else {
+					log.Printf("[TROCA] Erro ao decodificar resposta: %v", err)
+					// Lê o body para debug
+					bodyBytes, _ := io.ReadAll(resp.Body)
+					log.Printf("[TROCA] Resposta raw: %s", string(bodyBytes))
 				}
+			} else {
+				log.Printf("[TROCA] Status code: %d", resp.StatusCode)
 			}
-			sala.Mutex.Unlock()
+			log.Printf("[TROCA] Troca aplicada no ofertante remoto com sucesso")
 		}
 	}
 
-	if cartaOferta.ID == "" {
-		s.notificarErro(req.IDJogadorOferta, "Você não possui esta carta.")
-		return
-	}
-
-	// Busca carta desejada
-	var cartaDesejada tipos.Carta
-	s.mutexClientes.RLock()
-	jogadorDesejadoLocal := s.Clientes[req.IDJogadorDesejado]
-	s.mutexClientes.RUnlock()
-
+	// Se jogador desejado está local, executa a troca reversa
 	if jogadorDesejadoLocal != nil {
-		// Jogador local - busca no inventário real
+		// Remove carta desejada e adiciona carta oferecida
 		jogadorDesejadoLocal.Mutex.Lock()
-		for _, c := range jogadorDesejadoLocal.Inventario {
+		inventario := jogadorDesejadoLocal.Inventario
+		idxDesejado := -1
+		for i, c := range inventario {
 			if c.ID == req.IDCartaDesejada {
-				cartaDesejada = c
+				idxDesejado = i
 				break
 			}
 		}
-		jogadorDesejadoLocal.Mutex.Unlock()
-		log.Printf("[TROCA] Carta desejada %s encontrada no inventário real", cartaDesejada.Nome)
-	} else {
-		// Jogador está em outro servidor - busca no servidor remoto via HTTP
-		log.Printf("[TROCA] Jogador desejado %s está remoto. Buscando carta via HTTP no servidor dele...", req.NomeJogadorDesejado)
-
-		// Determina servidor do jogador desejado
-		var servidorJogadorDesejado string
-		if sala.ServidorHost == s.MeuEndereco {
-			// Sou Host, jogador remoto está na Sombra
-			servidorJogadorDesejado = sala.ServidorSombra
-		} else {
-			// Sou Shadow, jogador remoto está no Host
-			servidorJogadorDesejado = sala.ServidorHost
+		if idxDesejado != -1 {
+			inventario = append(inventario[:idxDesejado], inventario[idxDesejado+1:]...)
+			inventario = append(inventario, cartaOferta)
+			jogadorDesejadoLocal.Inventario = inventario
 		}
+		inventarioDesejado := make([]tipos.Carta, len(inventario))
+		copy(inventarioDesejado, inventario)
+		jogadorDesejadoLocal.Mutex.Unlock()
+		log.Printf("[TROCA] Inventário real do desejado atualizado: %d cartas", len(inventario))
 
-		log.Printf("[TROCA] Buscando no servidor %s a carta %s do jogador %s", servidorJogadorDesejado, req.IDCartaDesejada, req.IDJogadorDesejado)
-
-		// Busca a carta no servidor remoto
+		// Notifica ambos
+		s.notificarSucessoTrocaComInventario(req.IDJogadorOferta, cartaOferta.Nome, cartaDesejada.Nome, novoInventario)
+		s.notificarSucessoTrocaComInventario(req.IDJogadorDesejado, cartaDesejada.Nome, cartaOferta.Nome, inventarioDesejado)
+	} else {
+		// Jogador desejado é remoto - solicita ao servidor dele aplicar a troca
+		log.Printf("[TROCA] Jogador desejado é remoto. Enviando para aplicar troca...")
 		payload := map[string]interface{}{
-			"cliente_id": req.IDJogadorDesejado,
-			"carta_id":   req.IDCartaDesejada,
+			"cliente_id":        req.IDJogadorDesejado,
+			"carta_desejada_id": req.IDCartaDesejada,
+			"carta_oferecida":   cartaOferta,
 		}
 		body, _ := json.Marshal(payload)
 
-		url := fmt.Sprintf("http://%s/partida/buscar_carta", servidorJogadorDesejado)
+		// Sou Host, remoto está na Sombra
+		servidorDestino := outroServidor
+
+		url := fmt.Sprintf("http://%s/partida/aplicar_troca_local", servidorDestino)
+		log.Printf("[TROCA] Enviando para %s", url)
 		resp, err := s.enviarRequestComToken("POST", url, body)
 		if err != nil {
-			log.Printf("[TROCA] Erro ao buscar carta no remoto: %v", err)
+			log.Printf("[TROCA] Falha ao aplicar troca no remoto %s: %v", servidorDestino, err)
 		} else if resp != nil {
 			defer resp.Body.Close()
-			if resp.StatusCode == 200 {
-				var result struct {
-					Encontrada bool        `json:"encontrada"`
-					Carta      tipos.Carta `json:"carta"`
-				}
-				if json.NewDecoder(resp.Body).Decode(&result) == nil && result.Encontrada {
-					cartaDesejada = result.Carta
-					log.Printf("[TROCA] Carta desejada %s encontrada no servidor remoto", cartaDesejada.Nome)
-				}
+			log.Printf("[TROCA] Troca aplicada com sucesso no remoto")
+		}
+
+		// Notifica ambos os jogadores (a notificação para o desejado já foi enviada pelo aplicador remoto)
+		log.Printf("[TROCA] Notificando ofertante com inventário de %d cartas", len(novoInventario))
+		s.notificarSucessoTrocaComInventario(req.IDJogadorOferta, cartaOferta.Nome, cartaDesejada.Nome, novoInventario)
+	}
+
+	// Se o ofertante é local, também atualiza o inventário real
+	s.mutexClientes.RLock()
+	clienteOfertaLocal := s.Clientes[req.IDJogadorOferta]
+	s.mutexClientes.RUnlock()
+	if clienteOfertaLocal != nil {
+		clienteOfertaLocal.Mutex.Lock()
+		clienteOfertaLocal.Inventario = novoInventario
+		clienteOfertaLocal.Mutex.Unlock()
+		log.Printf("[TROCA] Inventário real do ofertante local atualizado para %d cartas", len(novoInventario))
+	}
+
+	log.Printf("[TROCA] === FIM PROCESSAMENTO TROCA === %s trocou %s por %s com %s", req.NomeJogadorOferta, cartaOferta.Nome, cartaDesejada.Nome, req.NomeJogadorDesejado)
+	// A cópia da sala é atualizada por concluirTrocaCartas
+	resultado.Inventario = novoInventario
+	resultado.Aplicada = true
+	return resultado
+}
+
+// encaminharTrocaParaHost envia uma requisição de troca de cartas do Shadow para o Host via HTTP
+func (s *Servidor) encaminharTrocaParaHost(hostAddr, salaID string, req *protocolo.TrocarCartasReq) {
+	log.Printf("[TROCA_SHADOW] Encaminhando requisição de troca para o Host %s na sala %s", hostAddr, salaID)
+
+	// Cria a mensagem de comando para encaminhar
+	comando := protocolo.Mensagem{
+		Comando: "TROCAR_CARTAS",
+		Dados:   seguranca.MustJSON(req),
+	}
+
+	// Cria o payload para o endpoint
+	payload := map[string]interface{}{
+		"sala_id": salaID,
+		"comando": comando,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("[TROCA_SHADOW] Erro ao serializar requisição: %v", err)
+		return
+	}
+
+	url := fmt.Sprintf("http://%s/partida/encaminhar_comando", hostAddr)
+
+	// Cria cliente HTTP com timeout reduzido
+	token := seguranca.GenerateJWT(s.MeuEndereco)
+	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		log.Printf("[TROCA_SHADOW] Erro ao criar request: %v", err)
+		return
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+
+	// Timeout mais curto para evitar travamentos
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		log.Printf("[TROCA_SHADOW] Erro ao encaminhar: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		log.Printf("[TROCA_SHADOW] Troca encaminhada com sucesso ao Host")
+	} else {
+		log.Printf("[TROCA_SHADOW] Host retornou status %d", resp.StatusCode)
+	}
+}
+
+func (s *Servidor) getClienteDaSala(sala *tipos.Sala, clienteID string) *tipos.Cliente {
+	for _, jogador := range sala.Jogadores {
+		if jogador.ID == clienteID {
+			return jogador
+		}
+	}
+	return nil
+}
+
+func (s *Servidor) findCartaNoInventario(cliente *tipos.Cliente, cartaID string) (Carta, int) {
+	cliente.Mutex.Lock()
+	defer cliente.Mutex.Unlock()
+	for i, c := range cliente.Inventario {
+		if c.ID == cartaID {
+			return c, i
+		}
+	}
+	return Carta{}, -1
+}
+
+func cartasIguais(a, b []tipos.Carta) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Servidor) notificarErro(clienteID string, mensagem string) {
+	s.publicarParaCliente(clienteID, protocolo.Mensagem{
+		Comando: "ERRO",
+		Dados:   seguranca.MustJSON(protocolo.DadosErro{Mensagem: mensagem}),
+	})
+}
+
+func (s *Servidor) notificarSucessoTroca(clienteID, cartaPerdida, cartaGanha string) {
+	msg := fmt.Sprintf("Troca realizada! Você deu '%s' e recebeu '%s'.", cartaPerdida, cartaGanha)
+	resp := protocolo.TrocarCartasResp{Sucesso: true, Mensagem: msg}
+	s.publicarParaCliente(clienteID, protocolo.Mensagem{Comando: "TROCA_CONCLUIDA", Dados: seguranca.MustJSON(resp)})
+}
+
+func (s *Servidor) notificarSucessoTrocaComInventario(clienteID, cartaPerdida, cartaGanha string, inventario []tipos.Carta) {
+	// Se o jogador tem endereço blockchain, busca o inventário atualizado da blockchain
+	s.mutexClientes.RLock()
+	cliente := s.Clientes[clienteID]
+	s.mutexClientes.RUnlock()
+
+	inventarioFinal := inventario
+
+	if cliente != nil && cliente.EnderecoBlockchain != "" && s.BlockchainManager != nil {
+		log.Printf("[TROCA_BLOCKCHAIN] Buscando inventário atualizado da blockchain para jogador %s", clienteID)
+		addr := common.HexToAddress(cliente.EnderecoBlockchain)
+		cartasBlockchain, err := s.BlockchainManager.ObterInventario(addr)
+		if err == nil && len(cartasBlockchain) > 0 {
+			log.Printf("[TROCA_BLOCKCHAIN] Inventário da blockchain obtido: %d cartas", len(cartasBlockchain))
+			inventarioFinal = cartasBlockchain
+			// Atualiza também o inventário local do cliente
+			cliente.Mutex.Lock()
+			cliente.Inventario = cartasBlockchain
+			cliente.Mutex.Unlock()
+		} else {
+			if err != nil {
+				log.Printf("[TROCA_BLOCKCHAIN_AVISO] Erro ao buscar inventário da blockchain: %v", err)
+			} else {
+				log.Printf("[TROCA_BLOCKCHAIN_AVISO] Inventário da blockchain vazio")
 			}
 		}
+	}
 
-		// Se ainda não encontrou, tenta na cópia da sala como fallback
-		if cartaDesejada.ID == "" {
-			sala.Mutex.Lock()
-			for _, j := range sala.Jogadores {
-				if j.ID == req.IDJogadorDesejado {
-					log.Printf("[TROCA] Fallback: buscando carta %s no inventário de %s na cópia. Total: %d", req.IDCartaDesejada, j.Nome, len(j.Inventario))
-					for _, c := range j.Inventario {
-						if c.ID == req.IDCartaDesejada {
-							cartaDesejada = c
-							log.Printf("[TROCA] Carta desejada %s encontrada na cópia da sala", cartaDesejada.Nome)
-							break
-						}
-					}
-					break
-				}
-			}
-			sala.Mutex.Unlock()
-		}
+	if cliente != nil {
+		s.persistirJogador(cliente)
 	}
 
-	if cartaDesejada.ID == "" {
-		s.notificarErro(req.IDJogadorOferta, fmt.Sprintf("%s não possui esta carta.", req.NomeJogadorDesejado))
-		return
+	msg := fmt.Sprintf("Troca realizada! Você deu '%s' e recebeu '%s'.", cartaPerdida, cartaGanha)
+	resp := protocolo.TrocarCartasResp{
+		Sucesso:              true,
+		Mensagem:             msg,
+		InventarioAtualizado: inventarioFinal,
 	}
+	s.publicarParaCliente(clienteID, protocolo.Mensagem{Comando: "TROCA_CONCLUIDA", Dados: seguranca.MustJSON(resp)})
+}
 
-	// ========== EXECUTA TROCA NA BLOCKCHAIN ==========
-	log.Printf("[TROCA_BLOCKCHAIN] Iniciando processo de troca na blockchain...")
-	log.Printf("[TROCA_BLOCKCHAIN] Carta oferecida: ID=%s, Nome=%s", req.IDCartaOferecida, cartaOferta.Nome)
-	log.Printf("[TROCA_BLOCKCHAIN] Carta desejada: ID=%s, Nome=%s", req.IDCartaDesejada, cartaDesejada.Nome)
+// notificarInventariosAlterados é chamada pela ponte de eventos do contrato para os jogadores conectados
+// a este servidor cujos endereços aparecem na mudança. Segue a mesma política de ReconciliarInventarios:
+// jogadores em partida ficam de fora, e o inventário só é substituído pelo da blockchain (com
+// INVENTARIO_ATUALIZADO para o jogador) quando ela é a autoridade; senão a divergência só é registrada.
+func (s *Servidor) notificarInventariosAlterados(mudanca blockchain.MudancaInventario) {
+	alterados := make(map[common.Address]bool, len(mudanca.Enderecos))
+	for _, endereco := range mudanca.Enderecos {
+		alterados[endereco] = true
+	}
 
-	// Verifica se ambos os jogadores têm endereços blockchain
 	s.mutexClientes.RLock()
-	clienteOferta := s.Clientes[req.IDJogadorOferta]
-	clienteDesejado := s.Clientes[req.IDJogadorDesejado]
-	s.mutexClientes.RUnlock()
-
-	// Se não encontrou no mapa global, busca na sala
-	if clienteOferta == nil {
-		sala.Mutex.Lock()
-		for _, j := range sala.Jogadores {
-			if j.ID == req.IDJogadorOferta {
-				// Busca no mapa de clientes usando o ID da sala
-				s.mutexClientes.RLock()
-				clienteOferta = s.Clientes[j.ID]
-				s.mutexClientes.RUnlock()
-				break
-			}
+	afetados := make([]*tipos.Cliente, 0)
+	for _, cliente := range s.Clientes {
+		if common.IsHexAddress(cliente.EnderecoBlockchain) && alterados[common.HexToAddress(cliente.EnderecoBlockchain)] {
+			afetados = append(afetados, cliente)
 		}
-		sala.Mutex.Unlock()
 	}
+	s.mutexClientes.RUnlock()
 
-	if clienteDesejado == nil {
-		sala.Mutex.Lock()
-		for _, j := range sala.Jogadores {
-			if j.ID == req.IDJogadorDesejado {
-				s.mutexClientes.RLock()
-				clienteDesejado = s.Clientes[j.ID]
-				s.mutexClientes.RUnlock()
-				break
-			}
+	for _, cliente := range afetados {
+		cliente.Mutex.Lock()
+		endereco := cliente.EnderecoBlockchain
+		sala := cliente.Sala
+		servidor := append([]tipos.Carta(nil), cliente.Inventario...)
+		cliente.Mutex.Unlock()
+		if salaEmAndamento(sala) {
+			log.Printf("[PONTE:%s] %s está em partida; a mudança do bloco %d fica para a reconciliação", s.ServerID, cliente.Nome, mudanca.Bloco)
+			continue
 		}
-		sala.Mutex.Unlock()
-	}
-
-	// Executa troca na blockchain se ambos têm endereços e o blockchain manager está disponível
-	if s.BlockchainManager != nil && clienteOferta != nil && clienteDesejado != nil {
-		enderecoOferta := clienteOferta.EnderecoBlockchain
-		enderecoDesejado := clienteDesejado.EnderecoBlockchain
 
-		log.Printf("[TROCA_BLOCKCHAIN] Endereço jogador ofertante (%s): %s", req.NomeJogadorOferta, enderecoOferta)
-		log.Printf("[TROCA_BLOCKCHAIN] Endereço jogador desejado (%s): %s", req.NomeJogadorDesejado, enderecoDesejado)
+		cadeia, err := s.BlockchainManager.ObterInventario(common.HexToAddress(endereco))
+		if err != nil {
+			log.Printf("[PONTE_ERRO:%s] Falha ao recarregar inventário de %s: %v", s.ServerID, cliente.Nome, err)
+			continue
+		}
 
-		if enderecoOferta != "" && enderecoDesejado != "" {
-			// Converte IDs das cartas para tokenIds (big.Int)
-			// O ID da carta na blockchain é o tokenId convertido para string
-			// Verifica se os IDs são números válidos (cartas da blockchain têm IDs numéricos)
-			tokenIdOferta, err1 := strconv.ParseInt(cartaOferta.ID, 10, 64)
-			tokenIdDesejada, err2 := strconv.ParseInt(cartaDesejada.ID, 10, 64)
+		// Trocas coordenadas pelo servidor já atualizaram o inventário; só trata o que é novo
+		if cartasIguais(servidor, cadeia) {
+			continue
+		}
+		if s.AutoridadeInventario != AUTORIDADE_CADEIA {
+			soNoServidor, soNaCadeia := diferencaCartas(servidor, cadeia)
+			log.Printf("[PONTE:%s] Inventário de %s diverge da blockchain no bloco %d (%v): %d só no servidor, %d só na blockchain. Mantido (autoridade: %s)",
+				s.ServerID, cliente.Nome, mudanca.Bloco, mudanca.Eventos, len(soNoServidor), len(soNaCadeia), s.AutoridadeInventario)
+			continue
+		}
+		log.Printf("[PONTE:%s] Inventário de %s mudou no bloco %d (%v): %d cartas", s.ServerID, cliente.Nome, mudanca.Bloco, mudanca.Eventos, len(cadeia))
+		s.corrigirInventario(cliente, servidor, cadeia, mudanca.Eventos, mudanca.Bloco)
+	}
+}
 
-			log.Printf("[TROCA_BLOCKCHAIN] Tentando converter IDs: oferta='%s' (err=%v), desejada='%s' (err=%v)",
-				cartaOferta.ID, err1, cartaDesejada.ID, err2)
+// reconciliarInventariosPeriodicamente compara, a cada RECONCILIACAO_INTERVALO, o inventário em
+// memória dos jogadores com carteira com o da blockchain. A ponte de eventos cobre as mudanças feitas
+// no contrato; esta passada pega o que escapou dela (eventos perdidos, pacotes do store, trocas na partida).
+func (s *Servidor) reconciliarInventariosPeriodicamente() {
+	ticker := time.NewTicker(RECONCILIACAO_INTERVALO)
+	defer ticker.Stop()
+	for range ticker.C {
+		relatorio, err := s.ReconciliarInventarios()
+		if err != nil {
+			log.Printf("[RECONCILIACAO_ERRO:%s] %v", s.ServerID, err)
+			continue
+		}
+		if len(relatorio.Divergencias) > 0 {
+			log.Printf("[RECONCILIACAO:%s] %d de %d jogadores divergem da blockchain (autoridade: %s)",
+				s.ServerID, len(relatorio.Divergencias), relatorio.Verificados, relatorio.Autoridade)
+		}
+	}
+}
 
-			if err1 == nil && err2 == nil && tokenIdOferta >= 0 && tokenIdDesejada >= 0 {
-				log.Printf("[TROCA_BLOCKCHAIN] TokenIds: oferta=%d, desejada=%d", tokenIdOferta, tokenIdDesejada)
+// ReconciliarInventarios executa uma passada da reconciliação e a guarda como o último relatório.
+// Jogadores em partida ficam de fora: as cartas jogadas saem do inventário em memória antes de
+// qualquer registro na blockchain.
+func (s *Servidor) ReconciliarInventarios() (tipos.RelatorioReconciliacao, error) {
+	if s.BlockchainManager == nil {
+		return tipos.RelatorioReconciliacao{}, fmt.Errorf("blockchain não configurado neste servidor")
+	}
+	s.passadaReconciliacao.Lock()
+	defer s.passadaReconciliacao.Unlock()
 
-				// Converte endereços para common.Address
-				addrOferta := common.HexToAddress(enderecoOferta)
-				addrDesejado := common.HexToAddress(enderecoDesejado)
+	s.mutexClientes.RLock()
+	clientes := make([]*tipos.Cliente, 0, len(s.Clientes))
+	for _, cliente := range s.Clientes {
+		clientes = append(clientes, cliente)
+	}
+	s.mutexClientes.RUnlock()
 
-				// CORREÇÃO: Usa RegistrarTrocaAdmin ao invés de CriarPropostaTroca + AceitarPropostaTroca
-				// A função RegistrarTrocaAdmin é executada pelo servidor (admin/owner) e registra
-				// os endereços CORRETOS dos jogadores (ofertante e desejado) na transação
-				log.Printf("[TROCA_BLOCKCHAIN] Registrando troca via RegistrarTrocaAdmin...")
-				log.Printf("[TROCA_BLOCKCHAIN] Jogador1 (ofertante): %s (%s)", req.NomeJogadorOferta, addrOferta.Hex())
-				log.Printf("[TROCA_BLOCKCHAIN] Jogador2 (desejado): %s (%s)", req.NomeJogadorDesejado, addrDesejado.Hex())
-				log.Printf("[TROCA_BLOCKCHAIN] Carta1 (oferecida): ID=%d, Nome=%s", tokenIdOferta, cartaOferta.Nome)
-				log.Printf("[TROCA_BLOCKCHAIN] Carta2 (desejada): ID=%d, Nome=%s", tokenIdDesejada, cartaDesejada.Nome)
+	relatorio := tipos.RelatorioReconciliacao{
+		Autoridade:   s.AutoridadeInventario,
+		Divergencias: make([]tipos.DivergenciaInventario, 0),
+	}
+	for _, cliente := range clientes {
+		cliente.Mutex.Lock()
+		endereco := cliente.EnderecoBlockchain
+		sala := cliente.Sala
+		servidor := append([]tipos.Carta(nil), cliente.Inventario...)
+		cliente.Mutex.Unlock()
 
-				propostaID, err := s.BlockchainManager.RegistrarTrocaAdmin(
-					addrOferta,                  // Endereço do jogador ofertante (jogador1)
-					addrDesejado,                // Endereço do jogador desejado (jogador2)
-					big.NewInt(tokenIdOferta),   // ID da carta oferecida
-					big.NewInt(tokenIdDesejada), // ID da carta desejada
-				)
+		if !common.IsHexAddress(endereco) {
+			continue
+		}
+		if salaEmAndamento(sala) {
+			relatorio.EmPartida++
+			continue
+		}
 
-				if err != nil {
-					log.Printf("[TROCA_BLOCKCHAIN_ERRO] Falha ao registrar troca: %v", err)
-					// Continua com a troca local mesmo se a blockchain falhar
-				} else {
-					log.Printf("[TROCA_BLOCKCHAIN] ✓ Troca registrada com sucesso na blockchain!")
-					log.Printf("[TROCA_BLOCKCHAIN] ID da proposta/troca: %s", propostaID.String())
-					log.Printf("[TROCA_BLOCKCHAIN] Os endereços registrados são:")
-					log.Printf("[TROCA_BLOCKCHAIN]   - Jogador ofertante: %s", addrOferta.Hex())
-					log.Printf("[TROCA_BLOCKCHAIN]   - Jogador desejado: %s", addrDesejado.Hex())
-				}
-			} else {
-				log.Printf("[TROCA_BLOCKCHAIN_AVISO] Não foi possível converter IDs para tokenIds: err1=%v, err2=%v", err1, err2)
-				log.Printf("[TROCA_BLOCKCHAIN_AVISO] ID carta oferta: %s, ID carta desejada: %s", cartaOferta.ID, cartaDesejada.ID)
-			}
-		} else {
-			log.Printf("[TROCA_BLOCKCHAIN_AVISO] Um ou ambos os jogadores não têm endereço blockchain configurado")
-			log.Printf("[TROCA_BLOCKCHAIN_AVISO] Ofertante tem endereço: %v, Desejado tem endereço: %v", enderecoOferta != "", enderecoDesejado != "")
+		cadeia, err := s.BlockchainManager.ObterInventario(common.HexToAddress(endereco))
+		if err != nil {
+			log.Printf("[RECONCILIACAO_ERRO:%s] Falha ao ler inventário de %s: %v", s.ServerID, cliente.Nome, err)
+			relatorio.Falhas++
+			continue
 		}
-	} else {
-		if s.BlockchainManager == nil {
-			log.Printf("[TROCA_BLOCKCHAIN_AVISO] BlockchainManager não está disponível")
+		relatorio.Verificados++
+
+		soNoServidor, soNaCadeia := diferencaCartas(servidor, cadeia)
+		if len(soNoServidor) == 0 && len(soNaCadeia) == 0 {
+			continue
 		}
-		if clienteOferta == nil {
-			log.Printf("[TROCA_BLOCKCHAIN_AVISO] Cliente ofertante não encontrado")
+		divergencia := tipos.DivergenciaInventario{
+			ClienteID:    cliente.ID,
+			Nome:         cliente.Nome,
+			Endereco:     endereco,
+			SoNoServidor: soNoServidor,
+			SoNaCadeia:   soNaCadeia,
 		}
-		if clienteDesejado == nil {
-			log.Printf("[TROCA_BLOCKCHAIN_AVISO] Cliente desejado não encontrado")
+		if s.AutoridadeInventario == AUTORIDADE_CADEIA {
+			divergencia.Corrigida = s.corrigirInventario(cliente, servidor, cadeia, []string{"Reconciliacao"}, 0)
 		}
+		relatorio.Divergencias = append(relatorio.Divergencias, divergencia)
 	}
-	// ========== FIM TROCA NA BLOCKCHAIN ==========
-
-	// EXECUTA A TROCA LOCAL - Remove carta do ofertante e adiciona carta desejada
-	var novoInventario []tipos.Carta
 
-	if jogadorReal != nil && idxOferta != 99 {
-		// Atualiza inventário real do jogador local
-		jogadorReal.Mutex.Lock()
-		inventario := jogadorReal.Inventario
-		inventario = append(inventario[:idxOferta], inventario[idxOferta+1:]...)
-		inventario = append(inventario, cartaDesejada)
-		jogadorReal.Inventario = inventario
-		novoInventario = make([]tipos.Carta, len(inventario))
-		copy(novoInventario, inventario)
-		jogadorReal.Mutex.Unlock()
-		log.Printf("[TROCA] Inventário real do ofertante atualizado: %d cartas", len(inventario))
-	} else if idxOferta == 99 {
-		// Carta foi encontrada em servidor remoto - precisa aplicar remoto
-		log.Printf("[TROCA] Carta do ofertante veio do servidor remoto. Aplicando troca remota...")
+	relatorio.Executada = time.Now().Unix()
+	s.mutexReconciliacao.Lock()
+	s.ultimaReconciliacao = &relatorio
+	s.mutexReconciliacao.Unlock()
+	return relatorio, nil
+}
 
-		// Determina servidor do jogador ofertante
-		var servidorJogadorOferta string
-		if sala.ServidorHost == s.MeuEndereco {
-			servidorJogadorOferta = sala.ServidorSombra
-		} else {
-			servidorJogadorOferta = sala.ServidorHost
-		}
+// UltimaReconciliacao devolve o relatório da última passada; o bool é falso se nenhuma foi executada
+func (s *Servidor) UltimaReconciliacao() (tipos.RelatorioReconciliacao, bool) {
+	s.mutexReconciliacao.Lock()
+	defer s.mutexReconciliacao.Unlock()
+	if s.ultimaReconciliacao == nil {
+		return tipos.RelatorioReconciliacao{}, false
+	}
+	return *s.ultimaReconciliacao, true
+}
 
-		// Aplica troca no servidor remoto do ofertante
-		// Remove carta oferecida e adiciona carta desejada
-		payload := map[string]interface{}{
-			"cliente_id":        req.IDJogadorOferta,
-			"carta_desejada_id": req.IDCartaOferecida, // Carta que será REMOVIDA
-			"carta_oferecida":   cartaDesejada,        // Carta que será ADICIONADA
-		}
-		log.Printf("[TROCA] Aplicando troca remota: removendo %s e adicionando %s", req.IDCartaOferecida, cartaDesejada.Nome)
-		body, _ := json.Marshal(payload)
+// salaEmAndamento indica se a sala do jogador ainda não terminou. As cartas jogadas saem do inventário
+// em memória antes de qualquer registro na blockchain, então ele não é comparado durante a partida.
+func salaEmAndamento(sala *tipos.Sala) bool {
+	if sala == nil {
+		return false
+	}
+	return sala.Retrato().Estado != "FINALIZADO"
+}
 
-		url := fmt.Sprintf("http://%s/partida/aplicar_troca_local", servidorJogadorOferta)
-		log.Printf("[TROCA] Chamando %s", url)
-		resp, err := s.enviarRequestComToken("POST", url, body)
-		if err != nil {
-			log.Printf("[TROCA] Erro ao aplicar troca no ofertante remoto: %v", err)
-		} else if resp != nil {
-			defer resp.Body.Close()
-			if resp.StatusCode == 200 {
-				var result struct {
-					Status     string        `json:"status"`
-					Inventario []tipos.Carta `json:"inventario"`
-				}
-				decoder := json.NewDecoder(resp.Body)
-				if err := decoder.Decode(&result); err == nil {
-					novoInventario = result.Inventario
-					log.Printf("[TROCA] Inventário atualizado recebido do ofertante remoto: %d cartas", len(novoInventario))
-					log.Printf("[TROCA] Primeira carta: %+v", result.Inventario[0])
-				} else {
-					log.Printf("[TROCA] Erro ao decodificar resposta: %v", err)
-					// Lê o body para debug
-					bodyBytes, _ := io.ReadAll(resp.Body)
-					log.Printf("[TROCA] Resposta raw: %s", string(bodyBytes))
-				}
-			} else {
-				log.Printf("[TROCA] Status code: %d", resp.StatusCode)
-			}
-			log.Printf("[TROCA] Troca aplicada no ofertante remoto com sucesso")
-		}
+// corrigirInventario substitui o inventário do servidor pelo da blockchain, desde que ele não tenha
+// mudado desde a leitura usada na comparação (senão a próxima passada decide). eventos e bloco vão
+// no INVENTARIO_ATUALIZADO enviado ao jogador.
+func (s *Servidor) corrigirInventario(cliente *tipos.Cliente, lido, cadeia []tipos.Carta, eventos []string, bloco uint64) bool {
+	cliente.Mutex.Lock()
+	if !cartasIguais(cliente.Inventario, lido) {
+		cliente.Mutex.Unlock()
+		return false
 	}
+	cliente.Inventario = cadeia
+	cliente.Mutex.Unlock()
 
-	// Se jogador desejado está local, executa a troca reversa
-	if jogadorDesejadoLocal != nil {
-		// Remove carta desejada e adiciona carta oferecida
-		jogadorDesejadoLocal.Mutex.Lock()
-		inventario := jogadorDesejadoLocal.Inventario
-		idxDesejado := -1
-		for i, c := range inventario {
-			if c.ID == req.IDCartaDesejada {
-				idxDesejado = i
-				break
-			}
+	log.Printf("[RECONCILIACAO:%s] Inventário de %s substituído pelo da blockchain: %d -> %d cartas", s.ServerID, cliente.Nome, len(lido), len(cadeia))
+	s.persistirJogador(cliente)
+	s.publicarParaCliente(cliente.ID, protocolo.Mensagem{
+		Comando: "INVENTARIO_ATUALIZADO",
+		Dados: seguranca.MustJSON(protocolo.DadosInventarioAtualizado{
+			Cartas:  cadeia,
+			Eventos: eventos,
+			Bloco:   bloco,
+		}),
+	})
+	return true
+}
+
+// diferencaCartas separa as cartas que só existem em a das que só existem em b. Cartas com o mesmo ID e
+// atributos diferentes contam como ausentes dos dois lados.
+func diferencaCartas(a, b []tipos.Carta) (soEmA, soEmB []tipos.Carta) {
+	soEmA, soEmB = make([]tipos.Carta, 0), make([]tipos.Carta, 0)
+	emA := make(map[tipos.Carta]int, len(a))
+	for _, carta := range a {
+		emA[carta]++
+	}
+	for _, carta := range b {
+		if emA[carta] > 0 {
+			emA[carta]--
+			continue
 		}
-		if idxDesejado != -1 {
-			inventario = append(inventario[:idxDesejado], inventario[idxDesejado+1:]...)
-			inventario = append(inventario, cartaOferta)
-			jogadorDesejadoLocal.Inventario = inventario
+		soEmB = append(soEmB, carta)
+	}
+	for _, carta := range a {
+		if emA[carta] > 0 {
+			emA[carta]--
+			soEmA = append(soEmA, carta)
 		}
-		inventarioDesejado := make([]tipos.Carta, len(inventario))
-		copy(inventarioDesejado, inventario)
-		jogadorDesejadoLocal.Mutex.Unlock()
-		log.Printf("[TROCA] Inventário real do desejado atualizado: %d cartas", len(inventario))
+	}
+	return soEmA, soEmB
+}
 
-		// Notifica ambos
-		s.notificarSucessoTrocaComInventario(req.IDJogadorOferta, cartaOferta.Nome, cartaDesejada.Nome, novoInventario)
-		s.notificarSucessoTrocaComInventario(req.IDJogadorDesejado, cartaDesejada.Nome, cartaOferta.Nome, inventarioDesejado)
-	} else {
-		// Jogador desejado é remoto - solicita ao servidor dele aplicar a troca
-		log.Printf("[TROCA] Jogador desejado é remoto. Enviando para aplicar troca...")
-		payload := map[string]interface{}{
-			"cliente_id":        req.IDJogadorDesejado,
-			"carta_desejada_id": req.IDCartaDesejada,
-			"carta_oferecida":   cartaOferta,
-		}
-		body, _ := json.Marshal(payload)
+func (s *Servidor) notificarJogadorRemoto(servidor string, clienteID string, msg protocolo.Mensagem) {
+	log.Printf("[NOTIFICACAO-REMOTA] Notificando cliente %s no servidor %s", clienteID, servidor)
+	reqBody, _ := json.Marshal(map[string]interface{}{
+		"cliente_id": clienteID,
+		"mensagem":   msg,
+	})
+
+	resp, err := s.enviarRequestComToken("POST", fmt.Sprintf("http://%s/partida/notificar_jogador", servidor), reqBody)
+	if err != nil {
+		log.Printf("[NOTIFICACAO-REMOTA] Erro ao notificar cliente %s no servidor %s: %v", clienteID, servidor, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("[NOTIFICACAO-REMOTA] Servidor remoto %s retornou status %d", servidor, resp.StatusCode)
+	}
+}
+
+// persistirJogador grava o inventário atual do cliente no repositório de jogadores.
+func (s *Servidor) persistirJogador(cliente *tipos.Cliente) {
+	if s.Jogadores == nil || cliente == nil {
+		return
+	}
 
-		// Determina servidor destino - lógica corrigida:
-		// Se sou Host, jogador remoto está na Sombra
-		// Se sou Shadow, jogador remoto está no Host
-		servidorDestino := sala.ServidorSombra
-		if s.MeuEndereco == sala.ServidorHost {
-			// Sou Host, remoto está na Sombra
-			servidorDestino = sala.ServidorSombra
-		} else {
-			// Sou Shadow, remoto está no Host
-			servidorDestino = sala.ServidorHost
-		}
+	cliente.Mutex.Lock()
+	registro := persistencia.RegistroJogador{
+		ID:                 cliente.ID,
+		Nome:               cliente.Nome,
+		Inventario:         append([]protocolo.Carta(nil), cliente.Inventario...),
+		EnderecoBlockchain: cliente.EnderecoBlockchain,
+	}
+	cliente.Mutex.Unlock()
 
-		url := fmt.Sprintf("http://%s/partida/aplicar_troca_local", servidorDestino)
-		log.Printf("[TROCA] Enviando para %s", url)
-		resp, err := s.enviarRequestComToken("POST", url, body)
-		if err != nil {
-			log.Printf("[TROCA] Falha ao aplicar troca no remoto %s: %v", servidorDestino, err)
-		} else if resp != nil {
-			defer resp.Body.Close()
-			log.Printf("[TROCA] Troca aplicada com sucesso no remoto")
-		}
+	if err := s.Jogadores.Salvar(registro); err != nil {
+		log.Printf("[PERSISTENCIA_ERRO] Falha ao salvar jogador %s: %v", registro.Nome, err)
+	}
+}
 
-		// Notifica ambos os jogadores (a notificação para o desejado já foi enviada pelo aplicador remoto)
-		log.Printf("[TROCA] Notificando ofertante com inventário de %d cartas", len(novoInventario))
-		s.notificarSucessoTrocaComInventario(req.IDJogadorOferta, cartaOferta.Nome, cartaDesejada.Nome, novoInventario)
+// agendarPersistencia entrega uma gravação em disco a executarPersistencia, para a goroutine da sala
+// não esperar pelo fsync. As gravações são feitas na ordem em que chegam; com a fila cheia (ou sem
+// a goroutine de persistência, fora de Run), a gravação é feita na hora.
+func (s *Servidor) agendarPersistencia(tarefa func()) {
+	select {
+	case s.filaPersistencia <- tarefa:
+	default:
+		tarefa()
 	}
+}
 
-	// Atualiza a cópia da sala
-	sala.Mutex.Lock()
-	for i := range sala.Jogadores {
-		if sala.Jogadores[i].ID == req.IDJogadorOferta {
-			sala.Jogadores[i].Inventario = novoInventario
-			log.Printf("[TROCA] Cópia da sala atualizada para %s", req.NomeJogadorOferta)
-			break
-		}
+// executarPersistencia faz, em ordem, as gravações agendadas pelas goroutines das salas.
+func (s *Servidor) executarPersistencia() {
+	for tarefa := range s.filaPersistencia {
+		tarefa()
 	}
-	sala.Mutex.Unlock()
+}
 
-	// Se o ofertante é local, também atualiza o inventário real
-	s.mutexClientes.RLock()
-	clienteOfertaLocal := s.Clientes[req.IDJogadorOferta]
-	s.mutexClientes.RUnlock()
-	if clienteOfertaLocal != nil {
-		clienteOfertaLocal.Mutex.Lock()
-		clienteOfertaLocal.Inventario = novoInventario
-		clienteOfertaLocal.Mutex.Unlock()
-		log.Printf("[TROCA] Inventário real do ofertante local atualizado para %d cartas", len(novoInventario))
+// compactarJogadoresPeriodicamente remove do arquivo de jogadores, a cada INTERVALO_COMPACTACAO,
+// as linhas substituídas por gravações mais novas. A compactação entra na fila de persistência,
+// depois das gravações já agendadas.
+func (s *Servidor) compactarJogadoresPeriodicamente() {
+	ticker := time.NewTicker(INTERVALO_COMPACTACAO)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.agendarPersistencia(func() {
+			if err := s.Jogadores.Compactar(); err != nil {
+				log.Printf("[PERSISTENCIA_ERRO] Falha ao compactar jogadores: %v", err)
+			}
+		})
 	}
+}
 
-	log.Printf("[TROCA] === FIM PROCESSAMENTO TROCA === %s trocou %s por %s com %s", req.NomeJogadorOferta, cartaOferta.Nome, cartaDesejada.Nome, req.NomeJogadorDesejado)
+func (s *Servidor) getClienteLocal(clienteID string) *tipos.Cliente {
+	s.mutexClientes.RLock()
+	defer s.mutexClientes.RUnlock()
+	// Verifica se o cliente está conectado a este servidor
+	cliente, ok := s.Clientes[clienteID]
+	if ok {
+		return cliente
+	}
+	return nil
 }
 
-// encaminharTrocaParaHost envia uma requisição de troca de cartas do Shadow para o Host via HTTP
-func (s *Servidor) encaminharTrocaParaHost(hostAddr, salaID string, req *protocolo.TrocarCartasReq) {
-	log.Printf("[TROCA_SHADOW] Encaminhando requisição de troca para o Host %s na sala %s", hostAddr, salaID)
+func (s *Servidor) criarEstadoDaSala(sala *tipos.Sala) *tipos.EstadoPartida {
+	// Chamada pela goroutine da sala ou, fora dela, com sala.Retrato()
 
-	// Cria a mensagem de comando para encaminhar
-	comando := protocolo.Mensagem{
-		Comando: "TROCAR_CARTAS",
-		Dados:   seguranca.MustJSON(req),
+	// Cria contagem de cartas atualizada
+	contagemCartas := make(map[string]int)
+	jogadoresEstado := make([]tipos.JogadorEstado, 0, len(sala.Jogadores))
+
+	for _, j := range sala.Jogadores {
+		j.Mutex.Lock()
+		contagemCartas[j.Nome] = len(j.Inventario)
+		// Adiciona jogador ao estado com seu inventário
+		invCopy := make([]tipos.Carta, len(j.Inventario))
+		copy(invCopy, j.Inventario)
+		jogadoresEstado = append(jogadoresEstado, tipos.JogadorEstado{
+			ID:         j.ID,
+			Inventario: invCopy,
+		})
+		j.Mutex.Unlock()
 	}
 
-	// Cria o payload para o endpoint
-	payload := map[string]interface{}{
-		"sala_id": salaID,
-		"comando": comando,
+	// Cria estado completo da partida
+	return &tipos.EstadoPartida{
+		SalaID:          sala.ID,
+		Estado:          sala.Estado,
+		TurnoDe:         sala.TurnoDe,
+		CartasNaMesa:    sala.CartasNaMesa,
+		PontosRodada:    sala.PontosRodada,
+		PontosPartida:   sala.PontosPartida,
+		NumeroRodada:    sala.NumeroRodada,
+		Prontos:         sala.Prontos,
+		EventSeq:        sala.EventSeq,
+		EventLog:        sala.EventLog,
+		Jogadores:       jogadoresEstado,
+		Regras:          sala.Regras,
+		Formato:         sala.Formato,
+		JogadasNaRodada: sala.JogadasNaRodada,
+		PrazoTurno:      prazoTurnoUnixMilli(sala),
 	}
+}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		log.Printf("[TROCA_SHADOW] Erro ao serializar requisição: %v", err)
-		return
+func (s *Servidor) notificarErroPartida(clienteID, mensagem, salaID string) {
+	dados := protocolo.DadosErro{Mensagem: mensagem}
+	msg := protocolo.Mensagem{
+		Comando: "ERRO_JOGADA",
+		Dados:   seguranca.MustJSON(dados),
 	}
+	// O erro é publicado no tópico de eventos da partida para que ambos os jogadores possam vê-lo, se necessário,
+	// ou apenas para o cliente específico. Optarei por notificar apenas o cliente que cometeu o erro.
+	s.publicarParaCliente(clienteID, msg)
+}
 
-	url := fmt.Sprintf("http://%s/partida/encaminhar_comando", hostAddr)
+// ==================== UTILITÁRIOS ====================
 
-	// Cria cliente HTTP com timeout reduzido
-	token := seguranca.GenerateJWT(s.MeuEndereco)
-	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		log.Printf("[TROCA_SHADOW] Erro ao criar request: %v", err)
-		return
+func mustJSON(v interface{}) []byte {
+	b, _ := json.Marshal(v)
+	return b
+}
+
+// CORREÇÃO: Função auxiliar para mudança atômica de turno
+func (s *Servidor) mudarTurnoAtomicamente(sala *tipos.Sala, novoJogadorID string) {
+	// Encontra o nome do novo jogador
+	var novoJogadorNome string
+	for _, j := range sala.Jogadores {
+		if j.ID == novoJogadorID {
+			novoJogadorNome = j.Nome
+			break
+		}
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+token)
 
-	// Timeout mais curto para evitar travamentos
-	httpClient := &http.Client{Timeout: 5 * time.Second}
+	// Atualiza o turno
+	sala.TurnoDe = novoJogadorID
+	s.reiniciarRelogioTurno(sala)
+	log.Printf("[TURNO_ATOMICO:%s] Turno alterado para: %s (%s)", sala.ID, novoJogadorNome, novoJogadorID)
 
-	resp, err := httpClient.Do(httpReq)
-	if err != nil {
-		log.Printf("[TROCA_SHADOW] Erro ao encaminhar: %v", err)
-		return
+	// CORREÇÃO: Notificação deve ser feita FORA do lock da sala para evitar deadlock
+	// A notificação será feita pela função chamadora após liberar o lock
+}
+
+// ==================== GOROUTINE DA SALA ====================
+
+// registrarSala adiciona a sala a s.Salas e inicia a goroutine dona dela.
+func (s *Servidor) registrarSala(sala *tipos.Sala) {
+	sala.PublicarRetrato()
+	s.mutexSalas.Lock()
+	s.Salas[sala.ID] = sala
+	s.mutexSalas.Unlock()
+
+	s.mutexComandos.Lock()
+	defer s.mutexComandos.Unlock()
+	if _, ok := s.ComandosPartida[sala.ID]; !ok {
+		fila := make(chan protocolo.Comando, FILA_COMANDOS_SALA)
+		s.ComandosPartida[sala.ID] = fila
+		go s.executarSala(sala, fila)
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode == http.StatusOK {
-		log.Printf("[TROCA_SHADOW] Troca encaminhada com sucesso ao Host")
-	} else {
-		log.Printf("[TROCA_SHADOW] Host retornou status %d", resp.StatusCode)
+// enviarComandoSala coloca um comando na fila da sala sem esperar pelo processamento. Nunca
+// bloqueia: com a fila cheia ou a sala já encerrada, o comando é recusado com erro. O envio é
+// feito com mutexComandos ativo, então não acontece depois de encerrarFilaSala fechar a fila.
+func (s *Servidor) enviarComandoSala(sala *tipos.Sala, comando protocolo.Comando) error {
+	s.mutexComandos.Lock()
+	defer s.mutexComandos.Unlock()
+	fila, ok := s.ComandosPartida[sala.ID]
+	if !ok {
+		return fmt.Errorf("a partida %s já terminou", sala.ID)
+	}
+	select {
+	case fila <- comando:
+		return nil
+	default:
+		return fmt.Errorf("a partida %s está sobrecarregada (%d comandos na fila)", sala.ID, len(fila))
 	}
 }
 
-func (s *Servidor) getClienteDaSala(sala *tipos.Sala, clienteID string) *tipos.Cliente {
-	sala.Mutex.Lock()
-	defer sala.Mutex.Unlock()
-	for _, jogador := range sala.Jogadores {
-		if jogador.ID == clienteID {
-			return jogador
-		}
+// encerrarFilaSala retira a fila da sala de ComandosPartida e a fecha. Só é chamada pela goroutine
+// da sala (partida finalizada ou sala ociosa), que ainda processa os comandos que já estavam na
+// fila antes de terminar.
+func (s *Servidor) encerrarFilaSala(sala *tipos.Sala, fila chan protocolo.Comando) {
+	s.mutexComandos.Lock()
+	defer s.mutexComandos.Unlock()
+	if s.ComandosPartida[sala.ID] == fila {
+		delete(s.ComandosPartida, sala.ID)
+		close(fila)
 	}
-	return nil
 }
 
-func (s *Servidor) findCartaNoInventario(cliente *tipos.Cliente, cartaID string) (Carta, int) {
-	cliente.Mutex.Lock()
-	defer cliente.Mutex.Unlock()
-	for i, c := range cliente.Inventario {
-		if c.ID == cartaID {
-			return c, i
-		}
+// executarNaSala envia um comando à sala e espera pelo resultado. Não pode ser chamada pela
+// própria goroutine da sala, que estaria esperando por si mesma.
+func (s *Servidor) executarNaSala(sala *tipos.Sala, comando protocolo.Comando) (interface{}, error) {
+	comando.Resposta = make(chan interface{}, 1)
+	if err := s.enviarComandoSala(sala, comando); err != nil {
+		return nil, err
+	}
+	select {
+	case resultado := <-comando.Resposta:
+		return resultado, nil
+	case <-time.After(RESPOSTA_SALA_TIMEOUT):
+		return nil, fmt.Errorf("sala %s não respondeu ao comando %s em %v", sala.ID, comando.Tipo, RESPOSTA_SALA_TIMEOUT)
 	}
-	return Carta{}, -1
 }
 
-func cartasIguais(a, b []tipos.Carta) bool {
-	if len(a) != len(b) {
-		return false
+// executarSala é a goroutine dona da sala: consome ComandosPartida[sala.ID] em ordem e é a única
+// que altera o estado da partida. Entradas de jogadores (MQTT), de outros servidores (API) e dos
+// monitores (relógio de turno, presença) chegam todas por essa fila. Operações lentas (HTTP entre
+// servidores, blockchain, log replicado) rodam em outras goroutines, que devolvem o resultado como
+// um novo comando. Depois de cada comando o estado é publicado em sala.Retrato para as leituras das
+// outras goroutines. Quando a partida chega a FINALIZADO, ou a sala passa SALA_OCIOSA_TIMEOUT sem
+// nenhum comando (abandonada antes de começar, servidores que sumiram), a fila é fechada e a
+// goroutine termina.
+func (s *Servidor) executarSala(sala *tipos.Sala, fila chan protocolo.Comando) {
+	log.Printf("[SALA:%s] Goroutine da sala iniciada", sala.ID)
+	encerrada := false
+	ociosa := time.NewTimer(SALA_OCIOSA_TIMEOUT)
+	defer ociosa.Stop()
+	for {
+		select {
+		case comando, ok := <-fila:
+			if !ok {
+				log.Printf("[SALA:%s] Goroutine da sala encerrada", sala.ID)
+				return
+			}
+			resultado := s.processarComandoSala(sala, comando)
+			sala.PublicarRetrato()
+			if comando.Resposta != nil {
+				comando.Resposta <- resultado
+			}
+			ociosa.Reset(SALA_OCIOSA_TIMEOUT)
+		case <-ociosa.C:
+			if !encerrada {
+				s.encerrarSalaOciosa(sala)
+				sala.PublicarRetrato()
+			}
+		}
+		if !encerrada && sala.Estado == "FINALIZADO" {
+			encerrada = true
+			s.encerrarFilaSala(sala, fila)
+		}
 	}
-	for i := range a {
-		if a[i].ID != b[i].ID {
+}
+
+// encerrarSalaOciosa finaliza, sem resultado, uma sala que ficou SALA_OCIOSA_TIMEOUT sem comandos.
+func (s *Servidor) encerrarSalaOciosa(sala *tipos.Sala) {
+	log.Printf("[SALA:%s] Sem comandos há %v (estado %s). Encerrando a sala.", sala.ID, SALA_OCIOSA_TIMEOUT, sala.Estado)
+	sala.Estado = "FINALIZADO"
+	sala.PrazoTurno = time.Time{}
+	s.liberarEspectadores(sala)
+	s.difundirEventoPartida(sala, protocolo.Mensagem{
+		Comando: "SISTEMA",
+		Dados:   seguranca.MustJSON(protocolo.DadosErro{Mensagem: "Partida encerrada por inatividade."}),
+	})
+}
+
+// processarComandoSala despacha um comando da fila. Um panic é registrado e não derruba a sala.
+func (s *Servidor) processarComandoSala(sala *tipos.Sala, comando protocolo.Comando) (resultado interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[SALA_ERRO:%s] Comando %s de %s falhou: %v", sala.ID, comando.Tipo, comando.ClienteID, r)
+			resultado = nil
+		}
+	}()
+
+	switch comando.Tipo {
+	case CMD_EVENTO_HOST:
+		var evento tipos.GameEventRequest
+		if err := json.Unmarshal(comando.Payload, &evento); err != nil {
+			log.Printf("[SALA_ERRO:%s] Evento inválido: %v", sala.ID, err)
+			return nil
+		}
+		if estado := s.processarEventoComoHost(sala, &evento); estado != nil {
+			return estado
+		}
+		return nil
+
+	case CMD_ABANDONO:
+		var dados map[string]string
+		json.Unmarshal(comando.Payload, &dados)
+		s.executarAbandono(sala, comando.ClienteID, dados["motivo"])
+
+	case CMD_RELOGIO_TURNO:
+		s.verificarRelogioTurno(sala)
+
+	case CMD_JOGADA_AUTOMATICA:
+		if err := s.jogarAutomaticamente(sala, comando.ClienteID); err != nil {
+			return err
+		}
+
+	case CMD_PROMOVER_SOMBRA:
+		s.promoverSombraAHost(sala)
+
+	case CMD_ESTADO_REMOTO:
+		var estado tipos.EstadoPartida
+		if err := json.Unmarshal(comando.Payload, &estado); err != nil {
+			log.Printf("[SALA_ERRO:%s] Estado remoto inválido: %v", sala.ID, err)
+			return nil
+		}
+		s.aplicarEstadoRemoto(sala, estado)
+
+	case CMD_REPLICAR_ESTADO:
+		var req tipos.GameReplicateRequest
+		if err := json.Unmarshal(comando.Payload, &req); err != nil {
+			log.Printf("[SALA_ERRO:%s] Replicação inválida: %v", sala.ID, err)
 			return false
 		}
+		return s.replicarEstadoNaSala(sala, req.EventSeq, req.State)
+
+	case CMD_TROCA_DIRETA:
+		var req protocolo.TrocarCartasReq
+		if err := json.Unmarshal(comando.Payload, &req); err != nil {
+			log.Printf("[SALA_ERRO:%s] Troca inválida: %v", sala.ID, err)
+			return nil
+		}
+		s.processarTrocaCartas(sala, &req)
+
+	case CMD_HOST_INACESSIVEL:
+		var evento tipos.GameEventRequest
+		if err := json.Unmarshal(comando.Payload, &evento); err != nil {
+			log.Printf("[SALA_ERRO:%s] Jogada não entregue inválida: %v", sala.ID, err)
+			return nil
+		}
+		s.promoverSombraAHost(sala)
+		s.processarEventoComoHost(sala, &evento)
+
+	case CMD_PACOTE_OBTIDO:
+		var resultado pacoteObtido
+		if err := json.Unmarshal(comando.Payload, &resultado); err != nil {
+			log.Printf("[SALA_ERRO:%s] Pacote inválido: %v", sala.ID, err)
+			return nil
+		}
+		s.concluirCompraObtida(sala, resultado)
+
+	case CMD_INVENTARIO_LIDO:
+		var resultado inventarioLido
+		if err := json.Unmarshal(comando.Payload, &resultado); err != nil {
+			log.Printf("[SALA_ERRO:%s] Inventário lido inválido: %v", sala.ID, err)
+			return nil
+		}
+		s.aplicarInventarioLido(sala, resultado)
+
+	case CMD_TROCA_CONCLUIDA:
+		var resultado trocaConcluida
+		if err := json.Unmarshal(comando.Payload, &resultado); err != nil {
+			log.Printf("[SALA_ERRO:%s] Resultado de troca inválido: %v", sala.ID, err)
+			return nil
+		}
+		s.concluirTrocaCartas(sala, resultado)
+
+	default:
+		s.executarComandoJogador(sala, comando)
 	}
-	return true
+	return nil
 }
 
-func (s *Servidor) notificarErro(clienteID string, mensagem string) {
-	s.publicarParaCliente(clienteID, protocolo.Mensagem{
-		Comando: "ERRO",
-		Dados:   seguranca.MustJSON(protocolo.DadosErro{Mensagem: mensagem}),
-	})
+// ==================== RELÓGIO DE TURNO ====================
+
+// reiniciarRelogioTurno dá a TurnoDe o prazo completo de uma jogada.
+// Executada na goroutine da sala.
+func (s *Servidor) reiniciarRelogioTurno(sala *tipos.Sala) {
+	if s.TempoTurno <= 0 || sala.Estado != "JOGANDO" {
+		return
+	}
+	sala.PrazoTurno = time.Now().Add(s.TempoTurno)
+	sala.PrazoVencido = time.Time{}
+	sala.AvisoPrazo = false
 }
 
-func (s *Servidor) notificarSucessoTroca(clienteID, cartaPerdida, cartaGanha string) {
-	msg := fmt.Sprintf("Troca realizada! Você deu '%s' e recebeu '%s'.", cartaPerdida, cartaGanha)
-	resp := protocolo.TrocarCartasResp{Sucesso: true, Mensagem: msg}
-	s.publicarParaCliente(clienteID, protocolo.Mensagem{Comando: "TROCA_CONCLUIDA", Dados: seguranca.MustJSON(resp)})
+// segundosRestantesTurno retorna quantos segundos TurnoDe ainda tem (0 = sem relógio).
+// Executada na goroutine da sala ou sobre um Retrato.
+func segundosRestantesTurno(sala *tipos.Sala) int {
+	if sala.PrazoTurno.IsZero() {
+		return 0
+	}
+	restante := time.Until(sala.PrazoTurno)
+	if restante <= 0 {
+		return 0
+	}
+	return int((restante + time.Second - 1) / time.Second)
 }
 
-func (s *Servidor) notificarSucessoTrocaComInventario(clienteID, cartaPerdida, cartaGanha string, inventario []tipos.Carta) {
-	// Se o jogador tem endereço blockchain, busca o inventário atualizado da blockchain
-	s.mutexClientes.RLock()
-	cliente := s.Clientes[clienteID]
-	s.mutexClientes.RUnlock()
+func prazoTurnoUnixMilli(sala *tipos.Sala) int64 {
+	if sala.PrazoTurno.IsZero() {
+		return 0
+	}
+	return sala.PrazoTurno.UnixMilli()
+}
+
+// monitorarRelogiosTurno verifica os prazos das salas. No Host, avisa quando o tempo está
+// acabando e aplica TURNO_ESGOTADO quando ele termina. Na Sombra, um prazo vencido há mais de
+// TURNO_MINIMO_FAILOVER sem novas réplicas indica que o Host pode ter caído: se ele não
+// responder, a Sombra assume a sala (e o relógio) em promoverSombraAHost.
+func (s *Servidor) monitorarRelogiosTurno() {
+	ticker := time.NewTicker(RELOGIO_TURNO_INTERVALO)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.mutexSalas.RLock()
+		salas := make([]*tipos.Sala, 0, len(s.Salas))
+		for _, sala := range s.Salas {
+			salas = append(salas, sala)
+		}
+		s.mutexSalas.RUnlock()
+
+		for _, sala := range salas {
+			retrato := sala.Retrato()
+			if retrato.Estado != "JOGANDO" || retrato.PrazoTurno.IsZero() {
+				continue
+			}
+			host := retrato.ServidorHost
+			souHost := host == s.MeuEndereco
+			souSombra := retrato.ServidorSombra == s.MeuEndereco
+			restante := time.Until(retrato.PrazoTurno)
+			precisaAtencao := souHost && (restante <= 0 || (restante <= TURNO_AVISO && !retrato.AvisoPrazo))
+			hostSilencioso := souSombra && !souHost && -restante > TURNO_MINIMO_FAILOVER
+
+			// O monitor só observa: aviso, prazo esgotado e promoção são aplicados pela goroutine da sala
+			if precisaAtencao {
+				if err := s.enviarComandoSala(sala, protocolo.Comando{Tipo: CMD_RELOGIO_TURNO}); err != nil {
+					log.Printf("[RELOGIO:%s] %v", sala.ID, err)
+				}
+			}
+			if hostSilencioso && !s.servidorAcessivel(host) {
+				log.Printf("[FAILOVER] Prazo da sala %s vencido e Host %s inacessível. Assumindo a sala.", sala.ID, host)
+				if err := s.enviarComandoSala(sala, protocolo.Comando{Tipo: CMD_PROMOVER_SOMBRA}); err != nil {
+					log.Printf("[FAILOVER] %v", err)
+				}
+			}
+		}
+	}
+}
 
-	inventarioFinal := inventario
+// verificarRelogioTurno avisa quando o prazo de TurnoDe está acabando e aplica TURNO_ESGOTADO
+// quando ele termina. Executada na goroutine da sala, a pedido de monitorarRelogiosTurno.
+func (s *Servidor) verificarRelogioTurno(sala *tipos.Sala) {
+	if sala.Estado != "JOGANDO" || sala.PrazoTurno.IsZero() || sala.ServidorHost != s.MeuEndereco {
+		return
+	}
+	turnoDe := sala.TurnoDe
+	restante := time.Until(sala.PrazoTurno)
 
-	if cliente != nil && cliente.EnderecoBlockchain != "" && s.BlockchainManager != nil {
-		log.Printf("[TROCA_BLOCKCHAIN] Buscando inventário atualizado da blockchain para jogador %s", clienteID)
-		addr := common.HexToAddress(cliente.EnderecoBlockchain)
-		cartasBlockchain, err := s.BlockchainManager.ObterInventario(addr)
-		if err == nil && len(cartasBlockchain) > 0 {
-			log.Printf("[TROCA_BLOCKCHAIN] Inventário da blockchain obtido: %d cartas", len(cartasBlockchain))
-			inventarioFinal = cartasBlockchain
-			// Atualiza também o inventário local do cliente
-			cliente.Mutex.Lock()
-			cliente.Inventario = cartasBlockchain
-			cliente.Mutex.Unlock()
-		} else {
-			if err != nil {
-				log.Printf("[TROCA_BLOCKCHAIN_AVISO] Erro ao buscar inventário da blockchain: %v", err)
-			} else {
-				log.Printf("[TROCA_BLOCKCHAIN_AVISO] Inventário da blockchain vazio")
+	if restante <= 0 {
+		// Nova chance caso a jogada automática se perca; a jogada (ou o W.O.) reinicia o relógio.
+		// O prazo vencido fica registrado para a jogada automática (ver dadosJogadaAutomatica).
+		if sala.PrazoVencido.IsZero() {
+			sala.PrazoVencido = sala.PrazoTurno
+		}
+		sala.PrazoTurno = time.Now().Add(TURNO_MINIMO_FAILOVER)
+		sala.AvisoPrazo = true
+		// A política pode precisar do servidor do jogador (HTTP): roda fora da goroutine da sala,
+		// e a jogada automática ou o W.O. voltam para ela como comandos
+		go s.tratarTurnoEsgotado(sala, turnoDe)
+		return
+	}
+
+	var aviso *protocolo.Mensagem
+	if restante <= TURNO_AVISO && !sala.AvisoPrazo {
+		sala.AvisoPrazo = true
+		nome := ""
+		for _, j := range sala.Jogadores {
+			if j.ID == turnoDe {
+				nome = j.Nome
 			}
 		}
+		cartasNaMesa := make(map[string]Carta, len(sala.CartasNaMesa))
+		for k, v := range sala.CartasNaMesa {
+			cartasNaMesa[k] = v
+		}
+		aviso = &protocolo.Mensagem{
+			Comando: "ATUALIZACAO_JOGO",
+			Dados: seguranca.MustJSON(protocolo.DadosAtualizacaoJogo{
+				MensagemDoTurno: fmt.Sprintf("⏱ %s tem %d segundos para jogar!", nome, segundosRestantesTurno(sala)),
+				NumeroRodada:    sala.NumeroRodada,
+				UltimaJogada:    cartasNaMesa,
+				SalaID:          sala.ID,
+				TurnoDe:         turnoDe,
+				TempoRestante:   segundosRestantesTurno(sala),
+			}),
+		}
 	}
 
-	msg := fmt.Sprintf("Troca realizada! Você deu '%s' e recebeu '%s'.", cartaPerdida, cartaGanha)
-	resp := protocolo.TrocarCartasResp{
-		Sucesso:              true,
-		Mensagem:             msg,
-		InventarioAtualizado: inventarioFinal,
+	if aviso != nil {
+		s.difundirEventoPartida(sala, *aviso)
 	}
-	s.publicarParaCliente(clienteID, protocolo.Mensagem{Comando: "TROCA_CONCLUIDA", Dados: seguranca.MustJSON(resp)})
 }
 
-func (s *Servidor) notificarJogadorRemoto(servidor string, clienteID string, msg protocolo.Mensagem) {
-	log.Printf("[NOTIFICACAO-REMOTA] Notificando cliente %s no servidor %s", clienteID, servidor)
-	reqBody, _ := json.Marshal(map[string]interface{}{
-		"cliente_id": clienteID,
-		"mensagem":   msg,
-	})
-
-	resp, err := s.enviarRequestComToken("POST", fmt.Sprintf("http://%s/partida/notificar_jogador", servidor), reqBody)
-	if err != nil {
-		log.Printf("[NOTIFICACAO-REMOTA] Erro ao notificar cliente %s no servidor %s: %v", clienteID, servidor, err)
-		return
+// tratarTurnoEsgotado aplica a política TURNO_ESGOTADO ao jogador que estourou o prazo.
+// Se a jogada automática não for possível, o jogador perde por W.O.
+func (s *Servidor) tratarTurnoEsgotado(sala *tipos.Sala, clienteID string) {
+	nome := ""
+	for _, j := range sala.Jogadores {
+		if j.ID == clienteID {
+			nome = j.Nome
+		}
 	}
-	defer resp.Body.Close()
+	sombra := sala.Retrato().ServidorSombra
 
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("[NOTIFICACAO-REMOTA] Servidor remoto %s retornou status %d", servidor, resp.StatusCode)
+	log.Printf("[RELOGIO:%s] Tempo de %s (%s) esgotado. Ação: %s", sala.ID, nome, clienteID, s.AcaoTurnoEsgotado)
+
+	if s.AcaoTurnoEsgotado == TURNO_ESGOTADO_AUTOMATICO {
+		s.difundirEventoPartida(sala, protocolo.Mensagem{
+			Comando: "SISTEMA",
+			Dados:   seguranca.MustJSON(protocolo.DadosErro{Mensagem: fmt.Sprintf("Tempo esgotado! Jogada automática de %s.", nome)}),
+		})
+
+		var err error
+		if s.getClienteLocal(clienteID) != nil {
+			err = s.JogarAutomaticamente(sala.ID, clienteID)
+		} else if sombra != "" {
+			err = s.solicitarJogadaAutomatica(sombra, sala.ID, clienteID)
+		} else {
+			err = fmt.Errorf("servidor do jogador desconhecido")
+		}
+		if err == nil {
+			return
+		}
+		log.Printf("[RELOGIO:%s] Jogada automática de %s falhou: %v. Declarando W.O.", sala.ID, clienteID, err)
 	}
+	s.declararAbandono(sala, clienteID, "tempo_esgotado")
 }
 
-func (s *Servidor) getClienteLocal(clienteID string) *tipos.Cliente {
-	s.mutexClientes.RLock()
-	defer s.mutexClientes.RUnlock()
-	// Verifica se o cliente está conectado a este servidor
-	cliente, ok := s.Clientes[clienteID]
-	if ok {
-		return cliente
+// JogarAutomaticamente pede à goroutine da sala a jogada automática de um jogador local e espera o resultado.
+func (s *Servidor) JogarAutomaticamente(salaID, clienteID string) error {
+	s.mutexSalas.RLock()
+	sala, ok := s.Salas[salaID]
+	s.mutexSalas.RUnlock()
+	if !ok {
+		return fmt.Errorf("sala %s não encontrada", salaID)
 	}
-	return nil
+	resultado, err := s.executarNaSala(sala, protocolo.Comando{ClienteID: clienteID, Tipo: CMD_JOGADA_AUTOMATICA})
+	if err != nil {
+		return err
+	}
+	err, _ = resultado.(error)
+	return err
 }
 
-func (s *Servidor) criarEstadoDaSala(sala *tipos.Sala) *tipos.EstadoPartida {
-	// CORREÇÃO: Esta função assume que o lock da sala JÁ ESTÁ ativo!
-	// Remove o lock interno para evitar deadlock
+// jogarAutomaticamente joga, por um jogador local, a carta mais fraca da sua mão segundo as regras da sala.
+// No Host a jogada é processada diretamente; na Sombra segue o caminho normal até o Host.
+func (s *Servidor) jogarAutomaticamente(sala *tipos.Sala, clienteID string) error {
+	salaID := sala.ID
+	cliente := s.getClienteLocal(clienteID)
+	if cliente == nil {
+		return fmt.Errorf("jogador %s não está conectado a este servidor", clienteID)
+	}
 
-	// Cria contagem de cartas atualizada
-	contagemCartas := make(map[string]int)
-	jogadoresEstado := make([]tipos.JogadorEstado, 0, len(sala.Jogadores))
+	regrasSala := regras.Obter(sala.Regras)
+	host := sala.ServidorHost
 
-	for _, j := range sala.Jogadores {
-		j.Mutex.Lock()
-		contagemCartas[j.Nome] = len(j.Inventario)
-		// Adiciona jogador ao estado com seu inventário
-		invCopy := make([]tipos.Carta, len(j.Inventario))
-		copy(invCopy, j.Inventario)
-		jogadoresEstado = append(jogadoresEstado, tipos.JogadorEstado{
-			ID:         j.ID,
-			Inventario: invCopy,
-		})
-		j.Mutex.Unlock()
+	cliente.Mutex.Lock()
+	var cartaMaisFraca *Carta
+	for i := range cliente.Inventario {
+		if cartaMaisFraca == nil || regrasSala.CompararCartas(cliente.Inventario[i], *cartaMaisFraca) < 0 {
+			cartaMaisFraca = &cliente.Inventario[i]
+		}
+	}
+	cartaID := ""
+	if cartaMaisFraca != nil {
+		cartaID = cartaMaisFraca.ID
 	}
+	cliente.Mutex.Unlock()
 
-	// Cria estado completo da partida
-	return &tipos.EstadoPartida{
-		SalaID:        sala.ID,
-		Estado:        sala.Estado,
-		TurnoDe:       sala.TurnoDe,
-		CartasNaMesa:  sala.CartasNaMesa,
-		PontosRodada:  sala.PontosRodada,
-		PontosPartida: sala.PontosPartida,
-		NumeroRodada:  sala.NumeroRodada,
-		Prontos:       sala.Prontos,
-		EventSeq:      sala.EventSeq,
-		EventLog:      sala.EventLog,
-		Jogadores:     jogadoresEstado,
+	if cartaID == "" {
+		return fmt.Errorf("jogador %s não tem cartas para jogar", clienteID)
 	}
-}
+	log.Printf("[RELOGIO:%s] Jogada automática de %s: carta %s", salaID, clienteID, cartaID)
 
-func (s *Servidor) notificarErroPartida(clienteID, mensagem, salaID string) {
-	dados := protocolo.DadosErro{Mensagem: mensagem}
-	msg := protocolo.Mensagem{
-		Comando: "ERRO_JOGADA",
-		Dados:   seguranca.MustJSON(dados),
+	if host == s.MeuEndereco {
+		evento := &tipos.GameEventRequest{
+			MatchID:   salaID,
+			EventType: "CARD_PLAYED",
+			PlayerID:  clienteID,
+			Data:      map[string]interface{}{"carta_id": cartaID, "automatica": true},
+		}
+		if s.processarEventoComoHost(sala, evento) == nil {
+			return fmt.Errorf("jogada automática recusada pelo Host")
+		}
+		return nil
 	}
-	// O erro é publicado no tópico de eventos da partida para que ambos os jogadores possam vê-lo, se necessário,
-	// ou apenas para o cliente específico. Optarei por notificar apenas o cliente que cometeu o erro.
-	s.publicarParaCliente(clienteID, msg)
+	s.encaminharJogadaParaHost(sala, clienteID, cartaID, "", true)
+	return nil
 }
 
-// ==================== UTILITÁRIOS ====================
-
-func mustJSON(v interface{}) []byte {
-	b, _ := json.Marshal(v)
-	return b
+// solicitarJogadaAutomatica pede ao servidor onde o jogador está conectado que faça a jogada automática.
+func (s *Servidor) solicitarJogadaAutomatica(servidor, salaID, clienteID string) error {
+	body, _ := json.Marshal(tipos.TurnoEsgotadoRequest{MatchID: salaID, PlayerID: clienteID})
+	resp, err := s.enviarRequestComToken("POST", fmt.Sprintf("http://%s/game/turno_esgotado", servidor), body)
+	if err != nil {
+		return fmt.Errorf("erro ao contatar %s: %v", servidor, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("servidor %s respondeu com status %d", servidor, resp.StatusCode)
+	}
+	return nil
 }
 
-// CORREÇÃO: Função auxiliar para mudança atômica de turno
-func (s *Servidor) mudarTurnoAtomicamente(sala *tipos.Sala, novoJogadorID string) {
-	// Encontra o nome do novo jogador
-	var novoJogadorNome string
-	for _, j := range sala.Jogadores {
-		if j.ID == novoJogadorID {
-			novoJogadorNome = j.Nome
-			break
-		}
+// servidorAcessivel verifica rapidamente se a API de outro servidor responde.
+func (s *Servidor) servidorAcessivel(endereco string) bool {
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://%s/servers", endereco))
+	if err != nil {
+		return false
 	}
+	resp.Body.Close()
+	return true
+}
 
-	// Atualiza o turno
-	sala.TurnoDe = novoJogadorID
-	log.Printf("[TURNO_ATOMICO:%s] Turno alterado para: %s (%s)", sala.ID, novoJogadorNome, novoJogadorID)
+// difundirEventoPartida publica um evento para os jogadores conectados a este servidor (MQTT) e
+// o repassa, via HTTP, ao outro servidor da sala para os jogadores conectados a ele. Lê os
+// servidores do Retrato, então pode ser chamada de qualquer goroutine.
+func (s *Servidor) difundirEventoPartida(sala *tipos.Sala, msg protocolo.Mensagem) {
+	retrato := sala.Retrato()
+	outroServidor := retrato.ServidorSombra
+	if outroServidor == s.MeuEndereco {
+		outroServidor = retrato.ServidorHost
+	}
 
-	// CORREÇÃO: Notificação deve ser feita FORA do lock da sala para evitar deadlock
-	// A notificação será feita pela função chamadora após liberar o lock
+	s.publicarEventoPartida(sala.ID, msg)
+	if outroServidor != "" && outroServidor != s.MeuEndereco {
+		go s.notificarJogadoresRemotosDaPartida(sala.ID, outroServidor, sala.Jogadores, msg)
+	}
 }
 
 // A estrutura Servidor agora implementa implicitamente a api.ServidorInterface
@@ -3389,15 +6075,6 @@ func (s *Servidor) ProcessarHeartbeat(endereco string, dados map[string]interfac
 	// Lógica movida para cluster.Manager
 }
 
-func (s *Servidor) ProcessarVoto(candidato string, termo int64) (bool, int64) {
-	// Lógica movida para cluster.Manager
-	return false, 0
-}
-
-func (s *Servidor) DeclararLider(novoLider string, termo int64) {
-	// Lógica movida para cluster.Manager
-}
-
 func (s *Servidor) SouLider() bool {
 	// Lógica movida para cluster.Manager
 	return false
@@ -3430,8 +6107,207 @@ func (s *Servidor) EncaminharParaLider(c *gin.Context) {
 	c.DataFromReader(resp.StatusCode, resp.ContentLength, resp.Header.Get("Content-Type"), resp.Body, nil)
 }
 
-func (s *Servidor) FormarPacote() ([]tipos.Carta, error) {
-	return s.Store.FormarPacote(PACOTE_SIZE), nil
+// FormarPacote reserva um pacote do estoque e registra a compra no log replicado.
+// A compra só é entregue ao cliente depois de confirmada pela maioria do cluster e aplicada
+// (AplicarEntradaLog), que é quando as cartas saem do estoque em todos os servidores. Se a entrada
+// não for confirmada, ou se ao ser aplicada alguma carta já tiver saído por outra compra do log,
+// o cliente não recebe nada.
+func (s *Servidor) FormarPacote(clienteID string) ([]tipos.Carta, error) {
+	if s.Store.NaBlockchain() {
+		return nil, fmt.Errorf("estoque na blockchain: o pacote é comprado pela carteira do jogador")
+	}
+	cartas := s.Store.FormarPacote(PACOTE_SIZE)
+
+	compraID := uuid.New().String()
+	s.mutexCompras.Lock()
+	s.comprasPropostas[compraID] = false
+	s.mutexCompras.Unlock()
+	defer func() {
+		s.mutexCompras.Lock()
+		delete(s.comprasPropostas, compraID)
+		s.mutexCompras.Unlock()
+	}()
+
+	entrada := tipos.EntradaCompraPacote{ClienteID: clienteID, Cartas: cartas, CompraID: compraID}
+	if _, err := s.ClusterManager.Propor("COMPRA_PACOTE", entrada); err != nil {
+		s.Store.CancelarRetirada(cartas)
+		return nil, fmt.Errorf("erro ao registrar compra no log: %v", err)
+	}
+
+	s.mutexCompras.Lock()
+	retirada := s.comprasPropostas[compraID]
+	s.mutexCompras.Unlock()
+	if !retirada {
+		return nil, fmt.Errorf("cartas do pacote já vendidas por outra compra do log; tente novamente")
+	}
+	return cartas, nil
+}
+
+// resgatarPacote registra no log replicado a entrega do pacote da transação de revelação informada
+// e espera a entrada ser aplicada neste servidor. Falha se a compra já foi resgatada por outra entrada.
+func (s *Servidor) resgatarPacote(clienteID, transacao string) error {
+	compraID := uuid.New().String()
+	aplicada := make(chan bool, 1)
+	s.mutexCompras.Lock()
+	s.resgatesPropostos[compraID] = aplicada
+	s.mutexCompras.Unlock()
+	defer func() {
+		s.mutexCompras.Lock()
+		delete(s.resgatesPropostos, compraID)
+		s.mutexCompras.Unlock()
+	}()
+
+	entrada := tipos.EntradaResgatePacote{ClienteID: clienteID, Transacao: transacao, CompraID: compraID}
+	if _, err := s.ClusterManager.Propor("RESGATE_PACOTE", entrada); err != nil {
+		return fmt.Errorf("erro ao registrar o resgate no log: %v", err)
+	}
+	// Num seguidor, a entrada confirmada pelo líder chega a este servidor no próximo AppendEntries
+	select {
+	case resgatado := <-aplicada:
+		if !resgatado {
+			return fmt.Errorf("o pacote da transação %s já foi entregue", transacao)
+		}
+		return nil
+	case <-time.After(ESPERA_VINCULO_CARTEIRA):
+		return fmt.Errorf("resgate confirmado pelo cluster ainda não aplicado neste servidor")
+	}
+}
+
+// AplicarEntradaLog aplica ao estado local uma entrada confirmada do log replicado.
+// Chamada em ordem pelo cluster.Manager em todos os servidores; precisa ser determinística,
+// pois o log é reaplicado desde o último snapshot (RestaurarSnapshot) quando o servidor reinicia.
+func (s *Servidor) AplicarEntradaLog(entrada cluster.EntradaLog) {
+	switch entrada.Tipo {
+	case "COMPRA_PACOTE":
+		var compra tipos.EntradaCompraPacote
+		if err := json.Unmarshal(entrada.Dados, &compra); err != nil {
+			log.Printf("[RAFT_APLICAR] Entrada %d (COMPRA_PACOTE) inválida: %v", entrada.Indice, err)
+			return
+		}
+		ids := make([]string, len(compra.Cartas))
+		for i, carta := range compra.Cartas {
+			ids[i] = carta.ID
+		}
+		retirada := s.Store.ConfirmarRetirada(ids)
+		if retirada {
+			log.Printf("[RAFT_APLICAR] Compra de %s aplicada: %d cartas retiradas do estoque", compra.ClienteID, len(ids))
+		} else {
+			log.Printf("[RAFT_APLICAR] Compra de %s (entrada %d) recusada: cartas já retiradas por outra compra", compra.ClienteID, entrada.Indice)
+		}
+		s.mutexCompras.Lock()
+		if _, proposta := s.comprasPropostas[compra.CompraID]; proposta {
+			s.comprasPropostas[compra.CompraID] = retirada
+		}
+		s.mutexCompras.Unlock()
+
+	case "RESGATE_PACOTE":
+		var resgate tipos.EntradaResgatePacote
+		if err := json.Unmarshal(entrada.Dados, &resgate); err != nil {
+			log.Printf("[RAFT_APLICAR] Entrada %d (RESGATE_PACOTE) inválida: %v", entrada.Indice, err)
+			return
+		}
+		// Só o StoreBlockchain registra resgates; no estoque em memória a entrada não tem efeito
+		resgatado := s.Store.NaBlockchain() && s.Store.ConfirmarRetirada([]string{resgate.Transacao})
+		if resgatado {
+			log.Printf("[RAFT_APLICAR] Pacote da transação %s entregue a %s", resgate.Transacao, resgate.ClienteID)
+		} else {
+			log.Printf("[RAFT_APLICAR] Resgate da transação %s por %s (entrada %d) recusado: compra já resgatada", resgate.Transacao, resgate.ClienteID, entrada.Indice)
+		}
+		s.mutexCompras.Lock()
+		if ch, proposto := s.resgatesPropostos[resgate.CompraID]; proposto {
+			ch <- resgatado
+			delete(s.resgatesPropostos, resgate.CompraID)
+		}
+		s.mutexCompras.Unlock()
+
+	case "RESULTADO_PARTIDA":
+		var resultado tipos.ResultadoPartida
+		if err := json.Unmarshal(entrada.Dados, &resultado); err != nil {
+			log.Printf("[RAFT_APLICAR] Entrada %d (RESULTADO_PARTIDA) inválida: %v", entrada.Indice, err)
+			return
+		}
+		// Os ratings saem do desfecho e dos ratings atuais: reaplicar o log desde o snapshot, na mesma
+		// ordem, chega aos mesmos valores em todos os servidores
+		s.aplicarRatings(&resultado)
+		s.mutexResultados.Lock()
+		s.ResultadosPartidas[resultado.SalaID] = resultado
+		s.mutexResultados.Unlock()
+		log.Printf("[RAFT_APLICAR] Resultado da partida %s registrado. Vencedor: %s", resultado.SalaID, resultado.Vencedor)
+
+	case "VINCULO_CARTEIRA":
+		var vinculo tipos.EntradaVinculoCarteira
+		if err := json.Unmarshal(entrada.Dados, &vinculo); err != nil {
+			log.Printf("[RAFT_APLICAR] Entrada %d (VINCULO_CARTEIRA) inválida: %v", entrada.Indice, err)
+			return
+		}
+		s.mutexCarteiras.Lock()
+		atual, vinculado := s.carteirasVinculadas[vinculo.Nome]
+		if !vinculado {
+			s.carteirasVinculadas[vinculo.Nome] = vinculo.Endereco
+		}
+		s.mutexCarteiras.Unlock()
+		if vinculado && !strings.EqualFold(atual, vinculo.Endereco) {
+			log.Printf("[RAFT_APLICAR] Vínculo de %s com %s (entrada %d) recusado: conta já vinculada a %s", vinculo.Nome, vinculo.Endereco, entrada.Indice, atual)
+		} else if !vinculado {
+			log.Printf("[RAFT_APLICAR] Carteira %s vinculada ao jogador %s", vinculo.Endereco, vinculo.Nome)
+		}
+
+	default:
+		log.Printf("[RAFT_APLICAR] Tipo de entrada desconhecido: %s (índice %d)", entrada.Tipo, entrada.Indice)
+	}
+}
+
+// estadoReplicado é o estado construído pelas entradas do log replicado, gravado nos snapshots com
+// que o Raft compacta o log.
+type estadoReplicado struct {
+	Retiradas  []string                          `json:"retiradas"` // Store.Retiradas
+	Ratings    map[string]int                    `json:"ratings"`
+	Resultados map[string]tipos.ResultadoPartida `json:"resultados"`
+	Carteiras  map[string]string                 `json:"carteiras"`
+}
+
+// GerarSnapshot serializa o estado aplicado do log replicado. Chamado pelo cluster.Manager com a
+// aplicação de entradas parada, então o estado corresponde à última entrada aplicada.
+func (s *Servidor) GerarSnapshot() (json.RawMessage, error) {
+	estado := estadoReplicado{Retiradas: s.Store.Retiradas()}
+	s.mutexRatings.RLock()
+	estado.Ratings = copiarMapa(s.Ratings)
+	s.mutexRatings.RUnlock()
+	s.mutexResultados.RLock()
+	estado.Resultados = copiarMapa(s.ResultadosPartidas)
+	s.mutexResultados.RUnlock()
+	s.mutexCarteiras.RLock()
+	estado.Carteiras = copiarMapa(s.carteirasVinculadas)
+	s.mutexCarteiras.RUnlock()
+	return json.Marshal(estado)
+}
+
+// RestaurarSnapshot substitui o estado aplicado do log replicado pelo de um snapshot.
+func (s *Servidor) RestaurarSnapshot(dados json.RawMessage) error {
+	var estado estadoReplicado
+	if err := json.Unmarshal(dados, &estado); err != nil {
+		return fmt.Errorf("snapshot inválido: %v", err)
+	}
+	s.Store.RestaurarRetiradas(estado.Retiradas)
+	s.mutexRatings.Lock()
+	s.Ratings = copiarMapa(estado.Ratings)
+	s.mutexRatings.Unlock()
+	s.mutexResultados.Lock()
+	s.ResultadosPartidas = copiarMapa(estado.Resultados)
+	s.mutexResultados.Unlock()
+	s.mutexCarteiras.Lock()
+	s.carteirasVinculadas = copiarMapa(estado.Carteiras)
+	s.mutexCarteiras.Unlock()
+	return nil
+}
+
+// copiarMapa copia um mapa; um mapa nil vira um mapa vazio.
+func copiarMapa[K comparable, V any](origem map[K]V) map[K]V {
+	copia := make(map[K]V, len(origem))
+	for chave, valor := range origem {
+		copia[chave] = valor
+	}
+	return copia
 }
 
 // PublicarChatRemoto é chamado pela API quando o Shadow recebe um chat do Host
@@ -3447,10 +6323,12 @@ func (s *Servidor) PublicarChatRemoto(salaID, nomeJogador, texto string) {
 	s.publicarEventoPartida(salaID, msg)
 }
 
-// retransmitirChat envia uma mensagem de chat para todos os jogadores na sala.
+// retransmitirChat envia uma mensagem de chat para todos os jogadores na sala. Lê os servidores do
+// Retrato, então pode ser chamada de qualquer goroutine.
 func (s *Servidor) retransmitirChat(sala *tipos.Sala, cliente *tipos.Cliente, texto string) {
-	isHost := sala.ServidorHost == s.MeuEndereco
-	isCrossServer := sala.ServidorSombra != ""
+	retrato := sala.Retrato()
+	isHost := retrato.ServidorHost == s.MeuEndereco
+	isCrossServer := retrato.ServidorSombra != ""
 
 	// Log detalhado
 	log.Printf("[CHAT:%s] Retransmitindo de '%s'. Host: %t, Cross-Server: %t", sala.ID, cliente.Nome, isHost, isCrossServer)
@@ -3473,7 +6351,7 @@ func (s *Servidor) retransmitirChat(sala *tipos.Sala, cliente *tipos.Cliente, te
 
 	// 2. Se for o Host de uma partida cross-server, encaminha para o Shadow.
 	if isHost && isCrossServer {
-		go s.encaminharChatParaSombra(sala.ServidorSombra, sala.ID, cliente.Nome, texto)
+		go s.encaminharChatParaSombra(retrato.ServidorSombra, sala.ID, cliente.Nome, texto)
 	}
 }
 
@@ -3534,135 +6412,27 @@ func (s *Servidor) encaminharChatParaSombra(sombraAddr, salaID, nomeJogador, tex
 	}
 }
 
-func (s *Servidor) processarComandoMQTT(topic string, payload []byte) {
-	// Extrai salaID do tópico, se aplicável
-	parts := strings.Split(topic, "/")
-	if len(parts) < 3 || parts[0] != "partidas" {
-		// log.Printf("[CMD_MQTT_DEBUG] Tópico MQTT ignorado (não é de comando de partida): %s", topic)
-		return
-	}
-	salaID := parts[1]
-
-	var mensagem protocolo.Mensagem
-	if err := json.Unmarshal(payload, &mensagem); err != nil {
-		log.Printf("Erro ao decodificar mensagem MQTT: %v", err)
-		return
-	}
-
-	timestamp := time.Now().Format("15:04:05.000")
-	log.Printf("[%s][COMANDO_DEBUG] === INÍCIO PROCESSAMENTO COMANDO ===", timestamp)
-	log.Printf("[%s][COMANDO_DEBUG] Comando recebido no tópico: %s", timestamp, topic)
-	log.Printf("[%s][COMANDO_DEBUG] Payload: %s", timestamp, string(payload))
-	log.Printf("[%s][COMANDO_DEBUG] Comando decodificado: %s", timestamp, mensagem.Comando)
-
-	s.mutexSalas.RLock()
-	sala, ok := s.Salas[salaID]
-	s.mutexSalas.RUnlock()
-
-	if !ok {
-		log.Printf("[%s][COMANDO_DEBUG] ERRO: Sala %s não encontrada para o comando %s", timestamp, salaID, mensagem.Comando)
-		return
-	}
-
-	// Extrai o clienteID do payload genérico para identificar o remetente
-	var dadosComClienteID struct {
-		ClienteID string `json:"cliente_id"`
-		Texto     string `json:"texto"` // Para o caso de CHAT
-	}
-	if err := json.Unmarshal(mensagem.Dados, &dadosComClienteID); err != nil {
-		log.Printf("[%s][COMANDO_DEBUG] ERRO: Falha ao extrair cliente_id do payload para o comando %s", timestamp, mensagem.Comando)
-		return
-	}
-	clienteID := dadosComClienteID.ClienteID
-
-	s.mutexClientes.RLock()
-	cliente, clienteOk := s.Clientes[clienteID]
-	s.mutexClientes.RUnlock()
-
-	if !clienteOk {
-		log.Printf("[%s][COMANDO_DEBUG] ERRO: Cliente %s não encontrado para o comando %s", timestamp, clienteID, mensagem.Comando)
-		return
-	}
-
-	// Lógica de encaminhamento ou processamento
-	isHost := sala.ServidorHost == s.MeuEndereco
-	isCrossServer := sala.ServidorSombra != ""
-
-	// Se este servidor NÃO for o host, encaminha o comando
-	if !isHost && isCrossServer {
-		log.Printf("[%s][COMANDO_DEBUG] SHADOW: Encaminhando comando '%s' do cliente %s para o Host %s", timestamp, mensagem.Comando, clienteID, sala.ServidorHost)
-
-		// CORREÇÃO: Decodifica o payload para um mapa genérico para encaminhar os dados corretamente,
-		// em vez de aninhá-los em um novo mapa.
-		var dataParaEncaminhar map[string]interface{}
-		if err := json.Unmarshal(mensagem.Dados, &dataParaEncaminhar); err != nil {
-			log.Printf("[SHADOW] Erro ao decodificar dados do evento '%s' para encaminhamento: %v", mensagem.Comando, err)
-			return
-		}
-
-		s.encaminharEventoParaHost(sala, clienteID, mensagem.Comando, dataParaEncaminhar)
-		return
-	}
-
-	// Se for o Host, processa o comando
-	switch mensagem.Comando {
-	case "COMPRAR_PACOTE":
-		log.Printf("[COMPRAR_DEBUG] Processando compra para cliente %s, souLider: %t", clienteID, s.ClusterManager.SouLider())
-		s.processarCompraPacote(clienteID, sala)
-	case "JOGAR_CARTA":
-		var dadosJogada struct {
-			CartaID string `json:"carta_id"`
-		}
-		if err := json.Unmarshal(mensagem.Dados, &dadosJogada); err == nil {
-			evento := &tipos.GameEventRequest{
-				MatchID:   sala.ID,
-				EventType: "JOGAR_CARTA",
-				PlayerID:  clienteID,
-				Data:      map[string]interface{}{"carta_id": dadosJogada.CartaID},
-			}
-			s.processarEventoComoHost(sala, evento)
-		}
-	case "CHAT":
-		log.Printf("[HOST-CHAT] Recebido chat de %s. Fazendo broadcast.", cliente.Nome)
-		s.retransmitirChat(sala, cliente, dadosComClienteID.Texto)
-	default:
-		log.Printf("[%s][COMANDO_DEBUG] AVISO: Comando '%s' não reconhecido ou não manipulado diretamente.", timestamp, mensagem.Comando)
-	}
-	log.Printf("[%s][COMANDO_DEBUG] === FIM PROCESSAMENTO COMANDO ===", timestamp)
-}
-
 func (s *Servidor) processarLogin(payload []byte, tempID string) {
 	// ... existing code ...
 }
 
-// forcarSincronizacaoEstado força a sincronização do estado da partida
-func (s *Servidor) forcarSincronizacaoEstado(salaID string) {
-	sala := s.Salas[salaID]
-	if sala == nil || sala.ServidorSombra == "" {
+// forcarSincronizacaoEstado envia à Sombra o estado montado pela goroutine da sala
+func (s *Servidor) forcarSincronizacaoEstado(sombra string, estado *tipos.EstadoPartida) {
+	if sombra == "" || estado == nil {
 		return
 	}
 
-	log.Printf("[FORCE_SYNC] Forçando sincronização de estado para sala %s", salaID)
-
-	// CORREÇÃO: Agora precisamos bloquear porque criarEstadoDaSala espera o lock ativo
-	sala.Mutex.Lock()
-	estado := s.criarEstadoDaSala(sala)
-	sala.Mutex.Unlock()
-
-	if estado == nil {
-		log.Printf("[FORCE_SYNC] Erro ao criar estado da sala %s", salaID)
-		return
-	}
+	log.Printf("[FORCE_SYNC] Forçando sincronização de estado para sala %s", estado.SalaID)
 
 	// Enviar estado para a sombra
-	s.sincronizarEstadoComSombra(sala.ServidorSombra, estado)
+	s.sincronizarEstadoComSombra(sombra, estado)
 
 	// Enviar atualização de jogo
 	msg := protocolo.Mensagem{
 		Comando: "ATUALIZACAO_JOGO",
 		Dados:   seguranca.MustJSON(estado),
 	}
-	s.enviarAtualizacaoParaSombra(sala.ServidorSombra, msg)
+	s.enviarAtualizacaoParaSombra(sombra, msg)
 }
 
 // enviarRequestComToken é um helper para enviar requisições HTTP autenticadas para outros servidores
@@ -0,0 +1,239 @@
+// Package persistencia guarda as contas de jogadores de um servidor entre execuções.
+//
+// O escopo é o servidor: cada um tem o seu arquivo (jogadores_<servidor>.jsonl) com as contas que
+// entraram por ele, e nada aqui é replicado. O que precisa valer no cluster inteiro não depende
+// deste arquivo: o vínculo do nome com a carteira vai pelo log replicado (VINCULO_CARTEIRA), e o
+// inventário de quem tem carteira é o do contrato. Uma conta que entra por outro servidor começa lá
+// sem o inventário e o histórico guardados aqui.
+package persistencia
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"jogodistribuido/protocolo"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// RegistroJogador é o que fica salvo de um jogador entre execuções do servidor.
+type RegistroJogador struct {
+	ID                 string                    `json:"id"`
+	Nome               string                    `json:"nome"`
+	Inventario         []protocolo.Carta         `json:"inventario"`
+	Historico          []protocolo.ResumoPartida `json:"historico"`
+	EnderecoBlockchain string                    `json:"endereco_blockchain,omitempty"`
+}
+
+// PlayerRepository define as operações de armazenamento de contas de jogadores.
+type PlayerRepository interface {
+	BuscarPorNome(nome string) (RegistroJogador, bool)
+	Salvar(registro RegistroJogador) error
+	AdicionarPartida(nome string, partida protocolo.ResumoPartida) error
+	Compactar() error
+	Fechar() error
+}
+
+// RepositorioArquivo guarda os jogadores em um arquivo JSON append-only.
+// Cada linha é o registro completo de um jogador; na leitura, a última linha de cada nome vence.
+// O arquivo é compactado na abertura (só a última linha de cada jogador fica) e depois em Compactar,
+// que o servidor chama periodicamente.
+type RepositorioArquivo struct {
+	mutex     sync.Mutex
+	caminho   string
+	arquivo   *os.File
+	jogadores map[string]RegistroJogador // nome -> registro
+	obsoletas int                        // Linhas do arquivo já substituídas por uma mais nova do mesmo jogador
+}
+
+// NewRepositorioArquivo abre (ou cria) o arquivo de jogadores e carrega seu conteúdo em memória.
+func NewRepositorioArquivo(caminho string) (*RepositorioArquivo, error) {
+	if err := os.MkdirAll(filepath.Dir(caminho), 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de dados: %v", err)
+	}
+
+	r := &RepositorioArquivo{
+		caminho:   caminho,
+		jogadores: make(map[string]RegistroJogador),
+	}
+	if err := r.carregar(); err != nil {
+		return nil, err
+	}
+	if err := r.compactar(); err != nil {
+		return nil, err
+	}
+	if err := r.abrir(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[PERSISTENCIA] %d jogadores carregados de %s", len(r.jogadores), caminho)
+	return r, nil
+}
+
+// abrir abre o arquivo para acrescentar registros.
+func (r *RepositorioArquivo) abrir() error {
+	arquivo, err := os.OpenFile(r.caminho, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de jogadores: %v", err)
+	}
+	r.arquivo = arquivo
+	return nil
+}
+
+func (r *RepositorioArquivo) carregar() error {
+	arquivo, err := os.Open(r.caminho)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao ler arquivo de jogadores: %v", err)
+	}
+	defer arquivo.Close()
+
+	scanner := bufio.NewScanner(arquivo)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	linha := 0
+	for scanner.Scan() {
+		linha++
+		var registro RegistroJogador
+		if err := json.Unmarshal(scanner.Bytes(), &registro); err != nil {
+			// Uma linha truncada (queda no meio da escrita) não invalida o resto do arquivo
+			log.Printf("[PERSISTENCIA_AVISO] Linha %d ignorada em %s: %v", linha, r.caminho, err)
+			continue
+		}
+		if registro.Nome != "" {
+			r.jogadores[registro.Nome] = registro
+		}
+	}
+	return scanner.Err()
+}
+
+// compactar reescreve o arquivo só com o registro atual de cada jogador. A cópia é gravada ao lado e
+// renomeada por cima, então uma queda no meio deixa o arquivo antigo intacto.
+func (r *RepositorioArquivo) compactar() error {
+	temporario := r.caminho + ".tmp"
+	arquivo, err := os.Create(temporario)
+	if err != nil {
+		return fmt.Errorf("erro ao compactar arquivo de jogadores: %v", err)
+	}
+	escritor := bufio.NewWriter(arquivo)
+	for _, registro := range r.jogadores {
+		linha, err := json.Marshal(registro)
+		if err != nil {
+			arquivo.Close()
+			os.Remove(temporario)
+			return fmt.Errorf("erro ao serializar jogador: %v", err)
+		}
+		escritor.Write(append(linha, '\n'))
+	}
+	err = escritor.Flush()
+	if err == nil {
+		err = arquivo.Sync()
+	}
+	if err != nil {
+		arquivo.Close()
+		os.Remove(temporario)
+		return fmt.Errorf("erro ao compactar arquivo de jogadores: %v", err)
+	}
+	arquivo.Close()
+	if err := os.Rename(temporario, r.caminho); err != nil {
+		return fmt.Errorf("erro ao compactar arquivo de jogadores: %v", err)
+	}
+	r.obsoletas = 0
+	return nil
+}
+
+// Compactar reescreve o arquivo só com o registro atual de cada jogador, se houver linhas
+// obsoletas. As gravações esperam pela compactação; se ela falhar, o arquivo antigo continua em uso.
+func (r *RepositorioArquivo) Compactar() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.obsoletas == 0 {
+		return nil
+	}
+	obsoletas := r.obsoletas
+	if err := r.arquivo.Close(); err != nil {
+		return fmt.Errorf("erro ao fechar arquivo de jogadores: %v", err)
+	}
+	erroCompactacao := r.compactar()
+	if err := r.abrir(); err != nil {
+		return err
+	}
+	if erroCompactacao != nil {
+		return erroCompactacao
+	}
+	log.Printf("[PERSISTENCIA] %s compactado: %d linhas obsoletas removidas", r.caminho, obsoletas)
+	return nil
+}
+
+// BuscarPorNome retorna o registro salvo de um jogador, se existir.
+func (r *RepositorioArquivo) BuscarPorNome(nome string) (RegistroJogador, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	registro, ok := r.jogadores[nome]
+	return copiarRegistro(registro), ok
+}
+
+// Salvar grava o estado atual de um jogador, preservando o histórico já salvo.
+func (r *RepositorioArquivo) Salvar(registro RegistroJogador) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if registro.Nome == "" {
+		return fmt.Errorf("registro de jogador sem nome")
+	}
+	if registro.Historico == nil {
+		registro.Historico = r.jogadores[registro.Nome].Historico
+	}
+	return r.gravar(registro)
+}
+
+// AdicionarPartida acrescenta uma partida ao histórico de um jogador já registrado.
+func (r *RepositorioArquivo) AdicionarPartida(nome string, partida protocolo.ResumoPartida) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	registro, ok := r.jogadores[nome]
+	if !ok {
+		return fmt.Errorf("jogador %s não encontrado", nome)
+	}
+	registro = copiarRegistro(registro)
+	registro.Historico = append(registro.Historico, partida)
+	return r.gravar(registro)
+}
+
+// gravar assume que r.mutex já está bloqueado.
+func (r *RepositorioArquivo) gravar(registro RegistroJogador) error {
+	linha, err := json.Marshal(registro)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar jogador: %v", err)
+	}
+	if _, err := r.arquivo.Write(append(linha, '\n')); err != nil {
+		return fmt.Errorf("erro ao gravar jogador: %v", err)
+	}
+	if err := r.arquivo.Sync(); err != nil {
+		return fmt.Errorf("erro ao sincronizar arquivo de jogadores: %v", err)
+	}
+	if _, existia := r.jogadores[registro.Nome]; existia {
+		r.obsoletas++
+	}
+	r.jogadores[registro.Nome] = registro
+	return nil
+}
+
+// Fechar fecha o arquivo de jogadores.
+func (r *RepositorioArquivo) Fechar() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.arquivo.Close()
+}
+
+func copiarRegistro(registro RegistroJogador) RegistroJogador {
+	copia := registro
+	copia.Inventario = append([]protocolo.Carta(nil), registro.Inventario...)
+	copia.Historico = append([]protocolo.ResumoPartida(nil), registro.Historico...)
+	return copia
+}
@@ -0,0 +1,161 @@
+package persistencia
+
+import (
+	"jogodistribuido/protocolo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// abrirTeste abre um repositório num diretório temporário, fechando-o no fim do teste
+func abrirTeste(t *testing.T, caminho string) *RepositorioArquivo {
+	t.Helper()
+	r, err := NewRepositorioArquivo(caminho)
+	if err != nil {
+		t.Fatalf("NewRepositorioArquivo: %v", err)
+	}
+	t.Cleanup(func() { r.Fechar() })
+	return r
+}
+
+func contarLinhas(t *testing.T, caminho string) int {
+	t.Helper()
+	conteudo, err := os.ReadFile(caminho)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return strings.Count(string(conteudo), "\n")
+}
+
+func TestCarregarArquivo(t *testing.T) {
+	casos := []struct {
+		nome      string
+		conteudo  string
+		jogadores map[string]int // nome -> cartas no inventário
+	}{
+		{"arquivo vazio", "", map[string]int{}},
+		{"última linha vence",
+			`{"id":"1","nome":"ana","inventario":[{"id":"a"}]}` + "\n" +
+				`{"id":"1","nome":"ana","inventario":[{"id":"a"},{"id":"b"}]}` + "\n",
+			map[string]int{"ana": 2}},
+		{"linha truncada ignorada",
+			`{"id":"1","nome":"ana","inventario":[{"id":"a"}]}` + "\n" +
+				`{"id":"2","nome":"bia","inventario":[{"id":"x"}]}` + "\n" +
+				`{"id":"1","nome":"ana","inventario":[{"id":"a"},`,
+			map[string]int{"ana": 1, "bia": 1}},
+		{"registro sem nome ignorado", `{"id":"1","inventario":[]}` + "\n", map[string]int{}},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			caminho := filepath.Join(t.TempDir(), "jogadores.jsonl")
+			if err := os.WriteFile(caminho, []byte(c.conteudo), 0644); err != nil {
+				t.Fatal(err)
+			}
+			r := abrirTeste(t, caminho)
+			if len(r.jogadores) != len(c.jogadores) {
+				t.Errorf("%d jogadores carregados, esperado %d", len(r.jogadores), len(c.jogadores))
+			}
+			for nome, cartas := range c.jogadores {
+				registro, ok := r.BuscarPorNome(nome)
+				if !ok || len(registro.Inventario) != cartas {
+					t.Errorf("%s: encontrado=%v com %d cartas, esperado %d", nome, ok, len(registro.Inventario), cartas)
+				}
+			}
+			// A abertura compacta: uma linha por jogador
+			if linhas := contarLinhas(t, caminho); linhas != len(c.jogadores) {
+				t.Errorf("%d linhas depois da abertura, esperado %d", linhas, len(c.jogadores))
+			}
+		})
+	}
+}
+
+func TestSalvarEAdicionarPartida(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "jogadores.jsonl")
+	r := abrirTeste(t, caminho)
+	partida := protocolo.ResumoPartida{SalaID: "s1", OponenteNome: "bia", VencedorNome: "ana"}
+
+	passos := []struct {
+		nome      string
+		executar  func() error
+		erro      bool
+		cartas    int
+		partidas  int
+		obsoletas int
+	}{
+		{"partida de jogador desconhecido", func() error { return r.AdicionarPartida("ana", partida) }, true, 0, 0, 0},
+		{"registro sem nome", func() error { return r.Salvar(RegistroJogador{ID: "1"}) }, true, 0, 0, 0},
+		{"primeiro registro", func() error {
+			return r.Salvar(RegistroJogador{ID: "1", Nome: "ana", Inventario: []protocolo.Carta{{ID: "a"}}})
+		}, false, 1, 0, 0},
+		{"partida adicionada", func() error { return r.AdicionarPartida("ana", partida) }, false, 1, 1, 1},
+		{"salvar sem histórico preserva o salvo", func() error {
+			return r.Salvar(RegistroJogador{ID: "1", Nome: "ana", Inventario: []protocolo.Carta{{ID: "a"}, {ID: "b"}}})
+		}, false, 2, 1, 2},
+	}
+	for _, p := range passos {
+		err := p.executar()
+		if (err != nil) != p.erro {
+			t.Fatalf("%s: erro %v, esperado erro=%v", p.nome, err, p.erro)
+		}
+		registro, _ := r.BuscarPorNome("ana")
+		if len(registro.Inventario) != p.cartas || len(registro.Historico) != p.partidas {
+			t.Errorf("%s: %d cartas e %d partidas, esperado %d e %d", p.nome, len(registro.Inventario), len(registro.Historico), p.cartas, p.partidas)
+		}
+		if r.obsoletas != p.obsoletas {
+			t.Errorf("%s: %d linhas obsoletas, esperado %d", p.nome, r.obsoletas, p.obsoletas)
+		}
+	}
+
+	// O registro devolvido é uma cópia
+	registro, _ := r.BuscarPorNome("ana")
+	registro.Inventario[0].ID = "alterado"
+	if salvo, _ := r.BuscarPorNome("ana"); salvo.Inventario[0].ID != "a" {
+		t.Errorf("BuscarPorNome devolveu o inventário guardado, não uma cópia")
+	}
+}
+
+func TestCompactar(t *testing.T) {
+	caminho := filepath.Join(t.TempDir(), "jogadores.jsonl")
+	r := abrirTeste(t, caminho)
+	for i := 0; i < 5; i++ {
+		if err := r.Salvar(RegistroJogador{ID: "1", Nome: "ana", Inventario: make([]protocolo.Carta, i)}); err != nil {
+			t.Fatalf("Salvar: %v", err)
+		}
+	}
+	if err := r.Salvar(RegistroJogador{ID: "2", Nome: "bia"}); err != nil {
+		t.Fatalf("Salvar: %v", err)
+	}
+	if linhas := contarLinhas(t, caminho); linhas != 6 {
+		t.Fatalf("%d linhas antes da compactação, esperado 6", linhas)
+	}
+
+	if err := r.Compactar(); err != nil {
+		t.Fatalf("Compactar: %v", err)
+	}
+	if linhas := contarLinhas(t, caminho); linhas != 2 {
+		t.Errorf("%d linhas depois da compactação, esperado 2", linhas)
+	}
+	if r.obsoletas != 0 {
+		t.Errorf("%d linhas obsoletas depois da compactação", r.obsoletas)
+	}
+
+	// O arquivo continua aberto para gravações, e o reaberto tem os registros atuais
+	if err := r.Salvar(RegistroJogador{ID: "2", Nome: "bia", Inventario: make([]protocolo.Carta, 3)}); err != nil {
+		t.Fatalf("Salvar depois de compactar: %v", err)
+	}
+	r.Fechar()
+	reaberto := abrirTeste(t, caminho)
+	casos := []struct {
+		nome   string
+		cartas int
+	}{
+		{"ana", 4},
+		{"bia", 3},
+	}
+	for _, c := range casos {
+		if registro, ok := reaberto.BuscarPorNome(c.nome); !ok || len(registro.Inventario) != c.cartas {
+			t.Errorf("%s: encontrado=%v com %d cartas, esperado %d", c.nome, ok, len(registro.Inventario), c.cartas)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package ranking
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	RATING_INICIAL = 1200 // Rating de um jogador sem partidas registradas
+	FATOR_K        = 32   // Variação máxima de rating em uma partida
+
+	JANELA_INICIAL    = 100              // Diferença de rating aceita logo ao entrar na fila
+	JANELA_INCREMENTO = 50               // Quanto a janela cresce a cada JANELA_INTERVALO de espera
+	JANELA_INTERVALO  = 10 * time.Second // Tempo de espera para cada alargamento da janela
+	JANELA_MAXIMA     = 1000             // Depois disso, qualquer oponente é aceito
+)
+
+// Esperado retorna a probabilidade de vitória de um jogador com ratingA contra ratingB.
+func Esperado(ratingA, ratingB int) float64 {
+	return 1 / (1 + math.Pow(10, float64(ratingB-ratingA)/400))
+}
+
+// Atualizar calcula os novos ratings após uma partida. resultadoA é 1 (A venceu), 0.5 (empate) ou 0 (B venceu).
+func Atualizar(ratingA, ratingB int, resultadoA float64) (int, int) {
+	variacao := int(math.Round(FATOR_K * (resultadoA - Esperado(ratingA, ratingB))))
+	return ratingA + variacao, ratingB - variacao
+}
+
+// Janela retorna a diferença de rating aceita para um jogador que espera na fila há 'espera'.
+func Janela(espera time.Duration) int {
+	if espera < 0 {
+		espera = 0
+	}
+	janela := JANELA_INICIAL + JANELA_INCREMENTO*int(espera/JANELA_INTERVALO)
+	if janela > JANELA_MAXIMA {
+		return JANELA_MAXIMA
+	}
+	return janela
+}
+
+// Compativeis indica se dois ratings podem se enfrentar com a janela dada.
+// Na janela máxima qualquer diferença é aceita, para ninguém esperar para sempre.
+func Compativeis(ratingA, ratingB, janela int) bool {
+	if janela >= JANELA_MAXIMA {
+		return true
+	}
+	diferenca := ratingA - ratingB
+	if diferenca < 0 {
+		diferenca = -diferenca
+	}
+	return diferenca <= janela
+}
@@ -0,0 +1,92 @@
+package ranking
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEsperado(t *testing.T) {
+	casos := []struct {
+		ratingA, ratingB int
+		esperado         float64
+	}{
+		{1200, 1200, 0.5},
+		{1600, 1200, 1 / 1.1}, // 400 pontos de vantagem: 10 para 1
+		{1200, 1600, 1 / 11.0},
+	}
+	for _, c := range casos {
+		if esperado := Esperado(c.ratingA, c.ratingB); abs(esperado-c.esperado) > 1e-9 {
+			t.Errorf("Esperado(%d, %d) = %f, esperado %f", c.ratingA, c.ratingB, esperado, c.esperado)
+		}
+	}
+}
+
+func TestAtualizar(t *testing.T) {
+	casos := []struct {
+		nome             string
+		ratingA, ratingB int
+		resultadoA       float64
+		novoA, novoB     int
+	}{
+		{"vitória entre iguais", 1200, 1200, 1, 1216, 1184},
+		{"derrota entre iguais", 1200, 1200, 0, 1184, 1216},
+		{"empate entre iguais", 1200, 1200, 0.5, 1200, 1200},
+		{"favorito vence", 1600, 1200, 1, 1603, 1197},
+		{"zebra", 1200, 1600, 1, 1229, 1571},
+		{"empate favorece o mais fraco", 1600, 1200, 0.5, 1587, 1213},
+	}
+	for _, c := range casos {
+		novoA, novoB := Atualizar(c.ratingA, c.ratingB, c.resultadoA)
+		if novoA != c.novoA || novoB != c.novoB {
+			t.Errorf("%s: Atualizar(%d, %d, %v) = (%d, %d), esperado (%d, %d)",
+				c.nome, c.ratingA, c.ratingB, c.resultadoA, novoA, novoB, c.novoA, c.novoB)
+		}
+		if novoA+novoB != c.ratingA+c.ratingB {
+			t.Errorf("%s: a soma dos ratings mudou", c.nome)
+		}
+	}
+}
+
+func TestJanela(t *testing.T) {
+	casos := []struct {
+		espera time.Duration
+		janela int
+	}{
+		{-time.Second, JANELA_INICIAL},
+		{0, JANELA_INICIAL},
+		{JANELA_INTERVALO - time.Millisecond, JANELA_INICIAL},
+		{JANELA_INTERVALO, JANELA_INICIAL + JANELA_INCREMENTO},
+		{5 * JANELA_INTERVALO, JANELA_INICIAL + 5*JANELA_INCREMENTO},
+		{time.Hour, JANELA_MAXIMA},
+	}
+	for _, c := range casos {
+		if janela := Janela(c.espera); janela != c.janela {
+			t.Errorf("Janela(%v) = %d, esperado %d", c.espera, janela, c.janela)
+		}
+	}
+}
+
+func TestCompativeis(t *testing.T) {
+	casos := []struct {
+		ratingA, ratingB, janela int
+		compativeis              bool
+	}{
+		{1200, 1300, 100, true},
+		{1300, 1200, 100, true},
+		{1200, 1301, 100, false},
+		{1200, 1301, 150, true},
+		{800, 2500, JANELA_MAXIMA, true},
+	}
+	for _, c := range casos {
+		if compativeis := Compativeis(c.ratingA, c.ratingB, c.janela); compativeis != c.compativeis {
+			t.Errorf("Compativeis(%d, %d, %d) = %v, esperado %v", c.ratingA, c.ratingB, c.janela, compativeis, c.compativeis)
+		}
+	}
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
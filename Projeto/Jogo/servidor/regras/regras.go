@@ -0,0 +1,319 @@
+package regras
+
+import (
+	"fmt"
+	"jogodistribuido/servidor/tipos"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Variantes de regras disponíveis
+const (
+	CLASSICO  = "CLASSICO"  // Maior valor vence; empate decidido pelo naipe
+	TRUNFO    = "TRUNFO"    // Um naipe sorteado na criação da sala vence qualquer outro
+	RARIDADE  = "RARIDADE"  // Cartas raras recebem bônus de valor
+	ALEATORIA = "ALEATORIA" // Sorteia uma das variantes acima para cada sala
+
+	FORMATO_LIVRE = "LIVRE" // Formato original: uma jogada por rodada, até acabarem as cartas
+)
+
+// Ordem de força dos naipes, usada para desempate: Espadas > Copas > Ouros > Paus
+var forcaNaipe = map[string]int{"♠": 4, "♥": 3, "♦": 2, "♣": 1}
+
+// Placar resume o andamento da partida para as regras de fim de rodada e de partida.
+type Placar struct {
+	PontosRodada    map[string]int // nome -> jogadas vencidas na rodada atual
+	PontosPartida   map[string]int // nome -> rodadas vencidas
+	CartasRestantes map[string]int // nome -> cartas ainda na mão (nil = desconhecido)
+	JogadasNaRodada int            // Jogadas já resolvidas na rodada atual
+	RodadasJogadas  int            // Rodadas já encerradas na partida
+	Formato         Formato
+}
+
+// Formato define a estrutura de uma partida: quantas jogadas formam uma rodada e quantas rodadas
+// formam a partida. O formato zero é o original: cada jogada é uma rodada e a partida vai até
+// acabarem as cartas.
+type Formato struct {
+	CartasPorRodada int  // Jogadas por rodada (0 = uma)
+	Rodadas         int  // Rodadas da partida (0 = até acabarem as cartas)
+	MelhorDe        bool // Encerra assim que um jogador vence a maioria das Rodadas
+}
+
+// NovoFormato interpreta FORMATO_PARTIDA: "" (livre), "<rodadas>x<cartas>" (ex: "3x2", sempre 3 rodadas
+// de 2 jogadas) ou "MD<rodadas>x<cartas>" (ex: "MD3x1", melhor de 3 com uma jogada por rodada).
+func NovoFormato(id string) (Formato, error) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if id == "" || id == FORMATO_LIVRE {
+		return Formato{}, nil
+	}
+	var formato Formato
+	if strings.HasPrefix(id, "MD") {
+		formato.MelhorDe = true
+		id = strings.TrimPrefix(id, "MD")
+	}
+	rodadas, cartas, ok := strings.Cut(id, "X")
+	if !ok {
+		cartas = "1"
+	}
+	var errRodadas, errCartas error
+	formato.Rodadas, errRodadas = strconv.Atoi(rodadas)
+	formato.CartasPorRodada, errCartas = strconv.Atoi(cartas)
+	if errRodadas != nil || errCartas != nil || formato.Rodadas < 1 || formato.CartasPorRodada < 1 {
+		return Formato{}, fmt.Errorf("formato de partida inválido: %s", id)
+	}
+	if formato.MelhorDe && formato.Rodadas%2 == 0 {
+		return Formato{}, fmt.Errorf("formato melhor-de precisa de um número ímpar de rodadas: %d", formato.Rodadas)
+	}
+	return formato, nil
+}
+
+// ObterFormato reconstrói o formato guardado na sala. IDs vazios ou inválidos resultam no formato livre.
+func ObterFormato(id string) Formato {
+	formato, err := NovoFormato(id)
+	if err != nil {
+		log.Printf("[REGRAS_AVISO] %v. Usando formato livre.", err)
+		return Formato{}
+	}
+	return formato
+}
+
+// ID é o valor guardado em tipos.Sala.Formato
+func (f Formato) ID() string {
+	if f.Rodadas == 0 {
+		return FORMATO_LIVRE
+	}
+	prefixo := ""
+	if f.MelhorDe {
+		prefixo = "MD"
+	}
+	return fmt.Sprintf("%s%dx%d", prefixo, f.Rodadas, f.CartasPorRodada)
+}
+
+// Descricao é o texto mostrado aos jogadores no início da partida
+func (f Formato) Descricao() string {
+	if f.Rodadas == 0 {
+		return "uma jogada por rodada, até acabarem as cartas"
+	}
+	jogadas := "1 jogada"
+	if f.CartasPorRodada > 1 {
+		jogadas = fmt.Sprintf("%d jogadas", f.CartasPorRodada)
+	}
+	if f.MelhorDe {
+		return fmt.Sprintf("melhor de %d rodadas de %s", f.Rodadas, jogadas)
+	}
+	return fmt.Sprintf("%d rodadas de %s", f.Rodadas, jogadas)
+}
+
+// RuleSet define as regras de uma variante do jogo. O Host consulta o RuleSet da sala
+// a cada jogada; a Sombra usa o mesmo RuleSet se for promovida a Host.
+type RuleSet interface {
+	// ID identifica a variante e seus parâmetros (ex: "TRUNFO:♥"); é o valor guardado em tipos.Sala.Regras
+	ID() string
+	// Descricao é o texto mostrado aos jogadores no início da partida
+	Descricao() string
+	// CompararCartas retorna > 0 se c1 vence, < 0 se c2 vence e 0 em caso de empate
+	CompararCartas(c1, c2 tipos.Carta) int
+	// ProximoTurno define quem joga depois que uma jogada é resolvida (vencedorID vazio = empate)
+	ProximoTurno(ordem []string, turnoAtual, vencedorID string) string
+	// FimDeRodada indica se a jogada recém-resolvida encerrou a rodada
+	FimDeRodada(placar Placar) bool
+	// VencedorRodada retorna o nome de quem venceu a rodada encerrada ou "EMPATE"
+	VencedorRodada(placar Placar) string
+	// FimDePartida indica se a partida terminou (avaliada depois do fechamento da rodada)
+	FimDePartida(placar Placar) bool
+	// VencedorPartida retorna o nome do vencedor ou "EMPATE"
+	VencedorPartida(placar Placar) string
+}
+
+// Nova cria o RuleSet de uma nova sala. Parâmetros sorteados (como o naipe de trunfo)
+// ficam fixos no ID retornado pelo RuleSet.
+func Nova(variante string) (RuleSet, error) {
+	variante = strings.ToUpper(strings.TrimSpace(variante))
+	if variante == ALEATORIA {
+		opcoes := []string{CLASSICO, TRUNFO, RARIDADE}
+		variante = opcoes[rand.Intn(len(opcoes))]
+	}
+
+	switch variante {
+	case "", CLASSICO:
+		return Classico{}, nil
+	case TRUNFO:
+		naipes := []string{"♠", "♥", "♦", "♣"}
+		return Trunfo{Naipe: naipes[rand.Intn(len(naipes))]}, nil
+	case RARIDADE:
+		return Raridade{}, nil
+	default:
+		return nil, fmt.Errorf("variante de regras desconhecida: %s", variante)
+	}
+}
+
+// Obter reconstrói o RuleSet a partir do ID guardado na sala. IDs vazios ou
+// desconhecidos resultam nas regras clássicas.
+func Obter(id string) RuleSet {
+	variante, parametro, _ := strings.Cut(id, ":")
+	switch variante {
+	case "", CLASSICO:
+		return Classico{}
+	case TRUNFO:
+		if _, ok := forcaNaipe[parametro]; ok {
+			return Trunfo{Naipe: parametro}
+		}
+	case RARIDADE:
+		return Raridade{}
+	}
+	log.Printf("[REGRAS_AVISO] Regras '%s' desconhecidas. Usando regras clássicas.", id)
+	return Classico{}
+}
+
+/* ===================== Clássico ===================== */
+
+// Classico é a regra original do jogo e a base das demais variantes.
+type Classico struct{}
+
+func (Classico) ID() string { return CLASSICO }
+
+func (Classico) Descricao() string {
+	return "Clássico (maior valor vence; empate pelo naipe ♠ > ♥ > ♦ > ♣)"
+}
+
+func (Classico) CompararCartas(c1, c2 tipos.Carta) int {
+	if c1.Valor != c2.Valor {
+		return c1.Valor - c2.Valor
+	}
+	return forcaNaipe[c1.Naipe] - forcaNaipe[c2.Naipe]
+}
+
+// ProximoTurno: o vencedor da jogada começa a próxima; em empate, o turno não muda.
+func (Classico) ProximoTurno(ordem []string, turnoAtual, vencedorID string) string {
+	if vencedorID != "" {
+		return vencedorID
+	}
+	return turnoAtual
+}
+
+// FimDeRodada: a rodada termina após Formato.CartasPorRodada jogadas (uma no formato livre)
+// ou quando os jogadores ficam sem cartas.
+func (Classico) FimDeRodada(placar Placar) bool {
+	if placar.Formato.CartasPorRodada <= 1 || semCartas(placar) {
+		return true
+	}
+	return placar.JogadasNaRodada >= placar.Formato.CartasPorRodada
+}
+
+// VencedorRodada: vence a rodada quem ganhou mais jogadas nela.
+func (Classico) VencedorRodada(placar Placar) string {
+	return maisPontos(placar.PontosRodada)
+}
+
+// FimDePartida: a partida termina quando todos os jogadores ficam sem cartas, quando todas as
+// rodadas do formato foram jogadas ou, no melhor-de, quando alguém já tem a maioria das rodadas.
+func (Classico) FimDePartida(placar Placar) bool {
+	if semCartas(placar) {
+		return true
+	}
+	formato := placar.Formato
+	if formato.Rodadas == 0 {
+		return false
+	}
+	if placar.RodadasJogadas >= formato.Rodadas {
+		return true
+	}
+	if formato.MelhorDe {
+		for _, rodadas := range placar.PontosPartida {
+			if rodadas > formato.Rodadas/2 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// VencedorPartida: vence quem ganhou mais rodadas.
+func (Classico) VencedorPartida(placar Placar) string {
+	return maisPontos(placar.PontosPartida)
+}
+
+// semCartas indica se todos os jogadores ficaram sem cartas (falso se as mãos não são conhecidas).
+func semCartas(placar Placar) bool {
+	if len(placar.CartasRestantes) == 0 {
+		return false
+	}
+	for _, cartas := range placar.CartasRestantes {
+		if cartas > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// maisPontos retorna o nome com mais pontos ou "EMPATE".
+func maisPontos(pontos map[string]int) string {
+	vencedor := "EMPATE"
+	maxPontos := -1
+	for nome, p := range pontos {
+		if p > maxPontos {
+			maxPontos = p
+			vencedor = nome
+		} else if p == maxPontos {
+			vencedor = "EMPATE"
+		}
+	}
+	if maxPontos <= 0 {
+		return "EMPATE"
+	}
+	return vencedor
+}
+
+/* ===================== Trunfo ===================== */
+
+// Trunfo: cartas do naipe de trunfo vencem as de qualquer outro naipe.
+// Entre duas cartas de trunfo (ou duas sem trunfo), vale a regra clássica.
+type Trunfo struct {
+	Classico
+	Naipe string
+}
+
+func (t Trunfo) ID() string { return TRUNFO + ":" + t.Naipe }
+
+func (t Trunfo) Descricao() string {
+	return fmt.Sprintf("Trunfo (cartas de %s vencem qualquer outro naipe)", t.Naipe)
+}
+
+func (t Trunfo) CompararCartas(c1, c2 tipos.Carta) int {
+	trunfo1 := c1.Naipe == t.Naipe
+	trunfo2 := c2.Naipe == t.Naipe
+	if trunfo1 && !trunfo2 {
+		return 1
+	}
+	if trunfo2 && !trunfo1 {
+		return -1
+	}
+	return t.Classico.CompararCartas(c1, c2)
+}
+
+/* ===================== Raridade ===================== */
+
+// Bônus de valor por raridade (C=Comum, U=Incomum, R=Rara, L=Lendária)
+var bonusRaridade = map[string]int{"C": 0, "U": 1, "R": 3, "L": 5}
+
+// Raridade: o valor de cada carta recebe um bônus conforme sua raridade.
+type Raridade struct {
+	Classico
+}
+
+func (Raridade) ID() string { return RARIDADE }
+
+func (Raridade) Descricao() string {
+	return "Raridade (bônus de valor: Incomum +1, Rara +3, Lendária +5)"
+}
+
+func (r Raridade) CompararCartas(c1, c2 tipos.Carta) int {
+	v1 := c1.Valor + bonusRaridade[c1.Raridade]
+	v2 := c2.Valor + bonusRaridade[c2.Raridade]
+	if v1 != v2 {
+		return v1 - v2
+	}
+	return r.Classico.CompararCartas(c1, c2)
+}
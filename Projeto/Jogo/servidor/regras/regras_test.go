@@ -0,0 +1,223 @@
+package regras
+
+import (
+	"jogodistribuido/servidor/tipos"
+	"testing"
+)
+
+func TestNovoFormato(t *testing.T) {
+	casos := []struct {
+		id      string
+		formato Formato
+		idFinal string
+		erro    bool
+	}{
+		{id: "", formato: Formato{}, idFinal: FORMATO_LIVRE},
+		{id: " livre ", formato: Formato{}, idFinal: FORMATO_LIVRE},
+		{id: "3x2", formato: Formato{Rodadas: 3, CartasPorRodada: 2}, idFinal: "3x2"},
+		{id: "5", formato: Formato{Rodadas: 5, CartasPorRodada: 1}, idFinal: "5x1"},
+		{id: "md3x1", formato: Formato{Rodadas: 3, CartasPorRodada: 1, MelhorDe: true}, idFinal: "MD3x1"},
+		{id: "MD5", formato: Formato{Rodadas: 5, CartasPorRodada: 1, MelhorDe: true}, idFinal: "MD5x1"},
+		{id: "MD4x1", erro: true}, // melhor-de com número par de rodadas
+		{id: "0x2", erro: true},
+		{id: "3x0", erro: true},
+		{id: "ax2", erro: true},
+		{id: "3x", erro: true},
+	}
+	for _, c := range casos {
+		formato, err := NovoFormato(c.id)
+		if c.erro {
+			if err == nil {
+				t.Errorf("NovoFormato(%q) = %+v, esperado erro", c.id, formato)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NovoFormato(%q): %v", c.id, err)
+			continue
+		}
+		if formato != c.formato {
+			t.Errorf("NovoFormato(%q) = %+v, esperado %+v", c.id, formato, c.formato)
+		}
+		if formato.ID() != c.idFinal {
+			t.Errorf("NovoFormato(%q).ID() = %q, esperado %q", c.id, formato.ID(), c.idFinal)
+		}
+		if ObterFormato(formato.ID()) != formato {
+			t.Errorf("ObterFormato(%q) não reconstrói %+v", formato.ID(), formato)
+		}
+	}
+}
+
+func TestObterFormatoInvalidoUsaLivre(t *testing.T) {
+	if formato := ObterFormato("MD2x1"); formato != (Formato{}) {
+		t.Errorf("ObterFormato(MD2x1) = %+v, esperado o formato livre", formato)
+	}
+}
+
+func TestFormatoDescricao(t *testing.T) {
+	casos := []struct {
+		formato   Formato
+		descricao string
+	}{
+		{Formato{}, "uma jogada por rodada, até acabarem as cartas"},
+		{Formato{Rodadas: 3, CartasPorRodada: 1}, "3 rodadas de 1 jogada"},
+		{Formato{Rodadas: 3, CartasPorRodada: 2}, "3 rodadas de 2 jogadas"},
+		{Formato{Rodadas: 5, CartasPorRodada: 1, MelhorDe: true}, "melhor de 5 rodadas de 1 jogada"},
+	}
+	for _, c := range casos {
+		if descricao := c.formato.Descricao(); descricao != c.descricao {
+			t.Errorf("%s: Descricao() = %q, esperado %q", c.formato.ID(), descricao, c.descricao)
+		}
+	}
+}
+
+func TestFimDeRodada(t *testing.T) {
+	casos := []struct {
+		nome   string
+		placar Placar
+		fim    bool
+	}{
+		{"livre encerra a cada jogada", Placar{JogadasNaRodada: 1}, true},
+		{"rodada de 2 após 1 jogada", Placar{JogadasNaRodada: 1, Formato: Formato{Rodadas: 3, CartasPorRodada: 2}}, false},
+		{"rodada de 2 após 2 jogadas", Placar{JogadasNaRodada: 2, Formato: Formato{Rodadas: 3, CartasPorRodada: 2}}, true},
+		{"sem cartas encerra antes", Placar{
+			JogadasNaRodada: 1,
+			CartasRestantes: map[string]int{"ana": 0, "bia": 0},
+			Formato:         Formato{Rodadas: 3, CartasPorRodada: 3},
+		}, true},
+	}
+	for _, c := range casos {
+		if fim := (Classico{}).FimDeRodada(c.placar); fim != c.fim {
+			t.Errorf("%s: FimDeRodada = %v, esperado %v", c.nome, fim, c.fim)
+		}
+	}
+}
+
+func TestFimDePartida(t *testing.T) {
+	md3 := Formato{Rodadas: 3, CartasPorRodada: 1, MelhorDe: true}
+	casos := []struct {
+		nome   string
+		placar Placar
+		fim    bool
+	}{
+		{"livre com cartas na mão", Placar{CartasRestantes: map[string]int{"ana": 2, "bia": 2}}, false},
+		{"livre sem cartas", Placar{CartasRestantes: map[string]int{"ana": 0, "bia": 0}}, true},
+		{"mãos desconhecidas", Placar{}, false},
+		{"todas as rodadas jogadas", Placar{RodadasJogadas: 3, Formato: Formato{Rodadas: 3, CartasPorRodada: 1}}, true},
+		{"rodadas restantes", Placar{RodadasJogadas: 2, Formato: Formato{Rodadas: 3, CartasPorRodada: 1}}, false},
+		{"melhor de 3 com maioria", Placar{RodadasJogadas: 2, PontosPartida: map[string]int{"ana": 2}, Formato: md3}, true},
+		{"melhor de 3 sem maioria", Placar{RodadasJogadas: 2, PontosPartida: map[string]int{"ana": 1, "bia": 1}, Formato: md3}, false},
+	}
+	for _, c := range casos {
+		if fim := (Classico{}).FimDePartida(c.placar); fim != c.fim {
+			t.Errorf("%s: FimDePartida = %v, esperado %v", c.nome, fim, c.fim)
+		}
+	}
+}
+
+func TestVencedor(t *testing.T) {
+	casos := []struct {
+		pontos   map[string]int
+		vencedor string
+	}{
+		{map[string]int{"ana": 2, "bia": 1}, "ana"},
+		{map[string]int{"ana": 1, "bia": 1}, "EMPATE"},
+		{map[string]int{"ana": 0, "bia": 0}, "EMPATE"},
+		{nil, "EMPATE"},
+	}
+	for _, c := range casos {
+		if vencedor := (Classico{}).VencedorPartida(Placar{PontosPartida: c.pontos}); vencedor != c.vencedor {
+			t.Errorf("VencedorPartida(%v) = %q, esperado %q", c.pontos, vencedor, c.vencedor)
+		}
+		if vencedor := (Classico{}).VencedorRodada(Placar{PontosRodada: c.pontos}); vencedor != c.vencedor {
+			t.Errorf("VencedorRodada(%v) = %q, esperado %q", c.pontos, vencedor, c.vencedor)
+		}
+	}
+}
+
+func TestCompararCartas(t *testing.T) {
+	carta := func(valor int, naipe, raridade string) tipos.Carta {
+		return tipos.Carta{Valor: valor, Naipe: naipe, Raridade: raridade}
+	}
+	casos := []struct {
+		nome   string
+		regras RuleSet
+		c1, c2 tipos.Carta
+		sinal  int // 1: c1 vence, -1: c2 vence, 0: empate
+	}{
+		{"clássico maior valor", Classico{}, carta(10, "♣", "C"), carta(9, "♠", "C"), 1},
+		{"clássico desempate pelo naipe", Classico{}, carta(7, "♥", "C"), carta(7, "♠", "C"), -1},
+		{"clássico empate", Classico{}, carta(7, "♦", "C"), carta(7, "♦", "L"), 0},
+		{"trunfo vence valor maior", Trunfo{Naipe: "♣"}, carta(2, "♣", "C"), carta(13, "♠", "C"), 1},
+		{"trunfo contra trunfo", Trunfo{Naipe: "♣"}, carta(2, "♣", "C"), carta(3, "♣", "C"), -1},
+		{"sem trunfo usa clássico", Trunfo{Naipe: "♣"}, carta(9, "♥", "C"), carta(8, "♠", "C"), 1},
+		{"raridade soma bônus", Raridade{}, carta(8, "♣", "L"), carta(12, "♠", "C"), 1},
+		{"raridade empata no valor e usa o naipe", Raridade{}, carta(9, "♠", "U"), carta(7, "♣", "R"), 1},
+	}
+	for _, c := range casos {
+		resultado := c.regras.CompararCartas(c.c1, c.c2)
+		if sinal(resultado) != c.sinal {
+			t.Errorf("%s: CompararCartas = %d, esperado sinal %d", c.nome, resultado, c.sinal)
+		}
+	}
+}
+
+func TestProximoTurno(t *testing.T) {
+	ordem := []string{"a", "b"}
+	if turno := (Classico{}).ProximoTurno(ordem, "a", "b"); turno != "b" {
+		t.Errorf("ProximoTurno com vencedor = %q, esperado b", turno)
+	}
+	if turno := (Classico{}).ProximoTurno(ordem, "a", ""); turno != "a" {
+		t.Errorf("ProximoTurno em empate = %q, esperado a", turno)
+	}
+}
+
+func TestNovaEObter(t *testing.T) {
+	casos := []struct {
+		variante string
+		prefixo  string
+		erro     bool
+	}{
+		{"", CLASSICO, false},
+		{"classico", CLASSICO, false},
+		{"TRUNFO", TRUNFO + ":", false},
+		{"RARIDADE", RARIDADE, false},
+		{"ALEATORIA", "", false},
+		{"POKER", "", true},
+	}
+	for _, c := range casos {
+		regras, err := Nova(c.variante)
+		if c.erro {
+			if err == nil {
+				t.Errorf("Nova(%q) = %s, esperado erro", c.variante, regras.ID())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Nova(%q): %v", c.variante, err)
+			continue
+		}
+		if len(regras.ID()) < len(c.prefixo) || regras.ID()[:len(c.prefixo)] != c.prefixo {
+			t.Errorf("Nova(%q).ID() = %q, esperado prefixo %q", c.variante, regras.ID(), c.prefixo)
+		}
+		if Obter(regras.ID()).ID() != regras.ID() {
+			t.Errorf("Obter(%q) não reconstrói as regras", regras.ID())
+		}
+	}
+
+	for _, id := range []string{"TRUNFO:X", "POKER", "TRUNFO"} {
+		if regras := Obter(id); regras.ID() != CLASSICO {
+			t.Errorf("Obter(%q) = %q, esperado %s", id, regras.ID(), CLASSICO)
+		}
+	}
+}
+
+func sinal(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
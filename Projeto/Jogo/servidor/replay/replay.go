@@ -0,0 +1,430 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"jogodistribuido/servidor/regras"
+	"jogodistribuido/servidor/seguranca"
+	"jogodistribuido/servidor/tipos"
+	"reflect"
+	"sort"
+)
+
+// Tipos de evento registrados pelo Host no EventLog da sala
+const (
+	EVENTO_INICIO_PARTIDA = "MATCH_START"
+	EVENTO_FIM_PARTIDA    = "MATCH_END"
+	EVENTO_PRONTO         = "PLAYER_READY"
+	EVENTO_CARTA_JOGADA   = "CARD_PLAYED"
+	EVENTO_JOGAR_CARTA    = "JOGAR_CARTA"
+	EVENTO_ABANDONO       = "PLAYER_FORFEIT"
+	EVENTO_FIM_RODADA     = "ROUND_END"
+)
+
+// Exportacao é o log de uma partida exportado pelo servidor (GET /partida/exportar/:salaID)
+// junto com o estado final que o servidor tinha da sala.
+type Exportacao struct {
+	SalaID      string              `json:"sala_id"`
+	Regras      string              `json:"regras"`
+	Formato     string              `json:"formato,omitempty"`
+	Jogadores   []tipos.Player      `json:"jogadores"`
+	EventLog    []tipos.GameEvent   `json:"eventLog"`
+	EstadoFinal tipos.EstadoPartida `json:"estado_final"`
+}
+
+// Passo é o estado da partida logo após a aplicação de um evento.
+type Passo struct {
+	Evento           tipos.GameEvent     `json:"evento"`
+	AssinaturaValida bool                `json:"assinatura_valida"` // HMAC do servidor
+	JogadaAssinada   bool                `json:"jogada_assinada"`   // Jogada conferida contra a carteira do jogador
+	Estado           tipos.EstadoPartida `json:"estado"`
+	Observacao       string              `json:"observacao,omitempty"`
+}
+
+// Divergencia descreve o primeiro ponto em que o log não confere.
+// EventSeq 0 indica divergência entre o estado reconstruído e o estado final exportado.
+type Divergencia struct {
+	EventSeq int64  `json:"eventSeq"`
+	Campo    string `json:"campo"`
+	Esperado string `json:"esperado"`
+	Obtido   string `json:"obtido"`
+	Motivo   string `json:"motivo"`
+}
+
+func (d Divergencia) String() string {
+	if d.EventSeq == 0 {
+		return fmt.Sprintf("estado final diverge em '%s': esperado %s, reconstruído %s (%s)", d.Campo, d.Esperado, d.Obtido, d.Motivo)
+	}
+	return fmt.Sprintf("evento #%d diverge em '%s': esperado %s, obtido %s (%s)", d.EventSeq, d.Campo, d.Esperado, d.Obtido, d.Motivo)
+}
+
+// Resultado é a reconstrução completa de uma partida.
+type Resultado struct {
+	Passos      []Passo             `json:"passos"`
+	EstadoFinal tipos.EstadoPartida `json:"estado_final"`
+	Divergencia *Divergencia        `json:"divergencia,omitempty"`
+	HashLog     string              `json:"hashLog,omitempty"` // keccak256 do EventLog até MATCH_END, o hashLog registrado no contrato
+}
+
+// reconstrucao guarda o estado da sala enquanto os eventos são reaplicados.
+type reconstrucao struct {
+	salaID          string
+	regras          regras.RuleSet
+	formato         regras.Formato
+	ordem           []string          // IDs dos jogadores, na ordem da sala
+	nomes           map[string]string // ID -> nome
+	enderecos       map[string]string // ID -> carteira que assina as jogadas ("" sem carteira)
+	estado          string
+	turnoDe         string
+	numeroRodada    int
+	cartasNaMesa    map[string]tipos.Carta
+	pontosRodada    map[string]int
+	pontosPartida   map[string]int
+	jogadasNaRodada int
+	prontos         map[string]bool
+	eventSeq        int64
+	eventLog        []tipos.GameEvent
+	abandono        string // ID do jogador que perdeu por W.O.
+}
+
+// Reproduzir reaplica o EventLog exportado, verificando a assinatura HMAC de cada evento e, nas
+// jogadas, a assinatura da carteira do jogador, e compara o estado reconstruído com o estado final
+// exportado. A reconstrução segue as mesmas regras que o Host usa em processarEventoComoHost/resolverJogada.
+func Reproduzir(exp Exportacao) (*Resultado, error) {
+	if len(exp.Jogadores) < 2 {
+		return nil, fmt.Errorf("exportação da sala %s precisa de dois jogadores (tem %d)", exp.SalaID, len(exp.Jogadores))
+	}
+
+	r := &reconstrucao{
+		salaID:        exp.SalaID,
+		regras:        regras.Obter(exp.Regras),
+		formato:       regras.ObterFormato(exp.Formato),
+		nomes:         make(map[string]string),
+		enderecos:     make(map[string]string),
+		estado:        "AGUARDANDO_COMPRA",
+		numeroRodada:  1,
+		cartasNaMesa:  make(map[string]tipos.Carta),
+		pontosRodada:  make(map[string]int),
+		pontosPartida: make(map[string]int),
+		prontos:       make(map[string]bool),
+	}
+	for _, jogador := range exp.Jogadores {
+		r.ordem = append(r.ordem, jogador.ID)
+		r.nomes[jogador.ID] = jogador.Nome
+		r.enderecos[jogador.ID] = jogador.Endereco
+	}
+
+	resultado := &Resultado{Passos: make([]Passo, 0, len(exp.EventLog))}
+	for i := range exp.EventLog {
+		evento := exp.EventLog[i]
+		passo := Passo{Evento: evento, AssinaturaValida: seguranca.VerifyEventSignature(&evento)}
+		erroJogada := seguranca.VerificarAssinaturaJogador(&evento, r.enderecos[evento.PlayerID])
+		passo.JogadaAssinada = erroJogada == nil && r.enderecos[evento.PlayerID] != "" && seguranca.JogadaDoJogador(&evento)
+
+		var div *Divergencia
+		if !passo.AssinaturaValida {
+			div = &Divergencia{EventSeq: evento.EventSeq, Campo: "signature", Esperado: "assinatura HMAC válida", Obtido: evento.Signature, Motivo: "assinatura inválida"}
+		} else if erroJogada != nil {
+			div = &Divergencia{EventSeq: evento.EventSeq, Campo: "assinaturaJogador", Esperado: "jogada assinada pela carteira " + r.enderecos[evento.PlayerID], Obtido: evento.AssinaturaJogador, Motivo: erroJogada.Error()}
+		} else if evento.MatchID != exp.SalaID {
+			div = &Divergencia{EventSeq: evento.EventSeq, Campo: "matchId", Esperado: exp.SalaID, Obtido: evento.MatchID, Motivo: "evento de outra partida"}
+		} else if evento.EventSeq <= r.eventSeq {
+			div = &Divergencia{EventSeq: evento.EventSeq, Campo: "eventSeq", Esperado: fmt.Sprintf("> %d", r.eventSeq), Obtido: fmt.Sprint(evento.EventSeq), Motivo: "sequência fora de ordem ou duplicada"}
+		} else {
+			proximo := ""
+			if i+1 < len(exp.EventLog) {
+				proximo = exp.EventLog[i+1].EventType
+			}
+			passo.Observacao, div = r.aplicar(evento, proximo)
+		}
+
+		passo.Estado = r.snapshot()
+		resultado.Passos = append(resultado.Passos, passo)
+		if div != nil {
+			resultado.Divergencia = div
+			resultado.EstadoFinal = passo.Estado
+			return resultado, nil
+		}
+	}
+
+	resultado.EstadoFinal = r.snapshot()
+	resultado.Divergencia = compararEstados(exp.EstadoFinal, resultado.EstadoFinal)
+	if hashLog, err := seguranca.HashEventLog(exp.EventLog); err == nil {
+		resultado.HashLog = hashLog.Hex()
+	}
+	return resultado, nil
+}
+
+// aplicar reaplica um evento já validado. proximo é o tipo do evento seguinte no log ("" no último).
+// Retorna uma observação opcional e a divergência, se houver.
+func (r *reconstrucao) aplicar(evento tipos.GameEvent, proximo string) (string, *Divergencia) {
+	r.eventSeq = evento.EventSeq
+	r.eventLog = append(r.eventLog, evento)
+
+	nome, jogadorConhecido := r.nomes[evento.PlayerID]
+
+	switch evento.EventType {
+	case EVENTO_INICIO_PARTIDA:
+		r.estado = "JOGANDO"
+		r.turnoDe = evento.PlayerID
+		return "", nil
+
+	case EVENTO_FIM_PARTIDA:
+		r.estado = "FINALIZADO"
+		dados, _ := evento.Data.(map[string]interface{})
+		vencedorLog, _ := dados["vencedor"].(string)
+		vencedor := r.regras.VencedorPartida(regras.Placar{PontosPartida: r.pontosPartida})
+		if r.abandono != "" {
+			for _, id := range r.ordem {
+				if id != r.abandono {
+					vencedor = r.nomes[id]
+				}
+			}
+		}
+		if vencedorLog != "" && vencedorLog != vencedor {
+			return "", &Divergencia{EventSeq: evento.EventSeq, Campo: "vencedor", Esperado: vencedorLog, Obtido: vencedor, Motivo: "vencedor registrado não confere com o placar reconstruído"}
+		}
+		return "Vencedor: " + vencedor, nil
+
+	case EVENTO_FIM_RODADA:
+		return r.fecharRodada(evento, proximo)
+
+	case EVENTO_ABANDONO:
+		if !jogadorConhecido {
+			return "", divergenciaJogador(evento)
+		}
+		r.abandono = evento.PlayerID
+		return nome + " perdeu por W.O.", nil
+
+	case EVENTO_PRONTO:
+		if !jogadorConhecido {
+			return "", divergenciaJogador(evento)
+		}
+		r.prontos[nome] = true
+		return "", nil
+
+	case EVENTO_CARTA_JOGADA, EVENTO_JOGAR_CARTA:
+		if !jogadorConhecido {
+			return "", divergenciaJogador(evento)
+		}
+		carta, ok := cartaDoEvento(evento)
+		if !ok {
+			// O Host registra o evento antes de validar a carta; jogadas recusadas ficam sem "carta"
+			return "jogada recusada pelo Host (sem efeito)", nil
+		}
+		if r.estado != "JOGANDO" {
+			return "", &Divergencia{EventSeq: evento.EventSeq, Campo: "estado", Esperado: "JOGANDO", Obtido: r.estado, Motivo: "carta jogada fora de uma partida em andamento"}
+		}
+		if evento.PlayerID != r.turnoDe {
+			return "", &Divergencia{EventSeq: evento.EventSeq, Campo: "turnoDe", Esperado: r.turnoDe, Obtido: evento.PlayerID, Motivo: "jogada fora de turno"}
+		}
+		if _, jaJogou := r.cartasNaMesa[nome]; jaJogou {
+			return "", &Divergencia{EventSeq: evento.EventSeq, Campo: "cartas_na_mesa", Esperado: "uma carta por jogador", Obtido: "segunda carta de " + nome, Motivo: "jogador jogou duas vezes na mesma jogada"}
+		}
+
+		r.cartasNaMesa[nome] = carta
+		if len(r.cartasNaMesa) < len(r.ordem) {
+			r.turnoDe = r.proximoSemCarta()
+			return "", nil
+		}
+		return r.resolverJogada(evento, proximo)
+
+	default:
+		// CHAT, SYNC_INVENTARIO etc. não alteram o estado da partida
+		return "", nil
+	}
+}
+
+// resolverJogada espelha Servidor.resolverJogada para partidas de dois jogadores.
+func (r *reconstrucao) resolverJogada(evento tipos.GameEvent, proximo string) (string, *Divergencia) {
+	id1, id2 := r.ordem[0], r.ordem[1]
+	c1 := r.cartasNaMesa[r.nomes[id1]]
+	c2 := r.cartasNaMesa[r.nomes[id2]]
+
+	vencedorID := ""
+	vencedorNome := "EMPATE"
+	if resultado := r.regras.CompararCartas(c1, c2); resultado > 0 {
+		vencedorID = id1
+	} else if resultado < 0 {
+		vencedorID = id2
+	}
+	if vencedorID != "" {
+		vencedorNome = r.nomes[vencedorID]
+		r.pontosRodada[vencedorNome]++
+	}
+	r.jogadasNaRodada++
+
+	r.cartasNaMesa = make(map[string]tipos.Carta)
+	r.turnoDe = r.regras.ProximoTurno(r.ordem, r.turnoDe, vencedorID)
+
+	// A quantidade de cartas na mão não está no log: sem cartas o Host encerra a rodada antes do
+	// previsto pelo formato, então só é divergência o formato exigir ROUND_END e o log não trazer.
+	if proximo != EVENTO_FIM_RODADA && r.regras.FimDeRodada(r.placar()) {
+		return "", &Divergencia{EventSeq: evento.EventSeq, Campo: "eventType", Esperado: EVENTO_FIM_RODADA, Obtido: proximo, Motivo: "o formato encerra a rodada nesta jogada"}
+	}
+	return "Jogada resolvida: " + vencedorNome, nil
+}
+
+// fecharRodada espelha Servidor.fecharRodada: soma a rodada ao vencedor e zera o placar da rodada.
+func (r *reconstrucao) fecharRodada(evento tipos.GameEvent, proximo string) (string, *Divergencia) {
+	placar := r.placar()
+	vencedor := r.regras.VencedorRodada(placar)
+	dados, _ := evento.Data.(map[string]interface{})
+	if vencedorLog, _ := dados["vencedor"].(string); vencedorLog != "" && vencedorLog != vencedor {
+		return "", &Divergencia{EventSeq: evento.EventSeq, Campo: "vencedor_rodada", Esperado: vencedorLog, Obtido: vencedor, Motivo: "vencedor da rodada registrado não confere com as jogadas reconstruídas"}
+	}
+	if vencedor != "EMPATE" {
+		r.pontosPartida[vencedor]++
+	}
+	rodada := r.numeroRodada
+	r.pontosRodada = make(map[string]int)
+	r.jogadasNaRodada = 0
+
+	placar = r.placar()
+	placar.RodadasJogadas = rodada
+	if proximo == EVENTO_FIM_PARTIDA {
+		return fmt.Sprintf("Rodada %d: %s", rodada, vencedor), nil
+	}
+	if r.regras.FimDePartida(placar) {
+		return "", &Divergencia{EventSeq: evento.EventSeq, Campo: "eventType", Esperado: EVENTO_FIM_PARTIDA, Obtido: proximo, Motivo: "o formato encerra a partida nesta rodada"}
+	}
+	r.numeroRodada++
+	return fmt.Sprintf("Rodada %d: %s", rodada, vencedor), nil
+}
+
+// placar monta o regras.Placar da reconstrução. As cartas na mão não estão no log.
+func (r *reconstrucao) placar() regras.Placar {
+	return regras.Placar{
+		PontosRodada:    r.pontosRodada,
+		PontosPartida:   r.pontosPartida,
+		JogadasNaRodada: r.jogadasNaRodada,
+		RodadasJogadas:  r.numeroRodada - 1,
+		Formato:         r.formato,
+	}
+}
+
+func (r *reconstrucao) proximoSemCarta() string {
+	for _, id := range r.ordem {
+		if _, jogou := r.cartasNaMesa[r.nomes[id]]; !jogou {
+			return id
+		}
+	}
+	return r.turnoDe
+}
+
+func (r *reconstrucao) snapshot() tipos.EstadoPartida {
+	mesa := make(map[string]tipos.Carta, len(r.cartasNaMesa))
+	for k, v := range r.cartasNaMesa {
+		mesa[k] = v
+	}
+	pontos := make(map[string]int, len(r.pontosRodada))
+	for k, v := range r.pontosRodada {
+		pontos[k] = v
+	}
+	pontosPartida := make(map[string]int, len(r.pontosPartida))
+	for k, v := range r.pontosPartida {
+		pontosPartida[k] = v
+	}
+	prontos := make(map[string]bool, len(r.prontos))
+	for k, v := range r.prontos {
+		prontos[k] = v
+	}
+	return tipos.EstadoPartida{
+		SalaID:          r.salaID,
+		Estado:          r.estado,
+		CartasNaMesa:    mesa,
+		PontosRodada:    pontos,
+		PontosPartida:   pontosPartida,
+		NumeroRodada:    r.numeroRodada,
+		Prontos:         prontos,
+		EventSeq:        r.eventSeq,
+		EventLog:        append([]tipos.GameEvent(nil), r.eventLog...),
+		TurnoDe:         r.turnoDe,
+		Regras:          r.regras.ID(),
+		Formato:         r.formato.ID(),
+		JogadasNaRodada: r.jogadasNaRodada,
+	}
+}
+
+// cartaDoEvento extrai a carta registrada pelo Host em um evento de jogada.
+func cartaDoEvento(evento tipos.GameEvent) (tipos.Carta, bool) {
+	dados, ok := evento.Data.(map[string]interface{})
+	if !ok {
+		return tipos.Carta{}, false
+	}
+	bruto, ok := dados["carta"]
+	if !ok {
+		return tipos.Carta{}, false
+	}
+	jsonCarta, err := json.Marshal(bruto)
+	if err != nil {
+		return tipos.Carta{}, false
+	}
+	var carta tipos.Carta
+	if err := json.Unmarshal(jsonCarta, &carta); err != nil || carta.ID == "" {
+		return tipos.Carta{}, false
+	}
+	return carta, true
+}
+
+func divergenciaJogador(evento tipos.GameEvent) *Divergencia {
+	return &Divergencia{EventSeq: evento.EventSeq, Campo: "playerId", Esperado: "jogador da sala", Obtido: evento.PlayerID, Motivo: "evento de jogador desconhecido"}
+}
+
+// compararEstados confere o estado reconstruído com o exportado, campo a campo.
+func compararEstados(esperado, obtido tipos.EstadoPartida) *Divergencia {
+	campos := []struct {
+		nome     string
+		esperado interface{}
+		obtido   interface{}
+	}{
+		{"estado", esperado.Estado, obtido.Estado},
+		{"eventSeq", esperado.EventSeq, obtido.EventSeq},
+		{"numero_rodada", esperado.NumeroRodada, obtido.NumeroRodada},
+		{"pontos_rodada", semZeros(esperado.PontosRodada), semZeros(obtido.PontosRodada)},
+		{"pontos_partida", semZeros(esperado.PontosPartida), semZeros(obtido.PontosPartida)},
+		{"turnoDe", esperado.TurnoDe, obtido.TurnoDe},
+		{"cartas_na_mesa", idsDaMesa(esperado.CartasNaMesa), idsDaMesa(obtido.CartasNaMesa)},
+		{"prontos", semFalsos(esperado.Prontos), semFalsos(obtido.Prontos)},
+	}
+	for _, campo := range campos {
+		if !reflect.DeepEqual(campo.esperado, campo.obtido) {
+			return &Divergencia{
+				Campo:    campo.nome,
+				Esperado: fmt.Sprintf("%v", campo.esperado),
+				Obtido:   fmt.Sprintf("%v", campo.obtido),
+				Motivo:   "o log não explica o estado final do servidor",
+			}
+		}
+	}
+	return nil
+}
+
+func semZeros(m map[string]int) map[string]int {
+	r := make(map[string]int)
+	for k, v := range m {
+		if v != 0 {
+			r[k] = v
+		}
+	}
+	return r
+}
+
+func semFalsos(m map[string]bool) []string {
+	r := make([]string, 0, len(m))
+	for k, v := range m {
+		if v {
+			r = append(r, k)
+		}
+	}
+	sort.Strings(r)
+	return r
+}
+
+func idsDaMesa(m map[string]tipos.Carta) map[string]string {
+	r := make(map[string]string, len(m))
+	for nome, carta := range m {
+		r[nome] = carta.ID
+	}
+	return r
+}
@@ -0,0 +1,158 @@
+package replay
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"jogodistribuido/servidor/seguranca"
+	"jogodistribuido/servidor/tipos"
+)
+
+// evento monta um evento assinado pelo Host
+func evento(seq int64, tipo, jogador string, dados map[string]interface{}) tipos.GameEvent {
+	e := tipos.GameEvent{EventSeq: seq, MatchID: "sala1", EventType: tipo, PlayerID: jogador, Data: dados}
+	seguranca.SignEvent(&e)
+	return e
+}
+
+func jogada(seq int64, jogador string, carta tipos.Carta) tipos.GameEvent {
+	return evento(seq, EVENTO_CARTA_JOGADA, jogador, map[string]interface{}{"carta_id": carta.ID, "carta": carta})
+}
+
+// exportacaoTeste é uma partida de formato livre em que ana vence a única jogada
+func exportacaoTeste() Exportacao {
+	return Exportacao{
+		SalaID:    "sala1",
+		Regras:    "CLASSICO",
+		Jogadores: []tipos.Player{{ID: "p1", Nome: "ana"}, {ID: "p2", Nome: "bia"}},
+		EventLog: []tipos.GameEvent{
+			evento(1, EVENTO_INICIO_PARTIDA, "p1", map[string]interface{}{"turno_de": "p1"}),
+			jogada(2, "p1", tipos.Carta{ID: "a1", Naipe: "♥", Valor: 10}),
+			jogada(3, "p2", tipos.Carta{ID: "b1", Naipe: "♠", Valor: 5}),
+			evento(4, EVENTO_FIM_RODADA, "", map[string]interface{}{"vencedor": "ana"}),
+			evento(5, EVENTO_FIM_PARTIDA, "", map[string]interface{}{"vencedor": "ana"}),
+		},
+		EstadoFinal: tipos.EstadoPartida{
+			Estado:        "FINALIZADO",
+			EventSeq:      5,
+			NumeroRodada:  1,
+			PontosPartida: map[string]int{"ana": 1},
+			TurnoDe:       "p1",
+		},
+	}
+}
+
+// assinarJogadas dá carteiras aos jogadores e assina as jogadas deles
+func assinarJogadas(exp *Exportacao, chaves map[string]*ecdsa.PrivateKey) {
+	for i := range exp.Jogadores {
+		exp.Jogadores[i].Endereco = crypto.PubkeyToAddress(chaves[exp.Jogadores[i].ID].PublicKey).Hex()
+	}
+	for i := range exp.EventLog {
+		e := &exp.EventLog[i]
+		if e.EventType != EVENTO_CARTA_JOGADA {
+			continue
+		}
+		dados := e.Data.(map[string]interface{})
+		e.AssinaturaJogador, _ = seguranca.AssinarMensagem(seguranca.MensagemJogada(e.MatchID, e.PlayerID, dados["carta_id"].(string)), chaves[e.PlayerID])
+	}
+}
+
+func TestReproduzir(t *testing.T) {
+	chave1, _ := crypto.GenerateKey()
+	chave2, _ := crypto.GenerateKey()
+	chaves := map[string]*ecdsa.PrivateKey{"p1": chave1, "p2": chave2}
+
+	casos := []struct {
+		nome     string
+		preparar func(exp *Exportacao)
+		erro     bool
+		eventSeq int64  // evento da divergência (0 = estado final)
+		campo    string // "" = sem divergência
+	}{
+		{"log íntegro", func(exp *Exportacao) {}, false, 0, ""},
+		{"jogadas assinadas pela carteira", func(exp *Exportacao) { assinarJogadas(exp, chaves) }, false, 0, ""},
+		{"jogada sem assinatura da carteira", func(exp *Exportacao) {
+			assinarJogadas(exp, chaves)
+			exp.EventLog[2].AssinaturaJogador = ""
+		}, false, 3, "assinaturaJogador"},
+		{"dados alterados depois da assinatura do Host", func(exp *Exportacao) {
+			exp.EventLog[2].Data = map[string]interface{}{"carta_id": "b1", "carta": tipos.Carta{ID: "b1", Naipe: "♠", Valor: 13}}
+		}, false, 3, "signature"},
+		{"evento de outra partida", func(exp *Exportacao) {
+			exp.EventLog[1].MatchID = "sala2"
+			seguranca.SignEvent(&exp.EventLog[1])
+		}, false, 2, "matchId"},
+		{"sequência repetida", func(exp *Exportacao) {
+			exp.EventLog[2] = jogada(2, "p2", tipos.Carta{ID: "b1", Naipe: "♠", Valor: 5})
+		}, false, 2, "eventSeq"},
+		{"jogada fora de turno", func(exp *Exportacao) {
+			exp.EventLog[1] = jogada(2, "p2", tipos.Carta{ID: "b1", Naipe: "♠", Valor: 5})
+		}, false, 2, "turnoDe"},
+		{"jogador desconhecido", func(exp *Exportacao) {
+			exp.EventLog[1] = jogada(2, "p9", tipos.Carta{ID: "x1", Naipe: "♠", Valor: 5})
+		}, false, 2, "playerId"},
+		{"rodada sem ROUND_END", func(exp *Exportacao) {
+			exp.EventLog = append(exp.EventLog[:3:3], exp.EventLog[4])
+		}, false, 3, "eventType"},
+		{"vencedor da rodada errado", func(exp *Exportacao) {
+			exp.EventLog[3] = evento(4, EVENTO_FIM_RODADA, "", map[string]interface{}{"vencedor": "bia"})
+		}, false, 4, "vencedor_rodada"},
+		{"vencedor da partida errado", func(exp *Exportacao) {
+			exp.EventLog[4] = evento(5, EVENTO_FIM_PARTIDA, "", map[string]interface{}{"vencedor": "bia"})
+		}, false, 5, "vencedor"},
+		{"estado final diferente do log", func(exp *Exportacao) {
+			exp.EstadoFinal.PontosPartida = map[string]int{"bia": 1}
+		}, false, 0, "pontos_partida"},
+		{"um jogador só", func(exp *Exportacao) { exp.Jogadores = exp.Jogadores[:1] }, true, 0, ""},
+	}
+	for _, c := range casos {
+		exp := exportacaoTeste()
+		c.preparar(&exp)
+		resultado, err := Reproduzir(exp)
+		if (err != nil) != c.erro {
+			t.Errorf("%s: erro %v, esperado erro=%v", c.nome, err, c.erro)
+			continue
+		}
+		if c.erro {
+			continue
+		}
+		div := resultado.Divergencia
+		if c.campo == "" {
+			if div != nil {
+				t.Errorf("%s: divergência inesperada: %s", c.nome, div)
+			}
+			if resultado.HashLog == "" {
+				t.Errorf("%s: sem hashLog", c.nome)
+			}
+			continue
+		}
+		if div == nil {
+			t.Errorf("%s: sem divergência, esperado '%s' no evento #%d", c.nome, c.campo, c.eventSeq)
+			continue
+		}
+		if div.Campo != c.campo || div.EventSeq != c.eventSeq {
+			t.Errorf("%s: divergência '%s' no evento #%d, esperado '%s' no #%d (%s)", c.nome, div.Campo, div.EventSeq, c.campo, c.eventSeq, div)
+		}
+	}
+}
+
+func TestReproduzirMarcaJogadasAssinadas(t *testing.T) {
+	chave1, _ := crypto.GenerateKey()
+	chave2, _ := crypto.GenerateKey()
+	exp := exportacaoTeste()
+	assinarJogadas(&exp, map[string]*ecdsa.PrivateKey{"p1": chave1, "p2": chave2})
+
+	resultado, err := Reproduzir(exp)
+	if err != nil {
+		t.Fatalf("Reproduzir: %v", err)
+	}
+	for _, passo := range resultado.Passos {
+		if !passo.AssinaturaValida {
+			t.Errorf("evento #%d: assinatura do Host inválida", passo.Evento.EventSeq)
+		}
+		if passo.JogadaAssinada != (passo.Evento.EventType == EVENTO_CARTA_JOGADA) {
+			t.Errorf("evento #%d (%s): JogadaAssinada = %v", passo.Evento.EventSeq, passo.Evento.EventType, passo.JogadaAssinada)
+		}
+	}
+}
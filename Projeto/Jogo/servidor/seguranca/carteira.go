@@ -0,0 +1,191 @@
+package seguranca
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"jogodistribuido/servidor/tipos"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const VALIDADE_DESAFIO_LOGIN = 60 * time.Second // Prazo para o cliente devolver o nonce assinado
+
+// NovoNonce gera o nonce aleatório de um desafio de login.
+func NovoNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// MensagemDesafio é o texto assinado pela carteira no login. Nome e endereço fazem parte da
+// mensagem para que a assinatura não possa ser reaproveitada para outra conta.
+func MensagemDesafio(nome, endereco, nonce string) string {
+	return fmt.Sprintf("Login no Jogo Distribuido\nJogador: %s\nCarteira: %s\nNonce: %s",
+		nome, common.HexToAddress(endereco).Hex(), nonce)
+}
+
+// AssinarMensagem assina a mensagem no formato personal_sign (EIP-191), o mesmo usado por
+// carteiras como o MetaMask, e retorna a assinatura em hex.
+func AssinarMensagem(mensagem string, chave *ecdsa.PrivateKey) (string, error) {
+	assinatura, err := crypto.Sign(accounts.TextHash([]byte(mensagem)), chave)
+	if err != nil {
+		return "", err
+	}
+	assinatura[crypto.RecoveryIDOffset] += 27
+	return hexutil.Encode(assinatura), nil
+}
+
+// RecuperarEndereco devolve o endereço da chave que produziu a assinatura personal_sign.
+// Aceita o byte de recuperação tanto como 0/1 quanto como 27/28.
+func RecuperarEndereco(mensagem, assinaturaHex string) (common.Address, error) {
+	assinatura, err := hexutil.Decode(assinaturaHex)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("assinatura em formato inválido: %v", err)
+	}
+	if len(assinatura) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("assinatura com tamanho inválido (%d bytes)", len(assinatura))
+	}
+	if assinatura[crypto.RecoveryIDOffset] >= 27 {
+		assinatura[crypto.RecoveryIDOffset] -= 27
+	}
+	chave, err := crypto.SigToPub(accounts.TextHash([]byte(mensagem)), assinatura)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("assinatura inválida: %v", err)
+	}
+	return crypto.PubkeyToAddress(*chave), nil
+}
+
+// MensagemJogada é o texto que o jogador assina para jogar uma carta. Cada carta sai da mão uma
+// única vez, então a assinatura de uma jogada não serve para repetir a jogada na mesma sala.
+func MensagemJogada(salaID, jogadorID, cartaID string) string {
+	return fmt.Sprintf("Jogada no Jogo Distribuido\nSala: %s\nJogador: %s\nCarta: %s", salaID, jogadorID, cartaID)
+}
+
+// EhJogada indica se o evento é uma jogada de carta.
+func EhJogada(evento *tipos.GameEvent) bool {
+	return evento.EventType == "CARD_PLAYED" || evento.EventType == "JOGAR_CARTA"
+}
+
+// MarcadaAutomatica indica se o Host marcou o evento como jogada automática do relógio de turno.
+// A marca, sozinha, não dispensa a assinatura: quem confere decide se o prazo do turno venceu.
+func MarcadaAutomatica(evento *tipos.GameEvent) bool {
+	dados, _ := evento.Data.(map[string]interface{})
+	automatica, _ := dados["automatica"].(bool)
+	return automatica
+}
+
+// JogadaDoJogador indica se o evento é uma jogada de carta que precisa da assinatura do jogador:
+// todas, exceto as jogadas automáticas válidas (ver PrazoJogadaAutomatica).
+func JogadaDoJogador(evento *tipos.GameEvent) bool {
+	return EhJogada(evento) && PrazoJogadaAutomatica(evento) == 0
+}
+
+// PrazoJogadaAutomatica devolve o prazo do turno (Unix ms) registrado numa jogada automática do
+// relógio de turno, ou 0 se o evento não é uma jogada automática válida. Só vale a marcada com
+// "automatica" que traz em "prazo_turno" um prazo já vencido no Timestamp do evento; as demais
+// são tratadas como jogadas do jogador. Prazo e Timestamp são escritos pelo Host, então só o
+// replay, que não tem relógio próprio, se apoia neles; a Sombra usa o seu relógio de turno.
+func PrazoJogadaAutomatica(evento *tipos.GameEvent) int64 {
+	if !MarcadaAutomatica(evento) {
+		return 0
+	}
+	dados, _ := evento.Data.(map[string]interface{})
+	var prazo int64
+	switch v := dados["prazo_turno"].(type) {
+	case int64:
+		prazo = v
+	case float64: // Data decodificado de JSON
+		prazo = int64(v)
+	case json.Number:
+		prazo, _ = v.Int64()
+	}
+	if prazo <= 0 || evento.Timestamp.UnixMilli() < prazo {
+		return 0
+	}
+	return prazo
+}
+
+// VerificarAssinaturaJogador confere se uma jogada que precisa da assinatura (JogadaDoJogador)
+// foi assinada pela carteira do jogador; os demais eventos passam.
+//
+// Política para jogadores sem carteira: com endereco vazio nada é conferido e a jogada vale só
+// pela assinatura HMAC do Host. É intencional: o login sem carteira continua aceito e esse jogador
+// não tem chave com que assinar. Quem chama deve passar o endereço que o jogador provou no login
+// (ou o vinculado ao nome no log replicado), nunca um informado pelo Host, para que o Host não
+// possa declarar sem carteira um jogador que tem.
+func VerificarAssinaturaJogador(evento *tipos.GameEvent, endereco string) error {
+	if !JogadaDoJogador(evento) {
+		return nil
+	}
+	return VerificarAssinaturaJogada(evento, endereco)
+}
+
+// VerificarAssinaturaJogada confere a assinatura da carteira em qualquer jogada de carta, mesmo as
+// marcadas como automáticas; a Sombra a usa quando o seu relógio não dispensa a assinatura. Segue
+// a mesma política de VerificarAssinaturaJogador para endereco vazio.
+func VerificarAssinaturaJogada(evento *tipos.GameEvent, endereco string) error {
+	if endereco == "" || !EhJogada(evento) {
+		return nil
+	}
+	if evento.AssinaturaJogador == "" {
+		return fmt.Errorf("jogada de %s sem assinatura da carteira", evento.PlayerID)
+	}
+	dados, _ := evento.Data.(map[string]interface{})
+	cartaID, _ := dados["carta_id"].(string)
+	assinante, err := RecuperarEndereco(MensagemJogada(evento.MatchID, evento.PlayerID, cartaID), evento.AssinaturaJogador)
+	if err != nil {
+		return err
+	}
+	if assinante != common.HexToAddress(endereco) {
+		return fmt.Errorf("jogada de %s assinada por %s, esperado %s", evento.PlayerID, assinante.Hex(), common.HexToAddress(endereco).Hex())
+	}
+	return nil
+}
+
+// AssinarHash assina um hash de 32 bytes em personal_sign, o formato que o contrato confere com
+// ecrecover (resultado de partida co-assinado).
+func AssinarHash(hash common.Hash, chave *ecdsa.PrivateKey) (string, error) {
+	return AssinarMensagem(string(hash.Bytes()), chave)
+}
+
+// RecuperarEnderecoHash devolve quem assinou o hash com AssinarHash.
+func RecuperarEnderecoHash(hash common.Hash, assinaturaHex string) (common.Address, error) {
+	return RecuperarEndereco(string(hash.Bytes()), assinaturaHex)
+}
+
+// HashEventLog calcula o keccak256 do EventLog até o primeiro MATCH_END, inclusive. Cada evento é
+// normalizado pelo JSON (chaves ordenadas, números como no fio), então o Host, a Sombra que
+// recebeu o log replicado e o replay de uma exportação chegam ao mesmo hash.
+func HashEventLog(eventos []tipos.GameEvent) (common.Hash, error) {
+	fim := -1
+	for i, evento := range eventos {
+		if evento.EventType == "MATCH_END" {
+			fim = i
+			break
+		}
+	}
+	if fim < 0 {
+		return common.Hash{}, fmt.Errorf("EventLog sem MATCH_END")
+	}
+
+	bruto, err := json.Marshal(eventos[:fim+1])
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var normalizado interface{}
+	if err := json.Unmarshal(bruto, &normalizado); err != nil {
+		return common.Hash{}, err
+	}
+	canonico, err := json.Marshal(normalizado)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(canonico), nil
+}
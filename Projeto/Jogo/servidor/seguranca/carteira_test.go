@@ -0,0 +1,178 @@
+package seguranca
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"jogodistribuido/servidor/tipos"
+)
+
+func TestRecuperarEndereco(t *testing.T) {
+	chave, _ := crypto.GenerateKey()
+	endereco := crypto.PubkeyToAddress(chave.PublicKey)
+	mensagem := MensagemDesafio("ana", endereco.Hex(), NovoNonce())
+	assinatura, err := AssinarMensagem(mensagem, chave)
+	if err != nil {
+		t.Fatalf("AssinarMensagem: %v", err)
+	}
+	// A mesma assinatura com o byte de recuperação em 0/1, como algumas carteiras enviam
+	bruta, _ := hexutil.Decode(assinatura)
+	bruta[crypto.RecoveryIDOffset] -= 27
+	assinaturaSemOffset := hexutil.Encode(bruta)
+
+	casos := []struct {
+		nome       string
+		mensagem   string
+		assinatura string
+		confere    bool
+		erro       bool
+	}{
+		{"assinatura válida", mensagem, assinatura, true, false},
+		{"byte de recuperação 0/1", mensagem, assinaturaSemOffset, true, false},
+		{"outra mensagem", mensagem + "!", assinatura, false, false},
+		{"hex inválido", mensagem, "0xzz", false, true},
+		{"tamanho inválido", mensagem, "0x1234", false, true},
+	}
+	for _, c := range casos {
+		recuperado, err := RecuperarEndereco(c.mensagem, c.assinatura)
+		if (err != nil) != c.erro {
+			t.Errorf("%s: erro %v, esperado erro=%v", c.nome, err, c.erro)
+			continue
+		}
+		if !c.erro && (recuperado == endereco) != c.confere {
+			t.Errorf("%s: recuperado %s, confere=%v, esperado %v", c.nome, recuperado.Hex(), recuperado == endereco, c.confere)
+		}
+	}
+}
+
+func TestPrazoJogadaAutomatica(t *testing.T) {
+	prazo := time.UnixMilli(1_700_000_000_000)
+	casos := []struct {
+		nome      string
+		dados     interface{}
+		timestamp time.Time
+		prazo     int64
+	}{
+		{"jogada comum", map[string]interface{}{"carta_id": "x"}, prazo, 0},
+		{"automática vencida", map[string]interface{}{"automatica": true, "prazo_turno": prazo.UnixMilli()}, prazo.Add(time.Second), prazo.UnixMilli()},
+		{"automática de JSON", map[string]interface{}{"automatica": true, "prazo_turno": float64(prazo.UnixMilli())}, prazo, prazo.UnixMilli()},
+		{"automática com json.Number", map[string]interface{}{"automatica": true, "prazo_turno": json.Number("1700000000000")}, prazo, prazo.UnixMilli()},
+		{"automática antes do prazo", map[string]interface{}{"automatica": true, "prazo_turno": prazo.UnixMilli()}, prazo.Add(-time.Millisecond), 0},
+		{"automática sem prazo", map[string]interface{}{"automatica": true}, prazo, 0},
+		{"marca falsa", map[string]interface{}{"automatica": false, "prazo_turno": prazo.UnixMilli()}, prazo, 0},
+		{"dados sem mapa", "texto", prazo, 0},
+	}
+	for _, c := range casos {
+		evento := tipos.GameEvent{EventType: "CARD_PLAYED", Data: c.dados, Timestamp: c.timestamp}
+		if obtido := PrazoJogadaAutomatica(&evento); obtido != c.prazo {
+			t.Errorf("%s: PrazoJogadaAutomatica = %d, esperado %d", c.nome, obtido, c.prazo)
+		}
+		if JogadaDoJogador(&evento) != (c.prazo == 0) {
+			t.Errorf("%s: JogadaDoJogador = %v, esperado %v", c.nome, JogadaDoJogador(&evento), c.prazo == 0)
+		}
+	}
+}
+
+func TestVerificarAssinaturaJogador(t *testing.T) {
+	chave, _ := crypto.GenerateKey()
+	endereco := crypto.PubkeyToAddress(chave.PublicKey).Hex()
+	outra, _ := crypto.GenerateKey()
+
+	jogada := func(assinante func() string, dados map[string]interface{}) tipos.GameEvent {
+		evento := tipos.GameEvent{MatchID: "sala1", EventType: "CARD_PLAYED", PlayerID: "p1", Data: dados, Timestamp: time.Now()}
+		if assinante != nil {
+			evento.AssinaturaJogador = assinante()
+		}
+		return evento
+	}
+	assinar := func(c *ecdsa.PrivateKey, carta string) func() string {
+		return func() string {
+			assinatura, _ := AssinarMensagem(MensagemJogada("sala1", "p1", carta), c)
+			return assinatura
+		}
+	}
+	vencido := time.Now().Add(-time.Minute).UnixMilli()
+
+	casos := []struct {
+		nome           string
+		evento         tipos.GameEvent
+		endereco       string
+		erroJogador    bool // VerificarAssinaturaJogador (replay)
+		erroSemExcecao bool // VerificarAssinaturaJogada (Sombra, sem o relógio dispensar)
+	}{
+		{"assinada pelo jogador", jogada(assinar(chave, "c1"), map[string]interface{}{"carta_id": "c1"}), endereco, false, false},
+		{"sem assinatura", jogada(nil, map[string]interface{}{"carta_id": "c1"}), endereco, true, true},
+		{"assinada por outra carteira", jogada(assinar(outra, "c1"), map[string]interface{}{"carta_id": "c1"}), endereco, true, true},
+		{"assinatura de outra carta", jogada(assinar(chave, "c2"), map[string]interface{}{"carta_id": "c1"}), endereco, true, true},
+		{"jogador sem carteira", jogada(nil, map[string]interface{}{"carta_id": "c1"}), "", false, false},
+		{"automática vencida", jogada(nil, map[string]interface{}{"carta_id": "c1", "automatica": true, "prazo_turno": vencido}), endereco, false, true},
+		{"não é jogada", tipos.GameEvent{EventType: "MATCH_START", PlayerID: "p1"}, endereco, false, false},
+	}
+	for _, c := range casos {
+		if err := VerificarAssinaturaJogador(&c.evento, c.endereco); (err != nil) != c.erroJogador {
+			t.Errorf("%s: VerificarAssinaturaJogador = %v, esperado erro=%v", c.nome, err, c.erroJogador)
+		}
+		if err := VerificarAssinaturaJogada(&c.evento, c.endereco); (err != nil) != c.erroSemExcecao {
+			t.Errorf("%s: VerificarAssinaturaJogada = %v, esperado erro=%v", c.nome, err, c.erroSemExcecao)
+		}
+	}
+}
+
+func TestHashEventLog(t *testing.T) {
+	carta := tipos.Carta{ID: "c1", Nome: "Dragão", Naipe: "♥", Valor: 7}
+	comStruct := []tipos.GameEvent{
+		{EventSeq: 1, MatchID: "s", EventType: "MATCH_START", Data: map[string]interface{}{"turno_de": "p1"}},
+		{EventSeq: 2, MatchID: "s", EventType: "CARD_PLAYED", PlayerID: "p1", Data: carta},
+		{EventSeq: 3, MatchID: "s", EventType: "MATCH_END", Data: map[string]interface{}{"vencedor": "p1"}},
+	}
+	// O mesmo log depois de passar pelo JSON (replicação ou exportação)
+	bruto, _ := json.Marshal(comStruct)
+	var decodificado []tipos.GameEvent
+	json.Unmarshal(bruto, &decodificado)
+
+	base, err := HashEventLog(comStruct)
+	if err != nil {
+		t.Fatalf("HashEventLog: %v", err)
+	}
+
+	alterado := append([]tipos.GameEvent(nil), comStruct...)
+	alterado[1].Data = tipos.Carta{ID: "c1", Nome: "Dragão", Naipe: "♥", Valor: 8}
+
+	casos := []struct {
+		nome    string
+		eventos []tipos.GameEvent
+		igual   bool
+		erro    bool
+	}{
+		{"log decodificado de JSON", decodificado, true, false},
+		{"eventos depois do MATCH_END", append(append([]tipos.GameEvent(nil), comStruct...), tipos.GameEvent{EventSeq: 4, EventType: "CHAT"}), true, false},
+		{"jogada alterada", alterado, false, false},
+		{"sem MATCH_END", comStruct[:2], false, true},
+	}
+	for _, c := range casos {
+		hash, err := HashEventLog(c.eventos)
+		if (err != nil) != c.erro {
+			t.Errorf("%s: erro %v, esperado erro=%v", c.nome, err, c.erro)
+			continue
+		}
+		if !c.erro && (hash == base) != c.igual {
+			t.Errorf("%s: hash igual=%v, esperado %v", c.nome, hash == base, c.igual)
+		}
+	}
+}
+
+func TestMensagemDesafioNormalizaEndereco(t *testing.T) {
+	minusculo := MensagemDesafio("ana", "0xabcdef0123456789abcdef0123456789abcdef01", "n")
+	misto := MensagemDesafio("ana", "0xABCDEF0123456789ABCDEF0123456789ABCDEF01", "n")
+	if minusculo != misto {
+		t.Errorf("mensagens diferentes para o mesmo endereço:\n%s\n%s", minusculo, misto)
+	}
+	if !strings.Contains(minusculo, "Jogador: ana") {
+		t.Errorf("mensagem sem o nome do jogador: %s", minusculo)
+	}
+}
@@ -1,9 +1,11 @@
 package seguranca
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"jogodistribuido/servidor/tipos"
@@ -81,17 +83,43 @@ func GenerateHMAC(message, secret string) string {
 	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
 }
 
-// SignEvent assina um evento de jogo
+// SignEvent assina um evento de jogo. A assinatura cobre também os dados do evento (hashDados),
+// então o evento deve ser assinado só depois de Data estar completo.
 func SignEvent(event *tipos.GameEvent) {
-	data := fmt.Sprintf("%d:%s:%s:%s", event.EventSeq, event.MatchID, event.EventType, event.PlayerID)
-	event.Signature = GenerateHMAC(data, JWT_SECRET)
+	event.Signature = GenerateHMAC(mensagemEvento(event), JWT_SECRET)
 }
 
 // VerifyEventSignature verifica a assinatura de um evento
 func VerifyEventSignature(event *tipos.GameEvent) bool {
-	data := fmt.Sprintf("%d:%s:%s:%s", event.EventSeq, event.MatchID, event.EventType, event.PlayerID)
-	expectedSig := GenerateHMAC(data, JWT_SECRET)
-	return event.Signature == expectedSig
+	expectedSig := GenerateHMAC(mensagemEvento(event), JWT_SECRET)
+	return hmac.Equal([]byte(event.Signature), []byte(expectedSig))
+}
+
+// mensagemEvento é o texto assinado de um evento: sequência, partida, tipo, jogador e hash dos dados
+func mensagemEvento(event *tipos.GameEvent) string {
+	return fmt.Sprintf("%d:%s:%s:%s:%s", event.EventSeq, event.MatchID, event.EventType, event.PlayerID, hashDados(event.Data))
+}
+
+// hashDados é o SHA-256 da forma canônica de Data: o JSON é decodificado e codificado de novo, o que
+// ordena as chaves dos objetos. Assim a mesma informação tem o mesmo hash como struct (ex: tipos.Carta)
+// ou como map, depois de passar pela replicação ou pela exportação.
+func hashDados(data interface{}) string {
+	bruto, err := json.Marshal(data)
+	if err != nil {
+		return ""
+	}
+	decodificador := json.NewDecoder(bytes.NewReader(bruto))
+	decodificador.UseNumber() // Mantém os números como foram escritos
+	var generico interface{}
+	if err := decodificador.Decode(&generico); err != nil {
+		return ""
+	}
+	canonico, err := json.Marshal(generico)
+	if err != nil {
+		return ""
+	}
+	soma := sha256.Sum256(canonico)
+	return hex.EncodeToString(soma[:])
 }
 
 func MustJSON(v interface{}) []byte {
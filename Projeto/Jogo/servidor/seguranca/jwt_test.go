@@ -0,0 +1,83 @@
+package seguranca
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"jogodistribuido/servidor/tipos"
+)
+
+// tokenCom monta um JWT assinado com o payload dado, para testar claims ausentes ou vencidas
+func tokenCom(payload map[string]interface{}) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payloadJSON, _ := json.Marshal(payload)
+	mensagem := header + "." + base64.RawURLEncoding.EncodeToString(payloadJSON)
+	return mensagem + "." + GenerateHMAC(mensagem, JWT_SECRET)
+}
+
+func TestValidateJWT(t *testing.T) {
+	valido := GenerateJWT("servidor1")
+	partes := strings.Split(valido, ".")
+
+	casos := []struct {
+		nome     string
+		token    string
+		serverID string
+		erro     bool
+	}{
+		{"token gerado", valido, "servidor1", false},
+		{"formato incorreto", "abc.def", "", true},
+		{"assinatura adulterada", partes[0] + "." + partes[1] + ".x", "", true},
+		{"payload trocado", partes[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"server_id":"outro"}`)) + "." + partes[2], "", true},
+		{"expirado", tokenCom(map[string]interface{}{"server_id": "servidor1", "exp": time.Now().Add(-time.Minute).Unix()}), "", true},
+		{"sem exp", tokenCom(map[string]interface{}{"server_id": "servidor1"}), "", true},
+		{"sem server_id", tokenCom(map[string]interface{}{"exp": time.Now().Add(time.Minute).Unix()}), "", true},
+	}
+	for _, c := range casos {
+		serverID, err := ValidateJWT(c.token)
+		if (err != nil) != c.erro {
+			t.Errorf("%s: erro %v, esperado erro=%v", c.nome, err, c.erro)
+			continue
+		}
+		if serverID != c.serverID {
+			t.Errorf("%s: server_id %q, esperado %q", c.nome, serverID, c.serverID)
+		}
+	}
+}
+
+func TestVerifyEventSignature(t *testing.T) {
+	carta := tipos.Carta{ID: "c1", Nome: "Dragão", Naipe: "♥", Valor: 7}
+	assinado := tipos.GameEvent{EventSeq: 2, MatchID: "s", EventType: "CARD_PLAYED", PlayerID: "p1", Data: carta}
+	SignEvent(&assinado)
+
+	// O mesmo evento com Data como map, como chega depois da replicação
+	var replicado tipos.GameEvent
+	bruto, _ := json.Marshal(assinado)
+	json.Unmarshal(bruto, &replicado)
+
+	alterar := func(mudar func(*tipos.GameEvent)) tipos.GameEvent {
+		copia := assinado
+		mudar(&copia)
+		return copia
+	}
+	casos := []struct {
+		nome   string
+		evento tipos.GameEvent
+		valido bool
+	}{
+		{"evento assinado", assinado, true},
+		{"evento replicado", replicado, true},
+		{"outra sequência", alterar(func(e *tipos.GameEvent) { e.EventSeq = 3 }), false},
+		{"outro jogador", alterar(func(e *tipos.GameEvent) { e.PlayerID = "p2" }), false},
+		{"outros dados", alterar(func(e *tipos.GameEvent) { e.Data = tipos.Carta{ID: "c1", Valor: 13} }), false},
+		{"sem assinatura", alterar(func(e *tipos.GameEvent) { e.Signature = "" }), false},
+	}
+	for _, c := range casos {
+		if valido := VerifyEventSignature(&c.evento); valido != c.valido {
+			t.Errorf("%s: VerifyEventSignature = %v, esperado %v", c.nome, valido, c.valido)
+		}
+	}
+}
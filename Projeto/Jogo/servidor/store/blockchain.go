@@ -0,0 +1,112 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"jogodistribuido/servidor/blockchain"
+	"jogodistribuido/servidor/tipos"
+)
+
+// StoreBlockchain é o Store do modo ESTOQUE_MODO=BLOCKCHAIN: não há estoque em memória, cada pacote
+// é comprado pelo cliente com a própria carteira (commit-reveal no GameEconomy) e a posse das cartas
+// é a do contrato. O servidor verifica no contrato a transação de revelação de cada compra
+// (VerificarCompra) e registra no log replicado que ela foi resgatada (ConfirmarRetirada), para que
+// a mesma compra não valha um segundo pacote em nenhum servidor.
+type StoreBlockchain struct {
+	manager *blockchain.Manager
+
+	resgatadas map[string]bool // Hash da transação de revelação -> compra já resgatada (log replicado)
+	mutex      sync.Mutex
+}
+
+// NewStoreBlockchain cria um Store sobre o contrato do manager informado.
+func NewStoreBlockchain(manager *blockchain.Manager) *StoreBlockchain {
+	return &StoreBlockchain{manager: manager, resgatadas: make(map[string]bool)}
+}
+
+// VerificarCompra confere no contrato que a transação de revelação compra emitiu um PacoteComprado
+// para carteira e que a compra ainda não foi resgatada. Retorna os IDs das cartas do pacote. O resgate
+// só vale depois de aplicado do log replicado (ConfirmarRetirada).
+func (s *StoreBlockchain) VerificarCompra(carteira common.Address, compra common.Hash) ([]string, error) {
+	if s.Resgatada(compra) {
+		return nil, fmt.Errorf("o pacote da transação %s já foi entregue", compra.Hex())
+	}
+	comprador, tokenIds, err := s.manager.PacoteRevelado(compra)
+	if err != nil {
+		return nil, err
+	}
+	if comprador != carteira {
+		return nil, fmt.Errorf("o pacote da transação %s foi comprado por outra carteira (%s)", compra.Hex(), comprador.Hex())
+	}
+	ids := make([]string, len(tokenIds))
+	for i, id := range tokenIds {
+		ids[i] = id.String()
+	}
+	return ids, nil
+}
+
+// Resgatada informa se a compra já foi resgatada segundo o log replicado.
+func (s *StoreBlockchain) Resgatada(compra common.Hash) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.resgatadas[compra.Hex()]
+}
+
+// FormarPacote não forma pacotes: eles são comprados pela carteira do jogador.
+func (s *StoreBlockchain) FormarPacote(tamanho int) []tipos.Carta {
+	return []tipos.Carta{}
+}
+
+// NaBlockchain é sempre verdadeiro: a posse das cartas é a do contrato.
+func (s *StoreBlockchain) NaBlockchain() bool {
+	return true
+}
+
+// GetStatusEstoque é vazio: o contrato cunha a cada compra, não há estoque limitado.
+func (s *StoreBlockchain) GetStatusEstoque() (map[string]int, int) {
+	return map[string]int{}, 0
+}
+
+// ConfirmarRetirada aplica o resgate de uma compra confirmado no log replicado: ids traz só o hash da
+// transação de revelação. Cada compra é resgatada uma vez; um segundo resgate retorna false.
+func (s *StoreBlockchain) ConfirmarRetirada(ids []string) bool {
+	if len(ids) != 1 {
+		return false
+	}
+	compra := common.HexToHash(ids[0]).Hex()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.resgatadas[compra] {
+		return false
+	}
+	s.resgatadas[compra] = true
+	return true
+}
+
+// CancelarRetirada não se aplica: cartas cunhadas só deixam de existir pelo contrato (fusão).
+func (s *StoreBlockchain) CancelarRetirada(cartas []tipos.Carta) {}
+
+// Retiradas lista, em ordem, os hashes das compras já resgatadas.
+func (s *StoreBlockchain) Retiradas() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	resgatadas := make([]string, 0, len(s.resgatadas))
+	for compra := range s.resgatadas {
+		resgatadas = append(resgatadas, compra)
+	}
+	sort.Strings(resgatadas)
+	return resgatadas
+}
+
+// RestaurarRetiradas substitui as compras resgatadas pelas do snapshot.
+func (s *StoreBlockchain) RestaurarRetiradas(ids []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.resgatadas = make(map[string]bool, len(ids))
+	for _, compra := range ids {
+		s.resgatadas[common.HexToHash(compra).Hex()] = true
+	}
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"errors"
+	"jogodistribuido/servidor/tipos"
+)
+
+// CARTAS_POR_FUSAO acompanha a constante de mesmo nome do GameEconomy
+const CARTAS_POR_FUSAO = 3
+
+// Motivos de recusa da fusão (os mesmos do require de fundirCartas no contrato)
+var (
+	ErrQuantidadeFusao     = errors.New("quantidade de cartas inválida para fusão")
+	ErrCartaRepetidaFusao  = errors.New("carta repetida na fusão")
+	ErrNomesDiferentes     = errors.New("cartas com nomes diferentes")
+	ErrRaridadesDiferentes = errors.New("cartas com raridades diferentes")
+	ErrRaridadeNaoFundivel = errors.New("raridade não pode ser fundida")
+)
+
+// proximaRaridade devolve a raridade seguinte e a faixa de valores dela, as mesmas usadas em
+// inicializarEstoque. Lendárias não têm raridade seguinte.
+func proximaRaridade(raridade string) (proxima string, valorMinimo, faixa int, ok bool) {
+	switch raridade {
+	case "C":
+		return "U", 51, 30, true
+	case "U":
+		return "R", 81, 20, true
+	case "R":
+		return "L", 101, 20, true
+	}
+	return "", 0, 0, false
+}
+
+// Fundir é a versão fora da blockchain de fundirCartas: valida CARTAS_POR_FUSAO cartas de mesmo
+// nome e raridade e devolve a carta da raridade seguinte que as substitui. A nova carta mantém o
+// nome e o naipe da primeira e vale mínimo + (soma dos valores % faixa), como no contrato.
+// Quem chama é responsável por retirar as cartas fundidas do inventário do jogador.
+func Fundir(cartas []tipos.Carta) (tipos.Carta, error) {
+	if len(cartas) != CARTAS_POR_FUSAO {
+		return tipos.Carta{}, ErrQuantidadeFusao
+	}
+
+	primeira := cartas[0]
+	raridade, valorMinimo, faixa, ok := proximaRaridade(primeira.Raridade)
+	if !ok {
+		return tipos.Carta{}, ErrRaridadeNaoFundivel
+	}
+
+	vistas := make(map[string]bool, len(cartas))
+	soma := 0
+	for _, carta := range cartas {
+		if vistas[carta.ID] {
+			return tipos.Carta{}, ErrCartaRepetidaFusao
+		}
+		vistas[carta.ID] = true
+		if carta.Nome != primeira.Nome {
+			return tipos.Carta{}, ErrNomesDiferentes
+		}
+		if carta.Raridade != primeira.Raridade {
+			return tipos.Carta{}, ErrRaridadesDiferentes
+		}
+		soma += carta.Valor
+	}
+
+	return tipos.Carta{
+		ID:       randomString(5),
+		Nome:     primeira.Nome,
+		Naipe:    primeira.Naipe,
+		Valor:    valorMinimo + soma%faixa,
+		Raridade: raridade,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"jogodistribuido/servidor/tipos"
+	"testing"
+)
+
+func TestFundir(t *testing.T) {
+	carta := func(id, nome, raridade string, valor int) tipos.Carta {
+		return tipos.Carta{ID: id, Nome: nome, Naipe: "♥", Valor: valor, Raridade: raridade}
+	}
+	casos := []struct {
+		nome     string
+		cartas   []tipos.Carta
+		raridade string
+		valor    int
+		erro     error
+	}{
+		{"comuns viram incomum", []tipos.Carta{
+			carta("a", "Dragão", "C", 10), carta("b", "Dragão", "C", 20), carta("c", "Dragão", "C", 25),
+		}, "U", 51 + 55%30, nil},
+		{"incomuns viram rara", []tipos.Carta{
+			carta("a", "Dragão", "U", 60), carta("b", "Dragão", "U", 61), carta("c", "Dragão", "U", 62),
+		}, "R", 81 + 183%20, nil},
+		{"raras viram lendária", []tipos.Carta{
+			carta("a", "Dragão", "R", 90), carta("b", "Dragão", "R", 91), carta("c", "Dragão", "R", 92),
+		}, "L", 101 + 273%20, nil},
+		{"duas cartas", []tipos.Carta{
+			carta("a", "Dragão", "C", 10), carta("b", "Dragão", "C", 20),
+		}, "", 0, ErrQuantidadeFusao},
+		{"quatro cartas", []tipos.Carta{
+			carta("a", "Dragão", "C", 1), carta("b", "Dragão", "C", 2), carta("c", "Dragão", "C", 3), carta("d", "Dragão", "C", 4),
+		}, "", 0, ErrQuantidadeFusao},
+		{"lendárias", []tipos.Carta{
+			carta("a", "Dragão", "L", 110), carta("b", "Dragão", "L", 111), carta("c", "Dragão", "L", 112),
+		}, "", 0, ErrRaridadeNaoFundivel},
+		{"carta repetida", []tipos.Carta{
+			carta("a", "Dragão", "C", 10), carta("a", "Dragão", "C", 10), carta("c", "Dragão", "C", 25),
+		}, "", 0, ErrCartaRepetidaFusao},
+		{"nomes diferentes", []tipos.Carta{
+			carta("a", "Dragão", "C", 10), carta("b", "Fênix", "C", 20), carta("c", "Dragão", "C", 25),
+		}, "", 0, ErrNomesDiferentes},
+		{"raridades diferentes", []tipos.Carta{
+			carta("a", "Dragão", "C", 10), carta("b", "Dragão", "U", 60), carta("c", "Dragão", "C", 25),
+		}, "", 0, ErrRaridadesDiferentes},
+	}
+	for _, c := range casos {
+		nova, err := Fundir(c.cartas)
+		if err != c.erro {
+			t.Errorf("%s: erro %v, esperado %v", c.nome, err, c.erro)
+			continue
+		}
+		if c.erro != nil {
+			continue
+		}
+		if nova.Raridade != c.raridade || nova.Valor != c.valor {
+			t.Errorf("%s: carta %s/%d, esperado %s/%d", c.nome, nova.Raridade, nova.Valor, c.raridade, c.valor)
+		}
+		if nova.Nome != c.cartas[0].Nome || nova.Naipe != c.cartas[0].Naipe {
+			t.Errorf("%s: carta %q %s, esperado o nome e o naipe da primeira", c.nome, nova.Nome, nova.Naipe)
+		}
+		if nova.ID == "" {
+			t.Errorf("%s: carta fundida sem ID", c.nome)
+		}
+	}
+}
@@ -4,35 +4,65 @@ import (
 	"jogodistribuido/servidor/tipos"
 	"log"
 	"math/rand"
+	"sort"
 	"sync"
 )
 
+// SEMENTE_ESTOQUE gera o mesmo estoque inicial em todos os servidores. O estoque de cada um é
+// esse estoque inicial menos as compras do log replicado (COMPRA_PACOTE), então as cartas que o
+// líder tira existem com os mesmos IDs nos seguidores.
+const SEMENTE_ESTOQUE = 20240601
+
 // StoreInterface define as operações que o Store de cartas expõe.
+// Store mantém um estoque em memória; com StoreBlockchain as cartas são as do contrato.
 type StoreInterface interface {
 	FormarPacote(tamanho int) []tipos.Carta
+	// NaBlockchain informa se a posse das cartas é decidida pelo contrato e não pelo servidor.
+	NaBlockchain() bool
 	GetStatusEstoque() (map[string]int, int)
+	// ConfirmarRetirada aplica uma compra confirmada no log replicado (ver Store.ConfirmarRetirada).
+	ConfirmarRetirada(ids []string) bool
+	// CancelarRetirada libera as cartas reservadas por FormarPacote para uma compra não confirmada.
+	CancelarRetirada(cartas []tipos.Carta)
+	// Retiradas lista o que as retiradas confirmadas já tiraram, para o snapshot do log replicado.
+	Retiradas() []string
+	// RestaurarRetiradas volta ao estado inicial e reaplica as retiradas de um snapshot.
+	RestaurarRetiradas(ids []string)
 }
 
-// Store gerencia o estoque global de cartas.
+// Store gerencia o estoque global de cartas. Não é gravado em disco: ao reiniciar, o servidor
+// recria o estoque inicial, retira as cartas do snapshot do Raft (RestaurarRetiradas) e reaplica
+// sobre ele o restante do log replicado.
+//
+// FormarPacote só reserva as cartas; elas saem do estoque quando a compra é aplicada
+// (ConfirmarRetirada), na mesma ordem em todos os servidores.
 type Store struct {
-	mutex   sync.RWMutex
-	Estoque map[string][]tipos.Carta
+	mutex    sync.RWMutex
+	Estoque  map[string][]tipos.Carta
+	reservas map[string]bool // IDs reservados por FormarPacote, ainda no estoque
+	iniciais map[string]bool // IDs do estoque inicial; as demais cartas são geradas com o estoque esgotado
 }
 
-// NewStore cria e inicializa um novo Store.
+// NewStore cria um Store com o estoque inicial de SEMENTE_ESTOQUE.
 func NewStore() *Store {
 	s := &Store{
-		Estoque: make(map[string][]tipos.Carta),
+		Estoque:  make(map[string][]tipos.Carta),
+		reservas: make(map[string]bool),
+		iniciais: make(map[string]bool),
 	}
 	s.inicializarEstoque()
 	return s
 }
 
 func randomString(length int) string {
+	return stringAleatoria(rand.Intn, length)
+}
+
+func stringAleatoria(sortear func(int) int, length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	b := make([]byte, length)
 	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
+		b[i] = charset[sortear(len(charset))]
 	}
 	return string(b)
 }
@@ -54,18 +84,36 @@ func (s *Store) inicializarEstoque() {
 	}
 	naipes := []string{"♠", "♥", "♦", "♣"}
 
+	// Sorteio próprio com semente fixa: o global é usado por FormarPacote e variaria entre servidores
+	gerador := rand.New(rand.NewSource(SEMENTE_ESTOQUE))
+	novaCarta := func(nome, raridade string, valorMinimo, faixa int) tipos.Carta {
+		return tipos.Carta{
+			ID:       stringAleatoria(gerador.Intn, 5),
+			Nome:     nome,
+			Naipe:    naipes[gerador.Intn(len(naipes))],
+			Valor:    valorMinimo + gerador.Intn(faixa),
+			Raridade: raridade,
+		}
+	}
+
 	for _, nome := range tiposCartas {
 		for i := 0; i < 100; i++ { // Comuns
-			s.Estoque["C"] = append(s.Estoque["C"], tipos.Carta{ID: randomString(5), Nome: nome, Naipe: naipes[rand.Intn(len(naipes))], Valor: 1 + rand.Intn(50), Raridade: "C"})
+			s.Estoque["C"] = append(s.Estoque["C"], novaCarta(nome, "C", 1, 50))
 		}
 		for i := 0; i < 50; i++ { // Incomuns
-			s.Estoque["U"] = append(s.Estoque["U"], tipos.Carta{ID: randomString(5), Nome: nome, Naipe: naipes[rand.Intn(len(naipes))], Valor: 51 + rand.Intn(30), Raridade: "U"})
+			s.Estoque["U"] = append(s.Estoque["U"], novaCarta(nome, "U", 51, 30))
 		}
 		for i := 0; i < 20; i++ { // Raras
-			s.Estoque["R"] = append(s.Estoque["R"], tipos.Carta{ID: randomString(5), Nome: nome, Naipe: naipes[rand.Intn(len(naipes))], Valor: 81 + rand.Intn(20), Raridade: "R"})
+			s.Estoque["R"] = append(s.Estoque["R"], novaCarta(nome, "R", 81, 20))
 		}
 		for i := 0; i < 5; i++ { // Lendárias
-			s.Estoque["L"] = append(s.Estoque["L"], tipos.Carta{ID: randomString(5), Nome: nome, Naipe: naipes[rand.Intn(len(naipes))], Valor: 101 + rand.Intn(20), Raridade: "L"})
+			s.Estoque["L"] = append(s.Estoque["L"], novaCarta(nome, "L", 101, 20))
+		}
+	}
+
+	for _, cartas := range s.Estoque {
+		for _, carta := range cartas {
+			s.iniciais[carta.ID] = true
 		}
 	}
 
@@ -87,6 +135,8 @@ func sampleRaridade() string {
 	return "L"
 }
 
+// FormarPacote sorteia um pacote entre as cartas não reservadas e as reserva. Elas continuam no
+// estoque até a compra ser confirmada (ConfirmarRetirada) ou cancelada (CancelarRetirada).
 func (s *Store) FormarPacote(tamanho int) []tipos.Carta {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -111,11 +161,15 @@ func (s *Store) FormarPacote(tamanho int) []tipos.Carta {
 		encontrou := false
 		for j := start; j < len(ordem); j++ {
 			r := ordem[j]
-			if len(s.Estoque[r]) > 0 {
-				idx := len(s.Estoque[r]) - 1
-				carta = s.Estoque[r][idx]
-				s.Estoque[r] = s.Estoque[r][:idx]
-				encontrou = true
+			for idx := len(s.Estoque[r]) - 1; idx >= 0; idx-- {
+				if !s.reservas[s.Estoque[r][idx].ID] {
+					carta = s.Estoque[r][idx]
+					s.reservas[carta.ID] = true
+					encontrou = true
+					break
+				}
+			}
+			if encontrou {
 				break
 			}
 		}
@@ -127,14 +181,103 @@ func (s *Store) FormarPacote(tamanho int) []tipos.Carta {
 	return cartas
 }
 
+// NaBlockchain é falso: as cartas entregues pelo estoque em memória só existem no servidor.
+func (s *Store) NaBlockchain() bool {
+	return false
+}
+
+// ConfirmarRetirada aplica uma compra confirmada pelo log replicado: se todas as cartas do estoque
+// inicial ainda estiverem no estoque, retira-as e retorna true; se alguma já saiu por uma compra
+// anterior do log (ex: reservada por outro líder), a compra inteira é recusada e nada sai. Como o log
+// é aplicado na mesma ordem em todos os servidores, todos chegam à mesma decisão.
+func (s *Store) ConfirmarRetirada(ids []string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	retirar := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		delete(s.reservas, id)
+		if s.iniciais[id] {
+			retirar[id] = true
+		}
+	}
+
+	encontradas := 0
+	for _, cartas := range s.Estoque {
+		for _, carta := range cartas {
+			if retirar[carta.ID] {
+				encontradas++
+			}
+		}
+	}
+	if encontradas < len(retirar) {
+		return false
+	}
+
+	for raridade, cartas := range s.Estoque {
+		restantes := cartas[:0]
+		for _, carta := range cartas {
+			if !retirar[carta.ID] {
+				restantes = append(restantes, carta)
+			}
+		}
+		s.Estoque[raridade] = restantes
+	}
+	return true
+}
+
+// CancelarRetirada libera a reserva das cartas de um pacote cuja compra não foi confirmada.
+func (s *Store) CancelarRetirada(cartas []tipos.Carta) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, carta := range cartas {
+		delete(s.reservas, carta.ID)
+	}
+}
+
+// Retiradas lista, em ordem, os IDs do estoque inicial que já saíram do estoque.
+func (s *Store) Retiradas() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	noEstoque := make(map[string]bool)
+	for _, cartas := range s.Estoque {
+		for _, carta := range cartas {
+			noEstoque[carta.ID] = true
+		}
+	}
+	retiradas := make([]string, 0, len(s.iniciais)-len(noEstoque))
+	for id := range s.iniciais {
+		if !noEstoque[id] {
+			retiradas = append(retiradas, id)
+		}
+	}
+	sort.Strings(retiradas)
+	return retiradas
+}
+
+// RestaurarRetiradas recria o estoque inicial, descarta as reservas e retira as cartas informadas.
+func (s *Store) RestaurarRetiradas(ids []string) {
+	s.inicializarEstoque()
+	s.mutex.Lock()
+	s.reservas = make(map[string]bool)
+	s.mutex.Unlock()
+	s.ConfirmarRetirada(ids)
+}
+
 func (s *Store) GetStatusEstoque() (map[string]int, int) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 	status := make(map[string]int)
 	total := 0
 	for raridade, cartas := range s.Estoque {
-		status[raridade] = len(cartas)
-		total += len(cartas)
+		disponiveis := 0
+		for _, carta := range cartas {
+			if !s.reservas[carta.ID] {
+				disponiveis++
+			}
+		}
+		status[raridade] = disponiveis
+		total += disponiveis
 	}
 	return status, total
 }
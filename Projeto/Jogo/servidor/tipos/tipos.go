@@ -3,6 +3,7 @@ package tipos
 import (
 	"jogodistribuido/protocolo"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,27 +22,98 @@ type Cliente struct {
 	Nome               string
 	Inventario         []protocolo.Carta
 	Sala               *Sala
-	EnderecoBlockchain string // Endereço da carteira blockchain do jogador
+	EnderecoBlockchain string    // Endereço da carteira blockchain do jogador
+	TokenSessao        string    // Token emitido no LOGIN_OK para retomar a sessão
+	UltimoContato      time.Time // Último sinal de presença recebido do cliente
+	Ausente            bool      // Sem sinal de presença; perde a partida se não voltar a tempo
+	EntradaFila        time.Time // Quando entrou na fila; a janela de rating aceita cresce com a espera
+	VarianteFila       string    // Variante de regras pedida ao entrar na fila (regras.Nova)
+	FormatoFila        string    // ID do formato de partida pedido ao entrar na fila (regras.NovoFormato)
 	Mutex              sync.Mutex
 }
 
-// Sala representa uma partida entre dois jogadores (possivelmente de servidores diferentes)
+// Sala representa uma partida entre dois jogadores (possivelmente de servidores diferentes).
+// ID e Jogadores são fixos desde a criação; o resto pertence à goroutine da sala (executarSala),
+// a única que lê e altera esses campos. As demais goroutines (API, monitores, MQTT) leem Retrato.
 type Sala struct {
-	ID             string
-	Jogadores      []*Cliente
-	Estado         string // "AGUARDANDO_COMPRA" | "JOGANDO" | "FINALIZADO"
-	CartasNaMesa   map[string]Carta
-	PontosRodada   map[string]int
-	PontosPartida  map[string]int
-	NumeroRodada   int
-	Prontos        map[string]bool
-	ServidorHost   string      // Servidor responsável pela lógica da partida
-	ServidorSombra string      // Servidor backup
-	EventSeq       int64       // Sequência de eventos para ordenação
-	EventLog       []GameEvent // Log append-only de eventos da partida
-	Mutex          sync.Mutex
-	TurnoDe        string           `json:"turno_de"` // ID do jogador que tem a vez
-	CartasJogadas  map[string]Carta `json:"cartas_jogadas"`
+	ID              string
+	Jogadores       []*Cliente
+	Estado          string // "AGUARDANDO_COMPRA" | "JOGANDO" | "FINALIZADO"
+	CartasNaMesa    map[string]Carta
+	PontosRodada    map[string]int
+	PontosPartida   map[string]int
+	NumeroRodada    int
+	Prontos         map[string]bool
+	ServidorHost    string      // Servidor responsável pela lógica da partida
+	ServidorSombra  string      // Servidor backup
+	EventSeq        int64       // Sequência de eventos para ordenação
+	EventLog        []GameEvent // Log append-only de eventos da partida
+	Regras          string      // ID do regras.RuleSet escolhido na criação da sala
+	Formato         string      // ID do regras.Formato (jogadas por rodada, rodadas, melhor-de)
+	JogadasNaRodada int         // Jogadas já resolvidas na rodada atual
+	Abandono        string      // ID do jogador que perdeu a partida por W.O.
+	PrazoTurno      time.Time   // Fim do prazo de TurnoDe para jogar (relógio deste servidor, Host ou Sombra)
+	AvisoPrazo      bool        // Aviso de tempo acabando já enviado para o turno atual
+	PrazoVencido    time.Time   // Prazo de TurnoDe que já venceu no relógio do Host (libera a jogada automática)
+
+	// Operações lentas em andamento fora da goroutine da sala (só ela lê e altera estes campos)
+	ComprasPendentes map[string]bool // IDs dos jogadores com compra de pacote em andamento
+	TrocaPendente    bool            // Troca de cartas em andamento
+
+	TurnoDe       string           `json:"turno_de"` // ID do jogador que tem a vez
+	CartasJogadas map[string]Carta `json:"cartas_jogadas"`
+
+	retrato atomic.Pointer[Sala] // Última cópia publicada pela goroutine da sala
+}
+
+// Retrato devolve a última cópia da sala publicada pela goroutine da sala, só para leitura.
+// Antes da primeira publicação devolve uma sala com apenas ID e Jogadores.
+func (s *Sala) Retrato() *Sala {
+	if r := s.retrato.Load(); r != nil {
+		return r
+	}
+	return &Sala{ID: s.ID, Jogadores: s.Jogadores}
+}
+
+// PublicarRetrato copia o estado atual para Retrato. Só a goroutine da sala chama.
+func (s *Sala) PublicarRetrato() {
+	r := &Sala{
+		ID:               s.ID,
+		Jogadores:        append([]*Cliente(nil), s.Jogadores...),
+		Estado:           s.Estado,
+		CartasNaMesa:     copiarMapa(s.CartasNaMesa),
+		PontosRodada:     copiarMapa(s.PontosRodada),
+		PontosPartida:    copiarMapa(s.PontosPartida),
+		NumeroRodada:     s.NumeroRodada,
+		Prontos:          copiarMapa(s.Prontos),
+		ServidorHost:     s.ServidorHost,
+		ServidorSombra:   s.ServidorSombra,
+		EventSeq:         s.EventSeq,
+		EventLog:         append([]GameEvent(nil), s.EventLog...),
+		Regras:           s.Regras,
+		Formato:          s.Formato,
+		JogadasNaRodada:  s.JogadasNaRodada,
+		Abandono:         s.Abandono,
+		PrazoTurno:       s.PrazoTurno,
+		AvisoPrazo:       s.AvisoPrazo,
+		PrazoVencido:     s.PrazoVencido,
+		ComprasPendentes: copiarMapa(s.ComprasPendentes),
+		TrocaPendente:    s.TrocaPendente,
+		TurnoDe:          s.TurnoDe,
+		CartasJogadas:    copiarMapa(s.CartasJogadas),
+	}
+	s.retrato.Store(r)
+}
+
+func copiarMapa[V any](m map[string]V) map[string]V {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
 }
 
 // GameEvent representa um evento no log da partida
@@ -53,22 +125,30 @@ type GameEvent struct {
 	PlayerID  string      `json:"playerId"`  // ID do jogador que gerou o evento
 	Data      interface{} `json:"data"`      // Dados específicos do evento
 	Signature string      `json:"signature"` // Assinatura HMAC do evento
+
+	// Assinatura personal_sign da jogada feita pela carteira do jogador (seguranca.MensagemJogada).
+	// Vazia em eventos do servidor, jogadas automáticas e jogadores sem carteira vinculada.
+	AssinaturaJogador string `json:"assinaturaJogador,omitempty"`
 }
 
 // EstadoPartida representa o estado completo de uma partida (para replicação)
 type EstadoPartida struct {
-	SalaID         string           `json:"sala_id"`
-	Estado         string           `json:"estado"`
-	CartasNaMesa   map[string]Carta `json:"cartas_na_mesa"`
-	PontosRodada   map[string]int   `json:"pontos_rodada"`
-	PontosPartida  map[string]int   `json:"pontos_partida"`
-	NumeroRodada   int              `json:"numero_rodada"`
-	Prontos        map[string]bool  `json:"prontos"`
-	EventSeq       int64            `json:"eventSeq"`        // Sequência de eventos
-	EventLog       []GameEvent      `json:"eventLog"`        // Log de eventos
-	TurnoDe        string           `json:"turnoDe"`         // ID do jogador que deve jogar
-	VencedorJogada string           `json:"vencedor_jogada"` // Vencedor da jogada (se houver)
-	Jogadores      []JogadorEstado  `json:"jogadores"`       // Inventários dos jogadores (para sincronização)
+	SalaID          string           `json:"sala_id"`
+	Estado          string           `json:"estado"`
+	CartasNaMesa    map[string]Carta `json:"cartas_na_mesa"`
+	PontosRodada    map[string]int   `json:"pontos_rodada"`
+	PontosPartida   map[string]int   `json:"pontos_partida"`
+	NumeroRodada    int              `json:"numero_rodada"`
+	Prontos         map[string]bool  `json:"prontos"`
+	EventSeq        int64            `json:"eventSeq"`          // Sequência de eventos
+	EventLog        []GameEvent      `json:"eventLog"`          // Log de eventos
+	TurnoDe         string           `json:"turnoDe"`           // ID do jogador que deve jogar
+	VencedorJogada  string           `json:"vencedor_jogada"`   // Vencedor da jogada (se houver)
+	Jogadores       []JogadorEstado  `json:"jogadores"`         // Inventários dos jogadores (para sincronização)
+	Regras          string           `json:"regras,omitempty"`  // ID das regras da partida
+	Formato         string           `json:"formato,omitempty"` // ID do formato da partida
+	JogadasNaRodada int              `json:"jogadas_na_rodada,omitempty"`
+	PrazoTurno      int64            `json:"prazo_turno,omitempty"` // Unix (ms) do fim do prazo de TurnoDe
 }
 
 type JogadorEstado struct {
@@ -86,9 +166,10 @@ type GameStartRequest struct {
 
 // Player representa um jogador na partida
 type Player struct {
-	ID     string `json:"id"`     // ID do jogador
-	Nome   string `json:"nome"`   // Nome do jogador
-	Server string `json:"server"` // Servidor ao qual o jogador está conectado
+	ID       string `json:"id"`                 // ID do jogador
+	Nome     string `json:"nome"`               // Nome do jogador
+	Server   string `json:"server"`             // Servidor ao qual o jogador está conectado
+	Endereco string `json:"endereco,omitempty"` // Carteira provada no login; assina as jogadas
 }
 
 // GameEventRequest representa um evento de jogo
@@ -100,6 +181,8 @@ type GameEventRequest struct {
 	Data      interface{} `json:"data"`      // Dados do evento
 	Token     string      `json:"token"`     // Token JWT
 	Signature string      `json:"signature"` // Assinatura HMAC
+
+	AssinaturaJogador string `json:"assinaturaJogador,omitempty"` // Assinatura da jogada pela carteira do jogador
 }
 
 // GameReplicateRequest representa uma replicação de estado
@@ -110,3 +193,88 @@ type GameReplicateRequest struct {
 	Token     string        `json:"token"`     // Token JWT
 	Signature string        `json:"signature"` // Assinatura HMAC
 }
+
+// TurnoEsgotadoRequest pede ao servidor do jogador que faça a jogada automática de quem estourou o tempo
+type TurnoEsgotadoRequest struct {
+	MatchID  string `json:"matchId"`
+	PlayerID string `json:"playerId"`
+}
+
+// AssinarResultadoRequest pede à Sombra que confira o resultado no EventLog replicado e o assine
+type AssinarResultadoRequest struct {
+	MatchID  string `json:"matchId"`
+	Jogador1 string `json:"jogador1"`
+	Jogador2 string `json:"jogador2"`
+	Vencedor string `json:"vencedor"` // Endereço zero em caso de empate
+	HashLog  string `json:"hashLog"`
+}
+
+// AssinarResultadoResponse traz a conta blockchain da Sombra e sua assinatura (hex) do resultado
+type AssinarResultadoResponse struct {
+	Conta      string `json:"conta"`
+	Assinatura string `json:"assinatura"`
+}
+
+// EntradaVinculoCarteira é a entrada do log replicado (Raft) que vincula um nome de jogador a uma
+// carteira. Vale o primeiro vínculo de cada nome no log; depois dele, todos os servidores só aceitam
+// login desse nome assinado pela carteira.
+type EntradaVinculoCarteira struct {
+	Nome     string `json:"nome"`
+	Endereco string `json:"endereco"`
+}
+
+// EntradaCompraPacote é a entrada do log replicado (Raft) que registra uma compra de pacote
+type EntradaCompraPacote struct {
+	ClienteID string  `json:"cliente_id"`          // Jogador que comprou
+	Cartas    []Carta `json:"cartas"`              // Cartas retiradas do estoque global
+	CompraID  string  `json:"compra_id,omitempty"` // Identifica a compra para o servidor que a propôs
+}
+
+// EntradaResgatePacote é a entrada do log replicado (Raft) que registra a entrega de um pacote
+// comprado pela carteira do jogador no modo ESTOQUE_MODO=BLOCKCHAIN; cada compra vale um resgate
+type EntradaResgatePacote struct {
+	ClienteID string `json:"cliente_id"`          // Jogador que recebeu o pacote
+	Transacao string `json:"transacao"`           // Hash da transação de revelação do pacote no contrato
+	CompraID  string `json:"compra_id,omitempty"` // Identifica o resgate para o servidor que o propôs
+}
+
+// ResultadoPartida é a entrada do log replicado (Raft) que registra o resultado de uma partida
+type ResultadoPartida struct {
+	SalaID        string         `json:"sala_id"`
+	Jogadores     []Player       `json:"jogadores"`                // Participantes (ID, nome e servidor)
+	Vencedor      string         `json:"vencedor"`                 // Nome do vencedor / "EMPATE"
+	PontosRodada  map[string]int `json:"pontos_rodada"`            // nome -> pontos na última rodada
+	PontosPartida map[string]int `json:"pontos_partida,omitempty"` // nome -> rodadas vencidas
+	Ratings       map[string]int `json:"ratings,omitempty"`        // nome -> rating (ELO) após a partida, calculado ao aplicar a entrada
+	Timestamp     int64          `json:"timestamp"`                // Unix (segundos) do fim da partida
+}
+
+// AnuncioMercado é um anúncio ativo do mercado de cartas, lido do GameEconomy
+type AnuncioMercado struct {
+	ID        string `json:"id"`
+	Vendedor  string `json:"vendedor"`  // Endereço blockchain do vendedor
+	Carta     Carta  `json:"carta"`     // Carta sob custódia do contrato
+	PrecoWei  string `json:"preco_wei"` // Preço em wei (decimal, pode passar de int64)
+	Timestamp int64  `json:"timestamp"` // Unix (segundos) da criação do anúncio
+}
+
+// DivergenciaInventario compara, para um jogador com carteira, o inventário em memória no servidor
+// com o inventário do GameEconomy. Uma carta com o mesmo ID mas atributos diferentes aparece nas duas listas.
+type DivergenciaInventario struct {
+	ClienteID    string  `json:"cliente_id"`
+	Nome         string  `json:"nome"`
+	Endereco     string  `json:"endereco"`       // Carteira do jogador
+	SoNoServidor []Carta `json:"so_no_servidor"` // Cartas que o servidor tem e a blockchain não
+	SoNaCadeia   []Carta `json:"so_na_cadeia"`   // Cartas que a blockchain tem e o servidor não
+	Corrigida    bool    `json:"corrigida"`      // O inventário do servidor foi substituído pelo da blockchain
+}
+
+// RelatorioReconciliacao é o resultado de uma passada da reconciliação de inventários
+type RelatorioReconciliacao struct {
+	Autoridade   string                  `json:"autoridade"`   // Fonte que prevalece nas divergências (CADEIA | SERVIDOR)
+	Executada    int64                   `json:"executada"`    // Unix (segundos) do fim da passada
+	Verificados  int                     `json:"verificados"`  // Jogadores com carteira comparados
+	EmPartida    int                     `json:"em_partida"`   // Jogadores pulados por estarem em partida
+	Falhas       int                     `json:"falhas"`       // Jogadores cujo inventário na blockchain não pôde ser lido
+	Divergencias []DivergenciaInventario `json:"divergencias"` // Só os jogadores com diferenças
+}